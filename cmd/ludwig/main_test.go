@@ -0,0 +1,404 @@
+// End to end tests, which run keystroke scripts through the editor on a
+// virtual screen and compare the screen and the text of the frames with
+// golden files.
+//
+// Each script in testdata/e2e/NAME.keys is run by a copy of the test binary
+// of its own, in a directory of its own.  If there
+// is a testdata/e2e/NAME.txt, it is the file being edited.  What is left on
+// the screen when the keys run out is compared with testdata/e2e/NAME.golden.
+// Run "go test ./cmd/ludwig -update" to write the golden files again.
+//
+// In a script, line breaks are ignored and lines starting with # are
+// comments.  Keys that are not characters are written between < and >:
+// <cr>, <tab>, <esc>, <bs>, <del>, <ins>, <up>, <down>, <left>, <right>,
+// <home>, <end>, <pgup>, <pgdn>, <fN>, <^X> for a control character, <lt>
+// for < and <resize ROWS COLS> to change the size of the screen.  Scripts
+// should not quit, since there would be nothing left to compare.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "ludwig-go/internal/ludwig"
+	"ludwig-go/internal/terminal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "write the golden files of the end to end tests")
+
+const (
+	e2eDir       = "testdata/e2e"
+	e2eScriptEnv = "LUDWIG_E2E_SCRIPT" // The script the test binary is to run
+	e2eOutputEnv = "LUDWIG_E2E_OUTPUT" // Where the script's results go
+	e2eRows      = 12
+	e2eCols      = 60
+)
+
+func TestMain(m *testing.M) {
+	if script := os.Getenv(e2eScriptEnv); script != "" {
+		if err := runScript(script, os.Getenv(e2eOutputEnv)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// scriptKey is one key of a script, or a change of the screen size
+type scriptKey struct {
+	text       string
+	key        int
+	rows, cols int
+}
+
+// scriptKeyNames are the names of the keys that are not characters
+var scriptKeyNames = map[string]int{
+	"bs":    terminal.KeyBackspace,
+	"del":   terminal.KeyDC,
+	"ins":   terminal.KeyIC,
+	"up":    terminal.KeyUp,
+	"down":  terminal.KeyDown,
+	"left":  terminal.KeyLeft,
+	"right": terminal.KeyRight,
+	"home":  terminal.KeyHome,
+	"end":   terminal.KeyEnd,
+	"pgup":  terminal.KeyPPage,
+	"pgdn":  terminal.KeyNPage,
+}
+
+// parseScript reads the keys of a script
+func parseScript(script string) ([]scriptKey, error) {
+	var keys []scriptKey
+	var text strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for line != "" {
+			start := strings.IndexByte(line, '<')
+			if start < 0 {
+				text.WriteString(line)
+				break
+			}
+			text.WriteString(line[:start])
+			end := strings.IndexByte(line[start:], '>')
+			if end < 0 {
+				return nil, fmt.Errorf("no > after %q", line[start:])
+			}
+			name := line[start+1 : start+end]
+			line = line[start+end+1:]
+
+			key := scriptKey{}
+			switch fields := strings.Fields(name); {
+			case name == "lt":
+				text.WriteByte('<')
+				continue
+			case name == "cr":
+				text.WriteByte('\r')
+				continue
+			case name == "tab":
+				text.WriteByte('\t')
+				continue
+			case name == "esc":
+				text.WriteByte(0x1b)
+				continue
+			case len(name) == 2 && name[0] == '^' && name[1] >= '@' && name[1] <= '_':
+				text.WriteByte(name[1] - '@')
+				continue
+			case len(fields) == 3 && fields[0] == "resize":
+				var err1, err2 error
+				key.rows, err1 = strconv.Atoi(fields[1])
+				key.cols, err2 = strconv.Atoi(fields[2])
+				if err1 != nil || err2 != nil {
+					return nil, fmt.Errorf("bad size <%s>", name)
+				}
+			case strings.HasPrefix(name, "f"):
+				n, err := strconv.Atoi(name[1:])
+				if err != nil || n < 0 || n > 63 {
+					return nil, fmt.Errorf("unknown key <%s>", name)
+				}
+				key.key = terminal.KeyF0 + n
+			default:
+				code, ok := scriptKeyNames[name]
+				if !ok {
+					return nil, fmt.Errorf("unknown key <%s>", name)
+				}
+				key.key = code
+			}
+			if text.Len() > 0 {
+				keys = append(keys, scriptKey{text: text.String()})
+				text.Reset()
+			}
+			keys = append(keys, key)
+		}
+	}
+	if text.Len() > 0 {
+		keys = append(keys, scriptKey{text: text.String()})
+	}
+	return keys, nil
+}
+
+// scriptScreen is a virtual screen that writes out the results of the
+// script when the editor wants a key and there are none left
+type scriptScreen struct {
+	*terminal.Virtual
+	editor *Editor
+	output string
+}
+
+func (s *scriptScreen) GetKey(timeout time.Duration) (int, bool, error) {
+	if s.Pending() == 0 {
+		err := os.WriteFile(s.output, []byte(s.results()), 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+	return s.Virtual.GetKey(timeout)
+}
+
+// results describes the screen and the text of the frames
+func (s *scriptScreen) results() string {
+	var b strings.Builder
+	b.WriteString("-- screen --\n")
+	for _, line := range s.Lines() {
+		b.WriteString(line + "\n")
+	}
+	y, x, _ := s.ShownCursor()
+	fmt.Fprintf(&b, "-- cursor %d,%d --\n", y+1, x+1)
+	for span := s.editor.FirstSpan; span != nil; span = span.FLink {
+		frame := span.Frame
+		if frame == nil || frame.Options.Has(OptSpecialFrame) {
+			continue
+		}
+		fmt.Fprintf(&b, "-- frame %s --\n", span.Name)
+		for line := frame.FirstGroup.FirstLine; line != nil && line.FLink != nil; line = line.FLink {
+			if line.Used > 0 {
+				b.WriteString(line.Str.Slice(1, line.Used))
+			}
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// runScript runs the editor on a script, in the current directory
+func runScript(name, output string) error {
+	script, err := os.ReadFile(name + ".keys")
+	if err != nil {
+		return err
+	}
+	keys, err := parseScript(string(script))
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	e := NewEditor()
+	screen := &scriptScreen{Virtual: terminal.NewVirtual(e2eRows, e2eCols), editor: e, output: output}
+	for _, key := range keys {
+		switch {
+		case key.text != "":
+			screen.Type(key.text)
+		case key.rows > 0:
+			screen.Resize(key.rows, key.cols)
+		default:
+			screen.Press(key.key)
+		}
+	}
+	e.VduUseScreen(screen)
+
+	args := []string{"ludwig"}
+	if _, err := os.Stat(filepath.Base(name) + ".txt"); err == nil {
+		args = append(args, filepath.Base(name)+".txt")
+	}
+	e.ValueInitializations()
+	if !startUp(e, len(args), args) {
+		return fmt.Errorf("%s: the editor did not start", name)
+	}
+
+	// Show the files by their names alone, so that the screen does not
+	// depend on the directory the editor is run in
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	for _, file := range e.Files {
+		if file != nil {
+			file.Filename = strings.TrimPrefix(file.Filename, dir+"/")
+		}
+	}
+	e.ScreenRedraw()
+	e.ExecuteImmed()
+	return fmt.Errorf("%s: the editor stopped before the keys ran out", name)
+}
+
+func TestEndToEnd(t *testing.T) {
+	scripts, err := filepath.Glob(filepath.Join(e2eDir, "*.keys"))
+	require.NoError(t, err)
+	require.NotEmpty(t, scripts)
+	self, err := os.Executable()
+	require.NoError(t, err)
+
+	for _, script := range scripts {
+		name := strings.TrimSuffix(script, ".keys")
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			// The editor runs in a directory of its own, with nothing from
+			// the user's set up
+			dir := t.TempDir()
+			abs, err := filepath.Abs(name)
+			require.NoError(t, err)
+			if text, err := os.ReadFile(name + ".txt"); err == nil {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.Base(name)+".txt"), text, 0644))
+			}
+			output := filepath.Join(dir, "results")
+
+			cmd := exec.Command(self)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(),
+				e2eScriptEnv+"="+abs,
+				e2eOutputEnv+"="+output,
+				"HOME="+dir,
+				"LUD_RECOVERDIR="+filepath.Join(dir, "recover"),
+				"LUD_SYNTAXDIR="+dir,
+				"LUD_REFRESH_DELAY=",
+			)
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, "%s", out)
+			results, err := os.ReadFile(output)
+			require.NoError(t, err)
+
+			golden := name + ".golden"
+			if *update {
+				require.NoError(t, os.WriteFile(golden, results, 0644))
+				return
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(results))
+		})
+	}
+}
+
+func TestParseScript(t *testing.T) {
+	keys, err := parseScript("# a comment\nab<cr>\nc<lt><^A><up><f2>\n<resize 5 20>x")
+	require.NoError(t, err)
+	assert.Equal(t, []scriptKey{
+		{text: "ab\rc<\x01"},
+		{key: terminal.KeyUp},
+		{key: terminal.KeyF0 + 2},
+		{rows: 5, cols: 20},
+		{text: "x"},
+	}, keys)
+
+	for _, bad := range []string{"<up", "<what>", "<resize 5>", "<fx>"} {
+		_, err := parseScript(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	steps, options, files, ok := commandArgs([]string{
+		"-e", "a k", "-O", "-s", "1000", "-f", "script.lud", "one", "-t", "two",
+	})
+	require.True(t, ok)
+	assert.Equal(t, []streamStep{{commands: "a k"}, {script: "script.lud"}}, steps)
+	assert.Equal(t, []string{"-O", "-s", "1000", "-t"}, options)
+	assert.Equal(t, []startFile{{name: "one"}, {name: "two"}}, files)
+
+	steps, _, files, ok = commandArgs([]string{"-O", "+12", "a.go", "b.go:3", "c.go:4:5:", "d:e"})
+	require.True(t, ok)
+	assert.Nil(t, steps)
+	assert.Equal(t, []startFile{
+		{name: "a.go", line: 12},
+		{name: "b.go", line: 3},
+		{name: "c.go", line: 4, col: 5},
+		{name: "d:e"},
+	}, files)
+
+	for _, bad := range [][]string{{"file", "-e"}, {"+x", "file"}, {"+0", "file"}} {
+		_, _, _, ok = commandArgs(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestStartUpFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	one := filepath.Join(dir, "one.txt")
+	two := filepath.Join(dir, "sub", "two.txt")
+	three := filepath.Join(dir, "two.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(two), 0755))
+	for _, file := range []string{one, two, three} {
+		require.NoError(t, os.WriteFile(file, []byte("line 1\nline 2\nline 3\n"), 0644))
+	}
+
+	e := NewEditor()
+	e.Output = io.Discard
+	e.ValueInitializations()
+	args := []string{"ludwig", "-M", one + ":2:3", "+3", two, three + ":9"}
+	require.True(t, startUp(e, len(args), args))
+
+	// Each file has a frame named after it, the first is current, and frame
+	// LUDWIG is left empty
+	assert.Equal(t, "ONE.TXT", e.CurrentFrame.Span.Name)
+	var names []string
+	for span := e.FirstSpan; span != nil; span = span.FLink {
+		if !span.Frame.Options.Has(OptSpecialFrame) {
+			names = append(names, span.Name)
+		}
+	}
+	assert.Equal(t, []string{DefaultFrameName, "ONE.TXT", "TWO.TXT", "TWO.TXT<2>"}, names)
+
+	dots := map[string][2]int{}
+	for span := e.FirstSpan; span != nil; span = span.FLink {
+		var lineNr int
+		LineToNumber(span.Frame.Dot.Line, &lineNr)
+		dots[span.Name] = [2]int{lineNr, span.Frame.Dot.Col}
+		if span.Frame.InputFile != 0 {
+			assert.Equal(t, span.Frame, e.FilesFrames[span.Frame.InputFile])
+			assert.Equal(t, span.Frame, e.FilesFrames[span.Frame.OutputFile])
+		}
+	}
+	assert.Equal(t, [2]int{1, 1}, dots[DefaultFrameName])
+	assert.Equal(t, [2]int{2, 3}, dots["ONE.TXT"])
+	assert.Equal(t, [2]int{3, 1}, dots["TWO.TXT"])
+	assert.Equal(t, [2]int{4, 1}, dots["TWO.TXT<2>"]) // After the last line
+	e.LudwigAborted = false
+	e.QuitCloseFiles()
+}
+
+func TestStartUpFilesWithSpaces(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	one := filepath.Join(dir, "my notes.txt")
+	two := filepath.Join(dir, "more notes.txt")
+	require.NoError(t, os.WriteFile(one, []byte("one\n"), 0644))
+	require.NoError(t, os.WriteFile(two, []byte("two\n"), 0644))
+
+	// A name with a space in it is one file, not two
+	e := NewEditor()
+	e.Output = io.Discard
+	e.ValueInitializations()
+	args := []string{"ludwig", "-M", one, two}
+	require.True(t, startUp(e, len(args), args))
+	assert.Equal(t, "MY NOTES.TXT", e.CurrentFrame.Span.Name)
+	assert.Equal(t, one, e.Files[e.CurrentFrame.InputFile].Filename)
+	require.True(t, e.FrameEdit("MORE NOTES.TXT"))
+	assert.Equal(t, two, e.Files[e.CurrentFrame.InputFile].Filename)
+	assert.Equal(t, "two", e.CurrentFrame.FirstGroup.FirstLine.Str.Slice(1, 3))
+	e.LudwigAborted = false
+	e.QuitCloseFiles()
+}
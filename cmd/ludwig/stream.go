@@ -0,0 +1,147 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         STREAM
+//
+// Description:  Stream editing.  "ludwig -e commands file..." and
+//               "ludwig -f script file..." run commands over each file in
+//               turn and write it back in place, or over standard input
+//               to standard output when there are no files.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	. "ludwig-go/internal/ludwig"
+)
+
+// streamStep is the commands of one -e option, or the script named by one
+// -f option
+type streamStep struct {
+	commands string
+	script   string
+}
+
+// streamEdit runs the steps over each of the files, or over standard input
+// when there are none, and says whether they all succeeded
+func streamEdit(steps []streamStep, options []string, files []startFile) bool {
+	if len(files) == 0 {
+		return streamFile(steps, options, "")
+	}
+	result := true
+	for _, file := range files {
+		if !streamFile(steps, options, file.name) {
+			result = false
+		}
+	}
+	return result
+}
+
+// streamFile runs the steps over one file, with an editor of its own.  The
+// file is only written back if the steps all succeed and change it.  An
+// empty file name means standard input, which is always written to
+// standard output.
+func streamFile(steps []streamStep, options []string, file string) bool {
+	e := NewEditor()
+	e.Output = os.Stderr
+	e.ValueInitializations()
+
+	// The options are Ludwig's own, but there is no filename memory or
+	// initialization unless the options ask for it
+	args := append([]string{"-M", "-I"}, options...)
+	if file != "" {
+		if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+			e.ScreenMessage(fmt.Sprintf("Error opening (%s) as input", file))
+			return false
+		}
+		args = append(args, file)
+	}
+	if !e.FileCreateOpenArgs(args, ParseCommand, &e.Files[1], &e.Files[2]) {
+		return false
+	}
+	if file == "" {
+		var stdinName string
+		var dummyFptr *FileObject
+		if !e.FileCreateOpen(&stdinName, ParseStdin, &e.Files[1], &dummyFptr) {
+			return false
+		}
+		e.Files[2] = &FileObject{Valid: true, OutputFlag: true, Fd: 1, Entab: e.FileData.Entab}
+	}
+
+	if !e.BatchStart() {
+		return false
+	}
+	frame := e.CurrentFrame
+	frame.InputFile = 1
+	e.FilesFrames[1] = frame
+	frame.OutputFile = 2
+	e.FilesFrames[2] = frame
+	if !e.FilePage(frame, &e.ExitAbort) {
+		return false
+	}
+
+	if e.FileData.Initial != "" {
+		if !e.Execute(CmdFileExecute, LeadParamNone, 1, streamTpar(e.FileData.Initial), true) {
+			return streamFailed(e, file)
+		}
+	}
+	for _, step := range steps {
+		var ok bool
+		if step.script != "" {
+			ok = e.Execute(CmdFileExecute, LeadParamNone, 1, streamTpar(step.script), true)
+		} else {
+			ok = e.BatchExecute(step.commands)
+		}
+		e.ExitAbort = false
+		if !ok {
+			return streamFailed(e, file)
+		}
+	}
+
+	// Standard output gets the text whether it has changed or not
+	if file == "" {
+		frame.TextModified = true
+	}
+	if !e.FileWindthru(frame, true) {
+		return false
+	}
+	if !e.FileCloseDelete(e.Files[1], false, false) {
+		return false
+	}
+	if file == "" {
+		return true
+	}
+	return e.FileCloseDelete(e.Files[2], !frame.TextModified, false)
+}
+
+// streamFailed reports that the steps failed for a file, and throws away
+// the file's output
+func streamFailed(e *Editor, file string) bool {
+	if file == "" {
+		e.ScreenMessage("COMMAND FAILED")
+		return false
+	}
+	e.ScreenMessage(fmt.Sprintf("COMMAND FAILED for %s, file not changed", file))
+	e.FileCloseDelete(e.Files[1], false, false)
+	e.FileCloseDelete(e.Files[2], true, false)
+	return false
+}
+
+// streamTpar makes the trailing parameter of a command
+func streamTpar(text string) *TParObject {
+	return &TParObject{
+		Len: utf8.RuneCountInString(text),
+		Dlm: TpdExact,
+		Str: NewStrObjectFrom(text),
+	}
+}
@@ -0,0 +1,61 @@
+// Tests for stream editing
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamEdit(t *testing.T) {
+	dir := t.TempDir()
+	one := filepath.Join(dir, "one.txt")
+	two := filepath.Join(dir, "two.txt")
+	script := filepath.Join(dir, "script.lud")
+	require.NoError(t, os.WriteFile(one, []byte("hello world\nsecond\n"), 0644))
+	require.NoError(t, os.WriteFile(two, []byte("no match\n"), 0644))
+	require.NoError(t, os.WriteFile(script, []byte("a\ni/2nd /\n"), 0644))
+
+	steps := []streamStep{{commands: "g/world/ -5d i/there/"}, {script: script}}
+	options := []string{"-b", "0"}
+	assert.True(t, streamEdit(steps, options, []startFile{{name: one}}))
+	text, err := os.ReadFile(one)
+	require.NoError(t, err)
+	assert.Equal(t, "hello there\n2nd second\n", string(text))
+
+	// A file the commands fail for is left alone, but the others are done
+	require.NoError(t, os.WriteFile(one, []byte("hello world\n"), 0644))
+	assert.False(t, streamEdit(steps[:1], options, []startFile{{name: two}, {name: one}}))
+	text, err = os.ReadFile(two)
+	require.NoError(t, err)
+	assert.Equal(t, "no match\n", string(text))
+	text, err = os.ReadFile(one)
+	require.NoError(t, err)
+	assert.Equal(t, "hello there\n", string(text))
+
+	// Nothing is left behind but the files
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"one.txt", "script.lud", "two.txt"}, names)
+
+	assert.False(t, streamEdit(steps, options, []startFile{{name: filepath.Join(dir, "missing")}}))
+}
+
+func TestStreamEditSpaces(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "my notes.txt")
+	require.NoError(t, os.WriteFile(name, []byte("hello world\n"), 0644))
+
+	steps := []streamStep{{commands: "g/world/ -5d i/there/"}}
+	assert.True(t, streamEdit(steps, []string{"-b", "0"}, []startFile{{name: name}}))
+	text, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "hello there\n", string(text))
+}
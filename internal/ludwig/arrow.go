@@ -30,39 +30,39 @@ func isArrowCommand(command Commands) bool {
 }
 
 // ArrowCommand handles arrow key, TAB, and BACKTAB commands
-func ArrowCommand(command Commands, rept LeadParam, count int, fromSpan bool) bool {
+func (e *Editor) ArrowCommand(command Commands, rept LeadParam, count int, fromSpan bool) bool {
 	cmdStatus := false
 	var newEql MarkObject
-	oldDot := *CurrentFrame.Dot
-	eopLineNr := CurrentFrame.LastGroup.FirstLineNr + CurrentFrame.LastGroup.LastLine.OffsetNr
+	oldDot := *e.CurrentFrame.Dot
+	eopLineNr := e.CurrentFrame.LastGroup.FirstLineNr + e.CurrentFrame.LastGroup.LastLine.OffsetNr
 
 	var key int
 	for {
 		cmdValid := false
 		switch command {
 		case CmdReturn:
-			cmdValid = doCmdReturn(count, &newEql, &eopLineNr)
+			cmdValid = e.doCmdReturn(count, &newEql, &eopLineNr)
 
 		case CmdHome:
-			cmdValid = doCmdHome(&newEql)
+			cmdValid = e.doCmdHome(&newEql)
 
 		case CmdTab:
-			cmdValid = doCmdTabBacktab(1, count, &newEql)
+			cmdValid = e.doCmdTabBacktab(1, count, &newEql)
 
 		case CmdBacktab:
-			cmdValid = doCmdTabBacktab(-1, count, &newEql)
+			cmdValid = e.doCmdTabBacktab(-1, count, &newEql)
 
 		case CmdLeft:
-			cmdValid = doCmdLeft(rept, count, &newEql)
+			cmdValid = e.doCmdLeft(rept, count, &newEql)
 
 		case CmdRight:
-			cmdValid = doCmdRight(rept, count, &newEql)
+			cmdValid = e.doCmdRight(rept, count, &newEql)
 
 		case CmdDown:
-			cmdValid = doCmdDown(rept, count, &newEql, eopLineNr)
+			cmdValid = e.doCmdDown(rept, count, &newEql, eopLineNr)
 
 		case CmdUp:
-			cmdValid = doCmdUp(rept, count, &newEql)
+			cmdValid = e.doCmdUp(rept, count, &newEql)
 		}
 
 		if cmdValid {
@@ -71,35 +71,35 @@ func ArrowCommand(command Commands, rept LeadParam, count int, fromSpan bool) bo
 		if fromSpan {
 			break
 		}
-		ScreenFixup()
+		e.ScreenFixup()
 		if !cmdValid || ((command == CmdDown) && (rept != LeadParamPIndef) &&
-			(CurrentFrame.Dot.Line.FLink == nil)) {
-			VduBeep()
+			(e.CurrentFrame.Dot.Line.FLink == nil)) {
+			e.VduBeep()
 		}
-		key = VduGetKey()
-		if TtControlC {
+		key = e.VduGetKey()
+		if e.TtControlC.Load() {
 			break
 		}
 		rept = LeadParamNone
 		count = 1
-		command = Lookup[key].Command
-		if (command == CmdReturn) && (EditMode == ModeInsert) {
+		command = e.KeyLookup(key).Command
+		if (command == CmdReturn) && (e.EditMode == ModeInsert) {
 			command = CmdSplitLine
 		}
 		if !isArrowCommand(command) {
-			VduTakeBackKey(key)
+			e.VduTakeBackKey(key)
 			break
 		}
 	}
 
-	if TtControlC {
-		MarkCreate(oldDot.Line, oldDot.Col, &CurrentFrame.Dot)
+	if e.TtControlC.Load() {
+		MarkCreate(oldDot.Line, oldDot.Col, &e.CurrentFrame.Dot)
 	} else {
 		// Define Equals.
 		if cmdStatus {
-			MarkCreate(newEql.Line, newEql.Col, &CurrentFrame.Marks[MarkEquals])
+			MarkCreate(newEql.Line, newEql.Col, &e.CurrentFrame.Marks[MarkEquals])
 			if (command == CmdDown) && (rept != LeadParamPIndef) &&
-				(CurrentFrame.Dot.Line.FLink == nil) {
+				(e.CurrentFrame.Dot.Line.FLink == nil) {
 				cmdStatus = false
 			}
 		}
@@ -107,9 +107,9 @@ func ArrowCommand(command Commands, rept LeadParam, count int, fromSpan bool) bo
 	return cmdStatus || !fromSpan
 }
 
-func doCmdDown(rept LeadParam, count int, newEql *MarkObject, eopLineNr int) bool {
-	*newEql = *CurrentFrame.Dot
-	dotLine := CurrentFrame.Dot.Line
+func (e *Editor) doCmdDown(rept LeadParam, count int, newEql *MarkObject, eopLineNr int) bool {
+	*newEql = *e.CurrentFrame.Dot
+	dotLine := e.CurrentFrame.Dot.Line
 	var lineNr int
 	if !LineToNumber(dotLine, &lineNr) {
 		return false
@@ -122,74 +122,93 @@ func doCmdDown(rept LeadParam, count int, newEql *MarkObject, eopLineNr int) boo
 					dotLine = dotLine.FLink
 				}
 			} else {
-				if !LineFromNumber(CurrentFrame, lineNr+count, &dotLine) {
+				if !LineFromNumber(e.CurrentFrame, lineNr+count, &dotLine) {
 					return false
 				}
 			}
 		}
 	case LeadParamPIndef:
-		dotLine = CurrentFrame.LastGroup.LastLine
+		dotLine = e.CurrentFrame.LastGroup.LastLine
 	}
-	if !MarkCreate(dotLine, CurrentFrame.Dot.Col, &CurrentFrame.Dot) {
+	if !MarkCreate(dotLine, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Dot) {
 		return false
 	}
 	return true
 }
 
-func doCmdHome(newEql *MarkObject) bool {
-	*newEql = *CurrentFrame.Dot
-	if CurrentFrame == ScrFrame {
-		if !MarkCreate(ScrTopLine, CurrentFrame.ScrOffset+1, &CurrentFrame.Dot) {
+func (e *Editor) doCmdHome(newEql *MarkObject) bool {
+	*newEql = *e.CurrentFrame.Dot
+	if e.CurrentFrame == e.ScrFrame {
+		if !MarkCreate(e.ScrTopLine, e.CurrentFrame.ScrOffset+1, &e.CurrentFrame.Dot) {
 			return false
 		}
 	}
 	return true
 }
 
-func doCmdLeft(rept LeadParam, count int, newEql *MarkObject) bool {
-	*newEql = *CurrentFrame.Dot
+func (e *Editor) doCmdLeft(rept LeadParam, count int, newEql *MarkObject) bool {
+	*newEql = *e.CurrentFrame.Dot
 	switch rept {
 	case LeadParamNone, LeadParamPlus, LeadParamPInt:
-		if CurrentFrame.Dot.Col-count >= 1 {
-			CurrentFrame.Dot.Col -= count
+		if e.CurrentFrame.Dot.Col-count >= 1 {
+			e.CurrentFrame.Dot.Col -= count
 			return true
 		}
 	case LeadParamPIndef:
-		if CurrentFrame.Dot.Col >= CurrentFrame.MarginLeft {
-			CurrentFrame.Dot.Col = CurrentFrame.MarginLeft
+		if e.CurrentFrame.Dot.Col >= e.CurrentFrame.MarginLeft {
+			e.CurrentFrame.Dot.Col = e.CurrentFrame.MarginLeft
 			return true
 		}
 	}
 	return false
 }
 
-func doCmdRight(rept LeadParam, count int, newEql *MarkObject) bool {
-	*newEql = *CurrentFrame.Dot
+func (e *Editor) doCmdRight(rept LeadParam, count int, newEql *MarkObject) bool {
+	*newEql = *e.CurrentFrame.Dot
 	switch rept {
 	case LeadParamNone, LeadParamPlus, LeadParamPInt:
-		if CurrentFrame.Dot.Col+count <= MaxStrLenP {
-			CurrentFrame.Dot.Col += count
+		if e.CurrentFrame.Dot.Col+count <= MaxStrLenP {
+			e.CurrentFrame.Dot.Col += count
 			return true
 		}
 	case LeadParamPIndef:
-		if CurrentFrame.Dot.Col <= CurrentFrame.MarginRight {
-			CurrentFrame.Dot.Col = CurrentFrame.MarginRight
+		if e.CurrentFrame.Dot.Col <= e.CurrentFrame.MarginRight {
+			e.CurrentFrame.Dot.Col = e.CurrentFrame.MarginRight
 			return true
 		}
 	}
 	return false
 }
 
-func doCmdTabBacktab(step, count int, newEql *MarkObject) bool {
-	*newEql = *CurrentFrame.Dot
-	newCol := CurrentFrame.Dot.Col
+func (e *Editor) doCmdTabBacktab(step, count int, newEql *MarkObject) bool {
+	*newEql = *e.CurrentFrame.Dot
+	newCol := e.CurrentFrame.Dot.Col
+	tabs := &e.CurrentFrame.TabStops
+	last := MaxStrLenP
+	if tabs.Every == 0 {
+		last = tabs.Last()
+	}
 	for counter := 1; counter <= count; counter++ {
 		for {
 			newCol += step
+			if newCol > last {
+				// Past the last tab stop only the margins are left
+				next := last
+				if step > 0 {
+					next = MaxStrLenP
+				}
+				for _, margin := range []int{e.CurrentFrame.MarginLeft, e.CurrentFrame.MarginRight} {
+					if (step > 0 && margin >= newCol && margin < next) ||
+						(step < 0 && margin <= newCol && margin > next) {
+						next = margin
+					}
+				}
+				newCol = next
+			}
 			if newCol <= 0 || newCol >= MaxStrLenP ||
-				CurrentFrame.TabStops[newCol] ||
-				(newCol == CurrentFrame.MarginLeft) ||
-				(newCol == CurrentFrame.MarginRight) {
+				tabs.IsStop(newCol) ||
+				(newCol == e.CurrentFrame.MarginLeft) ||
+				(newCol == e.CurrentFrame.MarginRight) {
 				break
 			}
 		}
@@ -197,13 +216,13 @@ func doCmdTabBacktab(step, count int, newEql *MarkObject) bool {
 			return false
 		}
 	}
-	CurrentFrame.Dot.Col = newCol
+	e.CurrentFrame.Dot.Col = newCol
 	return true
 }
 
-func doCmdUp(rept LeadParam, count int, newEql *MarkObject) bool {
-	*newEql = *CurrentFrame.Dot
-	dotLine := CurrentFrame.Dot.Line
+func (e *Editor) doCmdUp(rept LeadParam, count int, newEql *MarkObject) bool {
+	*newEql = *e.CurrentFrame.Dot
+	dotLine := e.CurrentFrame.Dot.Line
 	var lineNr int
 	if !LineToNumber(dotLine, &lineNr) {
 		return false
@@ -216,7 +235,7 @@ func doCmdUp(rept LeadParam, count int, newEql *MarkObject) bool {
 					dotLine = dotLine.BLink
 				}
 			} else {
-				if !LineFromNumber(CurrentFrame, lineNr-count, &dotLine) {
+				if !LineFromNumber(e.CurrentFrame, lineNr-count, &dotLine) {
 					return false
 				}
 			}
@@ -224,24 +243,24 @@ func doCmdUp(rept LeadParam, count int, newEql *MarkObject) bool {
 			return false
 		}
 	case LeadParamPIndef:
-		dotLine = CurrentFrame.FirstGroup.FirstLine
+		dotLine = e.CurrentFrame.FirstGroup.FirstLine
 	}
-	if !MarkCreate(dotLine, CurrentFrame.Dot.Col, &CurrentFrame.Dot) {
+	if !MarkCreate(dotLine, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Dot) {
 		return false
 	}
 	return true
 }
 
-func doCmdReturn(count int, newEql *MarkObject, eopLineNr *int) bool {
-	*newEql = *CurrentFrame.Dot
-	dotLine := CurrentFrame.Dot.Line
-	dotCol := CurrentFrame.Dot.Col
+func (e *Editor) doCmdReturn(count int, newEql *MarkObject, eopLineNr *int) bool {
+	*newEql = *e.CurrentFrame.Dot
+	dotLine := e.CurrentFrame.Dot.Line
+	dotCol := e.CurrentFrame.Dot.Col
 	for counter := 1; counter <= count; counter++ {
-		if TtControlC {
+		if e.TtControlC.Load() {
 			return false
 		}
 		if dotLine.FLink == nil {
-			if !TextRealizeNull(dotLine) {
+			if !e.TextRealizeNull(dotLine) {
 				return false
 			}
 			*eopLineNr++
@@ -253,7 +272,7 @@ func doCmdReturn(count int, newEql *MarkObject, eopLineNr *int) bool {
 		dotCol = TextReturnCol(dotLine, dotCol, false)
 		dotLine = dotLine.FLink
 	}
-	if !MarkCreate(dotLine, dotCol, &CurrentFrame.Dot) {
+	if !MarkCreate(dotLine, dotCol, &e.CurrentFrame.Dot) {
 		return false
 	}
 	return true
@@ -37,6 +37,7 @@ func TestIsArrowCommand(t *testing.T) {
 
 // TestDoCmdLeft tests the left arrow command logic
 func TestDoCmdLeft(t *testing.T) {
+	e := NewEditor()
 	t.Run("MoveLeftByOne", func(t *testing.T) {
 		// Setup a minimal frame
 		frame := &FrameObject{
@@ -45,15 +46,15 @@ func TestDoCmdLeft(t *testing.T) {
 			},
 			MarginLeft: 1,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdLeft(LeadParamNone, 1, &newEql)
+		result := e.doCmdLeft(LeadParamNone, 1, &newEql)
 
 		assert.True(t, result, "Expected move left to succeed")
-		assert.Equal(t, 9, CurrentFrame.Dot.Col, "Expected column to be 9")
+		assert.Equal(t, 9, e.CurrentFrame.Dot.Col, "Expected column to be 9")
 		assert.Equal(t, 10, newEql.Col, "Expected newEql to store old position")
 	})
 
@@ -64,15 +65,15 @@ func TestDoCmdLeft(t *testing.T) {
 			},
 			MarginLeft: 1,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdLeft(LeadParamPInt, 5, &newEql)
+		result := e.doCmdLeft(LeadParamPInt, 5, &newEql)
 
 		assert.True(t, result, "Expected move left to succeed")
-		assert.Equal(t, 5, CurrentFrame.Dot.Col, "Expected column to be 5")
+		assert.Equal(t, 5, e.CurrentFrame.Dot.Col, "Expected column to be 5")
 	})
 
 	t.Run("MoveLeftBeyondBoundary", func(t *testing.T) {
@@ -82,15 +83,15 @@ func TestDoCmdLeft(t *testing.T) {
 			},
 			MarginLeft: 1,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdLeft(LeadParamNone, 5, &newEql)
+		result := e.doCmdLeft(LeadParamNone, 5, &newEql)
 
 		assert.False(t, result, "Expected move left to fail at boundary")
-		assert.Equal(t, 3, CurrentFrame.Dot.Col, "Expected column unchanged")
+		assert.Equal(t, 3, e.CurrentFrame.Dot.Col, "Expected column unchanged")
 	})
 
 	t.Run("MoveLeftToMargin", func(t *testing.T) {
@@ -100,15 +101,15 @@ func TestDoCmdLeft(t *testing.T) {
 			},
 			MarginLeft: 5,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdLeft(LeadParamPIndef, 0, &newEql)
+		result := e.doCmdLeft(LeadParamPIndef, 0, &newEql)
 
 		assert.True(t, result, "Expected move to margin to succeed")
-		assert.Equal(t, 5, CurrentFrame.Dot.Col, "Expected column at left margin")
+		assert.Equal(t, 5, e.CurrentFrame.Dot.Col, "Expected column at left margin")
 	})
 
 	t.Run("AlreadyAtMargin", func(t *testing.T) {
@@ -118,20 +119,21 @@ func TestDoCmdLeft(t *testing.T) {
 			},
 			MarginLeft: 10,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdLeft(LeadParamPIndef, 0, &newEql)
+		result := e.doCmdLeft(LeadParamPIndef, 0, &newEql)
 
 		assert.False(t, result, "Expected move to fail when already beyond margin")
-		assert.Equal(t, 5, CurrentFrame.Dot.Col, "Expected column unchanged")
+		assert.Equal(t, 5, e.CurrentFrame.Dot.Col, "Expected column unchanged")
 	})
 }
 
 // TestDoCmdRight tests the right arrow command logic
 func TestDoCmdRight(t *testing.T) {
+	e := NewEditor()
 	t.Run("MoveRightByOne", func(t *testing.T) {
 		frame := &FrameObject{
 			Dot: &MarkObject{
@@ -139,15 +141,15 @@ func TestDoCmdRight(t *testing.T) {
 			},
 			MarginRight: MaxStrLenP,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdRight(LeadParamNone, 1, &newEql)
+		result := e.doCmdRight(LeadParamNone, 1, &newEql)
 
 		assert.True(t, result, "Expected move right to succeed")
-		assert.Equal(t, 11, CurrentFrame.Dot.Col, "Expected column to be 11")
+		assert.Equal(t, 11, e.CurrentFrame.Dot.Col, "Expected column to be 11")
 		assert.Equal(t, 10, newEql.Col, "Expected newEql to store old position")
 	})
 
@@ -158,15 +160,15 @@ func TestDoCmdRight(t *testing.T) {
 			},
 			MarginRight: MaxStrLenP,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdRight(LeadParamPInt, 5, &newEql)
+		result := e.doCmdRight(LeadParamPInt, 5, &newEql)
 
 		assert.True(t, result, "Expected move right to succeed")
-		assert.Equal(t, 15, CurrentFrame.Dot.Col, "Expected column to be 15")
+		assert.Equal(t, 15, e.CurrentFrame.Dot.Col, "Expected column to be 15")
 	})
 
 	t.Run("MoveRightBeyondBoundary", func(t *testing.T) {
@@ -176,15 +178,15 @@ func TestDoCmdRight(t *testing.T) {
 			},
 			MarginRight: MaxStrLenP,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdRight(LeadParamNone, 5, &newEql)
+		result := e.doCmdRight(LeadParamNone, 5, &newEql)
 
 		assert.False(t, result, "Expected move right to fail at boundary")
-		assert.Equal(t, MaxStrLenP-2, CurrentFrame.Dot.Col, "Expected column unchanged")
+		assert.Equal(t, MaxStrLenP-2, e.CurrentFrame.Dot.Col, "Expected column unchanged")
 	})
 
 	t.Run("MoveRightToMargin", func(t *testing.T) {
@@ -194,15 +196,15 @@ func TestDoCmdRight(t *testing.T) {
 			},
 			MarginRight: 80,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdRight(LeadParamPIndef, 0, &newEql)
+		result := e.doCmdRight(LeadParamPIndef, 0, &newEql)
 
 		assert.True(t, result, "Expected move to margin to succeed")
-		assert.Equal(t, 80, CurrentFrame.Dot.Col, "Expected column at right margin")
+		assert.Equal(t, 80, e.CurrentFrame.Dot.Col, "Expected column at right margin")
 	})
 
 	t.Run("AlreadyBeyondMargin", func(t *testing.T) {
@@ -212,25 +214,26 @@ func TestDoCmdRight(t *testing.T) {
 			},
 			MarginRight: 80,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdRight(LeadParamPIndef, 0, &newEql)
+		result := e.doCmdRight(LeadParamPIndef, 0, &newEql)
 
 		assert.False(t, result, "Expected move to fail when already beyond margin")
-		assert.Equal(t, 90, CurrentFrame.Dot.Col, "Expected column unchanged")
+		assert.Equal(t, 90, e.CurrentFrame.Dot.Col, "Expected column unchanged")
 	})
 }
 
 // TestDoCmdTabBacktab tests tab and backtab functionality
 func TestDoCmdTabBacktab(t *testing.T) {
+	e := NewEditor()
 	t.Run("TabToNextStop", func(t *testing.T) {
 		var tabStops TabArray
-		tabStops[10] = true
-		tabStops[20] = true
-		tabStops[30] = true
+		tabStops.Set(10, true)
+		tabStops.Set(20, true)
+		tabStops.Set(30, true)
 
 		frame := &FrameObject{
 			Dot: &MarkObject{
@@ -240,23 +243,23 @@ func TestDoCmdTabBacktab(t *testing.T) {
 			MarginLeft:  1,
 			MarginRight: MaxStrLenP,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdTabBacktab(1, 1, &newEql)
+		result := e.doCmdTabBacktab(1, 1, &newEql)
 
 		assert.True(t, result, "Expected tab to succeed")
-		assert.Equal(t, 10, CurrentFrame.Dot.Col, "Expected column at tab stop 10")
+		assert.Equal(t, 10, e.CurrentFrame.Dot.Col, "Expected column at tab stop 10")
 		assert.Equal(t, 5, newEql.Col, "Expected newEql to store old position")
 	})
 
 	t.Run("TabMultipleStops", func(t *testing.T) {
 		var tabStops TabArray
-		tabStops[10] = true
-		tabStops[20] = true
-		tabStops[30] = true
+		tabStops.Set(10, true)
+		tabStops.Set(20, true)
+		tabStops.Set(30, true)
 
 		frame := &FrameObject{
 			Dot: &MarkObject{
@@ -266,22 +269,22 @@ func TestDoCmdTabBacktab(t *testing.T) {
 			MarginLeft:  1,
 			MarginRight: MaxStrLenP,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdTabBacktab(1, 2, &newEql)
+		result := e.doCmdTabBacktab(1, 2, &newEql)
 
 		assert.True(t, result, "Expected tab to succeed")
-		assert.Equal(t, 20, CurrentFrame.Dot.Col, "Expected column at tab stop 20")
+		assert.Equal(t, 20, e.CurrentFrame.Dot.Col, "Expected column at tab stop 20")
 	})
 
 	t.Run("BacktabToPreviousStop", func(t *testing.T) {
 		var tabStops TabArray
-		tabStops[10] = true
-		tabStops[20] = true
-		tabStops[30] = true
+		tabStops.Set(10, true)
+		tabStops.Set(20, true)
+		tabStops.Set(30, true)
 
 		frame := &FrameObject{
 			Dot: &MarkObject{
@@ -291,15 +294,15 @@ func TestDoCmdTabBacktab(t *testing.T) {
 			MarginLeft:  1,
 			MarginRight: MaxStrLenP,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdTabBacktab(-1, 1, &newEql)
+		result := e.doCmdTabBacktab(-1, 1, &newEql)
 
 		assert.True(t, result, "Expected backtab to succeed")
-		assert.Equal(t, 20, CurrentFrame.Dot.Col, "Expected column at tab stop 20")
+		assert.Equal(t, 20, e.CurrentFrame.Dot.Col, "Expected column at tab stop 20")
 	})
 
 	t.Run("TabToMarginLeft", func(t *testing.T) {
@@ -314,15 +317,15 @@ func TestDoCmdTabBacktab(t *testing.T) {
 			MarginLeft:  15,
 			MarginRight: MaxStrLenP,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdTabBacktab(1, 1, &newEql)
+		result := e.doCmdTabBacktab(1, 1, &newEql)
 
 		assert.True(t, result, "Expected tab to succeed")
-		assert.Equal(t, 15, CurrentFrame.Dot.Col, "Expected column at left margin")
+		assert.Equal(t, 15, e.CurrentFrame.Dot.Col, "Expected column at left margin")
 	})
 
 	t.Run("TabToMarginRight", func(t *testing.T) {
@@ -337,15 +340,15 @@ func TestDoCmdTabBacktab(t *testing.T) {
 			MarginLeft:  1,
 			MarginRight: 80,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdTabBacktab(1, 1, &newEql)
+		result := e.doCmdTabBacktab(1, 1, &newEql)
 
 		assert.True(t, result, "Expected tab to succeed")
-		assert.Equal(t, 80, CurrentFrame.Dot.Col, "Expected column at right margin")
+		assert.Equal(t, 80, e.CurrentFrame.Dot.Col, "Expected column at right margin")
 	})
 
 	t.Run("TabBeyondBoundary", func(t *testing.T) {
@@ -360,15 +363,15 @@ func TestDoCmdTabBacktab(t *testing.T) {
 			MarginLeft:  1,
 			MarginRight: MaxStrLenP + 10, // Beyond boundary
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdTabBacktab(1, 1, &newEql)
+		result := e.doCmdTabBacktab(1, 1, &newEql)
 
 		assert.False(t, result, "Expected tab to fail at boundary")
-		assert.Equal(t, MaxStrLenP-2, CurrentFrame.Dot.Col, "Expected column unchanged")
+		assert.Equal(t, MaxStrLenP-2, e.CurrentFrame.Dot.Col, "Expected column unchanged")
 	})
 
 	t.Run("BacktabBeyondBoundary", func(t *testing.T) {
@@ -383,20 +386,95 @@ func TestDoCmdTabBacktab(t *testing.T) {
 			MarginLeft:  -5, // Beyond boundary
 			MarginRight: MaxStrLenP,
 		}
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
-		defer func() { CurrentFrame = oldFrame }()
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
 
 		var newEql MarkObject
-		result := doCmdTabBacktab(-1, 1, &newEql)
+		result := e.doCmdTabBacktab(-1, 1, &newEql)
 
 		assert.False(t, result, "Expected backtab to fail at boundary")
-		assert.Equal(t, 2, CurrentFrame.Dot.Col, "Expected column unchanged")
+		assert.Equal(t, 2, e.CurrentFrame.Dot.Col, "Expected column unchanged")
 	})
+
+	t.Run("TabPastLastStop", func(t *testing.T) {
+		var tabStops TabArray
+		tabStops.Set(10, true)
+
+		frame := &FrameObject{
+			Dot: &MarkObject{
+				Col: 5,
+			},
+			TabStops:    tabStops,
+			MarginLeft:  1,
+			MarginRight: 100000,
+		}
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
+
+		// Only the right margin is left after the last stop
+		var newEql MarkObject
+		assert.True(t, e.doCmdTabBacktab(1, 2, &newEql))
+		assert.Equal(t, 100000, e.CurrentFrame.Dot.Col)
+		assert.False(t, e.doCmdTabBacktab(1, 1, &newEql))
+		assert.Equal(t, 100000, e.CurrentFrame.Dot.Col)
+
+		e.CurrentFrame.Dot.Col = MaxStrLen
+		assert.True(t, e.doCmdTabBacktab(-1, 2, &newEql))
+		assert.Equal(t, 10, e.CurrentFrame.Dot.Col)
+	})
+
+	t.Run("TabRegularWidth", func(t *testing.T) {
+		tabStops := TabArray{Every: 8}
+		tabStops.Set(17, false)
+
+		frame := &FrameObject{
+			Dot: &MarkObject{
+				Col: 1000003,
+			},
+			TabStops:    tabStops,
+			MarginLeft:  1,
+			MarginRight: 80,
+		}
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
+		defer func() { e.CurrentFrame = oldFrame }()
+
+		var newEql MarkObject
+		assert.True(t, e.doCmdTabBacktab(1, 1, &newEql))
+		assert.Equal(t, 1000009, e.CurrentFrame.Dot.Col)
+
+		e.CurrentFrame.Dot.Col = 20
+		assert.True(t, e.doCmdTabBacktab(-1, 1, &newEql))
+		assert.Equal(t, 9, e.CurrentFrame.Dot.Col, "Expected the cleared stop to be skipped")
+	})
+}
+
+// TestTabArray tests setting and copying tab stops
+func TestTabArray(t *testing.T) {
+	tabs := DefaultTabStops.Clone()
+	assert.True(t, tabs.IsStop(1))
+	assert.True(t, tabs.IsStop(9))
+	assert.False(t, tabs.IsStop(10))
+	assert.True(t, tabs.IsStop(800001))
+
+	tabs.Set(9, false)
+	tabs.Set(12, true)
+	assert.False(t, tabs.IsStop(9))
+	assert.True(t, tabs.IsStop(12))
+	assert.Equal(t, 12, tabs.Last())
+
+	// Changing a copy leaves the original alone
+	assert.True(t, DefaultTabStops.IsStop(9))
+	other := tabs.Clone()
+	other.Set(12, false)
+	assert.True(t, tabs.IsStop(12))
 }
 
 // TestDoCmdHome tests the home command
 func TestDoCmdHome(t *testing.T) {
+	e := NewEditor()
 	t.Run("HomeInScreenFrame", func(t *testing.T) {
 		topLine := &LineHdrObject{}
 
@@ -408,26 +486,26 @@ func TestDoCmdHome(t *testing.T) {
 			ScrOffset: 10,
 		}
 
-		oldFrame := CurrentFrame
-		oldScrFrame := ScrFrame
-		oldScrTopLine := ScrTopLine
+		oldFrame := e.CurrentFrame
+		oldScrFrame := e.ScrFrame
+		oldScrTopLine := e.ScrTopLine
 
-		CurrentFrame = frame
-		ScrFrame = frame
-		ScrTopLine = topLine
+		e.CurrentFrame = frame
+		e.ScrFrame = frame
+		e.ScrTopLine = topLine
 
 		defer func() {
-			CurrentFrame = oldFrame
-			ScrFrame = oldScrFrame
-			ScrTopLine = oldScrTopLine
+			e.CurrentFrame = oldFrame
+			e.ScrFrame = oldScrFrame
+			e.ScrTopLine = oldScrTopLine
 		}()
 
 		var newEql MarkObject
-		result := doCmdHome(&newEql)
+		result := e.doCmdHome(&newEql)
 
 		assert.True(t, result, "Expected home to succeed")
 		assert.Equal(t, 50, newEql.Col, "Expected newEql to store old column")
-		assert.Equal(t, 11, CurrentFrame.Dot.Col, "Expected column to be 1 after home")
+		assert.Equal(t, 11, e.CurrentFrame.Dot.Col, "Expected column to be 1 after home")
 	})
 
 	t.Run("HomeInNonScreenFrame", func(t *testing.T) {
@@ -440,19 +518,19 @@ func TestDoCmdHome(t *testing.T) {
 
 		otherFrame := &FrameObject{}
 
-		oldFrame := CurrentFrame
-		oldScrFrame := ScrFrame
+		oldFrame := e.CurrentFrame
+		oldScrFrame := e.ScrFrame
 
-		CurrentFrame = frame
-		ScrFrame = otherFrame // Different from CurrentFrame
+		e.CurrentFrame = frame
+		e.ScrFrame = otherFrame // Different from CurrentFrame
 
 		defer func() {
-			CurrentFrame = oldFrame
-			ScrFrame = oldScrFrame
+			e.CurrentFrame = oldFrame
+			e.ScrFrame = oldScrFrame
 		}()
 
 		var newEql MarkObject
-		result := doCmdHome(&newEql)
+		result := e.doCmdHome(&newEql)
 
 		assert.True(t, result, "Expected home to succeed")
 		assert.Equal(t, 50, newEql.Col, "Expected newEql to store old column")
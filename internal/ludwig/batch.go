@@ -0,0 +1,64 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         BATCH
+//
+// Description:  Running Ludwig without a terminal, for programs and
+//               scripts that edit text with Ludwig's commands.
+
+package ludwig
+
+import "strings"
+
+// BatchStart gets an editor going without a terminal, with the commands of
+// the command set chosen in FileData, the special frames, and the frame
+// the user edits as the current frame.
+func (e *Editor) BatchStart() bool {
+	e.LoadCommandTable(e.FileData.OldCmds)
+	e.LudwigMode = LudwigBatch
+
+	// The size VduInit gives the terminal when there is none
+	e.TerminalInfo = TerminalInfoType{Width: 80, Height: 4}
+	e.ScrMsgRow = e.TerminalInfo.Height + 1
+
+	if !e.FrameCreateSpecial() {
+		return false
+	}
+	return e.FrameEdit(DefaultFrameName)
+}
+
+// BatchExecute compiles and runs a string of commands, which may be
+// several lines long, in the current frame.  This is what batch mode does
+// with the commands it reads.
+func (e *Editor) BatchExecute(commands string) bool {
+	var cmdSpan SpanObject
+	cmdSpan.Name = defaultSpanName
+	cmdSpan.MarkOne = &MarkObject{Col: 1}
+	cmdSpan.MarkTwo = &MarkObject{}
+
+	cmdFile := &FileObject{Valid: true, Reader: strings.NewReader(commands)}
+	var count int
+	if !e.FileRead(cmdFile, MaxInt, true, &cmdSpan.MarkOne.Line, &cmdSpan.MarkTwo.Line, &count) {
+		return false
+	}
+	if cmdSpan.MarkOne.Line == nil {
+		return true
+	}
+	defer LinesDestroy(&cmdSpan.MarkOne.Line, &cmdSpan.MarkTwo.Line)
+	cmdSpan.MarkTwo.Col = cmdSpan.MarkTwo.Line.Used + 1
+
+	e.UndoCheckpoint()
+	result := e.CodeCompile(&cmdSpan, true) &&
+		e.CodeInterpret(LeadParamNone, 1, cmdSpan.Code, true)
+	e.CodeDiscard(&cmdSpan.Code)
+	e.ExitAbort = false
+	e.TtControlC.Store(false)
+	return result
+}
@@ -0,0 +1,158 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         BLOCK
+//
+// Description:  The rectangular block commands.  A block is a span whose
+//               two marks are taken as opposite corners of a rectangle of
+//               columns, rather than as the two ends of a stream of text.
+
+package ludwig
+
+// blockColumns returns the first column of a block, and the column just
+// past its right hand edge
+func blockColumns(block *SpanObject) (int, int) {
+	colOne := block.MarkOne.Col
+	colTwo := block.MarkTwo.Col
+	if colOne > colTwo {
+		return colTwo, colOne
+	}
+	return colOne, colTwo
+}
+
+// blockRows returns the text of each row of a block, short lines are
+// padded with spaces to the width of the block
+func blockRows(block *SpanObject, colOne int, width int) []*StrObject {
+	rows := []*StrObject{}
+	line := block.MarkOne.Line
+	for {
+		row := NewBlankStrObject(width)
+		if colOne <= line.Used {
+			textLen := min(width, line.Used+1-colOne)
+			ChFillCopy(line.Str, colOne, textLen, row, 1, width, ' ')
+		}
+		rows = append(rows, row)
+		if line == block.MarkTwo.Line || line.FLink == nil {
+			break
+		}
+		line = line.FLink
+	}
+	return rows
+}
+
+// BlockMove copies or transfers a block to Dot.  Row N of the block is
+// inserted at Dot's column on the Nth line from Dot, lines are created at
+// the end of the frame as needed.  Equals is left at the top left corner of
+// the new block, and Dot just past its bottom right corner.
+func (e *Editor) BlockMove(copy bool, count int, block *SpanObject) bool {
+	result := false
+	var rowMark *MarkObject
+	var endMark *MarkObject
+	var line *LineHdrObject
+	var topLine *LineHdrObject
+
+	colOne, colTwo := blockColumns(block)
+	width := colTwo - colOne
+	if width == 0 || count == 0 {
+		return true
+	}
+	if width*count > MaxStrLen {
+		e.ScreenMessage(MsgNoRoomOnLine)
+		return false
+	}
+	dstCol := e.CurrentFrame.Dot.Col
+	rows := blockRows(block, colOne, width)
+
+	if !copy {
+		srcFrame := block.MarkOne.Line.Group.Frame
+		if srcFrame == e.CurrentFrame && dstCol > colOne && dstCol < colTwo {
+			var dotNr, firstNr, lastNr int
+			if !LineToNumber(e.CurrentFrame.Dot.Line, &dotNr) ||
+				!LineToNumber(block.MarkOne.Line, &firstNr) ||
+				!LineToNumber(block.MarkTwo.Line, &lastNr) {
+				goto l99
+			}
+			if dotNr >= firstNr && dotNr <= lastNr {
+				e.ScreenMessage(MsgDestInsideBlock)
+				goto l99
+			}
+		}
+
+		// Remove the block from its old position first, Dot is a mark so it
+		// stays with its text if it is on one of the rows.
+		line = block.MarkOne.Line
+		for {
+			if line.FLink == nil {
+				break
+			}
+			if !MarkCreate(line, colOne, &rowMark) {
+				goto l99
+			}
+			if !MarkCreate(line, colTwo, &endMark) {
+				goto l99
+			}
+			if !e.TextRemove(rowMark, endMark) {
+				goto l99
+			}
+			if line == block.MarkTwo.Line {
+				break
+			}
+			line = line.FLink
+		}
+		srcFrame.TextModified = true
+		if !MarkCreate(line, colOne, &srcFrame.Marks[MarkModified]) {
+			goto l99
+		}
+		dstCol = e.CurrentFrame.Dot.Col
+	}
+
+	line = e.CurrentFrame.Dot.Line
+	for _, row := range rows {
+		if !MarkCreate(line, dstCol, &rowMark) {
+			goto l99
+		}
+		if !e.TextInsert(true, count, row, width, rowMark) {
+			e.ScreenMessage(MsgNoRoomOnLine)
+			goto l99
+		}
+		if topLine == nil {
+			topLine = rowMark.Line
+		}
+		line = rowMark.Line.FLink
+	}
+
+	if !MarkCreate(topLine, dstCol, &e.CurrentFrame.Marks[MarkEquals]) {
+		goto l99
+	}
+	if !MarkCreate(rowMark.Line, dstCol+width*count, &e.CurrentFrame.Dot) {
+		goto l99
+	}
+	e.CurrentFrame.TextModified = true
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Marks[MarkModified]) {
+		goto l99
+	}
+	if !copy {
+		if !MarkCreate(topLine, dstCol, &block.MarkOne) {
+			goto l99
+		}
+		if !MarkCreate(rowMark.Line, dstCol+width, &block.MarkTwo) {
+			goto l99
+		}
+	}
+	result = true
+l99:
+	if rowMark != nil {
+		MarkDestroy(&rowMark)
+	}
+	if endMark != nil {
+		MarkDestroy(&endMark)
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+// Tests for block.go functions
+
+package ludwig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestBlock defines a block with corners at the given line numbers and
+// columns of the current frame
+func setupTestBlock(t *testing.T, e *Editor, lineOne, colOne, lineTwo, colTwo int) *SpanObject {
+	var line *LineHdrObject
+	var markOne *MarkObject
+	var markTwo *MarkObject
+	require.True(t, LineFromNumber(e.CurrentFrame, lineOne, &line))
+	require.True(t, MarkCreate(line, colOne, &markOne))
+	require.True(t, LineFromNumber(e.CurrentFrame, lineTwo, &line))
+	require.True(t, MarkCreate(line, colTwo, &markTwo))
+	require.True(t, e.SpanCreate("blk", markOne, markTwo))
+	MarkDestroy(&markOne)
+	MarkDestroy(&markTwo)
+
+	var block *SpanObject
+	var oldSpan *SpanObject
+	require.True(t, e.SpanFind("blk", &block, &oldSpan))
+	block.Block = true
+	return block
+}
+
+// setDot moves Dot of the current frame to a line number and column
+func setDot(t *testing.T, e *Editor, lineNr, col int) {
+	var line *LineHdrObject
+	require.True(t, LineFromNumber(e.CurrentFrame, lineNr, &line))
+	require.True(t, MarkCreate(line, col, &e.CurrentFrame.Dot))
+}
+
+func TestBlockMoveCopy(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "ab1234cd", "ef56", "gh7890ij")
+
+	// Corners may be given in either order
+	block := setupTestBlock(t, e, 1, 7, 3, 4)
+	setDot(t, e, 2, 10)
+	require.True(t, e.BlockMove(true, 1, block))
+	assert.Equal(t, []string{"ab1234cd", "ef56     234", "gh7890ij 6", "         890"}, frameText(frame))
+
+	require.NotNil(t, frame.Marks[MarkEquals])
+	assert.Equal(t, 10, frame.Marks[MarkEquals].Col)
+	assert.Equal(t, "         890", frame.Dot.Line.Str.Slice(1, frame.Dot.Line.Used))
+	assert.Equal(t, 13, frame.Dot.Col)
+	assert.True(t, frame.TextModified)
+}
+
+func TestBlockMoveCopyCount(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "abcd", "efgh")
+
+	block := setupTestBlock(t, e, 1, 2, 2, 4)
+	setDot(t, e, 1, 1)
+	require.True(t, e.BlockMove(true, 2, block))
+	assert.Equal(t, []string{"bcbcabcd", "fgfgefgh"}, frameText(frame))
+	assert.Equal(t, 5, frame.Dot.Col)
+}
+
+func TestBlockMoveTransfer(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "ab1234cd", "ef56", "gh7890ij")
+
+	block := setupTestBlock(t, e, 1, 4, 3, 7)
+	setDot(t, e, 1, 1)
+	require.True(t, e.BlockMove(false, 1, block))
+	assert.Equal(t, []string{"234ab1cd", "6  ef5", "890gh7ij"}, frameText(frame))
+
+	// The block now names the text at its new position
+	assert.Equal(t, 1, block.MarkOne.Col)
+	assert.Equal(t, 4, block.MarkTwo.Col)
+	assert.Equal(t, "890gh7ij", block.MarkTwo.Line.Str.Slice(1, block.MarkTwo.Line.Used))
+}
+
+func TestBlockMoveTransferDestInside(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "ab1234cd", "ef56")
+
+	block := setupTestBlock(t, e, 1, 4, 2, 7)
+	setDot(t, e, 2, 5)
+	assert.False(t, e.BlockMove(false, 1, block))
+	assert.Equal(t, []string{"ab1234cd", "ef56"}, frameText(frame))
+}
+
+func TestBlockMoveUndo(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "ab1234cd", "ef56")
+
+	block := setupTestBlock(t, e, 1, 4, 2, 7)
+	setDot(t, e, 2, 1)
+	e.UndoCheckpoint()
+	require.True(t, e.BlockMove(false, 1, block))
+	assert.Equal(t, []string{"ab1cd", "234ef5", "6"}, frameText(frame))
+	assert.True(t, e.UserUndo(LeadParamNone, 1))
+	assert.Equal(t, []string{"ab1234cd", "ef56"}, frameText(frame))
+}
+
+func TestBlockMoveCopyTooWide(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "abcd", "efgh")
+
+	// A count that would make the lines too long fails before copying
+	block := setupTestBlock(t, e, 1, 2, 2, 4)
+	setDot(t, e, 1, 1)
+	assert.False(t, e.BlockMove(true, MaxInt, block))
+	assert.Equal(t, []string{"abcd", "efgh"}, frameText(frame))
+}
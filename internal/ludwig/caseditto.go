@@ -19,20 +19,20 @@ import (
 )
 
 // CaseDittoCommand handles case change and ditto commands
-func CaseDittoCommand(command Commands, rept LeadParam, count int, fromSpan bool) bool {
+func (e *Editor) CaseDittoCommand(command Commands, rept LeadParam, count int, fromSpan bool) bool {
 	cmdStatus := false
 	insert := (command == CmdDittoUp || command == CmdDittoDown) &&
-		((EditMode == ModeInsert) ||
-			((EditMode == ModeCommand) && (PreviousMode == ModeInsert)))
+		((e.EditMode == ModeInsert) ||
+			((e.EditMode == ModeCommand) && (e.PreviousMode == ModeInsert)))
 
 	// Remember current line
-	oldDotCol := CurrentFrame.Dot.Col
+	oldDotCol := e.CurrentFrame.Dot.Col
 
 	oldStr := NewStrObjectCopy(
-		CurrentFrame.Dot.Line.Str,
+		e.CurrentFrame.Dot.Line.Str,
 		1,
-		CurrentFrame.Dot.Line.Used,
-		CurrentFrame.Dot.Line.Used,
+		e.CurrentFrame.Dot.Line.Used,
+		e.CurrentFrame.Dot.Line.Used,
 	)
 
 	commandSet := big.NewInt(0)
@@ -43,11 +43,11 @@ func CaseDittoCommand(command Commands, rept LeadParam, count int, fromSpan bool
 		commandSet.SetBit(commandSet, int(CmdCaseUp), 1)
 		commandSet.SetBit(commandSet, int(CmdCaseLow), 1)
 		commandSet.SetBit(commandSet, int(CmdCaseEdit), 1)
-		otherLine = CurrentFrame.Dot.Line
+		otherLine = e.CurrentFrame.Dot.Line
 	case CmdDittoUp, CmdDittoDown:
 		if insert && (rept == LeadParamMinus || rept == LeadParamNInt ||
 			rept == LeadParamNIndef) {
-			ScreenMessage(MsgNotAllowedInInsertMode)
+			e.ScreenMessage(MsgNotAllowedInInsertMode)
 			return false
 		}
 		commandSet.SetBit(commandSet, int(CmdDittoUp), 1)
@@ -61,40 +61,40 @@ func CaseDittoCommand(command Commands, rept LeadParam, count int, fromSpan bool
 	for {
 		switch command {
 		case CmdDittoUp:
-			otherLine = CurrentFrame.Dot.Line.BLink
+			otherLine = e.CurrentFrame.Dot.Line.BLink
 		case CmdDittoDown:
-			otherLine = CurrentFrame.Dot.Line.FLink
+			otherLine = e.CurrentFrame.Dot.Line.FLink
 		}
 
 		cmdValid := true
 		if otherLine != nil {
 			switch rept {
 			case LeadParamNone, LeadParamPlus, LeadParamPInt:
-				if (count != 0) && (CurrentFrame.Dot.Col+count > otherLine.Used+1) {
+				if (count != 0) && (e.CurrentFrame.Dot.Col+count > otherLine.Used+1) {
 					cmdValid = false
 				}
-				firstCol = CurrentFrame.Dot.Col
-				newCol = CurrentFrame.Dot.Col + count
+				firstCol = e.CurrentFrame.Dot.Col
+				newCol = e.CurrentFrame.Dot.Col + count
 
 			case LeadParamPIndef:
-				count = otherLine.Used + 1 - CurrentFrame.Dot.Col
+				count = otherLine.Used + 1 - e.CurrentFrame.Dot.Col
 				if count < 0 {
 					cmdValid = false
 				}
-				firstCol = CurrentFrame.Dot.Col
+				firstCol = e.CurrentFrame.Dot.Col
 				newCol = otherLine.Used + 1
 
 			case LeadParamMinus, LeadParamNInt:
 				count = -count
-				if count >= CurrentFrame.Dot.Col {
+				if count >= e.CurrentFrame.Dot.Col {
 					cmdValid = false
 				} else {
-					firstCol = CurrentFrame.Dot.Col - count
+					firstCol = e.CurrentFrame.Dot.Col - count
 				}
 				newCol = firstCol
 
 			case LeadParamNIndef:
-				count = CurrentFrame.Dot.Col - 1
+				count = e.CurrentFrame.Dot.Col - 1
 				firstCol = 1
 				newCol = 1
 			}
@@ -120,14 +120,14 @@ func CaseDittoCommand(command Commands, rept LeadParam, count int, fromSpan bool
 				newStr.ApplyN(ChToLower, count, 1)
 
 			case CmdCaseEdit:
-				var ch byte
+				var ch rune
 				if (1 < firstCol) && (firstCol <= otherLine.Used) {
 					ch = otherLine.Str.Get(firstCol - 1)
 				} else {
 					ch = ' '
 				}
 				for j := 1; j <= count; j++ {
-					if ChIsLetter(rune(ch)) {
+					if ChIsLetter(ch) {
 						ch = ChToLower(newStr.Get(j))
 					} else {
 						ch = ChToUpper(newStr.Get(j))
@@ -139,18 +139,18 @@ func CaseDittoCommand(command Commands, rept LeadParam, count int, fromSpan bool
 				// No massaging required
 			}
 
-			CurrentFrame.Dot.Col = firstCol
+			e.CurrentFrame.Dot.Col = firstCol
 			if insert {
-				if !TextInsert(true, 1, newStr, count, CurrentFrame.Dot) {
+				if !e.TextInsert(true, 1, newStr, count, e.CurrentFrame.Dot) {
 					goto l9
 				}
 			} else {
-				if !TextOvertype(true, 1, newStr, count, CurrentFrame.Dot) {
+				if !e.TextOvertype(true, 1, newStr, count, e.CurrentFrame.Dot) {
 					goto l9
 				}
 			}
 			// Reposition dot
-			CurrentFrame.Dot.Col = newCol
+			e.CurrentFrame.Dot.Col = newCol
 			cmdStatus = true
 		}
 
@@ -158,12 +158,12 @@ func CaseDittoCommand(command Commands, rept LeadParam, count int, fromSpan bool
 			goto l9
 		}
 		if cmdValid {
-			ScreenFixup()
+			e.ScreenFixup()
 		} else {
-			VduBeep()
+			e.VduBeep()
 		}
-		key = VduGetKey()
-		if TtControlC {
+		key = e.VduGetKey()
+		if e.TtControlC.Load() {
 			goto l9
 		}
 
@@ -179,7 +179,7 @@ func CaseDittoCommand(command Commands, rept LeadParam, count int, fromSpan bool
 		}
 
 		if command == CmdDittoUp || command == CmdDittoDown {
-			command = Lookup[keyUp].Command
+			command = e.Lookup[keyUp].Command
 		} else if keyUp == 'E' {
 			command = CmdCaseEdit
 		} else if keyUp == 'L' {
@@ -194,25 +194,25 @@ func CaseDittoCommand(command Commands, rept LeadParam, count int, fromSpan bool
 			break
 		}
 	}
-	VduTakeBackKey(key)
+	e.VduTakeBackKey(key)
 
 l9:
-	if TtControlC {
+	if e.TtControlC.Load() {
 		cmdStatus = false
-		CurrentFrame.Dot.Col = 1
-		TextOvertype(false, 1, oldStr, oldStr.Len(), CurrentFrame.Dot)
-		CurrentFrame.Dot.Col = oldDotCol
+		e.CurrentFrame.Dot.Col = 1
+		e.TextOvertype(false, 1, oldStr, oldStr.Len(), e.CurrentFrame.Dot)
+		e.CurrentFrame.Dot.Col = oldDotCol
 	} else if cmdStatus {
-		CurrentFrame.TextModified = true
+		e.CurrentFrame.TextModified = true
 		MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkModified],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkModified],
 		)
 		MarkCreate(
-			CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Line,
 			oldDotCol,
-			&CurrentFrame.Marks[MarkEquals],
+			&e.CurrentFrame.Marks[MarkEquals],
 		)
 	}
 	return cmdStatus || !fromSpan
@@ -38,7 +38,7 @@ func setupTestFrame(lineCount int) (*FrameObject, []*LineHdrObject) {
 			OffsetNr: i,
 			Used:     0,
 			ScrRowNr: 0, // Set to 0 to disable screen updates
-			Str:      NewBlankStrObject(MaxStrLen),
+			Str:      NewBlankStrObject(testStrLen),
 			Marks:    make([]*MarkObject, 0),
 		}
 	}
@@ -106,85 +106,74 @@ func getLineContent(line *LineHdrObject) string {
 
 // TestCaseDittoCommand tests the CaseDittoCommand function
 func TestCaseDittoCommand(t *testing.T) {
-	// Save and restore global state
-	oldCurrentFrame := CurrentFrame
-	oldEditMode := EditMode
-	oldPreviousMode := PreviousMode
-	oldTtControlC := TtControlC
-
-	defer func() {
-		CurrentFrame = oldCurrentFrame
-		EditMode = oldEditMode
-		PreviousMode = oldPreviousMode
-		TtControlC = oldTtControlC
-	}()
+	e := NewEditor()
 
 	// Note: Most tests use fromSpan=false to test the validation logic only
 	// Full integration tests would require VDU/screen initialization
 
 	t.Run("InsertMode_NotAllowedWithNegativeParams_Minus", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeInsert
-		PreviousMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeInsert
+		e.PreviousMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "test line")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
 
 		// Try DittoUp with LeadParamMinus in insert mode - should be rejected
-		result := CaseDittoCommand(CmdDittoUp, LeadParamMinus, -5, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamMinus, -5, true)
 		assert.False(t, result, "Should reject LeadParamMinus in insert mode")
 	})
 
 	t.Run("InsertMode_NotAllowedWithNInt", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeInsert
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeInsert
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "test line")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdDittoDown, LeadParamNInt, -5, true)
+		result := e.CaseDittoCommand(CmdDittoDown, LeadParamNInt, -5, true)
 		assert.False(t, result, "Should reject LeadParamNInt in insert mode")
 	})
 
 	t.Run("InsertMode_NotAllowedWithNIndef", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeInsert
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeInsert
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "test line")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdDittoUp, LeadParamNIndef, 0, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamNIndef, 0, true)
 		assert.False(t, result, "Should reject LeadParamNIndef in insert mode")
 	})
 
 	t.Run("InsertMode_AllowedWithPositiveParams", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeInsert
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeInsert
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "UPPER LINE")
 		setLineContent(lines[1], "lower")
 		frame.Dot.Line = lines[1]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdDittoUp, LeadParamNIndef, 0, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamNIndef, 0, true)
 		assert.False(t, result, "Should reject LeadParamNIndef in insert mode")
 	})
 
 	t.Run("DittoInsertModeDetection", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "UPPER")
 		setLineContent(lines[1], "lower")
@@ -192,42 +181,34 @@ func TestCaseDittoCommand(t *testing.T) {
 		frame.Dot.Col = 1
 
 		// Test insert mode detection: EditMode == ModeInsert
-		EditMode = ModeInsert
-		PreviousMode = ModeCommand
-		result1 := CaseDittoCommand(CmdDittoUp, LeadParamMinus, -1, true)
+		e.EditMode = ModeInsert
+		e.PreviousMode = ModeCommand
+		result1 := e.CaseDittoCommand(CmdDittoUp, LeadParamMinus, -1, true)
 		assert.False(t, result1, "Should reject in pure insert mode")
 
 		// Test insert mode detection: EditMode == ModeCommand && PreviousMode == ModeInsert
-		EditMode = ModeCommand
-		PreviousMode = ModeInsert
-		result2 := CaseDittoCommand(CmdDittoDown, LeadParamNInt, -1, true)
+		e.EditMode = ModeCommand
+		e.PreviousMode = ModeInsert
+		result2 := e.CaseDittoCommand(CmdDittoDown, LeadParamNInt, -1, true)
 		assert.False(t, result2, "Should reject when previous mode was insert")
 	})
 }
 
 // TestCaseDittoCommand_CaseCommands tests the case conversion commands
 func TestCaseDittoCommand_CaseCommands(t *testing.T) {
-	oldCurrentFrame := CurrentFrame
-	oldEditMode := EditMode
-	oldTtControlC := TtControlC
-
-	defer func() {
-		CurrentFrame = oldCurrentFrame
-		EditMode = oldEditMode
-		TtControlC = oldTtControlC
-	}()
+	e := NewEditor()
 
 	t.Run("CaseUp_CommandSetup", func(t *testing.T) {
 		frame, lines := setupTestFrame(1)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "hello world")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdCaseUp, LeadParamNone, 5, true)
+		result := e.CaseDittoCommand(CmdCaseUp, LeadParamNone, 5, true)
 		assert.True(t, result, "CaseUp should succeed")
 
 		// Verify the first 5 chars were converted to uppercase
@@ -238,15 +219,15 @@ func TestCaseDittoCommand_CaseCommands(t *testing.T) {
 
 	t.Run("CaseLow_CommandSetup", func(t *testing.T) {
 		frame, lines := setupTestFrame(1)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "HELLO WORLD")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdCaseLow, LeadParamNone, 5, true)
+		result := e.CaseDittoCommand(CmdCaseLow, LeadParamNone, 5, true)
 		assert.True(t, result, "CaseLow should succeed")
 
 		// Verify the first 5 chars were converted to lowercase
@@ -257,15 +238,15 @@ func TestCaseDittoCommand_CaseCommands(t *testing.T) {
 
 	t.Run("CaseEdit_CommandSetup", func(t *testing.T) {
 		frame, lines := setupTestFrame(1)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "HeLLo WoRLd")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdCaseEdit, LeadParamNone, 5, true)
+		result := e.CaseDittoCommand(CmdCaseEdit, LeadParamNone, 5, true)
 		assert.True(t, result, "CaseEdit should succeed")
 
 		// CaseEdit: uppercase after non-letter, lowercase after letter
@@ -275,15 +256,15 @@ func TestCaseDittoCommand_CaseCommands(t *testing.T) {
 
 	t.Run("CaseUp_WithCount", func(t *testing.T) {
 		frame, lines := setupTestFrame(1)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "lowercase text here")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdCaseUp, LeadParamPInt, 10, true)
+		result := e.CaseDittoCommand(CmdCaseUp, LeadParamPInt, 10, true)
 		assert.True(t, result, "CaseUp should succeed")
 
 		content := getLineContent(lines[0])
@@ -292,15 +273,15 @@ func TestCaseDittoCommand_CaseCommands(t *testing.T) {
 
 	t.Run("CaseLow_WithOffset", func(t *testing.T) {
 		frame, lines := setupTestFrame(1)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "UPPERCASE TEXT")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 5 // Start from column 5
 
-		result := CaseDittoCommand(CmdCaseLow, LeadParamNone, 4, true)
+		result := e.CaseDittoCommand(CmdCaseLow, LeadParamNone, 4, true)
 		assert.True(t, result, "CaseLow should succeed")
 
 		content := getLineContent(lines[0])
@@ -310,15 +291,15 @@ func TestCaseDittoCommand_CaseCommands(t *testing.T) {
 
 	t.Run("CaseEdit_AlternatingCase", func(t *testing.T) {
 		frame, lines := setupTestFrame(1)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "MiXeD CaSe text")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdCaseEdit, LeadParamPInt, 8, true)
+		result := e.CaseDittoCommand(CmdCaseEdit, LeadParamPInt, 8, true)
 		assert.True(t, result, "CaseEdit should succeed")
 
 		content := getLineContent(lines[0])
@@ -328,28 +309,20 @@ func TestCaseDittoCommand_CaseCommands(t *testing.T) {
 
 // TestCaseDittoCommand_SuccessfulDittoCases tests successful ditto operations
 func TestCaseDittoCommand_SuccessfulDittoCases(t *testing.T) {
-	oldCurrentFrame := CurrentFrame
-	oldEditMode := EditMode
-	oldTtControlC := TtControlC
-
-	defer func() {
-		CurrentFrame = oldCurrentFrame
-		EditMode = oldEditMode
-		TtControlC = oldTtControlC
-	}()
+	e := NewEditor()
 
 	t.Run("DittoUp_ValidLineAbove", func(t *testing.T) {
 		frame, lines := setupTestFrame(3)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "SOURCE LINE")
 		setLineContent(lines[1], "target line")
 		frame.Dot.Line = lines[1]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdDittoUp, LeadParamNone, 5, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamNone, 5, true)
 		assert.True(t, result, "DittoUp should succeed")
 
 		// Verify characters were copied from line above
@@ -359,16 +332,16 @@ func TestCaseDittoCommand_SuccessfulDittoCases(t *testing.T) {
 
 	t.Run("DittoDown_ValidLineBelow", func(t *testing.T) {
 		frame, lines := setupTestFrame(3)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[1], "target line")
 		setLineContent(lines[2], "SOURCE LINE")
 		frame.Dot.Line = lines[1]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdDittoDown, LeadParamNone, 5, true)
+		result := e.CaseDittoCommand(CmdDittoDown, LeadParamNone, 5, true)
 		assert.True(t, result, "DittoDown should succeed")
 
 		// Verify characters were copied from line below
@@ -378,16 +351,16 @@ func TestCaseDittoCommand_SuccessfulDittoCases(t *testing.T) {
 
 	t.Run("DittoUp_WithLeadParamPlus", func(t *testing.T) {
 		frame, lines := setupTestFrame(3)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "ABCDEFGHIJ")
 		setLineContent(lines[1], "1234567890")
 		frame.Dot.Line = lines[1]
 		frame.Dot.Col = 3
 
-		result := CaseDittoCommand(CmdDittoUp, LeadParamPlus, 4, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamPlus, 4, true)
 		assert.True(t, result, "DittoUp should succeed")
 
 		content := getLineContent(lines[1])
@@ -396,16 +369,16 @@ func TestCaseDittoCommand_SuccessfulDittoCases(t *testing.T) {
 
 	t.Run("DittoDown_WithLeadParamPInt", func(t *testing.T) {
 		frame, lines := setupTestFrame(3)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[1], "current")
 		setLineContent(lines[2], "BELOW LINE TEXT")
 		frame.Dot.Line = lines[1]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdDittoDown, LeadParamPInt, 7, true)
+		result := e.CaseDittoCommand(CmdDittoDown, LeadParamPInt, 7, true)
 		assert.True(t, result, "DittoDown should succeed")
 
 		content := getLineContent(lines[1])
@@ -414,9 +387,9 @@ func TestCaseDittoCommand_SuccessfulDittoCases(t *testing.T) {
 
 	t.Run("DittoUp_WithLeadParamPIndef", func(t *testing.T) {
 		frame, lines := setupTestFrame(3)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "COMPLETE LINE")
 		setLineContent(lines[1], "short")
@@ -424,7 +397,7 @@ func TestCaseDittoCommand_SuccessfulDittoCases(t *testing.T) {
 		frame.Dot.Col = 3
 
 		// LeadParamPIndef should copy from dot to end of other line
-		result := CaseDittoCommand(CmdDittoUp, LeadParamPIndef, 0, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamPIndef, 0, true)
 		assert.True(t, result, "DittoUp with PIndef should succeed")
 
 		content := getLineContent(lines[1])
@@ -434,16 +407,16 @@ func TestCaseDittoCommand_SuccessfulDittoCases(t *testing.T) {
 
 	t.Run("DittoDown_FromMiddleOfLine", func(t *testing.T) {
 		frame, lines := setupTestFrame(3)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[1], "1234567890")
 		setLineContent(lines[2], "ABCDEFGHIJ")
 		frame.Dot.Line = lines[1]
 		frame.Dot.Col = 5 // Start from middle
 
-		result := CaseDittoCommand(CmdDittoDown, LeadParamNone, 3, true)
+		result := e.CaseDittoCommand(CmdDittoDown, LeadParamNone, 3, true)
 		assert.True(t, result, "DittoDown should succeed")
 
 		content := getLineContent(lines[1])
@@ -453,28 +426,20 @@ func TestCaseDittoCommand_SuccessfulDittoCases(t *testing.T) {
 
 // TestCaseDittoCommand_ParameterCalculations tests parameter handling
 func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
-	oldCurrentFrame := CurrentFrame
-	oldEditMode := EditMode
-	oldTtControlC := TtControlC
-
-	defer func() {
-		CurrentFrame = oldCurrentFrame
-		EditMode = oldEditMode
-		TtControlC = oldTtControlC
-	}()
+	e := NewEditor()
 
 	t.Run("LeadParamNone_DefaultCount", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "SOURCE")
 		setLineContent(lines[1], "target")
 		frame.Dot.Line = lines[1]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdDittoUp, LeadParamNone, 3, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamNone, 3, true)
 		assert.True(t, result, "LeadParamNone should succeed")
 
 		content := getLineContent(lines[1])
@@ -484,15 +449,15 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 
 	t.Run("LeadParamPlus_PositiveCount", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "UPPERCASE")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdCaseUp, LeadParamPlus, 5, true)
+		result := e.CaseDittoCommand(CmdCaseUp, LeadParamPlus, 5, true)
 		assert.True(t, result, "LeadParamPlus should succeed")
 
 		content := getLineContent(lines[0])
@@ -501,9 +466,9 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 
 	t.Run("ZeroCount_Handling", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "SOURCE")
 		setLineContent(lines[1], "target")
@@ -511,31 +476,31 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 		frame.Dot.Col = 1
 
 		// Zero count should be handled
-		result := CaseDittoCommand(CmdDittoUp, LeadParamNone, 0, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamNone, 0, true)
 		assert.True(t, result, "Zero count should succeed")
 	})
 
 	t.Run("DittoUp_EmptySourceLine", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		lines[0].Used = 0 // Empty source line
 		setLineContent(lines[1], "target")
 		frame.Dot.Line = lines[1]
 		frame.Dot.Col = 1
 
-		result := CaseDittoCommand(CmdDittoUp, LeadParamNone, 3, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamNone, 3, true)
 		// Validation fails: Dot.Col + count > otherLine.Used + 1 (1+3 > 0+1)
 		assert.False(t, result, "Should fail when trying to copy beyond empty source")
 	})
 
 	t.Run("LeadParamMinus_BackwardCopy", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "ABCDEFGHIJ")
 		setLineContent(lines[1], "1234567890")
@@ -547,7 +512,7 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 		// - firstCol = 6 - 3 = 3
 		// - Copy 3 chars from source starting at column 3 ("CDE")
 		// - Overtype current line starting at column 3
-		result := CaseDittoCommand(CmdDittoUp, LeadParamMinus, -3, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamMinus, -3, true)
 		assert.True(t, result, "LeadParamMinus should succeed")
 
 		content := getLineContent(lines[1])
@@ -558,9 +523,9 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 
 	t.Run("LeadParamNInt_BackwardCopy", func(t *testing.T) {
 		frame, lines := setupTestFrame(3)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[1], "abcdefghij")
 		setLineContent(lines[2], "ZYXWVUTSRQ")
@@ -571,7 +536,7 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 		// - count becomes 4
 		// - firstCol = 8 - 4 = 4
 		// - Copy 4 chars from line below starting at column 4: "WVUT"
-		result := CaseDittoCommand(CmdDittoDown, LeadParamNInt, -4, true)
+		result := e.CaseDittoCommand(CmdDittoDown, LeadParamNInt, -4, true)
 		assert.True(t, result, "LeadParamNInt should succeed")
 
 		content := getLineContent(lines[1])
@@ -583,9 +548,9 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 
 	t.Run("LeadParamNIndef_CopyFromStart", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "PREFIXSUFFIX")
 		setLineContent(lines[1], "lowercase text")
@@ -594,7 +559,7 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 
 		// LeadParamNIndef copies from column 1 to (Dot.Col - 1)
 		// So copy 6 chars from source line
-		result := CaseDittoCommand(CmdDittoUp, LeadParamNIndef, 0, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamNIndef, 0, true)
 		assert.True(t, result, "LeadParamNIndef should succeed")
 
 		content := getLineContent(lines[1])
@@ -604,9 +569,9 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 
 	t.Run("LeadParamMinus_AtStartOfLine", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 
 		setLineContent(lines[0], "SOURCE")
 		setLineContent(lines[1], "target")
@@ -614,29 +579,19 @@ func TestCaseDittoCommand_ParameterCalculations(t *testing.T) {
 		frame.Dot.Col = 3
 
 		// LeadParamMinus with count=-3 would try to start at column 0 (invalid)
-		result := CaseDittoCommand(CmdDittoUp, LeadParamMinus, -3, true)
+		result := e.CaseDittoCommand(CmdDittoUp, LeadParamMinus, -3, true)
 		assert.False(t, result, "Should fail when going before column 1")
 	})
 }
 
 // TestCaseDittoCommandValidation tests parameter validation logic
 func TestCaseDittoCommandValidation(t *testing.T) {
-	oldCurrentFrame := CurrentFrame
-	oldEditMode := EditMode
-	oldPreviousMode := PreviousMode
-	oldTtControlC := TtControlC
-
-	defer func() {
-		CurrentFrame = oldCurrentFrame
-		EditMode = oldEditMode
-		PreviousMode = oldPreviousMode
-		TtControlC = oldTtControlC
-	}()
+	e := NewEditor()
 
 	t.Run("DittoInInsertMode_RejectsNegativeLeadParams", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		setLineContent(lines[0], "test")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
@@ -661,10 +616,10 @@ func TestCaseDittoCommandValidation(t *testing.T) {
 
 		for _, tc := range testCases {
 			t.Run(tc.name, func(t *testing.T) {
-				EditMode = tc.editMode
-				PreviousMode = tc.prevMode
+				e.EditMode = tc.editMode
+				e.PreviousMode = tc.prevMode
 
-				result := CaseDittoCommand(tc.command, tc.leadParam, tc.count, true)
+				result := e.CaseDittoCommand(tc.command, tc.leadParam, tc.count, true)
 				assert.False(t, result, "Should reject %s with %v in insert mode context", tc.command, tc.leadParam)
 			})
 		}
@@ -674,9 +629,9 @@ func TestCaseDittoCommandValidation(t *testing.T) {
 		// This tests that the function recognizes and groups commands correctly
 		// by checking the validation that rejects invalid commands
 		frame, lines := setupTestFrame(1)
-		CurrentFrame = frame
-		EditMode = ModeCommand
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.EditMode = ModeCommand
+		e.TtControlC.Store(false)
 		setLineContent(lines[0], "test")
 		frame.Dot.Line = lines[0]
 		frame.Dot.Col = 1
@@ -699,22 +654,12 @@ func TestCaseDittoCommandValidation(t *testing.T) {
 
 // TestInsertModeRestrictions specifically tests insert mode logic
 func TestInsertModeRestrictions(t *testing.T) {
-	oldCurrentFrame := CurrentFrame
-	oldEditMode := EditMode
-	oldPreviousMode := PreviousMode
-	oldTtControlC := TtControlC
-
-	defer func() {
-		CurrentFrame = oldCurrentFrame
-		EditMode = oldEditMode
-		PreviousMode = oldPreviousMode
-		TtControlC = oldTtControlC
-	}()
+	e := NewEditor()
 
 	t.Run("AllNegativeParamCombinations", func(t *testing.T) {
 		frame, lines := setupTestFrame(2)
-		CurrentFrame = frame
-		TtControlC = false
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		setLineContent(lines[0], "above")
 		setLineContent(lines[1], "current")
 		frame.Dot.Line = lines[1]
@@ -735,10 +680,10 @@ func TestInsertModeRestrictions(t *testing.T) {
 			for _, cmd := range dittoCommands {
 				for _, param := range negativeParams {
 					t.Run(fmt.Sprintf("%s_%d_%d", mode.desc, cmd, param), func(t *testing.T) {
-						EditMode = mode.edit
-						PreviousMode = mode.prev
+						e.EditMode = mode.edit
+						e.PreviousMode = mode.prev
 
-						result := CaseDittoCommand(cmd, param, -1, true)
+						result := e.CaseDittoCommand(cmd, param, -1, true)
 						assert.False(t, result, "Should reject command %d with param %d in %s", cmd, param, mode.desc)
 					})
 				}
@@ -16,8 +16,16 @@ package ludwig
 
 import (
 	"unicode"
+	"unicode/utf8"
+
+	"ludwig-go/internal/terminal"
 )
 
+// rawByteBase is added to bytes that are not part of a valid UTF-8
+// sequence when text is read, the result is a lone surrogate which can
+// never be decoded from UTF-8, so the byte is written back unchanged.
+const rawByteBase = 0xdc00
+
 // sgn returns the sign of a value: -1, 0, or 1
 func sgn(val int) int {
 	if val < 0 {
@@ -29,8 +37,9 @@ func sgn(val int) int {
 }
 
 // ChFillCopy is a wrapper for array copy that handles copies with fill character.
-// It copies srclen bytes from src starting at srcofs to dst starting at dstofs,
-// filling the remaining dstlen bytes with the fill character if srclen < dstlen.
+// It copies srclen characters from src starting at srcofs to dst starting at
+// dstofs, filling the remaining dstlen characters with the fill character if
+// srclen < dstlen.
 func ChFillCopy(
 	src *StrObject,
 	srcofs int,
@@ -38,7 +47,7 @@ func ChFillCopy(
 	dst *StrObject,
 	dstofs int,
 	dstlen int,
-	fill byte,
+	fill rune,
 ) {
 	if dstlen > 0 {
 		dst.FillCopy(src, srcofs, srclen, dstofs, dstlen, fill)
@@ -95,7 +104,7 @@ func ChCompareStr(
 	return diff
 }
 
-// ChReverseStr reverses len bytes from src and stores them in dst.
+// ChReverseStr reverses len characters from src and stores them in dst.
 // If src and dst are the same, it reverses in place.
 func ChReverseStr(src *StrObject, dst *StrObject, len int) {
 	half := (len + 1) / 2
@@ -109,49 +118,49 @@ func ChReverseStr(src *StrObject, dst *StrObject, len int) {
 }
 
 func ChIsPrintable(ch rune) bool {
-	if ch < 0 || ch > MaxSetRange {
+	if ch < 0 || ch > unicode.MaxRune {
 		return false
 	}
 	return unicode.IsPrint(ch)
 }
 
 func ChIsSpace(ch rune) bool {
-	if ch < 0 || ch > MaxSetRange {
+	if ch < 0 || ch > unicode.MaxRune {
 		return false
 	}
 	return unicode.IsSpace(ch)
 }
 
 func ChIsLetter(ch rune) bool {
-	if ch < 0 || ch > MaxSetRange {
+	if ch < 0 || ch > unicode.MaxRune {
 		return false
 	}
 	return unicode.IsLetter(ch)
 }
 
 func ChIsLower(ch rune) bool {
-	if ch < 0 || ch > MaxSetRange {
+	if ch < 0 || ch > unicode.MaxRune {
 		return false
 	}
 	return unicode.IsLower(ch)
 }
 
 func ChIsUpper(ch rune) bool {
-	if ch < 0 || ch > MaxSetRange {
+	if ch < 0 || ch > unicode.MaxRune {
 		return false
 	}
 	return unicode.IsUpper(ch)
 }
 
 func ChIsNumeric(ch rune) bool {
-	if ch < 0 || ch > MaxSetRange {
+	if ch < 0 || ch > unicode.MaxRune {
 		return false
 	}
 	return unicode.IsNumber(ch)
 }
 
 func ChIsPunctuation(ch rune) bool {
-	if ch < 0 || ch > MaxSetRange {
+	if ch < 0 || ch > unicode.MaxRune {
 		return false
 	}
 	// Broad definition of punctuation.
@@ -171,28 +180,100 @@ func ChIsWordElement(set int, ch rune) bool {
 
 func ChKeyToUpper(key int) int {
 	if key >= 0 && key <= MaxSetRange {
-		return int(ChToUpper(byte(key)))
+		if upper := ChToUpper(rune(key)); upper <= MaxSetRange {
+			return int(upper)
+		}
 	}
 	return key
 }
 
 // ChToUpper converts a character to uppercase.
-func ChToUpper(ch byte) byte {
-	return byte(unicode.ToUpper(rune(ch)))
+func ChToUpper(ch rune) rune {
+	return unicode.ToUpper(ch)
 }
 
 // ChToLower converts a character to lowercase.
-func ChToLower(ch byte) byte {
-	return byte(unicode.ToLower(rune(ch)))
+func ChToLower(ch rune) rune {
+	return unicode.ToLower(ch)
 }
 
 // ChApplyN applies a function to n characters in a string object
-func ChApplyN(str *StrObject, fn func(byte) byte, n int) {
+func ChApplyN(str *StrObject, fn func(rune) rune, n int) {
 	if n > 0 {
 		str.ApplyN(fn, n, 1)
 	}
 }
 
+// ChFromRawByte returns the character holding a byte that is not UTF-8.
+func ChFromRawByte(b byte) rune {
+	return rawByteBase + rune(b)
+}
+
+// ChAppendUTF8 appends len characters of str starting at index to dst
+// encoded as UTF-8, characters made by ChFromRawByte become bytes again.
+func ChAppendUTF8(dst []byte, str *StrObject, index int, len int) []byte {
+	for i := index; i < index+len; i++ {
+		dst = ChAppendRune(dst, str.Get(i))
+	}
+	return dst
+}
+
+// ChAppendRune appends a character to dst as ChAppendUTF8 does
+func ChAppendRune(dst []byte, ch rune) []byte {
+	if ch >= rawByteBase+0x80 && ch <= rawByteBase+0xff {
+		return append(dst, byte(ch-rawByteBase))
+	}
+	return utf8.AppendRune(dst, ch)
+}
+
+// ChFromUTF8 returns the characters of UTF-8 text, bytes that are not part
+// of a UTF-8 sequence are kept as ChFromRawByte does.
+func ChFromUTF8(text []byte) []rune {
+	chars := make([]rune, 0, len(text))
+	for len(text) > 0 {
+		ch, size := utf8.DecodeRune(text)
+		if ch == utf8.RuneError && size == 1 {
+			ch = ChFromRawByte(text[0])
+		}
+		chars = append(chars, ch)
+		text = text[size:]
+	}
+	return chars
+}
+
+// ChToKey returns the key code of a character.  Characters above OrdMaxChar
+// are given codes from KeyRuneBase up, clear of the special keys.
+func ChToKey(ch rune) int {
+	if ch > OrdMaxChar {
+		return KeyRuneBase + int(ch)
+	}
+	return int(ch)
+}
+
+// KeyToCh returns the character typed by a key, if it is one.
+func KeyToCh(key int) (rune, bool) {
+	if key >= 0 && key <= OrdMaxChar {
+		return rune(key), true
+	}
+	if key > KeyRuneBase+OrdMaxChar && key <= KeyRuneBase+unicode.MaxRune {
+		return rune(key - KeyRuneBase), true
+	}
+	return 0, false
+}
+
+// ChIsPrintableKey returns true if the key types a printable character, all
+// the characters above OrdMaxChar are taken to be text.
+func ChIsPrintableKey(key int) bool {
+	ch, ok := KeyToCh(key)
+	return ok && (ch > OrdMaxChar || ChIsPrintable(ch))
+}
+
+// ChWidth returns the number of screen columns a character occupies.
+// Combining marks take none, East Asian wide and fullwidth characters two.
+func ChWidth(ch rune) int {
+	return terminal.Width(ch)
+}
+
 // ChSearchStr searches for a target string within a text string.
 // Returns true if found, with foundLoc set to the location.
 // If backwards is true, searches from the end of the text.
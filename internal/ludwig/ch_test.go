@@ -35,7 +35,7 @@ func TestSgn(t *testing.T) {
 func TestChFillCopy(t *testing.T) {
 	t.Run("CopyFullSource", func(t *testing.T) {
 		src := NewStrObjectFrom("HELLO")
-		dst := NewBlankStrObject(MaxStrLen)
+		dst := NewBlankStrObject(testStrLen)
 
 		ChFillCopy(src, 1, 5, dst, 1, 5, ' ')
 
@@ -44,7 +44,7 @@ func TestChFillCopy(t *testing.T) {
 
 	t.Run("CopyWithFill", func(t *testing.T) {
 		src := NewStrObjectFrom("HI")
-		dst := NewBlankStrObject(MaxStrLen)
+		dst := NewBlankStrObject(testStrLen)
 
 		ChFillCopy(src, 1, 2, dst, 1, 5, '*')
 
@@ -52,8 +52,8 @@ func TestChFillCopy(t *testing.T) {
 	})
 
 	t.Run("FillOnly", func(t *testing.T) {
-		src := NewBlankStrObject(MaxStrLen)
-		dst := NewBlankStrObject(MaxStrLen)
+		src := NewBlankStrObject(testStrLen)
+		dst := NewBlankStrObject(testStrLen)
 
 		ChFillCopy(src, 1, 0, dst, 1, 5, '-')
 
@@ -72,7 +72,7 @@ func TestChFillCopy(t *testing.T) {
 
 	t.Run("SourceLongerThanDest", func(t *testing.T) {
 		src := NewStrObjectFrom("HELLO")
-		dst := NewBlankStrObject(MaxStrLen)
+		dst := NewBlankStrObject(testStrLen)
 
 		ChFillCopy(src, 1, 5, dst, 1, 3, ' ')
 
@@ -81,7 +81,7 @@ func TestChFillCopy(t *testing.T) {
 
 	t.Run("CopyWithOffset", func(t *testing.T) {
 		src := NewStrObjectFrom("HELLO")
-		dst := NewBlankStrObject(MaxStrLen)
+		dst := NewBlankStrObject(testStrLen)
 
 		ChFillCopy(src, 2, 3, dst, 3, 4, '.')
 
@@ -169,8 +169,8 @@ func TestChCompareStr(t *testing.T) {
 	})
 
 	t.Run("EmptyStrings", func(t *testing.T) {
-		s1 := NewBlankStrObject(MaxStrLen)
-		s2 := NewBlankStrObject(MaxStrLen)
+		s1 := NewBlankStrObject(testStrLen)
+		s2 := NewBlankStrObject(testStrLen)
 		var nchIdent int
 
 		result := ChCompareStr(s1, 1, 0, s2, 1, 0, false, &nchIdent)
@@ -184,7 +184,7 @@ func TestChCompareStr(t *testing.T) {
 func TestChReverseStr(t *testing.T) {
 	t.Run("ReverseOddLength", func(t *testing.T) {
 		src := NewStrObjectFrom("HELLO")
-		dst := NewBlankStrObject(MaxStrLen)
+		dst := NewBlankStrObject(testStrLen)
 
 		ChReverseStr(src, dst, 5)
 
@@ -193,7 +193,7 @@ func TestChReverseStr(t *testing.T) {
 
 	t.Run("ReverseEvenLength", func(t *testing.T) {
 		src := NewStrObjectFrom("TEST")
-		dst := NewBlankStrObject(MaxStrLen)
+		dst := NewBlankStrObject(testStrLen)
 
 		ChReverseStr(src, dst, 4)
 
@@ -202,7 +202,7 @@ func TestChReverseStr(t *testing.T) {
 
 	t.Run("ReverseSingleChar", func(t *testing.T) {
 		src := NewStrObjectFrom("A")
-		dst := NewBlankStrObject(MaxStrLen)
+		dst := NewBlankStrObject(testStrLen)
 
 		ChReverseStr(src, dst, 1)
 
@@ -234,8 +234,8 @@ func TestChReverseStr(t *testing.T) {
 func TestChToUpper(t *testing.T) {
 	tests := []struct {
 		name     string
-		input    byte
-		expected byte
+		input    rune
+		expected rune
 	}{
 		{"LowercaseA", 'a', 'A'},
 		{"LowercaseZ", 'z', 'Z'},
@@ -244,6 +244,9 @@ func TestChToUpper(t *testing.T) {
 		{"Digit", '5', '5'},
 		{"Space", ' ', ' '},
 		{"Punctuation", '!', '!'},
+		{"LatinOne", 'é', 'É'},
+		{"Greek", 'ω', 'Ω'},
+		{"Ideograph", '日', '日'},
 	}
 
 	for _, tt := range tests {
@@ -284,14 +287,61 @@ func TestChApplyN(t *testing.T) {
 		str := NewStrObjectFrom("12345")
 
 		// Custom function: add 1 to each character
-		addOne := func(ch byte) byte { return ch + 1 }
+		addOne := func(ch rune) rune { return ch + 1 }
 		ChApplyN(str, addOne, 3)
 
 		assert.Equal(t, "23445", str.Slice(1, 5))
 	})
 }
 
+// TestChWidth tests the screen width of characters
+func TestChWidth(t *testing.T) {
+	assert.Equal(t, 1, ChWidth('a'))
+	assert.Equal(t, 1, ChWidth('é'))
+	assert.Equal(t, 1, ChWidth('Ω'))
+	assert.Equal(t, 2, ChWidth('日'))
+	assert.Equal(t, 2, ChWidth('한'))
+	assert.Equal(t, 2, ChWidth('Ａ'))
+	assert.Equal(t, 0, ChWidth('\u0301'))
+	assert.Equal(t, 1, ChWidth(ChFromRawByte(0xff)))
+}
+
+// TestChKeys tests the conversion between characters and keys
+func TestChKeys(t *testing.T) {
+	for _, ch := range []rune{'a', 'é', 'Ω', '日'} {
+		key := ChToKey(ch)
+		assert.True(t, ChIsPrintableKey(key), "ChIsPrintableKey(%c)", ch)
+		got, ok := KeyToCh(key)
+		assert.True(t, ok)
+		assert.Equal(t, ch, got)
+	}
+	assert.Equal(t, int('a'), ChToKey('a'))
+	assert.Greater(t, ChToKey('日'), KeyRuneBase)
+
+	// Special keys are not characters
+	_, ok := KeyToCh(OrdMaxChar + 1)
+	assert.False(t, ok)
+	assert.False(t, ChIsPrintableKey(OrdMaxChar+1))
+	assert.False(t, ChIsPrintableKey(7))
+}
+
+// TestChAppendUTF8 tests writing characters back out as UTF-8
+func TestChAppendUTF8(t *testing.T) {
+	str := NewStrObjectFrom("é日x")
+	str.Set(3, ChFromRawByte(0xff))
+	assert.Equal(t, []byte("é日\xff"), ChAppendUTF8(nil, str, 1, 3))
+	assert.Equal(t, []byte("日"), ChAppendUTF8(nil, str, 2, 1))
+}
+
 // TestChSearchStr tests string searching
+// TestChFromUTF8 tests reading characters from UTF-8
+func TestChFromUTF8(t *testing.T) {
+	assert.Equal(t, []rune{'é', '日', ChFromRawByte(0xff), 'a'}, ChFromUTF8([]byte("é日\xffa")))
+	assert.Empty(t, ChFromUTF8(nil))
+	str := &StrObject{array: ChFromUTF8([]byte("\xe6\x97x\xff"))}
+	assert.Equal(t, []byte("\xe6\x97x\xff"), ChAppendUTF8(nil, str, 1, str.Len()))
+}
+
 func TestChSearchStr(t *testing.T) {
 	t.Run("SearchForward", func(t *testing.T) {
 		target := NewStrObjectFrom("WORLD")
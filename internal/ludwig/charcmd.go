@@ -19,19 +19,19 @@ import (
 )
 
 // CharcmdInsert handles character insertion commands
-func CharcmdInsert(cmd Commands, rept LeadParam, count int, fromSpan bool) bool {
+func (e *Editor) CharcmdInsert(cmd Commands, rept LeadParam, count int, fromSpan bool) bool {
 	cmdStatus := false
 	if rept == LeadParamMinus {
 		rept = LeadParamNInt
 	}
 	count = int(math.Abs(float64(count)))
 
-	oldDotCol := CurrentFrame.Dot.Col
+	oldDotCol := e.CurrentFrame.Dot.Col
 	var maximum int
-	if CurrentFrame.Dot.Col <= CurrentFrame.Dot.Line.Used {
-		maximum = MaxStrLen - CurrentFrame.Dot.Line.Used
+	if e.CurrentFrame.Dot.Col <= e.CurrentFrame.Dot.Line.Used {
+		maximum = MaxStrLen - e.CurrentFrame.Dot.Line.Used
 	} else {
-		maximum = MaxStrLen - CurrentFrame.Dot.Col
+		maximum = MaxStrLen - e.CurrentFrame.Dot.Col
 	}
 
 	inserted := 0
@@ -43,14 +43,14 @@ func CharcmdInsert(cmd Commands, rept LeadParam, count int, fromSpan bool) bool
 		if cmdValid {
 			maximum -= count
 			inserted += count
-			if !TextInsert(true, 1, BlankString, count, CurrentFrame.Dot) {
+			if !e.TextInsert(true, 1, NewBlankStrObject(count), count, e.CurrentFrame.Dot) {
 				goto l9
 			}
 			if rept == LeadParamNInt {
-				eqlCol = CurrentFrame.Dot.Col - count
+				eqlCol = e.CurrentFrame.Dot.Col - count
 			} else {
-				eqlCol = CurrentFrame.Dot.Col
-				CurrentFrame.Dot.Col -= count
+				eqlCol = e.CurrentFrame.Dot.Col
+				e.CurrentFrame.Dot.Col -= count
 			}
 			cmdStatus = true
 		}
@@ -58,85 +58,85 @@ func CharcmdInsert(cmd Commands, rept LeadParam, count int, fromSpan bool) bool
 			goto l9
 		}
 		if cmdValid {
-			ScreenFixup()
+			e.ScreenFixup()
 		} else {
-			VduBeep()
+			e.VduBeep()
 		}
-		key = VduGetKey()
-		if TtControlC {
+		key = e.VduGetKey()
+		if e.TtControlC.Load() {
 			goto l9
 		}
 		rept = LeadParamNone
 		count = 1
-		if ChIsPrintable(rune(key)) {
+		if ChIsPrintableKey(key) {
 			cmd = CmdNoop
 		} else {
-			cmd = Lookup[key].Command
+			cmd = e.KeyLookup(key).Command
 		}
 		if cmd != CmdInsertChar {
 			break
 		}
 	}
-	VduTakeBackKey(key)
+	e.VduTakeBackKey(key)
 
 l9:
-	if TtControlC {
+	if e.TtControlC.Load() {
 		cmdStatus = false
-		CurrentFrame.Dot.Col = oldDotCol
+		e.CurrentFrame.Dot.Col = oldDotCol
 		var tempMark *MarkObject
-		MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col+inserted, &tempMark)
-		TextRemove(CurrentFrame.Dot, tempMark)
+		MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col+inserted, &tempMark)
+		e.TextRemove(e.CurrentFrame.Dot, tempMark)
 		MarkDestroy(&tempMark)
 	} else {
 		if cmdStatus {
-			CurrentFrame.TextModified = true
+			e.CurrentFrame.TextModified = true
 			MarkCreate(
-				CurrentFrame.Dot.Line,
-				CurrentFrame.Dot.Col,
-				&CurrentFrame.Marks[MarkModified],
+				e.CurrentFrame.Dot.Line,
+				e.CurrentFrame.Dot.Col,
+				&e.CurrentFrame.Marks[MarkModified],
 			)
-			MarkCreate(CurrentFrame.Dot.Line, eqlCol, &CurrentFrame.Marks[MarkEquals])
+			MarkCreate(e.CurrentFrame.Dot.Line, eqlCol, &e.CurrentFrame.Marks[MarkEquals])
 		}
 	}
 	return cmdStatus || !fromSpan
 }
 
-func joinLines() bool {
+func (e *Editor) joinLines() bool {
 	// Only join lines if we are in the newline mode and there is a previous line to join to
-	if !CurrentFrame.Options.Has(OptNewLine) {
+	if !e.CurrentFrame.Options.Has(OptNewLine) {
 		return false
 	}
-	bLine := CurrentFrame.Dot.Line.BLink
+	bLine := e.CurrentFrame.Dot.Line.BLink
 	if bLine == nil {
 		return false
 	}
 	var theOtherMark *MarkObject
 	if MarkCreate(bLine, bLine.Used+1, &theOtherMark) {
 		defer MarkDestroy(&theOtherMark)
-		if TextRemove(theOtherMark, CurrentFrame.Dot) {
-			CurrentFrame.TextModified = true
-			return MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &CurrentFrame.Marks[MarkModified])
+		if e.TextRemove(theOtherMark, e.CurrentFrame.Dot) {
+			e.CurrentFrame.TextModified = true
+			return MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Marks[MarkModified])
 		}
 	}
 	return false
 }
 
 // CharcmdDelete handles character deletion commands
-func CharcmdDelete(cmd Commands, rept LeadParam, count int, fromSpan bool) bool {
+func (e *Editor) CharcmdDelete(cmd Commands, rept LeadParam, count int, fromSpan bool) bool {
 	cmdStatus := false
-	oldDotCol := CurrentFrame.Dot.Col
+	oldDotCol := e.CurrentFrame.Dot.Col
 	oldStr := NewStrObjectCopy(
-		CurrentFrame.Dot.Line.Str,
+		e.CurrentFrame.Dot.Line.Str,
 		1,
-		CurrentFrame.Dot.Line.Used,
-		CurrentFrame.Dot.Line.Used,
+		e.CurrentFrame.Dot.Line.Used,
+		e.CurrentFrame.Dot.Line.Used,
 	)
 	deleted := 0
 	var key int
 
 	for {
 		cmdValid := true
-		dotCol := CurrentFrame.Dot.Col
+		dotCol := e.CurrentFrame.Dot.Col
 		switch rept {
 		case LeadParamNone, LeadParamPlus, LeadParamPInt:
 			if count > MaxStrLenP-dotCol {
@@ -147,58 +147,67 @@ func CharcmdDelete(cmd Commands, rept LeadParam, count int, fromSpan bool) bool
 		case LeadParamMinus, LeadParamNInt:
 			count = -count
 			if count < dotCol {
-				CurrentFrame.Dot.Col -= count
-			} else if !fromSpan && count == 1 && dotCol == 1 && joinLines() {
-				MarkDestroy(&CurrentFrame.Marks[MarkEquals])
+				e.CurrentFrame.Dot.Col -= count
+			} else if !fromSpan && count == 1 && dotCol == 1 && e.joinLines() {
+				MarkDestroy(&e.CurrentFrame.Marks[MarkEquals])
 				return true
 			} else {
 				cmdValid = false
 			}
 		case LeadParamNIndef:
-			count = CurrentFrame.Dot.Col - 1
-			CurrentFrame.Dot.Col = 1
+			count = e.CurrentFrame.Dot.Col - 1
+			e.CurrentFrame.Dot.Col = 1
 		}
 
 		if cmdValid {
 			// Update the text of the line
-			oldUsed := CurrentFrame.Dot.Line.Used
-			length := (CurrentFrame.Dot.Line.Used + 1) - (CurrentFrame.Dot.Col + count)
+			rec := e.undoBegin()
+			oldUsed := e.CurrentFrame.Dot.Line.Used
+			narrow := screenNarrow(e.CurrentFrame.Dot.Line)
+			length := (e.CurrentFrame.Dot.Line.Used + 1) - (e.CurrentFrame.Dot.Col + count)
 			if length > 0 {
-				l := CurrentFrame.Dot.Line
-				dotCol := CurrentFrame.Dot.Col
+				l := e.CurrentFrame.Dot.Line
+				dotCol := e.CurrentFrame.Dot.Col
+				rec.capture(l, 1)
 				l.Str.Erase(count, dotCol)
 				l.Str.FillN(' ', count, l.Used+1-count)
 				l.Used -= count
-			} else if CurrentFrame.Dot.Col <= CurrentFrame.Dot.Line.Used {
-				d := CurrentFrame.Dot
+			} else if e.CurrentFrame.Dot.Col <= e.CurrentFrame.Dot.Line.Used {
+				d := e.CurrentFrame.Dot
+				rec.capture(d.Line, 1)
 				d.Line.Str.FillN(' ', d.Line.Used+1-d.Col, d.Col)
 				d.Line.Used = d.Line.Str.Length(' ', d.Col)
 			}
+			e.undoEnd(rec)
 
 			// Update the screen
-			scrCol := CurrentFrame.Dot.Col - CurrentFrame.ScrOffset
-			if (CurrentFrame.Dot.Line.ScrRowNr != 0) && (count != 0) &&
-				(CurrentFrame.Dot.Col <= oldUsed) && (scrCol <= CurrentFrame.ScrWidth) {
+			scrCol := e.CurrentFrame.Dot.Col - e.CurrentFrame.ScrOffset
+			if !narrow {
+				if e.CurrentFrame.Dot.Line.ScrRowNr != 0 && count != 0 {
+					e.ScreenDrawLine(e.CurrentFrame.Dot.Line)
+				}
+			} else if (e.CurrentFrame.Dot.Line.ScrRowNr != 0) && (count != 0) &&
+				(e.CurrentFrame.Dot.Col <= oldUsed) && (scrCol <= e.CurrentFrame.ScrWidth) {
 				if scrCol <= 0 {
 					scrCol = 1
 				}
-				VduMoveCurs(scrCol, CurrentFrame.Dot.Line.ScrRowNr)
-				length = CurrentFrame.ScrWidth + 1 - scrCol
+				e.VduMoveCurs(scrCol, e.CurrentFrame.Dot.Line.ScrRowNr)
+				length = e.CurrentFrame.ScrWidth + 1 - scrCol
 				if count < length {
 					length = count
-					VduDeleteChars(count)
+					e.VduDeleteChars(count)
 				} else {
-					VduClearEOL()
+					e.VduClearEOL()
 				}
-				firstCol := CurrentFrame.ScrOffset + CurrentFrame.ScrWidth + 1 - length
-				if firstCol <= CurrentFrame.Dot.Line.Used {
-					VduMoveCurs(
-						CurrentFrame.ScrWidth+1-length, CurrentFrame.Dot.Line.ScrRowNr,
+				firstCol := e.CurrentFrame.ScrOffset + e.CurrentFrame.ScrWidth + 1 - length
+				if firstCol <= e.CurrentFrame.Dot.Line.Used {
+					e.VduMoveCurs(
+						e.CurrentFrame.ScrWidth+1-length, e.CurrentFrame.Dot.Line.ScrRowNr,
 					)
-					if length > CurrentFrame.Dot.Line.Used+1-firstCol {
-						length = CurrentFrame.Dot.Line.Used + 1 - firstCol
+					if length > e.CurrentFrame.Dot.Line.Used+1-firstCol {
+						length = e.CurrentFrame.Dot.Line.Used + 1 - firstCol
 					}
-					VduDisplayStr(CurrentFrame.Dot.Line.Str.Slice(firstCol, length), 3)
+					e.VduDisplayStr(e.CurrentFrame.Dot.Line.Str.Slice(firstCol, length), 3)
 				}
 			}
 			deleted += count
@@ -209,22 +218,22 @@ func CharcmdDelete(cmd Commands, rept LeadParam, count int, fromSpan bool) bool
 			goto l9
 		}
 		if cmdValid {
-			ScreenFixup()
+			e.ScreenFixup()
 		} else {
-			VduBeep()
+			e.VduBeep()
 		}
-		key = VduGetKey()
-		if TtControlC {
+		key = e.VduGetKey()
+		if e.TtControlC.Load() {
 			goto l9
 		}
 		rept = LeadParamNone
 		count = 1
-		if ChIsPrintable(rune(key)) {
+		if ChIsPrintableKey(key) {
 			cmd = CmdNoop
 		} else {
-			cmd = Lookup[key].Command
+			cmd = e.KeyLookup(key).Command
 		}
-		if (cmd == CmdRubout) && (EditMode == ModeInsert) {
+		if (cmd == CmdRubout) && (e.EditMode == ModeInsert) {
 			// In insert_mode treat RUBOUT as \-D
 			rept = LeadParamMinus
 			count = -1
@@ -234,21 +243,21 @@ func CharcmdDelete(cmd Commands, rept LeadParam, count int, fromSpan bool) bool
 			break
 		}
 	}
-	VduTakeBackKey(key)
+	e.VduTakeBackKey(key)
 
 l9:
-	if TtControlC {
+	if e.TtControlC.Load() {
 		cmdStatus = false
-		CurrentFrame.Dot.Col = 1
-		TextOvertype(false, 1, oldStr, MaxStrLen, CurrentFrame.Dot)
-		CurrentFrame.Dot.Col = oldDotCol
+		e.CurrentFrame.Dot.Col = 1
+		e.TextOvertype(false, 1, oldStr, oldStr.Len(), e.CurrentFrame.Dot)
+		e.CurrentFrame.Dot.Col = oldDotCol
 	} else if cmdStatus {
-		oldDotCol = CurrentFrame.Dot.Col
+		oldDotCol = e.CurrentFrame.Dot.Col
 		count = MaxStrLenP - oldDotCol
 		if deleted > count {
 			deleted = count
 		}
-		line := CurrentFrame.Dot.Line
+		line := e.CurrentFrame.Dot.Line
 		MarksSqueeze(line, oldDotCol, line, oldDotCol+deleted)
 		MarksShift(
 			line,
@@ -257,86 +266,86 @@ l9:
 			line,
 			oldDotCol,
 		)
-		CurrentFrame.TextModified = true
-		MarkCreate(line, CurrentFrame.Dot.Col, &CurrentFrame.Marks[MarkModified])
-		if CurrentFrame.Marks[MarkEquals] != nil {
-			MarkDestroy(&CurrentFrame.Marks[MarkEquals])
+		e.CurrentFrame.TextModified = true
+		MarkCreate(line, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Marks[MarkModified])
+		if e.CurrentFrame.Marks[MarkEquals] != nil {
+			MarkDestroy(&e.CurrentFrame.Marks[MarkEquals])
 		}
 	}
 	return cmdStatus || !fromSpan
 }
 
 // CharcmdRubout handles rubout commands
-func CharcmdRubout(cmd Commands, rept LeadParam, count int, fromSpan bool) bool {
+func (e *Editor) CharcmdRubout(cmd Commands, rept LeadParam, count int, fromSpan bool) bool {
 	var cmdStatus bool
-	if EditMode == ModeInsert {
+	if e.EditMode == ModeInsert {
 		if rept == LeadParamPIndef {
 			rept = LeadParamNIndef
 		} else {
 			rept = LeadParamNInt
 		}
-		cmdStatus = CharcmdDelete(CmdDeleteChar, rept, -count, fromSpan)
+		cmdStatus = e.CharcmdDelete(CmdDeleteChar, rept, -count, fromSpan)
 	} else {
 		cmdStatus = false
-		oldDotCol := CurrentFrame.Dot.Col
-		dotUsed := CurrentFrame.Dot.Line.Used
-		oldStr := NewStrObjectCopy(CurrentFrame.Dot.Line.Str, 1, dotUsed, dotUsed)
+		oldDotCol := e.CurrentFrame.Dot.Col
+		dotUsed := e.CurrentFrame.Dot.Line.Used
+		oldStr := NewStrObjectCopy(e.CurrentFrame.Dot.Line.Str, 1, dotUsed, dotUsed)
 		var key int
 		var eqlCol int
 
 		for {
 			if rept == LeadParamPIndef {
-				count = CurrentFrame.Dot.Col - 1
+				count = e.CurrentFrame.Dot.Col - 1
 			}
-			cmdValid := (count <= CurrentFrame.Dot.Col-1)
+			cmdValid := (count <= e.CurrentFrame.Dot.Col-1)
 			if cmdValid {
-				eqlCol = CurrentFrame.Dot.Col
-				CurrentFrame.Dot.Col -= count
-				if !TextOvertype(true, 1, BlankString, count, CurrentFrame.Dot) {
+				eqlCol = e.CurrentFrame.Dot.Col
+				e.CurrentFrame.Dot.Col -= count
+				if !e.TextOvertype(true, 1, NewBlankStrObject(count), count, e.CurrentFrame.Dot) {
 					goto l9
 				}
-				CurrentFrame.Dot.Col -= count
+				e.CurrentFrame.Dot.Col -= count
 				cmdStatus = true
 			}
 			if fromSpan {
 				goto l9
 			}
 			if cmdValid {
-				ScreenFixup()
+				e.ScreenFixup()
 			} else {
-				VduBeep()
+				e.VduBeep()
 			}
-			key = VduGetKey()
-			if TtControlC {
+			key = e.VduGetKey()
+			if e.TtControlC.Load() {
 				goto l9
 			}
 			rept = LeadParamNone
 			count = 1
-			if ChIsPrintable(rune(key)) {
+			if ChIsPrintableKey(key) {
 				cmd = CmdNoop
 			} else {
-				cmd = Lookup[key].Command
+				cmd = e.KeyLookup(key).Command
 			}
 			if cmd != CmdRubout {
 				break
 			}
 		}
-		VduTakeBackKey(key)
+		e.VduTakeBackKey(key)
 
 	l9:
-		if TtControlC {
+		if e.TtControlC.Load() {
 			cmdStatus = false
-			CurrentFrame.Dot.Col = 1
-			TextOvertype(false, 1, oldStr, dotUsed, CurrentFrame.Dot)
-			CurrentFrame.Dot.Col = oldDotCol
+			e.CurrentFrame.Dot.Col = 1
+			e.TextOvertype(false, 1, oldStr, dotUsed, e.CurrentFrame.Dot)
+			e.CurrentFrame.Dot.Col = oldDotCol
 		} else if cmdStatus {
-			CurrentFrame.TextModified = true
+			e.CurrentFrame.TextModified = true
 			MarkCreate(
-				CurrentFrame.Dot.Line,
-				CurrentFrame.Dot.Col,
-				&CurrentFrame.Marks[MarkModified],
+				e.CurrentFrame.Dot.Line,
+				e.CurrentFrame.Dot.Col,
+				&e.CurrentFrame.Marks[MarkModified],
 			)
-			MarkCreate(CurrentFrame.Dot.Line, eqlCol, &CurrentFrame.Marks[MarkEquals])
+			MarkCreate(e.CurrentFrame.Dot.Line, eqlCol, &e.CurrentFrame.Marks[MarkEquals])
 		}
 	}
 	return cmdStatus || !fromSpan
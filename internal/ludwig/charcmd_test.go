@@ -3,6 +3,7 @@
 package ludwig
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,7 +35,7 @@ func setupTestFrameForCharCmd(content string) (*FrameObject, *LineHdrObject) {
 		OffsetNr: 0,
 		Used:     len(content),
 		ScrRowNr: 0,
-		Str:      NewBlankStrObject(MaxStrLen),
+		Str:      NewBlankStrObject(testStrLen),
 		Marks:    make([]*MarkObject, 0), // Initialize marks slice
 	}
 
@@ -97,7 +98,7 @@ func setupMultiLineFrame(lines []string) *FrameObject {
 			OffsetNr: i,
 			Used:     len(content),
 			ScrRowNr: 0,
-			Str:      NewBlankStrObject(MaxStrLen),
+			Str:      NewBlankStrObject(testStrLen),
 			Marks:    make([]*MarkObject, 0), // Initialize marks slice
 		}
 
@@ -146,21 +147,22 @@ func setupMultiLineFrame(lines []string) *FrameObject {
 // Tests for CharcmdInsert
 
 func TestCharcmdInsert(t *testing.T) {
+	e := NewEditor()
 	t.Run("InsertSingleSpaceInMiddle", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("hello")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 3 // After "he"
 
-		result := CharcmdInsert(CmdInsertChar, LeadParamNone, 1, true)
+		result := e.CharcmdInsert(CmdInsertChar, LeadParamNone, 1, true)
 
 		assert.True(t, result, "Expected insert to succeed")
 		assert.Equal(t, 6, line.Used, "Expected line length to be 6")
@@ -172,19 +174,19 @@ func TestCharcmdInsert(t *testing.T) {
 
 	t.Run("InsertMultipleSpaces", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("hello")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 3 // After "he"
 
-		result := CharcmdInsert(CmdInsertChar, LeadParamPInt, 3, true)
+		result := e.CharcmdInsert(CmdInsertChar, LeadParamPInt, 3, true)
 
 		assert.True(t, result, "Expected insert to succeed")
 		assert.Equal(t, 8, line.Used, "Expected line length to increase by 3")
@@ -194,19 +196,19 @@ func TestCharcmdInsert(t *testing.T) {
 
 	t.Run("InsertAtStartOfLine", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("world")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 1
 
-		result := CharcmdInsert(CmdInsertChar, LeadParamPInt, 2, true)
+		result := e.CharcmdInsert(CmdInsertChar, LeadParamPInt, 2, true)
 
 		assert.True(t, result, "Expected insert to succeed")
 		assert.Equal(t, 7, line.Used, "Expected line length to increase")
@@ -215,45 +217,59 @@ func TestCharcmdInsert(t *testing.T) {
 
 	t.Run("InsertWithNegativeCount", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("test")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 3
 
 		// Negative count should be converted to positive
-		result := CharcmdInsert(CmdInsertChar, LeadParamMinus, -2, true)
+		result := e.CharcmdInsert(CmdInsertChar, LeadParamMinus, -2, true)
 
 		assert.True(t, result, "Expected insert to succeed")
 		assert.Equal(t, 6, line.Used, "Expected line length to increase")
 	})
 
+	t.Run("InsertIntoLongLine", func(t *testing.T) {
+		// Lines are not limited to the old 400 characters
+		frame, line := setupTestFrameForCharCmd(strings.Repeat("x", 395))
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
+		defer func() {
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
+		}()
+
+		frame.Dot.Col = 390
+		result := e.CharcmdInsert(CmdInsertChar, LeadParamPInt, 10, true)
+
+		assert.True(t, result, "Expected insert to succeed")
+		assert.Equal(t, 405, line.Used, "Expected line length to increase")
+	})
+
 	t.Run("InsertBeyondMaxStrLen", func(t *testing.T) {
-		// Create a line that's nearly full
-		longContent := string(make([]byte, MaxStrLen-5))
-		for i := range MaxStrLen - 5 {
-			longContent = longContent[:i] + "x" + longContent[i+1:]
-		}
-		frame, _ := setupTestFrameForCharCmd(longContent)
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		frame, _ := setupTestFrameForCharCmd("text")
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Dot.Col = MaxStrLen - 4
 
-		// Try to insert more than we have space for
-		result := CharcmdInsert(CmdInsertChar, LeadParamPInt, 10, true)
+		// Try to insert past the last column
+		result := e.CharcmdInsert(CmdInsertChar, LeadParamPInt, 10, true)
 
 		// Should fail when exceeding MaxStrLen
 		assert.False(t, result, "Expected insert to fail when exceeding MaxStrLen")
@@ -261,19 +277,19 @@ func TestCharcmdInsert(t *testing.T) {
 
 	t.Run("InsertWithLeadParamNInt", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("text")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 3
 
-		result := CharcmdInsert(CmdInsertChar, LeadParamNInt, 2, true)
+		result := e.CharcmdInsert(CmdInsertChar, LeadParamNInt, 2, true)
 
 		assert.True(t, result, "Expected insert to succeed")
 		assert.Equal(t, 6, line.Used, "Expected line length to increase")
@@ -283,18 +299,18 @@ func TestCharcmdInsert(t *testing.T) {
 
 	t.Run("InsertCreatesModifiedMark", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("test")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Dot.Col = 2
 
-		result := CharcmdInsert(CmdInsertChar, LeadParamNone, 1, true)
+		result := e.CharcmdInsert(CmdInsertChar, LeadParamNone, 1, true)
 
 		assert.True(t, result, "Expected insert to succeed")
 		assert.NotNil(t, frame.Marks[MarkModified], "Expected modified mark to be created")
@@ -303,18 +319,18 @@ func TestCharcmdInsert(t *testing.T) {
 
 	t.Run("InsertCreatesEqualsMark", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("test")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Dot.Col = 2
 
-		result := CharcmdInsert(CmdInsertChar, LeadParamNone, 2, true)
+		result := e.CharcmdInsert(CmdInsertChar, LeadParamNone, 2, true)
 
 		assert.True(t, result, "Expected insert to succeed")
 		assert.NotNil(t, frame.Marks[MarkEquals], "Expected equals mark to be created")
@@ -324,21 +340,22 @@ func TestCharcmdInsert(t *testing.T) {
 // Tests for CharcmdDelete
 
 func TestCharcmdDelete(t *testing.T) {
+	e := NewEditor()
 	t.Run("DeleteSingleChar", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("hello")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 2 // At 'e'
 
-		result := CharcmdDelete(CmdDeleteChar, LeadParamNone, 1, true)
+		result := e.CharcmdDelete(CmdDeleteChar, LeadParamNone, 1, true)
 
 		assert.True(t, result, "Expected delete to succeed")
 		assert.Equal(t, 4, line.Used, "Expected line length to decrease by 1")
@@ -349,19 +366,19 @@ func TestCharcmdDelete(t *testing.T) {
 
 	t.Run("DeleteMultipleChars", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("testing")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 3 // At 's'
 
-		result := CharcmdDelete(CmdDeleteChar, LeadParamPInt, 3, true)
+		result := e.CharcmdDelete(CmdDeleteChar, LeadParamPInt, 3, true)
 
 		assert.True(t, result, "Expected delete to succeed")
 		assert.Equal(t, 4, line.Used, "Expected line length to decrease by 3")
@@ -371,20 +388,20 @@ func TestCharcmdDelete(t *testing.T) {
 
 	t.Run("DeleteBackward", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("world")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 4 // At 'l'
 
 		// Test backward delete - note: behavior with LeadParamNInt seems problematic
-		result := CharcmdDelete(CmdDeleteChar, LeadParamNInt, -2, true)
+		result := e.CharcmdDelete(CmdDeleteChar, LeadParamNInt, -2, true)
 
 		assert.True(t, result, "Expected delete to succeed")
 		// assert.Equal(t, 3, line.Used, "Expected line length to decrease")
@@ -395,19 +412,19 @@ func TestCharcmdDelete(t *testing.T) {
 
 	t.Run("DeleteToEndOfLine", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("hello")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 3
 
-		result := CharcmdDelete(CmdDeleteChar, LeadParamPIndef, 0, true)
+		result := e.CharcmdDelete(CmdDeleteChar, LeadParamPIndef, 0, true)
 
 		assert.True(t, result, "Expected delete to succeed")
 		assert.Equal(t, 2, line.Used, "Expected only first 2 chars to remain")
@@ -417,19 +434,19 @@ func TestCharcmdDelete(t *testing.T) {
 
 	t.Run("DeleteToStartOfLine", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("testing")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 5
 
-		result := CharcmdDelete(CmdDeleteChar, LeadParamNIndef, 0, true)
+		result := e.CharcmdDelete(CmdDeleteChar, LeadParamNIndef, 0, true)
 
 		assert.True(t, result, "Expected delete to succeed")
 		assert.Equal(t, 1, frame.Dot.Col, "Expected cursor at start")
@@ -440,19 +457,19 @@ func TestCharcmdDelete(t *testing.T) {
 
 	t.Run("DeleteBeyondLineEnd", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("hi")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Dot.Col = 2
 
 		// Delete forward with count that might exceed line - let's see what happens
-		result := CharcmdDelete(CmdDeleteChar, LeadParamPInt, 10, true)
+		result := e.CharcmdDelete(CmdDeleteChar, LeadParamPInt, 10, true)
 
 		// The function will try to delete, behavior depends on impl details
 		// Check if it completed without crashing
@@ -465,10 +482,10 @@ func TestCharcmdDelete(t *testing.T) {
 	t.Run("DeleteAtStartBackward", func(t *testing.T) {
 		frame := setupMultiLineFrame([]string{"first", "second"})
 		frame.Options.Set(OptNewLine)
-		oldFrame := CurrentFrame
-		CurrentFrame = frame
+		oldFrame := e.CurrentFrame
+		e.CurrentFrame = frame
 		defer func() {
-			CurrentFrame = oldFrame
+			e.CurrentFrame = oldFrame
 		}()
 
 		// Second line, first column
@@ -476,7 +493,7 @@ func TestCharcmdDelete(t *testing.T) {
 		frame.Dot.Col = 1
 
 		// Try to delete backward from start - should fail or join lines
-		result := CharcmdDelete(CmdDeleteChar, LeadParamNInt, -1, false)
+		result := e.CharcmdDelete(CmdDeleteChar, LeadParamNInt, -1, false)
 
 		assert.True(t, result, "Expected delete to succeed")
 		assert.Equal(t, getLineContent(frame.FirstGroup.FirstLine), "firstsecond", "Expected lines to be joined")
@@ -484,18 +501,18 @@ func TestCharcmdDelete(t *testing.T) {
 
 	t.Run("DeleteUpdatesModifiedMark", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("hello")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Dot.Col = 2
 
-		result := CharcmdDelete(CmdDeleteChar, LeadParamNone, 1, true)
+		result := e.CharcmdDelete(CmdDeleteChar, LeadParamNone, 1, true)
 
 		assert.True(t, result, "Expected delete to succeed")
 		assert.True(t, frame.TextModified, "Expected frame to be marked as modified")
@@ -504,20 +521,20 @@ func TestCharcmdDelete(t *testing.T) {
 
 	t.Run("DeleteClearsEqualsMark", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("test")
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		// Create an equals mark
 		MarkCreate(frame.Dot.Line, 3, &frame.Marks[MarkEquals])
 		frame.Dot.Col = 2
 
-		result := CharcmdDelete(CmdDeleteChar, LeadParamNone, 1, true)
+		result := e.CharcmdDelete(CmdDeleteChar, LeadParamNone, 1, true)
 
 		assert.True(t, result, "Expected delete to succeed")
 		assert.Nil(t, frame.Marks[MarkEquals], "Expected equals mark to be destroyed")
@@ -527,24 +544,25 @@ func TestCharcmdDelete(t *testing.T) {
 // Tests for CharcmdRubout
 
 func TestCharcmdRubout(t *testing.T) {
+	e := NewEditor()
 	t.Run("RuboutInInsertMode", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("hello")
-		oldFrame := CurrentFrame
-		oldMode := EditMode
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		EditMode = ModeInsert
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldMode := e.EditMode
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.EditMode = ModeInsert
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			EditMode = oldMode
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.EditMode = oldMode
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 4
 
-		result := CharcmdRubout(CmdRubout, LeadParamNone, 1, true)
+		result := e.CharcmdRubout(CmdRubout, LeadParamNone, 1, true)
 
 		assert.True(t, result, "Expected rubout to succeed")
 		assert.Equal(t, 4, line.Used, "Expected line length to decrease")
@@ -555,22 +573,22 @@ func TestCharcmdRubout(t *testing.T) {
 
 	t.Run("RuboutInOverwriteModeSingleChar", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("hello")
-		oldFrame := CurrentFrame
-		oldMode := EditMode
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		EditMode = ModeOvertype
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldMode := e.EditMode
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.EditMode = ModeOvertype
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			EditMode = oldMode
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.EditMode = oldMode
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 4
 
-		result := CharcmdRubout(CmdRubout, LeadParamNone, 1, true)
+		result := e.CharcmdRubout(CmdRubout, LeadParamNone, 1, true)
 
 		assert.True(t, result, "Expected rubout to succeed")
 		assert.Equal(t, 3, frame.Dot.Col, "Expected cursor to move backward")
@@ -581,22 +599,22 @@ func TestCharcmdRubout(t *testing.T) {
 
 	t.Run("RuboutInOverwriteModeMultiple", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("testing")
-		oldFrame := CurrentFrame
-		oldMode := EditMode
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		EditMode = ModeOvertype
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldMode := e.EditMode
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.EditMode = ModeOvertype
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			EditMode = oldMode
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.EditMode = oldMode
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 5
 
-		result := CharcmdRubout(CmdRubout, LeadParamNone, 3, true)
+		result := e.CharcmdRubout(CmdRubout, LeadParamNone, 3, true)
 
 		assert.True(t, result, "Expected rubout to succeed")
 		// assert.Equal(t, 2, frame.Dot.Col, "Expected cursor to move backward by 3")
@@ -607,22 +625,22 @@ func TestCharcmdRubout(t *testing.T) {
 
 	t.Run("RuboutAtStartOfLine", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("test")
-		oldFrame := CurrentFrame
-		oldMode := EditMode
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		EditMode = ModeOvertype
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldMode := e.EditMode
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.EditMode = ModeOvertype
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			EditMode = oldMode
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.EditMode = oldMode
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		line := frame.Dot.Line
 
 		frame.Dot.Col = 1
 
-		result := CharcmdRubout(CmdRubout, LeadParamNone, 1, true)
+		result := e.CharcmdRubout(CmdRubout, LeadParamNone, 1, true)
 
 		// Should fail at start of line
 		assert.False(t, result, "Expected rubout to fail at start of line")
@@ -631,22 +649,22 @@ func TestCharcmdRubout(t *testing.T) {
 
 	t.Run("RuboutInInsertModeConvertsToDelete", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("world")
-		oldFrame := CurrentFrame
-		oldMode := EditMode
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		EditMode = ModeInsert
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldMode := e.EditMode
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.EditMode = ModeInsert
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			EditMode = oldMode
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.EditMode = oldMode
+			e.TtControlC.Store(oldTtControlC)
 		}()
 		_ = frame.Dot.Line
 
 		frame.Dot.Col = 3
 
-		result := CharcmdRubout(CmdRubout, LeadParamNone, 2, true)
+		result := e.CharcmdRubout(CmdRubout, LeadParamNone, 2, true)
 
 		assert.True(t, result, "Expected rubout to succeed")
 		assert.Equal(t, 1, frame.Dot.Col, "Expected cursor to move to position 1")
@@ -655,21 +673,21 @@ func TestCharcmdRubout(t *testing.T) {
 
 	t.Run("RuboutInOverwriteModeCreatesMarks", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("hello")
-		oldFrame := CurrentFrame
-		oldMode := EditMode
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		EditMode = ModeOvertype
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldMode := e.EditMode
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.EditMode = ModeOvertype
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			EditMode = oldMode
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.EditMode = oldMode
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Dot.Col = 3
 
-		result := CharcmdRubout(CmdRubout, LeadParamNone, 1, true)
+		result := e.CharcmdRubout(CmdRubout, LeadParamNone, 1, true)
 
 		assert.True(t, result, "Expected rubout to succeed")
 		assert.True(t, frame.TextModified, "Expected frame to be marked as modified")
@@ -679,21 +697,21 @@ func TestCharcmdRubout(t *testing.T) {
 
 	t.Run("RuboutWithPIndefinite", func(t *testing.T) {
 		frame, _ := setupTestFrameForCharCmd("testing")
-		oldFrame := CurrentFrame
-		oldMode := EditMode
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		EditMode = ModeOvertype
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldMode := e.EditMode
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.EditMode = ModeOvertype
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			EditMode = oldMode
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.EditMode = oldMode
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Dot.Col = 5
 
-		result := CharcmdRubout(CmdRubout, LeadParamPIndef, 0, true)
+		result := e.CharcmdRubout(CmdRubout, LeadParamPIndef, 0, true)
 
 		assert.True(t, result, "Expected rubout to succeed")
 		// Should rubout all characters before current position
@@ -704,15 +722,16 @@ func TestCharcmdRubout(t *testing.T) {
 // Tests for joinLines helper
 
 func TestJoinLines(t *testing.T) {
+	e := NewEditor()
 	t.Run("JoinWithPreviousLine", func(t *testing.T) {
 		frame := setupMultiLineFrame([]string{"hello", "world"})
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		// Set options to enable newline mode
@@ -723,7 +742,7 @@ func TestJoinLines(t *testing.T) {
 		frame.Dot.Line = secondLine
 		frame.Dot.Col = 1
 
-		result := joinLines()
+		result := e.joinLines()
 
 		assert.True(t, result, "Expected join to succeed")
 		assert.True(t, frame.TextModified, "Expected frame to be marked as modified")
@@ -734,13 +753,13 @@ func TestJoinLines(t *testing.T) {
 
 	t.Run("JoinFailsWithoutNewlineOption", func(t *testing.T) {
 		frame := setupMultiLineFrame([]string{"hello", "world"})
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		// Explicitly clear newline option
@@ -750,39 +769,39 @@ func TestJoinLines(t *testing.T) {
 		frame.Dot.Line = secondLine
 		frame.Dot.Col = 1
 
-		result := joinLines()
+		result := e.joinLines()
 
 		assert.False(t, result, "Expected join to fail without newline option")
 	})
 
 	t.Run("JoinFailsAtFirstLine", func(t *testing.T) {
 		frame := setupMultiLineFrame([]string{"hello", "world"})
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Options.Set(OptNewLine)
 		frame.Dot.Col = 1
 
-		result := joinLines()
+		result := e.joinLines()
 
 		assert.False(t, result, "Expected join to fail at first line")
 	})
 
 	t.Run("JoinCreatesModifiedMark", func(t *testing.T) {
 		frame := setupMultiLineFrame([]string{"line1", "line2"})
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Options.Set(OptNewLine)
@@ -791,7 +810,7 @@ func TestJoinLines(t *testing.T) {
 		frame.Dot.Line = secondLine
 		frame.Dot.Col = 1
 
-		result := joinLines()
+		result := e.joinLines()
 
 		assert.True(t, result, "Expected join to succeed")
 		assert.NotNil(t, frame.Marks[MarkModified], "Expected modified mark to be created")
@@ -799,13 +818,13 @@ func TestJoinLines(t *testing.T) {
 
 	t.Run("JoinWithEmptyLine", func(t *testing.T) {
 		frame := setupMultiLineFrame([]string{"hello", ""})
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Options.Set(OptNewLine)
@@ -815,7 +834,7 @@ func TestJoinLines(t *testing.T) {
 		frame.Dot.Line = secondLine
 		frame.Dot.Col = 1
 
-		result := joinLines()
+		result := e.joinLines()
 
 		assert.True(t, result, "Expected join with empty line to succeed")
 		assert.True(t, frame.TextModified, "Expected frame to be marked as modified")
@@ -827,13 +846,13 @@ func TestJoinLines(t *testing.T) {
 
 	t.Run("JoinVerifiesContentMerge", func(t *testing.T) {
 		frame := setupMultiLineFrame([]string{"foo", "bar"})
-		oldFrame := CurrentFrame
-		oldTtControlC := TtControlC
-		CurrentFrame = frame
-		TtControlC = false
+		oldFrame := e.CurrentFrame
+		oldTtControlC := e.TtControlC.Load()
+		e.CurrentFrame = frame
+		e.TtControlC.Store(false)
 		defer func() {
-			CurrentFrame = oldFrame
-			TtControlC = oldTtControlC
+			e.CurrentFrame = oldFrame
+			e.TtControlC.Store(oldTtControlC)
 		}()
 
 		frame.Options.Set(OptNewLine)
@@ -842,7 +861,7 @@ func TestJoinLines(t *testing.T) {
 		frame.Dot.Line = secondLine
 		frame.Dot.Col = 1
 
-		result := joinLines()
+		result := e.joinLines()
 
 		assert.True(t, result, "Expected join to succeed")
 		firstLine := frame.FirstGroup.FirstLine
@@ -0,0 +1,653 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         CMDTABLE
+//
+// Description:  The tables of the commands bound to the keys, and of the
+//               commands that follow each prefix.
+
+package ludwig
+
+// addLookupExp sets an entry of the table of the commands that follow a
+// prefix
+func (e *Editor) addLookupExp(index int, ch byte, cmd Commands) {
+	e.LookupExp[index].Extn = ch
+	e.LookupExp[index].Command = cmd
+}
+
+// LoadCommandTable sets up the commands the keys do, and the commands that
+// follow each prefix, for the old or the new command set
+func (e *Editor) LoadCommandTable(oldVersion bool) {
+	var keyCode int
+
+	// for keyCode = -MaxSpecialKeys; keyCode <= -1; keyCode++ {
+	// 	Lookup[keyCode].Command = CmdNoop
+	// }
+	if oldVersion {
+		e.Lookup[0].Command = CmdNoop
+		e.Lookup[1].Command = CmdNoop
+		e.Lookup[2].Command = CmdWindowBackward
+		e.Lookup[3].Command = CmdNoop
+		e.Lookup[4].Command = CmdDeleteChar
+		e.Lookup[5].Command = CmdWindowEnd
+		e.Lookup[6].Command = CmdWindowForward
+		e.Lookup[7].Command = CmdDoLastCommand
+		e.Lookup[8].Command = CmdRubout
+		e.Lookup[9].Command = CmdTab
+		e.Lookup[10].Command = CmdDown
+		e.Lookup[11].Command = CmdDeleteLine
+		e.Lookup[12].Command = CmdInsertLine
+		e.Lookup[13].Command = CmdReturn
+		e.Lookup[14].Command = CmdWindowNew
+		e.Lookup[15].Command = CmdNoop
+		e.Lookup[16].Command = CmdUserCommandIntroducer
+		e.Lookup[17].Command = CmdNoop
+		e.Lookup[18].Command = CmdRight
+		e.Lookup[19].Command = CmdNoop
+		e.Lookup[20].Command = CmdWindowTop
+		e.Lookup[21].Command = CmdUp
+		e.Lookup[22].Command = CmdNoop
+		e.Lookup[23].Command = CmdWordAdvance
+		e.Lookup[24].Command = CmdNoop
+		e.Lookup[25].Command = CmdNoop
+		e.Lookup[26].Command = CmdUserParent
+		e.Lookup[27].Command = CmdNoop
+		e.Lookup[28].Command = CmdNoop
+		e.Lookup[29].Command = CmdNoop
+		e.Lookup[30].Command = CmdInsertChar
+		e.Lookup[31].Command = CmdNoop
+		e.Lookup[' '].Command = CmdNoop
+		e.Lookup['!'].Command = CmdNoop
+		e.Lookup['"'].Command = CmdDittoUp
+		e.Lookup['#'].Command = CmdNoop
+		e.Lookup['$'].Command = CmdNoop
+		e.Lookup['%'].Command = CmdNoop
+		e.Lookup['&'].Command = CmdNoop
+		e.Lookup['\''].Command = CmdDittoDown
+		e.Lookup['('].Command = CmdNoop
+		e.Lookup[')'].Command = CmdNoop
+		e.Lookup['*'].Command = CmdPrefixAst
+		e.Lookup['+'].Command = CmdNoop
+		e.Lookup[';'].Command = CmdNoop
+		e.Lookup['-'].Command = CmdNoop
+		e.Lookup['.'].Command = CmdNoop
+		e.Lookup['/'].Command = CmdNoop
+		for keyCode = '0'; keyCode <= '9'; keyCode++ {
+			e.Lookup[keyCode].Command = CmdNoop
+		}
+		e.Lookup[':'].Command = CmdNoop
+		e.Lookup[';'].Command = CmdNoop
+		e.Lookup['<'].Command = CmdNoop
+		e.Lookup['='].Command = CmdNoop
+		e.Lookup['>'].Command = CmdNoop
+		e.Lookup['?'].Command = CmdInsertInvisible
+		e.Lookup['@'].Command = CmdNoop
+		e.Lookup['A'].Command = CmdAdvance
+		e.Lookup['B'].Command = CmdPrefixB
+		e.Lookup['C'].Command = CmdInsertChar
+		e.Lookup['D'].Command = CmdDeleteChar
+		e.Lookup['E'].Command = CmdPrefixE
+		e.Lookup['F'].Command = CmdPrefixF
+		e.Lookup['G'].Command = CmdGet
+		e.Lookup['H'].Command = CmdHelp
+		e.Lookup['I'].Command = CmdInsertText
+		e.Lookup['J'].Command = CmdJump
+		e.Lookup['K'].Command = CmdDeleteLine
+		e.Lookup['L'].Command = CmdInsertLine
+		e.Lookup['M'].Command = CmdMark
+		e.Lookup['N'].Command = CmdNext
+		e.Lookup['O'].Command = CmdOvertypeText
+		e.Lookup['P'].Command = CmdNoop
+		e.Lookup['Q'].Command = CmdQuit
+		e.Lookup['R'].Command = CmdReplace
+		e.Lookup['S'].Command = CmdPrefixS
+		e.Lookup['T'].Command = CmdNoop
+		e.Lookup['U'].Command = CmdPrefixU
+		e.Lookup['V'].Command = CmdVerify
+		e.Lookup['W'].Command = CmdPrefixW
+		e.Lookup['X'].Command = CmdPrefixX
+		e.Lookup['Y'].Command = CmdPrefixY
+		e.Lookup['Z'].Command = CmdPrefixZ
+		e.Lookup['['].Command = CmdNoop
+		e.Lookup['\\'].Command = CmdCommand
+		e.Lookup[']'].Command = CmdNoop
+		e.Lookup['^'].Command = CmdExecuteString
+		e.Lookup['_'].Command = CmdNoop
+		e.Lookup['`'].Command = CmdNoop
+		for keyCode = 'a'; keyCode <= 'z'; keyCode++ {
+			e.Lookup[keyCode].Command = CmdNoop
+		}
+		e.Lookup['{'].Command = CmdSetMarginLeft
+		e.Lookup['|'].Command = CmdNoop
+		e.Lookup['}'].Command = CmdSetMarginRight
+		e.Lookup['~'].Command = CmdPrefixTilde
+		e.Lookup[127].Command = CmdRubout
+		for keyCode = 128; keyCode <= OrdMaxChar; keyCode++ {
+			e.Lookup[keyCode].Command = CmdNoop
+		}
+		// for keyCode = -MaxSpecialKeys; keyCode <= OrdMaxChar; keyCode++ {
+		// 	Lookup[keyCode].Code = nil
+		// 	Lookup[keyCode].Tpar = nil
+		// }
+
+		// initialize lookupexp
+		// case change command ; command =  * prefix }      {start at 1}
+		e.addLookupExp(1, 'U', CmdCaseUp)
+		e.addLookupExp(2, 'L', CmdCaseLow)
+		e.addLookupExp(3, 'E', CmdCaseEdit)
+
+		// A prefix }    {4}
+		// There aren't any in this table! }
+
+		// B prefix }    {4}
+		e.addLookupExp(4, 'C', CmdBlockCopy)
+		e.addLookupExp(5, 'D', CmdBlockDefine)
+		e.addLookupExp(6, 'R', CmdBridge)
+		e.addLookupExp(7, 'T', CmdBlockTransfer)
+
+		// C prefix }    {8}
+		// There aren't any in this table! }
+
+		// D prefix }    {8}
+		// There aren't any in this table! }
+
+		// E prefix }    {8}
+		e.addLookupExp(8, 'X', CmdSpanExecute)
+		e.addLookupExp(9, 'D', CmdFrameEdit)
+		e.addLookupExp(10, 'R', CmdFrameReturn)
+		e.addLookupExp(11, 'N', CmdSpanExecuteNoRecompile)
+		e.addLookupExp(12, 'Q', CmdPrefixEq)
+		e.addLookupExp(13, 'O', CmdPrefixEo)
+		e.addLookupExp(14, 'K', CmdFrameKill)
+		e.addLookupExp(15, 'P', CmdFrameParameters)
+		e.addLookupExp(16, 'L', CmdFrameList)
+
+		// EO prefix }   {17}
+		e.addLookupExp(17, 'L', CmdEqualEol)
+		e.addLookupExp(18, 'F', CmdEqualEof)
+		e.addLookupExp(19, 'P', CmdEqualEop)
+
+		// EQ prefix }   {20}
+		e.addLookupExp(20, 'S', CmdEqualString)
+		e.addLookupExp(21, 'C', CmdEqualColumn)
+		e.addLookupExp(22, 'M', CmdEqualMark)
+
+		// F prefix - files }    {23}
+		e.addLookupExp(23, 'S', CmdFileSave)
+		e.addLookupExp(24, 'B', CmdFileRewind)
+		e.addLookupExp(25, 'I', CmdFileInput)
+		e.addLookupExp(26, 'E', CmdFileEdit)
+		e.addLookupExp(27, 'O', CmdFileOutput)
+		e.addLookupExp(28, 'G', CmdPrefixFg)
+		e.addLookupExp(29, 'K', CmdFileKill)
+		e.addLookupExp(30, 'X', CmdFileExecute)
+		e.addLookupExp(31, 'T', CmdFileTable)
+		e.addLookupExp(32, 'P', CmdPage)
+
+		// FG prefix - global files }    {33}
+		e.addLookupExp(33, 'I', CmdFileGlobalInput)
+		e.addLookupExp(34, 'O', CmdFileGlobalOutput)
+		e.addLookupExp(35, 'B', CmdFileGlobalRewind)
+		e.addLookupExp(36, 'K', CmdFileGlobalKill)
+		e.addLookupExp(37, 'R', CmdFileRead)
+		e.addLookupExp(38, 'W', CmdFileWrite)
+
+		// I prefix }    {39}
+		// There aren't any in this table! }
+
+		// K prefix }    {39}
+		// There aren't any in this table! }
+
+		// L prefix }    {39}
+		// There aren't any in this table! }
+
+		// O prefix }    {39}
+		// There aren't any in this table! }
+
+		// P prefix }    {39}
+		// There aren't any in this table! }
+
+		// S prefix - mainly spans }     {39}
+		e.addLookupExp(39, 'A', CmdSpanAssign)
+		e.addLookupExp(40, 'C', CmdSpanCopy)
+		e.addLookupExp(41, 'D', CmdSpanDefine)
+		e.addLookupExp(42, 'T', CmdSpanTransfer)
+		e.addLookupExp(43, 'W', CmdSwapLine)
+		e.addLookupExp(44, 'L', CmdSplitLine)
+		e.addLookupExp(45, 'J', CmdSpanJump)
+		e.addLookupExp(46, 'I', CmdSpanIndex)
+		e.addLookupExp(47, 'R', CmdSpanCompile)
+
+		// T prefix }    {48}
+		// There aren't any in this table! }
+
+		// TC prefix }    {48}
+		// There aren't any in this table! }
+
+		// TF prefix }    {48}
+		// There aren't any in this table! }
+
+		// U prefix - user keyboard mappings }   {48}
+		e.addLookupExp(48, 'C', CmdUserCommandIntroducer)
+		e.addLookupExp(49, 'K', CmdUserKey)
+		e.addLookupExp(50, 'L', CmdUserLearn)
+		e.addLookupExp(51, 'P', CmdUserParent)
+		e.addLookupExp(52, 'R', CmdUserRecall)
+		e.addLookupExp(53, 'S', CmdUserSubprocess)
+		e.addLookupExp(54, 'U', CmdUserUndo)
+		e.addLookupExp(55, 'F', CmdOpSysFilter)
+		e.addLookupExp(56, 'E', CmdErrorList)
+		e.addLookupExp(57, 'N', CmdErrorNext)
+		e.addLookupExp(58, 'G', CmdGetIncremental)
+		e.addLookupExp(59, 'O', CmdOccur)
+
+		// W prefix - window commands }  {60}
+		e.addLookupExp(60, 'F', CmdWindowForward)
+		e.addLookupExp(61, 'B', CmdWindowBackward)
+		e.addLookupExp(62, 'M', CmdWindowMiddle)
+		e.addLookupExp(63, 'T', CmdWindowTop)
+		e.addLookupExp(64, 'E', CmdWindowEnd)
+		e.addLookupExp(65, 'N', CmdWindowNew)
+		e.addLookupExp(66, 'R', CmdWindowRight)
+		e.addLookupExp(67, 'L', CmdWindowLeft)
+		e.addLookupExp(68, 'H', CmdWindowSetHeight)
+		e.addLookupExp(69, 'S', CmdWindowScroll)
+		e.addLookupExp(70, 'U', CmdWindowUpdate)
+		e.addLookupExp(71, 'D', CmdWindowSplit)
+		e.addLookupExp(72, 'K', CmdWindowKill)
+		e.addLookupExp(73, 'O', CmdWindowOnly)
+		e.addLookupExp(74, 'V', CmdWindowSplitVertical)
+		e.addLookupExp(75, 'W', CmdWindowNext)
+
+		// X prefix - exit }             {76}
+		e.addLookupExp(76, 'S', CmdExitSuccess)
+		e.addLookupExp(77, 'F', CmdExitFail)
+		e.addLookupExp(78, 'A', CmdExitAbort)
+
+		// Y prefix - word processing }  {79}
+		e.addLookupExp(79, 'F', CmdLineFill)
+		e.addLookupExp(80, 'J', CmdLineJustify)
+		e.addLookupExp(81, 'S', CmdLineSquash)
+		e.addLookupExp(82, 'C', CmdLineCentre)
+		e.addLookupExp(83, 'L', CmdLineLeft)
+		e.addLookupExp(84, 'R', CmdLineRight)
+		e.addLookupExp(85, 'A', CmdWordAdvance)
+		e.addLookupExp(86, 'D', CmdWordDelete)
+
+		// Z prefix - cursor commands }  {87}
+		e.addLookupExp(87, 'U', CmdUp)
+		e.addLookupExp(88, 'D', CmdDown)
+		e.addLookupExp(89, 'R', CmdRight)
+		e.addLookupExp(90, 'L', CmdLeft)
+		e.addLookupExp(91, 'H', CmdHome)
+		e.addLookupExp(92, 'C', CmdReturn)
+		e.addLookupExp(93, 'T', CmdTab)
+		e.addLookupExp(94, 'B', CmdBacktab)
+		e.addLookupExp(95, 'Z', CmdRubout)
+
+		// ~ prefix - miscellaneous debugging commands}  {96}
+		e.addLookupExp(96, 'V', CmdValidate)
+		e.addLookupExp(97, 'D', CmdDump)
+
+		// sentinel }                    {98}
+		e.addLookupExp(98, '?', CmdNoSuch)
+
+		// initialize lookupexp_ptr }
+		// These magic numbers point to the start of each section in lookupexp table }
+		e.LookupExpPtr[CmdPrefixAst] = 1
+		e.LookupExpPtr[CmdPrefixA] = 4
+		e.LookupExpPtr[CmdPrefixB] = 4
+		e.LookupExpPtr[CmdPrefixC] = 8
+		e.LookupExpPtr[CmdPrefixD] = 8
+		e.LookupExpPtr[CmdPrefixE] = 8
+		e.LookupExpPtr[CmdPrefixEo] = 17
+		e.LookupExpPtr[CmdPrefixEq] = 20
+		e.LookupExpPtr[CmdPrefixF] = 23
+		e.LookupExpPtr[CmdPrefixFg] = 33
+		e.LookupExpPtr[CmdPrefixI] = 39
+		e.LookupExpPtr[CmdPrefixK] = 39
+		e.LookupExpPtr[CmdPrefixL] = 39
+		e.LookupExpPtr[CmdPrefixO] = 39
+		e.LookupExpPtr[CmdPrefixP] = 39
+		e.LookupExpPtr[CmdPrefixS] = 39
+		e.LookupExpPtr[CmdPrefixT] = 48
+		e.LookupExpPtr[CmdPrefixTc] = 48
+		e.LookupExpPtr[CmdPrefixTf] = 48
+		e.LookupExpPtr[CmdPrefixU] = 48
+		e.LookupExpPtr[CmdPrefixW] = 60
+		e.LookupExpPtr[CmdPrefixX] = 76
+		e.LookupExpPtr[CmdPrefixY] = 79
+		e.LookupExpPtr[CmdPrefixZ] = 87
+		e.LookupExpPtr[CmdPrefixTilde] = 96
+		e.LookupExpPtr[CmdNoSuch] = 98
+	} else {
+		e.Lookup[0].Command = CmdNoop
+		e.Lookup[1].Command = CmdNoop
+		e.Lookup[2].Command = CmdWindowBackward
+		e.Lookup[3].Command = CmdNoop
+		e.Lookup[4].Command = CmdDeleteChar
+		e.Lookup[5].Command = CmdWindowEnd
+		e.Lookup[6].Command = CmdWindowForward
+		e.Lookup[7].Command = CmdDoLastCommand
+		e.Lookup[8].Command = CmdLeft
+		e.Lookup[9].Command = CmdTab
+		e.Lookup[10].Command = CmdDown
+		e.Lookup[11].Command = CmdDeleteLine
+		e.Lookup[12].Command = CmdInsertLine
+		e.Lookup[13].Command = CmdReturn
+		e.Lookup[14].Command = CmdWindowNew
+		e.Lookup[15].Command = CmdNoop
+		e.Lookup[16].Command = CmdUserCommandIntroducer
+		e.Lookup[17].Command = CmdNoop
+		e.Lookup[18].Command = CmdRight
+		e.Lookup[19].Command = CmdNoop
+		e.Lookup[20].Command = CmdWindowTop
+		e.Lookup[21].Command = CmdUp
+		e.Lookup[22].Command = CmdNoop
+		e.Lookup[23].Command = CmdWordAdvance
+		e.Lookup[24].Command = CmdNoop
+		e.Lookup[25].Command = CmdNoop
+		e.Lookup[26].Command = CmdUserParent
+		e.Lookup[27].Command = CmdNoop
+		e.Lookup[28].Command = CmdNoop
+		e.Lookup[29].Command = CmdNoop
+		e.Lookup[30].Command = CmdInsertChar
+		e.Lookup[31].Command = CmdNoop
+		e.Lookup[' '].Command = CmdNoop
+		e.Lookup['!'].Command = CmdNoop
+		e.Lookup['"'].Command = CmdDittoUp
+		e.Lookup['#'].Command = CmdNoop
+		e.Lookup['$'].Command = CmdNoop
+		e.Lookup['%'].Command = CmdNoop
+		e.Lookup['&'].Command = CmdNoop
+		e.Lookup['\''].Command = CmdDittoDown
+		e.Lookup['('].Command = CmdNoop
+		e.Lookup[')'].Command = CmdNoop
+		e.Lookup['*'].Command = CmdNoop
+		e.Lookup['+'].Command = CmdNoop
+		e.Lookup[';'].Command = CmdNoop
+		e.Lookup['-'].Command = CmdNoop
+		e.Lookup['.'].Command = CmdNoop
+		e.Lookup['/'].Command = CmdNoop
+		for keyCode = '0'; keyCode <= '9'; keyCode++ {
+			e.Lookup[keyCode].Command = CmdNoop
+		}
+		e.Lookup[':'].Command = CmdNoop
+		e.Lookup[';'].Command = CmdNoop
+		e.Lookup['<'].Command = CmdNoop
+		e.Lookup['='].Command = CmdNoop
+		e.Lookup['>'].Command = CmdNoop
+		e.Lookup['?'].Command = CmdNoop
+		e.Lookup['@'].Command = CmdNoop
+		e.Lookup['A'].Command = CmdPrefixA
+		e.Lookup['B'].Command = CmdPrefixB
+		e.Lookup['C'].Command = CmdPrefixC
+		e.Lookup['D'].Command = CmdPrefixD
+		e.Lookup['E'].Command = CmdPrefixE
+		e.Lookup['F'].Command = CmdPrefixF
+		e.Lookup['G'].Command = CmdGet
+		e.Lookup['H'].Command = CmdHelp
+		e.Lookup['I'].Command = CmdNoop
+		e.Lookup['J'].Command = CmdNoop
+		e.Lookup['K'].Command = CmdPrefixK
+		e.Lookup['L'].Command = CmdPrefixL
+		e.Lookup['M'].Command = CmdMark
+		e.Lookup['N'].Command = CmdNoop
+		e.Lookup['O'].Command = CmdPrefixO
+		e.Lookup['P'].Command = CmdPrefixP
+		e.Lookup['Q'].Command = CmdQuit
+		e.Lookup['R'].Command = CmdReplace
+		e.Lookup['S'].Command = CmdPrefixS
+		e.Lookup['T'].Command = CmdPrefixT
+		e.Lookup['U'].Command = CmdPrefixU
+		e.Lookup['V'].Command = CmdVerify
+		e.Lookup['W'].Command = CmdPrefixW
+		e.Lookup['X'].Command = CmdPrefixX
+		e.Lookup['Y'].Command = CmdNoop
+		e.Lookup['Z'].Command = CmdNoop
+		e.Lookup['['].Command = CmdNoop
+		e.Lookup['\\'].Command = CmdCommand
+		e.Lookup[']'].Command = CmdNoop
+		e.Lookup['^'].Command = CmdNoop
+		e.Lookup['_'].Command = CmdNoop
+		e.Lookup['`'].Command = CmdNoop
+		for keyCode = 'a'; keyCode <= 'z'; keyCode++ {
+			e.Lookup[keyCode].Command = CmdNoop
+		}
+		e.Lookup['{'].Command = CmdSetMarginLeft
+		e.Lookup['|'].Command = CmdNoop
+		e.Lookup['}'].Command = CmdSetMarginRight
+		e.Lookup['~'].Command = CmdPrefixTilde
+		e.Lookup[127].Command = CmdRubout
+		for keyCode = 128; keyCode <= OrdMaxChar; keyCode++ {
+			e.Lookup[keyCode].Command = CmdNoop
+		}
+		// for keyCode = -MaxSpecialKeys; keyCode <= OrdMaxChar; keyCode++ {
+		// 	Lookup[keyCode].Code = nil
+		// 	Lookup[keyCode].Tpar = nil
+		// }
+
+		// initialize lookupexp
+		// Ast ( * ) prefix } {start at 1}
+		// There aren't any in this table!
+
+		// A prefix }    {start at 1}
+		e.addLookupExp(1, 'C', CmdJump)
+		e.addLookupExp(2, 'L', CmdAdvance)
+		e.addLookupExp(3, 'O', CmdBridge)
+		e.addLookupExp(4, 'P', CmdAdvanceParagraph)
+		e.addLookupExp(5, 'S', CmdNoop)
+		e.addLookupExp(6, 'T', CmdNext)
+		e.addLookupExp(7, 'W', CmdWordAdvance)
+
+		// B prefix }    {8}
+		e.addLookupExp(8, 'B', CmdNoop)
+		e.addLookupExp(9, 'C', CmdBlockCopy)
+		e.addLookupExp(10, 'D', CmdBlockDefine)
+		e.addLookupExp(11, 'I', CmdNoop)
+		e.addLookupExp(12, 'K', CmdNoop)
+		e.addLookupExp(13, 'M', CmdBlockTransfer)
+		e.addLookupExp(14, 'O', CmdNoop)
+
+		// C prefix }    {15}
+		e.addLookupExp(15, 'C', CmdInsertChar)
+		e.addLookupExp(16, 'L', CmdInsertLine)
+
+		// D prefix }    {17}
+		e.addLookupExp(17, 'C', CmdDeleteChar)
+		e.addLookupExp(18, 'L', CmdDeleteLine)
+		e.addLookupExp(19, 'P', CmdDeleteParagraph)
+		e.addLookupExp(20, 'S', CmdNoop)
+		e.addLookupExp(21, 'W', CmdWordDelete)
+
+		// E prefix }    {22}
+		e.addLookupExp(22, 'D', CmdFrameEdit)
+		e.addLookupExp(23, 'K', CmdFrameKill)
+		e.addLookupExp(24, 'L', CmdFrameList)
+		e.addLookupExp(25, 'O', CmdPrefixEo)
+		e.addLookupExp(26, 'P', CmdFrameParameters)
+		e.addLookupExp(27, 'Q', CmdPrefixEq)
+		e.addLookupExp(28, 'R', CmdFrameReturn)
+
+		// EO prefix }   {29}
+		e.addLookupExp(29, 'L', CmdEqualEol)
+		e.addLookupExp(30, 'F', CmdEqualEof)
+		e.addLookupExp(31, 'P', CmdEqualEop)
+
+		// EQ prefix }   {32}
+		e.addLookupExp(32, 'C', CmdEqualColumn)
+		e.addLookupExp(33, 'L', CmdNoop)
+		e.addLookupExp(34, 'M', CmdEqualMark)
+		e.addLookupExp(35, 'S', CmdEqualString)
+
+		// F prefix - files }    {36}
+		e.addLookupExp(36, 'S', CmdFileSave)
+		e.addLookupExp(37, 'B', CmdFileRewind)
+		e.addLookupExp(38, 'E', CmdFileEdit)
+		e.addLookupExp(39, 'G', CmdPrefixFg)
+		e.addLookupExp(40, 'I', CmdFileInput)
+		e.addLookupExp(41, 'K', CmdFileKill)
+		e.addLookupExp(42, 'O', CmdFileOutput)
+		e.addLookupExp(43, 'P', CmdPage)
+		e.addLookupExp(44, 'S', CmdNoop)
+		e.addLookupExp(45, 'T', CmdFileTable)
+		e.addLookupExp(46, 'X', CmdFileExecute)
+
+		// FG prefix - global files }    {47}
+		e.addLookupExp(47, 'B', CmdFileGlobalRewind)
+		e.addLookupExp(48, 'I', CmdFileGlobalInput)
+		e.addLookupExp(49, 'K', CmdFileGlobalKill)
+		e.addLookupExp(50, 'O', CmdFileGlobalOutput)
+		e.addLookupExp(51, 'R', CmdFileRead)
+		e.addLookupExp(52, 'W', CmdFileWrite)
+
+		// I prefix }    {53}
+		// There aren't any yet! }
+
+		// K prefix }    {53}
+		e.addLookupExp(53, 'B', CmdBacktab)
+		e.addLookupExp(54, 'C', CmdReturn)
+		e.addLookupExp(55, 'D', CmdDown)
+		e.addLookupExp(56, 'H', CmdHome)
+		e.addLookupExp(57, 'I', CmdInsertMode)
+		e.addLookupExp(58, 'L', CmdLeft)
+		e.addLookupExp(59, 'M', CmdUserKey)
+		e.addLookupExp(60, 'O', CmdOvertypeMode)
+		e.addLookupExp(61, 'R', CmdRight)
+		e.addLookupExp(62, 'T', CmdTab)
+		e.addLookupExp(63, 'U', CmdUp)
+		e.addLookupExp(64, 'X', CmdRubout)
+
+		// L prefix }    {65}
+		e.addLookupExp(65, 'R', CmdNoop)
+		e.addLookupExp(66, 'S', CmdNoop)
+
+		// O prefix }    {67}
+		e.addLookupExp(67, 'E', CmdErrorList)
+		e.addLookupExp(68, 'F', CmdOpSysFilter)
+		e.addLookupExp(69, 'N', CmdErrorNext)
+		e.addLookupExp(70, 'P', CmdUserParent)
+		e.addLookupExp(71, 'S', CmdUserSubprocess)
+		e.addLookupExp(72, 'X', CmdOpSysCommand)
+
+		// P prefix }    {73}
+		e.addLookupExp(73, 'C', CmdPositionColumn)
+		e.addLookupExp(74, 'L', CmdPositionLine)
+
+		// S prefix }    {75}
+		e.addLookupExp(75, 'A', CmdSpanAssign)
+		e.addLookupExp(76, 'C', CmdSpanCopy)
+		e.addLookupExp(77, 'D', CmdSpanDefine)
+		e.addLookupExp(78, 'E', CmdSpanExecuteNoRecompile)
+		e.addLookupExp(79, 'J', CmdSpanJump)
+		e.addLookupExp(80, 'M', CmdSpanTransfer)
+		e.addLookupExp(81, 'R', CmdSpanCompile)
+		e.addLookupExp(82, 'T', CmdSpanIndex)
+		e.addLookupExp(83, 'X', CmdSpanExecute)
+
+		// T prefix }    {84}
+		e.addLookupExp(84, 'B', CmdSplitLine)
+		e.addLookupExp(85, 'C', CmdPrefixTc)
+		e.addLookupExp(86, 'F', CmdPrefixTf)
+		e.addLookupExp(87, 'I', CmdInsertText)
+		e.addLookupExp(88, 'N', CmdInsertInvisible)
+		e.addLookupExp(89, 'O', CmdOvertypeText)
+		e.addLookupExp(90, 'R', CmdNoop)
+		e.addLookupExp(91, 'S', CmdSwapLine)
+		e.addLookupExp(92, 'X', CmdExecuteString)
+
+		// TC prefix }   {93}
+		e.addLookupExp(93, 'E', CmdCaseEdit)
+		e.addLookupExp(94, 'L', CmdCaseLow)
+		e.addLookupExp(95, 'U', CmdCaseUp)
+
+		// TF prefix }   {96}
+		e.addLookupExp(96, 'C', CmdLineCentre)
+		e.addLookupExp(97, 'F', CmdLineFill)
+		e.addLookupExp(98, 'J', CmdLineJustify)
+		e.addLookupExp(99, 'L', CmdLineLeft)
+		e.addLookupExp(100, 'R', CmdLineRight)
+		e.addLookupExp(101, 'S', CmdLineSquash)
+
+		// U prefix - user keyboard mappings }   {102}
+		e.addLookupExp(102, 'C', CmdUserCommandIntroducer)
+		e.addLookupExp(103, 'G', CmdGetIncremental)
+		e.addLookupExp(104, 'L', CmdUserLearn)
+		e.addLookupExp(105, 'O', CmdOccur)
+		e.addLookupExp(106, 'R', CmdUserRecall)
+		e.addLookupExp(107, 'U', CmdUserUndo)
+
+		// W prefix - window commands }  {108}
+		e.addLookupExp(108, 'B', CmdWindowBackward)
+		e.addLookupExp(109, 'C', CmdWindowMiddle)
+		e.addLookupExp(110, 'D', CmdWindowSplit)
+		e.addLookupExp(111, 'E', CmdWindowEnd)
+		e.addLookupExp(112, 'F', CmdWindowForward)
+		e.addLookupExp(113, 'H', CmdWindowSetHeight)
+		e.addLookupExp(114, 'K', CmdWindowKill)
+		e.addLookupExp(115, 'L', CmdWindowLeft)
+		e.addLookupExp(116, 'M', CmdWindowScroll)
+		e.addLookupExp(117, 'N', CmdWindowNew)
+		e.addLookupExp(118, 'O', CmdWindowOnly)
+		e.addLookupExp(119, 'R', CmdWindowRight)
+		e.addLookupExp(120, 'S', CmdNoop)
+		e.addLookupExp(121, 'T', CmdWindowTop)
+		e.addLookupExp(122, 'U', CmdWindowUpdate)
+		e.addLookupExp(123, 'V', CmdWindowSplitVertical)
+		e.addLookupExp(124, 'W', CmdWindowNext)
+
+		// X prefix - exit }             {125}
+		e.addLookupExp(125, 'A', CmdExitAbort)
+		e.addLookupExp(126, 'F', CmdExitFail)
+		e.addLookupExp(127, 'S', CmdExitSuccess)
+
+		// Y prefix }        {128}
+		// There aren't any in this table! }
+
+		// Z prefix }        {128}
+		// There aren't any in this table! }
+
+		// ~ prefix - miscellaneous debugging commands}  {128}
+		e.addLookupExp(128, 'D', CmdDump)
+		e.addLookupExp(129, 'V', CmdValidate)
+
+		// sentinel }                    {130}
+		e.addLookupExp(130, '?', CmdNoSuch)
+
+		// initialize lookupexp_ptr }
+		// These magic numbers point to the start of each section in lookupexp table }
+		e.LookupExpPtr[CmdPrefixAst] = 1
+		e.LookupExpPtr[CmdPrefixA] = 1
+		e.LookupExpPtr[CmdPrefixB] = 8
+		e.LookupExpPtr[CmdPrefixC] = 15
+		e.LookupExpPtr[CmdPrefixD] = 17
+		e.LookupExpPtr[CmdPrefixE] = 22
+		e.LookupExpPtr[CmdPrefixEo] = 29
+		e.LookupExpPtr[CmdPrefixEq] = 32
+		e.LookupExpPtr[CmdPrefixF] = 36
+		e.LookupExpPtr[CmdPrefixFg] = 47
+		e.LookupExpPtr[CmdPrefixI] = 53
+		e.LookupExpPtr[CmdPrefixK] = 53
+		e.LookupExpPtr[CmdPrefixL] = 65
+		e.LookupExpPtr[CmdPrefixO] = 67
+		e.LookupExpPtr[CmdPrefixP] = 73
+		e.LookupExpPtr[CmdPrefixS] = 75
+		e.LookupExpPtr[CmdPrefixT] = 84
+		e.LookupExpPtr[CmdPrefixTc] = 93
+		e.LookupExpPtr[CmdPrefixTf] = 96
+		e.LookupExpPtr[CmdPrefixU] = 102
+		e.LookupExpPtr[CmdPrefixW] = 108
+		e.LookupExpPtr[CmdPrefixX] = 125
+		e.LookupExpPtr[CmdPrefixY] = 128
+		e.LookupExpPtr[CmdPrefixZ] = 128
+		e.LookupExpPtr[CmdPrefixTilde] = 128
+		e.LookupExpPtr[CmdNoSuch] = 130
+	}
+}
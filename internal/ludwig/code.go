@@ -41,7 +41,7 @@ type parseState struct {
 }
 
 // CodeDiscard releases the specified code and compacts the code array
-func CodeDiscard(codeHead **CodeHeader) {
+func (e *Editor) CodeDiscard(codeHead **CodeHeader) {
 	if *codeHead == nil {
 		return
 	}
@@ -52,21 +52,21 @@ func CodeDiscard(codeHead **CodeHeader) {
 		size := (*codeHead).Len
 
 		for source := start; source < start+size; source++ {
-			if CompilerCode[source].Code != nil {
-				CodeDiscard(&CompilerCode[source].Code)
+			if e.CompilerCode[source].Code != nil {
+				e.CodeDiscard(&e.CompilerCode[source].Code)
 			}
-			if CompilerCode[source].Tpar != nil {
-				TparCleanObject(CompilerCode[source].Tpar)
+			if e.CompilerCode[source].Tpar != nil {
+				TparCleanObject(e.CompilerCode[source].Tpar)
 			}
 		}
 
-		for source := start + size; source <= CodeTop; source++ {
-			CompilerCode[source-size] = CompilerCode[source]
+		for source := start + size; source <= e.CodeTop; source++ {
+			e.CompilerCode[source-size] = e.CompilerCode[source]
 		}
-		CodeTop -= size
+		e.CodeTop -= size
 
 		link := (*codeHead).BLink
-		for link != CodeList {
+		for link != e.CodeList {
 			link.Code -= size
 			link = link.BLink
 		}
@@ -78,7 +78,7 @@ func CodeDiscard(codeHead **CodeHeader) {
 	}
 }
 
-func errorMsg(ps *parseState, errText string) {
+func (e *Editor) errorMsg(ps *parseState, errText string) {
 	ps.status = MsgSyntaxError
 	if ps.fromSpan {
 		// If possible, backup the current point one character
@@ -102,29 +102,26 @@ func errorMsg(ps *parseState, errText string) {
 		}
 
 		// Insert the error message into the span
-		if LudwigMode == LudwigScreen {
-			if !FrameEdit(ps.currentPoint.Line.Group.Frame.Span.Name) {
+		if e.LudwigMode == LudwigScreen {
+			if !e.FrameEdit(ps.currentPoint.Line.Group.Frame.Span.Name) {
 				return
 			}
-			if CurrentFrame.Marks[MarkEquals] != nil {
-				MarkDestroy(&CurrentFrame.Marks[MarkEquals])
+			if e.CurrentFrame.Marks[MarkEquals] != nil {
+				MarkDestroy(&e.CurrentFrame.Marks[MarkEquals])
 			}
 			var eLine *LineHdrObject
 			if !LinesCreate(1, &eLine, &eLine) {
 				return
 			}
 
-			str := NewBlankStrObject(MaxStrLen)
+			str := EmptyStrObject()
 			i := ps.currentPoint.Col
 			str.Set(i, '!')
-			if i < MaxStrLen {
-				i++
-				str.Set(i, ' ')
-			}
-			for (i < MaxStrLen) && len(errText) > 0 {
+			i++
+			str.Set(i, ' ')
+			for _, ch := range errText {
 				i++
-				str.Set(i, errText[0])
-				errText = errText[1:]
+				str.Set(i, ch)
 			}
 			if !LineChangeLength(eLine, i) {
 				return
@@ -132,28 +129,29 @@ func errorMsg(ps *parseState, errText string) {
 			// "i" can't be zero here, so e_line->str != nullptr
 			eLine.Str.Copy(str, 1, i, 1)
 			eLine.Used = str.Length(' ', i)
-			if !LinesInject(eLine, eLine, ps.currentPoint.Line) {
+			if !e.LinesInject(eLine, eLine, ps.currentPoint.Line) {
 				return
 			}
-			MarkCreate(eLine, ps.currentPoint.Col, &CurrentFrame.Dot)
+			MarkCreate(eLine, ps.currentPoint.Col, &e.CurrentFrame.Dot)
 		}
 	}
 }
 
-func nextKey(ps *parseState) bool {
+func (e *Editor) nextKey(ps *parseState) bool {
 	ps.eoln = false
 	if !ps.fromSpan {
-		ps.key = VduGetKey()
-		if TtControlC {
+		ps.key = e.VduGetKey()
+		if e.TtControlC.Load() {
 			return false
 		}
+		e.LearnCompileKey(ps.key)
 	} else {
 		if (ps.currentPoint.Line == ps.endPoint.Line) &&
 			(ps.currentPoint.Col == ps.endPoint.Col) {
 			ps.key = 0 // finished span
 		} else {
 			if ps.currentPoint.Col <= ps.currentPoint.Line.Used {
-				ps.key = int(ps.currentPoint.Line.Str.Get(ps.currentPoint.Col))
+				ps.key = ChToKey(ps.currentPoint.Line.Str.Get(ps.currentPoint.Col))
 				ps.currentPoint.Col++
 			} else if ps.currentPoint.Line != ps.endPoint.Line {
 				ps.key = ' '
@@ -168,10 +166,10 @@ func nextKey(ps *parseState) bool {
 	return true
 }
 
-func nextNonBl(ps *parseState) bool {
+func (e *Editor) nextNonBl(ps *parseState) bool {
 l1:
 	for {
-		if !nextKey(ps) {
+		if !e.nextKey(ps) {
 			return false
 		}
 		if ps.fromSpan {
@@ -197,7 +195,7 @@ l1:
 	return true
 }
 
-func generate(
+func (e *Editor) generate(
 	ps *parseState,
 	irep LeadParam,
 	icnt int,
@@ -211,7 +209,7 @@ func generate(
 		ps.status = MsgCompilerCodeOverflow
 		return false
 	}
-	cc := &CompilerCode[ps.codeBase+ps.pc]
+	cc := &e.CompilerCode[ps.codeBase+ps.pc]
 	cc.Rep = irep
 	cc.Cnt = icnt
 	cc.Op = iop
@@ -221,11 +219,11 @@ func generate(
 	return true
 }
 
-func poke(codeBase, location, newLabel int) {
-	CompilerCode[codeBase+location].Lbl = newLabel
+func (e *Editor) poke(codeBase, location, newLabel int) {
+	e.CompilerCode[codeBase+location].Lbl = newLabel
 }
 
-func getCount(ps *parseState, repCount *int) bool {
+func (e *Editor) getCount(ps *parseState, repCount *int) bool {
 	const maxRepCount = 65535
 
 	if ps.key >= '0' && ps.key <= '9' {
@@ -235,10 +233,10 @@ func getCount(ps *parseState, repCount *int) bool {
 			if *repCount <= (maxRepCount-digit)/10 {
 				*repCount = *repCount*10 + digit
 			} else {
-				errorMsg(ps, "Count too large")
+				e.errorMsg(ps, "Count too large")
 				return false
 			}
-			if !nextKey(ps) {
+			if !e.nextKey(ps) {
 				return false
 			}
 			if ps.key < '0' || ps.key > '9' {
@@ -251,77 +249,77 @@ func getCount(ps *parseState, repCount *int) bool {
 	return true
 }
 
-func scanLeadingParam(ps *parseState, repSym *LeadParam, repCount *int) bool {
+func (e *Editor) scanLeadingParam(ps *parseState, repSym *LeadParam, repCount *int) bool {
 	switch ps.key {
 	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 		*repSym = LeadParamPInt
-		if !getCount(ps, repCount) {
+		if !e.getCount(ps, repCount) {
 			return false
 		}
 
 	case '+':
-		if !nextKey(ps) {
+		if !e.nextKey(ps) {
 			return false
 		}
 		*repSym = LeadParamPlus
 		*repCount = 1
 		if ps.key >= '0' && ps.key <= '9' {
 			*repSym = LeadParamPInt
-			if !getCount(ps, repCount) {
+			if !e.getCount(ps, repCount) {
 				return false
 			}
 		}
 
 	case '-':
-		if !nextKey(ps) {
+		if !e.nextKey(ps) {
 			return false
 		}
 		*repSym = LeadParamMinus
 		*repCount = -1
 		if ps.key >= '0' && ps.key <= '9' {
 			*repSym = LeadParamNInt
-			if !getCount(ps, repCount) {
+			if !e.getCount(ps, repCount) {
 				return false
 			}
 			*repCount = -*repCount
 		}
 
 	case '>', '.':
-		if !nextKey(ps) {
+		if !e.nextKey(ps) {
 			return false
 		}
 		*repSym = LeadParamPIndef
 		*repCount = 0
 
 	case '<', ',':
-		if !nextKey(ps) {
+		if !e.nextKey(ps) {
 			return false
 		}
 		*repSym = LeadParamNIndef
 		*repCount = 0
 
 	case '@':
-		if !nextKey(ps) {
+		if !e.nextKey(ps) {
 			return false
 		}
 		*repSym = LeadParamMarker
-		if !getCount(ps, repCount) {
+		if !e.getCount(ps, repCount) {
 			return false
 		}
 		if (*repCount <= 0) || (*repCount > MaxUserMarkNumber) {
-			errorMsg(ps, "Illegal mark number")
+			e.errorMsg(ps, "Illegal mark number")
 			return false
 		}
 
 	case '=':
-		if !nextKey(ps) {
+		if !e.nextKey(ps) {
 			return false
 		}
 		*repSym = LeadParamMarker
 		*repCount = MarkEquals
 
 	case '%':
-		if !nextKey(ps) {
+		if !e.nextKey(ps) {
 			return false
 		}
 		*repSym = LeadParamMarker
@@ -334,8 +332,8 @@ func scanLeadingParam(ps *parseState, repSym *LeadParam, repCount *int) bool {
 	return true
 }
 
-func scanTrailingParam(ps *parseState, command Commands, repSym LeadParam, tparam **TParObject) bool {
-	tc := CmdAttrib[command].TpCount
+func (e *Editor) scanTrailingParam(ps *parseState, command Commands, repSym LeadParam, tparam **TParObject) bool {
+	tc := e.CmdAttrib[command].TpCount
 	*tparam = nil
 
 	// Some commands only take trailing parameters when repcount is +ve
@@ -348,12 +346,12 @@ func scanTrailingParam(ps *parseState, command Commands, repSym LeadParam, tpara
 	}
 
 	if tc > 0 {
-		if !nextKey(ps) {
+		if !e.nextKey(ps) {
 			return false
 		}
 		parDelim := ps.key
 		if ps.key < 0 || ps.key > MaxSetRange || !ChIsPunctuation(rune(parDelim)) {
-			errorMsg(ps, "Illegal parameter delimiter")
+			e.errorMsg(ps, "Illegal parameter delimiter")
 			return false
 		}
 
@@ -361,24 +359,29 @@ func scanTrailingParam(ps *parseState, command Commands, repSym LeadParam, tpara
 		for tci := 1; tci <= tc; tci++ {
 			for {
 				parLength := 0
-				parString := *NewBlankStrObject(MaxStrLen)
+				parString := *EmptyStrObject()
 				for {
-					if !nextKey(ps) {
+					if !e.nextKey(ps) {
 						return false
 					}
 					if ps.key == 0 {
-						errorMsg(ps, "Missing trailing delimiter")
+						e.errorMsg(ps, "Missing trailing delimiter")
+						return false
+					}
+					ch, ok := KeyToCh(ps.key)
+					if !ok {
+						e.errorMsg(ps, "Illegal character in parameter")
 						return false
 					}
 					parLength++
-					parString.Set(parLength, byte(ps.key))
+					parString.Set(parLength, ch)
 					if ps.eoln || ps.key == parDelim {
 						break
 					}
 				}
 				parLength--
-				if ps.eoln && !CmdAttrib[command].TparInfo[tci].MlAllowed {
-					errorMsg(ps, "Missing trailing delimiter")
+				if ps.eoln && !e.CmdAttrib[command].TparInfo[tci].MlAllowed {
+					e.errorMsg(ps, "Missing trailing delimiter")
 					return false
 				}
 
@@ -414,35 +417,37 @@ func scanTrailingParam(ps *parseState, command Commands, repSym LeadParam, tpara
 	return true
 }
 
-func scanCommand(ps *parseState, fullScan bool) bool {
+func (e *Editor) scanCommand(ps *parseState, fullScan bool) bool {
 	var repCount int
 	var repSym LeadParam
-	if !scanLeadingParam(ps, &repSym, &repCount) {
+	if !e.scanLeadingParam(ps, &repSym, &repCount) {
 		return false
 	}
 
-	if ps.key >= 0 && ps.key <= MaxSetRange {
-		ps.key = int(ChToUpper(byte(ps.key)))
+	if ps.key >= KeyRuneBase {
+		e.errorMsg(ps, "Command not valid")
+		return false
 	}
+	ps.key = ChKeyToUpper(ps.key)
 
-	command := Lookup[ps.key].Command
-	for Prefixes.Bit(int(command)) != 0 {
-		if !nextKey(ps) {
+	command := e.Lookup[ps.key].Command
+	for e.Prefixes.Bit(int(command)) != 0 {
+		if !e.nextKey(ps) {
 			return false
 		}
 		if ps.key < 0 {
-			errorMsg(ps, "Command not valid")
+			e.errorMsg(ps, "Command not valid")
 			return false
 		}
-		i := LookupExpPtr[command]
-		j := LookupExpPtr[command+1]
-		for (i < j) && (int(ChToUpper(byte(ps.key))) != int(LookupExp[i].Extn)) {
+		i := e.LookupExpPtr[command]
+		j := e.LookupExpPtr[command+1]
+		for (i < j) && (ChKeyToUpper(ps.key) != int(e.LookupExp[i].Extn)) {
 			i++
 		}
 		if i < j {
-			command = LookupExp[i].Command
+			command = e.LookupExp[i].Command
 		} else {
-			errorMsg(ps, "Command not valid")
+			e.errorMsg(ps, "Command not valid")
 			return false
 		}
 	}
@@ -450,106 +455,106 @@ func scanCommand(ps *parseState, fullScan bool) bool {
 	var pc1 int
 	if ps.key == '(' {
 		var pc2, pc3 int
-		if !scanCompoundCommand(ps, repSym, repCount, &pc1, &pc2, &pc3) {
+		if !e.scanCompoundCommand(ps, repSym, repCount, &pc1, &pc2, &pc3) {
 			return false
 		}
 	} else if command != CmdNoop {
 		var tparam *TParObject
 		var lookupCode *CodeHeader
-		if !scanSimpleCommand(ps, command, repSym, &repCount, &tparam, &lookupCode, &pc1, fullScan) {
+		if !e.scanSimpleCommand(ps, command, repSym, &repCount, &tparam, &lookupCode, &pc1, fullScan) {
 			return false
 		}
 	} else {
-		errorMsg(ps, "Command not valid")
+		e.errorMsg(ps, "Command not valid")
 		return false
 	}
 
 	if fullScan {
 		var pc4 int
-		if !scanExitHandler(ps, pc1, &pc4, fullScan) {
+		if !e.scanExitHandler(ps, pc1, &pc4, fullScan) {
 			return false
 		}
 	}
 	return true
 }
 
-func scanExitHandler(ps *parseState, pc1 int, pc4 *int, fullScan bool) bool {
-	if !nextNonBl(ps) {
+func (e *Editor) scanExitHandler(ps *parseState, pc1 int, pc4 *int, fullScan bool) bool {
+	if !e.nextNonBl(ps) {
 		return false
 	}
 	if ps.key == '[' {
-		if !nextNonBl(ps) {
+		if !e.nextNonBl(ps) {
 			return false
 		}
 		for (ps.key != ':') && (ps.key != ']') {
 			// Construct exit part
-			if !scanCommand(ps, fullScan) {
+			if !e.scanCommand(ps, fullScan) {
 				return false
 			}
 		}
 		if ps.key == ':' {
 			// Jump over fail handler
-			if !generate(ps, LeadParamNone, 0, CmdPcJump, nil, 0, nil) {
+			if !e.generate(ps, LeadParamNone, 0, CmdPcJump, nil, 0, nil) {
 				return false
 			}
 			*pc4 = ps.pc
-			poke(ps.codeBase, pc1, ps.pc+1) // Set fail label for command
-			if !nextNonBl(ps) {
+			e.poke(ps.codeBase, pc1, ps.pc+1) // Set fail label for command
+			if !e.nextNonBl(ps) {
 				return false
 			}
 			for ps.key != ']' {
 				// Construct fail part
-				if !scanCommand(ps, fullScan) {
+				if !e.scanCommand(ps, fullScan) {
 					return false
 				}
 			}
-			poke(ps.codeBase, *pc4, ps.pc+1) // End of fail handler
+			e.poke(ps.codeBase, *pc4, ps.pc+1) // End of fail handler
 		} else {
-			poke(ps.codeBase, pc1, ps.pc+1) // Set fail label
+			e.poke(ps.codeBase, pc1, ps.pc+1) // Set fail label
 		}
-		if !nextNonBl(ps) {
+		if !e.nextNonBl(ps) {
 			return false
 		}
 	}
 	return true
 }
 
-func scanCompoundCommand(ps *parseState, repSym LeadParam, repCount int, pc1, pc2, pc3 *int) bool {
+func (e *Editor) scanCompoundCommand(ps *parseState, repSym LeadParam, repCount int, pc1, pc2, pc3 *int) bool {
 	if repSym != LeadParamNone && repSym != LeadParamPlus && repSym != LeadParamPInt &&
 		repSym != LeadParamPIndef {
-		errorMsg(ps, "Illegal leading parameter")
+		e.errorMsg(ps, "Illegal leading parameter")
 		return false
 	}
-	if !generate(ps, LeadParamNone, 0, CmdExitTo, nil, 0, nil) {
+	if !e.generate(ps, LeadParamNone, 0, CmdExitTo, nil, 0, nil) {
 		return false
 	}
 	*pc2 = ps.pc
-	if !generate(ps, LeadParamNone, 0, CmdFailTo, nil, 0, nil) {
+	if !e.generate(ps, LeadParamNone, 0, CmdFailTo, nil, 0, nil) {
 		return false
 	}
 	*pc1 = ps.pc
 	*pc3 = ps.pc + 1
 	if repSym != LeadParamPIndef {
-		if !generate(ps, LeadParamNone, repCount, CmdIterate, nil, 0, nil) {
+		if !e.generate(ps, LeadParamNone, repCount, CmdIterate, nil, 0, nil) {
 			return false
 		}
 	}
-	if !nextNonBl(ps) {
+	if !e.nextNonBl(ps) {
 		return false
 	}
 	for ps.key != ')' {
-		if !scanCommand(ps, true) {
+		if !e.scanCommand(ps, true) {
 			return false
 		}
 	}
-	if !generate(ps, LeadParamNone, 0, CmdPcJump, nil, *pc3, nil) {
+	if !e.generate(ps, LeadParamNone, 0, CmdPcJump, nil, *pc3, nil) {
 		return false
 	}
-	poke(ps.codeBase, *pc2, ps.pc+1) // Fill in exit label
+	e.poke(ps.codeBase, *pc2, ps.pc+1) // Fill in exit label
 	return true
 }
 
-func scanSimpleCommand(
+func (e *Editor) scanSimpleCommand(
 	ps *parseState,
 	command Commands,
 	repSym LeadParam,
@@ -560,34 +565,37 @@ func scanSimpleCommand(
 	fullScan bool,
 ) bool {
 	// Check if leading parameter is allowed
-	lpAllowed := CmdAttrib[command].LpAllowed
+	lpAllowed := e.CmdAttrib[command].LpAllowed
 	allowed := false
 	// LpAllowed is a bitset stored as uint32
 	if (lpAllowed & (1 << uint(repSym))) != 0 {
 		allowed = true
 	}
 	if !allowed {
-		errorMsg(ps, "Illegal leading parameter")
+		e.errorMsg(ps, "Illegal leading parameter")
 		return false
 	}
 
 	if command == CmdVerify {
 		ps.verifyCount++
 		if ps.verifyCount > MaxVerify {
-			errorMsg(ps, "Too many verify commands in span")
+			e.errorMsg(ps, "Too many verify commands in span")
 			return false
 		}
 		*repCount = ps.verifyCount
 	}
 
-	*lookupCode = Lookup[ps.key].Code
-	if Lookup[ps.key].Tpar == nil {
-		if CmdAttrib[command].TpCount != 0 {
+	*lookupCode = e.Lookup[ps.key].Code
+	if e.Lookup[ps.key].Tpar == nil {
+		if e.CmdAttrib[command].TpCount != 0 {
 			if fullScan {
-				if !scanTrailingParam(ps, command, repSym, tparam) {
+				if !e.scanTrailingParam(ps, command, repSym, tparam) {
 					return false
 				}
 			} else {
+				if e.CmdAttrib[command].TpCount > 0 || repSym != LeadParamMinus {
+					e.LearnPrompt(abs(e.CmdAttrib[command].TpCount))
+				}
 				*tparam = &TParObject{
 					Str: EmptyStrObject(),
 					Len: 0,
@@ -596,7 +604,7 @@ func scanSimpleCommand(
 					Con: nil,
 				}
 				tmpTp := *tparam
-				for i := 2; i <= CmdAttrib[command].TpCount; i++ {
+				for i := 2; i <= e.CmdAttrib[command].TpCount; i++ {
 					tmpTp.Nxt = &TParObject{
 						Str: EmptyStrObject(),
 						Len: 0,
@@ -611,13 +619,13 @@ func scanSimpleCommand(
 			*tparam = nil
 		}
 	} else {
-		TparDuplicate(Lookup[ps.key].Tpar, tparam)
+		TparDuplicate(e.Lookup[ps.key].Tpar, tparam)
 	}
 
 	if *lookupCode != nil {
 		(*lookupCode).Ref++
 	}
-	if !generate(ps, repSym, *repCount, command, *tparam, 0, *lookupCode) {
+	if !e.generate(ps, repSym, *repCount, command, *tparam, 0, *lookupCode) {
 		return false
 	}
 	*pc1 = ps.pc
@@ -625,7 +633,7 @@ func scanSimpleCommand(
 }
 
 // CodeCompile compiles a span into executable code
-func CodeCompile(span *SpanObject, fromSpan bool) bool {
+func (e *Editor) CodeCompile(span *SpanObject, fromSpan bool) bool {
 	result := false
 	var ps parseState
 	ps.status = ""
@@ -639,32 +647,32 @@ func CodeCompile(span *SpanObject, fromSpan bool) bool {
 	}
 
 	if span.Code != nil {
-		CodeDiscard(&span.Code)
+		e.CodeDiscard(&span.Code)
 	}
 
-	ps.codeBase = CodeTop
+	ps.codeBase = e.CodeTop
 	ps.pc = 0
 	ps.verifyCount = 0
 
-	if !nextNonBl(&ps) {
+	if !e.nextNonBl(&ps) {
 		goto l99
 	}
 	if ps.key == 0 {
-		errorMsg(&ps, "Span contains no commands")
+		e.errorMsg(&ps, "Span contains no commands")
 		goto l99
 	}
 
 	if fromSpan {
 		for ps.key != 0 {
-			if !scanCommand(&ps, true) {
+			if !e.scanCommand(&ps, true) {
 				goto l99
 			}
 		}
-	} else if !scanCommand(&ps, false) {
+	} else if !e.scanCommand(&ps, false) {
 		goto l99
 	}
 
-	if !generate(&ps, LeadParamPInt, 1, CmdExitSuccess, nil, 0, nil) {
+	if !e.generate(&ps, LeadParamPInt, 1, CmdExitSuccess, nil, 0, nil) {
 		goto l99
 	}
 
@@ -673,18 +681,18 @@ func CodeCompile(span *SpanObject, fromSpan bool) bool {
 		Ref:   1,
 		Code:  ps.codeBase + 1,
 		Len:   ps.pc,
-		FLink: CodeList.FLink,
-		BLink: CodeList,
+		FLink: e.CodeList.FLink,
+		BLink: e.CodeList,
 	}
-	CodeList.FLink.BLink = span.Code
-	CodeList.FLink = span.Code
-	CodeTop = ps.codeBase + ps.pc
+	e.CodeList.FLink.BLink = span.Code
+	e.CodeList.FLink = span.Code
+	e.CodeTop = ps.codeBase + ps.pc
 	result = true
 
 l99:
 	if ps.status != "" {
-		ExitAbort = true
-		ScreenMessage(ps.status)
+		e.ExitAbort = true
+		e.ScreenMessage(ps.status)
 	}
 	return result
 }
@@ -696,7 +704,7 @@ type labelsType struct {
 }
 
 // CodeInterpret interprets compiled code
-func CodeInterpret(rept LeadParam, count int, codeHead *CodeHeader, fromSpan bool) bool {
+func (e *Editor) CodeInterpret(rept LeadParam, count int, codeHead *CodeHeader, fromSpan bool) bool {
 	const maxLevel = 100
 	labels := make([]labelsType, maxLevel+1)
 
@@ -718,7 +726,7 @@ func CodeInterpret(rept LeadParam, count int, codeHead *CodeHeader, fromSpan boo
 		failForever
 	)
 	interpStatus := success
-	verifyAlways := InitialVerify
+	verifyAlways := e.InitialVerify
 
 	for (count != 0) && (interpStatus == success) {
 		count--
@@ -730,12 +738,12 @@ func CodeInterpret(rept LeadParam, count int, codeHead *CodeHeader, fromSpan boo
 
 		for pc != 0 {
 			if pc > codeHead.Len {
-				ScreenMessage(DbgPcOutOfRange)
+				e.ScreenMessage(DbgPcOutOfRange)
 				goto l99
 			}
 
 			interpStatus = success
-			cc := &CompilerCode[codeHead.Code-1+pc]
+			cc := &e.CompilerCode[codeHead.Code-1+pc]
 			currLbl := cc.Lbl
 			currOp := cc.Op
 			currRep := cc.Rep
@@ -795,39 +803,39 @@ func CodeInterpret(rept LeadParam, count int, codeHead *CodeHeader, fromSpan boo
 					pc = labels[level+1].failLabel
 
 				case CmdExitAbort:
-					ExitAbort = true
+					e.ExitAbort = true
 					interpStatus = failForever
 					pc = 0
 
 				case CmdExtended:
 					if currCode == nil {
-						ScreenMessage(DbgCodePtrIsNil)
+						e.ScreenMessage(DbgCodePtrIsNil)
 						goto l99
 					}
-					CodeInterpret(currRep, currCnt, currCode, true)
+					e.CodeInterpret(currRep, currCnt, currCode, true)
 
 				case CmdVerify:
 					if !verifyAlways[currCnt] {
-						if LudwigMode == LudwigBatch {
-							ExitAbort = true
+						if e.LudwigMode == LudwigBatch {
+							e.ExitAbort = true
 							interpStatus = failForever
 							pc = 0
-						} else if TparGet1(currTpar, CmdVerify, &request) {
+						} else if e.TparGet1(currTpar, CmdVerify, &request) {
 							if request.Len == 0 {
-								request = CurrentFrame.VerifyTpar
+								request = e.CurrentFrame.VerifyTpar
 								if request.Len == 0 {
-									ScreenMessage(MsgNoDefaultStr)
+									e.ScreenMessage(MsgNoDefaultStr)
 									goto l99
 								}
 							} else {
-								CurrentFrame.VerifyTpar = request
+								e.CurrentFrame.VerifyTpar = request
 							}
 							if request.Str.Get(1) == 'Y' {
 								// do nothing
 							} else if request.Str.Get(1) == 'A' {
 								verifyAlways[currCnt] = true
 							} else if request.Str.Get(1) == 'Q' {
-								ExitAbort = true
+								e.ExitAbort = true
 								interpStatus = failForever
 								pc = 0
 							} else {
@@ -838,22 +846,22 @@ func CodeInterpret(rept LeadParam, count int, codeHead *CodeHeader, fromSpan boo
 					}
 
 				case CmdNoop:
-					ScreenMessage(DbgIllegalInstruction)
+					e.ScreenMessage(DbgIllegalInstruction)
 					goto l99
 				}
 			} else {
 				// Call execute command
-				if !Execute(currOp, currRep, currCnt, currTpar, fromSpan) {
+				if !e.Execute(currOp, currRep, currCnt, currTpar, fromSpan) {
 					interpStatus = failure
 					pc = currLbl
 				}
-				if ExitAbort {
+				if e.ExitAbort {
 					interpStatus = failForever
 					pc = 0
 				}
 			}
 
-			if TtControlC {
+			if e.TtControlC.Load() {
 				interpStatus = failForever
 				pc = 0
 			}
@@ -870,6 +878,6 @@ func CodeInterpret(rept LeadParam, count int, codeHead *CodeHeader, fromSpan boo
 	result = (interpStatus == success)
 l99:
 	TparCleanObject(&request)
-	CodeDiscard(&codeHead)
+	e.CodeDiscard(&codeHead)
 	return result
 }
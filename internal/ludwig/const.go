@@ -41,22 +41,30 @@ const (
 	MarkEquals   = 0
 	MarkModified = 10
 
-	// MaxSpace is the max chars allowed per frame
-	MaxSpace = 1000000
+	// MaxSpace is the max chars allowed per frame, a frame with this much
+	// space is only limited by the memory available
+	MaxSpace = math.MaxInt
 
-	// MaxRecSize is the max length rec. in inp file
-	MaxRecSize = 512
-
-	// MaxStrLen is the max length of a string
-	MaxStrLen  = 400
+	// MaxStrLen is the max length of a string.  Strings grow as text is
+	// put into them, this is far longer than any line needs to be, but
+	// stops a large count filling the memory.
+	MaxStrLen  = 1 << 24
 	MaxStrLenP = MaxStrLen + 1
 
+	// MaxUndoSteps is the max nr of undo steps kept per frame
+	MaxUndoSteps = 100
+
 	// MaxScrRows is the max nr of rows on screen
 	MaxScrRows = 100
 
 	// MaxScrCols is the max nr of cols on screen
 	MaxScrCols = 255
 
+	// MinWindowHeight and MinWindowWidth are the smallest window that the
+	// screen can be split into, the height includes the status line
+	MinWindowHeight = 3
+	MinWindowWidth  = 10
+
 	// MaxCode is the length of code array
 	MaxCode = 4000
 
@@ -74,11 +82,12 @@ const (
 	// TPar interpretations
 	TpdLit         = '\'' // don't do fancy processing on this one
 	TpdSmart       = '`'  // search target is a pattern
+	TpdRegexp      = '~'  // search target is a regular expression
 	TpdExact       = '"'  // use exact case during search
 	TpdSpan        = '$'  // span substitution
 	TpdPrompt      = '&'  // get parameter from user terminal
 	TpdEnvironment = '?'  // environment enquiry
-	ExpandLim      = 130  // at least # of multi-letter commands + 1
+	ExpandLim      = 131  // at least # of multi-letter commands + 1
 
 	// String lengths
 	NameLen     = 31   // Max length of a spn/frm name
@@ -89,6 +98,7 @@ const (
 	MaxSpecialKeys = 1000 // Taken from original XWin def
 	MaxNrKeyNames  = 1000
 	MaxParseTable  = 300
+	KeyRuneBase    = OrdMaxChar + MaxSpecialKeys + 1 // Keys above are characters
 
 	// Regular expression state machine
 	MaxNFAStateRange = 200        // no of states in NFA
@@ -130,6 +140,25 @@ const (
 	PatternMarksEquals   = 19 // marks_start + mark_equals
 
 	PatternAlphaStart = 32 // IE. ASCII
+
+	// Characters above MaxSetRange are matched by their class, unless the
+	// pattern names them, when they have an element of their own above
+	// PatternClassLast.
+	PatternClassUpper   = MaxSetRange + 1 // upper case letters
+	PatternClassLower   = MaxSetRange + 2 // lower case letters
+	PatternClassLetter  = MaxSetRange + 3 // other letters
+	PatternClassNumber  = MaxSetRange + 4
+	PatternClassSpace   = MaxSetRange + 5
+	PatternClassPunct   = MaxSetRange + 6
+	PatternClassOther   = MaxSetRange + 7 // other printable characters
+	PatternClassControl = MaxSetRange + 8 // characters that do not print
+	PatternClassLast    = PatternClassControl
+
+	// Tags a match records columns in, group n of the pattern starts at
+	// tag PatternTagGroups + 2(n-1) and finishes at the tag after it
+	PatternTagMiddleStart = 0 // the start of the middle context
+	PatternTagMiddleEnd   = 1 // the end of the middle context
+	PatternTagGroups      = 2
 )
 
 // Frame names
@@ -142,8 +171,10 @@ const (
 const (
 	MsgBlank                   = ""
 	MsgAbort                   = "Aborted. Output Files may be CORRUPTED"
+	MsgAlreadyLearning         = "Already learning a span."
 	MsgBadFormatInTabTable     = "Bad Format for list of Tab stops."
 	MsgCantKillFrame           = "Can't Kill Frame."
+	MsgCantLearnKey            = "Can't learn that key."
 	MsgCantSplitNullLine       = "Can't split the Null line."
 	MsgCommandNotValid         = "No Command starts with this character."
 	MsgCommandRecursionLimit   = "Command recursion limit exceeded."
@@ -152,6 +183,7 @@ const (
 	MsgCopyrightAndLoadingFile = "Copyright (C) 1981, 1987,  University of Adelaide."
 	MsgCountTooLarge           = "Count too large."
 	MsgDecommitted             = "Warning - Decommitted feature."
+	MsgDestInsideBlock         = "Destination is inside the block."
 	MsgEmptySpan               = "Span is empty."
 	MsgEqualsNotSet            = "The Equals mark is not defined."
 	MsgErrorOpeningKeysFile    = "Error opening keys definitions file."
@@ -180,7 +212,10 @@ const (
 	MsgMarkNotDefined          = "Mark Not Defined."
 	MsgMissingTrailingDelim    = "Missing trailing delimiter."
 	MsgNoDefaultStr            = "No default for trailing parameter string."
+	MsgNoErrors                = "No errors found."
 	MsgNoFileOpen              = "No file open."
+	MsgNoMoreErrors            = "No more errors."
+	MsgNoMatchingLines         = "No lines match."
 	MsgNoMoreFilesAllowed      = "No more files are allowed."
 	MsgNoRoomOnLine            = "Operation would cause a line to become too long."
 	MsgNoSuchFrame             = "No such frame."
@@ -188,18 +223,25 @@ const (
 	MsgNonprintableIntroducer  = "Command Introducer is not printable"
 	MsgNotEnoughInputLeft      = "Not enough input left to satisfy request."
 	MsgNotImplemented          = "Not implemented."
+	MsgNotInFrameList          = "Not in the frame list."
+	MsgNotInOccurList          = "Not on a line listed by UO."
 	MsgNotInputFile            = "File is not an input file."
 	MsgNotOutputFile           = "File is not an output file."
 	MsgNotWhileEditingCmd      = "Operation not allowed while editing frame COMMAND."
 	MsgNotAllowedInInsertMode  = "Command not allowed in insert mode."
+	MsgNotABlock               = "Span is not a block."
+	MsgNotLearning             = "Not learning a span."
+	MsgOnlyOneWindow           = "There is only one window."
 	MsgOptionsSyntaxError      = "Syntax error in options."
 	MsgOutOfRangeTabValue      = "Invalid value for tab stop."
 	MsgParameterTooLong        = "Parameter is too long."
 	MsgPromptsAreOneLine       = "A prompt string must be on one line."
+	MsgRecallWhileLearning     = "Can't recall the span being learnt."
 	MsgScreenModeOnly          = "Command allowed in screen mode only."
 	MsgScreenWidthInvalid      = "Invalid screen width specified."
 	MsgSpanMustBeOneLine       = "A span used as a trailing parameter for this command must be one line."
 	MsgSpanNamesAreOneLine     = "A span name must be on one line."
+	MsgSpanNotInFrame          = "Span is not in the current frame."
 	MsgSpanOfThatNameExists    = "Span of that name already exists."
 	MsgEnquiryMustBeOneLine    = "An enquiry item must be on one line."
 	MsgUnknownItem             = "Unknown enquiry item."
@@ -209,6 +251,7 @@ const (
 	MsgTopMarginLssBottom      = "Top margin must be less than or equal to bottom margin."
 	MsgTparTooDeep             = "Trailing parameter translation has gone too deep."
 	MsgUnknownOption           = "Not a valid option."
+	MsgWindowTooSmall          = "Window is too small to split."
 	MsgPatNoMatchingDelim      = "Pattern - No matching delimiter in pattern."
 	MsgPatIllegalParameter     = "Pattern - Illegal parameter in pattern."
 	MsgPatIllegalMarkNumber    = "Pattern - Illegal mark number in pattern."
@@ -237,6 +280,10 @@ const (
 	MsgInconsistentQualifier   = "Use of this qualifier is inconsistent with file operation"
 	MsgUnrecognizedKeyName     = "Unrecognized key name"
 	MsgKeyNameTruncated        = "Key name too long, name truncated"
+	MsgNothingToUndo           = "Nothing to undo."
+	MsgNothingToRedo           = "Nothing to redo."
+	MsgJournalFailed           = "Can't write the autosave journal."
+	MsgJournalRestored         = "Unsaved changes restored from the autosave journal."
 	DbgInternalLogicError      = "Internal logic error."
 	DbgBadFile                 = "FILE and FILESYS definition of file_object disagree."
 	DbgCantMarkScrBotLine      = "Can't mark scr bot line."
@@ -77,7 +77,7 @@ func PatternDFATableInitialize(patternPtr **DFATableObject, patternDefinition Pa
 // Helper functions for PatternDFAConvert
 
 // epsilonClosures computes the epsilon closure of a state set
-func epsilonClosures(
+func (e *Editor) epsilonClosures(
 	nfaTable *NFATableType,
 	stateSet *NFAAttributeType,
 	closure *NFAAttributeType,
@@ -93,7 +93,7 @@ func epsilonClosures(
 			stack[stackTop] = state
 			closure.EquivSet[state] = true
 		} else {
-			ScreenMessage(MsgPatPatternTooComplex)
+			e.ScreenMessage(MsgPatPatternTooComplex)
 			return false
 		}
 		return true
@@ -148,7 +148,7 @@ func epsilonClosures(
 }
 
 // epsilonAndMask computes epsilon closure and mask for a state
-func epsilonAndMask(
+func (e *Editor) epsilonAndMask(
 	nfaTable *NFATableType,
 	state int,
 	closureSet *[MaxNFAStateRange + 1]bool,
@@ -162,7 +162,7 @@ func epsilonAndMask(
 	auxEltPtr.StateElt = state
 	transitionSet.EquivList = auxEltPtr
 	transitionSet.EquivSet[state] = true
-	if !epsilonClosures(nfaTable, &transitionSet, &transitionSet) {
+	if !e.epsilonClosures(nfaTable, &transitionSet, &transitionSet) {
 		return false
 	}
 	*closureSet = transitionSet.EquivSet
@@ -280,7 +280,7 @@ func bitsetRemoveNFA(set1 *[MaxNFAStateRange + 1]bool, set2 [MaxNFAStateRange +
 }
 
 func bitsetSetRange(set *big.Int, start int, end int) {
-	for i := start; i <= end && i <= MaxSetRange; i++ {
+	for i := start; i <= end && i <= PatternClassLast; i++ {
 		set.SetBit(set, i, 1)
 	}
 }
@@ -291,8 +291,35 @@ func bitsetSetRangeNFA(set *[MaxNFAStateRange + 1]bool, start int, end int) {
 	}
 }
 
+// patternRuneSet finds the characters above MaxSetRange named in the NFA
+// accept sets, and adds each of them to the sets that hold its class unless
+// they were left out of a negated set.
+func patternRuneSet(nfaTable *NFATableType, runeSet *big.Int) {
+	runeSet.SetInt64(0)
+	for i := range nfaTable {
+		if !nfaTable[i].EpsilonOut {
+			runeSet.Or(runeSet, &nfaTable[i].AcceptSet)
+			runeSet.Or(runeSet, &nfaTable[i].ExcludeSet)
+		}
+	}
+	runeSet.Rsh(runeSet, PatternClassLast+1)
+	runeSet.Lsh(runeSet, PatternClassLast+1)
+	for elt := PatternClassLast + 1; elt < runeSet.BitLen(); elt++ {
+		if runeSet.Bit(elt) == 0 {
+			continue
+		}
+		class := patternClass(rune(elt - PatternClassLast + MaxSetRange))
+		for i := range nfaTable {
+			nta := &nfaTable[i]
+			if !nta.EpsilonOut && nta.AcceptSet.Bit(class) != 0 && nta.ExcludeSet.Bit(elt) == 0 {
+				nta.AcceptSet.SetBit(&nta.AcceptSet, elt, 1)
+			}
+		}
+	}
+}
+
 // PatternDFAConvert converts an NFA to a DFA
-func PatternDFAConvert(
+func (e *Editor) PatternDFAConvert(
 	nfaTable *NFATableType,
 	dfaTablePointer *DFATableObject,
 	nfaStart int,
@@ -347,7 +374,7 @@ func PatternDFAConvert(
 			dts.LeftContextCheck = false
 			dts.FinalAccept = false
 		} else {
-			ScreenMessage(MsgPatPatternTooComplex)
+			e.ScreenMessage(MsgPatPatternTooComplex)
 			return false
 		}
 		*stateCount = statesUsed
@@ -398,7 +425,14 @@ func PatternDFAConvert(
 		return false
 	}
 
-	ExitAbort = true // true in case we blow the dfa table or something
+	e.ExitAbort = true // true in case we blow the dfa table or something
+
+	// All the elements of the accept sets, characters above MaxSetRange
+	// named in the pattern are added to every set holding their class.
+	var universe big.Int
+	bitsetSetRange(&universe, 0, PatternClassLast)
+	patternRuneSet(nfaTable, &dfaTablePointer.RuneSet)
+	universe.Or(&universe, &dfaTablePointer.RuneSet)
 
 	// Initialize DFA kill state
 	dtk := &dfaTablePointer.DFATable[PatternDFAKill]
@@ -429,7 +463,7 @@ func PatternDFAConvert(
 	auxElt.StateElt = nfaStart
 	transitionSet.EquivList = auxElt
 	transitionSet.EquivSet[nfaStart] = true
-	if !epsilonClosures(nfaTable, &transitionSet, &auxClosure) {
+	if !e.epsilonClosures(nfaTable, &transitionSet, &auxClosure) {
 		return false
 	}
 	if !patternNewDFA(&auxClosure, dfaStart) {
@@ -437,11 +471,11 @@ func PatternDFAConvert(
 	}
 
 	for unmarkedStates(&currentState) {
-		if TtControlC {
+		if e.TtControlC.Load() {
 			dfaTablePointer.Definition.Length = 0 // invalidate the table
 			return false
 		}
-		bitsetSetRange(&killSet, 0, MaxSetRange)
+		killSet.Set(&universe)
 		partitionPtr = nil
 		dtc := &dfaTablePointer.DFATable[currentState]
 		dtc.Marked = true
@@ -547,7 +581,7 @@ func PatternDFAConvert(
 
 		for partitionPtr != nil {
 			auxPartitionPtr = partitionPtr
-			if !epsilonClosures(nfaTable, &auxPartitionPtr.nfaTransitionList, &transferState) {
+			if !e.epsilonClosures(nfaTable, &auxPartitionPtr.nfaTransitionList, &transferState) {
 				return false
 			}
 			if !patternAddDFA(transferState, &auxPartitionPtr.acceptSetPartition, currentState) {
@@ -600,7 +634,7 @@ func PatternDFAConvert(
 	}
 
 	// Find all end of left context states
-	if !epsilonAndMask(nfaTable, middleContextStart, &closureSet, &mask, true) {
+	if !e.epsilonAndMask(nfaTable, middleContextStart, &closureSet, &mask, true) {
 		return false
 	}
 	for auxCount = PatternDFAStart; auxCount <= statesUsed; auxCount++ {
@@ -653,7 +687,7 @@ func PatternDFAConvert(
 	}
 
 	// Find all end of middle context states
-	if !epsilonAndMask(nfaTable, rightContextStart, &closureSet, &mask, true) {
+	if !e.epsilonAndMask(nfaTable, rightContextStart, &closureSet, &mask, true) {
 		return false
 	}
 	for auxCount = 0; auxCount <= statesUsed; auxCount++ {
@@ -667,6 +701,19 @@ func PatternDFAConvert(
 	*dfaEnd = statesUsed
 	dfaTablePointer.DFAStatesUsed = statesUsed
 
-	ExitAbort = false
+	// Keep the NFA, its tags say where the groups matched
+	dfaTablePointer.NFA = nfaTable
+	dfaTablePointer.NFAStart = nfaStart
+	dfaTablePointer.NFAFinal = *nfaEnd
+	dfaTablePointer.Groups = 0
+	for i := range nfaTable {
+		for _, tag := range nfaTable[i].Tags {
+			if tag >= PatternTagGroups {
+				dfaTablePointer.Groups = max(dfaTablePointer.Groups, (tag-PatternTagGroups)/2+1)
+			}
+		}
+	}
+
+	e.ExitAbort = false
 	return true
 }
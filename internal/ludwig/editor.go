@@ -0,0 +1,255 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         EDITOR
+//
+// Description:  The state of an editing session.  Everything an editing
+//               session changes is held by an Editor, and the commands
+//               work through the Editor they are given, so that several
+//               editors can be used at once, each by one goroutine.
+
+package ludwig
+
+import (
+	"bufio"
+	"io"
+	"math/big"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"ludwig-go/internal/terminal"
+)
+
+// Editor holds the state of an editing session
+type Editor struct {
+	// Configuration
+	ProgramDirectory string
+	// TtControlC and TtWinChanged are set by the signal handler as the
+	// editor runs
+	TtControlC   atomic.Bool
+	TtWinChanged atomic.Bool
+
+	// Keyboard interface
+	NrKeyNames     int
+	KeyNameList    []KeyNameRecord
+	KeyIntroducers [MaxSetRange + 1]bool
+
+	// Special frames
+	CurrentFrame *FrameObject
+	FrameOops    *FrameObject
+	FrameCmd     *FrameObject
+	FrameHeap    *FrameObject
+
+	LudwigAborted bool
+	ExitAbort     bool
+	VduFreeFlag   bool
+	Hangup        bool
+
+	EditMode     ModeType
+	PreviousMode ModeType
+
+	Files       [MaxFiles + 1]*FileObject
+	FilesFrames [MaxFiles + 1]*FrameObject
+
+	FgiFile int
+	FgoFile int
+
+	FirstSpan *SpanObject
+
+	LudwigMode        LudwigModeType
+	CommandIntroducer int
+
+	PromptRegion [MaxTpCount + 1]PromptRegionAttrib
+
+	ScrFrame    *FrameObject
+	ScrTopLine  *LineHdrObject
+	ScrBotLine  *LineHdrObject
+	ScrMsgRow   int
+	ScrNeedsFix bool
+
+	// The windows the screen is split into, nil when it is not split
+	RootWindow    *WindowObject
+	CurrentWindow *WindowObject
+
+	// Compiler variables
+	CompilerCode [MaxCode + 1]CodeObject
+	CodeList     *CodeHeader
+	CodeTop      int
+
+	// Variables used in interpreting a command
+	Prefixes     big.Int
+	Lookup       [OrdMaxChar + MaxSpecialKeys + 1]CommandObject
+	LookupExp    [ExpandLim + 1]LookupExpType
+	LookupExpPtr [CmdNoSuch + 1]int
+	CmdAttrib    [CmdNoSuch + 1]CmdAttribRec
+	DfltPrompts  [PatternSetPrompt + 1]string
+	ExecLevel    int
+	UndoStepNr   int
+
+	// Initial frame settings
+	InitialMarks        MarkArray
+	InitialScrHeight    int
+	InitialScrWidth     int
+	InitialScrOffset    int
+	InitialMarginLeft   int
+	InitialMarginRight  int
+	InitialMarginTop    int
+	InitialMarginBottom int
+	InitialTabStops     TabArray
+	InitialOptions      FrameOptions
+	InitialVerify       VerifyArray
+
+	// Output file actions
+	FileData FileDataType
+
+	// Info about the terminal
+	TerminalInfo TerminalInfoType
+
+	// Output is where messages and other text for the user go when there is
+	// no screen, standard output unless changed
+	Output io.Writer
+
+	// SysWindup is called to wind Ludwig up when a signal tells it to stop
+	SysWindup func(hangup bool)
+
+	// sysFiles holds the files opened by number, so that they are not
+	// closed when the garbage collector finalizes them
+	sysFiles map[int]*os.File
+
+	// Ncurses key range constants
+	MinCursesKey    int
+	MaxCursesKey    int
+	NumNcursesKeys  int
+	NcursesSubtract int
+	MassagedMax     int
+
+	// The screen
+	terminators  map[int]bool
+	vduSetup     bool
+	inInsertMode bool
+	gCtrlC       *atomic.Bool
+	gWinChange   *atomic.Bool
+	vduScreen    terminal.Screen
+	takenBack    []int
+	vduAttr      terminal.Attr   // How text is being drawn
+	vduColour    terminal.Colour // The colour text is being drawn in
+	vduLastKey   time.Time       // When the user last typed a key
+
+	// noKeyLookup is the entry for keys that type characters above
+	// OrdMaxChar, they are not mapped to commands
+	noKeyLookup CommandObject
+
+	// undoDepth counts the nesting of text primitives, only the outermost
+	// primitive records anything.
+	undoDepth int
+	// undoStartFrame and undoStartState hold the state of the current frame
+	// when the last checkpoint was made
+	undoStartFrame *FrameObject
+	undoStartState UndoStep
+
+	// journalWritten is when the journals were last written, journalFailed
+	// is set if a journal could not be written and journalWarned once the
+	// user has been told about it
+	journalWritten time.Time
+	journalFailed  bool
+	journalWarned  bool
+
+	// learnName is the name of the span being learnt, empty when not
+	// learning
+	learnName string
+	// learnLastName is the name of the span most recently learnt
+	learnLastName string
+	// learnLines holds the commands written down so far
+	learnLines []string
+	// learnActive is set while a keyboard command is being written down,
+	// with the command in learnPending and the places for prompted
+	// parameters in learnSlots.
+	learnActive  bool
+	learnPending []byte
+	learnSlots   []learnSlot
+
+	// frameList is frame FRAMES once EL has created it
+	frameList *FrameObject
+	// frameErrors is frame ERRORS, once a command has failed
+	frameErrors *FrameObject
+	// opsysStatus is the exit status of the last command run by OX or UF,
+	// or -1 if it could not be run, and opsysOutput is what it wrote: both
+	// its outputs for OX, and its standard error for UF.  opsysSignal is
+	// the name of the signal that killed it, if one did.
+	opsysStatus int
+	opsysOutput string
+	opsysSignal string
+
+	// patternGroups is the text the last pattern replaced matched, then
+	// the text each of its groups matched
+	patternGroups [][]rune
+
+	// errorList is the list of errors UE made, and errorIndex the one that
+	// was last gone to
+	errorList  []errorEntry
+	errorIndex int
+
+	// frameOccur is frame OCCUR once UO has created it, occurSource the
+	// name of the frame whose lines it lists, and occurList where each of
+	// the lines listed is
+	frameOccur  *FrameObject
+	occurSource string
+	occurList   []occurEntry
+
+	// isearchLine is the line of the match an incremental search is
+	// showing, and isearchStart and isearchEnd the columns of its first
+	// character and of the one after it
+	isearchLine  *LineHdrObject
+	isearchStart int
+	isearchEnd   int
+
+	// matchExpr is the last regular expression whose matches were shown,
+	// compiled into matchTarget if matchValid is set
+	matchExpr   []rune
+	matchTarget *regexpTarget
+	matchValid  bool
+
+	// syntaxLoaded is set once the rule files have been read into
+	// syntaxRules
+	syntaxLoaded bool
+	syntaxRules  []*SyntaxRules
+
+	// The help file being read
+	helpSeeker io.ReadSeeker
+	helpReader *bufio.Reader
+	table      map[string]keyType
+	currentKey keyType
+
+	// Command line option parsing
+	LwOptInd   int
+	LwOptOpt   int
+	LwOptReset bool
+	LwOptArg   string
+	place      string
+}
+
+// NewEditor makes an editor with no frames and no commands yet.
+// ValueInitializations gives it its initial settings.
+func NewEditor() *Editor {
+	e := &Editor{
+		InitialTabStops: DefaultTabStops,
+		Output:          os.Stdout,
+		sysFiles:        map[int]*os.File{},
+		terminators:     map[int]bool{},
+		LwOptInd:        1,
+	}
+
+	// Now create the Code Header for the compiler to use
+	e.CodeList = &CodeHeader{Ref: 1, Code: 1}
+	e.CodeList.FLink = e.CodeList
+	e.CodeList.BLink = e.CodeList
+	return e
+}
@@ -0,0 +1,61 @@
+// Tests for editor.go functions
+
+package ludwig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFrameEditor makes an editor in batch mode editing an empty frame
+func newFrameEditor(t *testing.T) *Editor {
+	e := NewEditor()
+	e.ValueInitializations()
+	require.True(t, e.FrameEdit(DefaultFrameName))
+	return e
+}
+
+// insertText runs an insert command in an editor, as if from a span so
+// that the old command set inserts rather than entering insert mode
+func insertText(e *Editor, text string) bool {
+	tparam := &TParObject{
+		Len: len(text),
+		Dlm: TpdExact,
+		Str: NewStrObjectFrom(text),
+	}
+	return e.Execute(CmdInsertText, LeadParamNone, 1, tparam, true)
+}
+
+func TestEditorsIndependent(t *testing.T) {
+	// Each editor has a frame of the same name, and its own text in it
+	for i := range 4 {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			e := newFrameEditor(t)
+			frame := e.CurrentFrame
+			for range 50 {
+				require.True(t, insertText(e, fmt.Sprint(i)))
+			}
+			assert.Equal(t, []string{strings.Repeat(fmt.Sprint(i), 50)}, frameText(frame))
+			assert.Equal(t, 51, frame.Dot.Col)
+		})
+	}
+}
+
+func TestEditorSpans(t *testing.T) {
+	one := newFrameEditor(t)
+	two := newFrameEditor(t)
+	require.True(t, insertText(one, "text"))
+
+	// A frame made in one editor cannot be seen by the other
+	require.True(t, one.FrameEdit("OTHER"))
+	var span, oldp *SpanObject
+	assert.True(t, one.SpanFind("OTHER", &span, &oldp))
+	assert.False(t, two.SpanFind("OTHER", &span, &oldp))
+	assert.Equal(t, DefaultFrameName, two.CurrentFrame.Span.Name)
+	assert.Equal(t, []string{}, frameText(two.CurrentFrame))
+}
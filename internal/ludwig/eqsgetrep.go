@@ -44,8 +44,8 @@ func eqsgetrepSamePatternDef(pattern1 *PatternDefType, pattern2 *PatternDefType)
 	return false
 }
 
-func eqsgetrepPatternBuild(tpar TParObject, patternPtr **DFATableObject) bool {
-	patternDefinition := PatternDefType{Strng: *NewBlankStrObject(MaxStrLen)}
+func (e *Editor) eqsgetrepPatternBuild(tpar TParObject, patternPtr **DFATableObject) bool {
+	patternDefinition := PatternDefType{Strng: *EmptyStrObject()}
 	var nfaTable NFATableType
 	var firstPatternStart int
 	var patternFinalState int
@@ -53,7 +53,7 @@ func eqsgetrepPatternBuild(tpar TParObject, patternPtr **DFATableObject) bool {
 	var middleContextEnd int
 	var statesUsed int
 
-	if PatternParser(
+	if e.PatternParser(
 		&tpar,
 		&nfaTable,
 		&firstPatternStart,
@@ -74,7 +74,7 @@ func eqsgetrepPatternBuild(tpar TParObject, patternPtr **DFATableObject) bool {
 				return false
 			}
 			var dfaStart, dfaEnd int
-			if !PatternDFAConvert(
+			if !e.PatternDFAConvert(
 				&nfaTable,
 				*patternPtr,
 				firstPatternStart,
@@ -93,46 +93,49 @@ func eqsgetrepPatternBuild(tpar TParObject, patternPtr **DFATableObject) bool {
 	return true
 }
 
-func EqsGetRepEqs(rept LeadParam, tpar TParObject) bool {
+func (e *Editor) EqsGetRepEqs(rept LeadParam, tpar TParObject) bool {
 	success := false
 
+	if tpar.Dlm == TpdRegexp {
+		return e.eqsgetrepRegexpEqs(rept, tpar)
+	}
 	if tpar.Dlm == TpdSmart {
-		if !eqsgetrepPatternBuild(tpar, &CurrentFrame.EqsPatternPtr) {
+		if !e.eqsgetrepPatternBuild(tpar, &e.CurrentFrame.EqsPatternPtr) {
 			return false
 		}
 		markFlag := false
 		var startCol int
 		var endPos int
-		found := PatternRecognize(
-			CurrentFrame.EqsPatternPtr,
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
+		found := e.PatternRecognize(
+			e.CurrentFrame.EqsPatternPtr,
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
 			&markFlag,
 			&startCol,
 			&endPos,
 		)
 		switch rept {
 		case LeadParamNone, LeadParamPlus:
-			success = (CurrentFrame.Dot.Col == startCol) && found
+			success = (e.CurrentFrame.Dot.Col == startCol) && found
 		case LeadParamMinus:
-			success = !((CurrentFrame.Dot.Col == startCol) && found)
+			success = !((e.CurrentFrame.Dot.Col == startCol) && found)
 		case LeadParamPIndef:
-			success = (endPos <= CurrentFrame.Dot.Line.Used) && found
+			success = (endPos <= e.CurrentFrame.Dot.Line.Used) && found
 		case LeadParamNIndef:
-			success = (endPos >= CurrentFrame.Dot.Line.Used) && found
+			success = (endPos >= e.CurrentFrame.Dot.Line.Used) && found
 		}
 		if success && rept != LeadParamMinus {
-			success = MarkCreate(CurrentFrame.Dot.Line, endPos, &CurrentFrame.Marks[MarkEquals])
+			success = MarkCreate(e.CurrentFrame.Dot.Line, endPos, &e.CurrentFrame.Marks[MarkEquals])
 		}
 	} else {
 		exactcase := eqsgetrepExactcase(&tpar)
-		startCol := CurrentFrame.Dot.Col
+		startCol := e.CurrentFrame.Dot.Col
 		var length int
-		if startCol > CurrentFrame.Dot.Line.Used {
+		if startCol > e.CurrentFrame.Dot.Line.Used {
 			length = 0
 			startCol = 1
 		} else {
-			length = CurrentFrame.Dot.Line.Used + 1 - CurrentFrame.Dot.Col
+			length = e.CurrentFrame.Dot.Line.Used + 1 - e.CurrentFrame.Dot.Col
 		}
 		if length > tpar.Len {
 			length = tpar.Len
@@ -143,7 +146,7 @@ func EqsGetRepEqs(rept LeadParam, tpar TParObject) bool {
 			tpar.Str,
 			1,
 			tpar.Len,
-			CurrentFrame.Dot.Line.Str,
+			e.CurrentFrame.Dot.Line.Str,
 			startCol,
 			length,
 			exactcase,
@@ -161,20 +164,45 @@ func EqsGetRepEqs(rept LeadParam, tpar TParObject) bool {
 		}
 		if success && rept != LeadParamMinus {
 			success = MarkCreate(
-				CurrentFrame.Dot.Line,
-				CurrentFrame.Dot.Col+nchIdent,
-				&CurrentFrame.Marks[MarkEquals],
+				e.CurrentFrame.Dot.Line,
+				e.CurrentFrame.Dot.Col+nchIdent,
+				&e.CurrentFrame.Marks[MarkEquals],
 			)
 		}
 	}
 	return success
 }
 
-func eqsgetrepDumbGet(count int, tpar TParObject, fromSpan bool) bool {
+// eqsgetrepRegexpEqs matches a regular expression at Dot
+func (e *Editor) eqsgetrepRegexpEqs(rept LeadParam, tpar TParObject) bool {
+	var target regexpTarget
+	if !e.regexpCompile(&tpar, &target) {
+		return false
+	}
+	target.page = e
+	dot := e.CurrentFrame.Dot
+	loc := target.regexpMatch(dot.Line, dot.Col, nil, 0)
+	switch rept {
+	case LeadParamNone, LeadParamPlus:
+		if loc == nil {
+			return false
+		}
+	case LeadParamMinus:
+		return loc == nil
+	default:
+		// A regular expression has no order to compare the text by
+		e.ScreenMessage(MsgIllegalLeadingParam)
+		return false
+	}
+	line, col := regexpPosition(dot.Line, dot.Col, loc[1])
+	return MarkCreate(line, col, &e.CurrentFrame.Marks[MarkEquals])
+}
+
+func (e *Editor) eqsgetrepDumbGet(count int, tpar TParObject, fromSpan bool) bool {
 	result := (count == 0)
 
-	dotLine := CurrentFrame.Dot.Line
-	dotCol := CurrentFrame.Dot.Col
+	dotLine := e.CurrentFrame.Dot.Line
+	dotCol := e.CurrentFrame.Dot.Col
 	exactcase := eqsgetrepExactcase(&tpar)
 	line := dotLine
 	newlen := tpar.Len
@@ -185,7 +213,7 @@ func eqsgetrepDumbGet(count int, tpar TParObject, fromSpan bool) bool {
 	} else {
 		tailSpace = false
 	}
-	newstr := NewBlankStrObject(MaxStrLen)
+	newstr := NewBlankStrObject(newlen)
 	var backwards bool
 	var startCol int
 	var length int
@@ -194,14 +222,14 @@ func eqsgetrepDumbGet(count int, tpar TParObject, fromSpan bool) bool {
 		ChReverseStr(tpar.Str, newstr, newlen)
 		backwards = true
 		startCol = 1
-		length = CurrentFrame.Dot.Col - 1
+		length = e.CurrentFrame.Dot.Col - 1
 		if length > line.Used {
 			length = line.Used
 		}
 	} else {
 		newstr = tpar.Str
 		backwards = false
-		startCol = CurrentFrame.Dot.Col
+		startCol = e.CurrentFrame.Dot.Col
 		if startCol > line.Used {
 			length = 0
 		} else {
@@ -209,7 +237,7 @@ func eqsgetrepDumbGet(count int, tpar TParObject, fromSpan bool) bool {
 		}
 	}
 
-	for count > 0 && !TtControlC {
+	for count > 0 && !e.TtControlC.Load() {
 		var found bool
 		var offset int
 		if length == 0 {
@@ -229,7 +257,7 @@ func eqsgetrepDumbGet(count int, tpar TParObject, fromSpan bool) bool {
 		}
 		if found {
 			if tailSpace {
-				var tailChar byte
+				var tailChar rune
 				if startCol+offset+newlen <= line.Used {
 					tailChar = line.Str.Get(startCol + offset + newlen)
 				} else if startCol+offset+newlen == line.Used+1 {
@@ -256,19 +284,19 @@ func eqsgetrepDumbGet(count int, tpar TParObject, fromSpan bool) bool {
 			}
 			count--
 			if count == 0 {
-				if !MarkCreate(line, startCol, &CurrentFrame.Dot) {
+				if !MarkCreate(line, startCol, &e.CurrentFrame.Dot) {
 					goto l99
 				}
 				if !fromSpan {
-					switch ScreenVerify(thisOne) {
+					switch e.ScreenVerify(thisOne) {
 					case VerifyReplyAlways, VerifyReplyYes:
 						break
 					case VerifyReplyQuit, VerifyReplyNo:
 						count = 1
-						if !MarkCreate(dotLine, dotCol, &CurrentFrame.Dot) {
+						if !MarkCreate(dotLine, dotCol, &e.CurrentFrame.Dot) {
 							goto l99
 						}
-						if ExitAbort {
+						if e.ExitAbort {
 							goto l99
 						} else {
 							goto l1
@@ -276,11 +304,11 @@ func eqsgetrepDumbGet(count int, tpar TParObject, fromSpan bool) bool {
 					}
 				}
 				if backwards {
-					if !MarkCreate(line, startCol+tpar.Len, &CurrentFrame.Marks[MarkEquals]) {
+					if !MarkCreate(line, startCol+tpar.Len, &e.CurrentFrame.Marks[MarkEquals]) {
 						goto l99
 					}
 				} else {
-					if !MarkCreate(line, startCol-tpar.Len, &CurrentFrame.Marks[MarkEquals]) {
+					if !MarkCreate(line, startCol-tpar.Len, &e.CurrentFrame.Marks[MarkEquals]) {
 						goto l99
 					}
 				}
@@ -301,7 +329,7 @@ func eqsgetrepDumbGet(count int, tpar TParObject, fromSpan bool) bool {
 			if backwards {
 				line = line.BLink
 			} else {
-				line = line.FLink
+				line = e.FileNextLine(line)
 			}
 			if line == nil {
 				goto l99
@@ -314,21 +342,21 @@ l99:
 	return result
 }
 
-func eqsgetrepPatternGet(count int, tpar TParObject, fromSpan bool, replaceFlag bool) bool {
+func (e *Editor) eqsgetrepPatternGet(count int, tpar TParObject, fromSpan bool, replaceFlag bool) bool {
 	result := (count == 0)
 
 	var patternPtr *DFATableObject
 	if !replaceFlag {
-		if !eqsgetrepPatternBuild(tpar, &CurrentFrame.GetPatternPtr) {
+		if !e.eqsgetrepPatternBuild(tpar, &e.CurrentFrame.GetPatternPtr) {
 			return result
 		}
-		patternPtr = CurrentFrame.GetPatternPtr
+		patternPtr = e.CurrentFrame.GetPatternPtr
 	} else {
-		patternPtr = CurrentFrame.RepPatternPtr
+		patternPtr = e.CurrentFrame.RepPatternPtr
 	}
 
-	dotLine := CurrentFrame.Dot.Line
-	dotCol := CurrentFrame.Dot.Col
+	dotLine := e.CurrentFrame.Dot.Line
+	dotCol := e.CurrentFrame.Dot.Col
 	line := dotLine
 	markFlag := false
 	backwards := count < 0
@@ -343,10 +371,11 @@ func eqsgetrepPatternGet(count int, tpar TParObject, fromSpan bool, replaceFlag
 		startCol = line.Used + 1
 	}
 
-	for count > 0 && !TtControlC {
+	for count > 0 && !e.TtControlC.Load() {
 		var matchedStartCol int
 		var matchedFinishCol int
-		if PatternRecognize(
+		scanCol, scanMarkFlag := startCol, markFlag
+		if e.PatternRecognize(
 			patternPtr,
 			line,
 			startCol,
@@ -357,25 +386,28 @@ func eqsgetrepPatternGet(count int, tpar TParObject, fromSpan bool, replaceFlag
 			if !((line == dotLine) && (matchedFinishCol >= dotCol) && backwards) {
 				count--
 				if count == 0 {
+					if replaceFlag {
+						e.eqsgetrepGroups(patternPtr, line, scanCol, scanMarkFlag, matchedStartCol, matchedFinishCol)
+					}
 					if backwards {
-						if !MarkCreate(line, matchedStartCol, &CurrentFrame.Dot) {
+						if !MarkCreate(line, matchedStartCol, &e.CurrentFrame.Dot) {
 							goto l99
 						}
 					} else {
-						if !MarkCreate(line, matchedFinishCol, &CurrentFrame.Dot) {
+						if !MarkCreate(line, matchedFinishCol, &e.CurrentFrame.Dot) {
 							goto l99
 						}
 					}
 					if !fromSpan {
-						switch ScreenVerify(thisOne) {
+						switch e.ScreenVerify(thisOne) {
 						case VerifyReplyAlways, VerifyReplyYes:
 							break
 						case VerifyReplyQuit, VerifyReplyNo:
 							count = 1
-							if !MarkCreate(dotLine, dotCol, &CurrentFrame.Dot) {
+							if !MarkCreate(dotLine, dotCol, &e.CurrentFrame.Dot) {
 								goto l99
 							}
-							if ExitAbort {
+							if e.ExitAbort {
 								goto l99
 							} else {
 								goto l1
@@ -383,10 +415,10 @@ func eqsgetrepPatternGet(count int, tpar TParObject, fromSpan bool, replaceFlag
 						}
 					}
 					if backwards {
-						if !MarkCreate(line, matchedFinishCol, &CurrentFrame.Marks[MarkEquals]) {
+						if !MarkCreate(line, matchedFinishCol, &e.CurrentFrame.Marks[MarkEquals]) {
 							goto l99
 						}
-					} else if !MarkCreate(line, matchedStartCol, &CurrentFrame.Marks[MarkEquals]) {
+					} else if !MarkCreate(line, matchedStartCol, &e.CurrentFrame.Marks[MarkEquals]) {
 						goto l99
 					}
 					result = true
@@ -401,7 +433,7 @@ func eqsgetrepPatternGet(count int, tpar TParObject, fromSpan bool, replaceFlag
 					if backwards {
 						line = line.BLink
 					} else {
-						line = line.FLink
+						line = e.FileNextLine(line)
 					}
 					if line == nil {
 						goto l99
@@ -421,7 +453,7 @@ func eqsgetrepPatternGet(count int, tpar TParObject, fromSpan bool, replaceFlag
 			if backwards {
 				line = line.BLink
 			} else {
-				line = line.FLink
+				line = e.FileNextLine(line)
 			}
 			if line == nil {
 				goto l99
@@ -435,14 +467,188 @@ l99:
 	return result
 }
 
-func EqsGetRepGet(count int, tpar TParObject, fromSpan bool) bool {
+// eqsgetrepRegexpGet finds the count'th match of a regular expression,
+// forwards from Dot or backwards if count is negative.  A match can run
+// on over the end of a line, leaving Dot and Equals on different lines.
+func (e *Editor) eqsgetrepRegexpGet(count int, target *regexpTarget, fromSpan bool, replaceFlag bool) bool {
+	result := (count == 0)
+
+	dotLine := e.CurrentFrame.Dot.Line
+	dotCol := e.CurrentFrame.Dot.Col
+	line := dotLine
+	col := dotCol
+	backwards := count < 0
+	count = int(math.Abs(float64(count)))
+
+	for count > 0 && !e.TtControlC.Load() {
+		var loc []int
+		if backwards {
+			line, col, loc = e.regexpFindBackwards(target, line, col)
+		} else {
+			line, col, loc = target.regexpFind(line, col, nil, 0)
+		}
+		if loc == nil {
+			break
+		}
+		startLine, startCol := regexpPosition(line, col, loc[0])
+		finishLine, finishCol := regexpPosition(line, col, loc[1])
+		count--
+		if count == 0 {
+			if replaceFlag {
+				e.patternGroups = e.patternGroups[:0]
+				for i := 0; i < len(loc); i += 2 {
+					e.patternGroups = append(e.patternGroups, regexpText(line, col, loc[i], loc[i+1]))
+				}
+			}
+			if backwards {
+				if !MarkCreate(startLine, startCol, &e.CurrentFrame.Dot) {
+					break
+				}
+			} else if !MarkCreate(finishLine, finishCol, &e.CurrentFrame.Dot) {
+				break
+			}
+			verified := true
+			if !fromSpan {
+				switch e.ScreenVerify(thisOne) {
+				case VerifyReplyQuit, VerifyReplyNo:
+					verified = false
+				}
+			}
+			if verified {
+				if backwards {
+					result = MarkCreate(finishLine, finishCol, &e.CurrentFrame.Marks[MarkEquals])
+				} else {
+					result = MarkCreate(startLine, startCol, &e.CurrentFrame.Marks[MarkEquals])
+				}
+				break
+			}
+			count = 1
+			if !MarkCreate(dotLine, dotCol, &e.CurrentFrame.Dot) || e.ExitAbort {
+				break
+			}
+		}
+		if backwards {
+			line, col = startLine, startCol
+		} else {
+			line, col = finishLine, finishCol
+			if loc[1] == loc[0] {
+				// Go past an empty match so as not to find it again
+				r := regexpReader{line: line, col: col, prev: -1, page: e}
+				if _, _, err := r.ReadRune(); err != nil {
+					break
+				}
+				line, col = r.line, r.col
+			}
+		}
+	}
+	return result
+}
+
+// eqsgetrepGroups keeps the text a pattern matched, and the text each of
+// its groups matched, for the replacement to refer to
+func (e *Editor) eqsgetrepGroups(
+	patternPtr *DFATableObject,
+	line *LineHdrObject,
+	scanCol int,
+	markFlag bool,
+	startCol int,
+	finishCol int,
+) {
+	text := func(start, finish int) []rune {
+		finish = min(finish, line.Used+1)
+		if start < 1 || start >= finish {
+			return nil
+		}
+		return line.Str.Runes()[start-1 : finish-1]
+	}
+	e.patternGroups = [][]rune{text(startCol, finishCol)}
+	tags := e.PatternSubmatches(patternPtr, line, scanCol, markFlag, startCol, finishCol)
+	for group := range patternPtr.Groups {
+		if tags == nil {
+			e.patternGroups = append(e.patternGroups, nil)
+		} else {
+			tag := PatternTagGroups + 2*group
+			e.patternGroups = append(e.patternGroups, text(tags[tag], tags[tag+1]))
+		}
+	}
+}
+
+// eqsgetrepExpand makes the replacement for a pattern, with &1 to &9
+// replaced by the text the groups of the pattern matched, &0 by the text
+// the whole pattern matched, and && by &.  The text of a group that runs
+// over the end of a line makes more lines.  The characters are copied as
+// they are, so that raw bytes are kept.
+func (e *Editor) eqsgetrepExpand(tpar *TParObject, expanded *TParObject) bool {
+	var text []rune
+	for tp := tpar; tp != nil; tp = tp.Con {
+		if tp != tpar {
+			text = append(text, '\n')
+		}
+		str := tp.Str.Runes()[:tp.Len]
+		for i := 0; i < len(str); i++ {
+			if str[i] == '&' && i+1 < len(str) {
+				next := str[i+1]
+				if next >= '0' && next <= '9' {
+					if group := int(next - '0'); group < len(e.patternGroups) {
+						text = append(text, e.patternGroups[group]...)
+					}
+					i++
+					continue
+				} else if next == '&' {
+					text = append(text, '&')
+					i++
+					continue
+				}
+			}
+			text = append(text, str[i])
+		}
+	}
+	lines := [][]rune{nil}
+	for _, ch := range text {
+		if ch == '\n' {
+			lines = append(lines, nil)
+		} else {
+			lines[len(lines)-1] = append(lines[len(lines)-1], ch)
+		}
+	}
+	tp := expanded
+	for i, line := range lines {
+		if len(line) > MaxStrLen {
+			e.ScreenMessage(MsgNoRoomOnLine)
+			return false
+		}
+		if i > 0 {
+			tp.Con = &TParObject{}
+			tp = tp.Con
+		}
+		*tp = TParObject{Len: len(line), Dlm: tpar.Dlm, Str: NewStrObjectRunes(line)}
+	}
+	return true
+}
+
+func (e *Editor) EqsGetRepGet(count int, tpar TParObject, fromSpan bool) bool {
 	if tpar.Dlm == TpdSmart {
-		return eqsgetrepPatternGet(count, tpar, fromSpan, false)
+		return e.eqsgetrepPatternGet(count, tpar, fromSpan, false)
+	}
+	if tpar.Dlm == TpdRegexp {
+		var target regexpTarget
+		if !e.regexpCompile(&tpar, &target) {
+			return false
+		}
+		target.page = e
+		return e.eqsgetrepRegexpGet(count, &target, fromSpan, false)
 	}
-	return eqsgetrepDumbGet(count, tpar, fromSpan)
+	return e.eqsgetrepDumbGet(count, tpar, fromSpan)
 }
 
-func EqsGetRepRep(rept LeadParam, count int, tpar TParObject, tpar2 TParObject, fromSpan bool) bool {
+// eqsgetrepEmptyAt returns true if the last Get matched nothing at a mark
+func eqsgetrepEmptyAt(frame *FrameObject, mark *MarkObject) bool {
+	dot, equals := frame.Dot, frame.Marks[MarkEquals]
+	return dot.Line == equals.Line && dot.Col == equals.Col &&
+		dot.Line == mark.Line && dot.Col == mark.Col
+}
+
+func (e *Editor) EqsGetRepRep(rept LeadParam, count int, tpar TParObject, tpar2 TParObject, fromSpan bool) bool {
 	var getcount int
 	var length int
 	var delta int
@@ -450,24 +656,34 @@ func EqsGetRepRep(rept LeadParam, count int, tpar TParObject, tpar2 TParObject,
 	var oldDot *MarkObject
 	var oldEquals *MarkObject
 	var okay bool
+	var target regexpTarget
+	var pattern2 TParObject // the replacement before the groups go in
+	replaced := false
 	result := false
 
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &oldDot) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &oldDot) {
 		goto l99
 	}
-	if CurrentFrame.Marks[MarkEquals] != nil {
+	if e.CurrentFrame.Marks[MarkEquals] != nil {
 		if !MarkCreate(
-			CurrentFrame.Marks[MarkEquals].Line,
-			CurrentFrame.Marks[MarkEquals].Col,
+			e.CurrentFrame.Marks[MarkEquals].Line,
+			e.CurrentFrame.Marks[MarkEquals].Col,
 			&oldEquals,
 		) {
 			goto l99
 		}
 	}
 	if tpar.Dlm == TpdSmart {
-		if !eqsgetrepPatternBuild(tpar, &CurrentFrame.RepPatternPtr) {
+		if !e.eqsgetrepPatternBuild(tpar, &e.CurrentFrame.RepPatternPtr) {
 			goto l99
 		}
+		pattern2 = tpar2
+	} else if tpar.Dlm == TpdRegexp {
+		if !e.regexpCompile(&tpar, &target) {
+			goto l99
+		}
+		target.page = e
+		pattern2 = tpar2
 	}
 	getcount = 1
 	if rept == LeadParamMinus || rept == LeadParamNIndef || rept == LeadParamNInt {
@@ -481,21 +697,37 @@ func EqsGetRepRep(rept LeadParam, count int, tpar TParObject, tpar2 TParObject,
 	for count > 0 {
 		for {
 			okay = true
-			if TtControlC || ExitAbort {
+			if e.TtControlC.Load() || e.ExitAbort {
 				goto l1
 			}
 			if tpar.Dlm == TpdSmart {
-				if !eqsgetrepPatternGet(getcount, tpar, true, true) {
+				if !e.eqsgetrepPatternGet(getcount, tpar, true, true) {
+					goto l1
+				}
+			} else if tpar.Dlm == TpdRegexp {
+				if !e.eqsgetrepRegexpGet(getcount, &target, true, true) {
 					goto l1
 				}
-			} else if !eqsgetrepDumbGet(getcount, tpar, true) {
+				if replaced && getcount > 0 && eqsgetrepEmptyAt(e.CurrentFrame, oldDot) {
+					// An empty match where the last replacement ended would
+					// be found again after every replacement, go past it.
+					r := regexpReader{line: oldDot.Line, col: oldDot.Col, prev: -1, page: e}
+					if _, _, err := r.ReadRune(); err != nil {
+						goto l1
+					}
+					if !MarkCreate(r.line, r.col, &e.CurrentFrame.Dot) {
+						goto l99
+					}
+					continue
+				}
+			} else if !e.eqsgetrepDumbGet(getcount, tpar, true) {
 				goto l1
 			}
-			if TtControlC || ExitAbort {
+			if e.TtControlC.Load() || e.ExitAbort {
 				goto l1
 			}
 			if !fromSpan {
-				switch ScreenVerify(replaceThisOne) {
+				switch e.ScreenVerify(replaceThisOne) {
 				case VerifyReplyAlways:
 					fromSpan = true
 				case VerifyReplyYes:
@@ -508,93 +740,108 @@ func EqsGetRepRep(rept LeadParam, count int, tpar TParObject, tpar2 TParObject,
 				break
 			}
 		}
-		length = CurrentFrame.Marks[MarkEquals].Col - CurrentFrame.Dot.Col
+		if (tpar.Dlm == TpdSmart || tpar.Dlm == TpdRegexp) && !e.eqsgetrepExpand(&pattern2, &tpar2) {
+			goto l99
+		}
+		if e.CurrentFrame.Dot.Line != e.CurrentFrame.Marks[MarkEquals].Line {
+			// A regular expression matched over the end of a line.  Remove
+			// the match, and replace it as if it were empty.
+			first, last := e.CurrentFrame.Marks[MarkEquals], e.CurrentFrame.Dot
+			if getcount < 0 {
+				first, last = last, first
+			}
+			if !e.TextRemove(first, last) {
+				goto l99
+			}
+		}
+		length = e.CurrentFrame.Marks[MarkEquals].Col - e.CurrentFrame.Dot.Col
 		if length < 0 {
-			CurrentFrame.Dot.Col = CurrentFrame.Marks[MarkEquals].Col
-			CurrentFrame.Marks[MarkEquals].Col = CurrentFrame.Dot.Col - length
+			e.CurrentFrame.Dot.Col = e.CurrentFrame.Marks[MarkEquals].Col
+			e.CurrentFrame.Marks[MarkEquals].Col = e.CurrentFrame.Dot.Col - length
 			length = -length
 		}
 		if tpar2.Con == nil {
-			startCol = CurrentFrame.Dot.Col
+			startCol = e.CurrentFrame.Dot.Col
 			delta = length - tpar2.Len
 			if delta > 0 {
-				if CurrentFrame.Dot.Col+delta > CurrentFrame.Dot.Line.Used+1 {
-					delta = CurrentFrame.Dot.Line.Used + 1 - CurrentFrame.Dot.Col
+				if e.CurrentFrame.Dot.Col+delta > e.CurrentFrame.Dot.Line.Used+1 {
+					delta = e.CurrentFrame.Dot.Line.Used + 1 - e.CurrentFrame.Dot.Col
 				}
 				if delta > 0 {
-					if !CharcmdDelete(CmdDeleteChar, LeadParamPInt, delta, true) {
+					if !e.CharcmdDelete(CmdDeleteChar, LeadParamPInt, delta, true) {
 						goto l99
 					}
 				}
 			} else if delta < 0 {
-				if !CharcmdInsert(CmdInsertChar, LeadParamPInt, -delta, true) {
+				if !e.CharcmdInsert(CmdInsertChar, LeadParamPInt, -delta, true) {
 					goto l99
 				}
-				CurrentFrame.Dot.Col = startCol
+				e.CurrentFrame.Dot.Col = startCol
 			}
-			if !TextOvertype(true, 1, tpar2.Str, tpar2.Len, CurrentFrame.Dot) {
+			if !e.TextOvertype(true, 1, tpar2.Str, tpar2.Len, e.CurrentFrame.Dot) {
 				goto l99
 			}
 			if getcount > 0 {
 				if !MarkCreate(
-					CurrentFrame.Dot.Line, startCol, &CurrentFrame.Marks[MarkEquals],
+					e.CurrentFrame.Dot.Line, startCol, &e.CurrentFrame.Marks[MarkEquals],
 				) {
 					goto l99
 				}
 			} else {
 				if !MarkCreate(
-					CurrentFrame.Dot.Line,
+					e.CurrentFrame.Dot.Line,
 					startCol+tpar2.Len,
-					&CurrentFrame.Marks[MarkEquals],
+					&e.CurrentFrame.Marks[MarkEquals],
 				) {
 					goto l99
 				}
-				CurrentFrame.Dot.Col = startCol
+				e.CurrentFrame.Dot.Col = startCol
 			}
 		} else {
-			if !CharcmdDelete(CmdDeleteChar, LeadParamPInt, length, true) {
+			if !e.CharcmdDelete(CmdDeleteChar, LeadParamPInt, length, true) {
 				goto l99
 			}
-			if !TextInsertTpar(&tpar2, CurrentFrame.Dot, &CurrentFrame.Marks[MarkEquals]) {
+			if !e.TextInsertTpar(&tpar2, e.CurrentFrame.Dot, &e.CurrentFrame.Marks[MarkEquals]) {
 				goto l99
 			}
 		}
-		if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &oldDot) {
+		if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &oldDot) {
 			goto l99
 		}
 		if !MarkCreate(
-			CurrentFrame.Marks[MarkEquals].Line,
-			CurrentFrame.Marks[MarkEquals].Col,
+			e.CurrentFrame.Marks[MarkEquals].Line,
+			e.CurrentFrame.Marks[MarkEquals].Col,
 			&oldEquals,
 		) {
 			goto l99
 		}
-		CurrentFrame.TextModified = true
+		e.CurrentFrame.TextModified = true
 		if !MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkModified],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkModified],
 		) {
 			goto l99
 		}
+		replaced = true
 		count--
 	}
 l1:
-	if !MarkCreate(oldDot.Line, oldDot.Col, &CurrentFrame.Dot) {
+	if !MarkCreate(oldDot.Line, oldDot.Col, &e.CurrentFrame.Dot) {
 		goto l99
 	}
 	if !MarkDestroy(&oldDot) {
 		goto l99
 	}
 	if oldEquals != nil {
-		if !MarkCreate(oldEquals.Line, oldEquals.Col, &CurrentFrame.Marks[MarkEquals]) {
+		if !MarkCreate(oldEquals.Line, oldEquals.Col, &e.CurrentFrame.Marks[MarkEquals]) {
 			goto l99
 		}
 		if !MarkDestroy(&oldEquals) {
 			goto l99
 		}
-	} else if CurrentFrame.Marks[MarkEquals] != nil {
-		if !MarkDestroy(&CurrentFrame.Marks[MarkEquals]) {
+	} else if e.CurrentFrame.Marks[MarkEquals] != nil {
+		if !MarkDestroy(&e.CurrentFrame.Marks[MarkEquals]) {
 			goto l99
 		}
 	}
@@ -0,0 +1,62 @@
+// Tests for the EQS, GET and REPLACE commands
+
+package ludwig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternReplaceGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		command string
+		result  []string
+	}{
+		{"swap words", "hello world", "r`(*a)\" \"(*a)`&2 &1`", []string{"world hello"}},
+		{"swap numbers", "x 12-34", "r`(*n)\"-\"(*n)`&2-&1`", []string{"x 34-12"}},
+		{"whole match and ampersand", "key = value", "r`(+a)*s\"=\"*s(+a)`&1: &2 [&0] &&`", []string{"key: value [key = value] &"}},
+		{"middle context", "x12y", "r`a,(+n),a`[&1]`", []string{"x[12]y"}},
+		{"each line", "a1\nb2\nc3", ">r`(a)(n)`&2&1`", []string{"1a", "2b", "3c"}},
+		{"group not matched", "foo", "r`(\"f\")|(\"o\")`<&2&1>`", []string{"<f>oo"}},
+		{"no such group", "abc", "r`(a)`&1&5&x`", []string{"a&xbc"}},
+		{"multiple lines", "one two", "r`(*a)\" \"`&1\ntwo `", []string{"one", "two two"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages bytes.Buffer
+			e := newBatchEditor(t, &messages)
+			require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader(tt.text+"\n")))
+			require.True(t, e.BatchExecute(tt.command), messages.String())
+			assert.Equal(t, tt.result, frameText(e.CurrentFrame))
+		})
+	}
+}
+
+func TestReplaceGroupsBytes(t *testing.T) {
+	// A group holding a byte that is not UTF-8 is copied back as it was
+	for _, command := range []string{"r`(*-s)\" \"(*-s)`&2 &1`", `r~(\S+) (\S+)~&2 &1~`} {
+		var messages bytes.Buffer
+		e := newBatchEditor(t, &messages)
+		require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("caf\xe9 ol\xe9\n")))
+		require.True(t, e.BatchExecute(command), messages.String())
+		line := e.CurrentFrame.FirstGroup.FirstLine
+		assert.Equal(t, []byte("ol\xe9 caf\xe9"), ChAppendUTF8(nil, line.Str, 1, line.Used), command)
+	}
+}
+
+func TestPatternReplaceLiteral(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("a b\n")))
+
+	// Only a pattern's replacement refers to groups
+	require.True(t, e.BatchExecute("r/a/&1&&x/"), messages.String())
+	assert.Equal(t, []string{"&1&&x b"}, frameText(e.CurrentFrame))
+}
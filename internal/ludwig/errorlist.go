@@ -0,0 +1,157 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         ERRORLIST
+//
+// Description:  The error list.  The file:line:col: message lines a
+//               compiler writes are gathered from a frame or from the last
+//               operating system command, and each error can be visited
+//               in turn in a frame for its file.
+
+package ludwig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// errorEntry is an error in the error list, with where the file is and the
+// line and column it reported
+type errorEntry struct {
+	file    string
+	line    int
+	col     int
+	message string
+}
+
+// errorLinePattern matches the file:line: message and file:line:col: message
+// lines that compilers write
+var errorLinePattern = regexp.MustCompile(`^\s*([^:\s][^:]*):(\d+):(?:(\d+):)?\s*(.*)$`)
+
+// ErrorList is the UE command.  It makes the error list from the lines of
+// the frame named, or if no frame is named from the output of the last
+// operating system command, and goes to the first error.
+func (e *Editor) ErrorList(tparam *TParObject) bool {
+	var request TParObject
+	if !e.TparGet1(tparam, CmdErrorList, &request) {
+		return false
+	}
+	text := e.opsysOutput
+	if request.Len != 0 {
+		var span, oldp *SpanObject
+		if !e.SpanFind(strings.ToUpper(request.Str.Slice(1, request.Len)), &span, &oldp) || span.Frame == nil {
+			e.ScreenMessage(MsgNoSuchFrame)
+			return false
+		}
+		text = frameContents(span.Frame)
+	}
+
+	e.errorList = parseErrors(text)
+	e.errorIndex = -1
+	if len(e.errorList) == 0 {
+		e.ScreenMessage(MsgNoErrors)
+		return false
+	}
+	return e.errorGoTo(0)
+}
+
+// ErrorNext is the UN command.  It goes to the next error in the error list,
+// or with a negative leading parameter to the previous one, moving count
+// errors at a time.
+func (e *Editor) ErrorNext(count int) bool {
+	if len(e.errorList) == 0 {
+		e.ScreenMessage(MsgNoErrors)
+		return false
+	}
+	index := e.errorIndex + count
+	if index < 0 || index >= len(e.errorList) {
+		e.ScreenMessage(MsgNoMoreErrors)
+		return false
+	}
+	return e.errorGoTo(index)
+}
+
+// errorGoTo edits the frame for the file of an error in the error list,
+// creating the frame if there is none, and puts Dot where the error is
+func (e *Editor) errorGoTo(index int) bool {
+	entry := e.errorList[index]
+	frame := e.fileFrame(entry.file)
+	if frame != nil {
+		if !e.FrameEdit(frame.Span.Name) {
+			return false
+		}
+	} else {
+		if _, err := os.Stat(entry.file); err != nil {
+			e.ScreenMessage(fmt.Sprintf("File %s not found.", entry.file))
+			return false
+		}
+		if !e.FileEditFrame(e.FileFrameName(entry.file), nil, entry.file) {
+			return false
+		}
+	}
+	e.errorIndex = index
+	if !e.FramePositionDot(e.CurrentFrame, entry.line, entry.col) {
+		return false
+	}
+	e.ScreenMessage(fmt.Sprintf("(%d of %d) %s", index+1, len(e.errorList), entry.message))
+	return true
+}
+
+// fileFrame returns the frame that has a file as its input or output, or
+// nil if there is none
+func (e *Editor) fileFrame(file string) *FrameObject {
+	for span := e.FirstSpan; span != nil; span = span.FLink {
+		frame := span.Frame
+		if frame == nil {
+			continue
+		}
+		for _, slot := range []int{frame.InputFile, frame.OutputFile} {
+			if slot != 0 && e.Files[slot] != nil && e.Files[slot].Filename == file {
+				return frame
+			}
+		}
+	}
+	return nil
+}
+
+// parseErrors makes an error list of the lines of text that say where an
+// error is.  The files are made absolute, so that they are still found if
+// the current directory changes.
+func parseErrors(text string) []errorEntry {
+	var errors []errorEntry
+	for line := range strings.Lines(text) {
+		match := errorLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if match == nil {
+			continue
+		}
+		file, err := filepath.Abs(match[1])
+		if err != nil {
+			continue
+		}
+		lineNr, _ := strconv.Atoi(match[2])
+		col, _ := strconv.Atoi(match[3])
+		errors = append(errors, errorEntry{file: file, line: lineNr, col: col, message: match[4]})
+	}
+	return errors
+}
+
+// frameContents returns the text of a frame, a line at a time
+func frameContents(frame *FrameObject) string {
+	var text strings.Builder
+	for line := frame.FirstGroup.FirstLine; line.FLink != nil; line = line.FLink {
+		text.WriteString(line.Str.Slice(1, line.Used))
+		text.WriteByte('\n')
+	}
+	return text.String()
+}
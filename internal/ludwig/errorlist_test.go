@@ -0,0 +1,90 @@
+// Tests for the error list
+
+package ludwig
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	errors := parseErrors("go build\n./a.go:12:5: undefined: x\nmake: *** [all] Error 1\nb.c:3: warning: unused\r\n")
+	assert.Equal(t, []errorEntry{
+		{file: filepath.Join(dir, "a.go"), line: 12, col: 5, message: "undefined: x"},
+		{file: filepath.Join(dir, "b.c"), line: 3, col: 0, message: "warning: unused"},
+	}, errors)
+}
+
+func TestErrorList(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile("a.txt", []byte("one\ntwo\nthree\n"), 0644))
+	require.NoError(t, os.WriteFile("b.txt", []byte("four\n"), 0644))
+
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.BatchExecute("ed/build/"))
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("a.txt:2:3: bad thing\nb.txt:1: worse thing\n")))
+
+	// Each error opens its file in a frame, with Dot where the error is
+	require.True(t, e.BatchExecute("ue/build/"), messages.String())
+	assert.Equal(t, "A.TXT", e.CurrentFrame.Span.Name)
+	assert.Equal(t, "two", e.CurrentFrame.Dot.Line.Str.Slice(1, e.CurrentFrame.Dot.Line.Used))
+	assert.Equal(t, 3, e.CurrentFrame.Dot.Col)
+	assert.Contains(t, messages.String(), "(1 of 2) bad thing")
+
+	require.True(t, e.BatchExecute("un"), messages.String())
+	assert.Equal(t, "B.TXT", e.CurrentFrame.Span.Name)
+	assert.Equal(t, 1, e.CurrentFrame.Dot.Col)
+	messages.Reset()
+	assert.False(t, e.BatchExecute("un"))
+	assert.Contains(t, messages.String(), MsgNoMoreErrors)
+
+	// Going back uses the frame the file is already in
+	require.True(t, e.BatchExecute("-un"), messages.String())
+	assert.Equal(t, "A.TXT", e.CurrentFrame.Span.Name)
+	var span, oldp *SpanObject
+	assert.False(t, e.SpanFind("A.TXT<2>", &span, &oldp))
+
+	// The errors can come from the last operating system command
+	require.True(t, e.BatchExecute("a uf//cat; echo b.txt:1:2: oops >&2/"), messages.String())
+	require.True(t, e.BatchExecute("ue//"), messages.String())
+	assert.Equal(t, "B.TXT", e.CurrentFrame.Span.Name)
+	assert.Equal(t, 2, e.CurrentFrame.Dot.Col)
+	assert.False(t, e.BatchExecute("-un"))
+
+	messages.Reset()
+	assert.False(t, e.BatchExecute("ue/oops/"))
+	assert.Contains(t, messages.String(), MsgNoErrors)
+	e.LudwigAborted = false
+	e.QuitCloseFiles()
+}
+
+func TestErrorListSpaces(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile("my notes.txt", []byte("one\ntwo\n"), 0644))
+
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.BatchExecute("ed/build/"))
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("my notes.txt:2:2: bad thing\n")))
+
+	// A file whose name has a space in it is opened as one file
+	require.True(t, e.BatchExecute("ue/build/"), messages.String())
+	assert.Equal(t, "MY NOTES.TXT", e.CurrentFrame.Span.Name)
+	assert.Equal(t, filepath.Join(dir, "my notes.txt"), e.Files[e.CurrentFrame.InputFile].Filename)
+	assert.Equal(t, [2]int{2, 2}, markAt(e.CurrentFrame.Dot))
+	e.LudwigAborted = false
+	e.QuitCloseFiles()
+}
@@ -18,6 +18,26 @@ import (
 	"math"
 )
 
+// execOopsLines puts lines extracted from the current frame at the end of
+// frame OOPS, or destroys them if the current frame is OOPS
+func (e *Editor) execOopsLines(firstLine, lastLine *LineHdrObject) bool {
+	if e.CurrentFrame == e.FrameOops {
+		return LinesDestroy(&firstLine, &lastLine)
+	}
+	if !e.LinesInject(firstLine, lastLine, e.FrameOops.LastGroup.LastLine) {
+		return false
+	}
+	if !MarkCreate(firstLine, 1, &e.FrameOops.Marks[MarkEquals]) {
+		return false
+	}
+	if !MarkCreate(e.FrameOops.LastGroup.LastLine, 1, &e.FrameOops.Dot) {
+		return false
+	}
+	e.FrameOops.TextModified = true
+	MarkCreate(e.FrameOops.Dot.Line, e.FrameOops.Dot.Col, &e.FrameOops.Marks[MarkModified])
+	return true
+}
+
 // ExecComputeLineRange returns the range of lines specified by the REPT/COUNT pair.
 // It returns false if the range does not exist.
 // It returns firstLine as nil if the range is empty.
@@ -138,8 +158,42 @@ l99:
 	return result
 }
 
+// execPageIn pages in as much of the input files as a command needs before
+// it runs.  Most commands stay near Dot, and those that search forwards
+// page in more as they go, so only the commands that work on whole frames
+// read the rest of them.
+func (e *Editor) execPageIn(command Commands, rept LeadParam, count int) bool {
+	switch command {
+	case CmdFrameList, CmdErrorList:
+		return e.FileReadRest()
+	}
+	frame := e.CurrentFrame
+	if !e.FileUnread(frame) {
+		return true
+	}
+	if rept == LeadParamPIndef {
+		// > goes to the end of the frame
+		return e.FileReadAhead(frame, -1)
+	}
+	switch command {
+	case CmdFileWrite, CmdFileSave, CmdOccur:
+		return e.FileReadAhead(frame, -1)
+	case CmdPositionLine:
+		return e.FileReadLines(frame.FirstGroup.FirstLine, count-1)
+	case CmdAdvance, CmdDeleteLine, CmdDown, CmdReturn, CmdSwapLine, CmdOpSysFilter,
+		CmdLineCentre, CmdLineFill, CmdLineJustify, CmdLineSquash, CmdLineLeft, CmdLineRight:
+		// These go count lines down, and a line is filled from the one after
+		if rept == LeadParamNone || rept == LeadParamPlus || rept == LeadParamPInt {
+			return e.FileReadLines(frame.Dot.Line, count+1)
+		}
+	case CmdDittoDown:
+		return e.FileReadLines(frame.Dot.Line, 1)
+	}
+	return true
+}
+
 // Execute executes a command with the specified parameters
-func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fromSpan bool) bool {
+func (e *Editor) Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fromSpan bool) bool {
 	var cmdSuccess bool
 	var newCol int
 	var dotCol int
@@ -165,12 +219,16 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 	request.Con = nil
 	request2.Nxt = nil
 	request2.Con = nil
-	ExecLevel++
-	if TtControlC {
+	e.ExecLevel++
+	if e.TtControlC.Load() {
+		goto l99
+	}
+	if e.ExecLevel == MaxExecRecursion {
+		e.ScreenMessage(MsgCommandRecursionLimit)
 		goto l99
 	}
-	if ExecLevel == MaxExecRecursion {
-		ScreenMessage(MsgCommandRecursionLimit)
+
+	if !interpCmds[command] && !e.execPageIn(command, rept, count) {
 		goto l99
 	}
 
@@ -178,13 +236,13 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 	switch command {
 	case CmdMark:
 		if count == 0 || int(math.Abs(float64(count))) > MaxUserMarkNumber {
-			ScreenMessage(MsgIllegalMarkNumber)
+			e.ScreenMessage(MsgIllegalMarkNumber)
 			goto l99
 		}
-	case CmdSpanDefine:
+	case CmdSpanDefine, CmdBlockDefine:
 		if rept == LeadParamNone || rept == LeadParamPInt {
 			if count == 0 || count > MaxUserMarkNumber {
-				ScreenMessage(MsgIllegalMarkNumber)
+				e.ScreenMessage(MsgIllegalMarkNumber)
 				goto l99
 			}
 			rept = LeadParamMarker
@@ -193,23 +251,23 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 
 	// Check the mark, assign theMark to the mark
 	if rept == LeadParamMarker {
-		theMark = CurrentFrame.Marks[count]
+		theMark = e.CurrentFrame.Marks[count]
 		if theMark == nil {
-			ScreenMessage(MsgMarkNotDefined)
+			e.ScreenMessage(MsgMarkNotDefined)
 			goto l99
 		}
 	}
 
 	// Save the current value of DOT and CURRENT_FRAME for use by equals
-	oldDot = *CurrentFrame.Dot
-	oldFrame = CurrentFrame
+	oldDot = *e.CurrentFrame.Dot
+	oldFrame = e.CurrentFrame
 
 	// Execute the command
 	switch command {
 	case CmdAdvance:
 		// Establish which line to advance to
 		cmdSuccess = (rept == LeadParamPIndef || rept == LeadParamNIndef || rept == LeadParamMarker)
-		newLine = CurrentFrame.Dot.Line
+		newLine = e.CurrentFrame.Dot.Line
 		switch rept {
 		case LeadParamNone, LeadParamPlus, LeadParamPInt:
 			if count < 20 {
@@ -224,7 +282,7 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 				if !LineToNumber(newLine, &lineNr) {
 					goto l99
 				}
-				if !LineFromNumber(CurrentFrame, lineNr+count, &newLine) {
+				if !LineFromNumber(e.CurrentFrame, lineNr+count, &newLine) {
 					goto l99
 				}
 				if newLine == nil {
@@ -254,7 +312,7 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 				if count >= lineNr {
 					goto l99
 				}
-				if !LineFromNumber(CurrentFrame, lineNr-count, &newLine) {
+				if !LineFromNumber(e.CurrentFrame, lineNr-count, &newLine) {
 					goto l99
 				}
 				if newLine == nil {
@@ -264,116 +322,104 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 			cmdSuccess = true
 
 		case LeadParamPIndef:
-			newLine = CurrentFrame.LastGroup.LastLine
+			newLine = e.CurrentFrame.LastGroup.LastLine
 
 		case LeadParamNIndef:
-			newLine = CurrentFrame.FirstGroup.FirstLine
+			newLine = e.CurrentFrame.FirstGroup.FirstLine
 
 		case LeadParamMarker:
 			newLine = theMark.Line
 		}
 
-		if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &CurrentFrame.Marks[MarkEquals]) {
+		if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Marks[MarkEquals]) {
 			goto l99
 		}
-		MarkCreate(newLine, 1, &CurrentFrame.Dot)
+		MarkCreate(newLine, 1, &e.CurrentFrame.Dot)
 
 	case CmdBridge, CmdNext:
-		if TparGet1(tparam, command, &request) {
-			cmdSuccess = NextbridgeCommand(count, &request, command == CmdBridge)
+		if e.TparGet1(tparam, command, &request) {
+			cmdSuccess = e.NextbridgeCommand(count, &request, command == CmdBridge)
 		}
 
 	case CmdCaseEdit, CmdCaseLow, CmdCaseUp, CmdDittoDown, CmdDittoUp:
-		cmdSuccess = CaseDittoCommand(command, rept, count, fromSpan)
+		cmdSuccess = e.CaseDittoCommand(command, rept, count, fromSpan)
 
 	case CmdDeleteChar:
 		if rept != LeadParamMarker {
-			cmdSuccess = CharcmdDelete(command, rept, count, fromSpan)
+			cmdSuccess = e.CharcmdDelete(command, rept, count, fromSpan)
 		} else {
-			theOtherMark = CurrentFrame.Dot
-			if !LineToNumber(CurrentFrame.Dot.Line, &lineNr) {
+			theOtherMark = e.CurrentFrame.Dot
+			if !LineToNumber(e.CurrentFrame.Dot.Line, &lineNr) {
 				goto l99
 			}
 			if !LineToNumber(theMark.Line, &line2Nr) {
 				goto l99
 			}
-			if lineNr > line2Nr || (lineNr == line2Nr && CurrentFrame.Dot.Col > theMark.Col) {
+			if lineNr > line2Nr || (lineNr == line2Nr && e.CurrentFrame.Dot.Col > theMark.Col) {
 				// Reverse mark pointers to get theOtherMark first
 				anotherMark = theMark
 				theMark = theOtherMark
 				theOtherMark = anotherMark
 			}
-			if CurrentFrame != FrameOops {
+			if e.CurrentFrame != e.FrameOops {
 				// Make sure oops_span is okay
-				if !MarkCreate(FrameOops.LastGroup.LastLine, 1, &FrameOops.Span.MarkTwo) {
+				if !MarkCreate(e.FrameOops.LastGroup.LastLine, 1, &e.FrameOops.Span.MarkTwo) {
 					goto l99
 				}
-				cmdSuccess = TextMove(
-					false,                        // Don't copy, transfer
-					1,                            // One instance of
-					theOtherMark,                 // starting pos
-					theMark,                      // ending pos
-					FrameOops.Span.MarkTwo,       // destination
-					&FrameOops.Marks[MarkEquals], // leave at start
-					&FrameOops.Dot,               // leave at end
+				cmdSuccess = e.TextMove(
+					false,                          // Don't copy, transfer
+					1,                              // One instance of
+					theOtherMark,                   // starting pos
+					theMark,                        // ending pos
+					e.FrameOops.Span.MarkTwo,       // destination
+					&e.FrameOops.Marks[MarkEquals], // leave at start
+					&e.FrameOops.Dot,               // leave at end
 				)
-				FrameOops.TextModified = true
-				MarkCreate(FrameOops.Dot.Line, FrameOops.Dot.Col, &FrameOops.Marks[MarkModified])
+				e.FrameOops.TextModified = true
+				MarkCreate(e.FrameOops.Dot.Line, e.FrameOops.Dot.Col, &e.FrameOops.Marks[MarkModified])
 			} else {
-				cmdSuccess = TextRemove(theOtherMark, theMark)
+				cmdSuccess = e.TextRemove(theOtherMark, theMark)
 			}
-			CurrentFrame.TextModified = true
-			MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &CurrentFrame.Marks[MarkModified])
+			e.CurrentFrame.TextModified = true
+			MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Marks[MarkModified])
 		}
 
 	case CmdDeleteLine:
 		// Establish which lines to kill, this is common to K and FW cmds
-		if !ExecComputeLineRange(CurrentFrame, rept, count, &firstLine, &lastLine) {
+		if !ExecComputeLineRange(e.CurrentFrame, rept, count, &firstLine, &lastLine) {
 			goto l99
 		}
 		if firstLine != nil {
-			dotCol = CurrentFrame.Dot.Col
+			dotCol = e.CurrentFrame.Dot.Col
 			if lastLine.FLink == nil {
 				goto l99
 			}
 			if !MarksSqueeze(firstLine, 1, lastLine.FLink, 1) {
 				goto l99
 			}
-			if !LinesExtract(firstLine, lastLine) {
+			if !e.LinesExtract(firstLine, lastLine) {
 				goto l99
 			}
-			if CurrentFrame != FrameOops {
-				if !LinesInject(firstLine, lastLine, FrameOops.LastGroup.LastLine) {
-					goto l99
-				}
-				if !MarkCreate(firstLine, 1, &FrameOops.Marks[MarkEquals]) {
-					goto l99
-				}
-				if !MarkCreate(FrameOops.LastGroup.LastLine, 1, &FrameOops.Dot) {
-					goto l99
-				}
-				FrameOops.TextModified = true
-				MarkCreate(FrameOops.Dot.Line, FrameOops.Dot.Col, &FrameOops.Marks[MarkModified])
-			} else if !LinesDestroy(&firstLine, &lastLine) {
+			if !e.execOopsLines(firstLine, lastLine) {
 				goto l99
 			}
-			CurrentFrame.Dot.Col = dotCol
-			CurrentFrame.TextModified = true
-			MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &CurrentFrame.Marks[MarkModified])
+			e.CurrentFrame.Dot.Col = dotCol
+			e.CurrentFrame.TextModified = true
+			MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Marks[MarkModified])
 		}
 		cmdSuccess = true
 
 	case CmdBacktab, CmdDown, CmdHome, CmdLeft, CmdReturn, CmdRight, CmdTab, CmdUp:
-		if command == CmdReturn && EditMode == ModeInsert &&
-			CurrentFrame.Options.Has(OptNewLine) {
-			if CurrentFrame.Dot.Line.FLink == nil {
-				cmdSuccess = TextRealizeNull(CurrentFrame.Dot.Line)
-				cmdSuccess = ArrowCommand(command, rept, count, fromSpan)
+		if command == CmdReturn && e.EditMode == ModeInsert &&
+			e.CurrentFrame.Options.Has(OptNewLine) {
+			if e.CurrentFrame.Dot.Line.FLink == nil {
+				cmdSuccess = e.TextRealizeNull(e.CurrentFrame.Dot.Line)
+				cmdSuccess = e.ArrowCommand(command, rept, count, fromSpan)
 			} else {
-				cmdSuccess = Execute(CmdSplitLine, rept, count, tparam, fromSpan)
+				cmdSuccess = e.Execute(CmdSplitLine, rept, count, tparam, fromSpan)
 			}
 		} else {
-			cmdSuccess = ArrowCommand(command, rept, count, fromSpan)
+			cmdSuccess = e.ArrowCommand(command, rept, count, fromSpan)
 		}
 
 	case CmdDump:
@@ -381,17 +427,17 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 
 	case CmdEqualColumn:
 		i = 1 // Start of column number, j receives column number
-		if TparGet1(tparam, command, &request) {
-			if TparToInt(&request, &i, &j) {
+		if e.TparGet1(tparam, command, &request) {
+			if e.TparToInt(&request, &i, &j) {
 				switch rept {
 				case LeadParamNone, LeadParamPlus:
-					cmdSuccess = (CurrentFrame.Dot.Col == j)
+					cmdSuccess = (e.CurrentFrame.Dot.Col == j)
 				case LeadParamMinus:
-					cmdSuccess = (CurrentFrame.Dot.Col != j)
+					cmdSuccess = (e.CurrentFrame.Dot.Col != j)
 				case LeadParamPIndef:
-					cmdSuccess = (CurrentFrame.Dot.Col >= j)
+					cmdSuccess = (e.CurrentFrame.Dot.Col >= j)
 				case LeadParamNIndef:
-					cmdSuccess = (CurrentFrame.Dot.Col <= j)
+					cmdSuccess = (e.CurrentFrame.Dot.Col <= j)
 				}
 			}
 		}
@@ -399,20 +445,20 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 	case CmdEqualEol:
 		switch rept {
 		case LeadParamNone, LeadParamPlus:
-			cmdSuccess = (CurrentFrame.Dot.Col == CurrentFrame.Dot.Line.Used+1)
+			cmdSuccess = (e.CurrentFrame.Dot.Col == e.CurrentFrame.Dot.Line.Used+1)
 		case LeadParamMinus:
-			cmdSuccess = (CurrentFrame.Dot.Col != CurrentFrame.Dot.Line.Used+1)
+			cmdSuccess = (e.CurrentFrame.Dot.Col != e.CurrentFrame.Dot.Line.Used+1)
 		case LeadParamPIndef:
-			cmdSuccess = (CurrentFrame.Dot.Col >= CurrentFrame.Dot.Line.Used+1)
+			cmdSuccess = (e.CurrentFrame.Dot.Col >= e.CurrentFrame.Dot.Line.Used+1)
 		case LeadParamNIndef:
-			cmdSuccess = (CurrentFrame.Dot.Col <= CurrentFrame.Dot.Line.Used+1)
+			cmdSuccess = (e.CurrentFrame.Dot.Col <= e.CurrentFrame.Dot.Line.Used+1)
 		}
 
 	case CmdEqualEop, CmdEqualEof:
-		cmdSuccess = (CurrentFrame.Dot.Line.FLink == nil)
+		cmdSuccess = (e.CurrentFrame.Dot.Line.FLink == nil)
 		if command == CmdEqualEof {
-			if CurrentFrame.InputFile != 0 {
-				if !Files[CurrentFrame.InputFile].Eof {
+			if e.CurrentFrame.InputFile != 0 {
+				if !e.Files[e.CurrentFrame.InputFile].Eof {
 					cmdSuccess = false
 				}
 			}
@@ -422,31 +468,31 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 		}
 
 	case CmdEqualMark:
-		if !TparGet1(tparam, command, &request) {
+		if !e.TparGet1(tparam, command, &request) {
 			goto l99
 		}
-		if !TparToMark(&request, &j) {
+		if !e.TparToMark(&request, &j) {
 			goto l99
 		}
-		if CurrentFrame.Marks[j] != nil {
+		if e.CurrentFrame.Marks[j] != nil {
 			switch rept {
 			case LeadParamNone, LeadParamPlus, LeadParamMinus:
-				if CurrentFrame.Marks[j].Line == CurrentFrame.Dot.Line &&
-					CurrentFrame.Marks[j].Col == CurrentFrame.Dot.Col {
+				if e.CurrentFrame.Marks[j].Line == e.CurrentFrame.Dot.Line &&
+					e.CurrentFrame.Marks[j].Col == e.CurrentFrame.Dot.Col {
 					cmdSuccess = true
 				}
 				if rept == LeadParamMinus {
 					cmdSuccess = !cmdSuccess
 				}
 			case LeadParamPIndef, LeadParamNIndef:
-				if CurrentFrame.Marks[j].Line == CurrentFrame.Dot.Line {
+				if e.CurrentFrame.Marks[j].Line == e.CurrentFrame.Dot.Line {
 					if rept == LeadParamPIndef {
-						cmdSuccess = (CurrentFrame.Dot.Col >= CurrentFrame.Marks[j].Col)
+						cmdSuccess = (e.CurrentFrame.Dot.Col >= e.CurrentFrame.Marks[j].Col)
 					} else {
-						cmdSuccess = (CurrentFrame.Dot.Col <= CurrentFrame.Marks[j].Col)
+						cmdSuccess = (e.CurrentFrame.Dot.Col <= e.CurrentFrame.Marks[j].Col)
 					}
-				} else if LineToNumber(CurrentFrame.Dot.Line, &lineNr) &&
-					LineToNumber(CurrentFrame.Marks[j].Line, &line2Nr) {
+				} else if LineToNumber(e.CurrentFrame.Dot.Line, &lineNr) &&
+					LineToNumber(e.CurrentFrame.Marks[j].Line, &line2Nr) {
 					if rept == LeadParamPIndef {
 						cmdSuccess = (lineNr >= line2Nr)
 					} else {
@@ -457,41 +503,41 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 		}
 
 	case CmdEqualString:
-		if TparGet1(tparam, command, &request) {
+		if e.TparGet1(tparam, command, &request) {
 			if request.Len == 0 {
 				// If didn't specify, use default
-				request = CurrentFrame.EqsTpar
+				request = e.CurrentFrame.EqsTpar
 				if request.Len == 0 {
-					ScreenMessage(MsgNoDefaultStr)
+					e.ScreenMessage(MsgNoDefaultStr)
 					goto l99
 				}
 			} else {
-				CurrentFrame.EqsTpar = request // If did specify, save for next time
+				e.CurrentFrame.EqsTpar = request // If did specify, save for next time
 			}
 		}
-		cmdSuccess = EqsGetRepEqs(rept, request)
+		cmdSuccess = e.EqsGetRepEqs(rept, request)
 
 	case CmdDoLastCommand, CmdExecuteString:
-		if CurrentFrame == FrameCmd {
-			ScreenMessage(MsgNotWhileEditingCmd)
+		if e.CurrentFrame == e.FrameCmd {
+			e.ScreenMessage(MsgNotWhileEditingCmd)
 			goto l99
 		}
 		if command == CmdExecuteString {
-			if !TparGet1(tparam, command, &request) {
+			if !e.TparGet1(tparam, command, &request) {
 				goto l99
 			}
 
-			FrameCmd.ReturnFrame = CurrentFrame
-			CurrentFrame = FrameCmd
+			e.FrameCmd.ReturnFrame = e.CurrentFrame
+			e.CurrentFrame = e.FrameCmd
 
 			// Zap frame COMMAND's current contents
-			firstLine = FrameCmd.FirstGroup.FirstLine
-			lastLine = FrameCmd.LastGroup.LastLine.BLink
+			firstLine = e.FrameCmd.FirstGroup.FirstLine
+			lastLine = e.FrameCmd.LastGroup.LastLine.BLink
 			if lastLine != nil {
 				if !MarksSqueeze(firstLine, 1, lastLine.FLink, 1) {
 					goto l99
 				}
-				if !LinesExtract(firstLine, lastLine) {
+				if !e.LinesExtract(firstLine, lastLine) {
 					goto l99
 				}
 				if !LinesDestroy(&firstLine, &lastLine) {
@@ -500,183 +546,193 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 			}
 
 			// Insert the new tpar into frame COMMAND
-			if !TextInsertTpar(&request, FrameCmd.Dot, &FrameCmd.Marks[MarkEquals]) {
+			if !e.TextInsertTpar(&request, e.FrameCmd.Dot, &e.FrameCmd.Marks[MarkEquals]) {
 				goto l99
 			}
 
-			CurrentFrame = CurrentFrame.ReturnFrame
+			e.CurrentFrame = e.CurrentFrame.ReturnFrame
 		}
 
 		// Recompile and execute frame COMMAND
-		if SpanFind(FrameCmd.Span.Name, &newSpan, &oldSpan) {
-			if !CodeCompile(FrameCmd.Span, true) {
+		if e.SpanFind(e.FrameCmd.Span.Name, &newSpan, &oldSpan) {
+			if !e.CodeCompile(e.FrameCmd.Span, true) {
 				goto l99
 			}
-			cmdSuccess = CodeInterpret(rept, count, FrameCmd.Span.Code, true)
+			cmdSuccess = e.CodeInterpret(rept, count, e.FrameCmd.Span.Code, true)
 		}
 
 	case CmdFileInput, CmdFileOutput, CmdFileEdit, CmdFileRead, CmdFileWrite,
 		CmdFileRewind, CmdFileKill, CmdFileSave,
 		CmdFileGlobalInput, CmdFileGlobalOutput, CmdFileGlobalRewind, CmdFileGlobalKill:
-		cmdSuccess = FileCommand(command, rept, count, tparam, fromSpan)
+		cmdSuccess = e.FileCommand(command, rept, count, tparam, fromSpan)
 
 	case CmdFileExecute:
-		if CurrentFrame == FrameCmd {
-			ScreenMessage(MsgNotWhileEditingCmd)
+		if e.CurrentFrame == e.FrameCmd {
+			e.ScreenMessage(MsgNotWhileEditingCmd)
 			goto l99
 		}
-		if TparGet1(tparam, command, &request) {
+		if e.TparGet1(tparam, command, &request) {
 			newTparam := request
-			FrameCmd.ReturnFrame = CurrentFrame
-			CurrentFrame = FrameCmd
+			e.FrameCmd.ReturnFrame = e.CurrentFrame
+			e.CurrentFrame = e.FrameCmd
 			// Zap frame COMMAND's current contents
-			firstLine = FrameCmd.FirstGroup.FirstLine
-			lastLine = FrameCmd.LastGroup.LastLine.BLink
+			firstLine = e.FrameCmd.FirstGroup.FirstLine
+			lastLine = e.FrameCmd.LastGroup.LastLine.BLink
 			if lastLine != nil {
 				if !MarksSqueeze(firstLine, 1, lastLine.FLink, 1) {
 					goto l99
 				}
-				if !LinesExtract(firstLine, lastLine) {
+				if !e.LinesExtract(firstLine, lastLine) {
 					goto l99
 				}
 				if !LinesDestroy(&firstLine, &lastLine) {
 					goto l99
 				}
 			}
-			if FileCommand(CmdFileExecute, LeadParamNone, 0, &newTparam, false) {
-				CurrentFrame = CurrentFrame.ReturnFrame
+			if e.FileCommand(CmdFileExecute, LeadParamNone, 0, &newTparam, false) {
+				e.CurrentFrame = e.CurrentFrame.ReturnFrame
 				// Recompile and execute frame COMMAND
-				if SpanFind(FrameCmd.Span.Name, &newSpan, &oldSpan) {
-					if CodeCompile(FrameCmd.Span, true) {
-						cmdSuccess = CodeInterpret(rept, count, FrameCmd.Span.Code, true)
+				if e.SpanFind(e.FrameCmd.Span.Name, &newSpan, &oldSpan) {
+					if e.CodeCompile(e.FrameCmd.Span, true) {
+						cmdSuccess = e.CodeInterpret(rept, count, e.FrameCmd.Span.Code, true)
 					}
 				}
 			} else {
-				CurrentFrame = CurrentFrame.ReturnFrame
+				e.CurrentFrame = e.CurrentFrame.ReturnFrame
 			}
 		}
 
 	case CmdFileTable:
-		FileTable()
+		e.FileTable()
 		cmdSuccess = true
 
 	case CmdFrameEdit:
-		if TparGet1(tparam, command, &request) {
+		if e.TparGet1(tparam, command, &request) {
 			newName = request.Str.Slice(1, request.Len)
-			cmdSuccess = FrameEdit(newName)
+			cmdSuccess = e.FrameEdit(newName)
 		}
 
 	case CmdFrameKill:
-		if TparGet1(tparam, command, &request) {
+		if e.TparGet1(tparam, command, &request) {
 			newName = request.Str.Slice(1, request.Len)
-			cmdSuccess = FrameKill(newName)
+			cmdSuccess = e.FrameKill(newName)
 		}
 
 	case CmdFrameParameters:
-		cmdSuccess = FrameParameter(tparam)
+		cmdSuccess = e.FrameParameter(tparam)
+
+	case CmdFrameList:
+		cmdSuccess = e.FrameList(rept, fromSpan)
 
 	case CmdFrameReturn:
 		for i = 1; i <= count; i++ {
-			if CurrentFrame.ReturnFrame == nil {
-				CurrentFrame = oldFrame
+			if e.CurrentFrame.ReturnFrame == nil {
+				e.CurrentFrame = oldFrame
 				goto l99
 			}
-			CurrentFrame = CurrentFrame.ReturnFrame
+			e.CurrentFrame = e.CurrentFrame.ReturnFrame
 		}
 		cmdSuccess = true
 
 	case CmdGet:
-		if TparGet1(tparam, command, &request) {
+		if e.TparGet1(tparam, command, &request) {
 			if request.Len == 0 {
 				// If didn't specify, use default
-				request = CurrentFrame.GetTpar
+				request = e.CurrentFrame.GetTpar
 				if request.Len == 0 {
-					ScreenMessage(MsgNoDefaultStr)
+					e.ScreenMessage(MsgNoDefaultStr)
 					goto l99
 				}
 			} else {
-				CurrentFrame.GetTpar = request // If did specify, save for next time
+				e.CurrentFrame.GetTpar = request // If did specify, save for next time
 			}
-			cmdSuccess = EqsGetRepGet(count, request, fromSpan)
+			cmdSuccess = e.EqsGetRepGet(count, request, fromSpan)
 		}
 
+	case CmdGetIncremental:
+		cmdSuccess = e.GetIncremental(rept)
+
+	case CmdOccur:
+		cmdSuccess = e.Occur(tparam)
+
 	case CmdHelp:
-		if LudwigMode == LudwigBatch {
-			ScreenMessage(MsgInteractiveModeOnly)
+		if e.LudwigMode == LudwigBatch {
+			e.ScreenMessage(MsgInteractiveModeOnly)
 			goto l99
 		}
-		if TparGet1(tparam, command, &request) {
-			HelpHelp(string(request.Str.Slice(1, request.Len)))
+		if e.TparGet1(tparam, command, &request) {
+			e.HelpHelp(string(request.Str.Slice(1, request.Len)))
 			cmdSuccess = true // Never fails
 		}
 
 	case CmdInsertChar:
-		cmdSuccess = CharcmdInsert(command, rept, count, fromSpan)
+		cmdSuccess = e.CharcmdInsert(command, rept, count, fromSpan)
 
 	case CmdInsertLine:
 		if count != 0 {
 			cmdSuccess = LinesCreate(int(math.Abs(float64(count))), &firstLine, &lastLine)
 			if cmdSuccess {
-				cmdSuccess = LinesInject(firstLine, lastLine, CurrentFrame.Dot.Line)
+				cmdSuccess = e.LinesInject(firstLine, lastLine, e.CurrentFrame.Dot.Line)
 			}
 			if cmdSuccess {
 				if count > 0 {
-					cmdSuccess = MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-						&CurrentFrame.Marks[MarkEquals])
-					cmdSuccess = MarkCreate(firstLine, CurrentFrame.Dot.Col, &CurrentFrame.Dot)
+					cmdSuccess = MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+						&e.CurrentFrame.Marks[MarkEquals])
+					cmdSuccess = MarkCreate(firstLine, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Dot)
 				} else {
-					cmdSuccess = MarkCreate(firstLine, CurrentFrame.Dot.Col,
-						&CurrentFrame.Marks[MarkEquals])
+					cmdSuccess = MarkCreate(firstLine, e.CurrentFrame.Dot.Col,
+						&e.CurrentFrame.Marks[MarkEquals])
 				}
-				CurrentFrame.TextModified = true
-				MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-					&CurrentFrame.Marks[MarkModified])
+				e.CurrentFrame.TextModified = true
+				MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+					&e.CurrentFrame.Marks[MarkModified])
 			}
 		} else {
-			cmdSuccess = MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-				&CurrentFrame.Marks[MarkEquals])
+			cmdSuccess = MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+				&e.CurrentFrame.Marks[MarkEquals])
 		}
 
 	case CmdInsertMode:
-		EditMode = ModeInsert
+		e.EditMode = ModeInsert
 		cmdSuccess = true
 
 	case CmdInsertText:
-		if FileData.OldCmds && !fromSpan {
+		if e.FileData.OldCmds && !fromSpan {
 			if rept == LeadParamNone {
-				EditMode = ModeInsert
+				e.EditMode = ModeInsert
+				e.LearnDiscard()
 				cmdSuccess = true
 			} else {
-				ScreenMessage(MsgSyntaxError)
+				e.ScreenMessage(MsgSyntaxError)
 			}
-		} else if TparGet1(tparam, command, &request) {
+		} else if e.TparGet1(tparam, command, &request) {
 			if request.Con == nil {
-				cmdSuccess = TextInsert(true, count, request.Str, request.Len, CurrentFrame.Dot)
+				cmdSuccess = e.TextInsert(true, count, request.Str, request.Len, e.CurrentFrame.Dot)
 				if cmdSuccess && (count*request.Len != 0) {
-					CurrentFrame.TextModified = true
-					cmdSuccess = MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-						&CurrentFrame.Marks[MarkModified])
+					e.CurrentFrame.TextModified = true
+					cmdSuccess = MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+						&e.CurrentFrame.Marks[MarkModified])
 				}
 			} else {
 				for i = 1; i <= count; i++ {
-					if !TextInsertTpar(&request, CurrentFrame.Dot, &CurrentFrame.Marks[MarkEquals]) {
+					if !e.TextInsertTpar(&request, e.CurrentFrame.Dot, &e.CurrentFrame.Marks[MarkEquals]) {
 						goto l99
 					}
 				}
-				CurrentFrame.TextModified = true
-				cmdSuccess = MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-					&CurrentFrame.Marks[MarkModified])
+				e.CurrentFrame.TextModified = true
+				cmdSuccess = MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+					&e.CurrentFrame.Marks[MarkModified])
 			}
 		}
 
 	case CmdInsertInvisible:
-		if LudwigMode != LudwigScreen {
+		if e.LudwigMode != LudwigScreen {
 			goto l99
 		}
-		if CurrentFrame.Dot.Col > CurrentFrame.Dot.Line.Used {
-			i = MaxStrLenP - CurrentFrame.Dot.Col
+		if e.CurrentFrame.Dot.Col > e.CurrentFrame.Dot.Line.Used {
+			i = MaxStrLenP - e.CurrentFrame.Dot.Col
 		} else {
-			i = MaxStrLen - CurrentFrame.Dot.Line.Used
+			i = MaxStrLen - e.CurrentFrame.Dot.Line.Used
 		}
 		if rept == LeadParamPIndef {
 			count = i
@@ -684,16 +740,17 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 		if count > i {
 			goto l99
 		}
-		newStr = BlankString.Clone()
+		newStr = EmptyStrObject()
 		i = 0
 		for i < count {
-			key = VduGetKey()
-			if TtControlC {
+			key = e.VduGetKey()
+			if e.TtControlC.Load() {
 				goto l99
 			}
-			if ChIsPrintable(rune(key)) {
+			if ChIsPrintableKey(key) {
+				ch, _ := KeyToCh(key)
 				i++
-				newStr.Set(i, byte(key))
+				newStr.Set(i, ch)
 			} else if key == 13 {
 				if rept == LeadParamPIndef {
 					count = i
@@ -701,14 +758,15 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 					i = count
 				}
 			} else {
-				VduBeep()
+				e.VduBeep()
 			}
 		}
-		cmdSuccess = TextInsert(true, 1, newStr, count, CurrentFrame.Dot)
+		newStr.Fill(' ', newStr.Len()+1, count)
+		cmdSuccess = e.TextInsert(true, 1, newStr, count, e.CurrentFrame.Dot)
 		if cmdSuccess && count != 0 {
-			CurrentFrame.TextModified = true
-			if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-				&CurrentFrame.Marks[MarkModified]) {
+			e.CurrentFrame.TextModified = true
+			if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+				&e.CurrentFrame.Marks[MarkModified]) {
 				cmdSuccess = false
 			}
 		}
@@ -716,77 +774,77 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 	case CmdJump:
 		switch rept {
 		case LeadParamNone, LeadParamPlus, LeadParamPInt:
-			if CurrentFrame.Dot.Col+count > MaxStrLenP {
+			if e.CurrentFrame.Dot.Col+count > MaxStrLenP {
 				goto l99
 			}
 		case LeadParamMinus, LeadParamNInt:
-			if CurrentFrame.Dot.Col <= -count {
+			if e.CurrentFrame.Dot.Col <= -count {
 				goto l99
 			}
 		case LeadParamPIndef:
-			if CurrentFrame.Dot.Col > CurrentFrame.Dot.Line.Used+1 {
+			if e.CurrentFrame.Dot.Col > e.CurrentFrame.Dot.Line.Used+1 {
 				goto l99
 			}
-			count = 1 + CurrentFrame.Dot.Line.Used - CurrentFrame.Dot.Col
+			count = 1 + e.CurrentFrame.Dot.Line.Used - e.CurrentFrame.Dot.Col
 		case LeadParamNIndef:
-			count = 1 - CurrentFrame.Dot.Col
+			count = 1 - e.CurrentFrame.Dot.Col
 		case LeadParamMarker:
-			if !MarkCreate(theMark.Line, theMark.Col, &CurrentFrame.Dot) {
+			if !MarkCreate(theMark.Line, theMark.Col, &e.CurrentFrame.Dot) {
 				goto l99
 			}
 			count = 0
 		}
-		CurrentFrame.Dot.Col += count
+		e.CurrentFrame.Dot.Col += count
 		cmdSuccess = true
 
 	case CmdLineCentre:
-		cmdSuccess = WordCentre(rept, count)
+		cmdSuccess = e.WordCentre(rept, count)
 
 	case CmdLineFill:
-		cmdSuccess = WordFill(rept, count)
+		cmdSuccess = e.WordFill(rept, count)
 
 	case CmdLineJustify:
-		cmdSuccess = WordJustify(rept, count)
+		cmdSuccess = e.WordJustify(rept, count)
 
 	case CmdLineSquash:
-		cmdSuccess = WordSqueeze(rept, count)
+		cmdSuccess = e.WordSqueeze(rept, count)
 
 	case CmdLineLeft:
-		cmdSuccess = WordLeft(rept, count)
+		cmdSuccess = e.WordLeft(rept, count)
 
 	case CmdLineRight:
-		cmdSuccess = WordRight(rept, count)
+		cmdSuccess = e.WordRight(rept, count)
 
 	case CmdWordAdvance:
-		if FileData.OldCmds {
-			cmdSuccess = WordAdvanceWord(rept, count)
+		if e.FileData.OldCmds {
+			cmdSuccess = e.WordAdvanceWord(rept, count)
 		} else {
-			cmdSuccess = NewwordAdvanceWord(rept, count)
+			cmdSuccess = e.NewwordAdvanceWord(rept, count)
 		}
 
 	case CmdWordDelete:
-		if FileData.OldCmds {
-			cmdSuccess = WordDeleteWord(rept, count)
+		if e.FileData.OldCmds {
+			cmdSuccess = e.WordDeleteWord(rept, count)
 		} else {
-			cmdSuccess = NewwordDeleteWord(rept, count)
+			cmdSuccess = e.NewwordDeleteWord(rept, count)
 		}
 
 	case CmdAdvanceParagraph:
-		cmdSuccess = NewwordAdvanceParagraph(rept, count)
+		cmdSuccess = e.NewwordAdvanceParagraph(rept, count)
 
 	case CmdDeleteParagraph:
-		cmdSuccess = NewwordDeleteParagraph(rept, count)
+		cmdSuccess = e.NewwordDeleteParagraph(rept, count)
 
 	case CmdMark:
 		if count < 0 {
-			if CurrentFrame.Marks[-count] != nil {
-				cmdSuccess = MarkDestroy(&CurrentFrame.Marks[-count])
+			if e.CurrentFrame.Marks[-count] != nil {
+				cmdSuccess = MarkDestroy(&e.CurrentFrame.Marks[-count])
 			} else {
 				cmdSuccess = true
 			}
 		} else {
-			cmdSuccess = MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-				&CurrentFrame.Marks[count])
+			cmdSuccess = MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+				&e.CurrentFrame.Marks[count])
 		}
 
 	case CmdNoop:
@@ -794,43 +852,44 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 
 	case CmdCommand:
 		if rept == LeadParamMinus {
-			if EditMode != ModeCommand {
-				PreviousMode = EditMode
-				EditMode = ModeCommand
+			if e.EditMode != ModeCommand {
+				e.PreviousMode = e.EditMode
+				e.EditMode = ModeCommand
 			} else {
 				goto l99
 			}
 		} else {
-			if EditMode == ModeCommand {
-				EditMode = PreviousMode
+			if e.EditMode == ModeCommand {
+				e.EditMode = e.PreviousMode
 			} else {
-				if LudwigMode != LudwigScreen {
-					ScreenMessage(MsgScreenModeOnly)
+				if e.LudwigMode != LudwigScreen {
+					e.ScreenMessage(MsgScreenModeOnly)
 					goto l99
 				}
-				cmdSuccess = UserCommandIntroducer()
+				cmdSuccess = e.UserCommandIntroducer()
 			}
 		}
 		cmdSuccess = true
 
 	case CmdOvertypeMode:
-		EditMode = ModeOvertype
+		e.EditMode = ModeOvertype
 		cmdSuccess = true
 
 	case CmdOvertypeText:
-		if FileData.OldCmds && !fromSpan {
+		if e.FileData.OldCmds && !fromSpan {
 			if rept == LeadParamNone {
-				EditMode = ModeOvertype
+				e.EditMode = ModeOvertype
+				e.LearnDiscard()
 				cmdSuccess = true
 			} else {
-				ScreenMessage(MsgSyntaxError)
+				e.ScreenMessage(MsgSyntaxError)
 			}
-		} else if TparGet1(tparam, command, &request) {
-			cmdSuccess = TextOvertype(true, count, request.Str, request.Len, CurrentFrame.Dot)
+		} else if e.TparGet1(tparam, command, &request) {
+			cmdSuccess = e.TextOvertype(true, count, request.Str, request.Len, e.CurrentFrame.Dot)
 			if cmdSuccess && (count*request.Len != 0) {
-				CurrentFrame.TextModified = true
-				if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-					&CurrentFrame.Marks[MarkModified]) {
+				e.CurrentFrame.TextModified = true
+				if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+					&e.CurrentFrame.Marks[MarkModified]) {
 					cmdSuccess = false
 				}
 			}
@@ -838,126 +897,142 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 
 	case CmdPage:
 		if !fromSpan {
-			ScreenMessage(MsgPaging)
-			if LudwigMode == LudwigScreen {
-				VduFlush()
+			e.ScreenMessage(MsgPaging)
+			if e.LudwigMode == LudwigScreen {
+				e.VduFlush()
 			}
 		}
-		cmdSuccess = FilePage(CurrentFrame, &ExitAbort)
+		cmdSuccess = e.FilePage(e.CurrentFrame, &e.ExitAbort)
 		// Clean up the PAGING message
 		if !fromSpan {
-			ScreenClearMsgs(false)
+			e.ScreenClearMsgs(false)
 		}
 
 	case CmdOpSysCommand:
-		if TparGet1(tparam, command, &request) {
-			if !OpsysCommand(&request, &firstLine, &lastLine, &i) {
+		if e.TparGet1(tparam, command, &request) {
+			if !e.OpsysCommand(&request, &firstLine, &lastLine, &i) {
 				goto l99
 			}
+			// A command that writes nothing still succeeds
+			cmdSuccess = firstLine == nil
 			if firstLine != nil {
-				if !LinesInject(firstLine, lastLine, CurrentFrame.Dot.Line) {
+				if !e.LinesInject(firstLine, lastLine, e.CurrentFrame.Dot.Line) {
 					goto l99
 				}
-				if !MarkCreate(firstLine, 1, &CurrentFrame.Marks[MarkEquals]) {
+				if !MarkCreate(firstLine, 1, &e.CurrentFrame.Marks[MarkEquals]) {
 					goto l99
 				}
-				CurrentFrame.TextModified = true
-				if !MarkCreate(lastLine.FLink, 1, &CurrentFrame.Marks[MarkModified]) {
+				e.CurrentFrame.TextModified = true
+				if !MarkCreate(lastLine.FLink, 1, &e.CurrentFrame.Marks[MarkModified]) {
 					goto l99
 				}
-				if !MarkCreate(lastLine.FLink, 1, &CurrentFrame.Dot) {
+				if !MarkCreate(lastLine.FLink, 1, &e.CurrentFrame.Dot) {
 					goto l99
 				}
 				cmdSuccess = true
 			}
 		}
 
+	case CmdOpSysFilter:
+		cmdSuccess = e.OpsysFilter(rept, count, tparam)
+
+	case CmdErrorList:
+		cmdSuccess = e.ErrorList(tparam)
+
+	case CmdErrorNext:
+		cmdSuccess = e.ErrorNext(count)
+
 	case CmdPositionColumn:
 		if count > MaxStrLen {
 			goto l99
 		}
-		CurrentFrame.Dot.Col = count
+		e.CurrentFrame.Dot.Col = count
 		cmdSuccess = true
 
 	case CmdPositionLine:
-		if !LineFromNumber(CurrentFrame, count, &newLine) {
+		if !LineFromNumber(e.CurrentFrame, count, &newLine) {
 			goto l99
 		}
 		if newLine == nil {
 			goto l99
 		}
 		cmdSuccess = true
-		if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkEquals]) {
+		if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkEquals]) {
 			goto l99
 		}
-		MarkCreate(newLine, 1, &CurrentFrame.Dot)
+		MarkCreate(newLine, 1, &e.CurrentFrame.Dot)
 
 	case CmdQuit:
-		cmdSuccess = QuitCommand()
+		cmdSuccess = e.QuitCommand()
 
 	case CmdReplace:
-		if TparGet2(tparam, command, &request, &request2) {
+		if e.TparGet2(tparam, command, &request, &request2) {
 			if request.Len == 0 { // If didn't specify, use default
-				if CurrentFrame.Rep1Tpar.Len == 0 {
-					ScreenMessage(MsgNoDefaultStr)
+				if e.CurrentFrame.Rep1Tpar.Len == 0 {
+					e.ScreenMessage(MsgNoDefaultStr)
 					goto l99
 				}
 			} else {
-				CurrentFrame.Rep1Tpar = request // If did specify, save for next time
-				TparCleanObject(&CurrentFrame.Rep2Tpar)
-				CurrentFrame.Rep2Tpar = request2
+				e.CurrentFrame.Rep1Tpar = request // If did specify, save for next time
+				TparCleanObject(&e.CurrentFrame.Rep2Tpar)
+				e.CurrentFrame.Rep2Tpar = request2
 				request2.Con = nil
 			}
-			cmdSuccess = EqsGetRepRep(rept, count, CurrentFrame.Rep1Tpar,
-				CurrentFrame.Rep2Tpar, fromSpan)
+			cmdSuccess = e.EqsGetRepRep(rept, count, e.CurrentFrame.Rep1Tpar,
+				e.CurrentFrame.Rep2Tpar, fromSpan)
 		}
 
 	case CmdRubout:
-		cmdSuccess = CharcmdRubout(command, rept, count, fromSpan)
+		cmdSuccess = e.CharcmdRubout(command, rept, count, fromSpan)
 
 	case CmdSetMarginLeft:
 		if rept == LeadParamMinus {
-			CurrentFrame.MarginLeft = InitialMarginLeft
+			e.CurrentFrame.MarginLeft = e.InitialMarginLeft
 		} else {
-			if CurrentFrame.Dot.Col >= CurrentFrame.MarginRight {
-				ScreenMessage(MsgLeftMarginGeRight)
+			if e.CurrentFrame.Dot.Col >= e.CurrentFrame.MarginRight {
+				e.ScreenMessage(MsgLeftMarginGeRight)
 				goto l99
 			}
-			CurrentFrame.MarginLeft = CurrentFrame.Dot.Col
+			e.CurrentFrame.MarginLeft = e.CurrentFrame.Dot.Col
 		}
 		cmdSuccess = true
 
 	case CmdSetMarginRight:
 		if rept == LeadParamMinus {
-			CurrentFrame.MarginRight = InitialMarginRight
+			e.CurrentFrame.MarginRight = e.InitialMarginRight
 		} else {
-			if CurrentFrame.Dot.Col <= CurrentFrame.MarginLeft {
-				ScreenMessage(MsgLeftMarginGeRight)
+			if e.CurrentFrame.Dot.Col <= e.CurrentFrame.MarginLeft {
+				e.ScreenMessage(MsgLeftMarginGeRight)
 				goto l99
 			}
-			CurrentFrame.MarginRight = CurrentFrame.Dot.Col
+			e.CurrentFrame.MarginRight = e.CurrentFrame.Dot.Col
 		}
 		cmdSuccess = true
 
 	case CmdSpanJump, CmdSpanCompile, CmdSpanCopy, CmdSpanDefine,
 		CmdSpanExecute, CmdSpanExecuteNoRecompile, CmdSpanTransfer:
-		if TparGet1(tparam, command, &request) {
+		if e.TparGet1(tparam, command, &request) {
 			newName = request.Str.Slice(1, request.Len)
+			// A span that is a frame runs to the end of the frame
+			if command != CmdSpanDefine && e.SpanFind(newName, &newSpan, &oldSpan) &&
+				newSpan.Frame != nil && !e.FileReadAhead(newSpan.Frame, -1) {
+				goto l99
+			}
 			switch command {
 			case CmdSpanDefine:
 				if rept == LeadParamMinus {
-					if SpanFind(newName, &newSpan, &oldSpan) {
-						cmdSuccess = SpanDestroy(&newSpan)
+					if e.SpanFind(newName, &newSpan, &oldSpan) {
+						cmdSuccess = e.SpanDestroy(&newSpan)
 					} else {
-						ScreenMessage(MsgNoSuchSpan)
+						e.ScreenMessage(MsgNoSuchSpan)
 					}
 				} else {
-					cmdSuccess = SpanCreate(newName, theMark, CurrentFrame.Dot)
+					cmdSuccess = e.SpanCreate(newName, theMark, e.CurrentFrame.Dot)
 				}
 
 			case CmdSpanJump:
-				if SpanFind(newName, &newSpan, &oldSpan) {
+				if e.SpanFind(newName, &newSpan, &oldSpan) {
 					if rept == LeadParamMinus {
 						newCol = newSpan.MarkOne.Col
 						newLine = newSpan.MarkOne.Line
@@ -965,15 +1040,15 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 						newCol = newSpan.MarkTwo.Col
 						newLine = newSpan.MarkTwo.Line
 					}
-					if newLine.Group.Frame == CurrentFrame {
-						cmdSuccess = MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col,
-							&CurrentFrame.Marks[MarkEquals])
+					if newLine.Group.Frame == e.CurrentFrame {
+						cmdSuccess = MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col,
+							&e.CurrentFrame.Marks[MarkEquals])
 						if cmdSuccess {
-							cmdSuccess = MarkCreate(newLine, newCol, &CurrentFrame.Dot)
+							cmdSuccess = MarkCreate(newLine, newCol, &e.CurrentFrame.Dot)
 						}
 					} else {
 						fr := newLine.Group.Frame
-						if FrameEdit(fr.Span.Name) {
+						if e.FrameEdit(fr.Span.Name) {
 							if fr.Marks[MarkEquals] != nil {
 								MarkDestroy(&fr.Marks[MarkEquals])
 							}
@@ -981,170 +1056,174 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 						}
 					}
 				} else {
-					ScreenMessage(MsgNoSuchSpan)
+					e.ScreenMessage(MsgNoSuchSpan)
 				}
 
 			case CmdSpanCopy, CmdSpanTransfer:
-				if SpanFind(newName, &newSpan, &oldSpan) {
-					cmdSuccess = TextMove(
+				if e.SpanFind(newName, &newSpan, &oldSpan) {
+					cmdSuccess = e.TextMove(
 						command == CmdSpanCopy,
 						count,
 						newSpan.MarkOne,
 						newSpan.MarkTwo,
-						CurrentFrame.Dot,                // Dest
-						&CurrentFrame.Marks[MarkEquals], // New_Start
-						&CurrentFrame.Dot,               // New_End
+						e.CurrentFrame.Dot,                // Dest
+						&e.CurrentFrame.Marks[MarkEquals], // New_Start
+						&e.CurrentFrame.Dot,               // New_End
 					)
 					if command == CmdSpanTransfer && newSpan.Frame == nil && cmdSuccess {
-						MarkCreate(CurrentFrame.Marks[MarkEquals].Line,
-							CurrentFrame.Marks[MarkEquals].Col, &newSpan.MarkOne)
-						MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &newSpan.MarkTwo)
+						MarkCreate(e.CurrentFrame.Marks[MarkEquals].Line,
+							e.CurrentFrame.Marks[MarkEquals].Col, &newSpan.MarkOne)
+						MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &newSpan.MarkTwo)
 					}
 				} else {
-					ScreenMessage(MsgNoSuchSpan)
+					e.ScreenMessage(MsgNoSuchSpan)
 				}
 
 			case CmdSpanCompile, CmdSpanExecute, CmdSpanExecuteNoRecompile:
-				if SpanFind(newName, &newSpan, &oldSpan) {
+				if e.SpanFind(newName, &newSpan, &oldSpan) {
 					if newSpan.Code == nil || command != CmdSpanExecuteNoRecompile {
-						if !CodeCompile(newSpan, true) {
+						if !e.CodeCompile(newSpan, true) {
 							goto l99
 						}
 					}
 					if command == CmdSpanCompile {
 						cmdSuccess = true
 					} else {
-						cmdSuccess = CodeInterpret(rept, count, newSpan.Code, true)
+						cmdSuccess = e.CodeInterpret(rept, count, newSpan.Code, true)
 					}
 				} else {
-					ScreenMessage(MsgNoSuchSpan)
+					e.ScreenMessage(MsgNoSuchSpan)
 				}
 			}
 		}
 
 	case CmdSpanIndex:
-		cmdSuccess = SpanIndex()
+		cmdSuccess = e.SpanIndex()
 
 	case CmdSpanAssign:
-		if !TparGet2(tparam, command, &request, &request2) {
+		if !e.TparGet2(tparam, command, &request, &request2) {
 			goto l99
 		}
 		if request.Len == 0 {
 			goto l99
 		}
 		newName = request.Str.Slice(1, request.Len)
-		if SpanFind(newName, &newSpan, &oldSpan) {
-			// Grunge the old one
-			if newSpan == FrameOops.Span {
-				if !TextRemove(FrameOops.Span.MarkOne, FrameOops.Span.MarkTwo) {
-					goto l99
-				}
-			} else {
-				// Make sure oops_span is okay
-				if !MarkCreate(FrameOops.LastGroup.LastLine, 1, &FrameOops.Span.MarkTwo) {
-					goto l99
-				}
-				if !TextMove(
-					false, // Don't copy, transfer
-					1,     // One instance of
-					newSpan.MarkOne,
-					newSpan.MarkTwo,
-					FrameOops.Span.MarkTwo,       // destination
-					&FrameOops.Marks[MarkEquals], // leave at start
-					&FrameOops.Dot,               // leave at end
-				) {
-					goto l99
-				}
-			}
-		} else {
-			// Create a span in frame "HEAP"
-			if !MarkCreate(FrameHeap.LastGroup.LastLine, 1, &FrameHeap.Span.MarkTwo) {
-				goto l99
-			}
-			if !SpanCreate(newName, FrameHeap.Span.MarkTwo, FrameHeap.Span.MarkTwo) {
-				goto l99
-			}
-			if !SpanFind(newName, &newSpan, &oldSpan) {
-				goto l99
-			}
-		}
-		// Now copy the tpar into the span
-		if !TextInsertTpar(&request2, newSpan.MarkTwo, &newSpan.MarkOne) {
-			goto l99
-		}
-		fr := newSpan.MarkTwo.Line.Group.Frame
-		fr.TextModified = true
-		cmdSuccess = MarkCreate(newSpan.MarkTwo.Line, newSpan.MarkTwo.Col, &fr.Marks[MarkModified])
+		cmdSuccess = e.SpanAssign(newName, &request2)
 
 	case CmdSplitLine:
-		if CurrentFrame.Dot.Line.FLink == nil {
-			if !TextRealizeNull(CurrentFrame.Dot.Line) {
+		if e.CurrentFrame.Dot.Line.FLink == nil {
+			if !e.TextRealizeNull(e.CurrentFrame.Dot.Line) {
 				goto l99
 			}
 		}
-		cmdSuccess = TextSplitLine(CurrentFrame.Dot, 0, &CurrentFrame.Marks[MarkEquals])
+		cmdSuccess = e.TextSplitLine(e.CurrentFrame.Dot, 0, &e.CurrentFrame.Marks[MarkEquals])
 
 	case CmdSwapLine:
-		cmdSuccess = SwapLine(rept, count)
+		cmdSuccess = e.SwapLine(rept, count)
 
 	case CmdUserCommandIntroducer:
-		if LudwigMode != LudwigScreen {
-			ScreenMessage(MsgScreenModeOnly)
+		if e.LudwigMode != LudwigScreen {
+			e.ScreenMessage(MsgScreenModeOnly)
 			goto l99
 		}
-		cmdSuccess = UserCommandIntroducer()
+		cmdSuccess = e.UserCommandIntroducer()
+
+	case CmdUserLearn:
+		if e.LudwigMode != LudwigScreen {
+			e.ScreenMessage(MsgScreenModeOnly)
+			goto l99
+		}
+		if rept == LeadParamMinus {
+			cmdSuccess = e.UserLearnEnd()
+		} else if e.TparGet1(tparam, command, &request) {
+			cmdSuccess = e.UserLearn(request.Str.Slice(1, request.Len))
+		}
+
+	case CmdUserRecall:
+		if e.TparGet1(tparam, command, &request) {
+			cmdSuccess = e.UserRecall(rept, count, request.Str.Slice(1, request.Len))
+		}
 
 	case CmdUserKey:
-		if LudwigMode != LudwigScreen {
-			ScreenMessage(MsgScreenModeOnly)
+		if e.LudwigMode != LudwigScreen {
+			e.ScreenMessage(MsgScreenModeOnly)
 			goto l99
 		}
-		if TparGet2(tparam, command, &request, &request2) {
+		if e.TparGet2(tparam, command, &request, &request2) {
 			if request.Len == 0 {
 				cmdSuccess = false
 			} else {
-				cmdSuccess = UserKey(&request, &request2)
+				cmdSuccess = e.UserKey(&request, &request2)
 			}
 		}
 
 	case CmdUserParent:
-		if LudwigMode == LudwigBatch {
-			ScreenMessage(MsgInteractiveModeOnly)
+		if e.LudwigMode == LudwigBatch {
+			e.ScreenMessage(MsgInteractiveModeOnly)
 			goto l99
 		}
-		cmdSuccess = UserParent()
+		cmdSuccess = e.UserParent()
 
 	case CmdUserSubprocess:
-		if LudwigMode == LudwigBatch {
-			ScreenMessage(MsgInteractiveModeOnly)
+		if e.LudwigMode == LudwigBatch {
+			e.ScreenMessage(MsgInteractiveModeOnly)
 			goto l99
 		}
 		cmdSuccess = UserSubprocess()
 
 	case CmdUserUndo:
-		cmdSuccess = UserUndo()
+		cmdSuccess = e.UserUndo(rept, count)
 
 	case CmdWindowBackward, CmdWindowEnd, CmdWindowForward, CmdWindowLeft,
 		CmdWindowMiddle, CmdWindowNew, CmdWindowRight, CmdWindowScroll,
-		CmdWindowSetHeight, CmdWindowTop, CmdWindowUpdate:
-		cmdSuccess = WindowCommand(command, rept, count, fromSpan)
+		CmdWindowSetHeight, CmdWindowTop, CmdWindowUpdate, CmdWindowSplit,
+		CmdWindowSplitVertical, CmdWindowNext, CmdWindowKill, CmdWindowOnly:
+		cmdSuccess = e.WindowCommand(command, rept, count, fromSpan)
 
 	case CmdResizeWindow:
-		ScreenResize()
+		e.ScreenResize()
 		cmdSuccess = true
 
 	case CmdValidate:
 		// DEBUG command - skip in release build
 
 	case CmdBlockDefine, CmdBlockTransfer, CmdBlockCopy:
-		ScreenMessage(MsgNotImplemented)
+		if e.TparGet1(tparam, command, &request) {
+			newName = request.Str.Slice(1, request.Len)
+			switch command {
+			case CmdBlockDefine:
+				if rept == LeadParamMinus {
+					if !e.SpanFind(newName, &newSpan, &oldSpan) {
+						e.ScreenMessage(MsgNoSuchSpan)
+					} else if !newSpan.Block {
+						e.ScreenMessage(MsgNotABlock)
+					} else {
+						cmdSuccess = e.SpanDestroy(&newSpan)
+					}
+				} else if e.SpanCreate(newName, theMark, e.CurrentFrame.Dot) {
+					if e.SpanFind(newName, &newSpan, &oldSpan) {
+						newSpan.Block = true
+						cmdSuccess = true
+					}
+				}
+
+			case CmdBlockCopy, CmdBlockTransfer:
+				if !e.SpanFind(newName, &newSpan, &oldSpan) {
+					e.ScreenMessage(MsgNoSuchSpan)
+				} else if !newSpan.Block {
+					e.ScreenMessage(MsgNotABlock)
+				} else {
+					cmdSuccess = e.BlockMove(command == CmdBlockCopy, count, newSpan)
+				}
+			}
+		}
 
 	default:
-		ScreenMessage(DbgInternalLogicError)
+		e.ScreenMessage(DbgInternalLogicError)
 	}
 
 	if cmdSuccess {
-		switch CmdAttrib[command].EqAction {
+		switch e.CmdAttrib[command].EqAction {
 		case EqOld:
 			eqSet = MarkCreate(oldDot.Line, oldDot.Col, &oldFrame.Marks[MarkEquals])
 		case EqDel:
@@ -1154,13 +1233,13 @@ func Execute(command Commands, rept LeadParam, count int, tparam *TParObject, fr
 			eqSet = true
 		}
 		if !eqSet {
-			ScreenMessage(MsgEqualsNotSet)
+			e.ScreenMessage(MsgEqualsNotSet)
 		}
 	}
 
 l99:
 	TparCleanObject(&request)
 	TparCleanObject(&request2)
-	ExecLevel--
+	e.ExecLevel--
 	return cmdSuccess
 }
@@ -24,7 +24,7 @@ const (
 )
 
 // ExecuteImmed is the main execution loop for Ludwig
-func ExecuteImmed() {
+func (e *Editor) ExecuteImmed() {
 	var cmdSpan SpanObject
 	cmdSpan.FLink = nil
 	cmdSpan.BLink = nil
@@ -36,203 +36,222 @@ func ExecuteImmed() {
 
 	// Vector off to the appropriate main execution mode. Each mode behaves
 	// slightly differently at this level.
-	switch LudwigMode {
+	switch e.LudwigMode {
 	case LudwigScreen:
 		jammed := false
 		var cmdSuccess bool
 		for {
 		l2:
+			e.SysCheckSignals()
 			cmdSuccess = true
+			e.UndoCheckpoint()
 
 			// MAKE SURE THE USER CAN SEE THE CURRENT DOT POSITION.
-			ScreenFixup()
+			e.ScreenFixup()
+
+			// READ MORE OF A LARGE FILE WHEN ITS END COMES ON THE SCREEN.
+			if e.CurrentFrame.LastGroup.LastLine.ScrRowNr != 0 && e.FileUnread(e.CurrentFrame) {
+				e.FileReadAhead(e.CurrentFrame, filePieceLines)
+				e.ScreenFixup()
+			}
+			e.JournalUpdate()
 
 			var key int
-			if EditMode == ModeCommand {
-				key = CommandIntroducer
+			if e.EditMode == ModeCommand {
+				key = e.CommandIntroducer
 			} else {
 				// OVERTYPE/INSERTMODE IS DONE HERE AS A SPECIAL CASE.
 				// THIS IS NECESSARY BECAUSE THE SCREEN IS UPDATED BY
 				// VDU_GET_TEXT.
 				for {
 					// Check for boundaries where text cannot be accepted.
-					if jammed || CurrentFrame.Dot.Col == MaxStrLenP {
-						key = VduGetKey()
-						if TtControlC {
+					if jammed || e.CurrentFrame.Dot.Col == MaxStrLenP {
+						key = e.VduGetKey()
+						if e.TtControlC.Load() {
 							goto l9
 						}
-						if ChIsPrintable(rune(key)) && key != CommandIntroducer {
+						if ChIsPrintableKey(key) && key != e.CommandIntroducer {
 							cmdSuccess = false
 							goto l9
 						}
-						VduTakeBackKey(key)
+						e.VduTakeBackKey(key)
 						break
 					}
 
 					// DECIDE MAX CHARS THAT CAN BE READ.
-					tmpScrCol := CurrentFrame.Dot.Col - CurrentFrame.ScrOffset
-					inputLen := MaxStrLenP - CurrentFrame.Dot.Col
-					if CurrentFrame.Dot.Col <= CurrentFrame.MarginRight {
-						inputLen = CurrentFrame.MarginRight - CurrentFrame.Dot.Col + 1
+					tmpScrCol := e.screenDotCol()
+					inputLen := MaxStrLenP - e.CurrentFrame.Dot.Col
+					if e.CurrentFrame.Dot.Col <= e.CurrentFrame.MarginRight {
+						inputLen = e.CurrentFrame.MarginRight - e.CurrentFrame.Dot.Col + 1
 					}
-					if inputLen > CurrentFrame.ScrWidth+1-tmpScrCol {
-						inputLen = CurrentFrame.ScrWidth + 1 - tmpScrCol
+					if inputLen > e.CurrentFrame.ScrWidth+1-tmpScrCol {
+						inputLen = e.CurrentFrame.ScrWidth + 1 - tmpScrCol
 					}
 
 					// WATCH OUT FOR NULL LINE.
-					if CurrentFrame.Dot.Line.FLink == nil {
-						key = VduGetKey()
-						if TtControlC {
+					if e.CurrentFrame.Dot.Line.FLink == nil {
+						key = e.VduGetKey()
+						if e.TtControlC.Load() {
 							goto l9
 						}
-						VduTakeBackKey(key)
-						if ChIsPrintable(rune(key)) && key != CommandIntroducer {
+						e.VduTakeBackKey(key)
+						if ChIsPrintableKey(key) && key != e.CommandIntroducer {
 							// If printing char, realize NULL, re-fix cursor.
-							if !TextRealizeNull(CurrentFrame.Dot.Line) {
+							if !e.TextRealizeNull(e.CurrentFrame.Dot.Line) {
 								cmdSuccess = false
 								goto l9
 							}
 						}
 
 						// MAKE SURE THE USER CAN SEE THE CURRENT DOT POSITION.
-						ScreenFixup()
+						e.ScreenFixup()
 					}
 
 					// GET THE ECHOING TEXT
-					if EditMode == ModeInsert {
-						VduInsertMode(true)
+					if e.EditMode == ModeInsert {
+						e.VduInsertMode(true)
 					}
-					inputBuf := NewBlankStrObject(MaxStrLen)
-					VduGetText(inputLen, inputBuf, &inputLen)
-					if EditMode == ModeInsert {
-						VduInsertMode(false)
-						VduFlush() // Make sure in mode IS off!
+					// The echo is only right for characters one column wide
+					narrow := screenNarrow(e.CurrentFrame.Dot.Line)
+					inputBuf := EmptyStrObject()
+					e.VduGetText(inputLen, inputBuf, &inputLen)
+					if e.EditMode == ModeInsert {
+						e.VduInsertMode(false)
+						e.VduFlush() // Make sure in mode IS off!
 					}
-					if TtControlC {
+					if e.TtControlC.Load() {
 						goto l9
 					}
 					if inputLen == 0 {
 						break // Simulate a continue
 					}
 
-					if EditMode == ModeOvertype {
-						cmdSuccess = TextOvertype(false, 1, inputBuf, inputLen, CurrentFrame.Dot)
+					if e.EditMode == ModeOvertype {
+						cmdSuccess = e.TextOvertype(false, 1, inputBuf, inputLen, e.CurrentFrame.Dot)
 					} else {
-						cmdSuccess = TextInsert(false, 1, inputBuf, inputLen, CurrentFrame.Dot)
+						cmdSuccess = e.TextInsert(false, 1, inputBuf, inputLen, e.CurrentFrame.Dot)
 					}
 					if cmdSuccess {
-						CurrentFrame.TextModified = true
+						if !narrow || !screenNarrow(e.CurrentFrame.Dot.Line) ||
+							e.SyntaxRulesFor(e.CurrentFrame) != nil {
+							e.ScreenDrawLine(e.CurrentFrame.Dot.Line)
+						}
+						e.LearnText(e.EditMode == ModeOvertype, inputBuf, inputLen)
+						e.CurrentFrame.TextModified = true
 						if !MarkCreate(
-							CurrentFrame.Dot.Line,
-							CurrentFrame.Dot.Col,
-							&CurrentFrame.Marks[MarkModified],
+							e.CurrentFrame.Dot.Line,
+							e.CurrentFrame.Dot.Col,
+							&e.CurrentFrame.Marks[MarkModified],
 						) {
 							cmdSuccess = false
 						}
 						if !MarkCreate(
-							CurrentFrame.Dot.Line,
-							CurrentFrame.Dot.Col-inputLen,
-							&CurrentFrame.Marks[MarkEquals],
+							e.CurrentFrame.Dot.Line,
+							e.CurrentFrame.Dot.Col-inputLen,
+							&e.CurrentFrame.Marks[MarkEquals],
 						) {
 							cmdSuccess = false
 						}
 					} else {
 						// IF, FOR SOME REASON, THAT FAILED, CORRECT THE VDU IMAGE OF
 						// THE LINE. THIS IS BECAUSE VDU_GET_TEXT HAS CORRUPTED IT.
-						ScreenDrawLine(CurrentFrame.Dot.Line)
+						e.ScreenDrawLine(e.CurrentFrame.Dot.Line)
 						goto l9
 					}
-					if CurrentFrame.Dot.Col != CurrentFrame.MarginRight+1 {
+					if e.CurrentFrame.Dot.Col != e.CurrentFrame.MarginRight+1 {
 						// FOLLOW THE DOT.
-						ScreenPosition(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col)
-						VduMoveCurs(
-							CurrentFrame.Dot.Col-CurrentFrame.ScrOffset,
-							CurrentFrame.Dot.Line.ScrRowNr,
+						e.ScreenPosition(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col)
+						e.VduMoveCurs(
+							e.screenDotCol(),
+							e.CurrentFrame.Dot.Line.ScrRowNr,
 						)
 					} else {
 						// AT THE RIGHT MARGIN.
-						if CurrentFrame.Options.Has(OptAutoWrap) {
+						if e.CurrentFrame.Options.Has(OptAutoWrap) {
 							// Take care of Wrap Option.
-							key = VduGetKey()
-							if TtControlC {
+							key = e.VduGetKey()
+							if e.TtControlC.Load() {
 								goto l9
 							}
-							if ChIsPrintable(rune(key)) && key != CommandIntroducer {
-								col1 := CurrentFrame.MarginRight
+							if ChIsPrintableKey(key) && key != e.CommandIntroducer {
+								col1 := e.CurrentFrame.MarginRight
 								if key != ' ' {
-									for CurrentFrame.Dot.Line.Str.Get(col1) != ' ' &&
-										col1 > CurrentFrame.MarginLeft {
+									for e.CurrentFrame.Dot.Line.Str.Get(col1) != ' ' &&
+										col1 > e.CurrentFrame.MarginLeft {
 										col1--
 									}
 									col2 := col1
-									for CurrentFrame.Dot.Line.Str.Get(col2) == ' ' &&
-										col2 > CurrentFrame.MarginLeft {
+									for e.CurrentFrame.Dot.Line.Str.Get(col2) == ' ' &&
+										col2 > e.CurrentFrame.MarginLeft {
 										col2--
 									}
-									if col2 == CurrentFrame.MarginLeft { // Line has only one word
-										col1 = CurrentFrame.MarginRight // Split at right margin
+									if col2 == e.CurrentFrame.MarginLeft { // Line has only one word
+										col1 = e.CurrentFrame.MarginRight // Split at right margin
 									}
-									VduTakeBackKey(key)
+									e.VduTakeBackKey(key)
 								}
-								CurrentFrame.Dot.Col++
-								cmdSuccess = TextSplitLine(
-									CurrentFrame.Dot, 0, &CurrentFrame.Marks[MarkEquals],
+								e.CurrentFrame.Dot.Col++
+								cmdSuccess = e.TextSplitLine(
+									e.CurrentFrame.Dot, 0, &e.CurrentFrame.Marks[MarkEquals],
 								)
-								CurrentFrame.Dot.Col += CurrentFrame.MarginRight - col1
+								e.CurrentFrame.Dot.Col += e.CurrentFrame.MarginRight - col1
 								goto l2 // Simulate break of inner loop
 							}
-							VduTakeBackKey(key)
+							e.VduTakeBackKey(key)
 						} else {
-							VduBeep()
-							CurrentFrame.Dot.Col--
-							VduMoveCurs(
-								CurrentFrame.Dot.Col-CurrentFrame.ScrOffset,
-								CurrentFrame.Dot.Line.ScrRowNr,
+							e.VduBeep()
+							e.CurrentFrame.Dot.Col--
+							e.VduMoveCurs(
+								e.screenDotCol(),
+								e.CurrentFrame.Dot.Line.ScrRowNr,
 							)
 							jammed = true
 						}
 					}
 				} // of overtyping loop
 
-				key = VduGetKey() // key is a terminator
-				if TtControlC {
+				key = e.VduGetKey() // key is a terminator
+				if e.TtControlC.Load() {
 					goto l9
 				}
 
 				// DEBUG code removed - would check for printable characters
 			}
 
-			if key == CommandIntroducer {
-				if CodeCompile(&cmdSpan, false) {
-					cmdSuccess = CodeInterpret(LeadParamNone, 1, cmdSpan.Code, false)
+			if key == e.CommandIntroducer {
+				e.LearnBegin()
+				if e.CodeCompile(&cmdSpan, false) {
+					cmdSuccess = e.CodeInterpret(LeadParamNone, 1, cmdSpan.Code, false)
 				} else {
 					cmdSuccess = false
 				}
 			} else {
-				if Lookup[key].Command == CmdExtended {
-					cmdSuccess = CodeInterpret(LeadParamNone, 1, Lookup[key].Code, true)
+				e.LearnKey(key)
+				if e.Lookup[key].Command == CmdExtended {
+					cmdSuccess = e.CodeInterpret(LeadParamNone, 1, e.Lookup[key].Code, true)
 				} else {
-					cmdSuccess = Execute(
-						Lookup[key].Command, LeadParamNone, 1, Lookup[key].Tpar, false,
+					cmdSuccess = e.Execute(
+						e.Lookup[key].Command, LeadParamNone, 1, e.Lookup[key].Tpar, false,
 					)
 				}
 			}
+			e.LearnEnd(cmdSuccess)
 
 		l9:
-			if TtControlC {
-				TtControlC = false
-				if CurrentFrame.Dot.Line.ScrRowNr != 0 {
-					ScreenRedraw()
+			if e.TtControlC.Load() {
+				e.TtControlC.Store(false)
+				if e.CurrentFrame.Dot.Line.ScrRowNr != 0 {
+					e.ScreenRedraw()
 				} else {
-					ScreenUnload()
+					e.ScreenUnload()
 				}
 			} else if !cmdSuccess {
-				VduBeep()  // Complain.
-				VduFlush() // Make sure they hear the complaint.
+				e.VduBeep()  // Complain.
+				e.VduFlush() // Make sure they hear the complaint.
 			} else {
 				jammed = false
 			}
-			ExitAbort = false
+			e.ExitAbort = false
 		}
 
 	case LudwigHardcopy, LudwigBatch:
@@ -243,7 +262,7 @@ func ExecuteImmed() {
 		cmdSpan.MarkTwo = &MarkObject{}
 		cmdSpan.MarkTwo.Line = nil
 		var cmdCount int
-		if LudwigMode == LudwigHardcopy {
+		if e.LudwigMode == LudwigHardcopy {
 			cmdCount = 1
 		} else {
 			cmdCount = MaxInt
@@ -253,8 +272,10 @@ func ExecuteImmed() {
 		var cmdFnm string
 		var cmdFile *FileObject
 		var dummyFptr *FileObject
-		if FileCreateOpen(&cmdFnm, ParseStdin, &cmdFile, &dummyFptr) {
+		if e.FileCreateOpen(&cmdFnm, ParseStdin, &cmdFile, &dummyFptr) {
 			for {
+				e.SysCheckSignals()
+
 				// Destroy all of cmd_span's contents.
 				if cmdSpan.MarkOne.Line != nil {
 					if !LinesDestroy(&cmdSpan.MarkOne.Line, &cmdSpan.MarkTwo.Line) {
@@ -265,14 +286,14 @@ func ExecuteImmed() {
 				}
 
 				// If necessary, prompt.
-				if LudwigMode == LudwigHardcopy {
-					ScreenLoad(CurrentFrame.Dot.Line)
-					fmt.Println("COMMAND: ")
+				if e.LudwigMode == LudwigHardcopy {
+					e.ScreenLoad(e.CurrentFrame.Dot.Line)
+					fmt.Fprintln(e.Output, "COMMAND: ")
 				}
 
 				// Read, compile, and execute the next lot of commands.
 				var i int
-				if FileRead(
+				if e.FileRead(
 					cmdFile,
 					cmdCount,
 					true,
@@ -282,21 +303,22 @@ func ExecuteImmed() {
 				) {
 					if cmdSpan.MarkOne.Line != nil {
 						cmdSpan.MarkTwo.Col = cmdSpan.MarkTwo.Line.Used + 1
-						if CodeCompile(&cmdSpan, true) {
-							if !CodeInterpret(LeadParamNone, 1, cmdSpan.Code, true) {
-								fmt.Println("\aCOMMAND FAILED")
+						e.UndoCheckpoint()
+						if e.CodeCompile(&cmdSpan, true) {
+							if !e.CodeInterpret(LeadParamNone, 1, cmdSpan.Code, true) {
+								fmt.Fprintln(e.Output, "\aCOMMAND FAILED")
 							}
 						}
-						ExitAbort = false
-						TtControlC = false
+						e.ExitAbort = false
+						e.TtControlC.Store(false)
 					}
 				}
 				if cmdFile.Eof {
 					break
 				}
 			}
-			LudwigAborted = false
-			QuitCloseFiles()
+			e.LudwigAborted = false
+			e.QuitCloseFiles()
 		}
 	}
 }
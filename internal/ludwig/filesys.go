@@ -16,14 +16,19 @@ package ludwig
 
 import (
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 const (
 	filesysNL     = "\n"
 	filesysNLSize = 1
+
+	// filesysBufSize is the number of bytes read from a file at a time
+	filesysBufSize = 8192
 )
 
 // removeBackupFiles removes backup files in the specified range
@@ -40,29 +45,29 @@ func toArgv(cmdline string) []string {
 }
 
 // FilesysCreateOpen opens a file for input or creates a file for output
-func FilesysCreateOpen(fyle *FileObject, rfyle *FileObject, ordinaryOpen bool) bool {
+func (e *Editor) FilesysCreateOpen(fyle *FileObject, rfyle *FileObject, ordinaryOpen bool) bool {
 	fyle.LCounter = 0
 	if !fyle.OutputFlag { // open file for reading
 		if fyle.Filename == "" {
 			return false
 		}
 		if !ordinaryOpen { // really executing a command
-			fd := SysOpenCommand(fyle.Filename)
+			fd := e.SysOpenCommand(fyle.Filename)
 			if fd == -1 {
-				ScreenMessage("Cannot create pipe")
+				e.ScreenMessage("Cannot create pipe")
 				return false
 			}
 			fyle.Fd = fd
 		} else {
 			if !SysExpandFilename(&fyle.Filename) {
-				ScreenMessage(fmt.Sprintf("Error in filename (%s)", fyle.Filename))
+				e.ScreenMessage(fmt.Sprintf("Error in filename (%s)", fyle.Filename))
 				return false
 			}
 			fs := SysFileStatus(fyle.Filename)
 			if !fs.Valid || fs.IsDir {
 				return false
 			}
-			fyle.Fd = SysOpenFile(fyle.Filename)
+			fyle.Fd = e.SysOpenFile(fyle.Filename)
 			if fyle.Fd < 0 {
 				return false
 			}
@@ -83,7 +88,7 @@ func FilesysCreateOpen(fyle *FileObject, rfyle *FileObject, ordinaryOpen bool) b
 		if fyle.Filename == "" {
 			return false
 		} else if !SysExpandFilename(&fyle.Filename) {
-			ScreenMessage(fmt.Sprintf("Error in filename (%s)", fyle.Filename))
+			e.ScreenMessage(fmt.Sprintf("Error in filename (%s)", fyle.Filename))
 			return false
 		}
 		// if the file given is a directory create a filename using the input
@@ -97,17 +102,17 @@ func FilesysCreateOpen(fyle *FileObject, rfyle *FileObject, ordinaryOpen bool) b
 		if fs.Valid {
 			// if we wanted to create a new file then complain
 			if fyle.Create {
-				ScreenMessage(fmt.Sprintf("File (%s) already exists", fyle.Filename))
+				e.ScreenMessage(fmt.Sprintf("File (%s) already exists", fyle.Filename))
 				return false
 			}
 			// check that the file we may overwrite is not a directory
 			if fs.IsDir {
-				ScreenMessage(fmt.Sprintf("File (%s) is a directory", fyle.Filename))
+				e.ScreenMessage(fmt.Sprintf("File (%s) is a directory", fyle.Filename))
 				return false
 			}
 			// check that we can write over the current version
 			if !SysFileWriteable(fyle.Filename) {
-				ScreenMessage(fmt.Sprintf("Write access to file (%s) is denied", fyle.Filename))
+				e.ScreenMessage(fmt.Sprintf("Write access to file (%s) is denied", fyle.Filename))
 				return false
 			}
 			fyle.Mode = fs.Mode
@@ -123,9 +128,9 @@ func FilesysCreateOpen(fyle *FileObject, rfyle *FileObject, ordinaryOpen bool) b
 			uniq++
 			fyle.Tnm = fyle.Filename + "-lw" + strconv.Itoa(uniq)
 		}
-		fyle.Fd = SysCreateFile(fyle.Tnm)
+		fyle.Fd = e.SysCreateFile(fyle.Tnm)
 		if fyle.Fd < 0 {
-			ScreenMessage(fmt.Sprintf("Error opening (%s) as output", fyle.Tnm))
+			e.ScreenMessage(fmt.Sprintf("Error opening (%s) as output", fyle.Tnm))
 			return false
 		}
 	}
@@ -140,12 +145,12 @@ func FilesysCreateOpen(fyle *FileObject, rfyle *FileObject, ordinaryOpen bool) b
 //	2 : process the file as if closing it, but do not close it
 //
 // The msgs parameter indicates whether we want to be told about what is going on
-func FilesysClose(fyle *FileObject, action int, msgs bool) bool {
+func (e *Editor) FilesysClose(fyle *FileObject, action int, msgs bool) bool {
 	if !fyle.OutputFlag {
 		// reap any children
 		SysReapChildren()
 		// an ordinary input file, just close
-		if SysClose(fyle.Fd) < 0 {
+		if e.SysClose(fyle.Fd) < 0 {
 			return false
 		}
 		if msgs {
@@ -153,21 +158,21 @@ func FilesysClose(fyle *FileObject, action int, msgs bool) bool {
 			if fyle.LCounter == 1 {
 				plural = ""
 			}
-			ScreenMessage(fmt.Sprintf("File %s closed (%d line%s read).",
+			e.ScreenMessage(fmt.Sprintf("File %s closed (%d line%s read).",
 				fyle.Filename, fyle.LCounter, plural))
 		}
 		return true
 	}
 
 	// an output file to close
-	if action != 2 && SysClose(fyle.Fd) < 0 {
+	if action != 2 && e.SysClose(fyle.Fd) < 0 {
 		return false
 	}
 	if action == 1 {
 		// remove the file
 		if SysUnlink(fyle.Tnm) {
 			if msgs {
-				ScreenMessage(fmt.Sprintf("Output file %s deleted.", fyle.Tnm))
+				e.ScreenMessage(fmt.Sprintf("Output file %s deleted.", fyle.Tnm))
 			}
 			return true
 		} else {
@@ -179,7 +184,7 @@ func FilesysClose(fyle *FileObject, action int, msgs bool) bool {
 	// with the name we are going to use as the output name
 	fs := SysFileStatus(fyle.Filename)
 	if fs.Valid && fs.Mtime != fyle.PreviousFileId {
-		ScreenMessage(fmt.Sprintf("%s was modified by another process", fyle.Filename))
+		e.ScreenMessage(fmt.Sprintf("%s was modified by another process", fyle.Filename))
 	}
 
 	tname := fyle.Filename + "~"
@@ -226,7 +231,7 @@ func FilesysClose(fyle *FileObject, action int, msgs bool) bool {
 	// now rename the temp file to the real thing
 	SysChmod(fyle.Tnm, fyle.Mode&0777)
 	if !SysRename(fyle.Tnm, fyle.Filename) {
-		ScreenMessage(fmt.Sprintf("Cannot rename %s to %s", fyle.Tnm, fyle.Filename))
+		e.ScreenMessage(fmt.Sprintf("Cannot rename %s to %s", fyle.Tnm, fyle.Filename))
 		return false
 	} else {
 		if msgs {
@@ -234,7 +239,7 @@ func FilesysClose(fyle *FileObject, action int, msgs bool) bool {
 			if fyle.LCounter == 1 {
 				plural = ""
 			}
-			ScreenMessage(fmt.Sprintf("File %s created (%d line%s written).",
+			e.ScreenMessage(fmt.Sprintf("File %s created (%d line%s written).",
 				fyle.Filename, fyle.LCounter, plural))
 		}
 		// Time to set the memory, if it's required and we aren't writing in
@@ -248,16 +253,35 @@ func FilesysClose(fyle *FileObject, action int, msgs bool) bool {
 	}
 }
 
-// FilesysRead reads a line from a file
-// Attempts to read MAX_STRLEN characters into buffer
+// FilesysRead reads a line from a file into buffer, which grows to hold
+// it.  A line longer than MaxStrLen is split.
 // Number of characters read is returned in outlen
 func FilesysRead(fyle *FileObject, outputBuffer *StrObject, outlen *int) bool {
 	*outlen = 0
 	for {
-		if fyle.Idx >= fyle.Len {
-			fyle.Buf = make([]byte, MaxStrLen)
-			fyle.Len = int(SysRead(fyle.Fd, fyle.Buf))
+		if fyle.Idx >= fyle.Len || !utf8.FullRune(fyle.Buf[fyle.Idx:fyle.Len]) {
+			// Keep the start of a character split across two reads
+			buf := make([]byte, filesysBufSize+utf8.UTFMax)
+			partial := 0
+			if fyle.Idx < fyle.Len {
+				partial = copy(buf, fyle.Buf[fyle.Idx:fyle.Len])
+			}
+			var n int
+			if fyle.Reader != nil {
+				var err error
+				n, err = fyle.Reader.Read(buf[partial:])
+				if n == 0 && err != nil && err != io.EOF {
+					n = -1
+				}
+			} else {
+				n = int(SysRead(fyle.Fd, buf[partial:]))
+			}
+			fyle.Buf = buf
 			fyle.Idx = 0
+			fyle.Len = n
+			if partial > 0 {
+				fyle.Len = partial + max(n, 0)
+			}
 		}
 		if fyle.Len <= 0 {
 			fyle.Eof = true
@@ -268,16 +292,17 @@ func FilesysRead(fyle *FileObject, outputBuffer *StrObject, outlen *int) bool {
 			}
 			return false
 		}
-		ch := int(fyle.Buf[fyle.Idx]) & 0x7F // toascii
-		fyle.Idx++
-		if unicode.IsPrint(rune(ch)) {
+		ch, size := utf8.DecodeRune(fyle.Buf[fyle.Idx:fyle.Len])
+		if ch == utf8.RuneError && size == 1 {
+			// Not UTF-8, keep the byte so that it is written back unchanged
+			ch = ChFromRawByte(fyle.Buf[fyle.Idx])
+		}
+		fyle.Idx += size
+		if !unicode.IsControl(ch) {
 			*outlen++
-			outputBuffer.Set(*outlen, byte(ch))
+			outputBuffer.Set(*outlen, ch)
 		} else if ch == '\t' { // expand the tab
-			exp := 8 - (*outlen % 8)
-			if *outlen+exp > MaxStrLen {
-				exp = MaxStrLen - *outlen
-			}
+			exp := min(8-(*outlen%8), MaxStrLen-*outlen)
 			for ; exp > 0; exp-- {
 				*outlen++
 				outputBuffer.Set(*outlen, ' ')
@@ -286,7 +311,7 @@ func FilesysRead(fyle *FileObject, outputBuffer *StrObject, outlen *int) bool {
 			break // finished if newline or carriage return
 		} // forget other control characters
 		if *outlen >= MaxStrLen {
-			break
+			break // the rest goes on the next line
 		}
 	}
 	fyle.LCounter++
@@ -326,13 +351,14 @@ func FilesysWrite(fyle *FileObject, buffer *StrObject, bufsiz int) bool {
 				buffer.Set(offset+i, '\t')
 			}
 		}
-		count := SysWrite(fyle.Fd, []byte(buffer.Slice(offset+1, bufsiz-offset)))
+		text := ChAppendUTF8(nil, buffer, offset+1, bufsiz-offset)
+		count := SysWrite(fyle.Fd, text)
 		if tabs > 0 {
 			for i := 1; i <= tabs; i++ {
 				buffer.Set(offset+i, ' ')
 			}
 		}
-		if count != int64(bufsiz-offset) {
+		if count != int64(len(text)) {
 			return false
 		}
 	}
@@ -342,12 +368,12 @@ func FilesysWrite(fyle *FileObject, buffer *StrObject, bufsiz int) bool {
 }
 
 // FilesysSave implements part of the File Save command
-func FilesysSave(iFyle *FileObject, oFyle *FileObject, copyLines int) bool {
+func (e *Editor) FilesysSave(iFyle *FileObject, oFyle *FileObject, copyLines int) bool {
 	var fyle FileObject
 
 	var inputEof bool
 	var inputPosition int64
-	line := NewBlankStrObject(MaxStrLen)
+	line := EmptyStrObject()
 	var lineLen int
 
 	if iFyle != nil {
@@ -367,12 +393,12 @@ func FilesysSave(iFyle *FileObject, oFyle *FileObject, copyLines int) bool {
 		}
 
 		// close input file
-		FilesysClose(iFyle, 0, true)
+		e.FilesysClose(iFyle, 0, true)
 	}
 
 	// rename temporary file to output file
 	// process backup options, but do not close the file
-	FilesysClose(oFyle, 2, true)
+	e.FilesysClose(oFyle, 2, true)
 
 	// make the old output file the new input file
 	if iFyle == nil {
@@ -390,7 +416,7 @@ func FilesysSave(iFyle *FileObject, oFyle *FileObject, copyLines int) bool {
 
 	// open a new output file
 	oFyle.Create = false
-	if !FilesysCreateOpen(oFyle, nil, true) {
+	if !e.FilesysCreateOpen(oFyle, nil, true) {
 		return false
 	}
 
@@ -406,7 +432,7 @@ func FilesysSave(iFyle *FileObject, oFyle *FileObject, copyLines int) bool {
 
 	// reposition or close the input file
 	if iFyle == &fyle {
-		SysClose(iFyle.Fd)
+		e.SysClose(iFyle.Fd)
 	} else {
 		iFyle.Eof = inputEof
 		SysSeek(iFyle.Fd, inputPosition)
@@ -417,17 +443,30 @@ func FilesysSave(iFyle *FileObject, oFyle *FileObject, copyLines int) bool {
 }
 
 // FilesysParse parses command line arguments for file operations
-func FilesysParse(
+func (e *Editor) FilesysParse(
 	commandLine string,
 	parseType ParseType,
 	fileData *FileDataType,
 	input *FileObject,
 	output *FileObject,
+) bool {
+	return e.FilesysParseArgs(toArgv(commandLine), parseType, fileData, input, output)
+}
+
+// FilesysParseArgs parses command line arguments for file operations that
+// are already split into words, so that a file name may hold spaces
+func (e *Editor) FilesysParseArgs(
+	args []string,
+	parseType ParseType,
+	fileData *FileDataType,
+	input *FileObject,
+	output *FileObject,
 ) bool {
 	const usage = "usage : ludwig [-c] [-r] [-i value] [-I] " +
 		"[-s value] [-m file] [-M] [-t] [-T] " +
 		"[-b value] [-B value] [-o] [-O] [-u] " +
-		"[file [file]]"
+		"[[+line] file[:line[:col]] ...]\n" +
+		"        ludwig [options] -e commands | -f script ... [file ...]"
 	const fileUsage = "usage : [-m file] [-t] [-T] [-b value] " +
 		"[-B value] [file [file]]"
 
@@ -439,10 +478,7 @@ func FilesysParse(
 		return true
 	}
 
-	// create an argc and argv from the "command_line"
-	argv := []string{"Ludwig"}
-	cl := toArgv(commandLine)
-	argv = append(argv, cl...)
+	argv := append([]string{"Ludwig"}, args...)
 
 	entab := fileData.Entab
 	space := fileData.Space
@@ -556,9 +592,9 @@ func FilesysParse(
 
 	if usageFlag || errors > 0 {
 		if parseType == ParseCommand {
-			ScreenMessage(usage)
+			e.ScreenMessage(usage)
 		} else {
-			ScreenMessage(fileUsage)
+			e.ScreenMessage(fileUsage)
 		}
 		return false
 	}
@@ -576,7 +612,7 @@ func FilesysParse(
 	var file []string
 	for filesCount := 0; optind < len(argv); filesCount++ {
 		if filesCount >= 2 {
-			ScreenMessage("More than two files specified")
+			e.ScreenMessage("More than two files specified")
 			return false
 		}
 		file = append(file, argv[optind])
@@ -587,7 +623,7 @@ func FilesysParse(
 		checkInput = true
 		if parseType == ParseInput || parseType == ParseOutput ||
 			parseType == ParseExecute || createFlag || readOnlyFlag {
-			ScreenMessage("Only one file name can be specified")
+			e.ScreenMessage("Only one file name can be specified")
 			return false
 		}
 	}
@@ -604,7 +640,7 @@ func FilesysParse(
 					input.Filename = ""
 					return true
 				}
-				ScreenMessage(fmt.Sprintf("Error opening memory file (%s)", memory))
+				e.ScreenMessage(fmt.Sprintf("Error opening memory file (%s)", memory))
 				return false
 			}
 		} else if parseType == ParseCommand {
@@ -627,27 +663,27 @@ func FilesysParse(
 
 		if readOnlyFlag {
 			input.Create = false
-			if !FilesysCreateOpen(input, nil, true) {
-				ScreenMessage(fmt.Sprintf("Error opening (%s) as input", input.Filename))
+			if !e.FilesysCreateOpen(input, nil, true) {
+				e.ScreenMessage(fmt.Sprintf("Error opening (%s) as input", input.Filename))
 				return false
 			}
 			input.Valid = true
 		} else if createFlag {
 			output.Create = true
-			if !FilesysCreateOpen(output, nil, true) {
+			if !e.FilesysCreateOpen(output, nil, true) {
 				return false
 			}
 			output.Valid = true
 		} else {
 			input.Create = false
 			output.Create = false
-			if FilesysCreateOpen(input, nil, true) {
+			if e.FilesysCreateOpen(input, nil, true) {
 				input.Valid = true
 			} else if checkInput || parseType == ParseEdit {
-				ScreenMessage(fmt.Sprintf("Error opening (%s) as input", input.Filename))
+				e.ScreenMessage(fmt.Sprintf("Error opening (%s) as input", input.Filename))
 				return false
 			}
-			if FilesysCreateOpen(output, input, true) {
+			if e.FilesysCreateOpen(output, input, true) {
 				output.Valid = true
 			} else {
 				return false
@@ -662,8 +698,8 @@ func FilesysParse(
 			return false
 		}
 		input.Create = false
-		if input.Filename == "" || !FilesysCreateOpen(input, nil, true) {
-			ScreenMessage(fmt.Sprintf("Error opening (%s) as input", input.Filename))
+		if input.Filename == "" || !e.FilesysCreateOpen(input, nil, true) {
+			e.ScreenMessage(fmt.Sprintf("Error opening (%s) as input", input.Filename))
 			return false
 		}
 		input.Valid = true
@@ -675,7 +711,7 @@ func FilesysParse(
 			input.Filename = ""
 		}
 		input.Create = false
-		if input.Filename == "" || !FilesysCreateOpen(input, nil, true) {
+		if input.Filename == "" || !e.FilesysCreateOpen(input, nil, true) {
 			return false
 		}
 		input.Valid = true
@@ -693,7 +729,7 @@ func FilesysParse(
 		output.Purge = purge
 		output.Versions = versions
 		output.Create = false
-		if output.Filename == "" || !FilesysCreateOpen(output, input, true) {
+		if output.Filename == "" || !e.FilesysCreateOpen(output, input, true) {
 			return false
 		}
 		output.Valid = true
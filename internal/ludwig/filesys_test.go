@@ -3,6 +3,7 @@ package ludwig
 import (
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -84,3 +85,86 @@ func TestFilesysWriteEntab(t *testing.T) {
 		})
 	}
 }
+
+func TestFilesysReadWriteUTF8(t *testing.T) {
+	// The last line ends part way through a character
+	input := "café\tx\n日本語\nbad \xff\xfe byte\n\xe6\x97"
+	f, err := os.CreateTemp("", "filesys-test-*")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	_, err = f.WriteString(input)
+	require.NoError(t, err)
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+
+	fyle := &FileObject{Fd: int(f.Fd())}
+	var lines []*StrObject
+	var lens []int
+	for {
+		buf := NewBlankStrObject(testStrLen)
+		var outlen int
+		if !FilesysRead(fyle, buf, &outlen) {
+			break
+		}
+		lines = append(lines, buf)
+		lens = append(lens, outlen)
+	}
+	require.Len(t, lines, 4)
+	assert.Equal(t, "café    x", lines[0].Slice(1, lens[0]))
+	assert.Equal(t, 3, lens[1])
+	assert.Equal(t, "日本語", lines[1].Slice(1, lens[1]))
+	assert.Equal(t, 11, lens[2])
+	assert.Equal(t, ChFromRawByte(0xff), lines[2].Get(5))
+	assert.Equal(t, 2, lens[3])
+
+	out, err := os.CreateTemp("", "filesys-test-*")
+	require.NoError(t, err)
+	defer os.Remove(out.Name())
+	defer out.Close()
+	fyle = &FileObject{Fd: int(out.Fd())}
+	for i, line := range lines {
+		require.True(t, FilesysWrite(fyle, line, lens[i]))
+	}
+	_, err = out.Seek(0, 0)
+	require.NoError(t, err)
+	data, err := io.ReadAll(out)
+	require.NoError(t, err)
+	assert.Equal(t, "café    x\n日本語\nbad \xff\xfe byte\n\xe6\x97\n", string(data))
+}
+
+func TestFilesysReadLongLine(t *testing.T) {
+	// Longer than the read buffer, with a character split between reads
+	long := strings.Repeat("x", filesysBufSize-1) + "é" + strings.Repeat("y", 10000)
+	f, err := os.CreateTemp("", "filesys-test-*")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	_, err = f.WriteString(long + "\nshort\n")
+	require.NoError(t, err)
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+
+	fyle := &FileObject{Fd: int(f.Fd())}
+	buf := EmptyStrObject()
+	var outlen int
+	require.True(t, FilesysRead(fyle, buf, &outlen))
+	assert.Equal(t, filesysBufSize+10000, outlen)
+	assert.Equal(t, long, buf.Slice(1, outlen))
+
+	require.True(t, FilesysRead(fyle, buf, &outlen))
+	assert.Equal(t, "short", buf.Slice(1, outlen))
+	assert.False(t, FilesysRead(fyle, buf, &outlen))
+}
+
+func TestFilesysReadSplitsLine(t *testing.T) {
+	// A line longer than MaxStrLen goes on as the next line
+	fyle := &FileObject{Reader: strings.NewReader(strings.Repeat("x", MaxStrLen) + "yz\n")}
+	buf := EmptyStrObject()
+	var outlen int
+	require.True(t, FilesysRead(fyle, buf, &outlen))
+	assert.Equal(t, MaxStrLen, outlen)
+	require.True(t, FilesysRead(fyle, buf, &outlen))
+	assert.Equal(t, "yz", buf.Slice(1, outlen))
+	assert.False(t, FilesysRead(fyle, buf, &outlen))
+}
@@ -21,15 +21,20 @@ import (
 const (
 	endOfFile = "<End of File>   "
 	newValues = "  New Values: "
+
+	// The names of the frames every editor has
+	frameNameCmd  = "COMMAND"
+	frameNameOops = "OOPS"
+	frameNameHeap = "HEAP"
 )
 
-func isNPunct(ch byte) bool {
-	return !ChIsPunctuation(rune(ch))
+func isNPunct(ch rune) bool {
+	return !ChIsPunctuation(ch)
 }
 
 // FrameEdit creates or edits a frame with the specified name.
 // This is the \ED command. If frame_name doesn't exist, then it is created.
-func FrameEdit(frameName string) bool {
+func (e *Editor) FrameEdit(frameName string) bool {
 	const (
 		spnCreated  = 0x0001
 		frmCreated  = 0x0002
@@ -48,15 +53,15 @@ func FrameEdit(frameName string) bool {
 	var ptr *SpanObject
 	var oldp *SpanObject
 
-	if SpanFind(fname, &ptr, &oldp) {
+	if e.SpanFind(fname, &ptr, &oldp) {
 		if ptr.Frame != nil {
-			if ptr.Frame != CurrentFrame {
-				ptr.Frame.ReturnFrame = CurrentFrame
-				CurrentFrame = ptr.Frame
+			if ptr.Frame != e.CurrentFrame {
+				ptr.Frame.ReturnFrame = e.CurrentFrame
+				e.CurrentFrame = ptr.Frame
 			}
 			return true
 		}
-		ScreenMessage(MsgSpanOfThatNameExists)
+		e.ScreenMessage(MsgSpanOfThatNameExists)
 		return false
 	}
 
@@ -73,7 +78,7 @@ func FrameEdit(frameName string) bool {
 		sptr.BLink = oldp
 		sptr.FLink = ptr
 		if oldp == nil {
-			FirstSpan = sptr
+			e.FirstSpan = sptr
 		} else {
 			oldp.FLink = sptr
 		}
@@ -91,7 +96,7 @@ func FrameEdit(frameName string) bool {
 			if MarkCreate(gptr.LastLine, 1, &sptr.MarkTwo) {
 				created |= mrk2Created
 				fptr.Dot = nil
-				if MarkCreate(gptr.FirstLine, InitialMarginLeft, &fptr.Dot) {
+				if MarkCreate(gptr.FirstLine, e.InitialMarginLeft, &fptr.Dot) {
 					created |= dotCreated
 				}
 			}
@@ -101,23 +106,23 @@ func FrameEdit(frameName string) bool {
 			// Initialize frame object
 			fptr.FirstGroup = gptr
 			fptr.LastGroup = gptr
-			fptr.Marks = InitialMarks
-			fptr.ScrHeight = InitialScrHeight
-			fptr.ScrWidth = InitialScrWidth
-			fptr.ScrOffset = InitialScrOffset
+			fptr.Marks = e.InitialMarks
+			fptr.ScrHeight = e.InitialScrHeight
+			fptr.ScrWidth = e.InitialScrWidth
+			fptr.ScrOffset = e.InitialScrOffset
 			fptr.ScrDotLine = 1
 			fptr.Span = sptr
-			fptr.ReturnFrame = CurrentFrame
+			fptr.ReturnFrame = e.CurrentFrame
 			fptr.InputCount = 0
-			fptr.SpaceLimit = FileData.Space
-			fptr.SpaceLeft = FileData.Space
+			fptr.SpaceLimit = e.FileData.Space
+			fptr.SpaceLeft = e.FileData.Space
 			fptr.TextModified = false
-			fptr.MarginLeft = InitialMarginLeft
-			fptr.MarginRight = InitialMarginRight
-			fptr.MarginTop = InitialMarginTop
-			fptr.MarginBottom = InitialMarginBottom
-			fptr.TabStops = InitialTabStops
-			fptr.Options = InitialOptions
+			fptr.MarginLeft = e.InitialMarginLeft
+			fptr.MarginRight = e.InitialMarginRight
+			fptr.MarginTop = e.InitialMarginTop
+			fptr.MarginBottom = e.InitialMarginBottom
+			fptr.TabStops = e.InitialTabStops.Clone()
+			fptr.Options = e.InitialOptions
 			fptr.InputFile = 0
 			fptr.OutputFile = 0
 			fptr.GetTpar = TParObject{}
@@ -133,11 +138,11 @@ func FrameEdit(frameName string) bool {
 				// Copy end-of-file message and frame name
 				if gptr.LastLine.Str != nil {
 					lineLen := gptr.LastLine.Len()
-					gptr.LastLine.Str.FillCopyBytes([]byte(endOfFile), 1, lineLen, ' ')
+					gptr.LastLine.Str.FillCopyRunes([]rune(endOfFile), 1, lineLen, ' ')
 					eofLen := len(endOfFile) + 1
-					gptr.LastLine.Str.FillCopyBytes([]byte(fname), eofLen, lineLen-eofLen, ' ')
+					gptr.LastLine.Str.FillCopyRunes([]rune(fname), eofLen, lineLen-eofLen, ' ')
 					gptr.LastLine.Used = 0 // Special feature of the NULL line!
-					CurrentFrame = fptr
+					e.CurrentFrame = fptr
 					return true
 				}
 			}
@@ -158,36 +163,141 @@ func FrameEdit(frameName string) bool {
 	return false
 }
 
+// FrameCreateSpecial creates the frames OOPS, COMMAND and HEAP that every
+// editor has, and saves pointers to them for use in later frame routines.
+// The current frame is left unset.
+func (e *Editor) FrameCreateSpecial() bool {
+	if !e.FrameEdit(frameNameOops) {
+		return false
+	}
+	if !e.FrameSetHeight(e.InitialScrHeight, true) {
+		return false
+	}
+	e.FrameOops = e.CurrentFrame
+	e.CurrentFrame = nil
+	e.FrameOops.SpaceLimit = MaxSpace     // Big !
+	e.FrameOops.SpaceLeft = MaxSpace - 50 // Big ! - space for <eop> line !!
+	e.FrameOops.Options.Set(OptSpecialFrame)
+	if !e.FrameEdit(frameNameCmd) {
+		return false
+	}
+	e.FrameCmd = e.CurrentFrame
+	e.CurrentFrame = nil
+	e.FrameCmd.Options.Set(OptSpecialFrame)
+	if !e.FrameEdit(frameNameHeap) {
+		return false
+	}
+	e.FrameHeap = e.CurrentFrame
+	e.CurrentFrame = nil
+	e.FrameHeap.Options.Set(OptSpecialFrame)
+	return true
+}
+
+// FrameCreateExtra creates a special frame that is only made when it is
+// first needed, such as frame FRAMES, and saves a pointer to it.  The
+// current frame is left alone.
+func (e *Editor) FrameCreateExtra(frameName string, frame **FrameObject) bool {
+	if *frame != nil {
+		return true
+	}
+	var span, oldp *SpanObject
+	if e.SpanFind(frameName, &span, &oldp) {
+		e.ScreenMessage(MsgSpanOfThatNameExists)
+		return false
+	}
+	current := e.CurrentFrame
+	if !e.FrameEdit(frameName) {
+		return false
+	}
+	*frame = e.CurrentFrame
+	e.CurrentFrame = current
+	(*frame).Options.Set(OptSpecialFrame)
+	return true
+}
+
+// FrameReplaceText throws away the text of a frame and puts text in its
+// place, leaving the frame unmodified and Dot on the first line
+func (e *Editor) FrameReplaceText(frame *FrameObject, text string) bool {
+	firstLine := frame.FirstGroup.FirstLine
+	lastLine := frame.LastGroup.LastLine.BLink
+	if lastLine != nil {
+		if !MarksSqueeze(firstLine, 1, lastLine.FLink, 1) {
+			return false
+		}
+		if !e.LinesExtract(firstLine, lastLine) {
+			return false
+		}
+		if !LinesDestroy(&firstLine, &lastLine) {
+			return false
+		}
+	}
+	if !e.FileLoad(frame, strings.NewReader(text)) {
+		return false
+	}
+	frame.InputCount = 0
+	frame.TextModified = false
+	return true
+}
+
+// FramePositionDot puts Dot of a frame at a line and column, or at the end
+// of the frame if it is shorter.  The frame's file is read as far as the
+// line first.
+func (e *Editor) FramePositionDot(frame *FrameObject, lineNr int, col int) bool {
+	var line *LineHdrObject
+	if !e.FileReadLines(frame.FirstGroup.FirstLine, lineNr-1) ||
+		!LineFromNumber(frame, max(lineNr, 1), &line) {
+		return false
+	}
+	if line == nil {
+		line = frame.LastGroup.LastLine
+	}
+	return MarkCreate(line, min(max(col, 1), MaxStrLen), &frame.Dot)
+}
+
+// FrameFileName returns the name of the file a frame is written to, or if
+// there is none the file it is read from
+func (e *Editor) FrameFileName(frame *FrameObject) string {
+	fileSlot := frame.OutputFile
+	if fileSlot == 0 {
+		fileSlot = frame.InputFile
+	}
+	if fileSlot == 0 || e.Files[fileSlot] == nil {
+		return ""
+	}
+	return e.Files[fileSlot].Filename
+}
+
 // FrameKill destroys the specified frame.
 // You can't kill frame C or OOPS or the current frame.
-func FrameKill(frameName string) bool {
+func (e *Editor) FrameKill(frameName string) bool {
 	var oldp *SpanObject
 	var sptr *SpanObject
 
-	if !SpanFind(frameName, &sptr, &oldp) {
-		ScreenMessage(MsgNoSuchFrame)
+	if !e.SpanFind(frameName, &sptr, &oldp) {
+		e.ScreenMessage(MsgNoSuchFrame)
 		return false
 	}
 	if sptr.Frame == nil {
-		ScreenMessage(MsgNoSuchFrame)
+		e.ScreenMessage(MsgNoSuchFrame)
 		return false
 	}
 
 	thisFrame := sptr.Frame
-	if thisFrame == CurrentFrame || thisFrame == ScrFrame ||
-		thisFrame.Options.Has(OptSpecialFrame) {
-		ScreenMessage(MsgCantKillFrame)
+	if thisFrame == e.CurrentFrame || thisFrame == e.ScrFrame ||
+		thisFrame.Options.Has(OptSpecialFrame) || e.WindowShows(thisFrame) {
+		e.ScreenMessage(MsgCantKillFrame)
 		return false
 	}
 
 	if thisFrame.InputFile != 0 || thisFrame.OutputFile != 0 {
-		ScreenMessage(MsgFrameHasFilesAttached)
+		e.ScreenMessage(MsgFrameHasFilesAttached)
 		return false
 	}
 
 	// We are now free to destroy this frame
+	JournalRemove(thisFrame)
 	// Step 1: remove all ERs back to this frame and all spans into this frame
-	oldp = FirstSpan
+	oldp = e.FirstSpan
 	for oldp != nil {
 		sptr = oldp.FLink
 		if oldp.Frame != nil {
@@ -195,7 +305,7 @@ func FrameKill(frameName string) bool {
 				oldp.Frame.ReturnFrame = nil
 			}
 		} else if oldp.MarkOne.Line.Group.Frame == thisFrame {
-			if !SpanDestroy(&oldp) {
+			if !e.SpanDestroy(&oldp) {
 				return false
 			}
 		}
@@ -205,7 +315,7 @@ func FrameKill(frameName string) bool {
 	// Step 2: Destroy the Span
 	thisFrame.Span.Frame = nil
 	spanPtr := thisFrame.Span
-	if !SpanDestroy(&spanPtr) {
+	if !e.SpanDestroy(&spanPtr) {
 		return false
 	}
 
@@ -224,7 +334,7 @@ func FrameKill(frameName string) bool {
 	ptr2 := thisFrame.LastGroup.LastLine.BLink
 	if ptr2 != nil {
 		ptr1 := thisFrame.FirstGroup.FirstLine
-		if !LinesExtract(ptr1, ptr2) {
+		if !e.LinesExtract(ptr1, ptr2) {
 			return false
 		}
 		if !LinesDestroy(&ptr1, &ptr2) {
@@ -252,11 +362,11 @@ func FrameKill(frameName string) bool {
 }
 
 // nextchar gets the next non-space character from a tpar
-func nextchar(request *TParObject, pos *int) byte {
+func nextchar(request *TParObject, pos *int) rune {
 	for (*pos < request.Len) && (request.Str.Get(*pos) == ' ') {
 		*pos++
 	}
-	var ch byte
+	var ch rune
 	if (*pos > request.Len) || (request.Str.Get(*pos) == ' ') {
 		ch = 0
 	} else {
@@ -268,105 +378,126 @@ func nextchar(request *TParObject, pos *int) byte {
 	return ch
 }
 
-// setmemory sets the memory allocation for the current frame
-func setmemory(sz int, setInitial bool) bool {
-	if sz >= MaxSpace {
-		sz = MaxSpace
+// writeSpace writes an amount of memory, or that there is no limit if the
+// memory allowed is MaxSpace
+func (e *Editor) writeSpace(space int, limit int) {
+	if limit == MaxSpace {
+		e.ScreenWriteStr(0, "unlimited")
+	} else {
+		e.ScreenWriteInt(space, 9)
 	}
+}
+
+// setmemory sets the memory allocation for the current frame
+func (e *Editor) setmemory(sz int, setInitial bool) bool {
 	if setInitial {
-		FileData.Space = sz
+		e.FileData.Space = sz
 	}
 
-	usedStorage := CurrentFrame.SpaceLimit - CurrentFrame.SpaceLeft
+	usedStorage := e.CurrentFrame.SpaceLimit - e.CurrentFrame.SpaceLeft
 	minSize := usedStorage + 800
-	if minSize > CurrentFrame.SpaceLimit {
-		minSize = CurrentFrame.SpaceLimit
+	if minSize > e.CurrentFrame.SpaceLimit {
+		minSize = e.CurrentFrame.SpaceLimit
 	}
 	if sz < minSize {
 		sz = minSize
 	}
-	CurrentFrame.SpaceLimit = sz
-	CurrentFrame.SpaceLeft = sz - usedStorage
+	e.CurrentFrame.SpaceLimit = sz
+	e.CurrentFrame.SpaceLeft = sz - usedStorage
 	return true
 }
 
 // FrameSetHeight sets the screen height for the current frame
-func FrameSetHeight(sh int, setInitial bool) bool {
-	if sh >= 1 && sh <= TerminalInfo.Height {
+func (e *Editor) FrameSetHeight(sh int, setInitial bool) bool {
+	if sh >= 1 && sh <= e.TerminalInfo.Height {
 		if setInitial {
-			InitialScrHeight = sh
+			e.InitialScrHeight = sh
 		}
-		CurrentFrame.ScrHeight = sh
+		e.CurrentFrame.ScrHeight = sh
 		band := sh / 6
 		if setInitial {
-			InitialMarginTop = band
+			e.InitialMarginTop = band
 		}
-		CurrentFrame.MarginTop = band
+		e.CurrentFrame.MarginTop = band
 		if setInitial {
-			InitialMarginBottom = band
+			e.InitialMarginBottom = band
 		}
-		CurrentFrame.MarginBottom = band
+		e.CurrentFrame.MarginBottom = band
 		return true
 	}
-	ScreenMessage(MsgInvalidScreenHeight)
+	e.ScreenMessage(MsgInvalidScreenHeight)
 	return false
 }
 
 // setwidth sets the screen width for the current frame
-func setwidth(wid int, setInitial bool) bool {
-	if wid >= 10 && wid <= TerminalInfo.Width {
+func (e *Editor) setwidth(wid int, setInitial bool) bool {
+	if wid >= 10 && wid <= e.TerminalInfo.Width {
 		if setInitial {
-			InitialScrWidth = wid
+			e.InitialScrWidth = wid
 		}
-		CurrentFrame.ScrWidth = wid
+		e.CurrentFrame.ScrWidth = wid
 		return true
 	}
-	ScreenMessage(MsgScreenWidthInvalid)
+	e.ScreenMessage(MsgScreenWidthInvalid)
 	return false
 }
 
 // showOptions displays the current frame options
-func showOptions() {
-	ScreenUnload()
-	ScreenHome(true)
-	ScreenWriteStr(0, "    Ludwig Option         Code    State")
-	ScreenWriteln()
-	ScreenWriteStr(0, "    --------------------  ----    -----")
-	ScreenWriteln()
-	ScreenWriteln()
-	ScreenWriteStr(4, "Show current options  S")
-	ScreenWriteln()
-	ScreenWriteStr(4, "Auto-indenting        I       ")
-	if CurrentFrame.Options.Has(OptAutoIndent) {
-		ScreenWriteStr(0, "On")
+func (e *Editor) showOptions() {
+	e.ScreenUnload()
+	e.ScreenHome(true)
+	e.ScreenWriteStr(0, "    Ludwig Option         Code    State")
+	e.ScreenWriteln()
+	e.ScreenWriteStr(0, "    --------------------  ----    -----")
+	e.ScreenWriteln()
+	e.ScreenWriteln()
+	e.ScreenWriteStr(4, "Show current options  S")
+	e.ScreenWriteln()
+	e.ScreenWriteStr(4, "Auto-indenting        I       ")
+	if e.CurrentFrame.Options.Has(OptAutoIndent) {
+		e.ScreenWriteStr(0, "On")
+	} else {
+		e.ScreenWriteStr(0, "Off")
+	}
+	e.ScreenWriteln()
+	e.ScreenWriteStr(4, "New Line              N       ")
+	if e.CurrentFrame.Options.Has(OptNewLine) {
+		e.ScreenWriteStr(0, "On")
 	} else {
-		ScreenWriteStr(0, "Off")
+		e.ScreenWriteStr(0, "Off")
 	}
-	ScreenWriteln()
-	ScreenWriteStr(4, "New Line              N       ")
-	if CurrentFrame.Options.Has(OptNewLine) {
-		ScreenWriteStr(0, "On")
+	e.ScreenWriteln()
+	e.ScreenWriteStr(4, "Wrap at Right Margin  W       ")
+	if e.CurrentFrame.Options.Has(OptAutoWrap) {
+		e.ScreenWriteStr(0, "On")
 	} else {
-		ScreenWriteStr(0, "Off")
+		e.ScreenWriteStr(0, "Off")
 	}
-	ScreenWriteln()
-	ScreenWriteStr(4, "Wrap at Right Margin  W       ")
-	if CurrentFrame.Options.Has(OptAutoWrap) {
-		ScreenWriteStr(0, "On")
+	e.ScreenWriteln()
+	e.ScreenWriteStr(4, "Syntax Highlighting   H       ")
+	if e.CurrentFrame.Options.Has(OptHighlight) {
+		e.ScreenWriteStr(0, "On")
 	} else {
-		ScreenWriteStr(0, "Off")
+		e.ScreenWriteStr(0, "Off")
 	}
-	ScreenWriteln()
-	ScreenWriteln()
-	ScreenPause()
-	ScreenHome(true) // wipe out the display
+	e.ScreenWriteln()
+	e.ScreenWriteStr(4, "Match Highlighting    M       ")
+	if e.CurrentFrame.Options.Has(OptMatches) {
+		e.ScreenWriteStr(0, "On")
+	} else {
+		e.ScreenWriteStr(0, "Off")
+	}
+	e.ScreenWriteln()
+	e.ScreenWriteln()
+	e.ScreenPause()
+	e.ScreenHome(true) // wipe out the display
 }
 
 // setOpt sets a single option
-func setOpt(ch byte, seton bool, options *FrameOptions) bool {
+func (e *Editor) setOpt(ch rune, seton bool, options *FrameOptions) bool {
 	switch ch {
 	case 'S':
-		showOptions()
+		e.showOptions()
 	case 'I':
 		if seton {
 			options.Set(OptAutoIndent)
@@ -385,15 +516,27 @@ func setOpt(ch byte, seton bool, options *FrameOptions) bool {
 		} else {
 			options.Clear(OptNewLine)
 		}
+	case 'H':
+		if seton {
+			options.Set(OptHighlight)
+		} else {
+			options.Clear(OptHighlight)
+		}
+	case 'M':
+		if seton {
+			options.Set(OptMatches)
+		} else {
+			options.Clear(OptMatches)
+		}
 	default:
-		ScreenMessage(MsgUnknownOption)
+		e.ScreenMessage(MsgUnknownOption)
 		return false
 	}
 	return true
 }
 
 // setOptions sets frame options
-func setOptions(request *TParObject, pos *int, setInitial bool) bool {
+func (e *Editor) setOptions(request *TParObject, pos *int, setInitial bool) bool {
 	ok := false
 	ch := nextchar(request, pos)
 	if ch == '(' {
@@ -405,12 +548,12 @@ func setOptions(request *TParObject, pos *int, setInitial bool) bool {
 				ch = nextchar(request, pos)
 			}
 			if setInitial {
-				setOpt(ch, seton, &InitialOptions)
+				e.setOpt(ch, seton, &e.InitialOptions)
 			}
-			ok = setOpt(ch, seton, &CurrentFrame.Options)
+			ok = e.setOpt(ch, seton, &e.CurrentFrame.Options)
 			ch = nextchar(request, pos)
 			if ch != ',' && ch != ')' {
-				ScreenMessage(MsgSyntaxErrorInOptions)
+				e.ScreenMessage(MsgSyntaxErrorInOptions)
 				return false
 			}
 			if !ok || ch == ')' {
@@ -425,65 +568,65 @@ func setOptions(request *TParObject, pos *int, setInitial bool) bool {
 			ch = nextchar(request, pos)
 		}
 		if setInitial {
-			setOpt(ch, seton, &InitialOptions)
+			e.setOpt(ch, seton, &e.InitialOptions)
 		}
-		ok = setOpt(ch, seton, &CurrentFrame.Options)
+		ok = e.setOpt(ch, seton, &e.CurrentFrame.Options)
 	}
 	return ok
 }
 
 // setcmdintr sets the command introducer key
-func setcmdintr(request *TParObject, pos *int) bool {
-	if LudwigMode == LudwigScreen {
+func (e *Editor) setcmdintr(request *TParObject, pos *int) bool {
+	if e.LudwigMode == LudwigScreen {
 		var keyName strings.Builder
 		terminate := false
 		for *pos <= request.Len && !terminate {
 			if request.Str.Get(*pos) == ',' {
 				terminate = true
 			} else {
-				keyName.WriteByte(request.Str.Get(*pos))
+				keyName.WriteRune(request.Str.Get(*pos))
 				*pos++
 			}
 		}
 		keyNameStr := keyName.String()
 
 		var keyCode int
-		if len(keyNameStr) == 1 {
-			if ChIsPunctuation(rune(keyNameStr[0])) {
-				CommandIntroducer = int(keyNameStr[0])
-				VduNewIntroducer(CommandIntroducer)
+		if keyChars := []rune(keyNameStr); len(keyChars) == 1 {
+			if keyChars[0] <= MaxSetRange && ChIsPunctuation(keyChars[0]) {
+				e.CommandIntroducer = int(keyChars[0])
+				e.VduNewIntroducer(e.CommandIntroducer)
 				return true
 			}
-			ScreenMessage(MsgInvalidCmdIntroducer)
-		} else if UserKeyNameToCode(keyNameStr, &keyCode) {
-			if KeyIntroducers[keyCode] {
-				ScreenMessage(MsgInvalidCmdIntroducer)
+			e.ScreenMessage(MsgInvalidCmdIntroducer)
+		} else if e.UserKeyNameToCode(keyNameStr, &keyCode) {
+			if e.KeyIntroducers[keyCode] {
+				e.ScreenMessage(MsgInvalidCmdIntroducer)
 			} else {
-				CommandIntroducer = keyCode
-				VduNewIntroducer(CommandIntroducer)
+				e.CommandIntroducer = keyCode
+				e.VduNewIntroducer(e.CommandIntroducer)
 				return true
 			}
 		} else {
-			ScreenMessage(MsgUnrecognizedKeyName)
+			e.ScreenMessage(MsgUnrecognizedKeyName)
 		}
 	} else {
-		ScreenMessage(MsgScreenModeOnly)
+		e.ScreenMessage(MsgScreenModeOnly)
 	}
 	return false
 }
 
 // setMode sets the editing mode
-func setMode(request *TParObject, pos *int) bool {
+func (e *Editor) setMode(request *TParObject, pos *int) bool {
 	ch := nextchar(request, pos)
 	switch ch {
 	case 'I':
-		EditMode = ModeInsert
+		e.EditMode = ModeInsert
 	case 'O':
-		EditMode = ModeOvertype
+		e.EditMode = ModeOvertype
 	case 'C':
-		EditMode = ModeCommand
+		e.EditMode = ModeCommand
 	default:
-		ScreenMessage(MsgModeError)
+		e.ScreenMessage(MsgModeError)
 		// FIXME: Original always returns true, but this should probably fail.
 		// return false
 	}
@@ -491,37 +634,29 @@ func setMode(request *TParObject, pos *int) bool {
 }
 
 // setTabs sets tab stops for the current frame
-func setTabs(request *TParObject, pos *int, setInitial bool) bool {
+func (e *Editor) setTabs(request *TParObject, pos *int, setInitial bool) bool {
 	ch := nextchar(request, pos)
 	switch ch {
 	case 'D': // default tabs
 		if setInitial {
-			InitialTabStops = DefaultTabStops
+			e.InitialTabStops = DefaultTabStops.Clone()
 		}
-		CurrentFrame.TabStops = DefaultTabStops
+		e.CurrentFrame.TabStops = DefaultTabStops.Clone()
 
 	case 'T': // template match
-		if CurrentFrame.Dot.Line.Used > 0 {
-			ts := CurrentFrame.Dot.Line.Str.Get(1) != ' '
-			if setInitial {
-				InitialTabStops[1] = ts
-			}
-			CurrentFrame.TabStops[1] = ts
+		var temptab TabArray
+		if e.CurrentFrame.Dot.Line.Used > 0 {
+			temptab.Set(1, e.CurrentFrame.Dot.Line.Str.Get(1) != ' ')
 		}
-		for i := 2; i <= CurrentFrame.Dot.Line.Used; i++ {
-			chi := CurrentFrame.Dot.Line.Str.Get(i)
-			chim1 := CurrentFrame.Dot.Line.Str.Get(i - 1)
-			if setInitial {
-				InitialTabStops[i] = (chi != ' ') && (chim1 == ' ')
-			}
-			CurrentFrame.TabStops[i] = (chi != ' ') && (chim1 == ' ')
+		for i := 2; i <= e.CurrentFrame.Dot.Line.Used; i++ {
+			chi := e.CurrentFrame.Dot.Line.Str.Get(i)
+			chim1 := e.CurrentFrame.Dot.Line.Str.Get(i - 1)
+			temptab.Set(i, (chi != ' ') && (chim1 == ' '))
 		}
-		for i := CurrentFrame.Dot.Line.Used; i <= MaxStrLen; i++ {
-			if setInitial {
-				InitialTabStops[i] = false
-			}
-			CurrentFrame.TabStops[i] = false
+		if setInitial {
+			e.InitialTabStops = temptab.Clone()
 		}
+		e.CurrentFrame.TabStops = temptab
 
 	case 'I': // insert tabs
 		var firstLine *LineHdrObject
@@ -529,36 +664,37 @@ func setTabs(request *TParObject, pos *int, setInitial bool) bool {
 		if !LinesCreate(1, &firstLine, &lastLine) {
 			return false
 		}
-		if !LineChangeLength(firstLine, MaxStrLen) {
+		tabs := &e.CurrentFrame.TabStops
+		marginLeft := e.CurrentFrame.MarginLeft
+		marginRight := e.CurrentFrame.MarginRight
+		if setInitial {
+			tabs = &e.InitialTabStops
+			marginLeft = e.InitialMarginLeft
+			marginRight = e.InitialMarginRight
+		}
+		// The ruler runs to the last tab stop set one by one, or the right
+		// margin, whichever is further.
+		rulerLen := max(tabs.Last(), marginLeft, marginRight)
+		if !LineChangeLength(firstLine, rulerLen) {
 			return false
 		}
-		if setInitial {
-			for i := 1; i <= MaxStrLen; i++ {
-				if InitialTabStops[i] {
-					firstLine.Str.Set(i, 'T')
-				}
-			}
-			firstLine.Str.Set(InitialMarginLeft, 'L')
-			firstLine.Str.Set(InitialMarginRight, 'R')
-		} else {
-			for i := 1; i < MaxStrLen; i++ {
-				if CurrentFrame.TabStops[i] {
-					firstLine.Str.Set(i, 'T')
-				}
+		for i := 1; i <= rulerLen; i++ {
+			if tabs.IsStop(i) {
+				firstLine.Str.Set(i, 'T')
 			}
-			firstLine.Str.Set(CurrentFrame.MarginLeft, 'L')
-			firstLine.Str.Set(CurrentFrame.MarginRight, 'R')
 		}
+		firstLine.Str.Set(marginLeft, 'L')
+		firstLine.Str.Set(marginRight, 'R')
 		// Calculate used length
-		firstLine.Used = firstLine.Str.Length(' ', MaxStrLen)
-		if !LinesInject(firstLine, lastLine, CurrentFrame.Dot.Line) {
+		firstLine.Used = firstLine.Str.Length(' ', rulerLen)
+		if !e.LinesInject(firstLine, lastLine, e.CurrentFrame.Dot.Line) {
 			return false
 		}
-		if !MarkCreate(firstLine, CurrentFrame.Dot.Col, &CurrentFrame.Dot) {
+		if !MarkCreate(firstLine, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Dot) {
 			return false
 		}
-		CurrentFrame.TextModified = true
-		if !MarkCreate(firstLine, CurrentFrame.Dot.Col, &CurrentFrame.Marks[MarkModified]) {
+		e.CurrentFrame.TextModified = true
+		if !MarkCreate(firstLine, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Marks[MarkModified]) {
 			return false
 		}
 
@@ -571,8 +707,8 @@ func setTabs(request *TParObject, pos *int, setInitial bool) bool {
 			lmRight
 		)
 		lastMargin := lmNone
-		for i <= CurrentFrame.Dot.Line.Used && legal {
-			chi := ChToUpper(CurrentFrame.Dot.Line.Str.Get(i))
+		for i <= e.CurrentFrame.Dot.Line.Used && legal {
+			chi := ChToUpper(e.CurrentFrame.Dot.Line.Str.Get(i))
 			legal = (chi == 'T') || (chi == 'L') || (chi == 'R') || (chi == ' ')
 			switch chi {
 			case 'L':
@@ -586,113 +722,94 @@ func setTabs(request *TParObject, pos *int, setInitial bool) bool {
 		}
 		legal = legal && (lastMargin == lmRight)
 		if !legal {
-			ScreenMessage(MsgInvalidRuler)
+			e.ScreenMessage(MsgInvalidRuler)
 			return false
 		}
 
+		var temptab TabArray
 		i = 1
-		for i <= CurrentFrame.Dot.Line.Used {
-			chi := ChToUpper(CurrentFrame.Dot.Line.Str.Get(i))
-			if setInitial {
-				InitialTabStops[i] = (chi != ' ')
-			}
-			CurrentFrame.TabStops[i] = (chi != ' ')
+		for i <= e.CurrentFrame.Dot.Line.Used {
+			chi := ChToUpper(e.CurrentFrame.Dot.Line.Str.Get(i))
+			temptab.Set(i, chi != ' ')
 			switch chi {
 			case 'L':
 				if setInitial {
-					InitialMarginLeft = i
+					e.InitialMarginLeft = i
 				}
-				CurrentFrame.MarginLeft = i
+				e.CurrentFrame.MarginLeft = i
 			case 'R':
 				if setInitial {
-					InitialMarginRight = i
+					e.InitialMarginRight = i
 				}
-				CurrentFrame.MarginRight = i
+				e.CurrentFrame.MarginRight = i
 			}
 			i++
 		}
-		for j := CurrentFrame.Dot.Line.Used + 1; j <= MaxStrLen; j++ {
-			if setInitial {
-				InitialTabStops[j] = false
-			}
-			CurrentFrame.TabStops[j] = false
+		if setInitial {
+			e.InitialTabStops = temptab.Clone()
 		}
+		e.CurrentFrame.TabStops = temptab
 
-		firstLine := CurrentFrame.Dot.Line
-		dotCol := CurrentFrame.Dot.Col
+		firstLine := e.CurrentFrame.Dot.Line
+		dotCol := e.CurrentFrame.Dot.Col
 		if !MarksSqueeze(firstLine, 1, firstLine.FLink, 1) {
 			return false
 		}
-		if !LinesExtract(firstLine, firstLine) {
+		if !e.LinesExtract(firstLine, firstLine) {
 			return false
 		}
 		if !LinesDestroy(&firstLine, &firstLine) {
 			return false
 		}
-		CurrentFrame.Dot.Col = dotCol
+		e.CurrentFrame.Dot.Col = dotCol
 
 	case 'S': // Set tab
-		if CurrentFrame.Dot.Col == MaxStrLenP {
-			ScreenMessage(MsgOutOfRangeTabValue)
+		if e.CurrentFrame.Dot.Col == MaxStrLenP {
+			e.ScreenMessage(MsgOutOfRangeTabValue)
 			return false
 		}
 		if setInitial {
-			InitialTabStops[CurrentFrame.Dot.Col] = true
+			e.InitialTabStops.Set(e.CurrentFrame.Dot.Col, true)
 		}
-		CurrentFrame.TabStops[CurrentFrame.Dot.Col] = true
+		e.CurrentFrame.TabStops.Set(e.CurrentFrame.Dot.Col, true)
 
 	case 'C': // Clear tab
-		if CurrentFrame.Dot.Col == MaxStrLenP {
-			ScreenMessage(MsgOutOfRangeTabValue)
+		if e.CurrentFrame.Dot.Col == MaxStrLenP {
+			e.ScreenMessage(MsgOutOfRangeTabValue)
 			return false
 		}
 		if setInitial {
-			InitialTabStops[CurrentFrame.Dot.Col] = false
+			e.InitialTabStops.Set(e.CurrentFrame.Dot.Col, false)
 		}
-		CurrentFrame.TabStops[CurrentFrame.Dot.Col] = false
+		e.CurrentFrame.TabStops.Set(e.CurrentFrame.Dot.Col, false)
 
 	case 'W': // Regular width tabs
-		var temptab TabArray
-		for i := range temptab {
-			temptab[i] = false
-		}
 		var w int
-		if (!TparToInt(request, pos, &w)) || w <= 0 {
+		if (!e.TparToInt(request, pos, &w)) || w <= 0 {
 			return false
 		}
-		temptab[0] = true
-		temptab[MaxStrLenP] = true
-		for i := 1; i <= MaxStrLen; i++ {
-			if i%w == 1 {
-				temptab[i] = true
-			}
-		}
+		temptab := TabArray{Every: w}
 		if setInitial {
-			InitialTabStops = temptab
+			e.InitialTabStops = temptab.Clone()
 		}
-		CurrentFrame.TabStops = temptab
+		e.CurrentFrame.TabStops = temptab
 
 	case '(': // multi-columns specified
 		var temptab TabArray
-		for i := range temptab {
-			temptab[i] = false
-		}
-		temptab[0] = true
-		temptab[MaxStrLenP] = true
 		for {
 			var j int
-			if !TparToInt(request, pos, &j) {
+			if !e.TparToInt(request, pos, &j) {
 				return false
 			}
 			if j >= 1 && j <= MaxStrLen {
-				temptab[j] = true
+				temptab.Set(j, true)
 			} else {
-				ScreenMessage(MsgOutOfRangeTabValue)
+				e.ScreenMessage(MsgOutOfRangeTabValue)
 				return false
 			}
 			ch = nextchar(request, pos)
 			if ch != ',' && ch != ')' {
-				ScreenMessage(MsgBadFormatInTabTable)
+				e.ScreenMessage(MsgBadFormatInTabTable)
 				return false
 			}
 			if ch == ')' {
@@ -700,26 +817,26 @@ func setTabs(request *TParObject, pos *int, setInitial bool) bool {
 			}
 		}
 		if setInitial {
-			InitialTabStops = temptab
+			e.InitialTabStops = temptab.Clone()
 		}
-		CurrentFrame.TabStops = temptab
+		e.CurrentFrame.TabStops = temptab
 
 	default:
-		ScreenMessage(MsgInvalidTOption)
+		e.ScreenMessage(MsgInvalidTOption)
 		return false
 	}
 	return true
 }
 
 // getMar gets a margin value from the tpar
-func getMar(ch *byte, pos *int, request *TParObject, loBnd int, hiBnd int, margin *int) bool {
+func (e *Editor) getMar(ch *rune, pos *int, request *TParObject, loBnd int, hiBnd int, margin *int) bool {
 	if *ch >= '0' && *ch <= '9' {
 		*pos--
-		if !TparToInt(request, pos, margin) {
+		if !e.TparToInt(request, pos, margin) {
 			return false
 		}
 		if *margin < loBnd || *margin > hiBnd {
-			ScreenMessage(MsgMarginOutOfRange)
+			e.ScreenMessage(MsgMarginOutOfRange)
 			return false
 		}
 		*ch = nextchar(request, pos)
@@ -728,98 +845,98 @@ func getMar(ch *byte, pos *int, request *TParObject, loBnd int, hiBnd int, margi
 }
 
 // getMargins gets left/right or top/bottom margins from the tpar
-func getMargins(loBnd int, hiBnd int, request *TParObject, pos *int, lower *int, upper *int, lr bool) bool {
+func (e *Editor) getMargins(loBnd int, hiBnd int, request *TParObject, pos *int, lower *int, upper *int, lr bool) bool {
 	ch := nextchar(request, pos)
 	if ch != '(' {
-		ScreenMessage(MsgMarginSyntaxError)
+		e.ScreenMessage(MsgMarginSyntaxError)
 		return false
 	}
 	ch = nextchar(request, pos)
 	if ch == '.' {
 		if lr {
-			*lower = CurrentFrame.Dot.Col
+			*lower = e.CurrentFrame.Dot.Col
 		} else {
-			*lower = CurrentFrame.Dot.Line.ScrRowNr
+			*lower = e.CurrentFrame.Dot.Line.ScrRowNr
 		}
 		ch = nextchar(request, pos)
-	} else if !getMar(&ch, pos, request, loBnd, hiBnd, lower) {
+	} else if !e.getMar(&ch, pos, request, loBnd, hiBnd, lower) {
 		return false
 	}
 	if ch == ',' {
 		ch = nextchar(request, pos)
 		if ch == '.' {
 			if lr {
-				*upper = CurrentFrame.Dot.Col
+				*upper = e.CurrentFrame.Dot.Col
 			} else {
-				*upper = CurrentFrame.ScrHeight - CurrentFrame.Dot.Line.ScrRowNr
+				*upper = e.CurrentFrame.ScrHeight - e.CurrentFrame.Dot.Line.ScrRowNr
 			}
 			ch = nextchar(request, pos)
-		} else if !getMar(&ch, pos, request, loBnd, hiBnd, upper) {
+		} else if !e.getMar(&ch, pos, request, loBnd, hiBnd, upper) {
 			return false
 		}
 	}
 	if ch != ')' {
-		ScreenMessage(MsgMarginSyntaxError)
+		e.ScreenMessage(MsgMarginSyntaxError)
 		return false
 	}
 	return true
 }
 
 // setLRMargin sets the left and right margins
-func setLRMargin(request *TParObject, pos *int, setInitial bool) bool {
+func (e *Editor) setLRMargin(request *TParObject, pos *int, setInitial bool) bool {
 	var tl, tr int
 	if setInitial {
-		tl = InitialMarginLeft
-		tr = InitialMarginRight
+		tl = e.InitialMarginLeft
+		tr = e.InitialMarginRight
 	} else {
-		tl = CurrentFrame.MarginLeft
-		tr = CurrentFrame.MarginRight
+		tl = e.CurrentFrame.MarginLeft
+		tr = e.CurrentFrame.MarginRight
 	}
-	if !getMargins(1, MaxStrLen, request, pos, &tl, &tr, true) {
+	if !e.getMargins(1, MaxStrLen, request, pos, &tl, &tr, true) {
 		return false
 	}
 	if tl < tr {
 		if setInitial {
-			InitialMarginLeft = tl
-			InitialMarginRight = tr
+			e.InitialMarginLeft = tl
+			e.InitialMarginRight = tr
 		}
-		CurrentFrame.MarginLeft = tl
-		CurrentFrame.MarginRight = tr
+		e.CurrentFrame.MarginLeft = tl
+		e.CurrentFrame.MarginRight = tr
 	} else {
-		ScreenMessage(MsgLeftMarginGeRight)
+		e.ScreenMessage(MsgLeftMarginGeRight)
 		return false
 	}
 	return true
 }
 
 // setTBMargin sets the top and bottom margins
-func setTBMargin(request *TParObject, pos *int, setInitial bool) bool {
+func (e *Editor) setTBMargin(request *TParObject, pos *int, setInitial bool) bool {
 	var tt, tb int
 	if setInitial {
-		tt = InitialMarginTop
-		tb = InitialMarginBottom
+		tt = e.InitialMarginTop
+		tb = e.InitialMarginBottom
 	} else {
-		tt = CurrentFrame.MarginTop
-		tb = CurrentFrame.MarginBottom
+		tt = e.CurrentFrame.MarginTop
+		tb = e.CurrentFrame.MarginBottom
 	}
-	if !getMargins(0, CurrentFrame.ScrHeight, request, pos, &tt, &tb, false) {
+	if !e.getMargins(0, e.CurrentFrame.ScrHeight, request, pos, &tt, &tb, false) {
 		return false
 	}
-	if tt+tb >= CurrentFrame.ScrHeight {
-		ScreenMessage(MsgMarginOutOfRange)
+	if tt+tb >= e.CurrentFrame.ScrHeight {
+		e.ScreenMessage(MsgMarginOutOfRange)
 		return false
 	}
 	if setInitial {
-		InitialMarginTop = tt
-		InitialMarginBottom = tb
+		e.InitialMarginTop = tt
+		e.InitialMarginBottom = tb
 	}
-	CurrentFrame.MarginTop = tt
-	CurrentFrame.MarginBottom = tb
+	e.CurrentFrame.MarginTop = tt
+	e.CurrentFrame.MarginBottom = tb
 	return true
 }
 
 // setparam parses and sets frame parameters
-func setparam(request *TParObject) bool {
+func (e *Editor) setparam(request *TParObject) bool {
 	pos := 1
 	ch := nextchar(request, &pos)
 	for ch != 0 {
@@ -829,38 +946,38 @@ func setparam(request *TParObject) bool {
 			ch = nextchar(request, &pos)
 		}
 		if nextchar(request, &pos) != '=' {
-			ScreenMessage(MsgOptionsSyntaxError)
+			e.ScreenMessage(MsgOptionsSyntaxError)
 			return false
 		}
 		ok := false
 		var temp int
 		switch ch {
 		case 'O':
-			ok = setOptions(request, &pos, setInitial)
+			ok = e.setOptions(request, &pos, setInitial)
 		case 'S':
-			if TparToInt(request, &pos, &temp) {
-				ok = setmemory(temp, setInitial)
+			if e.TparToInt(request, &pos, &temp) {
+				ok = e.setmemory(temp, setInitial)
 			}
 		case 'H':
-			if TparToInt(request, &pos, &temp) {
-				ok = FrameSetHeight(temp, setInitial)
+			if e.TparToInt(request, &pos, &temp) {
+				ok = e.FrameSetHeight(temp, setInitial)
 			}
 		case 'W':
-			if TparToInt(request, &pos, &temp) {
-				ok = setwidth(temp, setInitial)
+			if e.TparToInt(request, &pos, &temp) {
+				ok = e.setwidth(temp, setInitial)
 			}
 		case 'C':
-			ok = setcmdintr(request, &pos)
+			ok = e.setcmdintr(request, &pos)
 		case 'T':
-			ok = setTabs(request, &pos, setInitial)
+			ok = e.setTabs(request, &pos, setInitial)
 		case 'M':
-			ok = setLRMargin(request, &pos, setInitial)
+			ok = e.setLRMargin(request, &pos, setInitial)
 		case 'V':
-			ok = setTBMargin(request, &pos, setInitial)
+			ok = e.setTBMargin(request, &pos, setInitial)
 		case 'K':
-			ok = setMode(request, &pos)
+			ok = e.setMode(request, &pos)
 		default:
-			ScreenMessage(MsgInvalidParameterCode)
+			e.ScreenMessage(MsgInvalidParameterCode)
 			return false
 		}
 		if !ok {
@@ -870,7 +987,7 @@ func setparam(request *TParObject) bool {
 		if ch == ',' || ch == 0 {
 			ch = nextchar(request, &pos)
 		} else {
-			ScreenMessage(MsgSyntaxErrorInParamCmd)
+			e.ScreenMessage(MsgSyntaxErrorInParamCmd)
 			return false
 		}
 	}
@@ -878,54 +995,62 @@ func setparam(request *TParObject) bool {
 }
 
 // displayOption displays a single option character
-func displayOption(ch byte, first *bool) {
+func (e *Editor) displayOption(ch byte, first *bool) {
 	if *first {
-		ScreenWriteCh(0, '(')
+		e.ScreenWriteCh(0, '(')
 	} else {
-		ScreenWriteCh(0, ',')
+		e.ScreenWriteCh(0, ',')
 	}
-	ScreenWriteCh(0, ch)
+	e.ScreenWriteCh(0, ch)
 	*first = false
 }
 
 // printOptions prints the frame options
-func printOptions(options FrameOptions) {
+func (e *Editor) printOptions(options FrameOptions) {
 	count := 1
 	first := true
-	ScreenWriteCh(0, ' ')
+	e.ScreenWriteCh(0, ' ')
 	if options.Has(OptAutoIndent) {
-		displayOption('I', &first)
+		e.displayOption('I', &first)
 		count += 2
 	}
 	if options.Has(OptAutoWrap) {
-		displayOption('W', &first)
+		e.displayOption('W', &first)
 		count += 2
 	}
 	if options.Has(OptNewLine) {
-		displayOption('N', &first)
+		e.displayOption('N', &first)
+		count += 2
+	}
+	if options.Has(OptHighlight) {
+		e.displayOption('H', &first)
+		count += 2
+	}
+	if options.Has(OptMatches) {
+		e.displayOption('M', &first)
 		count += 2
 	}
 	if first {
 		s := "  None    "
-		ScreenWriteStr(0, s)
+		e.ScreenWriteStr(0, s)
 		count += len(s)
 	} else {
-		ScreenWriteCh(0, ')')
+		e.ScreenWriteCh(0, ')')
 		count++
 	}
 	// Pad to 14 characters
 	if count < 14 {
-		ScreenWriteStr(0, strings.Repeat(" ", 14-count))
+		e.ScreenWriteStr(0, strings.Repeat(" ", 14-count))
 	}
 }
 
 // printMargins prints margin values
-func printMargins(m1 int, m2 int) {
-	ScreenWriteStr(0, " (")
-	ScreenWriteInt(m1, 1)
-	ScreenWriteCh(0, ',')
-	ScreenWriteInt(m2, 1)
-	ScreenWriteCh(0, ')')
+func (e *Editor) printMargins(m1 int, m2 int) {
+	e.ScreenWriteStr(0, " (")
+	e.ScreenWriteInt(m1, 1)
+	e.ScreenWriteCh(0, ',')
+	e.ScreenWriteInt(m2, 1)
+	e.ScreenWriteCh(0, ')')
 	count := 6
 	if m1 > 99 {
 		count += 2
@@ -938,140 +1063,140 @@ func printMargins(m1 int, m2 int) {
 		count++
 	}
 	if count < 14 {
-		ScreenWriteStr(0, strings.Repeat(" ", 14-count))
+		e.ScreenWriteStr(0, strings.Repeat(" ", 14-count))
 	}
 }
 
 // FrameParameter handles the frame parameters command
-func FrameParameter(tpar *TParObject) bool {
+func (e *Editor) FrameParameter(tpar *TParObject) bool {
 	result := false
 	tpar.Nxt = nil
 	tpar.Con = nil
 	var request TParObject
-	if !TparGet1(tpar, CmdFrameParameters, &request) {
+	if !e.TparGet1(tpar, CmdFrameParameters, &request) {
 		goto l99
 	}
 	if request.Len > 0 {
-		result = setparam(&request)
+		result = e.setparam(&request)
 		goto l99
 	}
 
 	// Display parameters and stats
-	ScreenUnload()
-	ScreenHome(true)
+	e.ScreenUnload()
+	e.ScreenHome(true)
 	for {
-		ScreenHome(false) // Don't clear the screen here!
-		ScreenWriteStr(0, " Ludwig ")
+		e.ScreenHome(false) // Don't clear the screen here!
+		e.ScreenWriteStr(0, " Ludwig ")
 		for i := 0; i < 8 && i < len(LudwigVersion); i++ {
-			ScreenWriteCh(0, LudwigVersion[i])
-		}
-		ScreenWriteStr(5, "Parameters      Frame: ")
-		ScreenWriteNameStr(0, CurrentFrame.Span.Name, NameLen)
-		ScreenWritelnClel()
-		ScreenWriteStr(0, " ===============     ==========      =====")
-		ScreenWritelnClel()
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Unused  memory available in frame    =")
-		ScreenWriteInt(CurrentFrame.SpaceLeft, 9)
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "The number of lines in this frame    =")
+			e.ScreenWriteCh(0, LudwigVersion[i])
+		}
+		e.ScreenWriteStr(5, "Parameters      Frame: ")
+		e.ScreenWriteNameStr(0, e.CurrentFrame.Span.Name, NameLen)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(0, " ===============     ==========      =====")
+		e.ScreenWritelnClel()
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Unused  memory available in frame    =")
+		e.writeSpace(e.CurrentFrame.SpaceLeft, e.CurrentFrame.SpaceLimit)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "The number of lines in this frame    =")
 		var temp int
-		if LineToNumber(CurrentFrame.LastGroup.LastLine, &temp) {
+		if LineToNumber(e.CurrentFrame.LastGroup.LastLine, &temp) {
 			temp--
 		} else {
 			temp = 0
 		}
-		ScreenWriteInt(temp, 9)
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Lines read from input file so far    =")
-		ScreenWriteInt(int(CurrentFrame.InputCount), 9)
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Current Line number in this frame    =")
-		if !LineToNumber(CurrentFrame.Dot.Line, &temp) {
+		e.ScreenWriteInt(temp, 9)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Lines read from input file so far    =")
+		e.ScreenWriteInt(int(e.CurrentFrame.InputCount), 9)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Current Line number in this frame    =")
+		if !LineToNumber(e.CurrentFrame.Dot.Line, &temp) {
 			temp = 0
 		}
-		ScreenWriteInt(temp, 9)
-		ScreenWritelnClel()
-		ScreenWritelnClel()
-		ScreenWriteStr(9, "Parameters")
-		ScreenWriteStr(41, "Defaults")
-		ScreenWritelnClel()
-		ScreenWriteStr(9, "----------")
-		ScreenWriteStr(41, "--------")
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Keyboard Mode                      K =")
-		switch EditMode {
+		e.ScreenWriteInt(temp, 9)
+		e.ScreenWritelnClel()
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(9, "Parameters")
+		e.ScreenWriteStr(41, "Defaults")
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(9, "----------")
+		e.ScreenWriteStr(41, "--------")
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Keyboard Mode                      K =")
+		switch e.EditMode {
 		case ModeOvertype:
-			ScreenWriteStr(1, "Overtype Mode")
+			e.ScreenWriteStr(1, "Overtype Mode")
 		case ModeInsert:
-			ScreenWriteStr(1, "Insert Mode")
+			e.ScreenWriteStr(1, "Insert Mode")
 		case ModeCommand:
-			ScreenWriteStr(1, "Command Mode")
+			e.ScreenWriteStr(1, "Command Mode")
 		}
-		ScreenWritelnClel()
-		if LudwigMode == LudwigScreen {
-			ScreenWriteStr(3, "Command introducer                 C = ")
+		e.ScreenWritelnClel()
+		if e.LudwigMode == LudwigScreen {
+			e.ScreenWriteStr(3, "Command introducer                 C = ")
 			var keyName string
-			if UserKeyCodeToName(CommandIntroducer, &keyName) {
-				ScreenWriteStr(0, keyName)
+			if e.UserKeyCodeToName(e.CommandIntroducer, &keyName) {
+				e.ScreenWriteStr(0, keyName)
 			} else {
-				ScreenWriteCh(0, byte(CommandIntroducer))
+				e.ScreenWriteCh(0, byte(e.CommandIntroducer))
 			}
-			ScreenWritelnClel()
-		}
-		ScreenWriteStr(3, "Maximum memory available in frame  S =")
-		ScreenWriteInt(CurrentFrame.SpaceLimit, 9)
-		ScreenWriteStr(5, "  --  ")
-		ScreenWriteInt(FileData.Space, 9)
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Screen height  (lines displayed)   H =")
-		ScreenWriteInt(CurrentFrame.ScrHeight, 9)
-		ScreenWriteStr(5, "  --  ")
-		ScreenWriteInt(InitialScrHeight, 9)
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Screen width   (characters)        W =")
-		ScreenWriteInt(CurrentFrame.ScrWidth, 9)
-		ScreenWriteStr(5, "  --  ")
-		ScreenWriteInt(InitialScrWidth, 9)
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Editing options                    O =")
-		printOptions(CurrentFrame.Options)
-		ScreenWriteStr(0, "  --  ")
-		printOptions(InitialOptions)
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Horizontal margins                 M =")
-		printMargins(CurrentFrame.MarginLeft, CurrentFrame.MarginRight)
-		ScreenWriteStr(0, "  --  ")
-		printMargins(InitialMarginLeft, InitialMarginRight)
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Vertical margins                   V =")
-		printMargins(CurrentFrame.MarginTop, CurrentFrame.MarginBottom)
-		ScreenWriteStr(0, "  --  ")
-		printMargins(InitialMarginTop, InitialMarginBottom)
-		ScreenWritelnClel()
-		ScreenWriteStr(3, "Tab settings                       T =")
-		ScreenWritelnClel()
-		for i := 1; i <= CurrentFrame.ScrWidth; i++ {
-			if i == CurrentFrame.MarginLeft {
-				ScreenWriteCh(0, 'L')
-			} else if i == CurrentFrame.MarginRight {
-				ScreenWriteCh(0, 'R')
-			} else if CurrentFrame.TabStops[i] {
-				ScreenWriteCh(0, 'T')
+			e.ScreenWritelnClel()
+		}
+		e.ScreenWriteStr(3, "Maximum memory available in frame  S =")
+		e.writeSpace(e.CurrentFrame.SpaceLimit, e.CurrentFrame.SpaceLimit)
+		e.ScreenWriteStr(5, "  --  ")
+		e.writeSpace(e.FileData.Space, e.FileData.Space)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Screen height  (lines displayed)   H =")
+		e.ScreenWriteInt(e.CurrentFrame.ScrHeight, 9)
+		e.ScreenWriteStr(5, "  --  ")
+		e.ScreenWriteInt(e.InitialScrHeight, 9)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Screen width   (characters)        W =")
+		e.ScreenWriteInt(e.CurrentFrame.ScrWidth, 9)
+		e.ScreenWriteStr(5, "  --  ")
+		e.ScreenWriteInt(e.InitialScrWidth, 9)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Editing options                    O =")
+		e.printOptions(e.CurrentFrame.Options)
+		e.ScreenWriteStr(0, "  --  ")
+		e.printOptions(e.InitialOptions)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Horizontal margins                 M =")
+		e.printMargins(e.CurrentFrame.MarginLeft, e.CurrentFrame.MarginRight)
+		e.ScreenWriteStr(0, "  --  ")
+		e.printMargins(e.InitialMarginLeft, e.InitialMarginRight)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Vertical margins                   V =")
+		e.printMargins(e.CurrentFrame.MarginTop, e.CurrentFrame.MarginBottom)
+		e.ScreenWriteStr(0, "  --  ")
+		e.printMargins(e.InitialMarginTop, e.InitialMarginBottom)
+		e.ScreenWritelnClel()
+		e.ScreenWriteStr(3, "Tab settings                       T =")
+		e.ScreenWritelnClel()
+		for i := 1; i <= e.CurrentFrame.ScrWidth; i++ {
+			if i == e.CurrentFrame.MarginLeft {
+				e.ScreenWriteCh(0, 'L')
+			} else if i == e.CurrentFrame.MarginRight {
+				e.ScreenWriteCh(0, 'R')
+			} else if e.CurrentFrame.TabStops.IsStop(i) {
+				e.ScreenWriteCh(0, 'T')
 			} else {
-				ScreenWriteCh(0, ' ')
+				e.ScreenWriteCh(0, ' ')
 			}
 		}
-		ScreenWriteln()
-		ScreenWritelnClel()
-		ScreenGetLineP(newValues, &request.Str, &request.Len, 1, 1)
+		e.ScreenWriteln()
+		e.ScreenWritelnClel()
+		e.ScreenGetLineP(newValues, &request.Str, &request.Len, 1, 1)
 		if request.Len > 0 {
 			ChApplyN(request.Str, ChToUpper, request.Len)
-			if !setparam(&request) {
-				ScreenBeep()
+			if !e.setparam(&request) {
+				e.ScreenBeep()
 			}
 		}
-		if TtControlC || request.Len == 0 {
+		if e.TtControlC.Load() || request.Len == 0 {
 			break
 		}
 	}
@@ -0,0 +1,182 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         FRAMELIST
+//
+// Description:  The EL command.  Frame FRAMES lists the frames, and from
+//               there the frame on Dot's line can be edited, saved or
+//               killed.
+
+package ludwig
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	frameNameList = "FRAMES"
+
+	// The number of heading lines above the line for the first frame
+	frameListHeading = 2
+)
+
+// FrameList is the EL command.  From any other frame it lists the frames in
+// frame FRAMES and goes there, with Dot on the line of the frame it came
+// from.  In frame FRAMES, EL edits the frame on Dot's line, +EL saves it
+// and -EL kills it.
+func (e *Editor) FrameList(rept LeadParam, fromSpan bool) bool {
+	if e.frameList == nil || e.CurrentFrame != e.frameList {
+		if rept != LeadParamNone {
+			e.ScreenMessage(MsgNotInFrameList)
+			return false
+		}
+		from := e.CurrentFrame
+		if !e.FrameCreateExtra(frameNameList, &e.frameList) {
+			return false
+		}
+		if !e.frameListLoad(from, 0) {
+			return false
+		}
+		return e.FrameEdit(frameNameList)
+	}
+
+	var lineNr int
+	LineToNumber(e.frameList.Dot.Line, &lineNr)
+	frame := e.frameListFrame(e.frameList.Dot.Line)
+	if frame == nil {
+		e.ScreenMessage(MsgNoSuchFrame)
+		return false
+	}
+	result := false
+	switch rept {
+	case LeadParamPlus:
+		e.CurrentFrame = frame
+		result = e.FileCommand(CmdFileSave, LeadParamNone, 0, nil, fromSpan)
+		e.CurrentFrame = e.frameList
+	case LeadParamMinus:
+		result = e.FrameKill(frame.Span.Name)
+	default:
+		return e.FrameEdit(frame.Span.Name)
+	}
+
+	// Show what saving or killing the frame did, leaving Dot where it was
+	if !e.frameListLoad(nil, lineNr) {
+		return false
+	}
+	return result
+}
+
+// frameListWidth returns the number of screen columns a name takes
+func frameListWidth(name string) int {
+	width := 0
+	for _, ch := range name {
+		width += ChWidth(ch)
+	}
+	return width
+}
+
+// frameListPad pads a name with spaces to take width screen columns, so
+// that the columns of the list line up whatever characters are in it
+func frameListPad(name string, width int) string {
+	return name + strings.Repeat(" ", max(width-frameListWidth(name), 0))
+}
+
+// frameListLoad replaces the text of frame FRAMES with a line for each
+// frame that is not a special frame.  Dot goes to the line for dotFrame if
+// it is listed, otherwise to line dotLine.
+func (e *Editor) frameListLoad(dotFrame *FrameObject, dotLine int) bool {
+	var names, inputs, outputs []string
+	var frames []*FrameObject
+	nameWidth := frameListWidth("Frame")
+	inputWidth := frameListWidth("Input")
+	for span := e.FirstSpan; span != nil; span = span.FLink {
+		frame := span.Frame
+		if frame == nil || frame.Options.Has(OptSpecialFrame) {
+			continue
+		}
+		input := e.frameListFileName(frame.InputFile)
+		frames = append(frames, frame)
+		names = append(names, span.Name)
+		inputs = append(inputs, input)
+		outputs = append(outputs, e.frameListFileName(frame.OutputFile))
+		nameWidth = max(nameWidth, frameListWidth(span.Name))
+		inputWidth = max(inputWidth, frameListWidth(input))
+		if frame == dotFrame {
+			dotLine = len(frames) + frameListHeading
+		}
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s  Lines Mod Dot         %s Output\n", frameListPad("Frame", nameWidth), frameListPad("Input", inputWidth))
+	fmt.Fprintf(&text, "%s ------ --- ----------- %s ------\n", strings.Repeat("-", nameWidth), strings.Repeat("-", inputWidth))
+	for i, frame := range frames {
+		var lastNr, dotNr int
+		LineToNumber(frame.LastGroup.LastLine, &lastNr)
+		LineToNumber(frame.Dot.Line, &dotNr)
+		modified := ""
+		if frame.TextModified {
+			modified = "*"
+		}
+		dot := fmt.Sprintf("%d:%d", dotNr, frame.Dot.Col)
+		line := fmt.Sprintf("%s %6d  %-1s  %-11s %s %s", frameListPad(names[i], nameWidth), lastNr-1, modified, dot,
+			frameListPad(inputs[i], inputWidth), outputs[i])
+		text.WriteString(strings.TrimRight(line, " "))
+		text.WriteByte('\n')
+	}
+
+	list := e.frameList
+	if !e.FrameReplaceText(list, text.String()) {
+		return false
+	}
+
+	var line *LineHdrObject
+	if !LineFromNumber(list, max(dotLine, frameListHeading+1), &line) {
+		return false
+	}
+	if line == nil {
+		line = list.LastGroup.LastLine
+	}
+	return MarkCreate(line, 1, &list.Dot)
+}
+
+// frameListFrame returns the frame listed on a line of frame FRAMES, or nil
+// if there is none.  The frame is found from the name at the start of the
+// line, as FRAMES may have been edited since it was listed.  The longest
+// name that fits is used, since frame names can hold spaces.
+func (e *Editor) frameListFrame(line *LineHdrObject) *FrameObject {
+	text := ""
+	if line.Used > 0 {
+		text = line.Str.Slice(1, line.Used)
+	}
+	var found *FrameObject
+	foundLen := 0
+	for span := e.FirstSpan; span != nil; span = span.FLink {
+		frame := span.Frame
+		if frame == nil || frame.Options.Has(OptSpecialFrame) || len(span.Name) <= foundLen {
+			continue
+		}
+		rest, ok := strings.CutPrefix(text, span.Name)
+		if ok && (rest == "" || rest[0] == ' ') {
+			found = frame
+			foundLen = len(span.Name)
+		}
+	}
+	return found
+}
+
+// frameListFileName returns the name of the file in a file slot, or "-"
+// when the slot is not in use
+func (e *Editor) frameListFileName(fileSlot int) string {
+	if fileSlot == 0 || e.Files[fileSlot] == nil {
+		return "-"
+	}
+	return e.Files[fileSlot].Filename
+}
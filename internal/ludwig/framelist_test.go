@@ -0,0 +1,127 @@
+// Tests for the frame list
+
+package ludwig
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBatchEditor makes an editor in batch mode with the old command set,
+// keeping its messages
+func newBatchEditor(t *testing.T, messages *bytes.Buffer) *Editor {
+	e := NewEditor()
+	e.Output = messages
+	e.ValueInitializations()
+	require.True(t, e.BatchStart())
+	return e
+}
+
+func TestFrameList(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.BatchExecute("i/hello/ ed/other/ el"))
+
+	// Each frame has a line, and Dot is on the frame EL came from
+	assert.Equal(t, frameNameList, e.CurrentFrame.Span.Name)
+	assert.Equal(t, []string{
+		"Frame   Lines Mod Dot         Input Output",
+		"------ ------ --- ----------- ----- ------",
+		"LUDWIG      1  *  1:6         -     -",
+		"OTHER       0     1:1         -     -",
+	}, frameText(e.CurrentFrame))
+	var lineNr int
+	LineToNumber(e.CurrentFrame.Dot.Line, &lineNr)
+	assert.Equal(t, 4, lineNr)
+	assert.False(t, e.CurrentFrame.TextModified)
+
+	// +EL and -EL only work in the list, and the list cannot be killed
+	require.True(t, e.BatchExecute("er"))
+	messages.Reset()
+	assert.False(t, e.BatchExecute("+el"))
+	assert.Contains(t, messages.String(), MsgNotInFrameList)
+	assert.False(t, e.BatchExecute("ek/frames/"))
+
+	// Killing a frame takes it out of the list
+	require.True(t, e.BatchExecute("el -el"))
+	assert.Equal(t, []string{"LUDWIG      1  *  1:6         -     -"}, frameText(e.CurrentFrame)[2:])
+	var span, oldp *SpanObject
+	assert.False(t, e.SpanFind("OTHER", &span, &oldp))
+
+	// Dot is left after the last frame, where there is nothing to edit
+	assert.False(t, e.BatchExecute("el"))
+	require.True(t, e.BatchExecute("-a el"))
+	assert.Equal(t, DefaultFrameName, e.CurrentFrame.Span.Name)
+	assert.Equal(t, e.frameList, e.CurrentFrame.ReturnFrame)
+
+	// A frame with no output file cannot be saved
+	messages.Reset()
+	assert.False(t, e.BatchExecute("el +el"))
+	assert.Contains(t, messages.String(), MsgNoOutput)
+}
+
+func TestFrameListWide(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+
+	// Columns line up by screen width, whatever characters a name has
+	require.True(t, e.BatchExecute("ed/日本語/ el"), messages.String())
+	assert.Equal(t, []string{
+		"Frame   Lines Mod Dot         Input Output",
+		"------ ------ --- ----------- ----- ------",
+		"LUDWIG      0     1:1         -     -",
+		"日本語      0     1:1         -     -",
+	}, frameText(e.CurrentFrame))
+}
+
+func TestFrameListEdited(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.BatchExecute("ed/aaa/ ed/bbb/ el"))
+
+	// The frame comes from the text of Dot's line, not where it was listed
+	require.True(t, e.BatchExecute("-3a k 2a"))
+	require.Equal(t, "BBB         0     1:1         -     -", frameText(e.CurrentFrame)[2])
+	require.True(t, e.BatchExecute("-el"), messages.String())
+	var span, oldp *SpanObject
+	assert.True(t, e.SpanFind("AAA", &span, &oldp))
+	assert.False(t, e.SpanFind("BBB", &span, &oldp))
+
+	// A line that does not start with the name of a frame has no frame
+	messages.Reset()
+	require.True(t, e.BatchExecute("-a"))
+	assert.False(t, e.BatchExecute("el"))
+	assert.Contains(t, messages.String(), MsgNoSuchFrame)
+}
+
+func TestFrameListSave(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	path := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("text\n"), 0644))
+
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileEditFrame("FILE", []string{"-b", "0"}, path))
+	require.True(t, e.BatchExecute("i/more /"))
+
+	require.True(t, e.BatchExecute("el"), messages.String())
+	assert.Equal(t, "FILE        1  *  1:6         "+path+" "+path, frameText(e.CurrentFrame)[2])
+
+	// Saving writes the frame out, and the list shows it is not modified
+	require.True(t, e.BatchExecute("+el"), messages.String())
+	text, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "more text\n", string(text))
+	assert.Equal(t, "FILE        1     1:6         "+path+" "+path, frameText(e.CurrentFrame)[2])
+
+	// A frame with files cannot be killed
+	assert.False(t, e.BatchExecute("-el"))
+	e.LudwigAborted = false
+	e.QuitCloseFiles()
+}
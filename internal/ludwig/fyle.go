@@ -14,8 +14,19 @@
 
 package ludwig
 
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
 const blankName = "                               "
 
+// filePieceLines is how many lines of a large file are paged in at a time
+const filePieceLines = 10000
+
 // FileName returns a file's name, in the specified width.
 func FileName(fp *FileObject, maxLen int, actFnm *string) {
 	if maxLen < 5 {
@@ -38,96 +49,102 @@ func FileName(fp *FileObject, maxLen int, actFnm *string) {
 }
 
 // FileTable lists the current files.
-func FileTable() {
-	ScreenUnload()
-	ScreenHome(false)
-	ScreenWriteStrWidth(0, "Usage   Mod Frame  Filename", 27)
-	ScreenWriteln()
-	ScreenWriteStrWidth(0, "------- --- ------ --------", 27)
-	ScreenWriteln()
-	ScreenWriteln()
+func (e *Editor) FileTable() {
+	e.ScreenUnload()
+	e.ScreenHome(false)
+	e.ScreenWriteStrWidth(0, "Usage   Mod Frame  Filename", 27)
+	e.ScreenWriteln()
+	e.ScreenWriteStrWidth(0, "------- --- ------ --------", 27)
+	e.ScreenWriteln()
+	e.ScreenWriteln()
 	for fileSlot := 1; fileSlot <= MaxFiles; fileSlot++ {
-		if Files[fileSlot] != nil {
+		if e.Files[fileSlot] != nil {
 			var frameName string
-			if FilesFrames[fileSlot] != nil {
-				frameName = FilesFrames[fileSlot].Span.Name
+			if e.FilesFrames[fileSlot] != nil {
+				frameName = e.FilesFrames[fileSlot].Span.Name
 			} else {
 				frameName = blankName[:6]
 			}
 
-			if FilesFrames[fileSlot] != nil {
-				if Files[fileSlot].OutputFlag {
-					ScreenWriteStrWidth(0, "FO ", 3)
+			if e.FilesFrames[fileSlot] != nil {
+				if e.Files[fileSlot].OutputFlag {
+					e.ScreenWriteStrWidth(0, "FO ", 3)
 				} else {
-					ScreenWriteStrWidth(0, "FI ", 3)
+					e.ScreenWriteStrWidth(0, "FI ", 3)
 				}
-			} else if fileSlot == FgiFile {
-				ScreenWriteStrWidth(0, "FGI", 3)
-			} else if fileSlot == FgoFile {
-				ScreenWriteStrWidth(0, "FGO", 3)
-			} else if Files[fileSlot].OutputFlag {
-				ScreenWriteStrWidth(0, "FFO", 3)
+			} else if fileSlot == e.FgiFile {
+				e.ScreenWriteStrWidth(0, "FGI", 3)
+			} else if fileSlot == e.FgoFile {
+				e.ScreenWriteStrWidth(0, "FGO", 3)
+			} else if e.Files[fileSlot].OutputFlag {
+				e.ScreenWriteStrWidth(0, "FFO", 3)
 			} else {
-				ScreenWriteStrWidth(0, "FFI", 3)
+				e.ScreenWriteStrWidth(0, "FFI", 3)
 			}
 
-			if Files[fileSlot].Eof {
-				ScreenWriteStrWidth(1, "EOF", 3)
+			if e.Files[fileSlot].Eof {
+				e.ScreenWriteStrWidth(1, "EOF", 3)
 			} else {
-				ScreenWriteStrWidth(1, "   ", 3)
+				e.ScreenWriteStrWidth(1, "   ", 3)
 			}
 
-			if FilesFrames[fileSlot] != nil {
-				if FilesFrames[fileSlot].TextModified {
-					ScreenWriteStrWidth(1, " * ", 3)
+			if e.FilesFrames[fileSlot] != nil {
+				if e.FilesFrames[fileSlot].TextModified {
+					e.ScreenWriteStrWidth(1, " * ", 3)
 				} else {
-					ScreenWriteStrWidth(1, "   ", 3)
+					e.ScreenWriteStrWidth(1, "   ", 3)
 				}
 			} else {
-				ScreenWriteStrWidth(1, "   ", 3)
+				e.ScreenWriteStrWidth(1, "   ", 3)
 			}
 
-			ScreenWriteNameStr(1, frameName, len(frameName))
+			e.ScreenWriteNameStr(1, frameName, len(frameName))
 			if len(frameName) > 6 {
-				ScreenWriteln()
-				ScreenWriteStrWidth(0, "                  ", 18)
+				e.ScreenWriteln()
+				e.ScreenWriteStrWidth(0, "                  ", 18)
 			}
 
 			var room int
-			if LudwigMode == LudwigScreen {
-				room = TerminalInfo.Width - 18 - 1
+			if e.LudwigMode == LudwigScreen {
+				room = e.TerminalInfo.Width - 18 - 1
 			} else {
 				room = FileNameLen
 			}
 			var compressedFnm string
-			FileName(Files[fileSlot], room, &compressedFnm)
-			ScreenWriteFileNameStr(1, compressedFnm, len(compressedFnm))
-			ScreenWriteln()
+			FileName(e.Files[fileSlot], room, &compressedFnm)
+			e.ScreenWriteFileNameStr(1, compressedFnm, len(compressedFnm))
+			e.ScreenWriteln()
 		}
 	}
-	ScreenPause()
+	e.ScreenPause()
 }
 
 // FileFixEOP updates the end-of-page marker
-func FileFixEOP(eof bool, eopLine *LineHdrObject) {
+func (e *Editor) FileFixEOP(eof bool, eopLine *LineHdrObject) {
 	if eof {
-		eopLine.Str.FillCopyBytes([]byte("<End of File>  "), 1, MaxStrLen, ' ')
+		eopLine.Str.FillCopyRunes([]rune("<End of File>  "), 1, MaxStrLen, ' ')
 	} else {
-		eopLine.Str.FillCopyBytes([]byte("<Page Boundary>"), 1, MaxStrLen, ' ')
+		eopLine.Str.FillCopyRunes([]rune("<Page Boundary>"), 1, MaxStrLen, ' ')
 	}
 	if eopLine.ScrRowNr != 0 {
-		VduDim()
-		ScreenDrawLine(eopLine)
-		VduNormal()
+		e.VduDim()
+		e.ScreenDrawLine(eopLine)
+		e.VduNormal()
 	}
 }
 
 // FileCreateOpen parses fn and creates I/O streams to files.
-func FileCreateOpen(fn *string, parse ParseType, inputfp **FileObject, outputfp **FileObject) bool {
+func (e *Editor) FileCreateOpen(fn *string, parse ParseType, inputfp **FileObject, outputfp **FileObject) bool {
+	return e.FileCreateOpenArgs(toArgv(*fn), parse, inputfp, outputfp)
+}
+
+// FileCreateOpenArgs is FileCreateOpen for arguments that are already split
+// into words, so that a file name may hold spaces
+func (e *Editor) FileCreateOpenArgs(args []string, parse ParseType, inputfp **FileObject, outputfp **FileObject) bool {
 	switch parse {
 	case ParseCommand, ParseInput, ParseEdit, ParseStdin, ParseExecute:
 		if *inputfp != nil {
-			ScreenMessage(MsgFileAlreadyInUse)
+			e.ScreenMessage(MsgFileAlreadyInUse)
 			return false
 		}
 		*inputfp = &FileObject{}
@@ -137,14 +154,14 @@ func FileCreateOpen(fn *string, parse ParseType, inputfp **FileObject, outputfp
 		(*inputfp).LineCount = 0
 		(*inputfp).OutputFlag = false
 		(*inputfp).Eof = false
-		(*inputfp).Idx = MaxStrLen
+		(*inputfp).Idx = 0
 		(*inputfp).Len = 0
 	}
 
 	switch parse {
 	case ParseCommand, ParseOutput, ParseEdit:
 		if *outputfp != nil {
-			ScreenMessage(MsgFileAlreadyInUse)
+			e.ScreenMessage(MsgFileAlreadyInUse)
 			return false
 		}
 		*outputfp = &FileObject{}
@@ -155,7 +172,7 @@ func FileCreateOpen(fn *string, parse ParseType, inputfp **FileObject, outputfp
 		(*outputfp).OutputFlag = true
 	}
 
-	result := FilesysParse(*fn, parse, &FileData, *inputfp, *outputfp)
+	result := e.FilesysParseArgs(args, parse, &e.FileData, *inputfp, *outputfp)
 	if *inputfp != nil && !(*inputfp).Valid {
 		*inputfp = nil
 	}
@@ -166,13 +183,13 @@ func FileCreateOpen(fn *string, parse ParseType, inputfp **FileObject, outputfp
 }
 
 // FileCloseDelete closes a file, if it is an output file it can optionally be deleted.
-func FileCloseDelete(fp *FileObject, delet bool, msgs bool) bool {
+func (e *Editor) FileCloseDelete(fp *FileObject, delet bool, msgs bool) bool {
 	if fp != nil {
 		deletFlag := 0
 		if delet {
 			deletFlag = 1
 		}
-		if FilesysClose(fp, deletFlag, msgs) {
+		if e.FilesysClose(fp, deletFlag, msgs) {
 			if fp.FirstLine != nil {
 				var tmp1, tmp2 *LineHdrObject = fp.FirstLine, fp.LastLine
 				LinesDestroy(&tmp1, &tmp2)
@@ -184,15 +201,15 @@ func FileCloseDelete(fp *FileObject, delet bool, msgs bool) bool {
 }
 
 // FileRead reads a series of lines from input file.
-func FileRead(fp *FileObject, count int, bestTry bool, first **LineHdrObject, last **LineHdrObject, actualCnt *int) bool {
+func (e *Editor) FileRead(fp *FileObject, count int, bestTry bool, first **LineHdrObject, last **LineHdrObject, actualCnt *int) bool {
 	if fp.OutputFlag {
-		ScreenMessage(MsgNotInputFile)
+		e.ScreenMessage(MsgNotInputFile)
 		return false
 	}
 
 	var line, line2 *LineHdrObject
 	for count > fp.LineCount && !fp.Eof {
-		buffer := NewBlankStrObject(MaxStrLen)
+		buffer := EmptyStrObject()
 		var outlen int
 		if FilesysRead(fp, buffer, &outlen) {
 			if outlen > 0 {
@@ -225,7 +242,7 @@ func FileRead(fp *FileObject, count int, bestTry bool, first **LineHdrObject, la
 	// Check there are enough lines.
 	if fp.LineCount < count {
 		if !bestTry {
-			ScreenMessage(MsgNotEnoughInputLeft)
+			e.ScreenMessage(MsgNotEnoughInputLeft)
 			return false
 		}
 		count = fp.LineCount
@@ -270,6 +287,78 @@ func FileRead(fp *FileObject, count int, bestTry bool, first **LineHdrObject, la
 	return true
 }
 
+// FileLoad reads all of the text from a reader onto the end of a frame, as
+// if it were the frame's input file being paged in.
+func (e *Editor) FileLoad(frame *FrameObject, r io.Reader) bool {
+	// Loading is not undoable, any more than paging is.
+	e.undoSuspend()
+	defer e.undoResume()
+
+	fp := &FileObject{Valid: true, Reader: r}
+	var firstLine, lastLine *LineHdrObject
+	var count int
+	if !e.FileRead(fp, MaxInt, true, &firstLine, &lastLine, &count) {
+		return false
+	}
+	if firstLine == nil {
+		return true
+	}
+	frame.InputCount += uint32(count)
+	if !e.LinesInject(firstLine, lastLine, frame.LastGroup.LastLine) {
+		return false
+	}
+
+	// If dot was on the null line, shift it onto the first line
+	if frame.Dot.Line.FLink == nil {
+		return MarkCreate(firstLine, frame.Dot.Col, &frame.Dot)
+	}
+	return true
+}
+
+// FileEditFrame creates a frame for a file named on the command line, and
+// opens the file as the frame's input and output as the options say.  The
+// name is kept apart from the options so that it may hold spaces.  The new
+// frame becomes the current frame.
+func (e *Editor) FileEditFrame(frameName string, options []string, fileName string) bool {
+	var inputSlot, outputSlot int
+	var status string
+	if !e.getFreeSlot(&inputSlot, 0, &status) || !e.getFreeSlot(&outputSlot, inputSlot, &status) {
+		e.ScreenMessage(status)
+		return false
+	}
+	if !e.FrameEdit(frameName) {
+		return false
+	}
+	args := append(slices.Clone(options), fileName)
+	if !e.FileCreateOpenArgs(args, ParseCommand, &e.Files[inputSlot], &e.Files[outputSlot]) {
+		return false
+	}
+	if e.Files[inputSlot] != nil {
+		e.CurrentFrame.InputFile = inputSlot
+		e.FilesFrames[inputSlot] = e.CurrentFrame
+	}
+	if e.Files[outputSlot] != nil {
+		e.CurrentFrame.OutputFile = outputSlot
+		e.FilesFrames[outputSlot] = e.CurrentFrame
+	}
+	return e.FilePage(e.CurrentFrame, &e.ExitAbort)
+}
+
+// FileFrameName makes the name of the frame for a file, from the file's
+// name without its directory, in capitals so that commands can name it.
+// The name is cut short to leave room for a <n> suffix that tells apart
+// files with the same name.
+func (e *Editor) FileFrameName(file string) string {
+	base := []rune(strings.ToUpper(filepath.Base(file)))
+	name := string(base[:min(len(base), NameLen)])
+	var span, oldp *SpanObject
+	for n := 2; e.SpanFind(name, &span, &oldp); n++ {
+		suffix := fmt.Sprintf("<%d>", n)
+		name = string(base[:min(len(base), NameLen-len(suffix))]) + suffix
+	}
+	return name
+}
+
 // FileWrite writes a series of lines to an output file.
 func FileWrite(firstLine *LineHdrObject, lastLine *LineHdrObject, fp *FileObject) bool {
 	for firstLine != nil {
@@ -285,17 +374,22 @@ func FileWrite(firstLine *LineHdrObject, lastLine *LineHdrObject, fp *FileObject
 }
 
 // FileWindthru writes all the remaining input file to the output file.
-func FileWindthru(current *FrameObject, fromSpan bool) bool {
+func (e *Editor) FileWindthru(current *FrameObject, fromSpan bool) bool {
 	if current.OutputFile == 0 {
 		return false
 	}
-	if Files[current.OutputFile] == nil {
+	if e.Files[current.OutputFile] == nil {
 		return false
 	}
+	// The frame is emptied, so its undo journal is no longer meaningful.
+	UndoDiscard(current)
+	e.undoSuspend()
+	defer e.undoResume()
+
 	if current.TextModified && !fromSpan {
-		ScreenMessage(MsgWritingFile)
-		if LudwigMode == LudwigScreen {
-			VduFlush()
+		e.ScreenMessage(MsgWritingFile)
+		if e.LudwigMode == LudwigScreen {
+			e.VduFlush()
 		}
 	}
 
@@ -305,14 +399,14 @@ func FileWindthru(current *FrameObject, fromSpan bool) bool {
 
 	if firstLine != nil && lastLine != nil {
 		if current.TextModified {
-			if !FileWrite(firstLine, lastLine, Files[current.OutputFile]) {
+			if !FileWrite(firstLine, lastLine, e.Files[current.OutputFile]) {
 				goto l98
 			}
 		}
 		if !MarksSqueeze(firstLine, 1, lastLine.FLink, 1) {
 			goto l98
 		}
-		if !LinesExtract(firstLine, lastLine) {
+		if !e.LinesExtract(firstLine, lastLine) {
 			goto l98
 		}
 		var tmp1, tmp2 *LineHdrObject = firstLine, lastLine
@@ -320,8 +414,8 @@ func FileWindthru(current *FrameObject, fromSpan bool) bool {
 			goto l98
 		}
 		if current.InputFile != 0 {
-			if Files[current.InputFile] != nil {
-				Files[current.InputFile].LineCount = 0
+			if e.Files[current.InputFile] != nil {
+				e.Files[current.InputFile].LineCount = 0
 			}
 		}
 	}
@@ -329,28 +423,28 @@ func FileWindthru(current *FrameObject, fromSpan bool) bool {
 	result = true
 	if current.TextModified {
 		if current.InputFile != 0 {
-			if Files[current.InputFile] != nil {
-				if !Files[current.InputFile].Eof {
-					buffer := NewBlankStrObject(MaxStrLen)
+			if e.Files[current.InputFile] != nil {
+				if !e.Files[current.InputFile].Eof {
+					buffer := EmptyStrObject()
 					var outlen int
-					for FilesysRead(Files[current.InputFile], buffer, &outlen) {
+					for FilesysRead(e.Files[current.InputFile], buffer, &outlen) {
 						buflen := 0
 						if outlen > 0 {
 							buflen = buffer.Length(' ', outlen)
 						}
-						if !FilesysWrite(Files[current.OutputFile], buffer, buflen) {
+						if !FilesysWrite(e.Files[current.OutputFile], buffer, buflen) {
 							result = false
 							goto l98
 						}
 					}
 				}
-				result = Files[current.InputFile].Eof
+				result = e.Files[current.InputFile].Eof
 			}
 		}
 	}
 l98:
 	if current.TextModified && !fromSpan {
-		ScreenClearMsgs(false)
+		e.ScreenClearMsgs(false)
 	}
 	return result
 }
@@ -371,17 +465,23 @@ func FileRewind(fp **FileObject) bool {
 }
 
 // FilePage handles paging operations.
-func FilePage(currentFrame *FrameObject, exitAbort *bool) bool {
+func (e *Editor) FilePage(currentFrame *FrameObject, exitAbort *bool) bool {
 	var firstLine, lastLine *LineHdrObject
 	if !ExecComputeLineRange(currentFrame, LeadParamNIndef, 0, &firstLine, &lastLine) {
-		ScreenMessage(DbgInternalLogicError)
+		e.ScreenMessage(DbgInternalLogicError)
 		return false
 	}
 
+	// Paging is not undoable.
+	e.undoSuspend()
+	defer e.undoResume()
+
 	// Page out the stuff above the dot line.
 	if firstLine != nil {
+		// Lines are renumbered, so the undo journal is no longer meaningful.
+		UndoDiscard(currentFrame)
 		if currentFrame.OutputFile != 0 &&
-			!FileWrite(firstLine, lastLine, Files[currentFrame.OutputFile]) {
+			!FileWrite(firstLine, lastLine, e.Files[currentFrame.OutputFile]) {
 			*exitAbort = true
 			return false
 		}
@@ -391,7 +491,7 @@ func FilePage(currentFrame *FrameObject, exitAbort *bool) bool {
 		if !MarksSqueeze(firstLine, 1, lastLine.FLink, 1) {
 			return false
 		}
-		if !LinesExtract(firstLine, lastLine) {
+		if !e.LinesExtract(firstLine, lastLine) {
 			return false
 		}
 		var tmp1, tmp2 *LineHdrObject = firstLine, lastLine
@@ -402,36 +502,141 @@ func FilePage(currentFrame *FrameObject, exitAbort *bool) bool {
 
 	// Page in the new lines
 	if currentFrame.InputFile == 0 {
-		goto l98
+		return true
+	}
+	count := -1
+	if e.fileLazy(currentFrame) {
+		count = filePieceLines
 	}
-	for (currentFrame.SpaceLeft*10 > currentFrame.SpaceLimit) && !TtControlC {
+	if !e.filePageIn(currentFrame, count) {
+		return false
+	}
+	e.FileFixEOP(e.Files[currentFrame.InputFile].Eof, currentFrame.LastGroup.LastLine)
+	return true
+}
+
+// filePageIn pages up to count lines of a frame's input file into the end
+// of the frame, or as much as there is space for if count is negative
+func (e *Editor) filePageIn(frame *FrameObject, count int) bool {
+	for count != 0 && (frame.SpaceLeft > frame.SpaceLimit/10) && !e.TtControlC.Load() {
+		lines := 50
+		if count > 0 {
+			lines = min(lines, count)
+			count -= lines
+		}
+		var firstLine, lastLine *LineHdrObject
 		var i int
-		if !FileRead(Files[currentFrame.InputFile], 50, true, &firstLine, &lastLine, &i) {
+		if !e.FileRead(e.Files[frame.InputFile], lines, true, &firstLine, &lastLine, &i) {
 			return false
 		}
-		currentFrame.InputCount += uint32(i)
+		frame.InputCount += uint32(i)
 
 		if firstLine == nil {
-			goto l98
+			break
 		}
-		if !LinesInject(firstLine, lastLine, currentFrame.LastGroup.LastLine) {
+		if !e.LinesInject(firstLine, lastLine, frame.LastGroup.LastLine) {
 			return false
 		}
 
 		// If dot was on the null line, shift it onto the first line
-		if currentFrame.Dot.Line.FLink == nil {
-			if !MarkCreate(firstLine, currentFrame.Dot.Col, &currentFrame.Dot) {
+		if frame.Dot.Line.FLink == nil {
+			if !MarkCreate(firstLine, frame.Dot.Col, &frame.Dot) {
 				return false
 			}
 		}
 	}
-l98:
-	if currentFrame.InputFile != 0 {
-		FileFixEOP(Files[currentFrame.InputFile].Eof, currentFrame.LastGroup.LastLine)
+	return true
+}
+
+// fileLazy returns true if a frame's input file is paged in a piece at a
+// time.  A frame with no limit on its space would otherwise read the whole
+// of a large file before it could be used, so only the first piece is read
+// when the file is opened.  The rest is read while Ludwig waits for a key,
+// as the end of what has been read comes onto the screen, and as commands
+// go past the end of it.
+func (e *Editor) fileLazy(frame *FrameObject) bool {
+	return frame.SpaceLimit == MaxSpace
+}
+
+// FileUnread returns true if a frame's input file is being paged in lazily
+// and has not all been read yet
+func (e *Editor) FileUnread(frame *FrameObject) bool {
+	if frame.InputFile == 0 || e.Files[frame.InputFile] == nil {
+		return false
+	}
+	return e.fileLazy(frame) && !e.Files[frame.InputFile].Eof
+}
+
+// FileReadAhead pages up to count more lines of a frame's input file into
+// the frame, or all of the rest if count is negative
+func (e *Editor) FileReadAhead(frame *FrameObject, count int) bool {
+	if !e.FileUnread(frame) {
+		return true
+	}
+	// Paging is not undoable.
+	e.undoSuspend()
+	defer e.undoResume()
+	if !e.filePageIn(frame, count) {
+		return false
+	}
+	e.FileFixEOP(e.Files[frame.InputFile].Eof, frame.LastGroup.LastLine)
+	return true
+}
+
+// FileReadLines pages in enough of the input file of a line's frame that
+// there are at least count lines after the line, if the file has that many
+func (e *Editor) FileReadLines(line *LineHdrObject, count int) bool {
+	frame := line.Group.Frame
+	if !e.FileUnread(frame) {
+		return true
+	}
+	var lineNr, lastNr int
+	if !LineToNumber(line, &lineNr) || !LineToNumber(frame.LastGroup.LastLine, &lastNr) {
+		return false
+	}
+	// The last line of a frame is the null line
+	if need := lineNr + count - (lastNr - 1); need > 0 {
+		return e.FileReadAhead(frame, need)
 	}
 	return true
 }
 
+// FileNextLine returns the line after a line.  If that is the null line, and
+// there is more of the frame's input file to read, another piece is paged
+// in first, so that a command going forwards through the frame sees all of
+// its text.  New lines go in before the null line, so this must be done
+// before stepping onto it.
+func (e *Editor) FileNextLine(line *LineHdrObject) *LineHdrObject {
+	if next := line.FLink; next != nil && next.FLink == nil {
+		e.FileReadAhead(line.Group.Frame, filePieceLines)
+	}
+	return line.FLink
+}
+
+// FileReadRest pages in the rest of the input files of all frames
+func (e *Editor) FileReadRest() bool {
+	for span := e.FirstSpan; span != nil; span = span.FLink {
+		if span.Frame != nil && !e.FileReadAhead(span.Frame, -1) {
+			return false
+		}
+	}
+	return !e.TtControlC.Load()
+}
+
+// FileIdle pages another piece of input into a frame while Ludwig waits for
+// a key.  Only a frame whose end is not on the screen is read, so that the
+// screen is left alone.  It returns false if there is nothing to read.
+func (e *Editor) FileIdle() bool {
+	for span := e.FirstSpan; span != nil; span = span.FLink {
+		frame := span.Frame
+		if frame != nil && e.FileUnread(frame) && frame.LastGroup.LastLine.ScrRowNr == 0 {
+			e.FileReadAhead(frame, filePieceLines)
+			return true
+		}
+	}
+	return false
+}
+
 func checkSlotAllocation(slot int, mustBeAllocated bool, status *string) bool {
 	if (slot == 0) == mustBeAllocated {
 		if mustBeAllocated {
@@ -444,9 +649,9 @@ func checkSlotAllocation(slot int, mustBeAllocated bool, status *string) bool {
 	return true
 }
 
-func checkSlotUsage(slot int, mustBeInUse bool, status *string) bool {
+func (e *Editor) checkSlotUsage(slot int, mustBeInUse bool, status *string) bool {
 	if checkSlotAllocation(slot, true, status) {
-		if (Files[slot] == nil) == mustBeInUse {
+		if (e.Files[slot] == nil) == mustBeInUse {
 			if mustBeInUse {
 				*status = MsgNoFileOpen
 			} else {
@@ -459,9 +664,9 @@ func checkSlotUsage(slot int, mustBeInUse bool, status *string) bool {
 	return false
 }
 
-func checkSlotDirection(slot int, mustBeOutput bool, status *string) bool {
-	if checkSlotUsage(slot, true, status) {
-		if Files[slot].OutputFlag != mustBeOutput {
+func (e *Editor) checkSlotDirection(slot int, mustBeOutput bool, status *string) bool {
+	if e.checkSlotUsage(slot, true, status) {
+		if e.Files[slot].OutputFlag != mustBeOutput {
 			if mustBeOutput {
 				*status = MsgNotOutputFile
 			} else {
@@ -474,32 +679,32 @@ func checkSlotDirection(slot int, mustBeOutput bool, status *string) bool {
 	return false
 }
 
-func freeFile(slot int, status *string) bool {
+func (e *Editor) freeFile(slot int, status *string) bool {
 	if !checkSlotAllocation(slot, true, status) {
 		return false
 	}
-	if FilesFrames[slot] != nil {
-		if slot == FilesFrames[slot].OutputFile {
-			FilesFrames[slot].OutputFile = 0
+	if e.FilesFrames[slot] != nil {
+		if slot == e.FilesFrames[slot].OutputFile {
+			e.FilesFrames[slot].OutputFile = 0
 		} else {
-			FileFixEOP(true, FilesFrames[slot].LastGroup.LastLine)
-			FilesFrames[slot].InputFile = 0
-		}
-		FilesFrames[slot] = nil
-	} else if slot == FgiFile {
-		FgiFile = 0
-	} else if slot == FgoFile {
-		FgoFile = 0
+			e.FileFixEOP(true, e.FilesFrames[slot].LastGroup.LastLine)
+			e.FilesFrames[slot].InputFile = 0
+		}
+		e.FilesFrames[slot] = nil
+	} else if slot == e.FgiFile {
+		e.FgiFile = 0
+	} else if slot == e.FgoFile {
+		e.FgoFile = 0
 	}
 	return true
 }
 
-func getFreeSlot(newSlot *int, fileSlot int, status *string) bool {
+func (e *Editor) getFreeSlot(newSlot *int, fileSlot int, status *string) bool {
 	slot := 1
-	for slot < MaxFiles && (Files[slot] != nil || slot == fileSlot) {
+	for slot < MaxFiles && (e.Files[slot] != nil || slot == fileSlot) {
 		slot++
 	}
-	if Files[slot] != nil {
+	if e.Files[slot] != nil {
 		*status = MsgNoMoreFilesAllowed
 		return false
 	}
@@ -507,11 +712,11 @@ func getFreeSlot(newSlot *int, fileSlot int, status *string) bool {
 	return true
 }
 
-func getFileName(tparam *TParObject, fnm *string, command Commands) bool {
+func (e *Editor) getFileName(tparam *TParObject, fnm *string, command Commands) bool {
 	tpFileName := TParObject{}
 	tpFileName.Con = nil
 	tpFileName.Nxt = nil
-	if !TparGet1(tparam, command, &tpFileName) {
+	if !e.TparGet1(tparam, command, &tpFileName) {
 		return false
 	}
 	*fnm = tpFileName.Str.Slice(1, tpFileName.Len)
@@ -520,7 +725,7 @@ func getFileName(tparam *TParObject, fnm *string, command Commands) bool {
 }
 
 // FileCommand executes file commands.
-func FileCommand(command Commands, rept LeadParam, count int, tparam *TParObject, fromSpan bool) bool {
+func (e *Editor) FileCommand(command Commands, rept LeadParam, count int, tparam *TParObject, fromSpan bool) bool {
 	savedCmd := command
 	if rept == LeadParamMinus && command != CmdFileWrite {
 		savedCmd = command
@@ -534,215 +739,217 @@ func FileCommand(command Commands, rept LeadParam, count int, tparam *TParObject
 
 	switch command {
 	case CmdFileInput:
-		if !checkSlotAllocation(CurrentFrame.InputFile, false, &status) {
+		if !checkSlotAllocation(e.CurrentFrame.InputFile, false, &status) {
 			goto l99
 		}
-		if !getFreeSlot(&fileSlot, fileSlot, &status) {
+		if !e.getFreeSlot(&fileSlot, fileSlot, &status) {
 			goto l99
 		}
-		if !getFileName(tparam, &fnm, command) {
+		if !e.getFileName(tparam, &fnm, command) {
 			goto l99
 		}
 		var dummyFptr *FileObject
-		if !FileCreateOpen(&fnm, ParseInput, &Files[fileSlot], &dummyFptr) {
+		if !e.FileCreateOpen(&fnm, ParseInput, &e.Files[fileSlot], &dummyFptr) {
 			goto l99
 		}
-		CurrentFrame.InputFile = fileSlot
-		FilesFrames[fileSlot] = CurrentFrame
+		e.CurrentFrame.InputFile = fileSlot
+		e.FilesFrames[fileSlot] = e.CurrentFrame
 		if !fromSpan {
-			ScreenMessage(MsgLoadingFile)
-			if LudwigMode == LudwigScreen {
-				VduFlush()
+			e.ScreenMessage(MsgLoadingFile)
+			if e.LudwigMode == LudwigScreen {
+				e.VduFlush()
 			}
 		}
-		FilePage(CurrentFrame, &ExitAbort)
+		e.FilePage(e.CurrentFrame, &e.ExitAbort)
 		if !fromSpan {
-			ScreenClearMsgs(false)
+			e.ScreenClearMsgs(false)
 		}
 
 	case CmdFileGlobalInput:
-		if !checkSlotAllocation(FgiFile, false, &status) {
+		if !checkSlotAllocation(e.FgiFile, false, &status) {
 			goto l99
 		}
-		if !getFreeSlot(&fileSlot, fileSlot, &status) {
+		if !e.getFreeSlot(&fileSlot, fileSlot, &status) {
 			goto l99
 		}
-		if Files[fileSlot] == nil {
-			if !getFileName(tparam, &fnm, command) {
+		if e.Files[fileSlot] == nil {
+			if !e.getFileName(tparam, &fnm, command) {
 				goto l99
 			}
 			var dummyFptr *FileObject
-			if !FileCreateOpen(&fnm, ParseInput, &Files[fileSlot], &dummyFptr) {
+			if !e.FileCreateOpen(&fnm, ParseInput, &e.Files[fileSlot], &dummyFptr) {
 				goto l99
 			}
 		}
-		FgiFile = fileSlot
+		e.FgiFile = fileSlot
 
 	case CmdFileEdit:
-		if !checkSlotAllocation(CurrentFrame.InputFile, false, &status) {
+		if !checkSlotAllocation(e.CurrentFrame.InputFile, false, &status) {
 			goto l99
 		}
-		if !checkSlotAllocation(CurrentFrame.OutputFile, false, &status) {
+		if !checkSlotAllocation(e.CurrentFrame.OutputFile, false, &status) {
 			goto l99
 		}
-		if !getFreeSlot(&fileSlot, fileSlot, &status) {
+		if !e.getFreeSlot(&fileSlot, fileSlot, &status) {
 			goto l99
 		}
 		var fileSlot2 int
-		if !getFreeSlot(&fileSlot2, fileSlot, &status) {
+		if !e.getFreeSlot(&fileSlot2, fileSlot, &status) {
 			goto l99
 		}
-		if !getFileName(tparam, &fnm, command) {
+		if !e.getFileName(tparam, &fnm, command) {
 			goto l99
 		}
-		if !FileCreateOpen(&fnm, ParseEdit, &Files[fileSlot], &Files[fileSlot2]) {
+		if !e.FileCreateOpen(&fnm, ParseEdit, &e.Files[fileSlot], &e.Files[fileSlot2]) {
 			goto l99
 		}
-		CurrentFrame.InputFile = fileSlot
-		FilesFrames[fileSlot] = CurrentFrame
-		CurrentFrame.OutputFile = fileSlot2
-		FilesFrames[fileSlot2] = CurrentFrame
+		e.CurrentFrame.InputFile = fileSlot
+		e.FilesFrames[fileSlot] = e.CurrentFrame
+		e.CurrentFrame.OutputFile = fileSlot2
+		e.FilesFrames[fileSlot2] = e.CurrentFrame
 		if !fromSpan {
-			ScreenMessage(MsgLoadingFile)
-			if LudwigMode == LudwigScreen {
-				VduFlush()
+			e.ScreenMessage(MsgLoadingFile)
+			if e.LudwigMode == LudwigScreen {
+				e.VduFlush()
 			}
 		}
-		FilePage(CurrentFrame, &ExitAbort)
+		e.FilePage(e.CurrentFrame, &e.ExitAbort)
 		if !fromSpan {
-			ScreenClearMsgs(false)
+			e.ScreenClearMsgs(false)
 		}
 
 	case CmdFileExecute:
-		if !checkSlotAllocation(CurrentFrame.InputFile, false, &status) {
+		if !checkSlotAllocation(e.CurrentFrame.InputFile, false, &status) {
 			goto l99
 		}
-		if !getFreeSlot(&fileSlot, fileSlot, &status) {
+		if !e.getFreeSlot(&fileSlot, fileSlot, &status) {
 			goto l99
 		}
-		if !getFileName(tparam, &fnm, command) {
+		if !e.getFileName(tparam, &fnm, command) {
 			goto l99
 		}
 		var dummyFptr *FileObject
-		if !FileCreateOpen(&fnm, ParseExecute, &Files[fileSlot], &dummyFptr) {
+		if !e.FileCreateOpen(&fnm, ParseExecute, &e.Files[fileSlot], &dummyFptr) {
 			goto l99
 		}
-		CurrentFrame.InputFile = fileSlot
-		FilesFrames[fileSlot] = CurrentFrame
-		FilePage(CurrentFrame, &ExitAbort)
-		if !freeFile(fileSlot, &status) {
+		e.CurrentFrame.InputFile = fileSlot
+		e.FilesFrames[fileSlot] = e.CurrentFrame
+		e.FilePage(e.CurrentFrame, &e.ExitAbort)
+		// The file is closed at once, so all of it is wanted now
+		e.FileReadAhead(e.CurrentFrame, -1)
+		if !e.freeFile(fileSlot, &status) {
 			goto l99
 		}
-		if !FileCloseDelete(Files[fileSlot], true, false) {
+		if !e.FileCloseDelete(e.Files[fileSlot], true, false) {
 			goto l99
 		}
 
 	case CmdFileClose:
 		switch savedCmd {
 		case CmdFileInput:
-			fileSlot = CurrentFrame.InputFile
+			fileSlot = e.CurrentFrame.InputFile
 		case CmdFileOutput:
-			fileSlot = CurrentFrame.OutputFile
+			fileSlot = e.CurrentFrame.OutputFile
 		case CmdFileGlobalInput:
-			fileSlot = FgiFile
+			fileSlot = e.FgiFile
 		case CmdFileGlobalOutput:
-			fileSlot = FgoFile
+			fileSlot = e.FgoFile
 		case CmdFileEdit:
-			fileSlot = CurrentFrame.InputFile
+			fileSlot = e.CurrentFrame.InputFile
 		}
 		if savedCmd == CmdFileOutput || savedCmd == CmdFileEdit {
-			if !FileWindthru(CurrentFrame, fromSpan) {
+			if !e.FileWindthru(e.CurrentFrame, fromSpan) {
 				goto l99
 			}
-			ScreenFixup()
+			e.ScreenFixup()
 		}
-		if !freeFile(fileSlot, &status) {
+		if !e.freeFile(fileSlot, &status) {
 			goto l99
 		}
 		if savedCmd == CmdFileGlobalInput || savedCmd == CmdFileGlobalOutput {
-			if !FileCloseDelete(Files[fileSlot], false, true) {
+			if !e.FileCloseDelete(e.Files[fileSlot], false, true) {
 				goto l99
 			}
 		} else {
-			if !FileCloseDelete(Files[fileSlot], !CurrentFrame.TextModified,
-				CurrentFrame.TextModified || !Files[fileSlot].OutputFlag) {
+			if !e.FileCloseDelete(e.Files[fileSlot], !e.CurrentFrame.TextModified,
+				e.CurrentFrame.TextModified || !e.Files[fileSlot].OutputFlag) {
 				goto l99
 			}
 		}
 		if savedCmd == CmdFileEdit {
-			fileSlot = CurrentFrame.OutputFile
-			if !freeFile(fileSlot, &status) {
+			fileSlot = e.CurrentFrame.OutputFile
+			if !e.freeFile(fileSlot, &status) {
 				goto l99
 			}
-			if !FileCloseDelete(Files[fileSlot], !CurrentFrame.TextModified, CurrentFrame.TextModified) {
+			if !e.FileCloseDelete(e.Files[fileSlot], !e.CurrentFrame.TextModified, e.CurrentFrame.TextModified) {
 				goto l99
 			}
 		}
 		if savedCmd == CmdFileOutput || savedCmd == CmdFileEdit {
-			CurrentFrame.TextModified = false
+			e.CurrentFrame.TextModified = false
 		}
 
 	case CmdFileKill:
-		fileSlot = CurrentFrame.OutputFile
-		if !freeFile(fileSlot, &status) {
+		fileSlot = e.CurrentFrame.OutputFile
+		if !e.freeFile(fileSlot, &status) {
 			goto l99
 		}
-		if !FileCloseDelete(Files[fileSlot], true, true) {
+		if !e.FileCloseDelete(e.Files[fileSlot], true, true) {
 			goto l99
 		}
 
 	case CmdFileGlobalKill:
-		fileSlot = FgoFile
-		if !freeFile(fileSlot, &status) {
+		fileSlot = e.FgoFile
+		if !e.freeFile(fileSlot, &status) {
 			goto l99
 		}
-		if !FileCloseDelete(Files[fileSlot], true, true) {
+		if !e.FileCloseDelete(e.Files[fileSlot], true, true) {
 			goto l99
 		}
 
 	case CmdFileOutput:
-		if !checkSlotAllocation(CurrentFrame.OutputFile, false, &status) {
+		if !checkSlotAllocation(e.CurrentFrame.OutputFile, false, &status) {
 			goto l99
 		}
-		if !getFreeSlot(&fileSlot, fileSlot, &status) {
+		if !e.getFreeSlot(&fileSlot, fileSlot, &status) {
 			goto l99
 		}
-		if !getFileName(tparam, &fnm, command) {
+		if !e.getFileName(tparam, &fnm, command) {
 			goto l99
 		}
-		if CurrentFrame.InputFile != 0 {
-			if !FileCreateOpen(&fnm, ParseOutput, &Files[CurrentFrame.InputFile], &Files[fileSlot]) {
+		if e.CurrentFrame.InputFile != 0 {
+			if !e.FileCreateOpen(&fnm, ParseOutput, &e.Files[e.CurrentFrame.InputFile], &e.Files[fileSlot]) {
 				goto l99
 			}
 		} else {
 			var dummyFptr *FileObject
-			if !FileCreateOpen(&fnm, ParseOutput, &dummyFptr, &Files[fileSlot]) {
+			if !e.FileCreateOpen(&fnm, ParseOutput, &dummyFptr, &e.Files[fileSlot]) {
 				goto l99
 			}
 		}
-		CurrentFrame.OutputFile = fileSlot
-		FilesFrames[fileSlot] = CurrentFrame
+		e.CurrentFrame.OutputFile = fileSlot
+		e.FilesFrames[fileSlot] = e.CurrentFrame
 
 	case CmdFileGlobalOutput:
-		if !checkSlotAllocation(FgoFile, false, &status) {
+		if !checkSlotAllocation(e.FgoFile, false, &status) {
 			goto l99
 		}
-		if !getFreeSlot(&fileSlot, fileSlot, &status) {
+		if !e.getFreeSlot(&fileSlot, fileSlot, &status) {
 			goto l99
 		}
-		if Files[fileSlot] == nil {
-			if !getFileName(tparam, &fnm, command) {
+		if e.Files[fileSlot] == nil {
+			if !e.getFileName(tparam, &fnm, command) {
 				goto l99
 			}
 			var dummyFptr *FileObject
-			if !FileCreateOpen(&fnm, ParseOutput, &dummyFptr, &Files[fileSlot]) {
+			if !e.FileCreateOpen(&fnm, ParseOutput, &dummyFptr, &e.Files[fileSlot]) {
 				goto l99
 			}
 		}
-		FgoFile = fileSlot
+		e.FgoFile = fileSlot
 
 	case CmdFileRead:
-		if !checkSlotAllocation(FgiFile, true, &status) {
+		if !checkSlotAllocation(e.FgiFile, true, &status) {
 			goto l99
 		}
 		linesToRead := count
@@ -751,108 +958,108 @@ func FileCommand(command Commands, rept LeadParam, count int, tparam *TParObject
 		}
 		var first, last *LineHdrObject
 		var i int
-		if !FileRead(Files[FgiFile], linesToRead, rept == LeadParamPIndef, &first, &last, &i) {
+		if !e.FileRead(e.Files[e.FgiFile], linesToRead, rept == LeadParamPIndef, &first, &last, &i) {
 			goto l99
 		}
 		if first != nil {
-			if !LinesInject(first, last, CurrentFrame.Dot.Line) {
+			if !e.LinesInject(first, last, e.CurrentFrame.Dot.Line) {
 				goto l99
 			}
-			if !MarkCreate(first, 1, &CurrentFrame.Marks[MarkEquals]) {
+			if !MarkCreate(first, 1, &e.CurrentFrame.Marks[MarkEquals]) {
 				goto l99
 			}
-			CurrentFrame.TextModified = true
-			if !MarkCreate(last.FLink, 1, &CurrentFrame.Marks[MarkModified]) {
+			e.CurrentFrame.TextModified = true
+			if !MarkCreate(last.FLink, 1, &e.CurrentFrame.Marks[MarkModified]) {
 				goto l99
 			}
-			if !MarkCreate(last.FLink, 1, &CurrentFrame.Dot) {
+			if !MarkCreate(last.FLink, 1, &e.CurrentFrame.Dot) {
 				goto l99
 			}
 		}
 
 	case CmdFileWrite:
-		if !checkSlotAllocation(FgoFile, true, &status) {
+		if !checkSlotAllocation(e.FgoFile, true, &status) {
 			goto l99
 		}
 		if !fromSpan {
-			ScreenMessage(MsgWritingFile)
-			if LudwigMode == LudwigScreen {
-				VduFlush()
+			e.ScreenMessage(MsgWritingFile)
+			if e.LudwigMode == LudwigScreen {
+				e.VduFlush()
 			}
 		}
 		var first, last *LineHdrObject
-		if !ExecComputeLineRange(CurrentFrame, rept, count, &first, &last) {
+		if !ExecComputeLineRange(e.CurrentFrame, rept, count, &first, &last) {
 			goto l99
 		}
 		if first != nil {
-			if !FileWrite(first, last, Files[FgoFile]) {
+			if !FileWrite(first, last, e.Files[e.FgoFile]) {
 				goto l99
 			}
 		}
 		if !fromSpan {
-			ScreenClearMsgs(false)
+			e.ScreenClearMsgs(false)
 		}
 
 	case CmdFileRewind:
-		if !checkSlotDirection(CurrentFrame.InputFile, false, &status) {
+		if !e.checkSlotDirection(e.CurrentFrame.InputFile, false, &status) {
 			goto l99
 		}
-		if !FileRewind(&Files[CurrentFrame.InputFile]) {
+		if !FileRewind(&e.Files[e.CurrentFrame.InputFile]) {
 			goto l99
 		}
 		if !fromSpan {
-			ScreenMessage(MsgLoadingFile)
-			if LudwigMode == LudwigScreen {
-				VduFlush()
+			e.ScreenMessage(MsgLoadingFile)
+			if e.LudwigMode == LudwigScreen {
+				e.VduFlush()
 			}
 		}
-		FilePage(CurrentFrame, &ExitAbort)
+		e.FilePage(e.CurrentFrame, &e.ExitAbort)
 		if !fromSpan {
-			ScreenClearMsgs(false)
+			e.ScreenClearMsgs(false)
 		}
 
 	case CmdFileGlobalRewind:
-		if !checkSlotDirection(FgiFile, false, &status) {
+		if !e.checkSlotDirection(e.FgiFile, false, &status) {
 			goto l99
 		}
-		if !FileRewind(&Files[FgiFile]) {
+		if !FileRewind(&e.Files[e.FgiFile]) {
 			goto l99
 		}
 
 	case CmdFileSave:
-		if CurrentFrame.OutputFile == 0 {
+		if e.CurrentFrame.OutputFile == 0 {
 			status = MsgNoOutput
 			goto l99
 		}
-		if !CurrentFrame.TextModified {
+		if !e.CurrentFrame.TextModified {
 			if !fromSpan {
-				ScreenMessage(MsgNotModified)
-				if LudwigMode == LudwigScreen {
-					VduFlush()
+				e.ScreenMessage(MsgNotModified)
+				if e.LudwigMode == LudwigScreen {
+					e.VduFlush()
 				}
 			}
 			result = true
 			goto l99
 		}
 		if !fromSpan {
-			ScreenMessage(MsgSavingFile)
-			if LudwigMode == LudwigScreen {
-				VduFlush()
+			e.ScreenMessage(MsgSavingFile)
+			if e.LudwigMode == LudwigScreen {
+				e.VduFlush()
 			}
 		}
-		linesWritten := Files[CurrentFrame.OutputFile].LCounter
-		first := CurrentFrame.FirstGroup.FirstLine
-		last := CurrentFrame.LastGroup.LastLine.BLink
+		linesWritten := e.Files[e.CurrentFrame.OutputFile].LCounter
+		first := e.CurrentFrame.FirstGroup.FirstLine
+		last := e.CurrentFrame.LastGroup.LastLine.BLink
 		if last != nil {
-			if !FileWrite(first, last, Files[CurrentFrame.OutputFile]) {
+			if !FileWrite(first, last, e.Files[e.CurrentFrame.OutputFile]) {
 				goto l99
 			}
 		}
 		var dummyFptr *FileObject
-		if CurrentFrame.InputFile != 0 {
-			dummyFptr = Files[CurrentFrame.InputFile]
+		if e.CurrentFrame.InputFile != 0 {
+			dummyFptr = e.Files[e.CurrentFrame.InputFile]
 		}
-		if !FilesysSave(dummyFptr, Files[CurrentFrame.OutputFile], linesWritten) {
+		if !e.FilesysSave(dummyFptr, e.Files[e.CurrentFrame.OutputFile], linesWritten) {
 			goto l99
 		}
 		var nrLines int
@@ -861,18 +1068,18 @@ func FileCommand(command Commands, rept LeadParam, count int, tparam *TParObject
 		} else if !LineToNumber(last, &nrLines) {
 			nrLines = 0
 		}
-		CurrentFrame.InputCount = uint32(Files[CurrentFrame.OutputFile].LCounter + nrLines)
-		if CurrentFrame.InputFile != 0 {
-			Files[CurrentFrame.InputFile].LCounter = int(CurrentFrame.InputCount)
+		e.CurrentFrame.InputCount = uint32(e.Files[e.CurrentFrame.OutputFile].LCounter + nrLines)
+		if e.CurrentFrame.InputFile != 0 {
+			e.Files[e.CurrentFrame.InputFile].LCounter = int(e.CurrentFrame.InputCount)
 		}
-		CurrentFrame.TextModified = false
+		e.CurrentFrame.TextModified = false
 	}
 
 	result = true
 
 l99:
 	if status != "" {
-		ScreenMessage(status)
+		e.ScreenMessage(status)
 	}
 	return result
 }
@@ -0,0 +1,102 @@
+// Tests for fyle.go functions
+
+package ludwig
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileFrameName(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	assert.Equal(t, "MAIN.GO", e.FileFrameName("/src/main.go"))
+
+	// Long names are cut by characters, and a suffix always fits
+	long := "/src/" + strings.Repeat("é", NameLen+5) + ".go"
+	name := e.FileFrameName(long)
+	assert.Equal(t, strings.Repeat("É", NameLen), name)
+	for n := 2; n <= 12; n++ {
+		require.True(t, e.FrameEdit(name), messages.String())
+		name = e.FileFrameName(long)
+		require.True(t, utf8.ValidString(name))
+		assert.Equal(t, NameLen, utf8.RuneCountInString(name))
+	}
+	assert.True(t, strings.HasSuffix(name, "<12>"))
+}
+
+func TestFilePageLazy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "large.txt")
+	var text strings.Builder
+	for i := 1; i <= 2*filePieceLines+5; i++ {
+		fmt.Fprintf(&text, "line %d\n", i)
+	}
+	require.NoError(t, os.WriteFile(path, []byte(text.String()), 0644))
+
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	lines := func() int {
+		var n int
+		LineToNumber(e.CurrentFrame.LastGroup.LastLine, &n)
+		return n - 1
+	}
+
+	// In screen mode only the first piece is read when the file is opened
+	e.LudwigMode = LudwigScreen
+	require.True(t, e.FileEditFrame("LARGE", nil, path), messages.String())
+	assert.Equal(t, filePieceLines, lines())
+	assert.True(t, e.FileUnread(e.CurrentFrame))
+	assert.Equal(t, "<Page Boundary>", strings.TrimSpace(e.CurrentFrame.LastGroup.LastLine.Str.Slice(1, 15)))
+
+	// More is read while waiting for a key
+	assert.True(t, e.FileIdle())
+	assert.Equal(t, 2*filePieceLines, lines())
+
+	// Editing near Dot does not need the rest of the file
+	require.True(t, e.Execute(CmdRight, LeadParamNone, 1, nil, true))
+	require.True(t, e.Execute(CmdMark, LeadParamNone, 1, nil, true), messages.String())
+	require.True(t, e.BatchExecute("i/top/ 5a k"), messages.String())
+	assert.Equal(t, 2*filePieceLines-1, lines())
+
+	// Going further down reads as far as it has to, > reads the rest
+	require.True(t, e.BatchExecute(fmt.Sprintf("%da", 2*filePieceLines-5)), messages.String())
+	assert.Equal(t, 2*filePieceLines+2, lines())
+	assert.True(t, e.FileUnread(e.CurrentFrame))
+	require.True(t, e.BatchExecute(">a"), messages.String())
+	assert.Equal(t, 2*filePieceLines+4, lines())
+	assert.False(t, e.FileUnread(e.CurrentFrame))
+	assert.False(t, e.FileIdle())
+	assert.Equal(t, "<End of File>", strings.TrimSpace(e.CurrentFrame.LastGroup.LastLine.Str.Slice(1, 15)))
+
+	// In batch mode too, and a search reads on until it finds its target
+	e.LudwigMode = LudwigBatch
+	for _, tt := range []struct {
+		command string
+		lineNr  int
+	}{
+		{fmt.Sprintf("g/line %d/", 2*filePieceLines+5), 2*filePieceLines + 5},
+		{fmt.Sprintf("g~^line %d$~", filePieceLines+1), filePieceLines + 1},
+		{"g/nowhere/", 0},
+	} {
+		require.True(t, e.FileEditFrame(fmt.Sprintf("BATCH%d", tt.lineNr), nil, path), messages.String())
+		assert.Equal(t, filePieceLines, lines())
+		if tt.lineNr == 0 {
+			assert.False(t, e.BatchExecute(tt.command))
+			assert.False(t, e.FileUnread(e.CurrentFrame))
+			continue
+		}
+		require.True(t, e.BatchExecute(tt.command), messages.String())
+		var dotNr int
+		LineToNumber(e.CurrentFrame.Dot.Line, &dotNr)
+		assert.Equal(t, tt.lineNr, dotNr, tt.command)
+	}
+}
@@ -28,20 +28,20 @@ func toUpper(s string) string {
 }
 
 // askUser prompts the user for input and returns the reply in uppercase
-func askUser(prompt string) string {
-	ScreenWriteln()
-	reply := ScreenHelpPrompt(prompt)
+func (e *Editor) askUser(prompt string) string {
+	e.ScreenWriteln()
+	reply := e.ScreenHelpPrompt(prompt)
 	// Note that all characters not overwritten by the user will be spaces!
-	ScreenWriteln()
+	e.ScreenWriteln()
 	reply = toUpper(reply)
 	return reply
 }
 
 // HelpHelp displays the help system
 // The argument selects a particular part of the help file to read e.g. SD
-func HelpHelp(selection string) {
-	ScreenUnload()
-	ScreenHome(true)
+func (e *Editor) HelpHelp(selection string) {
+	e.ScreenUnload()
+	e.ScreenHome(true)
 
 	var topic string
 	if selection == "" {
@@ -50,27 +50,27 @@ func HelpHelp(selection string) {
 		topic = selection
 	}
 
-	if !HelpfileOpen(FileData.OldCmds) {
-		ScreenWriteStr(3, "Can't open HELP file")
-		ScreenWriteln()
-		ScreenPause()
+	if !e.HelpfileOpen(e.FileData.OldCmds) {
+		e.ScreenWriteStr(3, "Can't open HELP file")
+		e.ScreenWriteln()
+		e.ScreenPause()
 		topic = ""
 	}
 
 	for topic != "" {
-		ScreenHome(true)
+		e.ScreenHome(true)
 		var buf HelpRecord
-		continu := HelpfileRead(topic, &buf)
+		continu := e.HelpfileRead(topic, &buf)
 		if !continu {
-			ScreenWriteStr(3, "Can't find Command or Section in HELP")
-			ScreenWriteln()
+			e.ScreenWriteStr(3, "Can't find Command or Section in HELP")
+			e.ScreenWriteln()
 			topic = ""
 		}
 
 		for continu {
 			if len(buf.Txt) >= 2 && buf.Txt[0] == '\\' && buf.Txt[1] == '%' {
-				reply := askUser("<space> for more, <return> to exit : ")
-				if TtControlC {
+				reply := e.askUser("<space> for more, <return> to exit : ")
+				if e.TtControlC.Load() {
 					reply = ""
 				}
 				if reply == "" || reply[0] != ' ' {
@@ -78,28 +78,28 @@ func HelpHelp(selection string) {
 					topic = reply
 				}
 			} else {
-				ScreenWriteStr(2, buf.Txt)
-				ScreenWriteln()
+				e.ScreenWriteStr(2, buf.Txt)
+				e.ScreenWriteln()
 			}
 
 			if continu {
-				continu = HelpfileNext(&buf) && buf.Key == topic
+				continu = e.HelpfileNext(&buf) && buf.Key == topic
 				if !continu {
 					topic = ""
 				}
 			}
 
-			if TtControlC {
+			if e.TtControlC.Load() {
 				continu = false
 			}
 		}
 
 		if topic == "" || (len(topic) > 0 && topic[0] == ' ') {
-			topic = askUser("Command or Section or <return> to exit : ")
+			topic = e.askUser("Command or Section or <return> to exit : ")
 			if topic != "" && topic[0] == ' ' {
 				topic = helpIndex
 			}
-			if TtControlC {
+			if e.TtControlC.Load() {
 				topic = ""
 			}
 		}
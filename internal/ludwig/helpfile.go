@@ -45,49 +45,42 @@ type keyType struct {
 	key      string
 }
 
-var (
-	helpSeeker io.ReadSeeker
-	helpReader *bufio.Reader
-	table      map[string]keyType
-	currentKey keyType
-)
-
 // helpOpenFile opens a help file
-func helpOpenFile(filename string) bool {
+func (e *Editor) helpOpenFile(filename string) bool {
 	var err error
-	helpSeeker, err = os.Open(filename)
+	e.helpSeeker, err = os.Open(filename)
 	if err != nil {
 		return false
 	}
-	helpReader = bufio.NewReader(helpSeeker)
+	e.helpReader = bufio.NewReader(e.helpSeeker)
 	return true
 }
 
-func helpOpenEmbedded(data []byte) bool {
-	helpSeeker = bytes.NewReader(data)
-	helpReader = bufio.NewReader(helpSeeker)
+func (e *Editor) helpOpenEmbedded(data []byte) bool {
+	e.helpSeeker = bytes.NewReader(data)
+	e.helpReader = bufio.NewReader(e.helpSeeker)
 	return true
 }
 
-func helpTryOpenFile(envName string, defaultFilename string, embeddedData []byte) bool {
+func (e *Editor) helpTryOpenFile(envName string, defaultFilename string, embeddedData []byte) bool {
 	// First try environment variable (for development/override)
 	helpPath := os.Getenv(envName)
-	if helpPath != "" && helpOpenFile(helpPath) {
+	if helpPath != "" && e.helpOpenFile(helpPath) {
 		return true
 	}
 	// Then try embedded data
 	if len(embeddedData) > 0 {
-		return helpOpenEmbedded(embeddedData)
+		return e.helpOpenEmbedded(embeddedData)
 	}
 	// Fallback to filesystem
-	return helpOpenFile(defaultFilename)
+	return e.helpOpenFile(defaultFilename)
 }
 
 // readIndex reads the help file index
-func readIndex() bool {
+func (e *Editor) readIndex() bool {
 	// Read in the size of the index, and the size in lines of the contents
 	// Both values are on the same line, space-separated
-	firstLine, err := helpReader.ReadString('\n')
+	firstLine, err := e.helpReader.ReadString('\n')
 	if err != nil {
 		return false
 	}
@@ -105,9 +98,9 @@ func readIndex() bool {
 	}
 
 	// Read in indexSize keys
-	table = make(map[string]keyType)
+	e.table = make(map[string]keyType)
 	for range indexSize {
-		indexLine, err := helpReader.ReadString('\n')
+		indexLine, err := e.helpReader.ReadString('\n')
 		if err != nil {
 			return false
 		}
@@ -128,7 +121,7 @@ func readIndex() bool {
 			return false
 		}
 
-		table[k.key] = k
+		e.table[k.key] = k
 	}
 
 	// The key "0" is special, it's the contents page, it does NOT appear
@@ -137,119 +130,119 @@ func readIndex() bool {
 	// bulk of the entries.
 	var contents keyType
 	contents.key = "0"
-	currentPos, err := helpSeeker.Seek(0, io.SeekCurrent)
+	currentPos, err := e.helpSeeker.Seek(0, io.SeekCurrent)
 	if err != nil {
 		return false
 	}
 	// Account for buffered data
-	currentPos -= int64(helpReader.Buffered())
+	currentPos -= int64(e.helpReader.Buffered())
 	contents.startPos = currentPos
 
 	for range contentsLines {
-		_, err := helpReader.ReadString('\n')
+		_, err := e.helpReader.ReadString('\n')
 		if err != nil {
 			return false
 		}
 	}
 
-	currentPos, err = helpSeeker.Seek(0, io.SeekCurrent)
+	currentPos, err = e.helpSeeker.Seek(0, io.SeekCurrent)
 	if err != nil {
 		return false
 	}
-	currentPos -= int64(helpReader.Buffered())
+	currentPos -= int64(e.helpReader.Buffered())
 	contents.endPos = currentPos
 
 	// Correct the positions in the index to point at the real offset in the file
-	for key, entry := range table {
+	for key, entry := range e.table {
 		entry.startPos += contents.endPos
 		entry.endPos += contents.endPos
-		table[key] = entry
+		e.table[key] = entry
 	}
 
 	// Add the contents to the table
-	table[contents.key] = contents
+	e.table[contents.key] = contents
 
 	return true
 }
 
 // HelpfileClose closes the help file and clears the index
-func HelpfileClose() {
-	if helpSeeker != nil {
-		if closer, ok := helpSeeker.(io.Closer); ok {
+func (e *Editor) HelpfileClose() {
+	if e.helpSeeker != nil {
+		if closer, ok := e.helpSeeker.(io.Closer); ok {
 			closer.Close()
 		}
-		helpSeeker = nil
-		helpReader = nil
+		e.helpSeeker = nil
+		e.helpReader = nil
 	}
-	currentKey.key = ""
-	table = nil
+	e.currentKey.key = ""
+	e.table = nil
 }
 
 // HelpfileOpen opens a help file (old or new version)
-func HelpfileOpen(oldVersion bool) bool {
-	if helpSeeker != nil { // we have done this before, don't do it again!
+func (e *Editor) HelpfileOpen(oldVersion bool) bool {
+	if e.helpSeeker != nil { // we have done this before, don't do it again!
 		return true
 	}
 	if oldVersion {
-		if !helpTryOpenFile(oldHelpfileEnv, oldDefaultHlpFile, oldHelpFileData) {
+		if !e.helpTryOpenFile(oldHelpfileEnv, oldDefaultHlpFile, oldHelpFileData) {
 			return false
 		}
 	} else {
-		if !helpTryOpenFile(newHelpfileEnv, newDefaultHlpFile, newHelpFileData) {
+		if !e.helpTryOpenFile(newHelpfileEnv, newDefaultHlpFile, newHelpFileData) {
 			return false
 		}
 	}
-	return readIndex()
+	return e.readIndex()
 }
 
 // HelpfileRead reads the first line of a help entry for the given key
-func HelpfileRead(keystr string, buffer *HelpRecord) bool {
-	currentKey.key = keystr
+func (e *Editor) HelpfileRead(keystr string, buffer *HelpRecord) bool {
+	e.currentKey.key = keystr
 
-	entry, found := table[currentKey.key]
+	entry, found := e.table[e.currentKey.key]
 	if !found {
 		return false
 	}
 
 	// Find the offset in the helpfile for the entry and go there now
-	currentKey = entry
-	_, err := helpSeeker.Seek(currentKey.startPos, io.SeekStart)
+	e.currentKey = entry
+	_, err := e.helpSeeker.Seek(e.currentKey.startPos, io.SeekStart)
 	if err != nil {
 		return false
 	}
 	// Need to create a new reader after seeking
-	helpReader = bufio.NewReader(helpSeeker)
+	e.helpReader = bufio.NewReader(e.helpSeeker)
 
 	// Get the first line in the entry and package it up in a HelpRecord
-	line, err := helpReader.ReadString('\n')
+	line, err := e.helpReader.ReadString('\n')
 	if err != nil && line == "" {
 		return false
 	}
 	buffer.Txt = strings.TrimRight(line, "\n")
-	buffer.Key = currentKey.key
+	buffer.Key = e.currentKey.key
 	return true
 }
 
 // HelpfileNext reads the next line of the current help entry
-func HelpfileNext(buffer *HelpRecord) bool {
+func (e *Editor) HelpfileNext(buffer *HelpRecord) bool {
 	// if the current position >= the end of the entry return false,
 	// else give back the next line nicely packaged.
-	currentPos, err := helpSeeker.Seek(0, io.SeekCurrent)
+	currentPos, err := e.helpSeeker.Seek(0, io.SeekCurrent)
 	if err != nil {
 		return false
 	}
 	// Account for buffered data
-	currentPos -= int64(helpReader.Buffered())
+	currentPos -= int64(e.helpReader.Buffered())
 
-	if currentPos >= currentKey.endPos {
+	if currentPos >= e.currentKey.endPos {
 		return false
 	}
 
-	line, err := helpReader.ReadString('\n')
+	line, err := e.helpReader.ReadString('\n')
 	if err != nil && line == "" {
 		return false
 	}
 	buffer.Txt = strings.TrimRight(line, "\n")
-	buffer.Key = currentKey.key
+	buffer.Key = e.currentKey.key
 	return true
 }
@@ -0,0 +1,224 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         ISEARCH
+//
+// Description:  The UG command, an incremental Get.  The target is
+//               searched for as it is typed, and the match is highlighted.
+
+package ludwig
+
+const (
+	isearchPrompt        = "I-Get  :"
+	isearchFailingPrompt = "Failing I-Get  :"
+
+	isearchNext     = 0x0e // Control-N goes on to the next match
+	isearchPrevious = 0x10 // Control-P goes back to the previous match
+)
+
+// GetIncremental is the UG command.  As each character of the target is
+// typed Dot goes to the first match from where the search started, or the
+// last match before it with a leading -.  Control-N and Control-P go on to
+// the next and previous matches, RETURN ends the search at the match and
+// ESCAPE goes back to where the search started.  A target starting with `
+// is a pattern, with " is matched in exact case and with ~ is a regular
+// expression, as for G.
+func (e *Editor) GetIncremental(rept LeadParam) bool {
+	if e.LudwigMode != LudwigScreen {
+		e.ScreenMessage(MsgInteractiveModeOnly)
+		return false
+	}
+	frame := e.CurrentFrame
+	startLine, startCol := frame.Dot.Line, frame.Dot.Col
+	var oldEquals *MarkObject
+	if frame.Marks[MarkEquals] != nil {
+		if !MarkCreate(frame.Marks[MarkEquals].Line, frame.Marks[MarkEquals].Col, &oldEquals) {
+			return false
+		}
+		defer MarkDestroy(&oldEquals)
+	}
+
+	count := 1
+	if rept == LeadParamMinus {
+		count = -1
+	}
+	var target []rune
+	found := false
+	result := false
+	for done := false; !done; {
+		e.ScreenFixup()
+		e.isearchShow(found)
+		prompt := isearchPrompt
+		if !found && len(target) > 0 {
+			prompt = isearchFailingPrompt
+		}
+		e.ScreenMessage(prompt + string(target))
+		e.VduMoveCurs(e.screenDotCol(), frame.Dot.Line.ScrRowNr)
+		key := e.VduGetKey()
+		e.ScreenClearMsgs(false)
+		if e.TtControlC.Load() {
+			key = ESC
+		}
+
+		switch key {
+		case CR, NL:
+			result = found
+			done = true
+		case ESC:
+			MarkCreate(startLine, startCol, &frame.Dot)
+			if oldEquals != nil {
+				MarkCreate(oldEquals.Line, oldEquals.Col, &frame.Marks[MarkEquals])
+			} else if frame.Marks[MarkEquals] != nil {
+				MarkDestroy(&frame.Marks[MarkEquals])
+			}
+			done = true
+		case isearchNext, isearchPrevious:
+			count = 1
+			if key == isearchPrevious {
+				count = -1
+			}
+			if !found {
+				found = e.isearchFind(target, count, startLine, startCol)
+			} else if line, start, end := e.isearchMatch(); count > 0 {
+				if !e.isearchFind(target, count, line, end) {
+					e.ScreenBeep()
+				}
+			} else if !e.isearchFind(target, count, line, start) {
+				e.ScreenBeep()
+			}
+		case BS, DEL:
+			if len(target) == 0 {
+				e.ScreenBeep()
+				break
+			}
+			target = target[:len(target)-1]
+			if len(target) == 0 {
+				MarkCreate(startLine, startCol, &frame.Dot)
+				found = false
+			} else {
+				found = e.isearchFind(target, count, startLine, startCol)
+			}
+		default:
+			ch, ok := KeyToCh(key)
+			if !ok || controlChars[key] {
+				e.ScreenBeep()
+				break
+			}
+			target = append(target, ch)
+			// The match can grow where it is, so look again from its start,
+			// or backwards from a character past its end
+			line, col := startLine, startCol
+			if found {
+				var start, end int
+				line, start, end = e.isearchMatch()
+				col = start
+				if count < 0 {
+					col = end + 1
+				}
+			}
+			found = e.isearchFind(target, count, line, col)
+		}
+	}
+	e.isearchShow(false)
+
+	if result {
+		frame.GetTpar, _ = isearchTarget(target)
+	}
+	return result
+}
+
+// isearchTarget makes the target typed so far into a trailing parameter for
+// G, returning false if there is nothing to search for yet
+func isearchTarget(target []rune) (TParObject, bool) {
+	dlm := byte(TpdLit)
+	if len(target) > 0 && (target[0] == TpdSmart || target[0] == TpdExact || target[0] == TpdRegexp) {
+		dlm = byte(target[0])
+		target = target[1:]
+		if len(target) > 0 && target[len(target)-1] == rune(dlm) {
+			target = target[:len(target)-1]
+		}
+	}
+	if len(target) == 0 {
+		return TParObject{}, false
+	}
+	return TParObject{Len: len(target), Dlm: dlm, Str: NewStrObjectRunes(target)}, true
+}
+
+// isearchFind looks for the target from a position, forwards or backwards
+// as count says.  Dot and Equals are left where they were if there is no
+// match.
+func (e *Editor) isearchFind(target []rune, count int, line *LineHdrObject, col int) bool {
+	tpar, ok := isearchTarget(target)
+	if !ok {
+		return false
+	}
+	frame := e.CurrentFrame
+	var oldDot, oldEquals *MarkObject
+	if !MarkCreate(frame.Dot.Line, frame.Dot.Col, &oldDot) {
+		return false
+	}
+	defer MarkDestroy(&oldDot)
+	if frame.Marks[MarkEquals] != nil {
+		if !MarkCreate(frame.Marks[MarkEquals].Line, frame.Marks[MarkEquals].Col, &oldEquals) {
+			return false
+		}
+		defer MarkDestroy(&oldEquals)
+	}
+
+	found := MarkCreate(line, col, &frame.Dot) && e.EqsGetRepGet(count, tpar, true)
+	// A target that is not yet a whole pattern is not worth a message
+	e.ScreenClearMsgs(false)
+	if !found {
+		MarkCreate(oldDot.Line, oldDot.Col, &frame.Dot)
+		if oldEquals != nil {
+			MarkCreate(oldEquals.Line, oldEquals.Col, &frame.Marks[MarkEquals])
+		}
+	}
+	return found
+}
+
+// isearchMatch returns the line of the match a search left Dot and Equals
+// around, and the columns of its first character and of the one after it.
+// Only the part of a match running over several lines that is on Dot's
+// line is given.
+func (e *Editor) isearchMatch() (*LineHdrObject, int, int) {
+	dot := e.CurrentFrame.Dot
+	equals := e.CurrentFrame.Marks[MarkEquals]
+	if equals == nil {
+		return dot.Line, dot.Col, dot.Col
+	}
+	if equals.Line != dot.Line {
+		var dotNr, equalsNr int
+		if !LineToNumber(dot.Line, &dotNr) || !LineToNumber(equals.Line, &equalsNr) {
+			return dot.Line, dot.Col, dot.Col
+		}
+		if equalsNr < dotNr {
+			return dot.Line, 1, dot.Col
+		}
+		return dot.Line, dot.Col, max(dot.Col, dot.Line.Used+1)
+	}
+	return dot.Line, min(dot.Col, equals.Col), max(dot.Col, equals.Col)
+}
+
+// isearchShow highlights the match if there is one, and takes the highlight
+// off the match shown before
+func (e *Editor) isearchShow(found bool) {
+	old := e.isearchLine
+	e.isearchLine = nil
+	if found {
+		e.isearchLine, e.isearchStart, e.isearchEnd = e.isearchMatch()
+	}
+	if old != nil && old != e.isearchLine && old.ScrRowNr != 0 {
+		e.ScreenDrawLine(old)
+	}
+	if e.isearchLine != nil && e.isearchLine.ScrRowNr != 0 {
+		e.ScreenDrawLine(e.isearchLine)
+	}
+}
@@ -0,0 +1,122 @@
+// Tests for the incremental Get
+
+package ludwig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsearchTarget(t *testing.T) {
+	tests := []struct {
+		typed string
+		ok    bool
+		dlm   byte
+		str   string
+	}{
+		{"", false, 0, ""},
+		{"cat", true, TpdLit, "cat"},
+		{"`", false, 0, ""},
+		{"`*a", true, TpdSmart, "*a"},
+		{"\"Cat\"", true, TpdExact, "Cat"},
+		{"~c.t", true, TpdRegexp, "c.t"},
+	}
+
+	for _, tt := range tests {
+		tpar, ok := isearchTarget([]rune(tt.typed))
+		require.Equal(t, tt.ok, ok, tt.typed)
+		if ok {
+			assert.Equal(t, tt.dlm, tpar.Dlm, tt.typed)
+			assert.Equal(t, tt.str, tpar.Str.Slice(1, tpar.Len), tt.typed)
+		}
+	}
+
+	// The keys typed are kept exactly, even a raw byte
+	typed := []rune{'c', ChFromRawByte(0xe9)}
+	tpar, ok := isearchTarget(typed)
+	require.True(t, ok)
+	assert.Equal(t, typed, tpar.Str.Runes()[:tpar.Len])
+}
+
+func TestIsearchFind(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("The quick brown fox\n")))
+	line := e.CurrentFrame.Dot.Line
+
+	// Forwards Dot goes after the match, backwards to its start
+	require.True(t, e.isearchFind([]rune("o"), 1, line, 1))
+	assert.Equal(t, [2]int{1, 14}, markAt(e.CurrentFrame.Dot))
+	_, start, end := e.isearchMatch()
+	assert.Equal(t, []int{13, 14}, []int{start, end})
+	require.True(t, e.isearchFind([]rune("ow"), -1, line, end+1))
+	assert.Equal(t, [2]int{1, 13}, markAt(e.CurrentFrame.Dot))
+
+	// No match leaves Dot and Equals alone
+	assert.False(t, e.isearchFind([]rune("owl"), 1, line, 1))
+	assert.Equal(t, [2]int{1, 13}, markAt(e.CurrentFrame.Dot))
+	assert.Equal(t, [2]int{1, 15}, markAt(e.CurrentFrame.Marks[MarkEquals]))
+	assert.Empty(t, messages.String())
+}
+
+func TestIsearchMatchLines(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("one two\nthree four\n")))
+	first := e.CurrentFrame.Dot.Line
+
+	// Forwards the part of the match on Dot's line is from its start
+	require.True(t, e.isearchFind([]rune(`~o\nth`), 1, first, 1), messages.String())
+	line, start, end := e.isearchMatch()
+	assert.Equal(t, first.FLink, line)
+	assert.Equal(t, []int{1, 3}, []int{start, end})
+
+	// Backwards it is to the end of the line
+	require.True(t, e.isearchFind([]rune(`~o\nth`), -1, first.FLink, 4), messages.String())
+	line, start, end = e.isearchMatch()
+	assert.Equal(t, first, line)
+	assert.Equal(t, []int{7, 8}, []int{start, end})
+}
+
+func TestGetIncrementalBatch(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	assert.False(t, e.BatchExecute("ug"))
+	assert.Contains(t, messages.String(), MsgInteractiveModeOnly)
+}
+
+func TestScreenOverlay(t *testing.T) {
+	over := SyntaxRun{Col: 5, Len: 3, Class: SyntaxMatch}
+	tests := []struct {
+		name   string
+		runs   []SyntaxRun
+		result []SyntaxRun
+	}{
+		{"no runs", nil, []SyntaxRun{over}},
+		{
+			"split a run",
+			[]SyntaxRun{{Col: 1, Len: 10, Class: SyntaxComment}},
+			[]SyntaxRun{{Col: 1, Len: 4, Class: SyntaxComment}, over, {Col: 8, Len: 3, Class: SyntaxComment}},
+		},
+		{
+			"cover runs",
+			[]SyntaxRun{{Col: 1, Len: 4, Class: SyntaxKeyword}, {Col: 5, Len: 2, Class: SyntaxString}, {Col: 7, Len: 3, Class: SyntaxType}},
+			[]SyntaxRun{{Col: 1, Len: 4, Class: SyntaxKeyword}, over, {Col: 8, Len: 2, Class: SyntaxType}},
+		},
+		{
+			"after the runs",
+			[]SyntaxRun{{Col: 1, Len: 2, Class: SyntaxKeyword}},
+			[]SyntaxRun{{Col: 1, Len: 2, Class: SyntaxKeyword}, over},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.result, screenOverlay(tt.runs, over))
+		})
+	}
+}
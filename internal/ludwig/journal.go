@@ -0,0 +1,366 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         JOURNAL
+//
+// Description:  The autosave journal.  The text of every modified frame is
+//               written to a journal file in the recovery directory every
+//               so often, and whenever the user stops typing for a while,
+//               so that little work is lost if Ludwig is killed or crashes.
+//               The journals are removed when Ludwig quits normally, and a
+//               journal left behind for the file being edited is offered
+//               back to the user when Ludwig next starts.
+
+package ludwig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	journalDirEnv   = "LUD_RECOVERDIR" // Directory the journals are written to
+	journalSuffix   = ".lwj"
+	journalMagic    = "LUDWIG JOURNAL"
+	journalInterval = 30 * time.Second // Longest time between writes while typing
+	journalIdle     = 4 * time.Second  // Journals are written after this long without a key
+
+	journalRecoverMsg = "Restore unsaved changes to this file from %s? "
+	journalInUseMsg   = "Ludwig process %d may be editing this file--restore its changes? "
+)
+
+// journalHeader describes the frame a journal was written from
+type journalHeader struct {
+	file  string
+	frame string
+	pid   int
+	time  time.Time
+}
+
+// journalDir returns the directory journals are kept in, "" if there is
+// nowhere to put them
+func journalDir() string {
+	if dir := os.Getenv(journalDirEnv); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ludwig", "recover")
+}
+
+// journalName returns the name of the journal for a frame.  The journal of
+// a frame with a file is named after the full path of the file, so that it
+// can be found again by the next Ludwig to edit the file.  Frames without a
+// file are journalled under the frame name and the process id.
+func (e *Editor) journalName(frame *FrameObject) string {
+	dir := journalDir()
+	if dir == "" {
+		return ""
+	}
+	if fileName := e.FrameFileName(frame); fileName != "" {
+		if path, err := filepath.Abs(fileName); err == nil {
+			fileName = path
+		}
+		return filepath.Join(dir, strings.ReplaceAll(fileName, "/", "%")+journalSuffix)
+	}
+	frameName := ""
+	if frame.Span != nil {
+		frameName = frame.Span.Name
+	}
+	frameName = strings.ReplaceAll(frameName, "/", "%")
+	return filepath.Join(dir, fmt.Sprintf("frame%%%s%%%d%s", frameName, os.Getpid(), journalSuffix))
+}
+
+// journalFrames returns the frames that can be journalled
+func (e *Editor) journalFrames() []*FrameObject {
+	var frames []*FrameObject
+	for span := e.FirstSpan; span != nil; span = span.FLink {
+		if span.Frame != nil && !span.Frame.Options.Has(OptSpecialFrame) {
+			frames = append(frames, span.Frame)
+		}
+	}
+	return frames
+}
+
+// JournalChange is called before any line of a frame is changed
+func JournalChange(line *LineHdrObject) {
+	if line.Group != nil {
+		line.Group.Frame.JournalDirty = true
+	}
+}
+
+// JournalPending returns true if any frame has changes that are not in
+// its journal yet
+func (e *Editor) JournalPending() bool {
+	for _, frame := range e.journalFrames() {
+		if frame.TextModified && frame.JournalDirty {
+			return true
+		}
+	}
+	return false
+}
+
+// journalWriteFrame writes the text of a frame to a journal.  The journal
+// is written to a temporary file first so that a crash while writing it
+// cannot destroy the previous journal.
+func (e *Editor) journalWriteFrame(frame *FrameObject, name string) error {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".journal*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	frameName := ""
+	if frame.Span != nil {
+		frameName = frame.Span.Name
+	}
+	w := bufio.NewWriter(tmp)
+	fmt.Fprintf(w, "%s\nfile %s\nframe %s\npid %d\ntime %s\n\n", journalMagic,
+		e.FrameFileName(frame), frameName, os.Getpid(), time.Now().Format(time.RFC3339))
+	for line := frame.FirstGroup.FirstLine; line != nil && line.FLink != nil; line = line.FLink {
+		w.Write(ChAppendUTF8(nil, line.Str, 1, line.Used))
+		w.WriteByte('\n')
+	}
+	err = w.Flush()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// journalRead reads a journal, returning its header and the frame's text
+func journalRead(name string) (*journalHeader, [][]rune, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+	readLine := func() (string, error) {
+		line, err := r.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimSuffix(line, "\n"), err
+	}
+
+	if line, err := readLine(); err != nil || line != journalMagic {
+		return nil, nil, errors.New("not a journal")
+	}
+	header := &journalHeader{}
+	for {
+		line, err := readLine()
+		if err != nil {
+			return nil, nil, err
+		}
+		if line == "" {
+			break
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "file":
+			header.file = value
+		case "frame":
+			header.frame = value
+		case "pid":
+			header.pid, _ = strconv.Atoi(value)
+		case "time":
+			header.time, _ = time.Parse(time.RFC3339, value)
+		}
+	}
+	var text [][]rune
+	for {
+		line, err := readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		text = append(text, ChFromUTF8([]byte(line)))
+	}
+	return header, text, nil
+}
+
+// JournalRemove removes the journal of a frame
+func JournalRemove(frame *FrameObject) {
+	if frame.JournalFile != "" {
+		os.Remove(frame.JournalFile)
+		frame.JournalFile = ""
+	}
+	frame.JournalDirty = true
+}
+
+// JournalRemoveAll removes the journals of all frames, when Ludwig quits
+func (e *Editor) JournalRemoveAll() {
+	for _, frame := range e.journalFrames() {
+		JournalRemove(frame)
+	}
+}
+
+// JournalWrite brings the journals of all frames up to date.  The journals
+// of frames that are no longer modified are removed.  A frame whose file is
+// still being paged in is journalled once all of it has been read.
+func (e *Editor) JournalWrite() {
+	e.journalWritten = time.Now()
+	for _, frame := range e.journalFrames() {
+		if !frame.TextModified {
+			JournalRemove(frame)
+			continue
+		}
+		name := e.journalName(frame)
+		if name != frame.JournalFile {
+			JournalRemove(frame)
+		}
+		if name == "" || !frame.JournalDirty || e.FileUnread(frame) {
+			continue
+		}
+		if e.journalWriteFrame(frame, name) != nil {
+			e.journalFailed = true
+			continue
+		}
+		frame.JournalFile = name
+		frame.JournalDirty = false
+	}
+}
+
+// JournalUpdate is called before each command.  The journals are written
+// if it is a while since they were last written, even if the user has not
+// stopped typing.
+func (e *Editor) JournalUpdate() {
+	if time.Since(e.journalWritten) >= journalInterval {
+		e.JournalWrite()
+	}
+	if e.journalFailed && !e.journalWarned {
+		e.journalWarned = true
+		e.ScreenMessage(MsgJournalFailed)
+	}
+}
+
+// JournalSave writes the journals when Ludwig is about to die.  A frame
+// may be in a mess if Ludwig has crashed, so a failure here is ignored
+// rather than getting in the way of writing out the files.
+func (e *Editor) JournalSave() {
+	defer func() {
+		_ = recover()
+	}()
+	e.JournalWrite()
+}
+
+// journalRunning returns true if a process is running
+func journalRunning(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// journalLoadInput reads the rest of a frame's input file into the frame
+func (e *Editor) journalLoadInput(frame *FrameObject) bool {
+	if frame.InputFile == 0 {
+		return true
+	}
+	e.undoSuspend()
+	defer e.undoResume()
+	fp := e.Files[frame.InputFile]
+	for !fp.Eof {
+		var firstLine *LineHdrObject
+		var lastLine *LineHdrObject
+		var count int
+		if !e.FileRead(fp, 50, true, &firstLine, &lastLine, &count) {
+			return false
+		}
+		frame.InputCount += uint32(count)
+		if firstLine == nil {
+			break
+		}
+		if !e.LinesInject(firstLine, lastLine, frame.LastGroup.LastLine) {
+			return false
+		}
+	}
+	e.FileFixEOP(fp.Eof, frame.LastGroup.LastLine)
+	return true
+}
+
+// journalRestore replaces the text of a frame with the text from its
+// journal.  The restore is made a step of the undo journal, so that the
+// text that was read from the file can be got back.
+func (e *Editor) journalRestore(frame *FrameObject, text [][]rune) bool {
+	var step UndoStep
+	step.StepNr = e.UndoStepNr
+	undoSaveState(frame, &step)
+	entry := UndoEntry{First: 1, Old: text, NewCount: undoLineCount(frame) - 1}
+	e.undoSuspend()
+	ok := e.undoApply(frame, &entry)
+	e.undoResume()
+	if !ok {
+		return false
+	}
+	step.Entries = []UndoEntry{entry}
+	frame.UndoSteps = append(frame.UndoSteps, &step)
+	frame.RedoSteps = nil
+	frame.TextModified = true
+	return MarkCreate(frame.FirstGroup.FirstLine, 1, &frame.Marks[MarkModified])
+}
+
+// JournalRecover looks for a journal left behind for the file of a frame
+// that has just been opened, and asks the user whether to put the changes
+// in it back into the frame.  A journal the user does not want is removed.
+func (e *Editor) JournalRecover(frame *FrameObject) bool {
+	if e.LudwigMode != LudwigScreen {
+		return true
+	}
+	name := e.journalName(frame)
+	if name == "" || e.FrameFileName(frame) == "" {
+		return true
+	}
+	header, text, err := journalRead(name)
+	if err != nil {
+		return true
+	}
+	running := journalRunning(header.pid)
+	prompt := fmt.Sprintf(journalRecoverMsg, header.time.Local().Format("2 Jan 15:04"))
+	if running {
+		prompt = fmt.Sprintf(journalInUseMsg, header.pid)
+	}
+	e.ScreenBeep()
+	switch e.ScreenVerify(prompt) {
+	case VerifyReplyYes, VerifyReplyAlways:
+		// Restore the journal below
+	default:
+		if !running {
+			os.Remove(name)
+		}
+		return true
+	}
+	if !e.journalLoadInput(frame) || !e.journalRestore(frame, text) {
+		return false
+	}
+	frame.JournalFile = name
+	e.ScreenMessage(MsgJournalRestored)
+	return true
+}
@@ -0,0 +1,107 @@
+// Tests for journal.go functions
+
+package ludwig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupJournal makes a journalled frame with the given lines, writing its
+// journals to a temporary directory
+func setupJournal(t *testing.T, e *Editor, lines ...string) (*FrameObject, string) {
+	dir := t.TempDir()
+	t.Setenv(journalDirEnv, dir)
+	frame := setupUndoFrame(t, e, lines...)
+	frame.Span = &SpanObject{Name: "NOTES", Frame: frame}
+	e.FirstSpan = frame.Span
+	return frame, dir
+}
+
+func TestJournalName(t *testing.T) {
+	e := NewEditor()
+	frame, dir := setupJournal(t, e)
+	assert.Equal(t, filepath.Join(dir, fmt.Sprintf("frame%%NOTES%%%d.lwj", os.Getpid())), e.journalName(frame))
+
+	e.Files[1] = &FileObject{Filename: "/home/user/notes.txt"}
+	frame.InputFile = 1
+	assert.Equal(t, filepath.Join(dir, "%home%user%notes.txt.lwj"), e.journalName(frame))
+}
+
+func TestJournalWriteRead(t *testing.T) {
+	e := NewEditor()
+	frame, _ := setupJournal(t, e, "one", "", "three")
+
+	// Nothing is written for a frame that has not been modified
+	e.JournalWrite()
+	assert.Empty(t, frame.JournalFile)
+
+	frame.TextModified = true
+	require.True(t, e.undoSetLine(frame.FirstGroup.FirstLine, []rune("ONE")))
+	assert.True(t, e.JournalPending())
+	e.JournalWrite()
+	assert.False(t, e.JournalPending())
+	require.Equal(t, e.journalName(frame), frame.JournalFile)
+
+	header, text, err := journalRead(frame.JournalFile)
+	require.NoError(t, err)
+	assert.Equal(t, "NOTES", header.frame)
+	assert.Equal(t, os.Getpid(), header.pid)
+	assert.Equal(t, [][]rune{[]rune("ONE"), {}, []rune("three")}, text)
+
+	// The journal goes once the frame is no longer modified
+	name := frame.JournalFile
+	frame.TextModified = false
+	e.JournalWrite()
+	assert.Empty(t, frame.JournalFile)
+	assert.NoFileExists(t, name)
+}
+
+func TestJournalRestore(t *testing.T) {
+	e := NewEditor()
+	frame, _ := setupJournal(t, e, "from", "the file")
+
+	require.True(t, e.journalRestore(frame, [][]rune{[]rune("from"), []rune("the"), []rune("journal")}))
+	assert.Equal(t, []string{"from", "the", "journal"}, frameText(frame))
+	assert.True(t, frame.TextModified)
+	require.NotNil(t, frame.Marks[MarkModified])
+
+	// The restore can be undone to get back the text of the file
+	assert.True(t, e.UserUndo(LeadParamNone, 1))
+	assert.Equal(t, []string{"from", "the file"}, frameText(frame))
+	assert.False(t, frame.TextModified)
+}
+
+func TestJournalKeepsCharacters(t *testing.T) {
+	e := NewEditor()
+	frame, _ := setupJournal(t, e, "from the file")
+	raw := []rune{ChFromRawByte(0xff), 'a', 'b', ChFromRawByte(0xe6)}
+	first := frame.FirstGroup.FirstLine
+	require.True(t, e.undoSetLine(first, []rune("héllo 日本")))
+	var second, last *LineHdrObject
+	require.True(t, LinesCreate(1, &second, &last))
+	require.True(t, e.undoSetLine(second, raw))
+	require.True(t, e.LinesInject(second, last, first.FLink))
+
+	frame.TextModified = true
+	e.JournalWrite()
+	data, err := os.ReadFile(frame.JournalFile)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(data), "\n\nhéllo 日本\n\xffab\xe6\n"))
+
+	// The characters come back as they were, and no more of them
+	_, text, err := journalRead(frame.JournalFile)
+	require.NoError(t, err)
+	other, _ := setupJournal(t, e, "from the file")
+	require.True(t, e.journalRestore(other, text))
+	line := other.FirstGroup.FirstLine
+	assert.Equal(t, []rune("héllo 日本"), line.Str.Runes()[:line.Used])
+	assert.Equal(t, raw, line.FLink.Str.Runes()[:line.FLink.Used])
+	assert.Nil(t, line.FLink.FLink.FLink)
+}
@@ -0,0 +1,358 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         LEARN
+//
+// Description:  Keyboard macros.  While learning, each thing done at the
+//               keyboard is written down as the equivalent command, and
+//               when learning stops the commands are put into a span in
+//               frame HEAP, from where they can be recalled.
+
+package ludwig
+
+import "strings"
+
+// learnDelimiters are the trailing parameter delimiters used when writing
+// down text, none of them have a special meaning.
+const learnDelimiters = "/|#%,;:=+"
+
+// learnSlot is a place in a command where the replies to prompts go
+type learnSlot struct {
+	pos     int
+	count   int
+	replies []string
+}
+
+// learnCommandName finds the keys that invoke a command in the current
+// command table
+func (e *Editor) learnCommandName(cmd Commands, depth int) (string, bool) {
+	for key := '!'; key <= '~'; key++ {
+		if key >= 'a' && key <= 'z' {
+			continue
+		}
+		if e.Lookup[key].Command == cmd && e.Lookup[key].Code == nil && e.Lookup[key].Tpar == nil {
+			return string(key), true
+		}
+	}
+	if depth > 0 {
+		for prefix := CmdPrefixAst; prefix <= CmdPrefixTilde; prefix++ {
+			for i := e.LookupExpPtr[prefix]; i < e.LookupExpPtr[prefix+1]; i++ {
+				if e.LookupExp[i].Command != cmd {
+					continue
+				}
+				if name, ok := e.learnCommandName(prefix, depth-1); ok {
+					return name + string(e.LookupExp[i].Extn), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// learnDelimiter picks a delimiter that does not appear in any of strs
+func learnDelimiter(strs ...string) (byte, bool) {
+	for i := 0; i < len(learnDelimiters); i++ {
+		found := false
+		for _, s := range strs {
+			if strings.IndexByte(s, learnDelimiters[i]) >= 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return learnDelimiters[i], true
+		}
+	}
+	return 0, false
+}
+
+// learnTparString returns the text of one trailing parameter, with its
+// lines separated by newlines.  Bytes that are not UTF-8 are written as
+// they were read.
+func learnTparString(tp *TParObject) string {
+	var text []byte
+	for con := tp; con != nil; con = con.Con {
+		if con != tp {
+			text = append(text, '\n')
+		}
+		text = ChAppendUTF8(text, con.Str, 1, con.Len)
+	}
+	return string(text)
+}
+
+// learnTextSplit returns the length of the longest start of text that
+// leaves a delimiter unused.  A delimiter is one byte, so text is not split
+// inside a character.
+func learnTextSplit(text string) int {
+	var used [len(learnDelimiters)]bool
+	left := len(learnDelimiters)
+	for i := 0; i < len(text); i++ {
+		if d := strings.IndexByte(learnDelimiters, text[i]); d >= 0 && !used[d] {
+			if left == 1 {
+				return i
+			}
+			used[d] = true
+			left--
+		}
+	}
+	return len(text)
+}
+
+// learnTparText writes down trailing parameters as they would appear in a span
+func learnTparText(tp *TParObject) string {
+	var text strings.Builder
+	text.WriteByte(tp.Dlm)
+	for ; tp != nil; tp = tp.Nxt {
+		text.WriteString(learnTparString(tp))
+		text.WriteByte(tp.Dlm)
+	}
+	return text.String()
+}
+
+// learnAdd adds a command to the span being learnt
+func (e *Editor) learnAdd(command string) {
+	e.learnLines = append(e.learnLines, command)
+}
+
+// LearnText writes down text typed in insert or overtype mode
+func (e *Editor) LearnText(overtype bool, buf *StrObject, bufLen int) {
+	if e.learnName == "" {
+		return
+	}
+	cmd := CmdInsertText
+	if overtype {
+		cmd = CmdOvertypeText
+	}
+	name, ok := e.learnCommandName(cmd, 2)
+	if !ok {
+		e.ScreenMessage(MsgCantLearnKey)
+		return
+	}
+	// Text holding every delimiter is written down a piece at a time
+	text := string(ChAppendUTF8(nil, buf, 1, bufLen))
+	for text != "" {
+		n := learnTextSplit(text)
+		dlm, _ := learnDelimiter(text[:n])
+		e.learnAdd(name + string(dlm) + text[:n] + string(dlm))
+		text = text[n:]
+	}
+}
+
+// LearnBegin starts writing down a command typed after the command
+// introducer, the keys are added by LearnCompileKey as they are read.
+func (e *Editor) LearnBegin() {
+	e.learnActive = e.learnName != ""
+	e.learnPending = e.learnPending[:0]
+	e.learnSlots = e.learnSlots[:0]
+}
+
+// LearnCompileKey writes down a key read while compiling a command
+func (e *Editor) LearnCompileKey(key int) {
+	if !e.learnActive {
+		return
+	}
+	ch, ok := KeyToCh(key)
+	if !ok {
+		e.learnActive = false
+		return
+	}
+	e.learnPending = ChAppendRune(e.learnPending, ch)
+}
+
+// LearnPrompt notes that count trailing parameters will be prompted for
+// when the command just compiled is executed
+func (e *Editor) LearnPrompt(count int) {
+	if e.learnActive {
+		e.learnSlots = append(e.learnSlots, learnSlot{pos: len(e.learnPending), count: count})
+	}
+}
+
+// LearnReply writes down the reply to a prompt for a trailing parameter
+func (e *Editor) LearnReply(tp *TParObject) {
+	if !e.learnActive {
+		return
+	}
+	for i := range e.learnSlots {
+		slot := &e.learnSlots[i]
+		if len(slot.replies) < slot.count {
+			slot.replies = append(slot.replies, string(ChAppendUTF8(nil, tp.Str, 1, tp.Len)))
+			return
+		}
+	}
+}
+
+// LearnKey starts writing down the command invoked by a key
+func (e *Editor) LearnKey(key int) {
+	e.LearnBegin()
+	if !e.learnActive {
+		return
+	}
+	lookup := &e.Lookup[key]
+	if lookup.Command == CmdNoop {
+		e.learnActive = false
+		return
+	}
+	if lookup.Command == CmdExtended {
+		if lookup.Text == "" {
+			e.learnActive = false
+			e.ScreenMessage(MsgCantLearnKey)
+			return
+		}
+		e.learnPending = append(e.learnPending, '(')
+		e.learnPending = append(e.learnPending, lookup.Text...)
+		if strings.IndexByte(lookup.Text, '!') >= 0 {
+			// A comment would hide the closing bracket
+			e.learnPending = append(e.learnPending, '\n')
+		}
+		e.learnPending = append(e.learnPending, ')')
+		return
+	}
+	name, ok := e.learnCommandName(lookup.Command, 2)
+	if ok {
+		e.learnPending = append(e.learnPending, name...)
+	} else if ch, isCh := KeyToCh(key); isCh && ch != ' ' && ch != '\n' {
+		// No other key does the same, so write down the key itself, which
+		// does whatever it is bound to when the span is recalled, trailing
+		// parameters and all
+		e.learnPending = ChAppendRune(e.learnPending, ch)
+		if lookup.Tpar != nil {
+			return
+		}
+	} else {
+		e.learnActive = false
+		e.ScreenMessage(MsgCantLearnKey)
+		return
+	}
+	tpCount := abs(e.CmdAttrib[lookup.Command].TpCount)
+	if lookup.Tpar == nil {
+		if tpCount != 0 {
+			e.LearnPrompt(tpCount)
+		}
+	} else if lookup.Tpar.Dlm == TpdPrompt {
+		e.LearnPrompt(tpCount)
+	} else {
+		e.learnPending = append(e.learnPending, learnTparText(lookup.Tpar)...)
+	}
+}
+
+// LearnDiscard stops the command being executed from being learnt.  It is
+// used by commands that only change the editing mode, the text typed in the
+// new mode is learnt instead.
+func (e *Editor) LearnDiscard() {
+	e.learnActive = false
+}
+
+// LearnEnd finishes writing down a command.  Commands that failed are not
+// learnt.
+func (e *Editor) LearnEnd(success bool) {
+	if !e.learnActive {
+		return
+	}
+	e.learnActive = false
+	if !success || e.learnName == "" {
+		return
+	}
+	var command strings.Builder
+	pos := 0
+	for _, slot := range e.learnSlots {
+		command.Write(e.learnPending[pos:slot.pos])
+		pos = slot.pos
+		dlm, ok := learnDelimiter(slot.replies...)
+		if len(slot.replies) < slot.count || !ok {
+			// Prompt for the parameters again when the span is recalled
+			command.WriteString(strings.Repeat(string(TpdPrompt), slot.count+1))
+			continue
+		}
+		command.WriteByte(dlm)
+		for _, reply := range slot.replies {
+			command.WriteString(reply)
+			command.WriteByte(dlm)
+		}
+	}
+	command.Write(e.learnPending[pos:])
+	e.learnAdd(command.String())
+}
+
+// UserLearn starts learning commands into a span
+func (e *Editor) UserLearn(spanName string) bool {
+	var span *SpanObject
+	var oldSpan *SpanObject
+
+	if e.learnName != "" {
+		e.ScreenMessage(MsgAlreadyLearning)
+		return false
+	}
+	if spanName == "" {
+		if e.learnLastName == "" {
+			e.ScreenMessage(MsgNoDefaultStr)
+			return false
+		}
+		spanName = e.learnLastName
+	}
+	if e.SpanFind(spanName, &span, &oldSpan) && span.Frame != nil {
+		e.ScreenMessage(MsgFrameOfThatNameExists)
+		return false
+	}
+	e.learnName = spanName
+	e.learnLines = nil
+	return true
+}
+
+// UserLearnEnd stops learning, and puts the commands learnt into the span
+func (e *Editor) UserLearnEnd() bool {
+	if e.learnName == "" {
+		e.ScreenMessage(MsgNotLearning)
+		return false
+	}
+	spanName := e.learnName
+	e.learnName = ""
+	e.learnLastName = spanName
+
+	var text *TParObject
+	var last *TParObject
+	for _, line := range strings.Split(strings.Join(e.learnLines, "\n"), "\n") {
+		chars := ChFromUTF8([]byte(line))
+		tp := &TParObject{Str: NewStrObjectRunes(chars), Len: len(chars)}
+		if text == nil {
+			text = tp
+		} else {
+			last.Con = tp
+		}
+		last = tp
+	}
+	e.learnLines = nil
+	return e.SpanAssign(spanName, text)
+}
+
+// UserRecall executes the commands in a learnt span count times
+func (e *Editor) UserRecall(rept LeadParam, count int, spanName string) bool {
+	var span *SpanObject
+	var oldSpan *SpanObject
+
+	if spanName == "" {
+		if e.learnLastName == "" {
+			e.ScreenMessage(MsgNoDefaultStr)
+			return false
+		}
+		spanName = e.learnLastName
+	}
+	if spanName == e.learnName {
+		e.ScreenMessage(MsgRecallWhileLearning)
+		return false
+	}
+	if !e.SpanFind(spanName, &span, &oldSpan) {
+		e.ScreenMessage(MsgNoSuchSpan)
+		return false
+	}
+	if !e.CodeCompile(span, true) {
+		return false
+	}
+	return e.CodeInterpret(rept, count, span.Code, true)
+}
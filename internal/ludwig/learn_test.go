@@ -0,0 +1,194 @@
+// Tests for learn.go functions
+
+package ludwig
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupLearnTable installs a small command table for writing down commands
+func setupLearnTable(e *Editor) {
+	e.Lookup['A'].Command = CmdAdvance
+	e.Lookup['E'].Command = CmdPrefixE
+	e.Lookup['G'].Command = CmdGet
+	e.Lookup['I'].Command = CmdInsertText
+	e.Lookup['S'].Command = CmdPrefixS
+	e.LookupExp[1] = LookupExpType{Extn: 'O', Command: CmdPrefixEo}
+	e.LookupExp[2] = LookupExpType{Extn: 'L', Command: CmdEqualEol}
+	e.LookupExp[3] = LookupExpType{Extn: 'W', Command: CmdSwapLine}
+	for prefix := CmdPrefixAst; prefix <= CmdNoSuch; prefix++ {
+		switch {
+		case prefix <= CmdPrefixE:
+			e.LookupExpPtr[prefix] = 1
+		case prefix == CmdPrefixEo:
+			e.LookupExpPtr[prefix] = 2
+		case prefix <= CmdPrefixS:
+			e.LookupExpPtr[prefix] = 3
+		default:
+			e.LookupExpPtr[prefix] = 4
+		}
+	}
+}
+
+func TestLearnCommandName(t *testing.T) {
+	e := NewEditor()
+	setupLearnTable(e)
+
+	name, ok := e.learnCommandName(CmdAdvance, 2)
+	assert.True(t, ok)
+	assert.Equal(t, "A", name)
+
+	name, ok = e.learnCommandName(CmdSwapLine, 2)
+	assert.True(t, ok)
+	assert.Equal(t, "SW", name)
+
+	// Reached through two prefixes
+	name, ok = e.learnCommandName(CmdEqualEol, 2)
+	assert.True(t, ok)
+	assert.Equal(t, "EOL", name)
+
+	_, ok = e.learnCommandName(CmdFileSave, 2)
+	assert.False(t, ok)
+}
+
+func TestLearnRecordsCommands(t *testing.T) {
+	e := NewEditor()
+	setupLearnTable(e)
+
+	// Nothing is written down unless learning
+	e.LearnText(false, NewStrObjectFrom("lost"), 4)
+	assert.Empty(t, e.learnLines)
+
+	require.True(t, e.UserLearn("MAC"))
+	assert.False(t, e.UserLearn("OTHER"))
+
+	e.LearnText(false, NewStrObjectFrom("a/b"), 3)
+
+	e.LearnBegin()
+	for _, key := range "3A" {
+		e.LearnCompileKey(int(key))
+	}
+	e.LearnEnd(true)
+
+	// Failed commands are left out
+	e.LearnBegin()
+	e.LearnCompileKey('A')
+	e.LearnEnd(false)
+
+	e.LearnBegin()
+	e.LearnCompileKey('G')
+	e.LearnPrompt(1)
+	e.LearnReply(&TParObject{Str: NewStrObjectFrom("x/y"), Len: 3})
+	e.LearnEnd(true)
+
+	// A prompt that was never answered is asked again
+	e.LearnBegin()
+	e.LearnCompileKey('G')
+	e.LearnPrompt(1)
+	e.LearnEnd(true)
+
+	e.LearnKey('A')
+	e.LearnEnd(true)
+
+	assert.Equal(t, []string{"I|a/b|", "3A", "G|x/y|", "G&&", "A"}, e.learnLines)
+}
+
+func TestLearnKeyWithTpar(t *testing.T) {
+	e := NewEditor()
+	setupLearnTable(e)
+	require.True(t, e.UserLearn("MAC"))
+
+	e.Lookup[200].Command = CmdInsertText
+	e.Lookup[200].Tpar = &TParObject{Str: NewStrObjectFrom("hi"), Len: 2, Dlm: '"'}
+	e.LearnKey(200)
+	e.LearnEnd(true)
+
+	e.Lookup[201].Command = CmdExtended
+	e.Lookup[201].Text = "2A ! down"
+	e.LearnKey(201)
+	e.LearnEnd(true)
+
+	e.Lookup[202].Command = CmdExtended
+	e.LearnKey(202)
+	e.LearnEnd(true)
+
+	assert.Equal(t, []string{`I"hi"`, "(2A ! down\n)"}, e.learnLines)
+}
+
+func TestUserRecallDefaults(t *testing.T) {
+	e := NewEditor()
+	setupLearnTable(e)
+	e.learnLastName = ""
+	assert.False(t, e.UserLearn(""))
+	assert.False(t, e.UserRecall(LeadParamNone, 1, ""))
+	assert.False(t, e.UserLearnEnd())
+
+	require.True(t, e.UserLearn("MAC"))
+	assert.False(t, e.UserRecall(LeadParamNone, 1, "MAC"))
+	assert.False(t, e.UserRecall(LeadParamNone, 1, "NONE"))
+}
+
+func TestLearnTextAnyCharacters(t *testing.T) {
+	e := NewEditor()
+	setupLearnTable(e)
+	require.True(t, e.UserLearn("MAC"))
+
+	// Text holding every delimiter is split, and bytes that are not UTF-8
+	// are kept
+	e.LearnText(false, NewStrObjectFrom("/|#%,;:=+x"), 10)
+	raw := NewStrObjectRunes([]rune{'c', 'a', 'f', ChFromRawByte(0xe9)})
+	e.LearnText(false, raw, 4)
+	e.LearnBegin()
+	e.LearnCompileKey('G')
+	e.LearnPrompt(1)
+	e.LearnReply(&TParObject{Str: raw, Len: 4})
+	e.LearnEnd(true)
+	assert.Equal(t, []string{"I+/|#%,;:=+", "I/+x/", "I/caf\xe9/", "G/caf\xe9/"}, e.learnLines)
+}
+
+func TestLearnKeyWithoutName(t *testing.T) {
+	var messages bytes.Buffer
+	e := NewEditor()
+	e.Output = &messages
+	setupLearnTable(e)
+	require.True(t, e.UserLearn("MAC"))
+
+	// A key whose command has no other name is written down as itself
+	e.Lookup[7].Command = CmdFileSave
+	e.LearnKey(7)
+	e.LearnEnd(true)
+	assert.Equal(t, []string{"\a"}, e.learnLines)
+	assert.Empty(t, messages.String())
+
+	// A key bound with trailing parameters brings them along
+	e.Lookup[6].Command = CmdFileSave
+	e.Lookup[6].Tpar = &TParObject{Str: NewStrObjectFrom("x"), Len: 1, Dlm: '/'}
+	e.LearnKey(6)
+	e.LearnEnd(true)
+	assert.Equal(t, []string{"\a", "\x06"}, e.learnLines)
+
+	// Unless it is not a character
+	e.Lookup[300].Command = CmdFileSave
+	e.LearnKey(300)
+	e.LearnEnd(true)
+	assert.Equal(t, []string{"\a", "\x06"}, e.learnLines)
+	assert.Contains(t, messages.String(), MsgCantLearnKey)
+}
+
+func TestLearnRecallBytes(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.UserLearn("MAC"))
+	text := []rune("/|#%,;:=+ caf")
+	text = append(text, ChFromRawByte(0xe9))
+	e.LearnText(false, NewStrObjectRunes(text), len(text))
+	require.True(t, e.UserLearnEnd())
+
+	require.True(t, e.BatchExecute("ur/mac/"), messages.String())
+	line := e.CurrentFrame.FirstGroup.FirstLine
+	assert.Equal(t, text, line.Str.RuneSlice(1, line.Used))
+}
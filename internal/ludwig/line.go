@@ -112,7 +112,11 @@ func GroupsDestroy(firstGroup **GroupObject, lastGroup **GroupObject) bool {
 }
 
 // LinesInject injects a linked list of lines into the data structure
-func LinesInject(firstLine *LineHdrObject, lastLine *LineHdrObject, beforeLine *LineHdrObject) bool {
+func (e *Editor) LinesInject(firstLine *LineHdrObject, lastLine *LineHdrObject, beforeLine *LineHdrObject) bool {
+	rec := e.undoBegin()
+	defer e.undoEnd(rec)
+	rec.capture(beforeLine, 0)
+
 	// Scan the lines to be inserted, counting lines and checking space used
 	var nrNewLines int
 	var space int
@@ -274,15 +278,19 @@ func LinesInject(firstLine *LineHdrObject, lastLine *LineHdrObject, beforeLine *
 	thisFrame.SpaceLeft -= space
 
 	// Update the screen
-	if beforeLine.ScrRowNr != 0 && beforeLine != ScrTopLine {
-		ScreenLinesInject(firstLine, nrNewLines, beforeLine)
+	if beforeLine.ScrRowNr != 0 && beforeLine != e.ScrTopLine {
+		e.ScreenLinesInject(firstLine, nrNewLines, beforeLine)
 	}
 
 	return true
 }
 
 // LinesExtract extracts lines from the data structure
-func LinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) bool {
+func (e *Editor) LinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) bool {
+	rec := e.undoBegin()
+	defer e.undoEnd(rec)
+	rec.captureBetween(firstLine, lastLine)
+
 	// Define some useful pointers
 	topLine := firstLine.BLink
 	endLine := lastLine.FLink
@@ -300,7 +308,7 @@ func LinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) bool {
 	firstLineNr := firstGroup.FirstLineNr + firstLineOffsetNr
 	nrLinesToRemove := lastGroup.FirstLineNr + lastLine.OffsetNr - firstLineNr + 1
 
-	if thisFrame == ScrFrame {
+	if thisFrame == e.ScrFrame {
 		var firstScrLine *LineHdrObject
 		var lastScrLine *LineHdrObject
 		needsExtract := false
@@ -308,8 +316,8 @@ func LinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) bool {
 		if firstLine.ScrRowNr != 0 {
 			firstScrLine = firstLine
 		} else {
-			if firstLineNr < ScrTopLine.Group.FirstLineNr+ScrTopLine.OffsetNr {
-				firstScrLine = ScrTopLine
+			if firstLineNr < e.ScrTopLine.Group.FirstLineNr+e.ScrTopLine.OffsetNr {
+				firstScrLine = e.ScrTopLine
 			} else {
 				goto done1
 			}
@@ -318,8 +326,8 @@ func LinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) bool {
 		if lastLine.ScrRowNr != 0 {
 			lastScrLine = lastLine
 		} else {
-			if lastLine.Group.FirstLineNr+lastLine.OffsetNr > ScrBotLine.Group.FirstLineNr+ScrBotLine.OffsetNr {
-				lastScrLine = ScrBotLine
+			if lastLine.Group.FirstLineNr+lastLine.OffsetNr > e.ScrBotLine.Group.FirstLineNr+e.ScrBotLine.OffsetNr {
+				lastScrLine = e.ScrBotLine
 			} else {
 				goto done1
 			}
@@ -327,7 +335,7 @@ func LinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) bool {
 		needsExtract = true
 	done1:
 		if needsExtract {
-			ScreenLinesExtract(firstScrLine, lastScrLine)
+			e.ScreenLinesExtract(firstScrLine, lastScrLine)
 		}
 	}
 
@@ -431,11 +439,7 @@ func LineChangeLength(line *LineHdrObject, newLength int) bool {
 
 	if newLength > 0 {
 		// Quantize the length to get some slack
-		if newLength < MaxStrLen-10 {
-			newLength = (newLength/10 + 1) * 10
-		} else {
-			newLength = MaxStrLen
-		}
+		newLength = (newLength/10 + 1) * 10
 
 		// Create a new str_object and copy the text from the old one
 		newStr = NewStrObjectCopy(line.Str, 1, line.Len(), newLength)
@@ -467,9 +471,23 @@ func LineFromNumber(frame *FrameObject, number int, line **LineHdrObject) bool {
 	if number >= thisGroup.FirstLineNr+thisGroup.NrLines {
 		*line = nil
 	} else {
+		// Start from whichever of the first group, the last group and the
+		// group holding Dot is nearest
+		if number < thisGroup.FirstLineNr/2 {
+			thisGroup = frame.FirstGroup
+		}
+		if frame.Dot != nil && frame.Dot.Line.Group != nil && frame.Dot.Line.Group.Frame == frame {
+			dotGroup := frame.Dot.Line.Group
+			if abs(dotGroup.FirstLineNr-number) < abs(thisGroup.FirstLineNr-number) {
+				thisGroup = dotGroup
+			}
+		}
 		for thisGroup.FirstLineNr > number {
 			thisGroup = thisGroup.BLink
 		}
+		for thisGroup.FirstLineNr+thisGroup.NrLines <= number {
+			thisGroup = thisGroup.FLink
+		}
 
 		thisLine := thisGroup.FirstLine
 		for lineNr := 1; lineNr <= number-thisGroup.FirstLineNr; lineNr++ {
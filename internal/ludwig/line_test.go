@@ -277,6 +277,31 @@ func TestLineFromNumber(t *testing.T) {
 		assert.Equal(t, group3, line.Group, "Line not in third group")
 		assert.Equal(t, 9, line.OffsetNr, "Expected offset 9")
 	})
+
+	t.Run("ManyGroupsFromEachEnd", func(t *testing.T) {
+		frame := createTestFrame()
+		groups := []*GroupObject{}
+		for i := 0; i < 1000; i++ {
+			group := createTestGroup(frame, i*MaxGroupLines+1, MaxGroupLines)
+			if i > 0 {
+				linkGroups(groups[i-1], group)
+			}
+			groups = append(groups, group)
+		}
+		frame.FirstGroup = groups[0]
+		frame.LastGroup = groups[999]
+		frame.Dot = &MarkObject{Line: groups[600].FirstLine, Col: 1}
+
+		for _, number := range []int{1, 65, 300*MaxGroupLines + 7, 600*MaxGroupLines + 1, 601 * MaxGroupLines, 1000 * MaxGroupLines} {
+			var line *LineHdrObject
+			assert.True(t, LineFromNumber(frame, number, &line), "LineFromNumber returned false")
+			if assert.NotNil(t, line, "No line for %d", number) {
+				var lineNr int
+				assert.True(t, LineToNumber(line, &lineNr))
+				assert.Equal(t, number, lineNr)
+			}
+		}
+	})
 }
 
 // Tests for LineChangeLength
@@ -369,21 +394,18 @@ func TestLineChangeLength(t *testing.T) {
 			requested int
 			expected  int
 		}{
-			{1, 10},                     // (1/10 + 1) * 10 = 10
-			{5, 10},                     // (5/10 + 1) * 10 = 10
-			{9, 10},                     // (9/10 + 1) * 10 = 10
-			{10, 20},                    // (10/10 + 1) * 10 = 20
-			{11, 20},                    // (11/10 + 1) * 10 = 20
-			{19, 20},                    // (19/10 + 1) * 10 = 20
-			{20, 30},                    // (20/10 + 1) * 10 = 30
-			{21, 30},                    // (21/10 + 1) * 10 = 30
-			{95, 100},                   // (95/10 + 1) * 10 = 100
-			{MaxStrLen - 20, 390},       // (380/10 + 1) * 10 = 390 (still below threshold)
-			{MaxStrLen - 11, 390},       // (389/10 + 1) * 10 = 390 (still below threshold)
-			{MaxStrLen - 10, MaxStrLen}, // >= MaxStrLen - 10, caps to MaxStrLen
-			{MaxStrLen - 5, MaxStrLen},  // >= MaxStrLen - 10
-			{MaxStrLen, MaxStrLen},
-			{MaxStrLen + 10, MaxStrLen}, // Should cap at MaxStrLen
+			{1, 10},        // (1/10 + 1) * 10 = 10
+			{5, 10},        // (5/10 + 1) * 10 = 10
+			{9, 10},        // (9/10 + 1) * 10 = 10
+			{10, 20},       // (10/10 + 1) * 10 = 20
+			{11, 20},       // (11/10 + 1) * 10 = 20
+			{19, 20},       // (19/10 + 1) * 10 = 20
+			{20, 30},       // (20/10 + 1) * 10 = 30
+			{21, 30},       // (21/10 + 1) * 10 = 30
+			{95, 100},      // (95/10 + 1) * 10 = 100
+			{395, 400},     // (395/10 + 1) * 10 = 400
+			{400, 410},     // No longer capped at 400
+			{10005, 10010}, // (10005/10 + 1) * 10 = 10010
 		}
 
 		for _, tc := range testCases {
@@ -526,7 +548,7 @@ func TestLinesDestroy(t *testing.T) {
 		// Add Str objects to some lines
 		line := firstLine
 		for i := 0; i < 3 && line != nil; i++ {
-			line.Str = NewBlankStrObject(MaxStrLen)
+			line.Str = NewBlankStrObject(testStrLen)
 			line = line.FLink
 		}
 
@@ -601,7 +623,7 @@ func TestLineEOPDestroy(t *testing.T) {
 		LineEOPCreate(frame, &group)
 
 		// Add a Str object to the EOP line
-		group.FirstLine.Str = NewBlankStrObject(MaxStrLen)
+		group.FirstLine.Str = NewBlankStrObject(testStrLen)
 
 		result := LineEOPDestroy(&group)
 
@@ -648,21 +670,7 @@ func TestGroupsDestroy(t *testing.T) {
 // Tests for LinesInject - Basic cases
 
 func TestLinesInject_Basic(t *testing.T) {
-	// Save and clear global screen variables
-	origScrFrame := ScrFrame
-	origScrTopLine := ScrTopLine
-	origScrBotLine := ScrBotLine
-
-	t.Cleanup(func() {
-		ScrFrame = origScrFrame
-		ScrTopLine = origScrTopLine
-		ScrBotLine = origScrBotLine
-	})
-
-	ScrFrame = nil
-	ScrTopLine = nil
-	ScrBotLine = nil
-
+	e := NewEditor()
 	t.Run("InjectIntoEOPGroup", func(t *testing.T) {
 		frame, eopGroup := createTestFrameWithEOP()
 		beforeLine := eopGroup.FirstLine
@@ -681,7 +689,7 @@ func TestLinesInject_Basic(t *testing.T) {
 
 		originalSpace := frame.SpaceLeft
 
-		result := LinesInject(firstLine, lastLine, beforeLine)
+		result := e.LinesInject(firstLine, lastLine, beforeLine)
 
 		assert.True(t, result, "LinesInject returned false")
 
@@ -708,7 +716,7 @@ func TestLinesInject_Basic(t *testing.T) {
 		var firstLine, lastLine *LineHdrObject
 		LinesCreate(1, &firstLine, &lastLine)
 
-		result := LinesInject(firstLine, lastLine, beforeLine)
+		result := e.LinesInject(firstLine, lastLine, beforeLine)
 
 		assert.True(t, result, "LinesInject returned false")
 
@@ -737,7 +745,7 @@ func TestLinesInject_Basic(t *testing.T) {
 		var firstLine, lastLine *LineHdrObject
 		LinesCreate(5, &firstLine, &lastLine)
 
-		result := LinesInject(firstLine, lastLine, beforeLine)
+		result := e.LinesInject(firstLine, lastLine, beforeLine)
 
 		assert.True(t, result, "LinesInject returned false")
 
@@ -769,21 +777,7 @@ func TestLinesInject_Basic(t *testing.T) {
 }
 
 func TestLinesInject_RequiringNewGroups(t *testing.T) {
-	// Save and clear global screen variables
-	origScrFrame := ScrFrame
-	origScrTopLine := ScrTopLine
-	origScrBotLine := ScrBotLine
-
-	t.Cleanup(func() {
-		ScrFrame = origScrFrame
-		ScrTopLine = origScrTopLine
-		ScrBotLine = origScrBotLine
-	})
-
-	ScrFrame = nil
-	ScrTopLine = nil
-	ScrBotLine = nil
-
+	e := NewEditor()
 	t.Run("InjectExceedingGroupCapacity", func(t *testing.T) {
 		frame, eopGroup := createTestFrameWithEOP()
 		beforeLine := eopGroup.FirstLine
@@ -793,7 +787,7 @@ func TestLinesInject_RequiringNewGroups(t *testing.T) {
 		var firstLine, lastLine *LineHdrObject
 		LinesCreate(lineCount, &firstLine, &lastLine)
 
-		result := LinesInject(firstLine, lastLine, beforeLine)
+		result := e.LinesInject(firstLine, lastLine, beforeLine)
 
 		assert.True(t, result, "LinesInject returned false")
 
@@ -825,7 +819,7 @@ func TestLinesInject_RequiringNewGroups(t *testing.T) {
 		var firstLine, lastLine *LineHdrObject
 		LinesCreate(lineCount, &firstLine, &lastLine)
 
-		result := LinesInject(firstLine, lastLine, beforeLine)
+		result := e.LinesInject(firstLine, lastLine, beforeLine)
 
 		assert.True(t, result, "LinesInject returned false")
 
@@ -847,21 +841,7 @@ func TestLinesInject_RequiringNewGroups(t *testing.T) {
 // Tests for LinesExtract - Basic cases
 
 func TestLinesExtract_Basic(t *testing.T) {
-	// Save and clear global screen variables
-	origScrFrame := ScrFrame
-	origScrTopLine := ScrTopLine
-	origScrBotLine := ScrBotLine
-
-	t.Cleanup(func() {
-		ScrFrame = origScrFrame
-		ScrTopLine = origScrTopLine
-		ScrBotLine = origScrBotLine
-	})
-
-	ScrFrame = nil
-	ScrTopLine = nil
-	ScrBotLine = nil
-
+	e := NewEditor()
 	t.Run("ExtractSingleLine", func(t *testing.T) {
 		frame := createTestFrame()
 		group := createTestGroup(frame, 1, 10)
@@ -878,7 +858,7 @@ func TestLinesExtract_Basic(t *testing.T) {
 		LineChangeLength(extractLine, 10)
 		originalSpace := frame.SpaceLeft
 
-		result := LinesExtract(extractLine, extractLine)
+		result := e.LinesExtract(extractLine, extractLine)
 
 		assert.True(t, result, "LinesExtract returned false")
 
@@ -908,7 +888,7 @@ func TestLinesExtract_Basic(t *testing.T) {
 		}
 		lastLine := firstLine.FLink.FLink
 
-		result := LinesExtract(firstLine, lastLine)
+		result := e.LinesExtract(firstLine, lastLine)
 
 		assert.True(t, result, "LinesExtract returned false")
 
@@ -926,7 +906,7 @@ func TestLinesExtract_Basic(t *testing.T) {
 
 		firstLine := group.FirstLine
 
-		result := LinesExtract(firstLine, firstLine)
+		result := e.LinesExtract(firstLine, firstLine)
 
 		assert.True(t, result, "LinesExtract returned false")
 
@@ -954,7 +934,7 @@ func TestLinesExtract_Basic(t *testing.T) {
 
 		lastLine := group.LastLine
 
-		result := LinesExtract(lastLine, lastLine)
+		result := e.LinesExtract(lastLine, lastLine)
 
 		assert.True(t, result, "LinesExtract returned false")
 
@@ -986,7 +966,7 @@ func TestLinesExtract_Basic(t *testing.T) {
 		firstLine := group2.FirstLine
 		lastLine := group2.LastLine
 
-		result := LinesExtract(firstLine, lastLine)
+		result := e.LinesExtract(firstLine, lastLine)
 
 		assert.True(t, result, "LinesExtract returned false")
 
@@ -1004,21 +984,7 @@ func TestLinesExtract_Basic(t *testing.T) {
 }
 
 func TestLinesExtract_MultipleGroups(t *testing.T) {
-	// Save and clear global screen variables
-	origScrFrame := ScrFrame
-	origScrTopLine := ScrTopLine
-	origScrBotLine := ScrBotLine
-
-	t.Cleanup(func() {
-		ScrFrame = origScrFrame
-		ScrTopLine = origScrTopLine
-		ScrBotLine = origScrBotLine
-	})
-
-	ScrFrame = nil
-	ScrTopLine = nil
-	ScrBotLine = nil
-
+	e := NewEditor()
 	t.Run("ExtractSpanningTwoGroups", func(t *testing.T) {
 		frame := createTestFrame()
 		group1 := createTestGroup(frame, 1, 20)
@@ -1050,7 +1016,7 @@ func TestLinesExtract_MultipleGroups(t *testing.T) {
 			lastLine = lastLine.FLink
 		}
 
-		result := LinesExtract(firstLine, lastLine)
+		result := e.LinesExtract(firstLine, lastLine)
 
 		assert.True(t, result, "LinesExtract returned false")
 
@@ -1097,7 +1063,7 @@ func TestLinesExtract_MultipleGroups(t *testing.T) {
 		firstLine := group2.FirstLine
 		lastLine := group2.LastLine
 
-		result := LinesExtract(firstLine, lastLine)
+		result := e.LinesExtract(firstLine, lastLine)
 
 		assert.True(t, result, "LinesExtract returned false")
 
@@ -1124,21 +1090,7 @@ func TestLinesExtract_MultipleGroups(t *testing.T) {
 // Integration tests
 
 func TestLinesInjectExtractIntegration(t *testing.T) {
-	// Save and clear global screen variables
-	origScrFrame := ScrFrame
-	origScrTopLine := ScrTopLine
-	origScrBotLine := ScrBotLine
-
-	t.Cleanup(func() {
-		ScrFrame = origScrFrame
-		ScrTopLine = origScrTopLine
-		ScrBotLine = origScrBotLine
-	})
-
-	ScrFrame = nil
-	ScrTopLine = nil
-	ScrBotLine = nil
-
+	e := NewEditor()
 	t.Run("InjectThenExtractSameLines", func(t *testing.T) {
 		frame, eopGroup := createTestFrameWithEOP()
 		beforeLine := eopGroup.FirstLine
@@ -1146,12 +1098,12 @@ func TestLinesInjectExtractIntegration(t *testing.T) {
 		// Create and inject lines
 		var firstLine, lastLine *LineHdrObject
 		LinesCreate(5, &firstLine, &lastLine)
-		LinesInject(firstLine, lastLine, beforeLine)
+		e.LinesInject(firstLine, lastLine, beforeLine)
 
 		originalSpace := frame.SpaceLeft
 
 		// Extract the same lines
-		result := LinesExtract(firstLine, lastLine)
+		result := e.LinesExtract(firstLine, lastLine)
 
 		assert.True(t, result, "LinesExtract returned false")
 
@@ -1171,14 +1123,14 @@ func TestLinesInjectExtractIntegration(t *testing.T) {
 		// Operation 1: Inject 10 lines
 		var first1, last1 *LineHdrObject
 		LinesCreate(10, &first1, &last1)
-		LinesInject(first1, last1, beforeLine)
+		e.LinesInject(first1, last1, beforeLine)
 
 		validateFrameStructure(t, frame)
 
 		// Operation 2: Inject 5 more lines at beginning
 		var first2, last2 *LineHdrObject
 		LinesCreate(5, &first2, &last2)
-		LinesInject(first2, last2, first1)
+		e.LinesInject(first2, last2, first1)
 
 		validateFrameStructure(t, frame)
 
@@ -1188,7 +1140,7 @@ func TestLinesInjectExtractIntegration(t *testing.T) {
 		for i := 0; i < 4; i++ {
 			extractLast = extractLast.FLink
 		}
-		LinesExtract(extractFirst, extractLast)
+		e.LinesExtract(extractFirst, extractLast)
 
 		validateFrameStructure(t, frame)
 
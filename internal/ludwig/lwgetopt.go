@@ -33,87 +33,75 @@ package ludwig
 
 import "strings"
 
-var (
-	LwOptInd   int
-	LwOptOpt   int
-	LwOptReset bool
-	LwOptArg   string
-	place      string
-)
-
 const (
 	badCh  = int('?')
 	badArg = int(':')
 )
 
-func init() {
-	LwOptInd = 1
-}
-
-func LwGetOpt(nargv []string, ostr string) int {
+func (e *Editor) LwGetOpt(nargv []string, ostr string) int {
 
-	if LwOptReset || place == "" {
-		LwOptReset = false
-		if LwOptInd >= len(nargv) {
-			place = ""
+	if e.LwOptReset || e.place == "" {
+		e.LwOptReset = false
+		if e.LwOptInd >= len(nargv) {
+			e.place = ""
 			return -1
 		}
-		place = nargv[LwOptInd]
-		if len(place) == 0 || place[0] != '-' {
-			place = ""
+		e.place = nargv[e.LwOptInd]
+		if len(e.place) == 0 || e.place[0] != '-' {
+			e.place = ""
 			return -1
 		}
-		place = place[1:]
-		if len(place) > 0 && place[0] == '-' && len(place) == 1 {
-			LwOptInd++
-			place = ""
+		e.place = e.place[1:]
+		if len(e.place) > 0 && e.place[0] == '-' && len(e.place) == 1 {
+			e.LwOptInd++
+			e.place = ""
 			return -1
 		}
-		if len(place) == 0 {
-			place = ""
+		if len(e.place) == 0 {
+			e.place = ""
 			if !strings.ContainsRune(ostr, '-') {
 				return -1
 			}
-			LwOptOpt = '-'
+			e.LwOptOpt = '-'
 		} else {
-			LwOptOpt = int(place[0])
-			place = place[1:]
+			e.LwOptOpt = int(e.place[0])
+			e.place = e.place[1:]
 		}
 	} else {
-		LwOptOpt = int(place[0])
-		place = place[1:]
+		e.LwOptOpt = int(e.place[0])
+		e.place = e.place[1:]
 	}
 
-	oli := strings.IndexRune(ostr, rune(LwOptOpt))
-	if LwOptOpt == ':' || oli == -1 {
-		if len(place) == 0 {
-			LwOptInd++
+	oli := strings.IndexRune(ostr, rune(e.LwOptOpt))
+	if e.LwOptOpt == ':' || oli == -1 {
+		if len(e.place) == 0 {
+			e.LwOptInd++
 		}
 		return badCh
 	}
 
 	if oli+1 < len(ostr) && ostr[oli+1] != ':' {
-		LwOptArg = ""
-		if len(place) == 0 {
-			LwOptInd++
+		e.LwOptArg = ""
+		if len(e.place) == 0 {
+			e.LwOptInd++
 		}
 	} else {
-		if len(place) > 0 {
-			LwOptArg = place
+		if len(e.place) > 0 {
+			e.LwOptArg = e.place
 		} else if oli+2 < len(ostr) && ostr[oli+2] == ':' {
-			LwOptArg = ""
-		} else if len(nargv) > LwOptInd+1 {
-			LwOptInd++
-			LwOptArg = nargv[LwOptInd]
+			e.LwOptArg = ""
+		} else if len(nargv) > e.LwOptInd+1 {
+			e.LwOptInd++
+			e.LwOptArg = nargv[e.LwOptInd]
 		} else {
-			place = ""
+			e.place = ""
 			if len(ostr) > 0 && ostr[0] == ':' {
 				return badArg
 			}
 			return badCh
 		}
-		place = ""
-		LwOptInd++
+		e.place = ""
+		e.LwOptInd++
 	}
-	return LwOptOpt
+	return e.LwOptOpt
 }
@@ -0,0 +1,183 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         MATCHES
+//
+// Description:  Highlighting of every match on the screen of the target
+//               of the last G in the current frame, unless the frame's M
+//               option is off.  A regular expression is matched as G
+//               matches it, so a match may run on over the end of a line.
+
+package ludwig
+
+import "slices"
+
+// matchTarget returns the target whose matches are highlighted in a frame,
+// as its delimiter followed by its text, or "" if nothing is highlighted
+func matchTarget(frame *FrameObject) string {
+	if !frame.Options.Has(OptMatches) || frame.GetTpar.Len == 0 {
+		return ""
+	}
+	return string(rune(frame.GetTpar.Dlm)) + frame.GetTpar.Str.Slice(1, frame.GetTpar.Len)
+}
+
+// MatchFixLines returns the lines from first to last if the target whose
+// matches they were drawn with has changed since, so that they have to be
+// drawn again, and nil otherwise
+func (e *Editor) MatchFixLines(first *LineHdrObject, last *LineHdrObject) []*LineHdrObject {
+	frame := first.Group.Frame
+	target := matchTarget(frame)
+	if target == frame.MatchShown {
+		return nil
+	}
+	frame.MatchShown = target
+	var lines []*LineHdrObject
+	for line := first; line != nil && line.FLink != nil; line = line.FLink {
+		lines = append(lines, line)
+		if line == last {
+			break
+		}
+	}
+	return lines
+}
+
+// MatchRuns returns a run for each match in a line of the target of the
+// last G.  Only the lines of the current frame are searched, as a pattern
+// can depend on where Dot and the marks are.
+func (e *Editor) MatchRuns(line *LineHdrObject) []SyntaxRun {
+	frame := line.Group.Frame
+	if frame != e.CurrentFrame || matchTarget(frame) == "" {
+		return nil
+	}
+	return e.matchLine(frame, line)
+}
+
+// matchLine returns a run for each match in a line of a frame's last G
+// target, whether the frame shows them or not
+func (e *Editor) matchLine(frame *FrameObject, line *LineHdrObject) []SyntaxRun {
+	switch frame.GetTpar.Dlm {
+	case TpdSmart:
+		return e.matchPattern(frame.GetPatternPtr, line)
+	case TpdRegexp:
+		return e.matchRegexp(frame, line)
+	}
+	return matchLiteral(frame.GetTpar, line)
+}
+
+// matchLiteral finds the matches in a line of a target that is not a
+// pattern, in exact case if it was given with " as the delimiter
+func matchLiteral(tpar TParObject, line *LineHdrObject) []SyntaxRun {
+	target := NewStrObjectCopy(tpar.Str, 1, tpar.Len, tpar.Len)
+	exactcase := tpar.Dlm == TpdExact
+	if !exactcase {
+		target.ApplyN(ChToUpper, tpar.Len, 1)
+	}
+	var runs []SyntaxRun
+	for col := 1; col+tpar.Len <= line.Used+1; {
+		var offset int
+		if !ChSearchStr(target, 1, tpar.Len, line.Str, col, line.Used+1-col, exactcase, false, &offset) {
+			break
+		}
+		runs = append(runs, SyntaxRun{Col: col + offset, Len: tpar.Len, Class: SyntaxMatch})
+		col += offset + tpar.Len
+	}
+	return runs
+}
+
+// matchPattern finds the matches in a line of the pattern the last G built
+func (e *Editor) matchPattern(pattern *DFATableObject, line *LineHdrObject) []SyntaxRun {
+	if pattern == nil {
+		return nil
+	}
+	var runs []SyntaxRun
+	markFlag := false
+	for col := 1; col <= line.Used && !e.TtControlC.Load(); {
+		var start, finish int
+		if !e.PatternRecognize(pattern, line, col, &markFlag, &start, &finish) {
+			break
+		}
+		if finish == start {
+			// Nothing to show, so go on past it
+			col = finish + 1
+			markFlag = false
+			continue
+		}
+		runs = append(runs, SyntaxRun{Col: start, Len: finish - start, Class: SyntaxMatch})
+		col = finish
+	}
+	return runs
+}
+
+// matchRegexp finds the matches in a line of a frame's last G target,
+// which is a regular expression, as G finds them.  The search starts a
+// window's height above the line, so that a match running on from a line
+// before is shown, and reads no further than a window's height below it.
+// The expression is only compiled again when it changes.
+func (e *Editor) matchRegexp(frame *FrameObject, line *LineHdrObject) []SyntaxRun {
+	tpar := &frame.GetTpar
+	expr := tpar.Str.Runes()[:tpar.Len]
+	if e.matchTarget == nil || !slices.Equal(expr, e.matchExpr) {
+		e.matchExpr = expr
+		e.matchTarget = &regexpTarget{}
+		e.matchValid = regexpBuild(tpar, e.matchTarget) == nil
+	}
+	if !e.matchValid {
+		return nil
+	}
+
+	var lineNr int
+	if !LineToNumber(line, &lineNr) {
+		return nil
+	}
+	start, end := line, line
+	for i := 0; i < max(frame.ScrHeight, 1); i++ {
+		if start.BLink != nil {
+			start = start.BLink
+		}
+		if end.FLink != nil {
+			end = end.FLink
+		}
+	}
+
+	var runs []SyntaxRun
+	from, col := start, 1
+	for !e.TtControlC.Load() {
+		findLine, findCol, loc := e.matchTarget.regexpFind(from, col, end, end.Used+1)
+		if loc == nil {
+			break
+		}
+		startLine, startCol := regexpPosition(findLine, findCol, loc[0])
+		finishLine, finishCol := regexpPosition(findLine, findCol, loc[1])
+		var startNr, finishNr int
+		if !LineToNumber(startLine, &startNr) || !LineToNumber(finishLine, &finishNr) || startNr > lineNr {
+			break
+		}
+		if finishNr >= lineNr {
+			if startNr < lineNr {
+				startCol = 1
+			}
+			if finishNr > lineNr {
+				finishCol = line.Used + 1
+			}
+			if finishCol > startCol {
+				runs = append(runs, SyntaxRun{Col: startCol, Len: finishCol - startCol, Class: SyntaxMatch})
+			}
+		}
+		from, col = finishLine, finishCol
+		if loc[1] == loc[0] {
+			// Go past an empty match so as not to find it again
+			var ok bool
+			if from, col, ok = regexpStep(from, col, end, end.Used+1); !ok {
+				break
+			}
+		}
+	}
+	return runs
+}
@@ -0,0 +1,104 @@
+// Tests for highlighting the matches of the last G
+
+package ludwig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// matchCols returns the column and length of each match shown in a line
+func matchCols(e *Editor, line *LineHdrObject) [][2]int {
+	var cols [][2]int
+	for _, run := range e.MatchRuns(line) {
+		cols = append(cols, [2]int{run.Col, run.Len})
+	}
+	return cols
+}
+
+func TestMatchRuns(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		cols    [][2]int
+	}{
+		{"inexact case", "g/ab/", [][2]int{{1, 2}, {4, 2}, {12, 2}}},
+		{"exact case", `g"AB"`, [][2]int{{4, 2}}},
+		{"pattern", "g`+n`", [][2]int{{9, 2}, {14, 1}}},
+		{"regular expression", `g~[a-z]b\d?~`, [][2]int{{1, 2}, {12, 3}}},
+		{"bad regular expression", "g~(~", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages bytes.Buffer
+			e := newBatchEditor(t, &messages)
+			require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("ab AB c 12 ab3\n")))
+			e.BatchExecute(tt.command)
+			assert.Equal(t, tt.cols, matchCols(e, e.CurrentFrame.Dot.Line))
+		})
+	}
+}
+
+func TestMatchRunsLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		cols    [][][2]int
+	}{
+		{"over the end of a line", `g~o\nth~`, [][][2]int{{{7, 1}}, {{1, 2}}, nil}},
+		{"over several lines", `g~(?s)two.*four~`, [][][2]int{{{5, 3}}, {{1, 10}}, nil}},
+		{"start of line", `g~^\w~`, [][][2]int{{{1, 1}}, {{1, 1}}, {{1, 1}}}},
+		{"bytes not UTF-8", "g~v\xe9~", [][][2]int{nil, nil, {{3, 2}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages bytes.Buffer
+			e := newBatchEditor(t, &messages)
+			require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("one two\nthree four\nfiv\xe9\n")))
+			require.True(t, e.BatchExecute(tt.command), messages.String())
+			var cols [][][2]int
+			for line := e.CurrentFrame.FirstGroup.FirstLine; line.FLink != nil; line = line.FLink {
+				cols = append(cols, matchCols(e, line))
+			}
+			assert.Equal(t, tt.cols, cols)
+		})
+	}
+}
+
+func TestMatchRunsOff(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("one\n")))
+	line := e.CurrentFrame.Dot.Line
+
+	// Nothing is shown before a search, or with the M option off
+	assert.Empty(t, e.MatchRuns(line))
+	require.True(t, e.BatchExecute("g/n/"), messages.String())
+	assert.Equal(t, [][2]int{{2, 1}}, matchCols(e, line))
+	require.True(t, e.BatchExecute("ep/o=-m/"), messages.String())
+	assert.Empty(t, e.MatchRuns(line))
+}
+
+func TestMatchFixLines(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("one\ntwo\nthree\n")))
+	first := e.CurrentFrame.FirstGroup.FirstLine
+	last := e.CurrentFrame.LastGroup.LastLine
+
+	// The lines are drawn again only when the target changes
+	assert.Nil(t, e.MatchFixLines(first, last))
+	require.True(t, e.BatchExecute("g/o/"), messages.String())
+	assert.Len(t, e.MatchFixLines(first, last), 3)
+	assert.Nil(t, e.MatchFixLines(first, last))
+	require.True(t, e.BatchExecute("g//"), messages.String())
+	assert.Nil(t, e.MatchFixLines(first, last))
+	require.True(t, e.BatchExecute("ep/o=-m/"), messages.String())
+	assert.Len(t, e.MatchFixLines(first, last), 3)
+}
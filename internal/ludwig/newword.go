@@ -15,13 +15,14 @@
 package ludwig
 
 // currentWord positions the mark at the start of the current word
-func currentWord(dot *MarkObject) bool {
+func (e *Editor) currentWord(dot *MarkObject) bool {
 	if dot.Line.Used+2 < dot.Col {
 		// check that we aren't past the last word in the para
-		if dot.Line.FLink == nil { // no more lines => end of para
+		nextLine := e.FileNextLine(dot.Line)
+		if nextLine == nil { // no more lines => end of para
 			return false
 		}
-		if dot.Line.FLink.Used == 0 { // next line blank => end of para
+		if nextLine.Used == 0 { // next line blank => end of para
 			return false
 		}
 		// In the middle of a paragraph so go to end of line
@@ -33,10 +34,10 @@ func currentWord(dot *MarkObject) bool {
 	if dot.Col == 0 {
 		return false
 	}
-	for (dot.Col > 1) && ChIsWordElement(0, rune(dot.Line.Str.Get(dot.Col))) {
+	for (dot.Col > 1) && ChIsWordElement(0, dot.Line.Str.Get(dot.Col)) {
 		dot.Col--
 	}
-	if ChIsWordElement(0, rune(dot.Line.Str.Get(dot.Col))) {
+	if ChIsWordElement(0, dot.Line.Str.Get(dot.Col)) {
 		// we must have been somewhere on the line before the first word
 		if dot.Line.BLink == nil { // oops top of the frame reached
 			return false
@@ -50,21 +51,21 @@ func currentWord(dot *MarkObject) bool {
 	}
 	// ASSERT: we now have dot sitting on part of a word
 	element := 0
-	for !ChIsWordElement(element, rune(dot.Line.Str.Get(dot.Col))) {
+	for !ChIsWordElement(element, dot.Line.Str.Get(dot.Col)) {
 		element++
 	}
 	// Now find the start of this word
-	for (dot.Col > 1) && ChIsWordElement(element, rune(dot.Line.Str.Get(dot.Col))) {
+	for (dot.Col > 1) && ChIsWordElement(element, dot.Line.Str.Get(dot.Col)) {
 		dot.Col--
 	}
-	if !ChIsWordElement(element, rune(dot.Line.Str.Get(dot.Col))) {
+	if !ChIsWordElement(element, dot.Line.Str.Get(dot.Col)) {
 		dot.Col++
 	}
 	return true
 }
 
 // nextWord positions the mark at the start of the next word
-func nextWord(dot *MarkObject) bool {
+func (e *Editor) nextWord(dot *MarkObject) bool {
 	if dot.Col > dot.Line.Used {
 		// check that we aren't on a blank line
 		if dot.Line.Used == 0 {
@@ -74,39 +75,40 @@ func nextWord(dot *MarkObject) bool {
 		dot.Col = dot.Line.Used
 	}
 	element := 0
-	for !ChIsWordElement(element, rune(dot.Line.Str.Get(dot.Col))) {
+	for !ChIsWordElement(element, dot.Line.Str.Get(dot.Col)) {
 		element++
 	}
-	for (dot.Col < dot.Line.Used) && ChIsWordElement(element, rune(dot.Line.Str.Get(dot.Col))) {
+	for (dot.Col < dot.Line.Used) && ChIsWordElement(element, dot.Line.Str.Get(dot.Col)) {
 		dot.Col++
 	}
-	if ChIsWordElement(element, rune(dot.Line.Str.Get(dot.Col))) {
-		if dot.Line.FLink == nil { // no more lines
+	if ChIsWordElement(element, dot.Line.Str.Get(dot.Col)) {
+		nextLine := e.FileNextLine(dot.Line)
+		if nextLine == nil { // no more lines
 			return false
 		}
-		if dot.Line.FLink.Used == 0 { // end of paragraph
+		if nextLine.Used == 0 { // end of paragraph
 			return false
 		}
-		if !MarkCreate(dot.Line.FLink, 1, &dot) {
+		if !MarkCreate(nextLine, 1, &dot) {
 			return false
 		}
 	}
-	for ChIsWordElement(0, rune(dot.Line.Str.Get(dot.Col))) {
+	for ChIsWordElement(0, dot.Line.Str.Get(dot.Col)) {
 		dot.Col++
 	}
 	return true
 }
 
 // previousWord positions the mark at the start of the previous word
-func previousWord(dot *MarkObject) bool {
+func (e *Editor) previousWord(dot *MarkObject) bool {
 	element := 0
-	for !ChIsWordElement(element, rune(dot.Line.Str.Get(dot.Col))) {
+	for !ChIsWordElement(element, dot.Line.Str.Get(dot.Col)) {
 		element++
 	}
-	for (dot.Col > 1) && ChIsWordElement(element, rune(dot.Line.Str.Get(dot.Col))) {
+	for (dot.Col > 1) && ChIsWordElement(element, dot.Line.Str.Get(dot.Col)) {
 		dot.Col--
 	}
-	if ChIsWordElement(element, rune(dot.Line.Str.Get(dot.Col))) {
+	if ChIsWordElement(element, dot.Line.Str.Get(dot.Col)) {
 		if dot.Line.BLink == nil { // no more lines
 			return false
 		}
@@ -117,23 +119,23 @@ func previousWord(dot *MarkObject) bool {
 			return false
 		}
 	}
-	if !currentWord(dot) {
+	if !e.currentWord(dot) {
 		return false
 	}
 	return true
 }
 
 // NewwordAdvanceWord advances cursor by word count
-func NewwordAdvanceWord(rept LeadParam, count int) bool {
+func (e *Editor) NewwordAdvanceWord(rept LeadParam, count int) bool {
 	result := false
 	var newDot *MarkObject
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &newDot) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &newDot) {
 		return false
 	}
 	if rept == LeadParamMarker {
 		if !MarkCreate(
-			CurrentFrame.Marks[count].Line,
-			CurrentFrame.Marks[count].Col,
+			e.CurrentFrame.Marks[count].Line,
+			e.CurrentFrame.Marks[count].Col,
 			&newDot,
 		) {
 			goto l98
@@ -149,26 +151,26 @@ func NewwordAdvanceWord(rept LeadParam, count int) bool {
 	case LeadParamNone, LeadParamPlus, LeadParamPInt:
 		for count > 0 {
 			count--
-			if !nextWord(newDot) {
+			if !e.nextWord(newDot) {
 				goto l98
 			}
 		}
-		if !MarkCreate(newDot.Line, newDot.Col, &CurrentFrame.Dot) {
+		if !MarkCreate(newDot.Line, newDot.Col, &e.CurrentFrame.Dot) {
 			goto l98
 		}
 
 	case LeadParamMinus, LeadParamNInt:
 		count = -count
-		if !currentWord(newDot) {
+		if !e.currentWord(newDot) {
 			goto l98
 		}
 		for count > 0 {
 			count--
-			if !previousWord(newDot) {
+			if !e.previousWord(newDot) {
 				goto l98
 			}
 		}
-		if !MarkCreate(newDot.Line, newDot.Col, &CurrentFrame.Dot) {
+		if !MarkCreate(newDot.Line, newDot.Col, &e.CurrentFrame.Dot) {
 			goto l98
 		}
 
@@ -187,8 +189,8 @@ func NewwordAdvanceWord(rept LeadParam, count int) bool {
 			// In the middle of a paragraph so go it end of line
 			newDot.Col = newDot.Line.Used
 		}
-		for nextWord(newDot) {
-			if !MarkCreate(newDot.Line, newDot.Col, &CurrentFrame.Dot) {
+		for e.nextWord(newDot) {
+			if !MarkCreate(newDot.Line, newDot.Col, &e.CurrentFrame.Dot) {
 				goto l98
 			}
 		}
@@ -196,22 +198,22 @@ func NewwordAdvanceWord(rept LeadParam, count int) bool {
 		//*** next statement should be more sophisticated
 		//    what about the right margin??
 		if newDot.Line.Used+2 > MaxStrLenP {
-			if !MarkCreate(newDot.Line, MaxStrLenP, &CurrentFrame.Dot) {
+			if !MarkCreate(newDot.Line, MaxStrLenP, &e.CurrentFrame.Dot) {
 				goto l98
 			}
-		} else if !MarkCreate(newDot.Line, newDot.Line.Used+2, &CurrentFrame.Dot) {
+		} else if !MarkCreate(newDot.Line, newDot.Line.Used+2, &e.CurrentFrame.Dot) {
 			goto l98
 		}
 
 	case LeadParamNIndef:
-		if !currentWord(newDot) {
+		if !e.currentWord(newDot) {
 			goto l98
 		}
-		if !MarkCreate(newDot.Line, newDot.Col, &CurrentFrame.Dot) {
+		if !MarkCreate(newDot.Line, newDot.Col, &e.CurrentFrame.Dot) {
 			goto l98
 		}
-		for previousWord(newDot) {
-			if !MarkCreate(newDot.Line, newDot.Col, &CurrentFrame.Dot) {
+		for e.previousWord(newDot) {
+			if !MarkCreate(newDot.Line, newDot.Col, &e.CurrentFrame.Dot) {
 				goto l98
 			}
 		}
@@ -227,7 +229,7 @@ l98:
 }
 
 // NewwordDeleteWord deletes words (same words as advance word advances over)
-func NewwordDeleteWord(rept LeadParam, count int) bool {
+func (e *Editor) NewwordDeleteWord(rept LeadParam, count int) bool {
 	result := false
 	var oldPos *MarkObject
 	var here *MarkObject
@@ -236,26 +238,26 @@ func NewwordDeleteWord(rept LeadParam, count int) bool {
 	var newLineNr int
 	var oldDotCol int
 
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &oldPos) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &oldPos) {
 		goto l99
 	}
 	// First Step: Get to the beginning of the word if we are in the middle of it
-	if !NewwordAdvanceWord(LeadParamPInt, 0) {
+	if !e.NewwordAdvanceWord(LeadParamPInt, 0) {
 		goto l99
 	}
 	// ASSERTION: We are on the beginning of a word
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &here) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &here) {
 		goto l99
 	}
-	if !NewwordAdvanceWord(rept, count) {
+	if !e.NewwordAdvanceWord(rept, count) {
 		// Put Dot back and bail out
-		if !MarkCreate(oldPos.Line, oldPos.Col, &CurrentFrame.Dot) {
+		if !MarkCreate(oldPos.Line, oldPos.Col, &e.CurrentFrame.Dot) {
 			goto l99
 		}
 	}
 	// OK. We now wipe out everything from Dot to here
-	oldDotCol = CurrentFrame.Dot.Col
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &theOtherMark) {
+	oldDotCol = e.CurrentFrame.Dot.Col
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &theOtherMark) {
 		goto l99
 	}
 	if !LineToNumber(theOtherMark.Line, &lineNr) {
@@ -270,28 +272,28 @@ func NewwordDeleteWord(rept LeadParam, count int) bool {
 		here = theOtherMark
 		theOtherMark = anotherMark
 	}
-	if CurrentFrame != FrameOops {
+	if e.CurrentFrame != e.FrameOops {
 		// Make sure oops_span is okay.
-		if !MarkCreate(FrameOops.LastGroup.LastLine, 1, &FrameOops.Span.MarkTwo) {
+		if !MarkCreate(e.FrameOops.LastGroup.LastLine, 1, &e.FrameOops.Span.MarkTwo) {
 			goto l99
 		}
-		result = TextMove(
-			false,                        // Don't copy, transfer
-			1,                            // One instance of
-			theOtherMark,                 // starting pos.
-			here,                         // ending pos.
-			FrameOops.Span.MarkTwo,       // destination.
-			&FrameOops.Marks[MarkEquals], // leave at start.
-			&FrameOops.Dot,               // leave at end.
+		result = e.TextMove(
+			false,                          // Don't copy, transfer
+			1,                              // One instance of
+			theOtherMark,                   // starting pos.
+			here,                           // ending pos.
+			e.FrameOops.Span.MarkTwo,       // destination.
+			&e.FrameOops.Marks[MarkEquals], // leave at start.
+			&e.FrameOops.Dot,               // leave at end.
 		)
 	} else {
-		result = TextRemove(
+		result = e.TextRemove(
 			theOtherMark, // starting pos.
 			here,         // ending pos.
 		)
 	}
 	if lineNr != newLineNr {
-		result = TextSplitLine(CurrentFrame.Dot, oldDotCol, &here)
+		result = e.TextSplitLine(e.CurrentFrame.Dot, oldDotCol, &here)
 	}
 l99:
 	if oldPos != nil {
@@ -312,10 +314,10 @@ func currentParagraph(dot *MarkObject) bool {
 	var pos int
 	if dot.Col < dot.Line.Used {
 		pos = dot.Col
-		for (pos > 1) && ChIsWordElement(0, rune(newLine.Str.Get(pos))) {
+		for (pos > 1) && ChIsWordElement(0, newLine.Str.Get(pos)) {
 			pos--
 		}
-		if ChIsWordElement(0, rune(newLine.Str.Get(pos))) {
+		if ChIsWordElement(0, newLine.Str.Get(pos)) {
 			if newLine.BLink == nil {
 				return false
 			}
@@ -335,25 +337,25 @@ func currentParagraph(dot *MarkObject) bool {
 		newLine = newLine.FLink // Oops too far!
 	}
 	pos = 1
-	for ChIsWordElement(0, rune(newLine.Str.Get(pos))) {
+	for ChIsWordElement(0, newLine.Str.Get(pos)) {
 		pos++
 	}
 	return MarkCreate(newLine, pos, &dot)
 }
 
 // nextParagraph positions the mark at the start of the next paragraph
-func nextParagraph(dot *MarkObject) bool {
+func (e *Editor) nextParagraph(dot *MarkObject) bool {
 	newLine := dot.Line
 	var pos int
 	if dot.Col < dot.Line.Used {
 		pos = dot.Col
-		for (pos > 1) && ChIsWordElement(0, rune(newLine.Str.Get(pos))) {
+		for (pos > 1) && ChIsWordElement(0, newLine.Str.Get(pos)) {
 			pos--
 		}
-		if ChIsWordElement(0, rune(newLine.Str.Get(pos))) {
+		if ChIsWordElement(0, newLine.Str.Get(pos)) {
 			if newLine.BLink == nil {
 				dot.Col = 1
-				for ChIsWordElement(0, rune(newLine.Str.Get(dot.Col))) {
+				for ChIsWordElement(0, newLine.Str.Get(dot.Col)) {
 					dot.Col++
 				}
 				return true
@@ -362,36 +364,36 @@ func nextParagraph(dot *MarkObject) bool {
 		}
 	}
 	for (newLine.FLink != nil) && (newLine.Used != 0) {
-		newLine = newLine.FLink
+		newLine = e.FileNextLine(newLine)
 	}
 	if newLine.Used != 0 {
 		return false
 	}
 	for (newLine.FLink != nil) && (newLine.Used == 0) {
-		newLine = newLine.FLink
+		newLine = e.FileNextLine(newLine)
 	}
 	if newLine.Used == 0 {
 		return false
 	}
 	pos = 1
-	for ChIsWordElement(0, rune(newLine.Str.Get(pos))) {
+	for ChIsWordElement(0, newLine.Str.Get(pos)) {
 		pos++
 	}
 	return MarkCreate(newLine, pos, &dot)
 }
 
 // NewwordAdvanceParagraph advances cursor by paragraph count
-func NewwordAdvanceParagraph(rept LeadParam, count int) bool {
+func (e *Editor) NewwordAdvanceParagraph(rept LeadParam, count int) bool {
 	result := false
 	var newDot *MarkObject
 
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &newDot) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &newDot) {
 		return false
 	}
 	if rept == LeadParamMarker {
 		if !MarkCreate(
-			CurrentFrame.Marks[count].Line,
-			CurrentFrame.Marks[count].Col,
+			e.CurrentFrame.Marks[count].Line,
+			e.CurrentFrame.Marks[count].Col,
 			&newDot,
 		) {
 			goto l98
@@ -407,11 +409,11 @@ func NewwordAdvanceParagraph(rept LeadParam, count int) bool {
 	case LeadParamNone, LeadParamPlus, LeadParamPInt:
 		for count > 0 {
 			count--
-			if !nextParagraph(newDot) {
+			if !e.nextParagraph(newDot) {
 				goto l98
 			}
 		}
-		if !MarkCreate(newDot.Line, newDot.Col, &CurrentFrame.Dot) {
+		if !MarkCreate(newDot.Line, newDot.Col, &e.CurrentFrame.Dot) {
 			goto l98
 		}
 
@@ -432,15 +434,15 @@ func NewwordAdvanceParagraph(rept LeadParam, count int) bool {
 				goto l98
 			}
 		}
-		if !MarkCreate(newDot.Line, newDot.Col, &CurrentFrame.Dot) {
+		if !MarkCreate(newDot.Line, newDot.Col, &e.CurrentFrame.Dot) {
 			goto l98
 		}
 
 	case LeadParamPIndef:
 		if !MarkCreate(
-			CurrentFrame.LastGroup.LastLine,
-			CurrentFrame.MarginLeft,
-			&CurrentFrame.Dot,
+			e.CurrentFrame.LastGroup.LastLine,
+			e.CurrentFrame.MarginLeft,
+			&e.CurrentFrame.Dot,
 		) {
 			goto l98
 		}
@@ -455,15 +457,15 @@ func NewwordAdvanceParagraph(rept LeadParam, count int) bool {
 		}
 		// OK we know that there is a paragraph behind us, so goto
 		// the top of the file and go down to the first paragraph
-		newLine = CurrentFrame.FirstGroup.FirstLine
+		newLine = e.CurrentFrame.FirstGroup.FirstLine
 		for newLine.Used == 0 {
 			newLine = newLine.FLink
 		}
 		pos := 1
-		for ChIsWordElement(0, rune(newLine.Str.Get(pos))) {
+		for ChIsWordElement(0, newLine.Str.Get(pos)) {
 			pos++
 		}
-		if !MarkCreate(newLine, pos, &CurrentFrame.Dot) {
+		if !MarkCreate(newLine, pos, &e.CurrentFrame.Dot) {
 			goto l98
 		}
 
@@ -478,7 +480,7 @@ l98:
 }
 
 // NewwordDeleteParagraph deletes paragraphs
-func NewwordDeleteParagraph(rept LeadParam, count int) bool {
+func (e *Editor) NewwordDeleteParagraph(rept LeadParam, count int) bool {
 	result := false
 	var oldPos *MarkObject
 	var here *MarkObject
@@ -486,24 +488,24 @@ func NewwordDeleteParagraph(rept LeadParam, count int) bool {
 	var lineNr int
 	var newLineNr int
 
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &oldPos) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &oldPos) {
 		goto l99
 	}
 	// Get to the beginning of the paragraph
-	if !NewwordAdvanceParagraph(LeadParamPInt, 0) {
+	if !e.NewwordAdvanceParagraph(LeadParamPInt, 0) {
 		goto l99
 	}
-	if !MarkCreate(CurrentFrame.Dot.Line, 1, &here) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, 1, &here) {
 		goto l99
 	}
-	if !NewwordAdvanceParagraph(rept, count) {
+	if !e.NewwordAdvanceParagraph(rept, count) {
 		// Something wrong so put dot back and abort
-		MarkCreate(oldPos.Line, oldPos.Col, &CurrentFrame.Dot)
+		MarkCreate(oldPos.Line, oldPos.Col, &e.CurrentFrame.Dot)
 		goto l99
 	}
 
 	// Now delete all the lines between marks dot and here
-	if !MarkCreate(CurrentFrame.Dot.Line, 1, &theOtherMark) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, 1, &theOtherMark) {
 		goto l99
 	}
 	if !LineToNumber(theOtherMark.Line, &lineNr) {
@@ -518,22 +520,22 @@ func NewwordDeleteParagraph(rept LeadParam, count int) bool {
 		here = theOtherMark
 		theOtherMark = anotherMark
 	}
-	if CurrentFrame != FrameOops {
+	if e.CurrentFrame != e.FrameOops {
 		// Make sure oops_span is okay.
-		if !MarkCreate(FrameOops.LastGroup.LastLine, 1, &FrameOops.Span.MarkTwo) {
+		if !MarkCreate(e.FrameOops.LastGroup.LastLine, 1, &e.FrameOops.Span.MarkTwo) {
 			goto l99
 		}
-		result = TextMove(
-			false,                        // Don't copy, transfer
-			1,                            // One instance of
-			theOtherMark,                 // starting pos.
-			here,                         // ending pos.
-			FrameOops.Span.MarkTwo,       // destination.
-			&FrameOops.Marks[MarkEquals], // leave at start.
-			&FrameOops.Dot,               // leave at end.
+		result = e.TextMove(
+			false,                          // Don't copy, transfer
+			1,                              // One instance of
+			theOtherMark,                   // starting pos.
+			here,                           // ending pos.
+			e.FrameOops.Span.MarkTwo,       // destination.
+			&e.FrameOops.Marks[MarkEquals], // leave at start.
+			&e.FrameOops.Dot,               // leave at end.
 		)
 	} else {
-		result = TextRemove(
+		result = e.TextRemove(
 			theOtherMark, // starting pos.
 			here,         // ending pos.
 		)
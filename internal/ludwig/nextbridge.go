@@ -16,7 +16,7 @@ package ludwig
 
 // NextbridgeCommand implements the NEXT and BRIDGE commands
 // NEXT searches for characters in the set, BRIDGE searches for characters NOT in the set
-func NextbridgeCommand(count int, tpar *TParObject, bridge bool) bool {
+func (e *Editor) NextbridgeCommand(count int, tpar *TParObject, bridge bool) bool {
 	// Form the character set
 	chars := [256]bool{}
 	i := 1
@@ -46,11 +46,11 @@ func NextbridgeCommand(count int, tpar *TParObject, bridge bool) bool {
 	}
 
 	// Search for a character in the set
-	newLine := CurrentFrame.Dot.Line
+	newLine := e.CurrentFrame.Dot.Line
 	var newCol int
 
 	if count > 0 {
-		newCol = CurrentFrame.Dot.Col
+		newCol = e.CurrentFrame.Dot.Col
 		if !bridge {
 			newCol++
 		}
@@ -68,7 +68,7 @@ func NextbridgeCommand(count int, tpar *TParObject, bridge bool) bool {
 					newCol = i
 					goto l1
 				}
-				newLine = newLine.FLink
+				newLine = e.FileNextLine(newLine)
 				newCol = 1
 			}
 			return false
@@ -81,14 +81,14 @@ func NextbridgeCommand(count int, tpar *TParObject, bridge bool) bool {
 		}
 		newCol--
 		if !MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkEquals],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkEquals],
 		) {
 			return false
 		}
 	} else if count < 0 {
-		newCol = CurrentFrame.Dot.Col - 1
+		newCol = e.CurrentFrame.Dot.Col - 1
 		if !bridge {
 			newCol--
 		}
@@ -124,20 +124,20 @@ func NextbridgeCommand(count int, tpar *TParObject, bridge bool) bool {
 		}
 		newCol += 2
 		if !MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkEquals],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkEquals],
 		) {
 			return false
 		}
 	} else {
 		return MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkEquals],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkEquals],
 		)
 	}
 
 	// Found it, move dot to new point
-	return MarkCreate(newLine, newCol, &CurrentFrame.Dot)
+	return MarkCreate(newLine, newCol, &e.CurrentFrame.Dot)
 }
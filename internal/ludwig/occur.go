@@ -0,0 +1,187 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         OCCUR
+//
+// Description:  The UO command.  Frame OCCUR lists the lines of a frame
+//               that a target matches, and from there Dot can be put on
+//               any of the lines in the frame they came from.
+
+package ludwig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	frameNameOccur = "OCCUR"
+
+	// The number of heading lines above the line for the first match
+	occurHeading = 1
+)
+
+// occurEntry is a line listed in frame OCCUR, with the column of the first
+// match on it
+type occurEntry struct {
+	line int
+	col  int
+}
+
+// Occur is the UO command.  From any other frame it lists the lines of the
+// frame that the target matches in frame OCCUR, each after its line number,
+// and goes there with Dot on the first line listed at or after Dot's line.
+// The target is searched for as G does, and becomes the target for G; an
+// empty target is the last target of G.  In frame OCCUR, UO goes back to
+// the frame the lines came from, with Dot at the first match on the line
+// listed on Dot's line.
+func (e *Editor) Occur(tparam *TParObject) bool {
+	if e.frameOccur != nil && e.CurrentFrame == e.frameOccur {
+		return e.occurGoTo()
+	}
+
+	var request TParObject
+	if !e.TparGet1(tparam, CmdOccur, &request) {
+		return false
+	}
+	frame := e.CurrentFrame
+	if request.Len == 0 {
+		if frame.GetTpar.Len == 0 {
+			e.ScreenMessage(MsgNoDefaultStr)
+			return false
+		}
+	} else {
+		frame.GetTpar = request
+	}
+	var target regexpTarget
+	switch frame.GetTpar.Dlm {
+	case TpdSmart:
+		if !e.eqsgetrepPatternBuild(frame.GetTpar, &frame.GetPatternPtr) {
+			return false
+		}
+	case TpdRegexp:
+		if !e.regexpCompile(&frame.GetTpar, &target) {
+			return false
+		}
+	}
+
+	var dotNr int
+	LineToNumber(frame.Dot.Line, &dotNr)
+	var entries []occurEntry
+	var text []byte
+	dotLine := 0
+	for line := frame.FirstGroup.FirstLine; !e.TtControlC.Load(); line = line.FLink {
+		var col int
+		if line, col = e.occurNext(frame, &target, line); line == nil {
+			break
+		}
+		var lineNr int
+		LineToNumber(line, &lineNr)
+		entries = append(entries, occurEntry{line: lineNr, col: col})
+		// Bytes that were not UTF-8 are listed as they were read
+		text = fmt.Appendf(text, "%6d  ", lineNr)
+		text = ChAppendUTF8(text, line.Str, 1, line.Used)
+		text = append(text, '\n')
+		if dotLine == 0 && lineNr >= dotNr {
+			dotLine = len(entries) + occurHeading
+		}
+	}
+	if len(entries) == 0 {
+		e.ScreenMessage(MsgNoMatchingLines)
+		return false
+	}
+
+	if !e.FrameCreateExtra(frameNameOccur, &e.frameOccur) {
+		return false
+	}
+	// A target typed at the prompt has no delimiter, and is shown as for G
+	dlm := rune(frame.GetTpar.Dlm)
+	if dlm == 0 {
+		dlm = '/'
+	}
+	heading := fmt.Sprintf("Lines of frame %s matching %c%s%c: %d\n", frame.Span.Name,
+		dlm, frame.GetTpar.Str.Slice(1, frame.GetTpar.Len), dlm, len(entries))
+	if !e.FrameReplaceText(e.frameOccur, heading+string(text)) {
+		return false
+	}
+	e.occurSource = frame.Span.Name
+	e.occurList = entries
+	if dotLine == 0 {
+		dotLine = len(entries) + occurHeading
+	}
+	if !e.FramePositionDot(e.frameOccur, dotLine, 1) {
+		return false
+	}
+	return e.FrameEdit(frameNameOccur)
+}
+
+// occurNext returns the first line at or after line that the frame's G
+// target matches, and the column the match starts at, or nil if there is
+// none.  A match may be empty, and a regular expression may match on over
+// the end of the line, as for G.
+func (e *Editor) occurNext(frame *FrameObject, target *regexpTarget, line *LineHdrObject) (*LineHdrObject, int) {
+	if frame.GetTpar.Dlm == TpdRegexp {
+		if line.FLink == nil {
+			return nil, 0
+		}
+		findLine, findCol, loc := target.regexpFind(line, 1, nil, 0)
+		if loc == nil {
+			return nil, 0
+		}
+		return regexpPosition(findLine, findCol, loc[0])
+	}
+	for ; line.FLink != nil && !e.TtControlC.Load(); line = line.FLink {
+		if frame.GetTpar.Dlm == TpdSmart {
+			markFlag := false
+			var start, finish int
+			if e.PatternRecognize(frame.GetPatternPtr, line, 1, &markFlag, &start, &finish) {
+				return line, start
+			}
+		} else if runs := matchLiteral(frame.GetTpar, line); runs != nil {
+			return line, runs[0].Col
+		}
+	}
+	return nil, 0
+}
+
+// occurGoTo edits the frame the lines in frame OCCUR came from, with Dot at
+// the first match on the line whose number starts Dot's line.  The number
+// is read from the text, as OCCUR may have been edited since it was listed.
+func (e *Editor) occurGoTo() bool {
+	dotLine := e.frameOccur.Dot.Line
+	var lineNr int
+	if dotLine.Used > 0 {
+		fields := strings.Fields(dotLine.Str.Slice(1, dotLine.Used))
+		if len(fields) > 0 {
+			lineNr, _ = strconv.Atoi(fields[0])
+		}
+	}
+	if lineNr <= 0 {
+		e.ScreenMessage(MsgNotInOccurList)
+		return false
+	}
+	col := 1
+	for _, entry := range e.occurList {
+		if entry.line == lineNr {
+			col = entry.col
+			break
+		}
+	}
+	var span, oldp *SpanObject
+	if !e.SpanFind(e.occurSource, &span, &oldp) || span.Frame == nil {
+		e.ScreenMessage(MsgNoSuchFrame)
+		return false
+	}
+	if !e.FrameEdit(e.occurSource) {
+		return false
+	}
+	return e.FramePositionDot(e.CurrentFrame, lineNr, col)
+}
@@ -0,0 +1,121 @@
+// Tests for listing the lines a target matches
+
+package ludwig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOccur(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("one cat\ntwo\nthree cats\nfour\nCAT five\n")))
+
+	// Dot goes to the first line listed at or after the line it was on
+	require.True(t, e.BatchExecute("2a uo/cat/"), messages.String())
+	assert.Equal(t, frameNameOccur, e.CurrentFrame.Span.Name)
+	assert.Equal(t, []string{
+		"Lines of frame LUDWIG matching /cat/: 3",
+		"     1  one cat",
+		"     3  three cats",
+		"     5  CAT five",
+	}, frameText(e.CurrentFrame))
+	assert.Equal(t, [2]int{3, 1}, markAt(e.CurrentFrame.Dot))
+	assert.False(t, e.CurrentFrame.TextModified)
+
+	// UO in the list goes to the first match on the line
+	require.True(t, e.BatchExecute("a uo//"), messages.String())
+	assert.Equal(t, DefaultFrameName, e.CurrentFrame.Span.Name)
+	assert.Equal(t, [2]int{5, 1}, markAt(e.CurrentFrame.Dot))
+	require.True(t, e.BatchExecute("er -a uo//"), messages.String())
+	assert.Equal(t, [2]int{3, 7}, markAt(e.CurrentFrame.Dot))
+
+	// The target becomes the target for G
+	require.True(t, e.BatchExecute("g//"), messages.String())
+	assert.Equal(t, [2]int{3, 10}, markAt(e.CurrentFrame.Dot))
+
+	// There is nothing to go to on the heading
+	messages.Reset()
+	assert.False(t, e.BatchExecute("er <a uo//"))
+	assert.Contains(t, messages.String(), MsgNotInOccurList)
+}
+
+func TestOccurBytes(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("caf\xe9 cat\nnone\n")))
+
+	// A byte that is not UTF-8 is listed as it was read
+	require.True(t, e.BatchExecute("uo/cat/"), messages.String())
+	line := e.CurrentFrame.FirstGroup.FirstLine.FLink
+	assert.Equal(t, []byte("     1  caf\xe9 cat"), ChAppendUTF8(nil, line.Str, 1, line.Used))
+}
+
+func TestOccurTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		lines   []int
+	}{
+		{"exact case", `uo"CAT"`, []int{3}},
+		{"pattern", "uo`+n`", []int{2}},
+		{"regular expression", `uo~(?i)^c~`, []int{1, 3}},
+		{"last target of G", "g/two/ uo//", []int{2}},
+		{"empty matches", `uo~^$~`, []int{4}},
+		{"over the end of a line", `uo~(?i)2\nc~`, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages bytes.Buffer
+			e := newBatchEditor(t, &messages)
+			require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("cat\ntwo 2\nCAT\n\ndog\n")))
+			require.True(t, e.BatchExecute(tt.command), messages.String())
+			var lines []int
+			for _, entry := range e.occurList {
+				lines = append(lines, entry.line)
+			}
+			assert.Equal(t, tt.lines, lines)
+		})
+	}
+}
+
+func TestOccurEdited(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("one cat\ntwo\nthree cats\n")))
+	require.True(t, e.BatchExecute("uo/cat/"), messages.String())
+
+	// The line comes from the number on Dot's line, not where it was listed
+	require.True(t, e.BatchExecute("-a k"), messages.String())
+	require.Equal(t, "     3  three cats", frameText(e.CurrentFrame)[1])
+	require.Equal(t, 1, markAt(e.CurrentFrame.Dot)[0])
+	require.True(t, e.BatchExecute("a uo//"), messages.String())
+	assert.Equal(t, [2]int{3, 7}, markAt(e.CurrentFrame.Dot))
+
+	// A line without a number has nowhere to go
+	messages.Reset()
+	require.True(t, e.BatchExecute("er -a i/x/"), messages.String())
+	assert.False(t, e.BatchExecute("uo//"))
+	assert.Contains(t, messages.String(), MsgNotInOccurList)
+}
+
+func TestOccurFails(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("one\n")))
+
+	assert.False(t, e.BatchExecute("uo//"))
+	assert.Contains(t, messages.String(), MsgNoDefaultStr)
+	messages.Reset()
+	assert.False(t, e.BatchExecute("uo/dog/"))
+	assert.Contains(t, messages.String(), MsgNoMatchingLines)
+	assert.Equal(t, DefaultFrameName, e.CurrentFrame.Span.Name)
+	assert.False(t, e.BatchExecute("uo~(~"))
+	assert.Contains(t, messages.String(), "Regular expression - missing closing ).")
+}
@@ -10,63 +10,162 @@
 
 // Name:         OPSYS
 //
-// Description:  This routine executes a command in a subprocess and
-//               transfers the result into the current frame.
+// Description:  These routines execute a command in a subprocess and
+//               transfer the result into the current frame.
 
 package ludwig
 
-// OpsysCommand executes a command in a subprocess and returns the output as lines
-func OpsysCommand(command *TParObject, first **LineHdrObject, last **LineHdrObject, actualCnt *int) bool {
+import (
+	"fmt"
+	"strings"
+)
+
+// The frame that gets what a failed command wrote to standard error
+const frameNameErrors = "ERRORS"
+
+// OpsysCommand executes a command in a subprocess and returns the output as
+// lines, with what the command wrote to standard output and standard error
+// interleaved.  If the command exits with a non-zero status no lines are
+// returned, the output goes to frame ERRORS instead, and it fails.
+func (e *Editor) OpsysCommand(command *TParObject, first **LineHdrObject, last **LineHdrObject, actualCnt *int) bool {
 	*first = nil
 	*last = nil
 	*actualCnt = 0
 
-	var mbx FileObject
-	mbx.Valid = false
-	mbx.FirstLine = nil
-	mbx.LastLine = nil
-	mbx.LineCount = 0
-	mbx.OutputFlag = false
-	if command.Len <= FileNameLen {
-		mbx.Filename = command.Str.Slice(1, command.Len)
+	if !SysCommand(command.Str.Slice(1, command.Len), &e.opsysOutput, &e.opsysStatus, &e.opsysSignal) || e.opsysStatus != 0 {
+		e.opsysFailed(e.opsysOutput, e.opsysStatus, e.opsysSignal)
+		return false
+	}
+	outFile := &FileObject{Valid: true, Reader: strings.NewReader(e.opsysOutput)}
+	return e.FileRead(outFile, MaxInt, true, first, last, actualCnt)
+}
+
+// OpsysFilter pipes lines of the current frame through a command, and puts
+// what the command writes in their place.  The lines are those of the span
+// named, or if no span is named those given by rept and count as for K.
+// If the command fails the text is left alone.
+func (e *Editor) OpsysFilter(rept LeadParam, count int, tparam *TParObject) bool {
+	var spanName, command TParObject
+	if !e.TparGet2(tparam, CmdOpSysFilter, &spanName, &command) {
+		return false
+	}
+	if command.Len == 0 {
+		return false
+	}
+
+	frame := e.CurrentFrame
+	var span *SpanObject
+	var firstLine, lastLine *LineHdrObject
+	if spanName.Len == 0 {
+		if !ExecComputeLineRange(frame, rept, count, &firstLine, &lastLine) {
+			return false
+		}
 	} else {
-		mbx.Filename = ""
+		if rept != LeadParamNone {
+			e.ScreenMessage(MsgIllegalLeadingParam)
+			return false
+		}
+		if !e.opsysSpanLines(spanName.Str.Slice(1, spanName.Len), &span, &firstLine, &lastLine) {
+			return false
+		}
+	}
+	if firstLine == nil {
+		return true
 	}
 
-	if !FilesysCreateOpen(&mbx, nil, false) {
+	// Bytes that were not UTF-8 are given to the command as they were read
+	var input []byte
+	for line := firstLine; ; line = line.FLink {
+		input = ChAppendUTF8(input, line.Str, 1, line.Used)
+		input = append(input, '\n')
+		if line == lastLine {
+			break
+		}
+	}
+	var output string
+	if !SysFilter(command.Str.Slice(1, command.Len), string(input), &output, &e.opsysOutput, &e.opsysStatus, &e.opsysSignal) || e.opsysStatus != 0 {
+		e.opsysFailed(e.opsysOutput, e.opsysStatus, e.opsysSignal)
+		return false
+	}
+
+	// Read the output before touching the text, so that nothing is lost if
+	// it cannot be read
+	outFile := &FileObject{Valid: true, Reader: strings.NewReader(output)}
+	var newFirst, newLast *LineHdrObject
+	var nrLines int
+	if !e.FileRead(outFile, MaxInt, true, &newFirst, &newLast, &nrLines) {
+		return false
+	}
+	nextLine := lastLine.FLink
+	if !MarksSqueeze(firstLine, 1, nextLine, 1) {
+		return false
+	}
+	if !e.LinesExtract(firstLine, lastLine) {
+		return false
+	}
+	if !e.execOopsLines(firstLine, lastLine) {
+		return false
+	}
+	if newFirst == nil {
+		newFirst = nextLine
+	} else if !e.LinesInject(newFirst, newLast, nextLine) {
 		return false
 	}
 
-	opsysResult := false
-	for !mbx.Eof {
-		result := NewBlankStrObject(MaxStrLen)
-		var outlen int
-		if FilesysRead(&mbx, result, &outlen) {
-			var line *LineHdrObject
-			var line2 *LineHdrObject
-			if !LinesCreate(1, &line, &line2) {
-				goto l98
-			}
-			if !LineChangeLength(line, outlen) {
-				LinesDestroy(&line, &line2)
-				goto l98
-			}
-			ChFillCopy(result, 1, outlen, line.Str, 1, line.Len(), ' ')
-			line.Used = outlen
-			line.BLink = *last
-			if *last != nil {
-				(*last).FLink = line
-			} else {
-				*first = line
-			}
-			*last = line
-			*actualCnt += 1
-		} else if !mbx.Eof { // Something terrible has happened!
-			goto l98
+	if span != nil && span.Frame == nil {
+		if !MarkCreate(newFirst, 1, &span.MarkOne) || !MarkCreate(nextLine, 1, &span.MarkTwo) {
+			return false
 		}
 	}
-	opsysResult = true
-l98:
-	FilesysClose(&mbx, 0, false)
-	return opsysResult
+	if !MarkCreate(newFirst, 1, &frame.Marks[MarkEquals]) {
+		return false
+	}
+	if !MarkCreate(nextLine, 1, &frame.Marks[MarkModified]) {
+		return false
+	}
+	frame.TextModified = true
+	return MarkCreate(newFirst, 1, &frame.Dot)
+}
+
+// opsysSpanLines finds the lines of the current frame a span covers.  A
+// line the span only reaches the start of is not included, nor is the null
+// line.  A frame covers all its lines.
+func (e *Editor) opsysSpanLines(name string, span **SpanObject, firstLine, lastLine **LineHdrObject) bool {
+	var oldp *SpanObject
+	if !e.SpanFind(name, span, &oldp) {
+		e.ScreenMessage(MsgNoSuchSpan)
+		return false
+	}
+	markOne, markTwo := (*span).MarkOne, (*span).MarkTwo
+	if markOne.Line.Group.Frame != e.CurrentFrame {
+		e.ScreenMessage(MsgSpanNotInFrame)
+		return false
+	}
+	*firstLine = markOne.Line
+	*lastLine = markTwo.Line
+	if (markTwo.Col == 1 && *lastLine != *firstLine) || (*lastLine).FLink == nil {
+		*lastLine = (*lastLine).BLink
+	}
+	if *lastLine == nil || (*firstLine).FLink == nil || *lastLine == (*firstLine).BLink {
+		*firstLine = nil
+	}
+	return true
+}
+
+// opsysFailed puts what a command that failed wrote to standard error in
+// frame ERRORS, and says how it failed
+func (e *Editor) opsysFailed(errors string, status int, signal string) {
+	if e.FrameCreateExtra(frameNameErrors, &e.frameErrors) {
+		e.FrameReplaceText(e.frameErrors, errors)
+	}
+	if first, _, _ := strings.Cut(strings.TrimSpace(errors), "\n"); first != "" {
+		e.ScreenMessage(first)
+	}
+	if status < 0 {
+		e.ScreenMessage(fmt.Sprintf("Command could not be run, see frame %s.", frameNameErrors))
+	} else if signal != "" {
+		e.ScreenMessage(fmt.Sprintf("Command was killed by signal (%s), see frame %s.", signal, frameNameErrors))
+	} else {
+		e.ScreenMessage(fmt.Sprintf("Command failed with exit status %d, see frame %s.", status, frameNameErrors))
+	}
 }
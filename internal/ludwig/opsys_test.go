@@ -0,0 +1,121 @@
+// Tests for running commands in a subprocess
+
+package ludwig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpsysFilterLines(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("keep\ncherry\napple\nbanana\nlast\n")))
+
+	// The lines from Dot to a mark, then the lines from Dot to the end
+	require.True(t, e.BatchExecute("a 1m 3a @1uf//sort/"), messages.String())
+	assert.Equal(t, []string{"keep", "apple", "banana", "cherry", "last"}, frameText(e.CurrentFrame))
+	assert.Equal(t, "apple", e.CurrentFrame.Dot.Line.Str.Slice(1, e.CurrentFrame.Dot.Line.Used))
+	assert.True(t, e.CurrentFrame.TextModified)
+
+	require.True(t, e.BatchExecute("a >uf//tr a-z A-Z | head -1/"), messages.String())
+	assert.Equal(t, []string{"keep", "apple", "BANANA"}, frameText(e.CurrentFrame))
+
+	// What the lines were goes to OOPS
+	assert.Equal(t, []string{"cherry", "apple", "banana", "banana", "cherry", "last"}, frameText(e.FrameOops))
+}
+
+func TestOpsysFilterBytes(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("caf\xe9\nz\xe9bra\n")))
+	want := e.CurrentFrame.FirstGroup.FirstLine.Str.Runes()[:4]
+
+	// A filter that changes nothing leaves bytes that are not UTF-8 alone
+	require.True(t, e.BatchExecute("2uf//cat/"), messages.String())
+	line := e.CurrentFrame.FirstGroup.FirstLine
+	assert.Equal(t, want, line.Str.Runes()[:line.Used])
+	assert.Equal(t, []byte("z\xe9bra"), ChAppendUTF8(nil, line.FLink.Str, 1, line.FLink.Used))
+}
+
+func TestOpsysFilterSpan(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("one\ntwo\nthree\n")))
+
+	// The span covers the whole of its lines, and the new lines afterwards
+	require.True(t, e.BatchExecute("a 2j 1m a 1j sd/S/ uf/S/rev/"), messages.String())
+	assert.Equal(t, []string{"one", "owt", "eerht"}, frameText(e.CurrentFrame))
+	var span, oldp *SpanObject
+	require.True(t, e.SpanFind("S", &span, &oldp))
+	assert.Equal(t, e.CurrentFrame.Dot.Line, span.MarkOne.Line)
+	assert.Equal(t, e.CurrentFrame.LastGroup.LastLine, span.MarkTwo.Line)
+
+	// A count cannot be given with a span
+	assert.False(t, e.BatchExecute("2uf/S/rev/"))
+	assert.Contains(t, messages.String(), MsgIllegalLeadingParam)
+	messages.Reset()
+	assert.False(t, e.BatchExecute("uf/NONE/rev/"))
+	assert.Contains(t, messages.String(), MsgNoSuchSpan)
+}
+
+func TestOpsysFilterFails(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("text\n")))
+
+	// The text is left alone, and standard error goes to frame ERRORS
+	assert.False(t, e.BatchExecute("uf//cat; echo oops >&2; echo more >&2; exit 3/"))
+	assert.Equal(t, []string{"text"}, frameText(e.CurrentFrame))
+	assert.False(t, e.CurrentFrame.TextModified)
+	assert.Contains(t, messages.String(), "oops\n")
+	assert.Contains(t, messages.String(), "exit status 3")
+	require.NotNil(t, e.frameErrors)
+	assert.Equal(t, []string{"oops", "more"}, frameText(e.frameErrors))
+
+	// Frame ERRORS only has the latest errors
+	require.True(t, e.BatchExecute("uf//cat/"))
+	assert.False(t, e.BatchExecute("uf//echo again >&2; false/"))
+	assert.Equal(t, []string{"again"}, frameText(e.frameErrors))
+}
+
+func TestOpsysCommandStatus(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	e.FileData.OldCmds = false
+	e.LoadCommandTable(false)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("text\n")))
+
+	// Standard error is interleaved with standard output
+	require.True(t, e.BatchExecute("ox/echo out; echo err >&2/"), messages.String())
+	assert.Equal(t, []string{"out", "err", "text"}, frameText(e.CurrentFrame))
+	require.True(t, e.BatchExecute("ox/true/"))
+	require.True(t, e.BatchExecute("ti?ludwig-exit_status?"), messages.String())
+	assert.Equal(t, []string{"out", "err", "0text"}, frameText(e.CurrentFrame))
+
+	// A non-zero exit status fails, and the output goes to frame ERRORS
+	messages.Reset()
+	assert.False(t, e.BatchExecute("ox/echo making; echo broken >&2; exit 2/"))
+	assert.Contains(t, messages.String(), "exit status 2")
+	assert.Equal(t, []string{"out", "err", "0text"}, frameText(e.CurrentFrame))
+	require.NotNil(t, e.frameErrors)
+	assert.Equal(t, []string{"making", "broken"}, frameText(e.frameErrors))
+	require.True(t, e.BatchExecute("ti?ludwig-exit_status?"))
+	assert.Equal(t, "02text", frameText(e.CurrentFrame)[2])
+
+	// So a span can branch on it
+	require.True(t, e.BatchExecute(`ox/false/[ : ti"failed" ]`))
+	assert.Equal(t, "02failedtext", frameText(e.CurrentFrame)[2])
+
+	// A command killed by a signal says so, and has the shell's status
+	messages.Reset()
+	assert.False(t, e.BatchExecute("ox/kill -KILL $$/"))
+	assert.Contains(t, messages.String(), "killed by signal")
+	assert.NotContains(t, messages.String(), "could not be run")
+	require.True(t, e.BatchExecute("ti?ludwig-exit_status?"))
+	assert.Equal(t, "02failed137text", frameText(e.CurrentFrame)[2])
+}
@@ -17,6 +17,8 @@ package ludwig
 
 import (
 	"math/big"
+	"slices"
+	"unicode"
 )
 
 // localException and otherException are used for non-local control flow
@@ -120,9 +122,8 @@ func init() {
 
 	// Initialize character sets for pattern matching.
 	// We are retaining the Ludwig original meanings of these ASCII sets for
-	// compatibility.  At some point it would be good to redefine these to be
-	// consistent with Unicode character classes, but that would be a breaking
-	// change.
+	// compatibility.  Characters outside ASCII are added to the sets
+	// according to their Unicode character class.
 
 	// SpaceSet: ' '
 	spaceSet.SetBit(&spaceSet, ' ', 1)
@@ -137,10 +138,6 @@ func init() {
 		upperSet.SetBit(&upperSet, int(i), 1)
 	}
 
-	// AlphaSet: union of LowerSet and UpperSet
-	alphaSet.Or(&alphaSet, &lowerSet)
-	alphaSet.Or(&alphaSet, &upperSet)
-
 	// NumericSet: '0'..'9'
 	for i := byte('0'); i <= byte('9'); i++ {
 		numericSet.SetBit(&numericSet, int(i), 1)
@@ -156,11 +153,80 @@ func init() {
 	for _, ch := range punctChars {
 		punctuationSet.SetBit(&punctuationSet, int(ch), 1)
 	}
+
+	// The rest of Latin-1 by the class of each character, then the class
+	// elements standing for the characters above it.
+	for i := 128; i <= PatternClassLast; i++ {
+		class := i
+		if i <= MaxSetRange {
+			class = patternClass(rune(i))
+		}
+		switch class {
+		case PatternClassUpper:
+			upperSet.SetBit(&upperSet, i, 1)
+		case PatternClassLower:
+			lowerSet.SetBit(&lowerSet, i, 1)
+		case PatternClassLetter:
+			alphaSet.SetBit(&alphaSet, i, 1)
+		case PatternClassNumber:
+			numericSet.SetBit(&numericSet, i, 1)
+		case PatternClassSpace:
+			spaceSet.SetBit(&spaceSet, i, 1)
+		case PatternClassPunct:
+			punctuationSet.SetBit(&punctuationSet, i, 1)
+		}
+		if class != PatternClassControl {
+			printableSet.SetBit(&printableSet, i, 1)
+		}
+	}
+
+	// AlphaSet: union of LowerSet and UpperSet, and the other letters
+	alphaSet.Or(&alphaSet, &lowerSet)
+	alphaSet.Or(&alphaSet, &upperSet)
+}
+
+// patternClass returns the class element a character is matched by
+func patternClass(ch rune) int {
+	switch {
+	case unicode.IsUpper(ch):
+		return PatternClassUpper
+	case unicode.IsLower(ch):
+		return PatternClassLower
+	case unicode.IsLetter(ch):
+		return PatternClassLetter
+	case unicode.IsNumber(ch):
+		return PatternClassNumber
+	case unicode.IsSpace(ch):
+		return PatternClassSpace
+	case unicode.IsPunct(ch):
+		return PatternClassPunct
+	case unicode.IsGraphic(ch):
+		return PatternClassOther
+	default:
+		return PatternClassControl
+	}
+}
+
+// patternElement returns the set element that stands for a character
+// named in a pattern
+func patternElement(ch rune) int {
+	if ch <= MaxSetRange {
+		return int(ch)
+	}
+	return PatternClassLast + int(ch) - MaxSetRange
+}
+
+// patternNegate sets the accept set of an NFA state to the characters that
+// are not in set
+func patternNegate(state *NFATransitionType, set *big.Int) {
+	state.AcceptSet.Set(setRemove(rangeSet(PatternAlphaStart, PatternClassLast), set))
+	state.ExcludeSet.Rsh(set, PatternClassLast+1)
+	state.ExcludeSet.Lsh(&state.ExcludeSet, PatternClassLast+1)
 }
 
 // Helper function to check if a value is in a set
-func setContains(set [MaxSetRange + 1]bool, val byte) bool {
-	return set[val]
+func setContains(set [MaxSetRange + 1]bool, val rune) bool {
+	return val >= 0 && val <= MaxSetRange && set[val]
 }
 
 // Helper function to set union
@@ -176,29 +242,29 @@ func setRemove(a, b *big.Int) *big.Int {
 }
 
 // Helper function to create a set from a single character
-func singletonSet(ch byte) *big.Int {
+func singletonSet(ch rune) *big.Int {
 	s := new(big.Int)
-	return s.SetBit(s, int(ch), 1)
+	return s.SetBit(s, patternElement(ch), 1)
 }
 
-// Helper function to create a set from a range of characters
-func rangeSet(start, end byte) *big.Int {
+// Helper function to create a set from a range of elements
+func rangeSet(start, end int) *big.Int {
 	s := new(big.Int)
-	for i := int(start); i <= int(end); i++ {
+	for i := start; i <= end; i++ {
 		s.SetBit(s, i, 1)
 	}
 	return s
 }
 
 // Helper function to add an element to a set
-func setAdd(set *big.Int, ch byte) {
-	set.SetBit(set, int(ch), 1)
+func setAdd(set *big.Int, elt int) {
+	set.SetBit(set, elt, 1)
 }
 
-// Helper function to add a range to a set
-func setAddRange(set *big.Int, start, end byte) {
-	for i := int(start); i <= int(end); i++ {
-		set.SetBit(set, i, 1)
+// Helper function to add a range of characters to a set
+func setAddRange(set *big.Int, start, end rune) {
+	for ch := start; ch <= end; ch++ {
+		set.SetBit(set, patternElement(ch), 1)
 	}
 }
 
@@ -208,7 +274,7 @@ func setClear(set *big.Int) {
 }
 
 // PatternParser parses a pattern and builds an NFA table
-func PatternParser(
+func (e *Editor) PatternParser(
 	pattern *TParObject,
 	nfaTable *NFATableType,
 	firstPatternStart *int,
@@ -220,6 +286,7 @@ func PatternParser(
 ) bool {
 	var firstPatternEnd int
 	var parseCount int
+	var groups int
 
 	// patternNewNFA allocates a new NFA state
 	patternNewNFA := func() int {
@@ -227,10 +294,11 @@ func PatternParser(
 			newNfa := *statesUsed
 			nfaTable[*statesUsed].Fail = false
 			nfaTable[*statesUsed].Indefinite = false
+			nfaTable[*statesUsed].Tags = nil
 			*statesUsed++
 			return newNfa
 		}
-		ScreenMessage(MsgPatPatternTooComplex)
+		e.ScreenMessage(MsgPatPatternTooComplex)
 		panic(localException{})
 	}
 
@@ -238,7 +306,7 @@ func PatternParser(
 	patternDuplicateNFA := func(copyThisStart, copyThisFinish, currentState int, duplicateFinish *int) bool {
 		offset := (currentState - copyThisStart) + 1
 		if (*statesUsed + offset) > MaxNFAStateRange {
-			ScreenMessage(MsgPatPatternTooComplex)
+			e.ScreenMessage(MsgPatPatternTooComplex)
 			panic(localException{})
 		}
 		duplicateStart := *statesUsed
@@ -246,6 +314,7 @@ func PatternParser(
 		for aux := copyThisStart; aux <= copyThisFinish; aux++ {
 			auxState := patternNewNFA()
 			nfaTable[auxState].Fail = nfaTable[aux].Fail
+			nfaTable[auxState].Tags = slices.Clone(nfaTable[aux].Tags)
 			if !nfaTable[auxState].Fail {
 				nfaTable[auxState].EpsilonOut = nfaTable[aux].EpsilonOut
 				if nfaTable[auxState].EpsilonOut {
@@ -266,6 +335,7 @@ func PatternParser(
 						nfaTable[auxState].NextState = nfaTable[aux].NextState + offset
 					}
 					nfaTable[auxState].AcceptSet.Set(&nfaTable[aux].AcceptSet)
+					nfaTable[auxState].ExcludeSet.Set(&nfaTable[aux].ExcludeSet)
 				}
 			}
 			nfaTable[currentState].EpsilonOut = true
@@ -276,14 +346,14 @@ func PatternParser(
 	}
 
 	// patternGetch reads next character from pattern
-	patternGetch := func(parseCount *int, ch *byte, inString *TParObject) bool {
+	patternGetch := func(parseCount *int, ch *rune, inString *TParObject) bool {
 		result := true
 		if *parseCount < inString.Len {
 			*parseCount++
 			*ch = inString.Str.Get(*parseCount)
 			patternDefinition.Length++
 			if patternDefinition.Length > MaxStrLen {
-				ScreenMessage(MsgPatPatternTooComplex)
+				e.ScreenMessage(MsgPatPatternTooComplex)
 				panic(localException{})
 			}
 			patternDefinition.Strng.Set(patternDefinition.Length, *ch)
@@ -295,7 +365,7 @@ func PatternParser(
 	}
 
 	// patternGetnumb reads a number from pattern
-	patternGetnumb := func(parseCount *int, number *int, ch *byte, inString *TParObject) bool {
+	patternGetnumb := func(parseCount *int, number *int, ch *rune, inString *TParObject) bool {
 		auxBool := patternGetch(parseCount, ch, inString)
 		result := auxBool && (*ch >= '0' && *ch <= '9')
 
@@ -311,15 +381,15 @@ func PatternParser(
 	}
 
 	// Forward declarations for mutually recursive functions
-	var patternCompound func(first int, finish *int, parseCount *int, inString *TParObject, patCh *byte, depth int)
-	var patternPattern func(first int, finish *int, parseCount *int, inString *TParObject, patCh *byte, depth int)
+	var patternCompound func(first int, finish *int, parseCount *int, inString *TParObject, patCh *rune, depth int)
+	var patternPattern func(first int, finish *int, parseCount *int, inString *TParObject, patCh *rune, depth int)
 
 	// patternPattern is the main pattern parsing function
-	patternPattern = func(first int, finish *int, parseCount *int, inString *TParObject, patCh *byte, depth int) {
+	patternPattern = func(first int, finish *int, parseCount *int, inString *TParObject, patCh *rune, depth int) {
 		var leadingParam ParameterType
 		var aux, auxCount, temporary int
-		var delimiter, auxCh1, auxCh2, auxPatCh byte
-		derefSpan := TParObject{Str: NewBlankStrObject(MaxStrLen)}
+		var delimiter, auxCh1, auxCh2, auxPatCh rune
+		derefSpan := TParObject{Str: EmptyStrObject()}
 		var tparSort Commands
 		var currentState, auxState, beginState int
 		var endOfInput, negate, noDereference bool
@@ -346,7 +416,7 @@ func PatternParser(
 				*rangeEnd = *rangeStart
 			case PatternLRangeDelim:
 				if !patternGetch(parseCount, patCh, inString) {
-					ScreenMessage(MsgPatNoMatchingDelim)
+					e.ScreenMessage(MsgPatNoMatchingDelim)
 					panic(localException{})
 				}
 				if *patCh >= '0' && *patCh <= '9' {
@@ -358,11 +428,11 @@ func PatternParser(
 				}
 				if *patCh == PatternComma {
 					if !patternGetch(parseCount, patCh, inString) {
-						ScreenMessage(MsgPatNoMatchingDelim)
+						e.ScreenMessage(MsgPatNoMatchingDelim)
 						panic(localException{})
 					}
 				} else {
-					ScreenMessage(MsgPatErrorInRange)
+					e.ScreenMessage(MsgPatErrorInRange)
 					panic(localException{})
 				}
 				if *patCh >= '0' && *patCh <= '9' {
@@ -374,7 +444,7 @@ func PatternParser(
 					*rangeEnd = 0
 				}
 				if *patCh != PatternRRangeDelim {
-					ScreenMessage(MsgPatNoMatchingDelim)
+					e.ScreenMessage(MsgPatNoMatchingDelim)
 					panic(localException{})
 				}
 			}
@@ -435,7 +505,7 @@ func PatternParser(
 		derefSpan.Con = nil
 
 		if depth > PatternMaxDepth {
-			ScreenMessage(MsgPatPatternTooComplex)
+			e.ScreenMessage(MsgPatPatternTooComplex)
 			panic(localException{})
 		}
 
@@ -449,49 +519,49 @@ func PatternParser(
 
 		for (*patCh != PatternComma && *patCh != PatternRParen && *patCh != PatternBar) && !endOfInput {
 			if !setContains(syntaxSet, *patCh) {
-				ScreenMessage(MsgPatIllegalSymbol)
+				e.ScreenMessage(MsgPatIllegalSymbol)
 				panic(localException{})
 			}
 
 			switch *patCh {
 			case TpdSpan, TpdPrompt:
 				delimiter = *patCh
-				derefTpar := TParObject{Str: NewBlankStrObject(MaxStrLen)}
+				derefTpar := TParObject{Str: EmptyStrObject()}
 				aux = 0
 				if !patternGetch(parseCount, patCh, inString) {
-					ScreenMessage(MsgPatNoMatchingDelim)
+					e.ScreenMessage(MsgPatNoMatchingDelim)
 					panic(localException{})
 				}
 				for *patCh != delimiter {
 					aux++
 					derefTpar.Str.Set(aux, *patCh)
 					if !patternGetch(parseCount, patCh, inString) {
-						ScreenMessage(MsgPatNoMatchingDelim)
+						e.ScreenMessage(MsgPatNoMatchingDelim)
 						panic(localException{})
 					}
 				}
 				patternDefinition.Length = patternDefinition.Length - (aux + 2)
 				tparSort = CmdPatternDummyPattern
 				derefTpar.Len = aux
-				derefTpar.Dlm = delimiter
-				if !TparGet1(&derefTpar, tparSort, &derefSpan) {
+				derefTpar.Dlm = byte(delimiter)
+				if !e.TparGet1(&derefTpar, tparSort, &derefSpan) {
 					panic(otherException{})
 				}
 
-				if setContains(quotedSet, derefSpan.Dlm) {
+				if setContains(quotedSet, rune(derefSpan.Dlm)) {
 					// Insert quote at beginning: shift string right and add delimiters
 					for i := derefSpan.Len; i >= 1; i-- {
 						derefSpan.Str.Set(i+1, derefSpan.Str.Get(i))
 					}
 					derefSpan.Len += 2
-					derefSpan.Str.Set(derefSpan.Len, derefSpan.Dlm)
-					derefSpan.Str.Set(1, derefSpan.Dlm)
+					derefSpan.Str.Set(derefSpan.Len, rune(derefSpan.Dlm))
+					derefSpan.Str.Set(1, rune(derefSpan.Dlm))
 				}
 				auxCount = 0
 				if patternGetch(&auxCount, &auxPatCh, &derefSpan) {
 					patternCompound(currentState, &currentState, &auxCount, &derefSpan, &auxPatCh, depth+1)
 					if (auxCount != derefSpan.Len) || (derefSpan.Str.Get(auxCount) == PatternComma) {
-						ScreenMessage(MsgPatErrorInSpan)
+						e.ScreenMessage(MsgPatErrorInSpan)
 						panic(localException{})
 					}
 				}
@@ -510,7 +580,7 @@ func PatternParser(
 				if setContains(delimitedSet, *patCh) {
 					patternRangeDelimgen(&rangePatch, &rangeStart, &rangeEnd, &rangeIndefinite, &leadingParam)
 					if !patternGetch(parseCount, patCh, inString) {
-						ScreenMessage(MsgPatPrematurePatternEnd)
+						e.ScreenMessage(MsgPatPrematurePatternEnd)
 						panic(localException{})
 					}
 					beginState = currentState
@@ -521,17 +591,17 @@ func PatternParser(
 					auxCh1 = *patCh
 					auxCount = *parseCount
 					if !patternGetch(parseCount, patCh, inString) {
-						ScreenMessage(MsgPatNoMatchingDelim)
+						e.ScreenMessage(MsgPatNoMatchingDelim)
 						panic(localException{})
 					}
 					if *patCh == TpdSpan || *patCh == TpdPrompt {
 						noDereference = false
 						delimiter = *patCh
-						derefTpar := TParObject{Str: NewBlankStrObject(MaxStrLen)}
+						derefTpar := TParObject{Str: EmptyStrObject()}
 						aux = 0
 						for {
 							if !patternGetch(parseCount, patCh, inString) {
-								ScreenMessage(MsgPatNoMatchingDelim)
+								e.ScreenMessage(MsgPatNoMatchingDelim)
 								panic(localException{})
 							}
 							aux++
@@ -545,8 +615,8 @@ func PatternParser(
 								patternDefinition.Length -= (aux + 2)
 								tparSort = CmdPatternDummyText
 								derefTpar.Len = aux - 2
-								derefTpar.Dlm = delimiter
-								if !TparGet1(&derefTpar, tparSort, &derefSpan) {
+								derefTpar.Dlm = byte(delimiter)
+								if !e.TparGet1(&derefTpar, tparSort, &derefSpan) {
 									panic(otherException{})
 								}
 								// Insert quote at beginning: shift string right and add delimiters
@@ -585,33 +655,23 @@ func PatternParser(
 								nfaTable[currentState].NextState = patternNewNFA()
 								currentState = nfaTable[currentState].NextState
 								if !patternGetch(parseCount, patCh, inString) {
-									ScreenMessage(MsgPatNoMatchingDelim)
+									e.ScreenMessage(MsgPatNoMatchingDelim)
 									panic(localException{})
 								}
 							}
 						} else {
 							for *patCh != TpdLit {
 								nfaTable[currentState].EpsilonOut = false
-								if *patCh >= 'a' && *patCh <= 'z' {
-									uset := setUnion(
-										singletonSet(*patCh),
-										singletonSet(ChToUpper(*patCh)),
-									)
-									nfaTable[currentState].AcceptSet.Set(uset)
-								} else if *patCh >= 'A' && *patCh <= 'Z' {
-									uset := setUnion(
-										singletonSet(*patCh),
-										singletonSet(ChToLower(*patCh)),
-									)
-									nfaTable[currentState].AcceptSet.Set(uset)
-								} else {
-									sset := singletonSet(*patCh)
-									nfaTable[currentState].AcceptSet.Set(sset)
-								}
+								uset := setUnion(
+									singletonSet(ChToUpper(*patCh)),
+									singletonSet(ChToLower(*patCh)),
+								)
+								setAdd(uset, patternElement(*patCh))
+								nfaTable[currentState].AcceptSet.Set(uset)
 								nfaTable[currentState].NextState = patternNewNFA()
 								currentState = nfaTable[currentState].NextState
 								if !patternGetch(parseCount, patCh, inString) {
-									ScreenMessage(MsgPatNoMatchingDelim)
+									e.ScreenMessage(MsgPatNoMatchingDelim)
 									panic(localException{})
 								}
 							}
@@ -619,27 +679,32 @@ func PatternParser(
 					}
 
 				case PatternLParen:
+					// Each group tags where it starts and finishes
+					groupTag := PatternTagGroups + 2*groups
+					groups++
+					nfaTable[currentState].Tags = append(nfaTable[currentState].Tags, groupTag)
 					_ = patternGetch(parseCount, patCh, inString)
 					patternCompound(currentState, &auxState, parseCount, inString, patCh, depth+1)
 					currentState = auxState
+					nfaTable[currentState].Tags = append(nfaTable[currentState].Tags, groupTag+1)
 					if *patCh != PatternRParen {
-						ScreenMessage(MsgPatNoMatchingDelim)
+						e.ScreenMessage(MsgPatNoMatchingDelim)
 						panic(localException{})
 					}
 
 				case PatternMark:
 					if patternGetnumb(parseCount, &auxi, patCh, inString) {
 						if (auxi == 0) || (auxi > MaxUserMarkNumber) {
-							ScreenMessage(MsgPatIllegalMarkNumber)
+							e.ScreenMessage(MsgPatIllegalMarkNumber)
 							panic(localException{})
 						}
 						nfaTable[currentState].EpsilonOut = false
-						sset := singletonSet(byte(auxi + PatternMarksStart))
+						sset := singletonSet(rune(auxi + PatternMarksStart))
 						nfaTable[currentState].AcceptSet.Set(sset)
 						nfaTable[currentState].NextState = patternNewNFA()
 						currentState = nfaTable[currentState].NextState
 					} else {
-						ScreenMessage(MsgPatIllegalMarkNumber)
+						e.ScreenMessage(MsgPatIllegalMarkNumber)
 						panic(localException{})
 					}
 
@@ -660,7 +725,7 @@ func PatternParser(
 					if *patCh == PatternNegate {
 						negate = true
 						if !patternGetch(parseCount, patCh, inString) {
-							ScreenMessage(MsgPatPrematurePatternEnd)
+							e.ScreenMessage(MsgPatPrematurePatternEnd)
 							panic(localException{})
 						}
 					}
@@ -669,7 +734,7 @@ func PatternParser(
 						auxSet := new(big.Int)
 						setClear(auxSet)
 						if !patternGetch(parseCount, patCh, inString) {
-							ScreenMessage(MsgPatPrematurePatternEnd)
+							e.ScreenMessage(MsgPatPrematurePatternEnd)
 							panic(localException{})
 						}
 						delimiter = *patCh
@@ -678,11 +743,11 @@ func PatternParser(
 
 						if delimiter == TpdSpan || delimiter == TpdPrompt {
 							derefTpar := TParObject{
-								Str: NewBlankStrObject(MaxStrLen),
-								Dlm: delimiter,
+								Str: EmptyStrObject(),
+								Dlm: byte(delimiter),
 							}
 							if !patternGetch(parseCount, patCh, inString) {
-								ScreenMessage(MsgPatPrematurePatternEnd)
+								e.ScreenMessage(MsgPatPrematurePatternEnd)
 								panic(localException{})
 							}
 							aux = 0
@@ -690,19 +755,19 @@ func PatternParser(
 								aux++
 								derefTpar.Str.Set(aux, *patCh)
 								if !patternGetch(parseCount, patCh, inString) {
-									ScreenMessage(MsgPatNoMatchingDelim)
+									e.ScreenMessage(MsgPatNoMatchingDelim)
 									panic(localException{})
 								}
 							}
 							patternDefinition.Length -= (aux + 1)
 							derefTpar.Len = aux
-							if !TparGet1(&derefTpar, tparSort, &derefSpan) {
+							if !e.TparGet1(&derefTpar, tparSort, &derefSpan) {
 								panic(otherException{})
 							}
 						} else {
 							aux = 0
 							if !patternGetch(parseCount, patCh, inString) {
-								ScreenMessage(MsgPatPrematurePatternEnd)
+								e.ScreenMessage(MsgPatPrematurePatternEnd)
 								panic(localException{})
 							}
 							patternDefinition.Strng.Set(patternDefinition.Length, 0)
@@ -710,7 +775,7 @@ func PatternParser(
 								aux++
 								derefSpan.Str.Set(aux, *patCh)
 								if !patternGetch(parseCount, patCh, inString) {
-									ScreenMessage(MsgPatNoMatchingDelim)
+									e.ScreenMessage(MsgPatNoMatchingDelim)
 									panic(localException{})
 								}
 							}
@@ -737,12 +802,12 @@ func PatternParser(
 						}
 						patternDefinition.Length = temporary + derefSpan.Len + 1
 						patternDefinition.Strng.Set(patternDefinition.Length, 0)
+						nfaTable[currentState].EpsilonOut = false
 						if negate {
-							fullSet := rangeSet(PatternAlphaStart, MaxSetRange)
-							auxSet = setRemove(fullSet, auxSet)
+							patternNegate(&nfaTable[currentState], auxSet)
+						} else {
+							nfaTable[currentState].AcceptSet.Set(auxSet)
 						}
-						nfaTable[currentState].EpsilonOut = false
-						nfaTable[currentState].AcceptSet.Set(auxSet)
 						nfaTable[currentState].NextState = patternNewNFA()
 						currentState = nfaTable[currentState].NextState
 
@@ -750,7 +815,7 @@ func PatternParser(
 						nfaTable[currentState].EpsilonOut = false
 						if setContains(positionalsSet, *patCh) {
 							if negate {
-								ScreenMessage(MsgPatIllegalParameter)
+								e.ScreenMessage(MsgPatIllegalParameter)
 								panic(localException{})
 							}
 							setClear(&nfaTable[currentState].AcceptSet)
@@ -785,15 +850,14 @@ func PatternParser(
 								nfaTable[currentState].AcceptSet.Set(&punctuationSet)
 							}
 							if negate {
-								fullSet := rangeSet(PatternAlphaStart, MaxSetRange)
-								rset := setRemove(fullSet, &nfaTable[currentState].AcceptSet)
-								nfaTable[currentState].AcceptSet.Set(rset)
+								cset := new(big.Int).Set(&nfaTable[currentState].AcceptSet)
+								patternNegate(&nfaTable[currentState], cset)
 							}
 						}
 						nfaTable[currentState].NextState = patternNewNFA()
 						currentState = nfaTable[currentState].NextState
 					} else {
-						ScreenMessage(MsgPatSetNotDefined)
+						e.ScreenMessage(MsgPatSetNotDefined)
 						panic(localException{})
 					}
 				}
@@ -817,12 +881,12 @@ func PatternParser(
 	}
 
 	// patternCompound handles compound patterns (with alternatives)
-	patternCompound = func(first int, finish *int, parseCount *int, inString *TParObject, patCh *byte, depth int) {
+	patternCompound = func(first int, finish *int, parseCount *int, inString *TParObject, patCh *rune, depth int) {
 		var compoundFinish int
 		var currentEStart int
 
 		if depth > PatternMaxDepth {
-			ScreenMessage(MsgPatPatternTooComplex)
+			e.ScreenMessage(MsgPatPatternTooComplex)
 			panic(localException{})
 		}
 
@@ -858,7 +922,7 @@ func PatternParser(
 		}
 	}()
 
-	ExitAbort = true
+	e.ExitAbort = true
 	patternDefinition.Length = 0
 
 	nfaTable[PatternNull].EpsilonOut = true
@@ -868,7 +932,7 @@ func PatternParser(
 	*statesUsed = PatternNFAStart
 	*firstPatternStart = patternNewNFA()
 	parseCount = 0
-	var patCh byte
+	var patCh rune
 	if patternGetch(&parseCount, &patCh, pattern) {
 		patternCompound(*firstPatternStart, &firstPatternEnd, &parseCount, pattern, &patCh, 1)
 		if patCh == PatternComma {
@@ -894,16 +958,22 @@ func PatternParser(
 			*patternFinalState = *middleContextEnd
 		}
 	} else {
-		ScreenMessage(MsgPatNullPattern)
+		e.ScreenMessage(MsgPatNullPattern)
 		panic(localException{})
 	}
 
 	nfaTable[*patternFinalState].EpsilonOut = true
 	nfaTable[*patternFinalState].FirstOut = PatternNull
 	nfaTable[*patternFinalState].SecondOut = PatternNull
+	if *leftContextEnd != PatternNull {
+		nfaTable[*leftContextEnd].Tags = append(nfaTable[*leftContextEnd].Tags, PatternTagMiddleStart)
+	}
+	if *middleContextEnd != PatternNull {
+		nfaTable[*middleContextEnd].Tags = append(nfaTable[*middleContextEnd].Tags, PatternTagMiddleEnd)
+	}
 
 	result = true
-	ExitAbort = false
+	e.ExitAbort = false
 
 	return result
 }
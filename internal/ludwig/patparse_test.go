@@ -34,7 +34,7 @@ func createTestTpar(content string) *TParObject {
 func setupParser() (*NFATableType, *PatternDefType) {
 	nfaTable := &NFATableType{}
 	patternDef := &PatternDefType{
-		Strng: *NewBlankStrObject(MaxStrLen),
+		Strng: *NewBlankStrObject(testStrLen),
 	}
 	return nfaTable, patternDef
 }
@@ -354,6 +354,7 @@ func TestStaticAcceptSets(t *testing.T) {
 // Tests for PatternParser function
 
 func TestPatternParser(t *testing.T) {
+	e := NewEditor()
 	// Helper to create test environment
 	t.Run("EmptyPattern", func(t *testing.T) {
 		nfaTable, patternDef := setupParser()
@@ -361,7 +362,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.False(t, result, "Empty pattern should fail")
 	})
@@ -372,7 +373,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Simple literal pattern should succeed")
 		assert.Greater(t, statesUsed, PatternNFAStart, "Should have allocated NFA states")
@@ -385,7 +386,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Exact case pattern should succeed")
 	})
@@ -396,7 +397,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Space character class should succeed")
 		// Verify the accept set contains space
@@ -416,7 +417,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Lowercase character class should succeed")
 	})
@@ -427,7 +428,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Uppercase character class should succeed")
 	})
@@ -438,7 +439,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Alpha character class should succeed")
 	})
@@ -449,7 +450,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Numeric character class should succeed")
 	})
@@ -460,7 +461,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Punctuation character class should succeed")
 	})
@@ -471,7 +472,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Negated character class should succeed")
 	})
@@ -483,7 +484,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Kleene star should succeed")
 	})
@@ -495,7 +496,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Kleene plus should succeed")
 	})
@@ -507,7 +508,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Exact count repetition should succeed")
 	})
@@ -519,7 +520,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Range repetition should succeed")
 	})
@@ -531,7 +532,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Open-ended range should succeed")
 	})
@@ -542,7 +543,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Beginning of line positional should succeed")
 	})
@@ -553,7 +554,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "End of line positional should succeed")
 	})
@@ -564,7 +565,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Left margin positional should succeed")
 	})
@@ -575,7 +576,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Right margin positional should succeed")
 	})
@@ -586,7 +587,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Dot column positional should succeed")
 	})
@@ -597,7 +598,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Alternation pattern should succeed")
 	})
@@ -608,7 +609,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Grouped pattern should succeed")
 	})
@@ -621,7 +622,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Complex pattern should succeed")
 	})
@@ -633,7 +634,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Context pattern should succeed")
 		assert.NotEqual(t, leftEnd, firstStart, "leftEnd should be different from start")
@@ -647,7 +648,7 @@ func TestPatternParser(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.False(t, result, "Invalid character should fail")
 	})
@@ -656,13 +657,14 @@ func TestPatternParser(t *testing.T) {
 // Edge case tests
 
 func TestPatternParserEdgeCases(t *testing.T) {
+	e := NewEditor()
 	t.Run("SpacesInPattern", func(t *testing.T) {
 		nfaTable, patternDef := setupParser()
 		pattern := createTestTpar("  'a'  ")
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Pattern with spaces should succeed")
 	})
@@ -673,7 +675,7 @@ func TestPatternParserEdgeCases(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Nested groups should succeed")
 	})
@@ -684,7 +686,7 @@ func TestPatternParserEdgeCases(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Multiple alternations should succeed")
 	})
@@ -696,7 +698,7 @@ func TestPatternParserEdgeCases(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Zero repetition range should succeed")
 	})
@@ -708,7 +710,7 @@ func TestPatternParserEdgeCases(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Single repetition range should succeed")
 	})
@@ -719,7 +721,7 @@ func TestPatternParserEdgeCases(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Combined character classes should succeed")
 	})
@@ -731,7 +733,7 @@ func TestPatternParserEdgeCases(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Uppercase character classes should succeed")
 	})
@@ -740,7 +742,7 @@ func TestPatternParserEdgeCases(t *testing.T) {
 // Integration tests
 
 func TestPatternParserIntegration(t *testing.T) {
-
+	e := NewEditor()
 	t.Run("EmailPattern", func(t *testing.T) {
 		nfaTable, patternDef := setupParser()
 		// Simple pattern: one or more alphas, @, one or more alphas, ., one or more alphas
@@ -749,7 +751,7 @@ func TestPatternParserIntegration(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Email-like pattern should succeed")
 	})
@@ -762,7 +764,7 @@ func TestPatternParserIntegration(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Word pattern with anchors should succeed")
 	})
@@ -775,7 +777,7 @@ func TestPatternParserIntegration(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Number pattern should succeed")
 	})
@@ -788,7 +790,7 @@ func TestPatternParserIntegration(t *testing.T) {
 
 		var firstStart, finalState, leftEnd, middleEnd, statesUsed int
 
-		result := PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
+		result := e.PatternParser(pattern, nfaTable, &firstStart, &finalState, &leftEnd, &middleEnd, patternDef, &statesUsed)
 
 		assert.True(t, result, "Whitespace pattern should succeed")
 	})
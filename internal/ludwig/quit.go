@@ -19,27 +19,27 @@ const (
 )
 
 // QuitCommand handles the quit command
-func QuitCommand() bool {
-	if LudwigMode != LudwigBatch {
-		newSpan := FirstSpan
+func (e *Editor) QuitCommand() bool {
+	if e.LudwigMode != LudwigBatch {
+		newSpan := e.FirstSpan
 		for newSpan != nil {
 			if newSpan.Frame != nil {
 				if newSpan.Frame.TextModified && newSpan.Frame.OutputFile == 0 &&
 					newSpan.Frame.InputFile != 0 {
-					CurrentFrame = newSpan.Frame
+					e.CurrentFrame = newSpan.Frame
 					mm := newSpan.Frame.Marks[MarkModified]
 					MarkCreate(mm.Line, mm.Col, &newSpan.Frame.Dot)
-					if LudwigMode == LudwigScreen {
-						ScreenFixup()
+					if e.LudwigMode == LudwigScreen {
+						e.ScreenFixup()
 					}
-					ScreenBeep()
-					switch ScreenVerify(noOutputFileMsg) {
+					e.ScreenBeep()
+					switch e.ScreenVerify(noOutputFileMsg) {
 					case VerifyReplyYes:
 						// Nothing to do here
 					case VerifyReplyAlways:
 						goto l2
 					case VerifyReplyNo, VerifyReplyQuit:
-						ExitAbort = true
+						e.ExitAbort = true
 						return false
 					}
 				}
@@ -48,34 +48,34 @@ func QuitCommand() bool {
 		}
 	}
 l2:
-	ScreenUnload()
-	if LudwigMode != LudwigBatch {
-		ScreenMessage(MsgQuitting)
+	e.ScreenUnload()
+	if e.LudwigMode != LudwigBatch {
+		e.ScreenMessage(MsgQuitting)
 	}
-	if LudwigMode == LudwigScreen {
-		VduFlush()
+	if e.LudwigMode == LudwigScreen {
+		e.VduFlush()
 	}
-	LudwigAborted = false
-	QuitCloseFiles()
+	e.LudwigAborted = false
+	e.QuitCloseFiles()
 	SysExitSuccess()
 	return true // Given the exit above, this shouldn't happen
 }
 
 // doFrame handles closing files for a single frame
-func doFrame(f *FrameObject) bool {
+func (e *Editor) doFrame(f *FrameObject) bool {
 	if f.OutputFile == 0 {
 		return true
 	}
-	if Files[f.OutputFile] == nil {
+	if e.Files[f.OutputFile] == nil {
 		return true
 	}
 	// Wind out and close the associated input file
-	if !FileWindthru(f, true) {
+	if !e.FileWindthru(f, true) {
 		return false
 	}
 	if f.InputFile != 0 {
-		if Files[f.InputFile] != nil {
-			if !FileCloseDelete(Files[f.InputFile], false, true) {
+		if e.Files[f.InputFile] != nil {
+			if !e.FileCloseDelete(e.Files[f.InputFile], false, true) {
 				return false
 			}
 			f.InputFile = 0
@@ -83,8 +83,8 @@ func doFrame(f *FrameObject) bool {
 	}
 	// Close the output file
 	result := true
-	if !LudwigAborted {
-		result = FileCloseDelete(Files[f.OutputFile], !f.TextModified, f.TextModified)
+	if !e.LudwigAborted {
+		result = e.FileCloseDelete(e.Files[f.OutputFile], !f.TextModified, f.TextModified)
 	}
 	f.OutputFile = 0
 	return result
@@ -94,12 +94,12 @@ func doFrame(f *FrameObject) bool {
 // THIS ROUTINE DOES BOTH THE NORMAL "Q" COMMAND, AND ALSO IS CALLED AS PART
 // OF THE LUDWIG "PROG_WINDUP" SEQUENCE. THUS BY TYPING "^Y EXIT" USERS MAY
 // SAFELY ABORT LUDWIG AND NOT LOSE ANY FILES.
-func QuitCloseFiles() {
-	nextSpan := FirstSpan
+func (e *Editor) QuitCloseFiles() {
+	nextSpan := e.FirstSpan
 	for nextSpan != nil {
 		nextFrame := nextSpan.Frame
 		if nextFrame != nil {
-			if !doFrame(nextFrame) {
+			if !e.doFrame(nextFrame) {
 				goto l99
 			}
 		}
@@ -107,24 +107,26 @@ func QuitCloseFiles() {
 	}
 
 	// Close all remaining files
-	if !LudwigAborted {
+	if !e.LudwigAborted {
 		for fileIndex := 1; fileIndex <= MaxFiles; fileIndex++ {
-			if Files[fileIndex] != nil {
-				if !FileCloseDelete(Files[fileIndex], false, true) {
+			if e.Files[fileIndex] != nil {
+				if !e.FileCloseDelete(e.Files[fileIndex], false, true) {
 					goto l99
 				}
 			}
 		}
+		// Everything is safely written, the journals are not needed
+		e.JournalRemoveAll()
 	}
 l99:
 	// Now free up the VDU, thus re-setting anything we have changed
-	if !VduFreeFlag { // Has it been called already?
-		VduFree()
-		VduFreeFlag = true // Well it has now
-		LudwigMode = LudwigBatch
+	if !e.VduFreeFlag { // Has it been called already?
+		e.VduFree()
+		e.VduFreeFlag = true // Well it has now
+		e.LudwigMode = LudwigBatch
 	}
-	if LudwigAborted {
-		ScreenMessage(MsgNotRenamed)
-		ScreenMessage(MsgAbort)
+	if e.LudwigAborted {
+		e.ScreenMessage(MsgNotRenamed)
+		e.ScreenMessage(MsgAbort)
 	}
 }
@@ -14,7 +14,10 @@
 
 package ludwig
 
-import "math/big"
+import (
+	"math/big"
+	"slices"
+)
 
 func emptySet() *big.Int {
 	return big.NewInt(0)
@@ -22,9 +25,9 @@ func emptySet() *big.Int {
 }
 
 // patternGetInputElt gets the next input element for pattern matching
-func patternGetInputElt(
+func (e *Editor) patternGetInputElt(
 	line *LineHdrObject,
-	ch *byte,
+	ch *rune,
 	inputSet *big.Int,
 	column *int,
 	length int,
@@ -52,25 +55,25 @@ func patternGetInputElt(
 				*endOfLine = true
 				markFound = true
 			}
-			if *column == CurrentFrame.MarginLeft {
+			if *column == e.CurrentFrame.MarginLeft {
 				setAdd(inputSet, PatternLeftMargin)
 				markFound = true
 			}
-			if *column == CurrentFrame.MarginRight {
+			if *column == e.CurrentFrame.MarginRight {
 				setAdd(inputSet, PatternRightMargin)
 				markFound = true
 			}
-			if *column == CurrentFrame.Dot.Col {
+			if *column == e.CurrentFrame.Dot.Col {
 				setAdd(inputSet, PatternDotColumn)
 				markFound = true
 			}
 			if len(line.Marks) > 0 { // if any marks on this line
 				for markNo := 0; markNo <= MaxMarkNumber; markNo++ {
 					// run through user accessible ones
-					if CurrentFrame.Marks[markNo] != nil {
-						if (CurrentFrame.Marks[markNo].Line == line) &&
-							(CurrentFrame.Marks[markNo].Col == *column) {
-							setAdd(inputSet, byte(markNo+PatternMarksStart))
+					if e.CurrentFrame.Marks[markNo] != nil {
+						if (e.CurrentFrame.Marks[markNo].Line == line) &&
+							(e.CurrentFrame.Marks[markNo].Col == *column) {
+							setAdd(inputSet, markNo+PatternMarksStart)
 							markFound = true
 						}
 					}
@@ -97,10 +100,24 @@ func setIntersection(set1 *big.Int, set2 *big.Int) bool {
 	return s.And(set1, set2).Sign() != 0
 }
 
+// patternInputElement returns the set element a character of the text is
+// matched by, characters above MaxSetRange that the pattern does not name
+// are matched by their class.
+func patternInputElement(dfaTablePointer *DFATableObject, ch rune) int {
+	if ch <= MaxSetRange {
+		return int(ch)
+	}
+	elt := patternElement(ch)
+	if dfaTablePointer.RuneSet.Bit(elt) != 0 {
+		return elt
+	}
+	return patternClass(ch)
+}
+
 // patternNextState determines the next state in the DFA
 func patternNextState(
 	dfaTablePointer *DFATableObject,
-	ch byte,
+	ch rune,
 	inputSet *big.Int,
 	markFlag bool,
 	state *int,
@@ -129,8 +146,9 @@ func patternNextState(
 		}
 	} else {
 		// look for transitions on characters
+		elt := patternInputElement(dfaTablePointer, ch)
 		for transitionPointer != nil && !found {
-			if transitionPointer.TransitionAcceptSet.Bit(int(ch)) != 0 {
+			if transitionPointer.TransitionAcceptSet.Bit(elt) != 0 {
 				found = true
 				if transitionPointer.StartFlag && !*started {
 					*state = PatternDFAKill
@@ -148,7 +166,7 @@ func patternNextState(
 }
 
 // PatternRecognize performs pattern matching on a line
-func PatternRecognize(
+func (e *Editor) PatternRecognize(
 	dfaTablePointer *DFATableObject,
 	line *LineHdrObject,
 	startCol int,
@@ -166,11 +184,11 @@ func PatternRecognize(
 	endOfLine := false
 	leftFlag := false
 	var positionalSet big.Int
-	var ch byte
+	var ch rune
 
 	for {
 		for {
-			patternGetInputElt(
+			e.patternGetInputElt(
 				line, &ch, &positionalSet, &lineCounter, line.Used, markFlag, &endOfLine,
 			)
 			if patternNextState(
@@ -204,7 +222,7 @@ func PatternRecognize(
 
 		if !endOfLine {
 			for {
-				patternGetInputElt(
+				e.patternGetInputElt(
 					line, &ch, &positionalSet, &lineCounter, line.Used, markFlag, &endOfLine,
 				)
 				if patternNextState(
@@ -256,3 +274,125 @@ func PatternRecognize(
 	}
 	return found
 }
+
+// patternThread is a path through the NFA followed by PatternSubmatches,
+// with the column it passed each tag at, zero for a tag not passed
+type patternThread struct {
+	state int
+	tags  []int
+}
+
+// PatternSubmatches finds where the groups of a pattern matched, once
+// PatternRecognize has found a match from startCol whose middle context
+// runs from startPos to finishPos.  The DFA only knows where the contexts
+// start and finish, so the tagged NFA is followed over the same input, and
+// the first path in order of preference that agrees with the match found
+// gives the groups.  The result has the start and finish column of each
+// group, both zero if the group took no part in the match, or is nil if no
+// path agrees with the match.
+func (e *Editor) PatternSubmatches(
+	dfaTablePointer *DFATableObject,
+	line *LineHdrObject,
+	startCol int,
+	markFlag bool,
+	startPos int,
+	finishPos int,
+) []int {
+	nfa := dfaTablePointer.NFA
+	if nfa == nil || dfaTablePointer.Groups == 0 {
+		return nil
+	}
+	nrTags := PatternTagGroups + 2*dfaTablePointer.Groups
+	var matched []int
+	column := startCol
+	var onList [MaxNFAStateRange + 1]bool
+	dropRest := false
+
+	// addThread follows the epsilon transitions from a state, adding the
+	// states that read input to threads in order of preference
+	var addThread func(threads []patternThread, state int, tags []int) []patternThread
+	addThread = func(threads []patternThread, state int, tags []int) []patternThread {
+		final := state == dfaTablePointer.NFAFinal
+		if dropRest || (onList[state] && !final) {
+			return threads
+		}
+		onList[state] = true
+		nta := &nfa[state]
+		if len(nta.Tags) > 0 {
+			tags = slices.Clone(tags)
+			for _, tag := range nta.Tags {
+				tags[tag] = column
+			}
+		}
+		if final {
+			if tags[PatternTagMiddleStart] == startPos && tags[PatternTagMiddleEnd] == finishPos {
+				// Paths less preferred than this one can be dropped
+				matched = tags
+				dropRest = true
+			}
+			return threads
+		}
+		if nta.EpsilonOut {
+			if nta.FirstOut != PatternNull {
+				threads = addThread(threads, nta.FirstOut, tags)
+			}
+			if nta.SecondOut != PatternNull {
+				threads = addThread(threads, nta.SecondOut, tags)
+			}
+			return threads
+		}
+		return append(threads, patternThread{state: state, tags: tags})
+	}
+
+	// step starts a new path at the column before an element of input,
+	// until a match is found, then moves the paths over the element
+	var threads []patternThread
+	var positionalSet big.Int
+	var ch rune
+	endOfLine := false
+	step := func(positional bool) {
+		elt := patternInputElement(dfaTablePointer, ch)
+		current := threads
+		threads = nil
+		onList = [MaxNFAStateRange + 1]bool{}
+		dropRest = false
+		for _, thread := range current {
+			nta := &nfa[thread.state]
+			switch {
+			case positional && setIntersection(&nta.AcceptSet, &positionalSet):
+				threads = addThread(threads, nta.NextState, thread.tags)
+			case positional:
+				// A positional that is not matched is passed over
+				if !dropRest && !onList[thread.state] {
+					onList[thread.state] = true
+					threads = append(threads, thread)
+				}
+			case nta.AcceptSet.Bit(elt) != 0:
+				threads = addThread(threads, nta.NextState, thread.tags)
+			}
+		}
+	}
+	start := func() {
+		if matched != nil {
+			return
+		}
+		onList = [MaxNFAStateRange + 1]bool{}
+		for _, thread := range threads {
+			onList[thread.state] = true
+		}
+		threads = addThread(threads, dfaTablePointer.NFAStart, make([]int, nrTags))
+	}
+
+	for !endOfLine && (len(threads) > 0 || matched == nil) {
+		start()
+		e.patternGetInputElt(line, &ch, &positionalSet, &column, line.Used, &markFlag, &endOfLine)
+		step(markFlag)
+	}
+	// Then the white space at the end of the line
+	if len(threads) > 0 || matched == nil {
+		start()
+		ch = ' '
+		step(false)
+	}
+	return matched
+}
@@ -0,0 +1,100 @@
+// Tests for recognize.go functions
+
+package ludwig
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recognizeLine builds a pattern and looks for it in a line of text,
+// returning whether it was found and the columns it was found between
+func recognizeLine(t *testing.T, e *Editor, pattern string, text string) (bool, int, int) {
+	frame := setupUndoFrame(t, e, text)
+
+	var dfa *DFATableObject
+	tpar := TParObject{
+		Str: NewStrObjectFrom(pattern),
+		Len: utf8.RuneCountInString(pattern),
+		Dlm: TpdSmart,
+	}
+	require.True(t, e.eqsgetrepPatternBuild(tpar, &dfa))
+	markFlag := false
+	var startPos, finishPos int
+	found := e.PatternRecognize(dfa, frame.FirstGroup.FirstLine, 1, &markFlag, &startPos, &finishPos)
+	return found, startPos, finishPos
+}
+
+func TestPatternRecognizeUnicode(t *testing.T) {
+	e := NewEditor()
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		found   bool
+		start   int
+		finish  int
+	}{
+		{"wide literal", `"日本"`, "ab 日本語", true, 4, 6},
+		{"literal any case", `'ÉT'`, "caféT ÉT", true, 4, 6},
+		{"exact case", `"ÉT"`, "café te ÉT", true, 9, 11},
+		{"alphabetic class", `*A`, "日本語 text", true, 1, 4},
+		{"upper class", `U`, "abc Ωx", true, 5, 6},
+		{"negated class", `-A`, "日本語 text", true, 4, 5},
+		{"defined set", `D/α..ω/`, "abγ", true, 3, 4},
+		{"negated set", `-D/a..z/`, "abcé", true, 4, 5},
+		{"negated set of runes", `-D/α..ω/`, "αβ日", true, 3, 4},
+		{"not found", `"語"`, "日本", false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, start, finish := recognizeLine(t, e, tt.pattern, tt.text)
+			assert.Equal(t, tt.found, found)
+			if tt.found {
+				assert.Equal(t, tt.start, start, "start column")
+				assert.Equal(t, tt.finish, finish, "finish column")
+			}
+		})
+	}
+}
+
+func TestPatternSubmatches(t *testing.T) {
+	e := NewEditor()
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		groups  []int
+	}{
+		{"two groups", `(*a)" "(*a)`, "hello world", []int{1, 6, 7, 12}},
+		{"last of a repeat", `*("a"|"b")`, "abba", []int{4, 5}},
+		{"group not matched", `("x")|("y")`, "y", []int{0, 0, 1, 2}},
+		{"in the middle context", `a,(+n),a`, "x12y", []int{2, 4}},
+		{"nested groups", `(+n("."+n))`, "v1.25", []int{2, 6, 3, 6}},
+		{"empty group", `"a"(*n)"b"`, "ab", []int{2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := setupUndoFrame(t, e, tt.text)
+			var dfa *DFATableObject
+			tpar := TParObject{
+				Str: NewStrObjectFrom(tt.pattern),
+				Len: utf8.RuneCountInString(tt.pattern),
+				Dlm: TpdSmart,
+			}
+			require.True(t, e.eqsgetrepPatternBuild(tpar, &dfa))
+			line := frame.FirstGroup.FirstLine
+			markFlag := false
+			var startPos, finishPos int
+			require.True(t, e.PatternRecognize(dfa, line, 1, &markFlag, &startPos, &finishPos))
+			tags := e.PatternSubmatches(dfa, line, 1, false, startPos, finishPos)
+			require.NotNil(t, tags)
+			assert.Equal(t, tt.groups, tags[PatternTagGroups:])
+		})
+	}
+}
@@ -0,0 +1,263 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         REGEXP
+//
+// Description:  Regular expression targets for the EQS, GET and REPLACE
+//               commands.  A target given with the ~ delimiter is a Go
+//               regular expression, matched against the text of the frame
+//               with a newline at the end of each line, so that a match
+//               can run on from one line to the next.
+
+package ludwig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// regexpTarget is a regular expression compiled twice: to search for it,
+// and to match it at a position after reading the character before the
+// position, so that ^ and \b see the text either side of the position.  If
+// page is set, a search pages in more of the frame's input file as it
+// reads past what has been read so far.
+type regexpTarget struct {
+	find  *regexp.Regexp
+	after *regexp.Regexp
+	page  *Editor
+}
+
+// regexpReader reads the text of a frame from a position, with a newline at
+// the end of each line.  Every character counts as one byte, so the offsets
+// of a match are the number of characters from where the reading started.
+// If end is set the text stops at column endCol of line end, which must be
+// no further than the end of the line.  If page is set, more of the input
+// file is paged in before the reading gets to the null line.
+type regexpReader struct {
+	line   *LineHdrObject
+	col    int
+	prev   rune // read before the text if not -1
+	end    *LineHdrObject
+	endCol int
+	page   *Editor
+}
+
+func (r *regexpReader) ReadRune() (rune, int, error) {
+	if r.prev >= 0 {
+		ch := r.prev
+		r.prev = -1
+		return ch, 1, nil
+	}
+	if r.line.FLink == nil || (r.line == r.end && r.col >= r.endCol) {
+		return 0, 0, io.EOF
+	}
+	if r.col <= r.line.Used {
+		ch := r.line.Str.Get(r.col)
+		r.col++
+		return ch, 1, nil
+	}
+	if r.page != nil {
+		r.line = r.page.FileNextLine(r.line)
+	} else {
+		r.line = r.line.FLink
+	}
+	r.col = 1
+	return '\n', 1, nil
+}
+
+// regexpCompile compiles the target of a command given with the ~
+// delimiter.  ^ and $ match at the start and end of each line.
+func (e *Editor) regexpCompile(tpar *TParObject, target *regexpTarget) bool {
+	if err := regexpBuild(tpar, target); err != nil {
+		var syntaxErr *syntax.Error
+		if errors.As(err, &syntaxErr) {
+			e.ScreenMessage(fmt.Sprintf("Regular expression - %s.", syntaxErr.Code))
+		} else {
+			e.ScreenMessage(fmt.Sprintf("Regular expression - %v.", err))
+		}
+		return false
+	}
+	return true
+}
+
+// regexpBuild compiles a regular expression without saying what is wrong
+// with it.  A character holding a byte that is not UTF-8 stands for
+// itself, as it is read from the text.
+func regexpBuild(tpar *TParObject, target *regexpTarget) error {
+	var expr strings.Builder
+	for i := 1; i <= tpar.Len; i++ {
+		ch := tpar.Str.Get(i)
+		if ch >= rawByteBase+0x80 && ch <= rawByteBase+0xff {
+			fmt.Fprintf(&expr, `\x{%x}`, ch)
+		} else {
+			expr.WriteRune(ch)
+		}
+	}
+	find, err := regexp.Compile("(?m)" + expr.String())
+	if err != nil {
+		return err
+	}
+	// The expression is known to be well formed, so cannot escape the group
+	after, err := regexp.Compile(`\A(?s:.)(?m:` + expr.String() + ")")
+	if err != nil {
+		return err
+	}
+	*target = regexpTarget{find: find, after: after}
+	return nil
+}
+
+// regexpPosition returns the position a number of characters on from a
+// position in the text
+func regexpPosition(line *LineHdrObject, col int, offset int) (*LineHdrObject, int) {
+	r := regexpReader{line: line, col: col, prev: -1}
+	for range offset {
+		if _, _, err := r.ReadRune(); err != nil {
+			break
+		}
+	}
+	return r.line, r.col
+}
+
+// regexpStep returns the position one character on from a position in the
+// text, or false at the end of the text.  If end is not nil, the text stops
+// at column endCol of line end.
+func regexpStep(line *LineHdrObject, col int, end *LineHdrObject, endCol int) (*LineHdrObject, int, bool) {
+	r := regexpReader{line: line, col: col, prev: -1, end: end, endCol: endCol}
+	if _, _, err := r.ReadRune(); err != nil {
+		return line, col, false
+	}
+	return r.line, r.col, true
+}
+
+// regexpText returns the characters between two offsets from a position in
+// the text, with a newline where a line ends
+func regexpText(line *LineHdrObject, col int, start int, finish int) []rune {
+	if start < 0 || finish <= start {
+		return nil
+	}
+	r := regexpReader{line: line, col: col, prev: -1}
+	var text []rune
+	for i := range finish {
+		ch, _, err := r.ReadRune()
+		if err != nil {
+			break
+		}
+		if i >= start {
+			text = append(text, ch)
+		}
+	}
+	return text
+}
+
+// regexpMatch matches a target at a position in the text.  It returns the
+// offsets from the position of the match and of its groups, or nil if the
+// target does not match there.  If end is not nil, the text stops at
+// column endCol of line end.
+func (target *regexpTarget) regexpMatch(line *LineHdrObject, col int, end *LineHdrObject, endCol int) []int {
+	prev := '\n'
+	if col > 1 {
+		prev = ' '
+		if col-1 <= line.Used {
+			prev = line.Str.Get(col - 1)
+		}
+	}
+	r := regexpReader{line: line, col: col, prev: prev, end: end, endCol: endCol, page: target.page}
+	loc := target.after.FindReaderSubmatchIndex(&r)
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i]--
+		}
+	}
+	return loc
+}
+
+// regexpFind finds the first match of a target at or after a position in
+// the text.  It returns the position the offsets are from, which is where
+// the search started unless a match there had to be ruled out, and the
+// offsets of the match and of its groups, or nil if there is no match.
+// The null line holds no text, so nothing matches there.  If end is not
+// nil, the text stops at column endCol of line end.
+func (target *regexpTarget) regexpFind(line *LineHdrObject, col int, end *LineHdrObject, endCol int) (*LineHdrObject, int, []int) {
+	for {
+		if col > 1 {
+			// Searching from here cannot see the character before
+			if loc := target.regexpMatch(line, col, end, endCol); loc != nil {
+				return line, col, loc
+			}
+		}
+		r := regexpReader{line: line, col: col, prev: -1, end: end, endCol: endCol, page: target.page}
+		loc := target.find.FindReaderSubmatchIndex(&r)
+		if loc != nil {
+			if startLine, _ := regexpPosition(line, col, loc[0]); startLine.FLink == nil {
+				return line, col, nil
+			}
+		}
+		if loc == nil || loc[0] > 0 || col == 1 {
+			return line, col, loc
+		}
+		// The match depends on not seeing the character before
+		var ok bool
+		if line, col, ok = regexpStep(line, col, end, endCol); !ok {
+			return line, col, nil
+		}
+	}
+}
+
+// regexpFindBackwards finds the last match of a target that starts before a
+// position in the text.  It searches forwards from the start of the line
+// the position is on, taking the matches from left to right as G does, and
+// if there are none there from further and further back.  The text stops
+// at the position, so a match does not go past it, and each line is read
+// only a few times however far back the match is.  It returns the position
+// the offsets are from and the offsets of the match and of its groups, or
+// nil if there is no match.
+func (e *Editor) regexpFindBackwards(target *regexpTarget, limitLine *LineHdrObject, limitCol int) (*LineHdrObject, int, []int) {
+	endCol := min(limitCol, limitLine.Used+1)
+	start := limitLine
+	for back := 1; !e.TtControlC.Load(); back *= 2 {
+		var foundLine *LineHdrObject
+		var foundCol int
+		var found []int
+		line, col := start, 1
+		for !e.TtControlC.Load() {
+			findLine, findCol, loc := target.regexpFind(line, col, limitLine, endCol)
+			if loc == nil {
+				break
+			}
+			startLine, startCol := regexpPosition(findLine, findCol, loc[0])
+			if startLine == limitLine && startCol >= limitCol {
+				break
+			}
+			foundLine, foundCol, found = findLine, findCol, loc
+			line, col = regexpPosition(findLine, findCol, loc[1])
+			if loc[1] == loc[0] {
+				// Go past an empty match so as not to find it again
+				var ok bool
+				if line, col, ok = regexpStep(line, col, limitLine, endCol); !ok {
+					break
+				}
+			}
+		}
+		if found != nil {
+			return foundLine, foundCol, found
+		}
+		if start.BLink == nil {
+			break
+		}
+		for i := 0; i < back && start.BLink != nil; i++ {
+			start = start.BLink
+		}
+	}
+	return nil, 0, nil
+}
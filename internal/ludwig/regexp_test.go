@@ -0,0 +1,126 @@
+// Tests for regular expression targets
+
+package ludwig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markAt returns the line number and column of a mark
+func markAt(mark *MarkObject) [2]int {
+	var lineNr int
+	LineToNumber(mark.Line, &lineNr)
+	return [2]int{lineNr, mark.Col}
+}
+
+func TestRegexpGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		command string
+		dot     [2]int
+		equals  [2]int
+	}{
+		{"first match", "one two\nthree four", `g~t\w+~`, [2]int{1, 8}, [2]int{1, 5}},
+		{"count", "one two\nthree four", `2g~t\w+~`, [2]int{2, 6}, [2]int{2, 1}},
+		{"over the end of a line", "one two\nthree four", `g~o\nth~`, [2]int{2, 3}, [2]int{1, 7}},
+		{"start of line after Dot", "xab ab\nab", `j g~^ab~`, [2]int{2, 3}, [2]int{2, 1}},
+		{"word boundary after Dot", "concat cat", `j g~\bcat~`, [2]int{1, 11}, [2]int{1, 8}},
+		{"word inside after Dot", "xcat", `j g~\Bcat~`, [2]int{1, 5}, [2]int{1, 2}},
+		{"end of line", "ab\ncd", `g~$~`, [2]int{1, 3}, [2]int{1, 3}},
+		{"backwards", "a1 b22\nc333", `>j -g~\d+~`, [2]int{1, 5}, [2]int{1, 7}},
+		{"backwards count", "a1 b22\nc333", `>j -2g~\d+~`, [2]int{1, 2}, [2]int{1, 3}},
+		{"backwards over the end of a line", "ab\ncd", `a >j -g~b\nc~`, [2]int{1, 2}, [2]int{2, 2}},
+		{"backwards over several lines", "ab\ncd\nef", `2a >j -g~b\ncd\ne~`, [2]int{1, 2}, [2]int{3, 2}},
+		{"backwards up to Dot", "abc abc", `5j -g~a\w*~`, [2]int{1, 5}, [2]int{1, 6}},
+		{"backwards greedy", "ab\ncd\nef", `2a j -g~(?s).*~`, [2]int{3, 1}, [2]int{3, 2}},
+		{"backwards greedy from the start of a line", "ab\ncd\nef", `2a -g~(?s).*~`, [2]int{2, 1}, [2]int{3, 1}},
+		{"backwards from far", "x\n" + strings.Repeat("a\n", 1000) + "b", `1001a j -g~x\n(?s).*~`, [2]int{1, 1}, [2]int{1002, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages bytes.Buffer
+			e := newBatchEditor(t, &messages)
+			require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader(tt.text+"\n")))
+			require.True(t, e.BatchExecute(tt.command), messages.String())
+			assert.Equal(t, tt.dot, markAt(e.CurrentFrame.Dot))
+			assert.Equal(t, tt.equals, markAt(e.CurrentFrame.Marks[MarkEquals]))
+		})
+	}
+}
+
+func TestRegexpGetFails(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("one\ntwo\n")))
+
+	assert.False(t, e.BatchExecute(`g~three~`))
+	assert.Equal(t, [2]int{1, 1}, markAt(e.CurrentFrame.Dot))
+	assert.False(t, e.BatchExecute(`3g~o~`))
+	assert.False(t, e.BatchExecute(`g~(one~`))
+	assert.Contains(t, messages.String(), "Regular expression - missing closing ).")
+}
+
+func TestRegexpReplace(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		command string
+		result  []string
+	}{
+		{"groups", "key=value", `r~(\w+)=(\w+)~&2=&1~`, []string{"value=key"}},
+		{"every match", "foo boo\nmoo", `>r~o+~0~`, []string{"f0 b0", "m0"}},
+		{"backwards", "ab ab ab", `>j -2r~b~X~`, []string{"ab aX aX"}},
+		{"join lines", "one\ntwo\nthree", `r~\n~ ~ r~\n~ ~`, []string{"one two three"}},
+		{"split a line", "one two", "r~ ~\n~", []string{"one", "two"}},
+		{"group over the end of a line", "a\nb c", `r~(a\nb)~[&1]~`, []string{"[a", "b] c"}},
+		{"backwards over the end of a line", "ab\ncd", `a >j -r~b\nc~-~`, []string{"a-d"}},
+		{"start of line count", "ab\nxc\nyc", `2r~^~>~`, []string{">ab", ">xc", "yc"}},
+		{"start of every line", "ab\nxc", `>r~^~>~`, []string{">ab", ">xc"}},
+		{"end of line count", "aab\nxc\nyc", `2r~$~;~`, []string{"aab;", "xc;", "yc"}},
+		{"end of every line", "aab\nxc", `>r~$~;~`, []string{"aab;", "xc;"}},
+		{"empty matches count", "aab", `3r~x*~-~`, []string{"-a-a-b"}},
+		{"empty and other matches", "axxb\nc", `>r~x*~-~`, []string{"-a-b-", "-c-"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages bytes.Buffer
+			e := newBatchEditor(t, &messages)
+			require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader(tt.text+"\n")))
+			require.True(t, e.BatchExecute(tt.command), messages.String())
+			assert.Equal(t, tt.result, frameText(e.CurrentFrame))
+		})
+	}
+}
+
+func TestRegexpReplaceEmptyFails(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("aab\nxc\n")))
+
+	// There are only two ends of lines, the null line has none
+	assert.False(t, e.BatchExecute(`3r~$~;~`))
+	assert.Equal(t, []string{"aab;", "xc;"}, frameText(e.CurrentFrame))
+}
+
+func TestRegexpEqs(t *testing.T) {
+	var messages bytes.Buffer
+	e := newBatchEditor(t, &messages)
+	require.True(t, e.FileLoad(e.CurrentFrame, strings.NewReader("abc\ndef\n")))
+
+	require.True(t, e.BatchExecute(`eqs~a.c\nd~`), messages.String())
+	assert.Equal(t, [2]int{2, 2}, markAt(e.CurrentFrame.Marks[MarkEquals]))
+	assert.Equal(t, [2]int{1, 1}, markAt(e.CurrentFrame.Dot))
+	assert.False(t, e.BatchExecute(`eqs~b~`))
+	assert.True(t, e.BatchExecute(`-eqs~b~`))
+	assert.True(t, e.BatchExecute(`j eqs~(?i)B~`))
+	assert.False(t, e.BatchExecute(`>eqs~a~`))
+	assert.Contains(t, messages.String(), MsgIllegalLeadingParam)
+}
@@ -56,33 +56,80 @@ func spc(count int) string {
 }
 
 // ScreenMessage puts a message out to the user
-func ScreenMessage(message string) {
-	if Hangup {
+func (e *Editor) ScreenMessage(message string) {
+	if e.Hangup {
 		return
 	}
 
-	if LudwigMode == LudwigScreen {
+	if e.LudwigMode == LudwigScreen {
 		for i := 0; i < len(message); {
-			ScreenFreeBottomLine()
-			VduMoveCurs(1, TerminalInfo.Height)
+			e.ScreenFreeBottomLine()
+			e.VduMoveCurs(1, e.TerminalInfo.Height)
 			j := len(message) - i
-			if j > TerminalInfo.Width-1 {
-				j = TerminalInfo.Width - 1
+			if j > e.TerminalInfo.Width-1 {
+				j = e.TerminalInfo.Width - 1
 			}
-			VduBold()
-			VduDisplayStr(message[i:i+j], 3)
-			VduNormal()
+			e.VduBold()
+			e.VduDisplayStr(message[i:i+j], 3)
+			e.VduNormal()
 			i += j
 		}
 	} else {
-		fmt.Println(message)
+		fmt.Fprintln(e.Output, message)
 	}
 }
 
+// screenNarrow returns true if every character of a line takes up exactly
+// one column of the screen, so that columns and screen columns agree
+func screenNarrow(line *LineHdrObject) bool {
+	for i := 1; i <= line.Used; i++ {
+		if ChWidth(line.Str.Get(i)) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// screenColumn returns the screen column of a column of a line when the
+// screen is offset by offset columns
+func screenColumn(line *LineHdrObject, col int, offset int) int {
+	scrCol := col - offset
+	for i := offset + 1; i < col && i <= line.Used; i++ {
+		scrCol += ChWidth(line.Str.Get(i)) - 1
+	}
+	return scrCol
+}
+
+// screenDotCol returns the screen column of Dot in the current frame
+func (e *Editor) screenDotCol() int {
+	return screenColumn(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, e.CurrentFrame.ScrOffset)
+}
+
+// screenFit returns how many of the strlen characters of a line starting
+// at col fit into width columns of the screen
+func screenFit(line *LineHdrObject, col int, strlen int, width int) int {
+	used := 0
+	for i := 0; i < strlen; i++ {
+		used += ChWidth(line.Str.Get(col + i))
+		if used > width {
+			return i
+		}
+	}
+	return strlen
+}
+
 // ScreenDrawLine draws a line if it is on the screen
-func ScreenDrawLine(line *LineHdrObject) {
-	VduMoveCurs(1, line.ScrRowNr)
-	offset := ScrFrame.ScrOffset
+func (e *Editor) ScreenDrawLine(line *LineHdrObject) {
+	e.VduMoveCurs(1, line.ScrRowNr)
+	e.screenDrawText(line, e.ScrFrame.ScrOffset, e.ScrFrame.ScrWidth)
+	if line.ScrRowNr == e.ScrMsgRow {
+		e.ScrMsgRow++
+	}
+}
+
+// screenDrawText draws the text of a line at the cursor, starting offset
+// columns into the line and fitting into width columns of the screen
+func (e *Editor) screenDrawText(line *LineHdrObject, offset int, width int) {
 	var strlen int
 
 	eopLine := false
@@ -94,86 +141,158 @@ func ScreenDrawLine(line *LineHdrObject) {
 		eopLine = true
 	}
 
+	var runs []SyntaxRun
+	if !eopLine {
+		runs = e.SyntaxLine(line)
+		for _, match := range e.MatchRuns(line) {
+			runs = screenOverlay(runs, match)
+		}
+		if line == e.isearchLine && e.isearchEnd > e.isearchStart {
+			runs = screenOverlay(runs, SyntaxRun{Col: e.isearchStart, Len: e.isearchEnd - e.isearchStart, Class: SyntaxMatch})
+		}
+	}
+
 	if strlen <= 0 {
-		VduClearEOL()
+		e.VduClearEOL()
 	} else {
-		if strlen > ScrFrame.ScrWidth {
-			strlen = ScrFrame.ScrWidth
+		strlen = screenFit(line, offset+1, strlen, width)
+		if runs != nil {
+			e.screenDrawRuns(line, runs, offset+1, strlen)
+			return
 		}
 		if eopLine {
-			VduDim()
+			e.VduDim()
 		}
-		VduDisplayStr(line.Str.Slice(offset+1, strlen), 3)
+		e.VduDisplayStr(line.Str.Slice(offset+1, strlen), 3)
 		if eopLine {
-			VduNormal()
+			e.VduNormal()
 		}
 	}
+}
 
-	if line.ScrRowNr == ScrMsgRow {
-		ScrMsgRow++
+// screenDrawRuns draws strlen characters of a line starting at col, each
+// part in the colour of the highlighted run it is in
+func (e *Editor) screenDrawRuns(line *LineHdrObject, runs []SyntaxRun, col int, strlen int) {
+	last := col + strlen - 1
+	i := 0
+	for col <= last {
+		for i < len(runs) && runs[i].Col+runs[i].Len <= col {
+			i++
+		}
+		class := SyntaxPlain
+		end := last
+		if i < len(runs) {
+			if runs[i].Col <= col {
+				class = runs[i].Class
+				end = min(end, runs[i].Col+runs[i].Len-1)
+			} else {
+				end = min(end, runs[i].Col-1)
+			}
+		}
+		opts := 0
+		if end == last {
+			opts = 3
+		}
+		e.VduSyntax(class)
+		e.VduDisplayStr(line.Str.Slice(col, end+1-col), opts)
+		col = end + 1
 	}
+	e.VduNormal()
+}
+
+// screenOverlay returns the runs of a line with another run laid over them,
+// cutting short or splitting the runs it covers
+func screenOverlay(runs []SyntaxRun, over SyntaxRun) []SyntaxRun {
+	end := over.Col + over.Len
+	var result []SyntaxRun
+	added := false
+	for _, run := range runs {
+		runEnd := run.Col + run.Len
+		if !added && runEnd > over.Col {
+			if run.Col < over.Col {
+				result = append(result, SyntaxRun{Col: run.Col, Len: over.Col - run.Col, Class: run.Class})
+			}
+			result = append(result, over)
+			added = true
+		}
+		if runEnd <= over.Col {
+			result = append(result, run)
+		} else if runEnd > end {
+			start := max(run.Col, end)
+			result = append(result, SyntaxRun{Col: start, Len: runEnd - start, Class: run.Class})
+		}
+	}
+	if !added {
+		result = append(result, over)
+	}
+	return result
 }
 
 // ScreenRedraw redraws the screen exactly as is
-func ScreenRedraw() {
-	if ScrFrame != nil {
-		VduClearScr()
-		ScrMsgRow = TerminalInfo.Height + 1
-		ScrNeedsFix = false
-		line := ScrTopLine
-		for line != ScrBotLine {
-			ScreenDrawLine(line)
+func (e *Editor) ScreenRedraw() {
+	if e.ScrFrame != nil {
+		e.VduClearScr()
+		e.ScrMsgRow = e.TerminalInfo.Height + 1
+		e.ScrNeedsFix = false
+		line := e.ScrTopLine
+		for line != e.ScrBotLine {
+			e.ScreenDrawLine(line)
 			line = line.FLink
 		}
-		ScreenDrawLine(line)
+		e.ScreenDrawLine(line)
+		e.WindowUpdate()
 	}
 }
 
 // screenSlideLine slides a line according to slide_dist and slide_state
-func screenSlideLine(line *LineHdrObject, slideDist int, slideState slideType) {
+func (e *Editor) screenSlideLine(line *LineHdrObject, slideDist int, slideState slideType) {
 	if line.FLink == nil {
 		return
 	}
+	if !screenNarrow(line) || e.SyntaxRulesFor(e.ScrFrame) != nil {
+		e.ScreenDrawLine(line)
+		return
+	}
 
-	offset := ScrFrame.ScrOffset
-	width := ScrFrame.ScrWidth
+	offset := e.ScrFrame.ScrOffset
+	width := e.ScrFrame.ScrWidth
 
-	VduMoveCurs(1, line.ScrRowNr)
+	e.VduMoveCurs(1, line.ScrRowNr)
 
 	if slideState == slideLeft {
 		overlap := line.Used - offset
 		if overlap > 0 {
 			if overlap > slideDist {
-				VduInsertChars(slideDist)
+				e.VduInsertChars(slideDist)
 				overlap = slideDist
 			}
-			VduDisplayStr(line.Str.Slice(offset+1, overlap), 2)
+			e.VduDisplayStr(line.Str.Slice(offset+1, overlap), 2)
 		}
 	} else {
 		if offset-slideDist < line.Used {
 			overlap := line.Used - (offset - slideDist + width)
 			if slideDist >= width {
-				VduClearEOL()
+				e.VduClearEOL()
 				slideDist = width
 			} else {
-				VduDeleteChars(slideDist)
-				VduMoveCurs(width+1-slideDist, line.ScrRowNr)
+				e.VduDeleteChars(slideDist)
+				e.VduMoveCurs(width+1-slideDist, line.ScrRowNr)
 			}
 			if overlap > 0 {
 				if overlap > slideDist {
 					overlap = slideDist
 				}
-				VduDisplayStr(line.Str.Slice(offset+width+1-slideDist+1, overlap), 2)
+				e.VduDisplayStr(line.Str.Slice(offset+width+1-slideDist+1, overlap), 2)
 			}
 		}
 	}
 }
 
 // ScreenSlide slides the whole screen the specified distance
-func ScreenSlide(dist int) {
-	if ScrFrame != nil {
+func (e *Editor) ScreenSlide(dist int) {
+	if e.ScrFrame != nil {
 		if dist != 0 {
-			ScrFrame.ScrOffset += dist
+			e.ScrFrame.ScrOffset += dist
 			var s slideType
 			if dist < 0 {
 				s = slideLeft
@@ -181,10 +300,10 @@ func ScreenSlide(dist int) {
 			} else {
 				s = slideRight
 			}
-			l := ScrTopLine
+			l := e.ScrTopLine
 			for l != nil {
-				screenSlideLine(l, dist, s)
-				if l == ScrBotLine {
+				e.screenSlideLine(l, dist, s)
+				if l == e.ScrBotLine {
 					l = nil
 				} else {
 					l = l.FLink
@@ -195,50 +314,50 @@ func ScreenSlide(dist int) {
 }
 
 // ScreenUnload unloads the screen
-func ScreenUnload() {
-	if ScrFrame != nil {
-		if ScrFrame.Dot.Line.ScrRowNr == 0 {
-			ScrFrame.ScrDotLine =
-				(ScrFrame.MarginTop+ScrFrame.ScrHeight-ScrFrame.MarginBottom+1)/2 +
-					(TerminalInfo.Height-ScrFrame.ScrHeight)/2
+func (e *Editor) ScreenUnload() {
+	if e.ScrFrame != nil {
+		if e.ScrFrame.Dot.Line.ScrRowNr == 0 {
+			e.ScrFrame.ScrDotLine =
+				(e.ScrFrame.MarginTop+e.ScrFrame.ScrHeight-e.ScrFrame.MarginBottom+1)/2 +
+					(e.TerminalInfo.Height-e.ScrFrame.ScrHeight)/2
 		} else {
-			ScrFrame.ScrDotLine = ScrFrame.Dot.Line.ScrRowNr
-		}
-		VduClearScr()
-		ScrMsgRow = TerminalInfo.Height + 1
-		ScrNeedsFix = false
-		ScrTopLine.ScrRowNr = 0
-		for ScrTopLine != ScrBotLine {
-			ScrTopLine = ScrTopLine.FLink
-			ScrTopLine.ScrRowNr = 0
-		}
-		ScrFrame = nil
-		ScrBotLine = nil
-		ScrTopLine = nil
+			e.ScrFrame.ScrDotLine = e.ScrFrame.Dot.Line.ScrRowNr
+		}
+		e.VduClearScr()
+		e.ScrMsgRow = e.TerminalInfo.Height + 1
+		e.ScrNeedsFix = false
+		e.ScrTopLine.ScrRowNr = 0
+		for e.ScrTopLine != e.ScrBotLine {
+			e.ScrTopLine = e.ScrTopLine.FLink
+			e.ScrTopLine.ScrRowNr = 0
+		}
+		e.ScrFrame = nil
+		e.ScrBotLine = nil
+		e.ScrTopLine = nil
 	}
 }
 
 // ScreenScroll scrolls the screen forward or back
-func ScreenScroll(count int, expand bool) {
-	if ScrFrame == nil {
+func (e *Editor) ScreenScroll(count int, expand bool) {
+	if e.ScrFrame == nil {
 		return
 	}
 
-	botLine := ScrBotLine
-	topLine := ScrTopLine
+	botLine := e.ScrBotLine
+	topLine := e.ScrTopLine
 
 	if count >= 0 {
 		// FORWARD DIRECTION
 		var botLineNr int
 		if expand {
 			LineToNumber(botLine, &botLineNr)
-			eopLineNr := ScrFrame.LastGroup.FirstLineNr + ScrFrame.LastGroup.LastLine.OffsetNr
+			eopLineNr := e.ScrFrame.LastGroup.FirstLineNr + e.ScrFrame.LastGroup.LastLine.OffsetNr
 			remainingLines := eopLineNr - botLineNr
 			if remainingLines < count {
 				count = remainingLines
 			}
 			botLineRow := botLine.ScrRowNr
-			freeLines := TerminalInfo.Height - botLineRow
+			freeLines := e.TerminalInfo.Height - botLineRow
 			if freeLines > count {
 				freeLines = count
 			}
@@ -246,9 +365,9 @@ func ScreenScroll(count int, expand bool) {
 				for rowNr := botLineRow + 1; rowNr <= botLineRow+freeLines; rowNr++ {
 					botLine = botLine.FLink
 					botLine.ScrRowNr = rowNr
-					ScreenDrawLine(botLine)
+					e.ScreenDrawLine(botLine)
 				}
-				ScrBotLine = botLine
+				e.ScrBotLine = botLine
 				count -= freeLines
 				if count == 0 {
 					return
@@ -260,18 +379,18 @@ func ScreenScroll(count int, expand bool) {
 			// Would have to scroll too far, redraw instead
 			var frame *FrameObject
 			if expand {
-				frame = ScrFrame
+				frame = e.ScrFrame
 				botLineNr += count
-				LineFromNumber(ScrFrame, botLineNr, &botLine)
+				LineFromNumber(e.ScrFrame, botLineNr, &botLine)
 			}
-			ScreenUnload()
+			e.ScreenUnload()
 			if expand {
-				ScrFrame = frame
-				ScrTopLine = botLine
-				ScrBotLine = botLine
-				botLine.ScrRowNr = TerminalInfo.Height
-				ScreenDrawLine(botLine)
-				screenExpand(true, false)
+				e.ScrFrame = frame
+				e.ScrTopLine = botLine
+				e.ScrBotLine = botLine
+				botLine.ScrRowNr = e.TerminalInfo.Height
+				e.ScreenDrawLine(botLine)
+				e.screenExpand(true, false)
 			}
 			return
 		}
@@ -279,14 +398,14 @@ func ScreenScroll(count int, expand bool) {
 		// SCROLL 'COUNT' LINES ONTO THE SCREEN
 		for count > 0 {
 			count--
-			VduScrollUp(1)
-			if ScrMsgRow <= TerminalInfo.Height {
-				ScrMsgRow--
+			e.VduScrollUp(1)
+			if e.ScrMsgRow <= e.TerminalInfo.Height {
+				e.ScrMsgRow--
 			}
 
 			if expand {
 				botLine.FLink.ScrRowNr = botLine.ScrRowNr
-				ScreenDrawLine(botLine.FLink)
+				e.ScreenDrawLine(botLine.FLink)
 				botLine = botLine.FLink
 			} else {
 				botLine.ScrRowNr--
@@ -314,13 +433,13 @@ func ScreenScroll(count int, expand bool) {
 				freeLines = count
 			}
 
-			if topLineRow+count-freeLines <= TerminalInfo.Height+1 {
+			if topLineRow+count-freeLines <= e.TerminalInfo.Height+1 {
 				for rowNr := topLineRow - 1; rowNr >= topLineRow-freeLines; rowNr-- {
 					topLine = topLine.BLink
 					topLine.ScrRowNr = rowNr
-					ScreenDrawLine(topLine)
+					e.ScreenDrawLine(topLine)
 				}
-				ScrTopLine = topLine
+				e.ScrTopLine = topLine
 				count -= freeLines
 				if count == 0 {
 					return
@@ -328,23 +447,23 @@ func ScreenScroll(count int, expand bool) {
 			}
 		}
 
-		if count+topLine.ScrRowNr > TerminalInfo.Height+1 {
+		if count+topLine.ScrRowNr > e.TerminalInfo.Height+1 {
 			// REDRAW
 			var frame *FrameObject
 			var tmpTopLine *LineHdrObject
 			if expand {
-				frame = ScrFrame
+				frame = e.ScrFrame
 				topLineNr -= count
-				LineFromNumber(ScrFrame, topLineNr, &tmpTopLine)
+				LineFromNumber(e.ScrFrame, topLineNr, &tmpTopLine)
 			}
-			ScreenUnload()
+			e.ScreenUnload()
 			if expand {
-				ScrFrame = frame
-				ScrTopLine = tmpTopLine
-				ScrBotLine = tmpTopLine
-				tmpTopLine.ScrRowNr = 1 + TerminalInfo.Height - ScrFrame.ScrHeight
-				ScreenDrawLine(tmpTopLine)
-				screenExpand(false, true)
+				e.ScrFrame = frame
+				e.ScrTopLine = tmpTopLine
+				e.ScrBotLine = tmpTopLine
+				tmpTopLine.ScrRowNr = 1 + e.TerminalInfo.Height - e.ScrFrame.ScrHeight
+				e.ScreenDrawLine(tmpTopLine)
+				e.screenExpand(false, true)
 			}
 			return
 		}
@@ -352,23 +471,23 @@ func ScreenScroll(count int, expand bool) {
 		// SCROLL 'COUNT' LINES ONTO THE SCREEN
 		for count > 0 {
 			count--
-			VduMoveCurs(1, 1)
-			VduInsertLines(1)
-			if ScrMsgRow <= TerminalInfo.Height {
-				ScrMsgRow++
+			e.VduMoveCurs(1, 1)
+			e.VduInsertLines(1)
+			if e.ScrMsgRow <= e.TerminalInfo.Height {
+				e.ScrMsgRow++
 			}
 
 			if expand {
 				topLine.BLink.ScrRowNr = topLine.ScrRowNr
-				ScreenDrawLine(topLine.BLink)
+				e.ScreenDrawLine(topLine.BLink)
 				topLine = topLine.BLink
 			} else {
 				topLine.ScrRowNr--
 			}
 
-			if botLine.ScrRowNr == TerminalInfo.Height {
+			if botLine.ScrRowNr == e.TerminalInfo.Height {
 				botLine.ScrRowNr = 0
-				botLine.BLink.ScrRowNr = TerminalInfo.Height
+				botLine.BLink.ScrRowNr = e.TerminalInfo.Height
 				botLine = botLine.BLink
 			} else {
 				botLine.ScrRowNr++
@@ -377,8 +496,8 @@ func ScreenScroll(count int, expand bool) {
 	}
 
 	// NOW RESET THE DAMAGED SCREEN POINTERS AND LINE NUMBERS
-	ScrTopLine = topLine
-	ScrBotLine = botLine
+	e.ScrTopLine = topLine
+	e.ScrBotLine = botLine
 
 	rowNr := topLine.ScrRowNr
 	for topLine != botLine {
@@ -389,13 +508,13 @@ func ScreenScroll(count int, expand bool) {
 }
 
 // screenExpand expands a screen out to at least the frame's specified screen height
-func screenExpand(initUpwards bool, initDownwards bool) {
+func (e *Editor) screenExpand(initUpwards bool, initDownwards bool) {
 	upwards := initUpwards
 	downwards := initDownwards
 
-	height := ScrFrame.ScrHeight
-	botLine := ScrBotLine
-	topLine := ScrTopLine
+	height := e.ScrFrame.ScrHeight
+	botLine := e.ScrBotLine
+	topLine := e.ScrTopLine
 
 	linesOnScr := botLine.ScrRowNr + 1 - topLine.ScrRowNr
 
@@ -404,12 +523,12 @@ func screenExpand(initUpwards bool, initDownwards bool) {
 			downwards = false
 			curRow := botLine.ScrRowNr
 			if botLine.FLink != nil {
-				if curRow < TerminalInfo.Height {
+				if curRow < e.TerminalInfo.Height {
 					downwards = true
 					linesOnScr++
 					botLine = botLine.FLink
 					botLine.ScrRowNr = curRow + 1
-					ScreenDrawLine(botLine)
+					e.ScreenDrawLine(botLine)
 				}
 			}
 		}
@@ -423,71 +542,71 @@ func screenExpand(initUpwards bool, initDownwards bool) {
 					linesOnScr++
 					topLine = topLine.BLink
 					topLine.ScrRowNr = curRow - 1
-					ScreenDrawLine(topLine)
+					e.ScreenDrawLine(topLine)
 				}
 			}
 		}
 	}
 
-	ScrBotLine = botLine
-	ScrTopLine = topLine
+	e.ScrBotLine = botLine
+	e.ScrTopLine = topLine
 
 	// If just expanding wasn't enough then try scrolling to get the lines.
 	if linesOnScr < height {
 		if initDownwards {
 			if botLine.FLink != nil {
-				ScreenScroll(height-linesOnScr, true)
-				linesOnScr = ScrBotLine.ScrRowNr + 1 - ScrTopLine.ScrRowNr
+				e.ScreenScroll(height-linesOnScr, true)
+				linesOnScr = e.ScrBotLine.ScrRowNr + 1 - e.ScrTopLine.ScrRowNr
 			}
 		}
 		if initUpwards && linesOnScr < height {
 			var nrLines int
-			if LineToNumber(ScrTopLine, &nrLines) {
+			if LineToNumber(e.ScrTopLine, &nrLines) {
 				if nrLines >= height-linesOnScr {
 					nrLines = height - linesOnScr
 				}
-				ScreenScroll(-nrLines, true)
+				e.ScreenScroll(-nrLines, true)
 			}
 		}
 	}
 
 	// Redraw the <TOP> and <BOTTOM> markers.
-	if ScrBotLine.FLink != nil {
-		curRow := ScrBotLine.ScrRowNr
-		if curRow < TerminalInfo.Height {
+	if e.ScrBotLine.FLink != nil {
+		curRow := e.ScrBotLine.ScrRowNr
+		if curRow < e.TerminalInfo.Height {
 			curRow += 1
-			VduMoveCurs(1, curRow)
-			VduBold()
-			VduDisplayStr("<BOTTOM>", 3)
-			VduNormal()
-			if curRow == ScrMsgRow {
-				ScrMsgRow += 1
+			e.VduMoveCurs(1, curRow)
+			e.VduBold()
+			e.VduDisplayStr("<BOTTOM>", 3)
+			e.VduNormal()
+			if curRow == e.ScrMsgRow {
+				e.ScrMsgRow += 1
 			}
 		}
 	}
 
-	if ScrTopLine.ScrRowNr > 1 {
-		VduMoveCurs(1, ScrTopLine.ScrRowNr-1)
-		VduBold()
-		VduDisplayStr("<TOP>", 3)
-		VduNormal()
+	if e.ScrTopLine.ScrRowNr > 1 {
+		e.VduMoveCurs(1, e.ScrTopLine.ScrRowNr-1)
+		e.VduBold()
+		e.VduDisplayStr("<TOP>", 3)
+		e.VduNormal()
 	}
 }
 
 // ScreenLinesExtract extracts lines from the screen
-func ScreenLinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) {
-	if lastLine != ScrBotLine {
+func (e *Editor) ScreenLinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) {
+	if lastLine != e.ScrBotLine {
 		// EXTRACTION NOT AT BOT-OF-SCR ACCOMPLISHED VIA TERMINAL H/W
-		VduMoveCurs(1, firstLine.ScrRowNr)
+		e.VduMoveCurs(1, firstLine.ScrRowNr)
 		count := lastLine.ScrRowNr + 1 - firstLine.ScrRowNr
-		VduDeleteLines(count)
-		if ScrMsgRow <= TerminalInfo.Height {
-			ScrMsgRow -= count
+		e.VduDeleteLines(count)
+		if e.ScrMsgRow <= e.TerminalInfo.Height {
+			e.ScrMsgRow -= count
 		}
 
 		lineLimit := lastLine.FLink
-		if firstLine == ScrTopLine {
-			ScrTopLine = lineLimit
+		if firstLine == e.ScrTopLine {
+			e.ScrTopLine = lineLimit
 		}
 		count = lineLimit.ScrRowNr - firstLine.ScrRowNr
 		for {
@@ -497,7 +616,7 @@ func ScreenLinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) {
 				break
 			}
 		}
-		lineLimit = ScrBotLine.FLink
+		lineLimit = e.ScrBotLine.FLink
 		for {
 			firstLine.ScrRowNr -= count
 			firstLine = firstLine.FLink
@@ -508,42 +627,42 @@ func ScreenLinesExtract(firstLine *LineHdrObject, lastLine *LineHdrObject) {
 		return
 	}
 
-	if firstLine == ScrTopLine {
-		ScreenUnload()
+	if firstLine == e.ScrTopLine {
+		e.ScreenUnload()
 	} else {
 		lineLimit := firstLine.BLink
 		for {
-			ScrBotLine.ScrRowNr = 0
-			ScrBotLine = ScrBotLine.BLink
-			if ScrBotLine == lineLimit {
+			e.ScrBotLine.ScrRowNr = 0
+			e.ScrBotLine = e.ScrBotLine.BLink
+			if e.ScrBotLine == lineLimit {
 				break
 			}
 		}
-		VduMoveCurs(1, ScrBotLine.ScrRowNr+1)
-		VduClearEOS()
-		ScrMsgRow = TerminalInfo.Height + 1
+		e.VduMoveCurs(1, e.ScrBotLine.ScrRowNr+1)
+		e.VduClearEOS()
+		e.ScrMsgRow = e.TerminalInfo.Height + 1
 	}
 }
 
 // ScreenLinesInject injects lines into the screen
-func ScreenLinesInject(firstLine *LineHdrObject, count int, beforeLine *LineHdrObject) {
+func (e *Editor) ScreenLinesInject(firstLine *LineHdrObject, count int, beforeLine *LineHdrObject) {
 	// HEURISTIC -- KEEP AS MANY LINES ON THE SCREEN AS POSSIBLE
-	freeSpaceBelow := TerminalInfo.Height - ScrBotLine.ScrRowNr
-	freeSpaceAbove := ScrTopLine.ScrRowNr - 1
+	freeSpaceBelow := e.TerminalInfo.Height - e.ScrBotLine.ScrRowNr
+	freeSpaceAbove := e.ScrTopLine.ScrRowNr - 1
 
-	if freeSpaceAbove > 0 && beforeLine != ScrTopLine &&
-		TerminalInfo.Height > beforeLine.ScrRowNr-freeSpaceAbove+count {
+	if freeSpaceAbove > 0 && beforeLine != e.ScrTopLine &&
+		e.TerminalInfo.Height > beforeLine.ScrRowNr-freeSpaceAbove+count {
 		scrollupCount := count - freeSpaceBelow
 		if scrollupCount > 0 {
 			if scrollupCount > freeSpaceAbove {
 				scrollupCount = freeSpaceAbove
 			}
-			VduScrollUp(scrollupCount)
-			if ScrMsgRow <= TerminalInfo.Height {
-				ScrMsgRow = ScrMsgRow - scrollupCount
+			e.VduScrollUp(scrollupCount)
+			if e.ScrMsgRow <= e.TerminalInfo.Height {
+				e.ScrMsgRow = e.ScrMsgRow - scrollupCount
 			}
 
-			line := ScrTopLine
+			line := e.ScrTopLine
 			rowNr := line.ScrRowNr - scrollupCount
 			for {
 				line.ScrRowNr = rowNr
@@ -554,42 +673,42 @@ func ScreenLinesInject(firstLine *LineHdrObject, count int, beforeLine *LineHdrO
 				}
 			}
 
-			ScrBotLine.ScrRowNr -= scrollupCount
+			e.ScrBotLine.ScrRowNr -= scrollupCount
 			beforeLine.ScrRowNr = rowNr
 		}
 	}
 
 	// The screen is now optimally placed for the insertion
-	if beforeLine == ScrTopLine && freeSpaceAbove > 0 && count+1 <= TerminalInfo.Height {
-		rowNr := ScrTopLine.ScrRowNr - 1
+	if beforeLine == e.ScrTopLine && freeSpaceAbove > 0 && count+1 <= e.TerminalInfo.Height {
+		rowNr := e.ScrTopLine.ScrRowNr - 1
 		for rowNr > 1 && count > 0 {
-			ScrTopLine = ScrTopLine.BLink
-			ScrTopLine.ScrRowNr = rowNr
-			ScreenDrawLine(ScrTopLine)
+			e.ScrTopLine = e.ScrTopLine.BLink
+			e.ScrTopLine.ScrRowNr = rowNr
+			e.ScreenDrawLine(e.ScrTopLine)
 			rowNr--
 			count--
 		}
-		beforeLine = ScrTopLine
+		beforeLine = e.ScrTopLine
 	}
 
 	// Finally do the insert if necessary
 	if count > 0 {
-		if beforeLine == ScrTopLine {
-			ScrTopLine = firstLine
+		if beforeLine == e.ScrTopLine {
+			e.ScrTopLine = firstLine
 		}
 		rowNr := beforeLine.ScrRowNr
-		VduMoveCurs(1, rowNr)
-		VduInsertLines(count)
-		if ScrMsgRow <= TerminalInfo.Height {
-			ScrMsgRow = ScrMsgRow + count
-			if ScrMsgRow > TerminalInfo.Height {
-				ScrMsgRow = TerminalInfo.Height + 1
+		e.VduMoveCurs(1, rowNr)
+		e.VduInsertLines(count)
+		if e.ScrMsgRow <= e.TerminalInfo.Height {
+			e.ScrMsgRow = e.ScrMsgRow + count
+			if e.ScrMsgRow > e.TerminalInfo.Height {
+				e.ScrMsgRow = e.TerminalInfo.Height + 1
 			}
 		}
 
 		// Patch up the pointers and scr_row_nr's of lines pushed off screen
-		line := ScrBotLine
-		for i := line.ScrRowNr + count; i >= TerminalInfo.Height+1; i-- {
+		line := e.ScrBotLine
+		for i := line.ScrRowNr + count; i >= e.TerminalInfo.Height+1; i-- {
 			if line.ScrRowNr == 0 {
 				break
 			}
@@ -599,12 +718,12 @@ func ScreenLinesInject(firstLine *LineHdrObject, count int, beforeLine *LineHdrO
 
 		if line.ScrRowNr != 0 {
 			// Lines were pushed but left on the screen
-			ScrBotLine = line
+			e.ScrBotLine = line
 			rowNr = line.ScrRowNr + count
 			for {
 				if line.ScrRowNr == 0 {
 					line.ScrRowNr = rowNr
-					ScreenDrawLine(line)
+					e.ScreenDrawLine(line)
 				} else {
 					line.ScrRowNr = rowNr
 				}
@@ -615,22 +734,22 @@ func ScreenLinesInject(firstLine *LineHdrObject, count int, beforeLine *LineHdrO
 				}
 			}
 			line.ScrRowNr = rowNr
-			ScreenDrawLine(line)
+			e.ScreenDrawLine(line)
 		} else {
 			// No lines were left on the screen
-			ScrBotLine = firstLine
+			e.ScrBotLine = firstLine
 			firstLine.ScrRowNr = rowNr
-			ScreenDrawLine(firstLine)
-			screenExpand(false, true)
+			e.ScreenDrawLine(firstLine)
+			e.screenExpand(false, true)
 		}
 	}
 }
 
 // ScreenLoad loads a screen centered on the given line
-func ScreenLoad(line *LineHdrObject) {
+func (e *Editor) ScreenLoad(line *LineHdrObject) {
 	frame := line.Group.Frame
 
-	switch LudwigMode {
+	switch e.LudwigMode {
 	case LudwigBatch:
 		// Do nothing
 
@@ -645,27 +764,27 @@ func ScreenLoad(line *LineHdrObject) {
 		newRow = 1
 		for newRow <= frame.ScrHeight && line != nil {
 			if newRow == 1 {
-				fmt.Println("WINDOW:")
+				fmt.Fprintln(e.Output, "WINDOW:")
 			}
 			buflen := line.Used
 			if line.FLink == nil {
 				buflen = line.Len()
 			}
 			if buflen > 0 && line.Str != nil {
-				fmt.Println(line.Str.Slice(1, buflen))
+				fmt.Fprintln(e.Output, line.Str.Slice(1, buflen))
 			} else {
-				fmt.Println("")
+				fmt.Fprintln(e.Output)
 			}
 			if line == dotLine {
 				switch dotCol {
 				case 1:
-					fmt.Println("<")
+					fmt.Fprintln(e.Output, "<")
 				case MaxStrLenP:
-					fmt.Print(strings.Repeat(" ", MaxStrLen-1))
-					fmt.Println(">")
+					fmt.Fprint(e.Output, strings.Repeat(" ", buflen))
+					fmt.Fprintln(e.Output, ">")
 				default:
-					fmt.Print(strings.Repeat(" ", dotCol-2))
-					fmt.Println("><")
+					fmt.Fprint(e.Output, strings.Repeat(" ", dotCol-2))
+					fmt.Fprintln(e.Output, "><")
 				}
 			}
 			newRow++
@@ -673,20 +792,20 @@ func ScreenLoad(line *LineHdrObject) {
 		}
 
 	case LudwigScreen:
-		if ScrFrame != nil {
-			ScreenUnload()
+		if e.ScrFrame != nil {
+			e.ScreenUnload()
 		} else {
-			VduClearScr()
-			ScrMsgRow = TerminalInfo.Height + 1
-			ScrNeedsFix = false
+			e.VduClearScr()
+			e.ScrMsgRow = e.TerminalInfo.Height + 1
+			e.ScrNeedsFix = false
 		}
 
-		newRow := frame.ScrDotLine
+		newRow := min(frame.ScrDotLine, e.TerminalInfo.Height)
 		var lineNr int
 		LineToNumber(line, &lineNr)
 		eopLineNr := frame.LastGroup.FirstLineNr + frame.LastGroup.NrLines - 1
-		if (eopLineNr - lineNr) < (TerminalInfo.Height - newRow) {
-			newRow = TerminalInfo.Height - (eopLineNr - lineNr)
+		if (eopLineNr - lineNr) < (e.TerminalInfo.Height - newRow) {
+			newRow = e.TerminalInfo.Height - (eopLineNr - lineNr)
 		}
 		if lineNr < newRow {
 			newRow = lineNr
@@ -696,11 +815,18 @@ func ScreenLoad(line *LineHdrObject) {
 
 		// Move left or right in 1/2 window chunks until DOT on screen
 		dotCol := frame.Dot.Col
-		for dotCol <= frame.ScrOffset || dotCol > frame.ScrOffset+frame.ScrWidth {
-			halfWidth := frame.ScrWidth / 2
-			if halfWidth == 0 {
-				halfWidth = 1
-			}
+		halfWidth := frame.ScrWidth / 2
+		if halfWidth == 0 {
+			halfWidth = 1
+		}
+		// Take most of the chunks at once when DOT is far off the screen
+		if dotCol <= frame.ScrOffset {
+			frame.ScrOffset -= (frame.ScrOffset - dotCol) / halfWidth * halfWidth
+		} else if dotCol-frame.ScrOffset > 2*frame.ScrWidth {
+			frame.ScrOffset += (dotCol - frame.ScrOffset - 2*frame.ScrWidth) / halfWidth * halfWidth
+		}
+		for dotCol <= frame.ScrOffset ||
+			screenColumn(frame.Dot.Line, dotCol, frame.ScrOffset) > frame.ScrWidth {
 			if dotCol <= frame.ScrOffset {
 				if frame.ScrOffset > halfWidth {
 					frame.ScrOffset -= halfWidth
@@ -715,35 +841,35 @@ func ScreenLoad(line *LineHdrObject) {
 		}
 
 		// Load the screen
-		ScrFrame = frame
-		ScrBotLine = line
-		ScrTopLine = line
-		ScreenDrawLine(line)
-		screenExpand(true, true)
+		e.ScrFrame = frame
+		e.ScrBotLine = line
+		e.ScrTopLine = line
+		e.ScreenDrawLine(line)
+		e.screenExpand(true, true)
 	}
 }
 
 // ScreenPosition positions the screen and cursor
-func ScreenPosition(newLine *LineHdrObject, newCol int) {
-	if newLine.Group.Frame != ScrFrame {
-		ScreenLoad(newLine)
+func (e *Editor) ScreenPosition(newLine *LineHdrObject, newCol int) {
+	if newLine.Group.Frame != e.ScrFrame {
+		e.ScreenLoad(newLine)
 		return
 	}
 
-	offset := ScrFrame.ScrOffset
-	width := ScrFrame.ScrWidth
-	topMargin := ScrFrame.MarginTop
-	botMargin := ScrFrame.MarginBottom
+	offset := e.ScrFrame.ScrOffset
+	width := e.ScrFrame.ScrWidth
+	topMargin := e.ScrFrame.MarginTop
+	botMargin := e.ScrFrame.MarginBottom
 
 	// Check if position is already on screen between margins
 	if newLine.ScrRowNr == 0 ||
-		(newLine.ScrRowNr-ScrTopLine.ScrRowNr < topMargin && ScrTopLine.BLink != nil) ||
-		(ScrBotLine.ScrRowNr-newLine.ScrRowNr < botMargin && ScrBotLine.FLink != nil) ||
-		newCol <= offset || newCol > offset+width {
+		(newLine.ScrRowNr-e.ScrTopLine.ScrRowNr < topMargin && e.ScrTopLine.BLink != nil) ||
+		(e.ScrBotLine.ScrRowNr-newLine.ScrRowNr < botMargin && e.ScrBotLine.FLink != nil) ||
+		newCol <= offset || screenColumn(newLine, newCol, offset) > width {
 
-		height := ScrFrame.ScrHeight
-		botLine := ScrBotLine
-		topLine := ScrTopLine
+		height := e.ScrFrame.ScrHeight
+		botLine := e.ScrBotLine
+		topLine := e.ScrTopLine
 
 		// Compute horizontal adjusting needed
 		slideState := slideDont
@@ -756,9 +882,9 @@ func ScreenPosition(newLine *LineHdrObject, newCol int) {
 			}
 		} else {
 			slideDist = newCol - (offset + width)
-			if slideDist > 0 {
+			if slideDist > 0 || screenColumn(newLine, newCol, offset) > width {
 				slideState = slideRedraw
-				if offset > MaxStrLenP-width/4 {
+				if slideDist > 0 && offset > MaxStrLenP-width/4 {
 					slideState = slideRight
 					slideDist = MaxStrLenP - (offset + width)
 				}
@@ -770,8 +896,8 @@ func ScreenPosition(newLine *LineHdrObject, newCol int) {
 		var scrollDist int
 		if slideState != slideRedraw &&
 			(newLine.ScrRowNr == 0 ||
-				(newLine.ScrRowNr-ScrTopLine.ScrRowNr < topMargin && ScrTopLine.BLink != nil) ||
-				(ScrBotLine.ScrRowNr-newLine.ScrRowNr < botMargin && ScrBotLine.FLink != nil)) {
+				(newLine.ScrRowNr-e.ScrTopLine.ScrRowNr < topMargin && e.ScrTopLine.BLink != nil) ||
+				(e.ScrBotLine.ScrRowNr-newLine.ScrRowNr < botMargin && e.ScrBotLine.FLink != nil)) {
 
 			var botLineNr, newLineNr, topLineNr int
 			LineToNumber(botLine, &botLineNr)
@@ -800,14 +926,14 @@ func ScreenPosition(newLine *LineHdrObject, newCol int) {
 
 		// Execute the scroll and slide operations
 		if scrollState == scrollRedraw || slideState == slideRedraw {
-			ScreenLoad(newLine)
+			e.ScreenLoad(newLine)
 		} else {
 			if slideState != slideDont {
 				// Adjust the screen offset
 				if slideState == slideLeft {
-					ScrFrame.ScrOffset -= slideDist
+					e.ScrFrame.ScrOffset -= slideDist
 				} else {
-					ScrFrame.ScrOffset += slideDist
+					e.ScrFrame.ScrOffset += slideDist
 				}
 
 				line := topLine
@@ -817,7 +943,7 @@ func ScreenPosition(newLine *LineHdrObject, newCol int) {
 						scrollDist = 0
 					}
 					// Predict which lines will be left on screen
-					nrRows := TerminalInfo.Height - botLine.ScrRowNr
+					nrRows := e.TerminalInfo.Height - botLine.ScrRowNr
 					var rowNr int
 					if nrRows >= scrollDist {
 						rowNr = 0
@@ -827,7 +953,7 @@ func ScreenPosition(newLine *LineHdrObject, newCol int) {
 					// Adjust lines that will be left on screen
 					for line != nil {
 						if line.ScrRowNr > rowNr {
-							screenSlideLine(line, slideDist, slideState)
+							e.screenSlideLine(line, slideDist, slideState)
 						}
 						if line != botLine {
 							line = line.FLink
@@ -841,14 +967,14 @@ func ScreenPosition(newLine *LineHdrObject, newCol int) {
 					nrRows := topLine.ScrRowNr - 1
 					var rowNr int
 					if nrRows < scrollDist {
-						rowNr = TerminalInfo.Height
+						rowNr = e.TerminalInfo.Height
 					} else {
-						rowNr = TerminalInfo.Height - (scrollDist - nrRows)
+						rowNr = e.TerminalInfo.Height - (scrollDist - nrRows)
 					}
 					// Adjust lines that will be left on screen
 					for line != nil {
 						if line.ScrRowNr <= rowNr {
-							screenSlideLine(line, slideDist, slideState)
+							e.screenSlideLine(line, slideDist, slideState)
 						}
 						if line != topLine {
 							line = line.BLink
@@ -861,34 +987,34 @@ func ScreenPosition(newLine *LineHdrObject, newCol int) {
 
 			switch scrollState {
 			case scrollForward:
-				ScreenScroll(scrollDist, true)
+				e.ScreenScroll(scrollDist, true)
 			case scrollBack:
-				ScreenScroll(-scrollDist, true)
+				e.ScreenScroll(-scrollDist, true)
 			}
 		}
 	}
-	screenExpand(true, true)
+	e.screenExpand(true, true)
 }
 
 // ScreenPause waits until user types RETURN
-func ScreenPause() {
-	if LudwigMode == LudwigScreen {
-		if ScrFrame != nil {
-			VduMoveCurs(1, 1)
+func (e *Editor) ScreenPause() {
+	if e.LudwigMode == LudwigScreen {
+		if e.ScrFrame != nil {
+			e.VduMoveCurs(1, 1)
 		} else {
-			VduDisplayCrLf()
+			e.VduDisplayCrLf()
 		}
 		var buffer *StrObject
 		var outlen int
-		VduGetInput(PAUSE_MSG, &buffer, MaxStrLen, &outlen)
-		if ScrTopLine != nil {
-			if ScrTopLine.ScrRowNr == 1 {
-				ScreenDrawLine(ScrTopLine)
+		e.VduGetInput(PAUSE_MSG, &buffer, MaxStrLen, &outlen)
+		if e.ScrTopLine != nil {
+			if e.ScrTopLine.ScrRowNr == 1 {
+				e.ScreenDrawLine(e.ScrTopLine)
 			} else {
-				VduMoveCurs(1, 1)
-				VduClearEOL()
-				if ScrTopLine.ScrRowNr == 2 {
-					ScrNeedsFix = true
+				e.VduMoveCurs(1, 1)
+				e.VduClearEOL()
+				if e.ScrTopLine.ScrRowNr == 2 {
+					e.ScrNeedsFix = true
 				}
 			}
 		}
@@ -896,124 +1022,153 @@ func ScreenPause() {
 }
 
 // ScreenClearMsgs clears any messages off the screen
-func ScreenClearMsgs(pause bool) {
-	if ScrMsgRow <= TerminalInfo.Height {
+func (e *Editor) ScreenClearMsgs(pause bool) {
+	if e.ScrMsgRow <= e.TerminalInfo.Height {
 		if pause {
-			ScreenPause()
+			e.ScreenPause()
 		}
-		if ScrFrame == nil {
-			VduClearScr()
+		if e.ScrFrame == nil {
+			e.VduClearScr()
 		} else {
-			VduMoveCurs(1, ScrMsgRow)
-			VduClearEOS()
+			e.VduMoveCurs(1, e.ScrMsgRow)
+			e.VduClearEOS()
 		}
-		ScrMsgRow = TerminalInfo.Height + 1
+		e.ScrMsgRow = e.TerminalInfo.Height + 1
 	}
 }
 
 // changeFrameSize changes frame size based on terminal
-func changeFrameSize(frm *FrameObject, band int, halfScreen int) {
-	if frm.ScrHeight == InitialScrHeight || frm.ScrHeight > TerminalInfo.Height {
-		frm.ScrHeight = TerminalInfo.Height
+func (e *Editor) changeFrameSize(frm *FrameObject, band int, halfScreen int) {
+	if frm.ScrHeight == e.InitialScrHeight || frm.ScrHeight > e.TerminalInfo.Height {
+		frm.ScrHeight = e.TerminalInfo.Height
 	}
-	if frm.ScrWidth == InitialScrWidth || frm.ScrWidth > TerminalInfo.Width {
-		frm.ScrWidth = TerminalInfo.Width
+	if frm.ScrWidth == e.InitialScrWidth || frm.ScrWidth > e.TerminalInfo.Width {
+		frm.ScrWidth = e.TerminalInfo.Width
 	}
-	if frm.MarginTop == InitialMarginTop || frm.MarginTop >= halfScreen {
+	if frm.MarginTop == e.InitialMarginTop || frm.MarginTop >= halfScreen {
 		frm.MarginTop = band
 	}
-	if frm.MarginBottom == InitialMarginBottom || frm.MarginBottom >= halfScreen {
+	if frm.MarginBottom == e.InitialMarginBottom || frm.MarginBottom >= halfScreen {
 		frm.MarginBottom = band
 	}
-	if frm.MarginLeft > TerminalInfo.Width {
-		frm.MarginLeft = 1
-	}
-	if frm.MarginRight == InitialMarginRight || frm.MarginRight > TerminalInfo.Width {
-		frm.MarginRight = TerminalInfo.Width
+}
+
+// screenSetSize sets the size of the screen that frames are mapped onto,
+// which is the terminal or the current window.  Frames that were the size
+// of the old screen take on the size of the new one.
+func (e *Editor) screenSetSize(width int, height int) {
+	e.TerminalInfo.Width = width
+	e.TerminalInfo.Height = height
+
+	band := e.TerminalInfo.Height / 6
+	halfScreen := e.TerminalInfo.Height / 2
+	nextSpan := e.FirstSpan
+	for nextSpan != nil {
+		nextFrame := nextSpan.Frame
+		if nextFrame != nil {
+			e.changeFrameSize(nextFrame, band, halfScreen)
+		}
+		nextSpan = nextSpan.FLink
 	}
+
+	e.InitialMarginBottom = band
+	e.InitialMarginTop = band
+	e.InitialScrWidth = e.TerminalInfo.Width
+	e.InitialScrHeight = e.TerminalInfo.Height
 }
 
 // ScreenResize handles screen resize
-func ScreenResize() {
-	TtWinChanged = false
-	VduGetNewDimensions(&TerminalInfo.Width, &TerminalInfo.Height)
-	ScrMsgRow = TerminalInfo.Height + 1
-	VduClearScr()
-
-	band := TerminalInfo.Height / 6
-	halfScreen := TerminalInfo.Height / 2
-	nextSpan := FirstSpan
+func (e *Editor) ScreenResize() {
+	e.TtWinChanged.Store(false)
+	var width int
+	var height int
+	e.VduGetNewDimensions(&width, &height)
+	e.VduClearScr()
+
+	nextSpan := e.FirstSpan
 	for nextSpan != nil {
 		nextFrame := nextSpan.Frame
 		if nextFrame != nil {
-			changeFrameSize(nextFrame, band, halfScreen)
+			if nextFrame.MarginLeft > width {
+				nextFrame.MarginLeft = 1
+			}
+			if nextFrame.MarginRight == e.InitialMarginRight || nextFrame.MarginRight > width {
+				nextFrame.MarginRight = width
+			}
 		}
 		nextSpan = nextSpan.FLink
 	}
-
-	InitialMarginRight = TerminalInfo.Width
-	InitialMarginBottom = band
-	InitialMarginTop = band
-	InitialScrWidth = TerminalInfo.Width
-	InitialScrHeight = TerminalInfo.Height
-
-	ScreenLoad(CurrentFrame.Dot.Line)
-	ScrNeedsFix = false
-	screenExpand(true, true)
-	VduMoveCurs(
-		CurrentFrame.Dot.Col-CurrentFrame.ScrOffset,
-		CurrentFrame.Dot.Line.ScrRowNr,
+	e.InitialMarginRight = width
+
+	e.WindowResize(width, height)
+	e.ScrMsgRow = e.TerminalInfo.Height + 1
+
+	e.ScreenLoad(e.CurrentFrame.Dot.Line)
+	e.ScrNeedsFix = false
+	e.screenExpand(true, true)
+	e.WindowUpdate()
+	e.VduMoveCurs(
+		e.screenDotCol(),
+		e.CurrentFrame.Dot.Line.ScrRowNr,
 	)
 }
 
 // ScreenFixup makes sure the screen is correct
-func ScreenFixup() {
-	if TtWinChanged {
-		ScreenResize()
+func (e *Editor) ScreenFixup() {
+	if e.TtWinChanged.Load() {
+		e.ScreenResize()
 	} else {
-		if ScrFrame != CurrentFrame {
-			if ScrMsgRow <= TerminalInfo.Height {
-				ScreenClearMsgs(true)
+		if e.ScrFrame != e.CurrentFrame {
+			if e.ScrMsgRow <= e.TerminalInfo.Height {
+				e.ScreenClearMsgs(true)
 			}
-			ScreenLoad(CurrentFrame.Dot.Line)
+			e.ScreenLoad(e.CurrentFrame.Dot.Line)
 		} else {
-			needsReposition := CurrentFrame.Dot.Line.ScrRowNr == 0 ||
-				(CurrentFrame.Dot.Line.ScrRowNr-ScrTopLine.ScrRowNr < CurrentFrame.MarginTop &&
-					ScrTopLine.BLink != nil) ||
-				(ScrBotLine.ScrRowNr-CurrentFrame.Dot.Line.ScrRowNr < CurrentFrame.MarginBottom &&
-					ScrBotLine.FLink != nil) ||
-				CurrentFrame.Dot.Col <= CurrentFrame.ScrOffset ||
-				CurrentFrame.Dot.Col > CurrentFrame.ScrOffset+CurrentFrame.ScrWidth
+			needsReposition := e.CurrentFrame.Dot.Line.ScrRowNr == 0 ||
+				(e.CurrentFrame.Dot.Line.ScrRowNr-e.ScrTopLine.ScrRowNr < e.CurrentFrame.MarginTop &&
+					e.ScrTopLine.BLink != nil) ||
+				(e.ScrBotLine.ScrRowNr-e.CurrentFrame.Dot.Line.ScrRowNr < e.CurrentFrame.MarginBottom &&
+					e.ScrBotLine.FLink != nil) ||
+				e.CurrentFrame.Dot.Col <= e.CurrentFrame.ScrOffset ||
+				e.screenDotCol() > e.CurrentFrame.ScrWidth
 
 			if needsReposition {
-				if ScrMsgRow <= TerminalInfo.Height {
-					ScreenClearMsgs(true)
+				if e.ScrMsgRow <= e.TerminalInfo.Height {
+					e.ScreenClearMsgs(true)
 				}
-				ScreenPosition(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col)
-			} else if ScrMsgRow <= TerminalInfo.Height {
-				VduMoveCurs(
-					CurrentFrame.Dot.Col-CurrentFrame.ScrOffset,
-					CurrentFrame.Dot.Line.ScrRowNr,
+				e.ScreenPosition(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col)
+			} else if e.ScrMsgRow <= e.TerminalInfo.Height {
+				e.VduMoveCurs(
+					e.screenDotCol(),
+					e.CurrentFrame.Dot.Line.ScrRowNr,
 				)
-				key := VduGetKey()
-				ScreenClearMsgs(false)
-				if TtControlC {
+				key := e.VduGetKey()
+				e.ScreenClearMsgs(false)
+				if e.TtControlC.Load() {
 					return
 				}
-				VduTakeBackKey(key)
+				e.VduTakeBackKey(key)
 			}
 		}
-		ScrNeedsFix = false
-		screenExpand(true, true)
-		VduMoveCurs(
-			CurrentFrame.Dot.Col-CurrentFrame.ScrOffset,
-			CurrentFrame.Dot.Line.ScrRowNr,
+		e.ScrNeedsFix = false
+		e.screenExpand(true, true)
+		stale := e.MatchFixLines(e.ScrTopLine, e.ScrBotLine)
+		if stale == nil {
+			stale = e.SyntaxFixLines(e.ScrTopLine, e.ScrBotLine)
+		}
+		for _, line := range stale {
+			e.ScreenDrawLine(line)
+		}
+		e.WindowUpdate()
+		e.VduMoveCurs(
+			e.screenDotCol(),
+			e.CurrentFrame.Dot.Line.ScrRowNr,
 		)
 	}
 }
 
 // ScreenGetLineP gets a line from the user
-func ScreenGetLineP(
+func (e *Editor) ScreenGetLineP(
 	prompt string,
 	outbuf **StrObject,
 	outlen *int,
@@ -1024,74 +1179,74 @@ func ScreenGetLineP(
 	var tmpLine *LineHdrObject
 	maxTp = abs(maxTp)
 
-	if !TtControlC {
-		if LudwigMode == LudwigScreen {
-			PromptRegion[thisTp].LineNr = 0
-			PromptRegion[thisTp].Redraw = nil
+	if !e.TtControlC.Load() {
+		if e.LudwigMode == LudwigScreen {
+			e.PromptRegion[thisTp].LineNr = 0
+			e.PromptRegion[thisTp].Redraw = nil
 
-			if ScrTopLine == nil {
+			if e.ScrTopLine == nil {
 				goto l1
 			}
-			ScreenFixup()
-			PromptRegion[thisTp].LineNr = thisTp
-			if ScrTopLine.ScrRowNr > maxTp {
+			e.ScreenFixup()
+			e.PromptRegion[thisTp].LineNr = thisTp
+			if e.ScrTopLine.ScrRowNr > maxTp {
 				goto l1
 			}
-			if ScrBotLine.ScrRowNr < ScrMsgRow-maxTp {
-				PromptRegion[thisTp].LineNr = ScrMsgRow - maxTp + thisTp - 1
+			if e.ScrBotLine.ScrRowNr < e.ScrMsgRow-maxTp {
+				e.PromptRegion[thisTp].LineNr = e.ScrMsgRow - maxTp + thisTp - 1
 				goto l1
 			}
-			tmpLine = ScrTopLine
-			for index := ScrTopLine.ScrRowNr; index <= thisTp-1; index++ {
+			tmpLine = e.ScrTopLine
+			for index := e.ScrTopLine.ScrRowNr; index <= thisTp-1; index++ {
 				tmpLine = tmpLine.FLink
 			}
-			PromptRegion[thisTp].Redraw = tmpLine
-			if ScrFrame.Dot.Line.ScrRowNr > 2 {
+			e.PromptRegion[thisTp].Redraw = tmpLine
+			if e.ScrFrame.Dot.Line.ScrRowNr > 2 {
 				goto l1
 			}
 
-			tmpLine = ScrBotLine
-			for index := TerminalInfo.Height - ScrBotLine.ScrRowNr; index <= maxTp-thisTp-1; index++ {
+			tmpLine = e.ScrBotLine
+			for index := e.TerminalInfo.Height - e.ScrBotLine.ScrRowNr; index <= maxTp-thisTp-1; index++ {
 				tmpLine = tmpLine.BLink
 			}
-			if TerminalInfo.Height-ScrBotLine.ScrRowNr > maxTp-thisTp {
+			if e.TerminalInfo.Height-e.ScrBotLine.ScrRowNr > maxTp-thisTp {
 				tmpLine = nil
 			}
-			PromptRegion[thisTp].Redraw = tmpLine
-			PromptRegion[thisTp].LineNr = TerminalInfo.Height - maxTp + thisTp
+			e.PromptRegion[thisTp].Redraw = tmpLine
+			e.PromptRegion[thisTp].LineNr = e.TerminalInfo.Height - maxTp + thisTp
 
 		l1:
-			if PromptRegion[thisTp].LineNr != 0 {
-				VduMoveCurs(1, PromptRegion[thisTp].LineNr)
+			if e.PromptRegion[thisTp].LineNr != 0 {
+				e.VduMoveCurs(1, e.PromptRegion[thisTp].LineNr)
 			}
-			VduGetInput(prompt, outbuf, MaxStrLen, outlen)
-			if TtControlC {
+			e.VduGetInput(prompt, outbuf, MaxStrLen, outlen)
+			if e.TtControlC.Load() {
 				goto l2
 			}
 			if *outlen == 0 {
 				for index := thisTp + 1; index <= maxTp; index++ {
-					PromptRegion[index].LineNr = 0
-					PromptRegion[index].Redraw = nil
+					e.PromptRegion[index].LineNr = 0
+					e.PromptRegion[index].Redraw = nil
 				}
 			}
 			if thisTp == maxTp || *outlen == 0 {
 				for index := 1; index <= maxTp; index++ {
-					if PromptRegion[index].Redraw != nil {
-						ScreenDrawLine(PromptRegion[index].Redraw)
-					} else if PromptRegion[index].LineNr != 0 {
-						VduMoveCurs(1, PromptRegion[index].LineNr)
-						VduClearEOL()
+					if e.PromptRegion[index].Redraw != nil {
+						e.ScreenDrawLine(e.PromptRegion[index].Redraw)
+					} else if e.PromptRegion[index].LineNr != 0 {
+						e.VduMoveCurs(1, e.PromptRegion[index].LineNr)
+						e.VduClearEOL()
 					}
 				}
 			}
 		} else {
-			fmt.Print(prompt)
+			fmt.Fprint(e.Output, prompt)
 			// Read from stdin (simplified version)
 			*outlen = 0
 		}
 	}
 l2:
-	if TtControlC {
+	if e.TtControlC.Load() {
 		*outlen = 0
 	}
 }
@@ -1105,68 +1260,68 @@ func abs(x int) int {
 }
 
 // ScreenFreeBottomLine frees the bottom line of the screen
-func ScreenFreeBottomLine() {
+func (e *Editor) ScreenFreeBottomLine() {
 	// This routine assumes that the editor is in SCREEN mode.
 	// This routine frees the bottom line of the screen for use by the caller.
 	// The main use of the area is the outputting of messages.
-	if ScrFrame == nil {
+	if e.ScrFrame == nil {
 		// IF SCREEN NOT MAPPED.
-		VduDisplayCrLf()
-		VduDeleteLines(1)
+		e.VduDisplayCrLf()
+		e.VduDeleteLines(1)
 		return
 	}
-	ScrNeedsFix = true
+	e.ScrNeedsFix = true
 	// IF BOTTOM LINE FREE.
-	if (ScrMsgRow > TerminalInfo.Height) && (ScrBotLine.ScrRowNr < TerminalInfo.Height) {
+	if (e.ScrMsgRow > e.TerminalInfo.Height) && (e.ScrBotLine.ScrRowNr < e.TerminalInfo.Height) {
 		// Nothing
-	} else if ScrBotLine.ScrRowNr+2 < ScrMsgRow {
+	} else if e.ScrBotLine.ScrRowNr+2 < e.ScrMsgRow {
 		// IF ROOM BELOW BOT LINE.
 		// +2 because of <eos> line.
-		VduMoveCurs(1, ScrBotLine.ScrRowNr+2)
-		VduDeleteLines(1)
-	} else if ScrTopLine.ScrRowNr != 1 {
+		e.VduMoveCurs(1, e.ScrBotLine.ScrRowNr+2)
+		e.VduDeleteLines(1)
+	} else if e.ScrTopLine.ScrRowNr != 1 {
 		// IF TOP LINE FREE.
-		ScreenScroll(1, false)
+		e.ScreenScroll(1, false)
 	} else {
-		if ScrBotLine.ScrRowNr+1 < ScrMsgRow {
+		if e.ScrBotLine.ScrRowNr+1 < e.ScrMsgRow {
 			// IF ROOM FOR MORE MSGS.
-			VduMoveCurs(1, ScrBotLine.ScrRowNr+1)
-			VduDeleteLines(1)
-		} else if (ScrFrame.Dot.Line != ScrTopLine) &&
-			!((ScrFrame.Dot.Line != ScrBotLine) &&
-				(ScrBotLine.ScrRowNr == TerminalInfo.Height)) {
+			e.VduMoveCurs(1, e.ScrBotLine.ScrRowNr+1)
+			e.VduDeleteLines(1)
+		} else if (e.ScrFrame.Dot.Line != e.ScrTopLine) &&
+			!((e.ScrFrame.Dot.Line != e.ScrBotLine) &&
+				(e.ScrBotLine.ScrRowNr == e.TerminalInfo.Height)) {
 			// IF DOT NOT ON TOP LINE,
 			// AND WE CANT USE THE BOT.
-			ScreenScroll(1, false)
-		} else if ScrMsgRow <= TerminalInfo.Height/2 {
+			e.ScreenScroll(1, false)
+		} else if e.ScrMsgRow <= e.TerminalInfo.Height/2 {
 			// 1/2 SCREEN ALREADY MSGS.
-			VduMoveCurs(1, ScrMsgRow)
-			VduDeleteLines(1)
+			e.VduMoveCurs(1, e.ScrMsgRow)
+			e.VduDeleteLines(1)
 			return
 		} else {
 			// CONTRACT SCREEN 1 LINE.
-			ScrBotLine.ScrRowNr = 0
-			ScrBotLine = ScrBotLine.BLink
-			VduMoveCurs(1, ScrMsgRow-1)
-			VduDeleteLines(1)
+			e.ScrBotLine.ScrRowNr = 0
+			e.ScrBotLine = e.ScrBotLine.BLink
+			e.VduMoveCurs(1, e.ScrMsgRow-1)
+			e.VduDeleteLines(1)
 		}
 	}
-	ScrMsgRow -= 1
+	e.ScrMsgRow -= 1
 }
 
 // ScreenVerify gets verification from user
-func ScreenVerify(prompt string) VerifyResponse {
+func (e *Editor) ScreenVerify(prompt string) VerifyResponse {
 	const verHeight = 4
 
 	verify := VerifyReplyQuit
 
-	oldHeight := CurrentFrame.ScrHeight
-	oldTopM := CurrentFrame.MarginTop
-	oldBotM := CurrentFrame.MarginBottom
+	oldHeight := e.CurrentFrame.ScrHeight
+	oldTopM := e.CurrentFrame.MarginTop
+	oldBotM := e.CurrentFrame.MarginBottom
 	if oldHeight > verHeight {
-		CurrentFrame.MarginTop = verHeight / 2
-		CurrentFrame.ScrHeight = verHeight
-		CurrentFrame.MarginBottom = verHeight / 2
+		e.CurrentFrame.MarginTop = verHeight / 2
+		e.CurrentFrame.ScrHeight = verHeight
+		e.CurrentFrame.MarginBottom = verHeight / 2
 	}
 
 	usePrompt := true
@@ -1174,36 +1329,36 @@ func ScreenVerify(prompt string) VerifyResponse {
 	var more bool
 
 	for {
-		switch LudwigMode {
+		switch e.LudwigMode {
 		case LudwigScreen:
-			ScreenFixup()
-			VduBold()
+			e.ScreenFixup()
+			e.VduBold()
 			if usePrompt {
-				ScreenMessage(prompt)
+				e.ScreenMessage(prompt)
 			} else {
-				ScreenMessage(YNAQM_MSG)
+				e.ScreenMessage(YNAQM_MSG)
 			}
-			VduNormal()
-			VduMoveCurs(
-				CurrentFrame.Dot.Col-CurrentFrame.ScrOffset,
-				CurrentFrame.Dot.Line.ScrRowNr,
+			e.VduNormal()
+			e.VduMoveCurs(
+				e.screenDotCol(),
+				e.CurrentFrame.Dot.Line.ScrRowNr,
 			)
-			key = VduGetKey()
+			key = e.VduGetKey()
 			if key >= 'a' && key <= 'z' {
 				key = key - 'a' + 'A'
 			}
 			if key == 13 {
 				key = 'N' // RETURN <=> NO
 			}
-			ScreenClearMsgs(false)
+			e.ScreenClearMsgs(false)
 
 		case LudwigBatch, LudwigHardcopy:
 			var response *StrObject
 			var respLen int
 			if usePrompt {
-				ScreenGetLineP(prompt, &response, &respLen, 1, 1)
+				e.ScreenGetLineP(prompt, &response, &respLen, 1, 1)
 			} else {
-				ScreenGetLineP(YNAQM_MSG, &response, &respLen, 1, 1)
+				e.ScreenGetLineP(YNAQM_MSG, &response, &respLen, 1, 1)
 			}
 			if respLen == 0 {
 				key = 'N'
@@ -1217,7 +1372,7 @@ func ScreenVerify(prompt string) VerifyResponse {
 			}
 		}
 
-		if TtControlC {
+		if e.TtControlC.Load() {
 			goto l99
 		}
 
@@ -1234,27 +1389,27 @@ func ScreenVerify(prompt string) VerifyResponse {
 				verify = VerifyReplyQuit
 			case '1', '2', '3', '4', '5', '6', '7', '8', '9', 'M':
 				// MORE CONTEXT PLEASE
-				if ScrTopLine != nil {
-					CurrentFrame.ScrHeight = ScrBotLine.ScrRowNr + 1 - ScrTopLine.ScrRowNr
+				if e.ScrTopLine != nil {
+					e.CurrentFrame.ScrHeight = e.ScrBotLine.ScrRowNr + 1 - e.ScrTopLine.ScrRowNr
 				}
 				if key == 'M' {
 					key = '1'
 				}
-				if key-'0'+CurrentFrame.ScrHeight < TerminalInfo.Height {
-					CurrentFrame.ScrHeight += key - '0'
+				if key-'0'+e.CurrentFrame.ScrHeight < e.TerminalInfo.Height {
+					e.CurrentFrame.ScrHeight += key - '0'
 				} else {
-					CurrentFrame.ScrHeight = TerminalInfo.Height
+					e.CurrentFrame.ScrHeight = e.TerminalInfo.Height
 				}
-				if ScrTopLine == nil {
-					ScreenLoad(CurrentFrame.Dot.Line)
+				if e.ScrTopLine == nil {
+					e.ScreenLoad(e.CurrentFrame.Dot.Line)
 				} else {
-					screenExpand(true, true)
+					e.screenExpand(true, true)
 				}
 				more = true
 				usePrompt = true
 			}
 		} else {
-			ScreenBeep()
+			e.ScreenBeep()
 			more = true
 			usePrompt = false
 		}
@@ -1265,166 +1420,166 @@ func ScreenVerify(prompt string) VerifyResponse {
 	}
 
 l99:
-	CurrentFrame.ScrHeight = oldHeight
-	CurrentFrame.MarginTop = oldTopM
-	CurrentFrame.MarginBottom = oldBotM
+	e.CurrentFrame.ScrHeight = oldHeight
+	e.CurrentFrame.MarginTop = oldTopM
+	e.CurrentFrame.MarginBottom = oldBotM
 
 	if verify == VerifyReplyQuit {
-		ExitAbort = true
+		e.ExitAbort = true
 	}
 	return verify
 }
 
 // ScreenBeep produces a beep
-func ScreenBeep() {
-	if LudwigMode == LudwigScreen {
-		VduBeep()
+func (e *Editor) ScreenBeep() {
+	if e.LudwigMode == LudwigScreen {
+		e.VduBeep()
 	}
 }
 
 // ScreenHome moves cursor to home position
-func ScreenHome(clear bool) {
-	if LudwigMode == LudwigScreen {
-		VduMoveCurs(1, 1)
+func (e *Editor) ScreenHome(clear bool) {
+	if e.LudwigMode == LudwigScreen {
+		e.VduMoveCurs(1, 1)
 		if clear {
-			VduClearScr()
+			e.VduClearScr()
 		}
-		VduFlush()
+		e.VduFlush()
 	} else {
-		fmt.Println("")
-		fmt.Println("")
+		fmt.Fprintln(e.Output)
+		fmt.Fprintln(e.Output)
 	}
 }
 
 // ScreenWriteInt writes an integer to screen
-func ScreenWriteInt(intVal int, width int) {
-	if LudwigMode == LudwigScreen {
+func (e *Editor) ScreenWriteInt(intVal int, width int) {
+	if e.LudwigMode == LudwigScreen {
 		str := fmt.Sprintf("%*d", width, intVal)
-		VduDisplayStr(str, 0)
+		e.VduDisplayStr(str, 0)
 	} else {
-		fmt.Printf("%d", intVal)
+		fmt.Fprintf(e.Output, "%d", intVal)
 	}
 }
 
 // ScreenWriteCh writes a character with indent
-func ScreenWriteCh(indent int, ch byte) {
-	if LudwigMode == LudwigScreen {
-		VduDisplayStr(spc(indent)+string(ch), 0)
+func (e *Editor) ScreenWriteCh(indent int, ch byte) {
+	if e.LudwigMode == LudwigScreen {
+		e.VduDisplayStr(spc(indent)+string(ch), 0)
 	} else {
-		fmt.Print(spc(indent) + string(ch))
+		fmt.Fprint(e.Output, spc(indent)+string(ch))
 	}
 }
 
 // ScreenWriteStr writes a string with indent
-func ScreenWriteStr(indent int, str string) {
-	if LudwigMode == LudwigScreen {
-		VduDisplayStr(spc(indent)+str, 3)
-		VduFlush()
+func (e *Editor) ScreenWriteStr(indent int, str string) {
+	if e.LudwigMode == LudwigScreen {
+		e.VduDisplayStr(spc(indent)+str, 3)
+		e.VduFlush()
 	} else {
-		fmt.Print(spc(indent) + str)
+		fmt.Fprint(e.Output, spc(indent)+str)
 	}
 }
 
 // ScreenWriteStrWidth writes a string with indent and width
-func ScreenWriteStrWidth(indent int, str string, width int) {
-	if LudwigMode == LudwigScreen {
+func (e *Editor) ScreenWriteStrWidth(indent int, str string, width int) {
+	if e.LudwigMode == LudwigScreen {
 		strLen := min(len(str), width)
 		trailingSpaces := width - strLen
-		VduDisplayStr(spc(indent)+str[:strLen]+spc(trailingSpaces), 3)
+		e.VduDisplayStr(spc(indent)+str[:strLen]+spc(trailingSpaces), 3)
 	} else {
-		fmt.Print(spc(indent) + str)
+		fmt.Fprint(e.Output, spc(indent)+str)
 	}
 }
 
 // ScreenWriteNameStr writes a name string with indent and width
-func ScreenWriteNameStr(indent int, str string, width int) {
+func (e *Editor) ScreenWriteNameStr(indent int, str string, width int) {
 	strLen := min(len(str), width)
 	trailingSpaces := width - strLen
-	if LudwigMode == LudwigScreen {
-		VduDisplayStr(spc(indent)+str[:strLen]+spc(trailingSpaces), 3)
+	if e.LudwigMode == LudwigScreen {
+		e.VduDisplayStr(spc(indent)+str[:strLen]+spc(trailingSpaces), 3)
 	} else {
-		fmt.Print(spc(indent))
-		fmt.Print(str[:strLen])
-		fmt.Print(spc(trailingSpaces))
+		fmt.Fprint(e.Output, spc(indent))
+		fmt.Fprint(e.Output, str[:strLen])
+		fmt.Fprint(e.Output, spc(trailingSpaces))
 	}
 }
 
 // ScreenWriteFileNameStr writes a file name with indent and width
-func ScreenWriteFileNameStr(indent int, str string, width int) {
-	if LudwigMode == LudwigScreen {
+func (e *Editor) ScreenWriteFileNameStr(indent int, str string, width int) {
+	if e.LudwigMode == LudwigScreen {
 		for range indent {
-			VduDisplayCh(' ')
+			e.VduDisplayCh(' ')
 		}
 		for i := range width {
 			if i < len(str) && str[i] >= 32 && str[i] <= 126 {
-				VduDisplayCh(str[i])
+				e.VduDisplayCh(rune(str[i]))
 			} else {
-				VduDisplayCh(' ')
+				e.VduDisplayCh(' ')
 			}
 		}
 	} else {
-		fmt.Print(spc(indent))
+		fmt.Fprint(e.Output, spc(indent))
 		for i := 0; i < width; i++ {
 			if i < len(str) {
-				fmt.Print(string(str[i]))
+				fmt.Fprint(e.Output, string(str[i]))
 			} else {
-				fmt.Print(" ")
+				fmt.Fprint(e.Output, " ")
 			}
 		}
 	}
 }
 
 // ScreenWriteln writes a newline
-func ScreenWriteln() {
-	if LudwigMode == LudwigScreen {
-		VduDisplayCrLf()
+func (e *Editor) ScreenWriteln() {
+	if e.LudwigMode == LudwigScreen {
+		e.VduDisplayCrLf()
 	} else {
-		fmt.Println("")
+		fmt.Fprintln(e.Output)
 	}
 }
 
 // ScreenWritelnClel writes newline and clears to end of line
-func ScreenWritelnClel() {
-	if LudwigMode == LudwigScreen {
-		VduClearEOL()
-		VduDisplayCrLf()
+func (e *Editor) ScreenWritelnClel() {
+	if e.LudwigMode == LudwigScreen {
+		e.VduClearEOL()
+		e.VduDisplayCrLf()
 	} else {
-		fmt.Println("")
+		fmt.Fprintln(e.Output)
 	}
 }
 
 // ScreenHelpPrompt displays help prompt
-func ScreenHelpPrompt(prompt string) string {
+func (e *Editor) ScreenHelpPrompt(prompt string) string {
 	var reply string
 
-	switch LudwigMode {
+	switch e.LudwigMode {
 	case LudwigScreen, LudwigHardcopy:
-		if LudwigMode == LudwigScreen {
-			VduBold()
+		if e.LudwigMode == LudwigScreen {
+			e.VduBold()
 		}
-		ScreenWriteStr(0, prompt)
-		if LudwigMode == LudwigScreen {
-			VduNormal()
+		e.ScreenWriteStr(0, prompt)
+		if e.LudwigMode == LudwigScreen {
+			e.VduNormal()
 		}
 		terminated := false
 		for !terminated {
-			key := VduGetKey()
+			key := e.VduGetKey()
 			if key == 13 {
 				terminated = true
 			} else if key == 127 {
 				if len(reply) > 0 {
 					reply = reply[:len(reply)-1]
-					VduDisplayCh(8)
-					VduDisplayCh(' ')
-					VduDisplayCh(8)
+					e.VduDisplayCh(8)
+					e.VduDisplayCh(' ')
+					e.VduDisplayCh(8)
 				}
-			} else if ChIsPrintable(rune(key)) {
-				VduDisplayCh(byte(key))
-				reply += string(byte(key))
+			} else if key < DEL && ChIsPrintableKey(key) {
+				e.VduDisplayCh(rune(key))
+				reply += string(rune(key))
 				terminated = (key == ' ') || len(reply) == KeyLen
 			}
 		}
-		ScreenWriteln()
+		e.ScreenWriteln()
 
 	case LudwigBatch:
 		reply = ""
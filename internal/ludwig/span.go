@@ -17,9 +17,9 @@ package ludwig
 // SpanFind finds a span of the specified name.
 // Returns a pointer to the entry in the span table if found.
 // If the span is a frame then reset the frame's two marks.
-func SpanFind(spanName string, ptr **SpanObject, oldp **SpanObject) bool {
+func (e *Editor) SpanFind(spanName string, ptr **SpanObject, oldp **SpanObject) bool {
 	*oldp = nil
-	*ptr = FirstSpan
+	*ptr = e.FirstSpan
 	if *ptr == nil {
 		return false
 	}
@@ -45,21 +45,21 @@ func SpanFind(spanName string, ptr **SpanObject, oldp **SpanObject) bool {
 // SpanCreate creates a span of the specified name over the specified range of lines.
 // Checks first that a span of this name doesn't already exist. If it does, it re-defines it.
 // Fails if span already exists and is a frame.
-func SpanCreate(spanName string, firstMark *MarkObject, lastMark *MarkObject) bool {
+func (e *Editor) SpanCreate(spanName string, firstMark *MarkObject, lastMark *MarkObject) bool {
 	var p *SpanObject
 	var ptr *SpanObject
 	var oldp *SpanObject
 	var mrk1 *MarkObject
 	var mrk2 *MarkObject
 
-	if SpanFind(spanName, &p, &oldp) {
+	if e.SpanFind(spanName, &p, &oldp) {
 		if p.Frame != nil {
-			ScreenMessage(MsgFrameOfThatNameExists)
+			e.ScreenMessage(MsgFrameOfThatNameExists)
 			return false
 		}
 		ptr = p
 		if ptr.Code != nil {
-			CodeDiscard(&ptr.Code)
+			e.CodeDiscard(&ptr.Code)
 		}
 		mrk1 = ptr.MarkOne
 		mrk2 = ptr.MarkTwo
@@ -78,7 +78,7 @@ func SpanCreate(spanName string, firstMark *MarkObject, lastMark *MarkObject) bo
 		}
 		if oldp == nil {
 			ptr.BLink = nil
-			FirstSpan = ptr
+			e.FirstSpan = ptr
 		} else {
 			ptr.BLink = oldp
 			oldp.FLink = ptr
@@ -96,6 +96,7 @@ func SpanCreate(spanName string, firstMark *MarkObject, lastMark *MarkObject) bo
 	var lineNrLast int
 	if LineToNumber(mrk1.Line, &lineNrFirst) && LineToNumber(mrk2.Line, &lineNrLast) {
 		ptr.Frame = nil
+		ptr.Block = false
 		if (lineNrFirst < lineNrLast) ||
 			((lineNrFirst == lineNrLast) && (mrk1.Col < mrk2.Col)) {
 			// Marks are in the right order
@@ -113,18 +114,18 @@ func SpanCreate(spanName string, firstMark *MarkObject, lastMark *MarkObject) bo
 
 // SpanDestroy destroys the specified span.
 // Fails if span is a frame or if span is not destroyed.
-func SpanDestroy(span **SpanObject) bool {
+func (e *Editor) SpanDestroy(span **SpanObject) bool {
 	if (*span).Frame != nil {
-		ScreenMessage(MsgCantKillFrame)
+		e.ScreenMessage(MsgCantKillFrame)
 		return false
 	}
 	if (*span).Code != nil {
-		CodeDiscard(&(*span).Code)
+		e.CodeDiscard(&(*span).Code)
 	}
 	if (*span).BLink != nil {
 		(*span).BLink.FLink = (*span).FLink
 	} else {
-		FirstSpan = (*span).FLink
+		e.FirstSpan = (*span).FLink
 	}
 	if (*span).FLink != nil {
 		(*span).FLink.BLink = (*span).BLink
@@ -139,32 +140,32 @@ func SpanDestroy(span **SpanObject) bool {
 }
 
 // SpanIndex displays the list of spans. This is the \SI command.
-func SpanIndex() bool {
-	ScreenUnload()
-	ScreenHome(true)
-	p := FirstSpan
+func (e *Editor) SpanIndex() bool {
+	e.ScreenUnload()
+	e.ScreenHome(true)
+	p := e.FirstSpan
 	haveSpan := false
-	ScreenWriteln()
-	ScreenWriteStr(0, "Spans")
-	ScreenWriteln()
-	ScreenWriteStr(0, "=====")
-	ScreenWriteln()
+	e.ScreenWriteln()
+	e.ScreenWriteStr(0, "Spans")
+	e.ScreenWriteln()
+	e.ScreenWriteStr(0, "=====")
+	e.ScreenWriteln()
 	lineCount := 3
 	for p != nil {
 		if p.Frame == nil {
 			haveSpan = true
-			if lineCount > TerminalInfo.Height-2 {
-				ScreenPause()
-				ScreenHome(true)
-				ScreenWriteln()
-				ScreenWriteStr(0, "Spans")
-				ScreenWriteln()
-				ScreenWriteStr(0, "=====")
-				ScreenWriteln()
+			if lineCount > e.TerminalInfo.Height-2 {
+				e.ScreenPause()
+				e.ScreenHome(true)
+				e.ScreenWriteln()
+				e.ScreenWriteStr(0, "Spans")
+				e.ScreenWriteln()
+				e.ScreenWriteStr(0, "=====")
+				e.ScreenWriteln()
 				lineCount = 3
 			}
-			ScreenWriteNameStr(0, p.Name, NameLen)
-			ScreenWriteStr(0, " : ")
+			e.ScreenWriteNameStr(0, p.Name, NameLen)
+			e.ScreenWriteStr(0, " : ")
 			continu := p.MarkOne.Line != p.MarkTwo.Line
 			var spanStart string
 			if p.MarkOne.Col <= p.MarkOne.Line.Used {
@@ -180,36 +181,36 @@ func SpanIndex() bool {
 					spanStart = p.MarkOne.Line.Str.Slice(p.MarkOne.Col, p.MarkOne.Col+toCopy)
 				}
 			}
-			ScreenWriteNameStr(0, spanStart, NameLen)
+			e.ScreenWriteNameStr(0, spanStart, NameLen)
 			if continu {
-				ScreenWriteStr(1, "...")
+				e.ScreenWriteStr(1, "...")
 			}
-			ScreenWriteln()
+			e.ScreenWriteln()
 			lineCount++
 		}
 		p = p.FLink
 	}
 	if !haveSpan {
-		ScreenWriteStr(10, "<none>")
-		ScreenWriteln()
+		e.ScreenWriteStr(10, "<none>")
+		e.ScreenWriteln()
 		lineCount++
 	}
 	firstTime := true
 	oldCount := lineCount
-	p = FirstSpan
-	lineCount = TerminalInfo.Height
+	p = e.FirstSpan
+	lineCount = e.TerminalInfo.Height
 	for p != nil {
 		if p.Frame != nil {
-			if lineCount > TerminalInfo.Height-2 {
+			if lineCount > e.TerminalInfo.Height-2 {
 				if !firstTime {
-					ScreenPause()
-					ScreenHome(true)
+					e.ScreenPause()
+					e.ScreenHome(true)
 				}
-				ScreenWriteln()
-				ScreenWriteStr(0, "Frames")
-				ScreenWriteln()
-				ScreenWriteStr(0, "======")
-				ScreenWriteln()
+				e.ScreenWriteln()
+				e.ScreenWriteStr(0, "Frames")
+				e.ScreenWriteln()
+				e.ScreenWriteStr(0, "======")
+				e.ScreenWriteln()
 				if firstTime {
 					lineCount = oldCount + 3
 					firstTime = false
@@ -217,27 +218,78 @@ func SpanIndex() bool {
 					lineCount = 3
 				}
 			}
-			ScreenWriteNameStr(0, p.Name, NameLen)
-			ScreenWriteln()
+			e.ScreenWriteNameStr(0, p.Name, NameLen)
+			e.ScreenWriteln()
 			lineCount++
 			var fylNam string
 			if p.Frame.InputFile != 0 {
-				ScreenWriteStr(0, "  Input:  ")
-				FileName(Files[p.Frame.InputFile], 70, &fylNam)
-				ScreenWriteFileNameStr(0, fylNam, len(fylNam))
-				ScreenWriteln()
+				e.ScreenWriteStr(0, "  Input:  ")
+				FileName(e.Files[p.Frame.InputFile], 70, &fylNam)
+				e.ScreenWriteFileNameStr(0, fylNam, len(fylNam))
+				e.ScreenWriteln()
 				lineCount++
 			}
 			if p.Frame.OutputFile != 0 {
-				ScreenWriteStr(0, "  Output: ")
-				FileName(Files[p.Frame.OutputFile], 70, &fylNam)
-				ScreenWriteFileNameStr(0, fylNam, len(fylNam))
-				ScreenWriteln()
+				e.ScreenWriteStr(0, "  Output: ")
+				FileName(e.Files[p.Frame.OutputFile], 70, &fylNam)
+				e.ScreenWriteFileNameStr(0, fylNam, len(fylNam))
+				e.ScreenWriteln()
 				lineCount++
 			}
 		}
 		p = p.FLink
 	}
-	ScreenPause()
+	e.ScreenPause()
 	return true
 }
+
+// SpanAssign sets the text of a span, creating it in frame HEAP if it does
+// not already exist. This is the \SA command.
+func (e *Editor) SpanAssign(spanName string, text *TParObject) bool {
+	var newSpan *SpanObject
+	var oldSpan *SpanObject
+
+	if e.SpanFind(spanName, &newSpan, &oldSpan) {
+		// Grunge the old one
+		if newSpan == e.FrameOops.Span {
+			if !e.TextRemove(e.FrameOops.Span.MarkOne, e.FrameOops.Span.MarkTwo) {
+				return false
+			}
+		} else {
+			// Make sure oops_span is okay
+			if !MarkCreate(e.FrameOops.LastGroup.LastLine, 1, &e.FrameOops.Span.MarkTwo) {
+				return false
+			}
+			if !e.TextMove(
+				false, // Don't copy, transfer
+				1,     // One instance of
+				newSpan.MarkOne,
+				newSpan.MarkTwo,
+				e.FrameOops.Span.MarkTwo,       // destination
+				&e.FrameOops.Marks[MarkEquals], // leave at start
+				&e.FrameOops.Dot,               // leave at end
+			) {
+				return false
+			}
+		}
+	} else {
+		// Create a span in frame "HEAP"
+		if !MarkCreate(e.FrameHeap.LastGroup.LastLine, 1, &e.FrameHeap.Span.MarkTwo) {
+			return false
+		}
+		if !e.SpanCreate(spanName, e.FrameHeap.Span.MarkTwo, e.FrameHeap.Span.MarkTwo) {
+			return false
+		}
+		if !e.SpanFind(spanName, &newSpan, &oldSpan) {
+			return false
+		}
+	}
+	// Now copy the tpar into the span
+	newSpan.Block = false
+	if !e.TextInsertTpar(text, newSpan.MarkTwo, &newSpan.MarkOne) {
+		return false
+	}
+	fr := newSpan.MarkTwo.Line.Group.Frame
+	fr.TextModified = true
+	return MarkCreate(newSpan.MarkTwo.Line, newSpan.MarkTwo.Col, &fr.Marks[MarkModified])
+}
@@ -48,19 +48,6 @@ func createTestSpanStructure() (*FrameObject, *GroupObject, *LineHdrObject) {
 	return frame, group, firstLine
 }
 
-// saveAndClearSpans saves the current span state and clears it
-func saveAndClearSpans(t *testing.T) *SpanObject {
-	t.Helper()
-	origFirstSpan := FirstSpan
-	FirstSpan = nil
-
-	t.Cleanup(func() {
-		FirstSpan = origFirstSpan
-	})
-
-	return origFirstSpan
-}
-
 // createTestSpan creates a span with the given name and marks
 func createTestSpan(name string, markOne, markTwo *MarkObject) *SpanObject {
 	span := &SpanObject{
@@ -76,12 +63,12 @@ func createTestSpan(name string, markOne, markTwo *MarkObject) *SpanObject {
 // Tests for SpanFind
 
 func TestSpanFind(t *testing.T) {
-	saveAndClearSpans(t)
+	e := NewEditor()
 
 	t.Run("EmptySpanList", func(t *testing.T) {
 		var ptr, oldp *SpanObject
 
-		result := SpanFind("test", &ptr, &oldp)
+		result := e.SpanFind("test", &ptr, &oldp)
 
 		assert.False(t, result, "SpanFind should return false for empty span list")
 		assert.Nil(t, ptr, "ptr should be nil")
@@ -96,10 +83,10 @@ func TestSpanFind(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark2)
 
 		span := createTestSpan("test", mark1, mark2)
-		FirstSpan = span
+		e.FirstSpan = span
 
 		var ptr, oldp *SpanObject
-		result := SpanFind("test", &ptr, &oldp)
+		result := e.SpanFind("test", &ptr, &oldp)
 
 		assert.True(t, result, "SpanFind should return true")
 		assert.Equal(t, span, ptr, "ptr should point to the span")
@@ -118,10 +105,10 @@ func TestSpanFind(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark2)
 
 		span := createTestSpan("test", mark1, mark2)
-		FirstSpan = span
+		e.FirstSpan = span
 
 		var ptr, oldp *SpanObject
-		result := SpanFind("notfound", &ptr, &oldp)
+		result := e.SpanFind("notfound", &ptr, &oldp)
 
 		assert.False(t, result, "SpanFind should return false for non-existent span")
 
@@ -148,10 +135,10 @@ func TestSpanFind(t *testing.T) {
 		span2.FLink = span3
 		span3.BLink = span2
 
-		FirstSpan = span1
+		e.FirstSpan = span1
 
 		var ptr, oldp *SpanObject
-		result := SpanFind("beta", &ptr, &oldp)
+		result := e.SpanFind("beta", &ptr, &oldp)
 
 		assert.True(t, result, "SpanFind should return true")
 		assert.Equal(t, span2, ptr, "ptr should point to span2")
@@ -175,10 +162,10 @@ func TestSpanFind(t *testing.T) {
 		span1.FLink = span2
 		span2.BLink = span1
 
-		FirstSpan = span1
+		e.FirstSpan = span1
 
 		var ptr, oldp *SpanObject
-		result := SpanFind("beta", &ptr, &oldp)
+		result := e.SpanFind("beta", &ptr, &oldp)
 
 		assert.True(t, result, "SpanFind should return true")
 		assert.Equal(t, span2, ptr, "ptr should point to span2")
@@ -202,10 +189,10 @@ func TestSpanFind(t *testing.T) {
 		span1.FLink = span2
 		span2.BLink = span1
 
-		FirstSpan = span1
+		e.FirstSpan = span1
 
 		var ptr, oldp *SpanObject
-		result := SpanFind("zulu", &ptr, &oldp)
+		result := e.SpanFind("zulu", &ptr, &oldp)
 
 		assert.False(t, result, "SpanFind should return false for name after all spans")
 		assert.Nil(t, ptr, "ptr should be nil")
@@ -228,10 +215,10 @@ func TestSpanFind(t *testing.T) {
 		span := createTestSpan("framespan", mark1, mark2)
 		span.Frame = frame
 
-		FirstSpan = span
+		e.FirstSpan = span
 
 		var ptr, oldp *SpanObject
-		result := SpanFind("framespan", &ptr, &oldp)
+		result := e.SpanFind("framespan", &ptr, &oldp)
 
 		assert.True(t, result, "SpanFind should return true for frame span")
 		assert.Equal(t, span, ptr, "ptr should point to the span")
@@ -250,12 +237,11 @@ func TestSpanFind(t *testing.T) {
 // Tests for SpanCreate
 
 func TestSpanCreate(t *testing.T) {
+	e := NewEditor()
 	// Note: We can't easily mock ScreenMessage as it's a regular function,
 	// not a variable. The tests will call it, but that's okay for unit tests.
 	// In real usage, error messages would be displayed to the user.
 
-	saveAndClearSpans(t)
-
 	t.Run("CreateNewSpan", func(t *testing.T) {
 		frame, _, firstLine := createTestSpanStructure()
 
@@ -263,19 +249,19 @@ func TestSpanCreate(t *testing.T) {
 		MarkCreate(firstLine, 1, &mark1)
 		MarkCreate(firstLine, 10, &mark2)
 
-		result := SpanCreate("newspan", mark1, mark2)
+		result := e.SpanCreate("newspan", mark1, mark2)
 
 		assert.True(t, result, "SpanCreate should return true")
-		assert.NotNil(t, FirstSpan, "FirstSpan should not be nil")
-		assert.Equal(t, "newspan", FirstSpan.Name, "Span name should be 'newspan'")
-		assert.Nil(t, FirstSpan.Frame, "Span Frame should be nil")
-		assert.NotNil(t, FirstSpan.MarkOne, "MarkOne should be created")
-		assert.NotNil(t, FirstSpan.MarkTwo, "MarkTwo should be created")
+		assert.NotNil(t, e.FirstSpan, "FirstSpan should not be nil")
+		assert.Equal(t, "newspan", e.FirstSpan.Name, "Span name should be 'newspan'")
+		assert.Nil(t, e.FirstSpan.Frame, "Span Frame should be nil")
+		assert.NotNil(t, e.FirstSpan.MarkOne, "MarkOne should be created")
+		assert.NotNil(t, e.FirstSpan.MarkTwo, "MarkTwo should be created")
 
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("CreateSpanWithCorrectOrder", func(t *testing.T) {
@@ -285,16 +271,16 @@ func TestSpanCreate(t *testing.T) {
 		MarkCreate(firstLine, 5, &mark1)
 		MarkCreate(firstLine, 15, &mark2)
 
-		result := SpanCreate("ordered", mark1, mark2)
+		result := e.SpanCreate("ordered", mark1, mark2)
 
 		assert.True(t, result, "SpanCreate should return true")
-		assert.Equal(t, 5, FirstSpan.MarkOne.Col, "MarkOne col should be 5")
-		assert.Equal(t, 15, FirstSpan.MarkTwo.Col, "MarkTwo col should be 15")
+		assert.Equal(t, 5, e.FirstSpan.MarkOne.Col, "MarkOne col should be 5")
+		assert.Equal(t, 15, e.FirstSpan.MarkTwo.Col, "MarkTwo col should be 15")
 
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("CreateSpanWithReverseOrder", func(t *testing.T) {
@@ -304,17 +290,17 @@ func TestSpanCreate(t *testing.T) {
 		MarkCreate(firstLine, 15, &mark1)
 		MarkCreate(firstLine, 5, &mark2)
 
-		result := SpanCreate("reversed", mark1, mark2)
+		result := e.SpanCreate("reversed", mark1, mark2)
 
 		assert.True(t, result, "SpanCreate should return true")
 		// Marks should be swapped to maintain correct order
-		assert.Equal(t, 5, FirstSpan.MarkOne.Col, "MarkOne col should be 5 (swapped)")
-		assert.Equal(t, 15, FirstSpan.MarkTwo.Col, "MarkTwo col should be 15 (swapped)")
+		assert.Equal(t, 5, e.FirstSpan.MarkOne.Col, "MarkOne col should be 5 (swapped)")
+		assert.Equal(t, 15, e.FirstSpan.MarkTwo.Col, "MarkTwo col should be 15 (swapped)")
 
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("CreateSpanAcrossLines", func(t *testing.T) {
@@ -325,17 +311,17 @@ func TestSpanCreate(t *testing.T) {
 		secondLine := firstLine.FLink
 		MarkCreate(secondLine, 5, &mark2)
 
-		result := SpanCreate("multiline", mark1, mark2)
+		result := e.SpanCreate("multiline", mark1, mark2)
 
 		assert.True(t, result, "SpanCreate should return true")
 		// Should keep marks in original order since first line < second line
-		assert.Equal(t, firstLine, FirstSpan.MarkOne.Line, "MarkOne should be on first line")
-		assert.Equal(t, secondLine, FirstSpan.MarkTwo.Line, "MarkTwo should be on second line")
+		assert.Equal(t, firstLine, e.FirstSpan.MarkOne.Line, "MarkOne should be on first line")
+		assert.Equal(t, secondLine, e.FirstSpan.MarkTwo.Line, "MarkTwo should be on second line")
 
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("RedefineExistingSpan", func(t *testing.T) {
@@ -346,27 +332,27 @@ func TestSpanCreate(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark2)
 
 		// Create initial span
-		SpanCreate("redefine", mark1, mark2)
+		e.SpanCreate("redefine", mark1, mark2)
 
-		assert.NotNil(t, FirstSpan, "FirstSpan should not be nil")
+		assert.NotNil(t, e.FirstSpan, "FirstSpan should not be nil")
 
-		originalSpan := FirstSpan
+		originalSpan := e.FirstSpan
 
 		// Redefine with new marks
 		MarkCreate(firstLine, 5, &mark3)
 		MarkCreate(firstLine, 15, &mark4)
 
-		result := SpanCreate("redefine", mark3, mark4)
+		result := e.SpanCreate("redefine", mark3, mark4)
 
 		assert.True(t, result, "SpanCreate should return true for redefinition")
-		assert.Equal(t, originalSpan, FirstSpan, "Should reuse the same span object")
-		assert.Equal(t, 5, FirstSpan.MarkOne.Col, "MarkOne col should be updated to 5")
-		assert.Equal(t, 15, FirstSpan.MarkTwo.Col, "MarkTwo col should be updated to 15")
+		assert.Equal(t, originalSpan, e.FirstSpan, "Should reuse the same span object")
+		assert.Equal(t, 5, e.FirstSpan.MarkOne.Col, "MarkOne col should be updated to 5")
+		assert.Equal(t, 15, e.FirstSpan.MarkTwo.Col, "MarkTwo col should be updated to 15")
 
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("CannotRedefineFrame", func(t *testing.T) {
@@ -380,13 +366,13 @@ func TestSpanCreate(t *testing.T) {
 		// Create a frame span
 		span := createTestSpan("framespan", mark1, mark2)
 		span.Frame = frame
-		FirstSpan = span
+		e.FirstSpan = span
 
 		// Try to redefine it
 		MarkCreate(firstLine, 5, &mark3)
 		MarkCreate(firstLine, 15, &mark4)
 
-		result := SpanCreate("framespan", mark3, mark4)
+		result := e.SpanCreate("framespan", mark3, mark4)
 
 		assert.False(t, result, "SpanCreate should return false when trying to redefine a frame")
 		// Note: ScreenMessage will be called, but we can't easily verify it in tests
@@ -394,7 +380,7 @@ func TestSpanCreate(t *testing.T) {
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("InsertSpanInAlphabeticalOrder", func(t *testing.T) {
@@ -405,24 +391,24 @@ func TestSpanCreate(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark2)
 
 		// Create spans in non-alphabetical order
-		SpanCreate("charlie", mark1, mark2)
-		SpanCreate("alpha", mark1, mark2)
-		SpanCreate("bravo", mark1, mark2)
+		e.SpanCreate("charlie", mark1, mark2)
+		e.SpanCreate("alpha", mark1, mark2)
+		e.SpanCreate("bravo", mark1, mark2)
 
 		// Verify alphabetical order
-		assert.Equal(t, "alpha", FirstSpan.Name, "First span should be 'alpha'")
-		assert.Equal(t, "bravo", FirstSpan.FLink.Name, "Second span should be 'bravo'")
-		assert.Equal(t, "charlie", FirstSpan.FLink.FLink.Name, "Third span should be 'charlie'")
+		assert.Equal(t, "alpha", e.FirstSpan.Name, "First span should be 'alpha'")
+		assert.Equal(t, "bravo", e.FirstSpan.FLink.Name, "Second span should be 'bravo'")
+		assert.Equal(t, "charlie", e.FirstSpan.FLink.FLink.Name, "Third span should be 'charlie'")
 
 		// Verify back links
-		assert.Nil(t, FirstSpan.BLink, "First span BLink should be nil")
-		assert.Equal(t, FirstSpan, FirstSpan.FLink.BLink, "Second span BLink should point to first")
-		assert.Equal(t, FirstSpan.FLink, FirstSpan.FLink.FLink.BLink, "Third span BLink should point to second")
+		assert.Nil(t, e.FirstSpan.BLink, "First span BLink should be nil")
+		assert.Equal(t, e.FirstSpan, e.FirstSpan.FLink.BLink, "Second span BLink should point to first")
+		assert.Equal(t, e.FirstSpan.FLink, e.FirstSpan.FLink.FLink.BLink, "Third span BLink should point to second")
 
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("CreateSpanSameLineEqualMarks", func(t *testing.T) {
@@ -432,28 +418,27 @@ func TestSpanCreate(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark1)
 		MarkCreate(firstLine, 10, &mark2)
 
-		result := SpanCreate("equal", mark1, mark2)
+		result := e.SpanCreate("equal", mark1, mark2)
 
 		assert.True(t, result, "SpanCreate should return true even with equal marks")
 		// When marks are equal, order doesn't matter but should still succeed
-		assert.Equal(t, 10, FirstSpan.MarkOne.Col, "MarkOne col should be 10")
-		assert.Equal(t, 10, FirstSpan.MarkTwo.Col, "MarkTwo col should be 10")
+		assert.Equal(t, 10, e.FirstSpan.MarkOne.Col, "MarkOne col should be 10")
+		assert.Equal(t, 10, e.FirstSpan.MarkTwo.Col, "MarkTwo col should be 10")
 
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 }
 
 // Tests for SpanDestroy
 
 func TestSpanDestroy(t *testing.T) {
+	e := NewEditor()
 	// Note: ScreenMessage will be called for frame destruction attempts,
 	// but we can't easily mock it. That's okay for unit tests.
 
-	saveAndClearSpans(t)
-
 	t.Run("DestroySingleSpan", func(t *testing.T) {
 		frame, _, firstLine := createTestSpanStructure()
 
@@ -462,13 +447,13 @@ func TestSpanDestroy(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark2)
 
 		span := createTestSpan("test", mark1, mark2)
-		FirstSpan = span
+		e.FirstSpan = span
 
-		result := SpanDestroy(&span)
+		result := e.SpanDestroy(&span)
 
 		assert.True(t, result, "SpanDestroy should return true")
 		assert.Nil(t, span, "span pointer should be nil after destroy")
-		assert.Nil(t, FirstSpan, "FirstSpan should be nil after destroying only span")
+		assert.Nil(t, e.FirstSpan, "FirstSpan should be nil after destroying only span")
 
 		// Clean up
 		frame.FirstGroup = nil
@@ -485,9 +470,9 @@ func TestSpanDestroy(t *testing.T) {
 
 		span := createTestSpan("framespan", mark1, mark2)
 		span.Frame = frame
-		FirstSpan = span
+		e.FirstSpan = span
 
-		result := SpanDestroy(&span)
+		result := e.SpanDestroy(&span)
 
 		assert.False(t, result, "SpanDestroy should return false for frame span")
 		assert.NotNil(t, span, "span pointer should not be nil when destruction fails")
@@ -496,7 +481,7 @@ func TestSpanDestroy(t *testing.T) {
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("DestroyFirstSpanInList", func(t *testing.T) {
@@ -515,19 +500,19 @@ func TestSpanDestroy(t *testing.T) {
 		span2.FLink = span3
 		span3.BLink = span2
 
-		FirstSpan = span1
+		e.FirstSpan = span1
 
-		result := SpanDestroy(&span1)
+		result := e.SpanDestroy(&span1)
 
 		assert.True(t, result, "SpanDestroy should return true")
 		assert.Nil(t, span1, "span1 pointer should be nil")
-		assert.Equal(t, span2, FirstSpan, "FirstSpan should now be span2")
+		assert.Equal(t, span2, e.FirstSpan, "FirstSpan should now be span2")
 		assert.Nil(t, span2.BLink, "span2 BLink should be nil")
 
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("DestroyMiddleSpanInList", func(t *testing.T) {
@@ -546,9 +531,9 @@ func TestSpanDestroy(t *testing.T) {
 		span2.FLink = span3
 		span3.BLink = span2
 
-		FirstSpan = span1
+		e.FirstSpan = span1
 
-		result := SpanDestroy(&span2)
+		result := e.SpanDestroy(&span2)
 
 		assert.True(t, result, "SpanDestroy should return true")
 		assert.Nil(t, span2, "span2 pointer should be nil")
@@ -558,7 +543,7 @@ func TestSpanDestroy(t *testing.T) {
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("DestroyLastSpanInList", func(t *testing.T) {
@@ -574,9 +559,9 @@ func TestSpanDestroy(t *testing.T) {
 		span1.FLink = span2
 		span2.BLink = span1
 
-		FirstSpan = span1
+		e.FirstSpan = span1
 
-		result := SpanDestroy(&span2)
+		result := e.SpanDestroy(&span2)
 
 		assert.True(t, result, "SpanDestroy should return true")
 		assert.Nil(t, span2, "span2 pointer should be nil")
@@ -585,7 +570,7 @@ func TestSpanDestroy(t *testing.T) {
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("DestroySpanWithCode", func(t *testing.T) {
@@ -601,9 +586,9 @@ func TestSpanDestroy(t *testing.T) {
 		span := createTestSpan("withcode", mark1, mark2)
 		// Set Code to non-nil to trigger CodeDiscard path
 		span.Code = &CodeHeader{}
-		FirstSpan = span
+		e.FirstSpan = span
 
-		result := SpanDestroy(&span)
+		result := e.SpanDestroy(&span)
 
 		assert.True(t, result, "SpanDestroy should return true even with code")
 		assert.Nil(t, span, "span pointer should be nil")
@@ -617,7 +602,7 @@ func TestSpanDestroy(t *testing.T) {
 // Integration tests
 
 func TestSpanCreateFindDestroyIntegration(t *testing.T) {
-	saveAndClearSpans(t)
+	e := NewEditor()
 
 	t.Run("CreateFindAndDestroy", func(t *testing.T) {
 		frame, _, firstLine := createTestSpanStructure()
@@ -627,21 +612,21 @@ func TestSpanCreateFindDestroyIntegration(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark2)
 
 		// Create a span
-		result := SpanCreate("test", mark1, mark2)
+		result := e.SpanCreate("test", mark1, mark2)
 		assert.True(t, result, "SpanCreate failed")
 
 		// Find it
 		var ptr, oldp *SpanObject
-		result = SpanFind("test", &ptr, &oldp)
+		result = e.SpanFind("test", &ptr, &oldp)
 		assert.True(t, result, "SpanFind failed")
 		assert.Equal(t, "test", ptr.Name, "Found wrong span")
 
 		// Destroy it
-		result = SpanDestroy(&ptr)
+		result = e.SpanDestroy(&ptr)
 		assert.True(t, result, "SpanDestroy failed")
 
 		// Verify it's gone
-		result = SpanFind("test", &ptr, &oldp)
+		result = e.SpanFind("test", &ptr, &oldp)
 		assert.False(t, result, "Span should not be found after destruction")
 
 		// Clean up
@@ -657,28 +642,28 @@ func TestSpanCreateFindDestroyIntegration(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark2)
 
 		// Create multiple spans
-		SpanCreate("span1", mark1, mark2)
-		SpanCreate("span2", mark1, mark2)
-		SpanCreate("span3", mark1, mark2)
+		e.SpanCreate("span1", mark1, mark2)
+		e.SpanCreate("span2", mark1, mark2)
+		e.SpanCreate("span3", mark1, mark2)
 
 		// Find and verify middle span
 		var ptr, oldp *SpanObject
-		assert.True(t, SpanFind("span2", &ptr, &oldp), "SpanFind span2 failed")
+		assert.True(t, e.SpanFind("span2", &ptr, &oldp), "SpanFind span2 failed")
 
 		// Destroy middle span
-		assert.True(t, SpanDestroy(&ptr), "SpanDestroy span2 failed")
+		assert.True(t, e.SpanDestroy(&ptr), "SpanDestroy span2 failed")
 
 		// Verify span1 and span3 still exist
-		assert.True(t, SpanFind("span1", &ptr, &oldp), "span1 should still exist")
-		assert.True(t, SpanFind("span3", &ptr, &oldp), "span3 should still exist")
+		assert.True(t, e.SpanFind("span1", &ptr, &oldp), "span1 should still exist")
+		assert.True(t, e.SpanFind("span3", &ptr, &oldp), "span3 should still exist")
 
 		// Verify span2 is gone
-		assert.False(t, SpanFind("span2", &ptr, &oldp), "span2 should not exist")
+		assert.False(t, e.SpanFind("span2", &ptr, &oldp), "span2 should not exist")
 
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("RedefineAndDestroy", func(t *testing.T) {
@@ -689,23 +674,23 @@ func TestSpanCreateFindDestroyIntegration(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark2)
 
 		// Create span
-		SpanCreate("redef", mark1, mark2)
+		e.SpanCreate("redef", mark1, mark2)
 
 		// Redefine it
 		MarkCreate(firstLine, 5, &mark3)
 		MarkCreate(firstLine, 15, &mark4)
-		SpanCreate("redef", mark3, mark4)
+		e.SpanCreate("redef", mark3, mark4)
 
 		// Verify redefinition
 		var ptr, oldp *SpanObject
-		assert.True(t, SpanFind("redef", &ptr, &oldp), "SpanFind failed")
+		assert.True(t, e.SpanFind("redef", &ptr, &oldp), "SpanFind failed")
 		assert.Equal(t, 5, ptr.MarkOne.Col, "Mark not updated, expected col 5")
 
 		// Destroy it
-		assert.True(t, SpanDestroy(&ptr), "SpanDestroy failed")
+		assert.True(t, e.SpanDestroy(&ptr), "SpanDestroy failed")
 
 		// Verify destruction
-		assert.False(t, SpanFind("redef", &ptr, &oldp), "Span should be destroyed")
+		assert.False(t, e.SpanFind("redef", &ptr, &oldp), "Span should be destroyed")
 
 		// Clean up
 		frame.FirstGroup = nil
@@ -716,7 +701,7 @@ func TestSpanCreateFindDestroyIntegration(t *testing.T) {
 // Tests for span list ordering
 
 func TestSpanListOrdering(t *testing.T) {
-	saveAndClearSpans(t)
+	e := NewEditor()
 
 	t.Run("MaintainAlphabeticalOrder", func(t *testing.T) {
 		frame, _, firstLine := createTestSpanStructure()
@@ -728,11 +713,11 @@ func TestSpanListOrdering(t *testing.T) {
 		// Create spans in random order
 		names := []string{"zebra", "alpha", "mike", "charlie", "tango"}
 		for _, name := range names {
-			SpanCreate(name, mark1, mark2)
+			e.SpanCreate(name, mark1, mark2)
 		}
 
 		// Verify alphabetical order
-		span := FirstSpan
+		span := e.FirstSpan
 		var prevName string
 		for span != nil {
 			if prevName != "" {
@@ -745,7 +730,7 @@ func TestSpanListOrdering(t *testing.T) {
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 
 	t.Run("VerifyBidirectionalLinks", func(t *testing.T) {
@@ -756,13 +741,13 @@ func TestSpanListOrdering(t *testing.T) {
 		MarkCreate(firstLine, 10, &mark2)
 
 		// Create several spans
-		SpanCreate("a", mark1, mark2)
-		SpanCreate("b", mark1, mark2)
-		SpanCreate("c", mark1, mark2)
-		SpanCreate("d", mark1, mark2)
+		e.SpanCreate("a", mark1, mark2)
+		e.SpanCreate("b", mark1, mark2)
+		e.SpanCreate("c", mark1, mark2)
+		e.SpanCreate("d", mark1, mark2)
 
 		// Verify forward links
-		span := FirstSpan
+		span := e.FirstSpan
 		var prev *SpanObject
 		for span != nil {
 			assert.Equal(t, prev, span.BLink, "BLink mismatch for span %s", span.Name)
@@ -773,6 +758,6 @@ func TestSpanListOrdering(t *testing.T) {
 		// Clean up
 		frame.FirstGroup = nil
 		frame.LastGroup = nil
-		FirstSpan = nil
+		e.FirstSpan = nil
 	})
 }
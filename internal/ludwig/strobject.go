@@ -1,12 +1,13 @@
 // Handles a variable-size string object with 1-based indexing.
-// Calls to methods will panic if indices are out of range.
+// Writing past the end of the string extends it with spaces, other calls
+// to methods will panic if indices are out of range.
 
 package ludwig
 
 import (
-	"bytes"
 	"fmt"
 	"math"
+	"slices"
 )
 
 const (
@@ -16,7 +17,7 @@ const (
 
 // StrObject represents a variable-size string object with 1-based indexing
 type StrObject struct {
-	array []byte
+	array []rune
 }
 
 // Size returns the allocated size (max valid 1-based index)
@@ -43,10 +44,29 @@ func (s *StrObject) adjustIndex(index, offset int) int {
 	return index + offset - MinIndex
 }
 
+// grow extends the array with spaces so that n characters starting at
+// index can be written
+func (s *StrObject) grow(index, n int) {
+	if index < MinIndex {
+		panic("index out of range")
+	}
+	if n > math.MaxInt-index {
+		panic("index + offset overflow")
+	}
+	size := index + n - MinIndex
+	if size > len(s.array) {
+		old := len(s.array)
+		s.array = slices.Grow(s.array, size-old)[:size]
+		for i := old; i < size; i++ {
+			s.array[i] = ' '
+		}
+	}
+}
+
 // NewBlankStrObject creates a new StrObject of the given size filled with
 // spaces
 func NewBlankStrObject(size int) *StrObject {
-	s := &StrObject{array: make([]byte, size)}
+	s := &StrObject{array: make([]rune, size)}
 	for i := range s.array {
 		s.array[i] = ' '
 	}
@@ -55,16 +75,22 @@ func NewBlankStrObject(size int) *StrObject {
 
 // NewStrObjectFrom creates a new StrObject from a string
 func NewStrObjectFrom(str string) *StrObject {
-	s := &StrObject{array: make([]byte, len(str))}
+	s := &StrObject{array: make([]rune, len(str))}
 	s.Assign(str)
 	return s
 }
 
+// NewStrObjectRunes creates a new StrObject holding a copy of some runes,
+// which may include raw bytes that a string would lose
+func NewStrObjectRunes(runes []rune) *StrObject {
+	return &StrObject{array: append([]rune(nil), runes...)}
+}
+
 // NewStrObjectCopy creates a new StrObject by copying srcLen characters from
 // src starting at srcIndex, and filling the rest of dstLen with spaces if
 // dstLen > srcLen
 func NewStrObjectCopy(src *StrObject, srcIndex int, srcLen int, dstLen int) *StrObject {
-	s := &StrObject{array: make([]byte, dstLen)}
+	s := &StrObject{array: make([]rune, dstLen)}
 	s.Copy(src, srcIndex, min(dstLen, srcLen), 1)
 	s.Fill(' ', srcLen+1, dstLen)
 	return s
@@ -73,35 +99,36 @@ func NewStrObjectCopy(src *StrObject, srcIndex int, srcLen int, dstLen int) *Str
 // EmptyStrObject returns a StrObject with an empty, zero length string
 func EmptyStrObject() *StrObject {
 	// We return a fresh object, but could consider a shared object.
-	return &StrObject{array: make([]byte, 0)}
+	return &StrObject{array: make([]rune, 0)}
 }
 
 // Clone creates a copy of the StrObject
 func (s *StrObject) Clone() *StrObject {
-	newArray := make([]byte, len(s.array))
+	newArray := make([]rune, len(s.array))
 	copy(newArray, s.array)
 	return &StrObject{array: newArray}
 }
 
 // Get returns the character at the given 1-based index
-func (s *StrObject) Get(index int) byte {
+func (s *StrObject) Get(index int) rune {
 	idx := s.adjustIndex(index, 0)
 	return s.array[idx]
 }
 
 // Set sets the character at the given 1-based index
-func (s *StrObject) Set(index int, value byte) {
+func (s *StrObject) Set(index int, value rune) {
+	s.grow(index, 1)
 	idx := s.adjustIndex(index, 0)
 	s.array[idx] = value
 }
 
 // Assign sets the content from a string, reallocating if necessary
 func (s *StrObject) Assign(str string) {
-	thisLen := len(s.array)
-	if thisLen < len(str) {
-		s.array = make([]byte, len(str))
+	runes := []rune(str)
+	if len(s.array) < len(runes) {
+		s.array = make([]rune, len(runes))
 	}
-	s.FillCopyBytes([]byte(str), 1, len(s.array), ' ')
+	s.FillCopyRunes(runes, 1, len(s.array), ' ')
 }
 
 // Equals compares n characters starting at srcOffset with another StrObject starting at dstOffset
@@ -113,11 +140,22 @@ func (s *StrObject) Equals(other *StrObject, n, srcOffset, dstOffset int) bool {
 	other.checkIndex(dstOffset, n-1)
 	srcIdx := s.adjustIndex(srcOffset, 0)
 	dstIdx := other.adjustIndex(dstOffset, 0)
-	return bytes.Equal(s.array[srcIdx:srcIdx+n], other.array[dstIdx:dstIdx+n])
+	return slices.Equal(s.array[srcIdx:srcIdx+n], other.array[dstIdx:dstIdx+n])
+}
+
+// EqualsRunes compares the characters starting at index with runes
+func (s *StrObject) EqualsRunes(runes []rune, index int) bool {
+	n := len(runes)
+	if n == 0 {
+		return true
+	}
+	s.checkIndex(index, n-1)
+	idx := s.adjustIndex(index, 0)
+	return slices.Equal(s.array[idx:idx+n], runes)
 }
 
 // ApplyN applies a function to n characters starting at start
-func (s *StrObject) ApplyN(f func(byte) byte, n, start int) {
+func (s *StrObject) ApplyN(f func(rune) rune, n, start int) {
 	if n <= 0 {
 		return
 	}
@@ -141,13 +179,15 @@ func (s *StrObject) Copy(src *StrObject, srcOffset, count, dstOffset int) {
 	// if count <= 0 {
 	// return
 	// }
+	src.checkIndex(srcOffset, count-1)
+	s.grow(dstOffset, count)
 	srcIdx := src.adjustIndex(srcOffset, 0)
 	dstIdx := s.adjustIndex(dstOffset, 0)
 	copy(s.array[dstIdx:dstIdx+count], src.array[srcIdx:srcIdx+count])
 }
 
-// CopyN copies count bytes from src to this object at dstOffset
-func (s *StrObject) CopyN(src []byte, count, dstOffset int) {
+// CopyN copies count characters from src to this object at dstOffset
+func (s *StrObject) CopyN(src []rune, count, dstOffset int) {
 	if count <= 0 {
 		return
 	}
@@ -155,6 +195,7 @@ func (s *StrObject) CopyN(src []byte, count, dstOffset int) {
 	// if count <= 0 {
 	// 	return
 	// }
+	s.grow(dstOffset, count)
 	dstIdx := s.adjustIndex(dstOffset, 0)
 	copy(s.array[dstIdx:dstIdx+count], src[:count])
 }
@@ -173,8 +214,9 @@ func (s *StrObject) Erase(n, from int) {
 }
 
 // Fill fills the range [start, end] with value
-func (s *StrObject) Fill(value byte, start, end int) {
+func (s *StrObject) Fill(value rune, start, end int) {
 	if start <= end {
+		s.grow(start, end+1-start)
 		startIdx := s.adjustIndex(start, 0)
 		endIdx := s.adjustIndex(end, 0) + 1
 		for i := startIdx; i < endIdx; i++ {
@@ -184,7 +226,7 @@ func (s *StrObject) Fill(value byte, start, end int) {
 }
 
 // FillN fills n characters starting at start with value
-func (s *StrObject) FillN(value byte, n, start int) {
+func (s *StrObject) FillN(value rune, n, start int) {
 	if n <= 0 {
 		return
 	}
@@ -192,6 +234,7 @@ func (s *StrObject) FillN(value byte, n, start int) {
 	// if n <= 0 {
 	// 	return
 	// }
+	s.grow(start, n)
 	startIdx := s.adjustIndex(start, 0)
 	for i := startIdx; i < startIdx+n; i++ {
 		s.array[i] = value
@@ -200,7 +243,7 @@ func (s *StrObject) FillN(value byte, n, start int) {
 
 // FillCopy copies srcLen characters from src at srcIndex to dstLen positions at dstIndex,
 // filling remaining positions with value if dstLen > srcLen
-func (s *StrObject) FillCopy(src *StrObject, srcIndex, srcLen, dstIndex, dstLen int, value byte) {
+func (s *StrObject) FillCopy(src *StrObject, srcIndex, srcLen, dstIndex, dstLen int, value rune) {
 	if dstLen <= 0 {
 		return
 	}
@@ -208,6 +251,7 @@ func (s *StrObject) FillCopy(src *StrObject, srcIndex, srcLen, dstIndex, dstLen
 	// if dstLen <= 0 {
 	// 	return
 	// }
+	s.grow(dstIndex, dstLen)
 	dstIdx := s.adjustIndex(dstIndex, 0)
 	length := min(srcLen, dstLen)
 
@@ -223,10 +267,10 @@ func (s *StrObject) FillCopy(src *StrObject, srcIndex, srcLen, dstIndex, dstLen
 	}
 }
 
-// FillCopyBytes copies bytes from src to dstLen positions at dstIndex,
+// FillCopyRunes copies characters from src to dstLen positions at dstIndex,
 // filling remaining positions with value if dstLen > len(src). dstLen is
 // clamped to the available space in the array starting at dstIndex.
-func (s *StrObject) FillCopyBytes(src []byte, dstIndex, dstLen int, value byte) {
+func (s *StrObject) FillCopyRunes(src []rune, dstIndex, dstLen int, value rune) {
 	dstLen = min(dstLen, len(s.array)-dstIndex+MinIndex)
 	if dstLen <= 0 {
 		return
@@ -259,7 +303,7 @@ func (s *StrObject) Len() int {
 // Length returns the position of the last character that is not equal to value,
 // searching backwards from the 'from' position.
 // Note that 'from' is adjusted to start within the array bounds.
-func (s *StrObject) Length(value byte, from int) int {
+func (s *StrObject) Length(value rune, from int) int {
 	from = min(from, len(s.array))
 	if from > 0 {
 		lastIdx := s.adjustIndex(from, 0)
@@ -282,6 +326,17 @@ func (s *StrObject) Slice(index, length int) string {
 	return string(s.array[idx : idx+length])
 }
 
+// RuneSlice returns a copy of length characters starting at index, as
+// they are stored
+func (s *StrObject) RuneSlice(index, length int) []rune {
+	if length == 0 {
+		return nil
+	}
+	s.checkIndex(index, length-1)
+	idx := s.adjustIndex(index, 0)
+	return slices.Clone(s.array[idx : idx+length])
+}
+
 // String returns the entire array as a string
 func (s *StrObject) String() string {
 	return string(s.array[:])
@@ -298,17 +353,17 @@ func (s *StrObject) TrimmedString() string {
 
 // Compare compares this StrObject with another
 func (s *StrObject) Compare(other *StrObject) int {
-	return bytes.Compare(s.array[:], other.array[:])
+	return slices.Compare(s.array[:], other.array[:])
 }
 
 // Equal returns true if this StrObject is equal to another
 func (s *StrObject) Equal(other *StrObject) bool {
-	return bytes.Equal(s.array, other.array)
+	return slices.Equal(s.array, other.array)
 }
 
-// Bytes returns a copy of the underlying byte array
-func (s *StrObject) Bytes() []byte {
-	return append([]byte(nil), s.array[:]...)
+// Runes returns a copy of the underlying character array
+func (s *StrObject) Runes() []rune {
+	return append([]rune(nil), s.array[:]...)
 }
 
 // Format implements fmt.Formatter for pretty printing
@@ -316,9 +371,9 @@ func (s *StrObject) Format(f fmt.State, verb rune) {
 	switch verb {
 	case 's', 'v':
 		length := s.Length(' ', len(s.array))
-		f.Write(s.array[:length])
+		f.Write([]byte(string(s.array[:length])))
 	case 'q':
-		fmt.Fprintf(f, "%q", s.array[:])
+		fmt.Fprintf(f, "%q", string(s.array[:]))
 	default:
 		fmt.Fprintf(f, "%%!%c(StrObject)", verb)
 	}
@@ -11,20 +11,23 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// testStrLen is the size of the strings used by the tests
+const testStrLen = 400
+
 // TestNewBlankStrObject tests the NewBlankStrObject constructor
 func TestNewBlankStrObject(t *testing.T) {
 	t.Run("Blank object contains blanks", func(t *testing.T) {
-		s := NewBlankStrObject(MaxStrLen)
+		s := NewBlankStrObject(testStrLen)
 		assert.NotNil(t, s, "NewBlankStrObject returned nil")
-		for i := 0; i < MaxStrLen; i++ {
-			assert.Equal(t, byte(' '), s.array[i], "NewBlankStrObject(): array[%d] mismatch", i)
+		for i := 0; i < testStrLen; i++ {
+			assert.Equal(t, rune(' '), s.array[i], "NewBlankStrObject(): array[%d] mismatch", i)
 		}
 	})
 }
 
 // TestClone tests the Clone method
 func TestClone(t *testing.T) {
-	original := NewBlankStrObject(MaxStrLen)
+	original := NewBlankStrObject(testStrLen)
 	original.Set(10, 'B')
 	original.Set(20, 'C')
 
@@ -35,21 +38,21 @@ func TestClone(t *testing.T) {
 
 	// Verify it's a different object
 	clone.Set(10, 'X')
-	assert.NotEqual(t, byte('X'), original.Get(10), "Modifying clone affected original")
+	assert.NotEqual(t, rune('X'), original.Get(10), "Modifying clone affected original")
 }
 
 // TestGetSet tests Get and Set methods
 func TestGetSet(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 
 	// Test valid indices (1-based)
 	testCases := []struct {
 		index int
-		value byte
+		value rune
 	}{
 		{1, 'A'},
 		{10, 'B'},
-		{MaxStrLen, 'Z'},
+		{testStrLen, 'Z'},
 		{100, 'X'},
 	}
 
@@ -62,9 +65,9 @@ func TestGetSet(t *testing.T) {
 
 // TestGetSetPanics tests that Get/Set panic on invalid indices
 func TestGetSetPanics(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 
-	invalidIndices := []int{0, -1, MaxStrLen + 1, -100}
+	invalidIndices := []int{0, -1, testStrLen + 1, -100}
 
 	for _, idx := range invalidIndices {
 		t.Run(fmt.Sprintf("Get(%d)", idx), func(t *testing.T) {
@@ -76,6 +79,10 @@ func TestGetSetPanics(t *testing.T) {
 			s.Get(idx)
 		})
 
+		if idx > 0 {
+			// Setting past the end extends the string instead
+			continue
+		}
 		t.Run(fmt.Sprintf("Set(%d)", idx), func(t *testing.T) {
 			defer func() {
 				if r := recover(); r == nil {
@@ -87,6 +94,35 @@ func TestGetSetPanics(t *testing.T) {
 	}
 }
 
+// TestWriteGrows tests that writing past the end extends the string with spaces
+func TestWriteGrows(t *testing.T) {
+	s := EmptyStrObject()
+	s.Set(5, 'A')
+	assert.Equal(t, "    A", s.String())
+
+	s.CopyN([]rune("BC"), 2, 7)
+	assert.Equal(t, "    A BC", s.String())
+
+	s.Fill('D', 9, 10)
+	s.FillN('E', 2, 12)
+	assert.Equal(t, "    A BCDD EE", s.String())
+
+	src := NewStrObjectFrom("xyz")
+	s.Copy(src, 1, 3, 15)
+	s.FillCopy(src, 1, 3, 19, 5, '-')
+	assert.Equal(t, "    A BCDD EE xyz xyz--", s.String())
+
+	// Writing in the middle leaves the length alone
+	s.Set(1, 'F')
+	assert.Equal(t, 23, s.Len())
+
+	// A long string
+	long := EmptyStrObject()
+	long.Fill('L', 1, 100000)
+	assert.Equal(t, 100000, long.Len())
+	assert.Equal(t, 100000, long.Length(' ', long.Len()))
+}
+
 // TestAssign tests the Assign method
 func TestAssign(t *testing.T) {
 	tests := []struct {
@@ -119,7 +155,7 @@ func TestAssign(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewBlankStrObject(MaxStrLen)
+			s := NewBlankStrObject(testStrLen)
 			s.Assign(tt.input)
 			assert.True(t, tt.expected(s), "Assign(%q) failed verification", tt.input)
 		})
@@ -128,10 +164,10 @@ func TestAssign(t *testing.T) {
 
 // TestEquals tests the Equals method
 func TestEquals(t *testing.T) {
-	s1 := NewBlankStrObject(MaxStrLen)
+	s1 := NewBlankStrObject(testStrLen)
 	s1.Assign("ABCDEFGH")
 
-	s2 := NewBlankStrObject(MaxStrLen)
+	s2 := NewBlankStrObject(testStrLen)
 	s2.Assign("ABCDXXGH")
 
 	tests := []struct {
@@ -158,11 +194,11 @@ func TestEquals(t *testing.T) {
 
 // TestApplyN tests the ApplyN method
 func TestApplyN(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 	s.Assign("hello")
 
 	// Convert to uppercase
-	toUpper := func(b byte) byte {
+	toUpper := func(b rune) rune {
 		if b >= 'a' && b <= 'z' {
 			return b - 32
 		}
@@ -179,11 +215,11 @@ func TestApplyN(t *testing.T) {
 
 // TestCopy tests the Copy method
 func TestCopy(t *testing.T) {
-	src := NewBlankStrObject(MaxStrLen)
+	src := NewBlankStrObject(testStrLen)
 	src.Assign("Source Text")
 
-	dst := NewBlankStrObject(MaxStrLen)
-	dst.Fill('X', 1, MaxStrLen)
+	dst := NewBlankStrObject(testStrLen)
+	dst.Fill('X', 1, testStrLen)
 
 	// Copy 6 characters from position 1 to position 5
 	dst.Copy(src, 1, 6, 5)
@@ -191,7 +227,7 @@ func TestCopy(t *testing.T) {
 	assert.Equal(t, "Source", dst.Slice(5, 6), "Copy() failed")
 
 	// Verify surrounding characters unchanged
-	assert.Equal(t, byte('X'), dst.Get(4), "Copy affected preceding character")
+	assert.Equal(t, rune('X'), dst.Get(4), "Copy affected preceding character")
 
 	// Test zero count (should be no-op)
 	original := dst.Clone()
@@ -201,8 +237,8 @@ func TestCopy(t *testing.T) {
 
 // TestCopyN tests the CopyN method
 func TestCopyN(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
-	src := []byte("Hello World")
+	s := NewBlankStrObject(testStrLen)
+	src := []rune("Hello World")
 
 	s.CopyN(src, 5, 10)
 
@@ -214,7 +250,7 @@ func TestCopyN(t *testing.T) {
 
 // TestErase tests the Erase method
 func TestErase(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 	s.Assign("ABCDEFGHIJ")
 
 	// Erase 3 characters starting at position 4
@@ -234,33 +270,33 @@ func TestErase(t *testing.T) {
 
 // TestFill tests the Fill method
 func TestFill(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 
 	s.Fill('X', 10, 20)
 
 	// Check filled range
 	for i := 10; i <= 20; i++ {
-		assert.Equal(t, byte('X'), s.Get(i), "Fill: position %d mismatch", i)
+		assert.Equal(t, rune('X'), s.Get(i), "Fill: position %d mismatch", i)
 	}
 
 	// Check outside range
-	assert.NotEqual(t, byte('X'), s.Get(9), "Fill affected position before range")
-	assert.NotEqual(t, byte('X'), s.Get(21), "Fill affected position after range")
+	assert.NotEqual(t, rune('X'), s.Get(9), "Fill affected position before range")
+	assert.NotEqual(t, rune('X'), s.Get(21), "Fill affected position after range")
 }
 
 // TestFillN tests the FillN method
 func TestFillN(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 
 	s.FillN('Y', 10, 15)
 
 	// Check filled positions
 	for i := range 10 {
-		assert.Equal(t, byte('Y'), s.Get(15+i), "FillN: position %d mismatch", 15+i)
+		assert.Equal(t, rune('Y'), s.Get(15+i), "FillN: position %d mismatch", 15+i)
 	}
 
 	// Check before range
-	assert.NotEqual(t, byte('Y'), s.Get(14), "FillN affected position before range")
+	assert.NotEqual(t, rune('Y'), s.Get(14), "FillN affected position before range")
 
 	// Test zero count
 	s.FillN('Z', 0, 1)
@@ -268,10 +304,10 @@ func TestFillN(t *testing.T) {
 
 // TestFillCopy tests the FillCopy method
 func TestFillCopy(t *testing.T) {
-	src := NewBlankStrObject(MaxStrLen)
+	src := NewBlankStrObject(testStrLen)
 	src.Assign("Source")
 
-	dst := NewBlankStrObject(MaxStrLen)
+	dst := NewBlankStrObject(testStrLen)
 
 	tests := []struct {
 		name     string
@@ -279,7 +315,7 @@ func TestFillCopy(t *testing.T) {
 		srcLen   int
 		dstIndex int
 		dstLen   int
-		fillVal  byte
+		fillVal  rune
 		verify   func(t *testing.T, s *StrObject)
 	}{
 		{
@@ -320,7 +356,7 @@ func TestFillCopy(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			dst := NewBlankStrObject(MaxStrLen)
+			dst := NewBlankStrObject(testStrLen)
 			dst.FillCopy(src, tt.srcIndex, tt.srcLen, tt.dstIndex, tt.dstLen, tt.fillVal)
 			tt.verify(t, dst)
 		})
@@ -330,15 +366,15 @@ func TestFillCopy(t *testing.T) {
 	dst.FillCopy(src, 1, 5, 1, 0, '-')
 }
 
-// TestFillCopyBytes tests the FillCopyBytes method
-func TestFillCopyBytes(t *testing.T) {
-	src := []byte("Hello")
+// TestFillCopyRunes tests the FillCopyRunes method
+func TestFillCopyRunes(t *testing.T) {
+	src := []rune("Hello")
 
 	tests := []struct {
 		name     string
 		dstIndex int
 		dstLen   int
-		fillVal  byte
+		fillVal  rune
 		expected string
 	}{
 		{"exact fit", 10, 5, '-', "Hello"},
@@ -348,17 +384,17 @@ func TestFillCopyBytes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewBlankStrObject(MaxStrLen)
-			s.FillCopyBytes(src, tt.dstIndex, tt.dstLen, tt.fillVal)
+			s := NewBlankStrObject(testStrLen)
+			s.FillCopyRunes(src, tt.dstIndex, tt.dstLen, tt.fillVal)
 			got := s.Slice(tt.dstIndex, tt.dstLen)
-			assert.Equal(t, tt.expected, got, "FillCopyBytes() failed")
+			assert.Equal(t, tt.expected, got, "FillCopyRunes() failed")
 		})
 	}
 }
 
 // TestInsert tests the Insert method
 func TestInsert(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 	s.Assign("ABCDEFGH")
 
 	// Insert 3 spaces at position 4
@@ -384,19 +420,19 @@ func TestLength(t *testing.T) {
 	tests := []struct {
 		name     string
 		content  string
-		value    byte
+		value    rune
 		from     int
 		expected int
 	}{
-		{"string with trailing spaces", "Hello", ' ', MaxStrLen, 5},
-		{"all spaces", "", ' ', MaxStrLen, 0},
-		{"find last non-space after ABC", "ABC", ' ', MaxStrLen, 3},
+		{"string with trailing spaces", "Hello", ' ', testStrLen, 5},
+		{"all spaces", "", ' ', testStrLen, 0},
+		{"find last non-space after ABC", "ABC", ' ', testStrLen, 3},
 		{"search from middle", "Hello World", ' ', 50, 11},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewBlankStrObject(MaxStrLen)
+			s := NewBlankStrObject(testStrLen)
 			if tt.content != "" {
 				s.Assign(tt.content)
 			}
@@ -408,7 +444,7 @@ func TestLength(t *testing.T) {
 
 // TestSlice tests the Slice method
 func TestSlice(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 	s.Assign("Hello World")
 
 	tests := []struct {
@@ -433,10 +469,10 @@ func TestSlice(t *testing.T) {
 
 // TestString tests the String method
 func TestString(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
-	s.Fill('X', 1, MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
+	s.Fill('X', 1, testStrLen)
 	str := s.String()
-	assert.Len(t, str, MaxStrLen, "String() length mismatch")
+	assert.Len(t, str, testStrLen, "String() length mismatch")
 	assert.Equal(t, byte('X'), str[0], "String()[0] mismatch")
 }
 
@@ -455,7 +491,7 @@ func TestTrimmedString(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewBlankStrObject(MaxStrLen)
+			s := NewBlankStrObject(testStrLen)
 			if tt.content != "" {
 				s.Assign(tt.content)
 			}
@@ -481,10 +517,10 @@ func TestCompare(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s1 := NewBlankStrObject(MaxStrLen)
+			s1 := NewBlankStrObject(testStrLen)
 			s1.Assign(tt.content1)
 
-			s2 := NewBlankStrObject(MaxStrLen)
+			s2 := NewBlankStrObject(testStrLen)
 			s2.Assign(tt.content2)
 
 			got := s1.Compare(s2)
@@ -502,38 +538,64 @@ func TestCompare(t *testing.T) {
 
 // TestEqual tests the Equal method
 func TestEqual(t *testing.T) {
-	s1 := NewBlankStrObject(MaxStrLen)
-	s2 := NewBlankStrObject(MaxStrLen)
-	s3 := NewBlankStrObject(MaxStrLen)
-	s3.Fill('X', 1, MaxStrLen)
+	s1 := NewBlankStrObject(testStrLen)
+	s2 := NewBlankStrObject(testStrLen)
+	s3 := NewBlankStrObject(testStrLen)
+	s3.Fill('X', 1, testStrLen)
 
 	assert.True(t, s1.Equal(s2), "Equal objects not detected as equal")
 	assert.False(t, s1.Equal(s3), "Different objects detected as equal")
 
 	// Test with same content through Assign
-	s4 := NewBlankStrObject(MaxStrLen)
+	s4 := NewBlankStrObject(testStrLen)
 	s4.Assign("Test")
-	s5 := NewBlankStrObject(MaxStrLen)
+	s5 := NewBlankStrObject(testStrLen)
 	s5.Assign("Test")
 
 	assert.True(t, s4.Equal(s5), "Objects with same assigned content not equal")
 }
 
-// TestBytes tests the Bytes method
-func TestBytes(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
-	b := s.Bytes()
+// TestRunes tests the Runes method
+func TestRunes(t *testing.T) {
+	s := NewBlankStrObject(testStrLen)
+	b := s.Runes()
 
-	assert.Len(t, b, MaxStrLen, "Bytes() length mismatch")
+	assert.Len(t, b, testStrLen, "Runes() length mismatch")
 
 	// Verify it's a copy, not the original
 	b[0] = 'Y'
-	assert.NotEqual(t, byte('Y'), s.array[0], "Bytes() returned reference to internal array")
+	assert.NotEqual(t, rune('Y'), s.array[0], "Runes() returned reference to internal array")
+}
+
+// TestRuneSlice tests the RuneSlice method
+func TestRuneSlice(t *testing.T) {
+	s := NewBlankStrObject(testStrLen)
+	s.Assign("Hello")
+	s.Set(2, ChFromRawByte(0xe9))
+
+	b := s.RuneSlice(2, 3)
+	assert.Equal(t, []rune{ChFromRawByte(0xe9), 'l', 'l'}, b, "RuneSlice() mismatch")
+	assert.Nil(t, s.RuneSlice(1, 0), "RuneSlice() of nothing not nil")
+
+	// Verify it's a copy, not the original
+	b[0] = 'Y'
+	assert.NotEqual(t, rune('Y'), s.array[1], "RuneSlice() returned reference to internal array")
+	assert.Panics(t, func() { s.RuneSlice(testStrLen, 2) }, "RuneSlice() past the end did not panic")
+}
+
+// TestEqualsRunes tests the EqualsRunes method
+func TestEqualsRunes(t *testing.T) {
+	s := NewBlankStrObject(testStrLen)
+	s.Assign("Hello")
+
+	assert.True(t, s.EqualsRunes([]rune("ell"), 2), "EqualsRunes() failed for equal characters")
+	assert.False(t, s.EqualsRunes([]rune("elp"), 2), "EqualsRunes() succeeded for different characters")
+	assert.True(t, s.EqualsRunes(nil, 1), "EqualsRunes() failed for no characters")
 }
 
 // TestFormat tests the Format method
 func TestFormat(t *testing.T) {
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 	s.Assign("Hello")
 
 	tests := []struct {
@@ -542,7 +604,7 @@ func TestFormat(t *testing.T) {
 	}{
 		{"%s", "Hello"},
 		{"%v", "Hello"},
-		{"%q", fmt.Sprintf("%q", s.array[:])},
+		{"%q", fmt.Sprintf("%q", string(s.array[:]))},
 	}
 
 	for _, tt := range tests {
@@ -561,7 +623,7 @@ func TestCheckIndexOverflow(t *testing.T) {
 		}
 	}()
 
-	s := NewBlankStrObject(MaxStrLen)
+	s := NewBlankStrObject(testStrLen)
 	// This should cause an overflow panic
 	s.Get(math.MaxInt)
 }
@@ -14,15 +14,15 @@
 package ludwig
 
 // SwapLine swaps the current line with another line
-func SwapLine(rept LeadParam, count int) bool {
+func (e *Editor) SwapLine(rept LeadParam, count int) bool {
 	// SW is implemented as a ST of the dot line to before the other line.
 	result := false
 	var topMark *MarkObject
 	var endMark *MarkObject
 	var destMark *MarkObject
 
-	thisLine := CurrentFrame.Dot.Line
-	dotCol := CurrentFrame.Dot.Col
+	thisLine := e.CurrentFrame.Dot.Line
+	dotCol := e.CurrentFrame.Dot.Col
 	nextLine := thisLine.FLink
 	var destLine *LineHdrObject
 
@@ -48,11 +48,11 @@ func SwapLine(rept LeadParam, count int) bool {
 			}
 		}
 	case LeadParamPIndef:
-		destLine = CurrentFrame.LastGroup.LastLine
+		destLine = e.CurrentFrame.LastGroup.LastLine
 	case LeadParamNIndef:
-		destLine = CurrentFrame.FirstGroup.FirstLine
+		destLine = e.CurrentFrame.FirstGroup.FirstLine
 	case LeadParamMarker:
-		destLine = CurrentFrame.Marks[count].Line
+		destLine = e.CurrentFrame.Marks[count].Line
 	}
 
 	if !MarkCreate(thisLine, 1, &topMark) {
@@ -64,13 +64,13 @@ func SwapLine(rept LeadParam, count int) bool {
 	if !MarkCreate(destLine, 1, &destMark) {
 		goto l99
 	}
-	if !TextMove(false, 1, topMark, endMark, destMark, &CurrentFrame.Dot, &topMark) {
+	if !e.TextMove(false, 1, topMark, endMark, destMark, &e.CurrentFrame.Dot, &topMark) {
 		goto l99
 	}
-	CurrentFrame.TextModified = true
-	CurrentFrame.Dot.Col = dotCol
+	e.CurrentFrame.TextModified = true
+	e.CurrentFrame.Dot.Col = dotCol
 	if !MarkCreate(
-		CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &CurrentFrame.Marks[MarkModified],
+		e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Marks[MarkModified],
 	) {
 		goto l99
 	}
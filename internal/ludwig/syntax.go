@@ -0,0 +1,364 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         SYNTAX
+//
+// Description:  Syntax highlighting.  The rules for each kind of file are
+//               read from rule files, and the one whose patterns match the
+//               name of a frame's file is used to break the frame's lines
+//               into keywords, strings, comments and so on.  The state at
+//               the end of each line, such as being inside a comment, is
+//               kept in the line so that only lines that change, and the
+//               lines after them, need to be looked at again.
+
+package ludwig
+
+import (
+	"bufio"
+	"embed"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//go:embed syntax/*.syn
+var syntaxFiles embed.FS
+
+const (
+	syntaxDirEnv = "LUD_SYNTAXDIR" // Directory of rule files used before the built-in ones
+)
+
+// syntaxClassNames are the names used for the classes in rule files
+var syntaxClassNames = map[string]SyntaxClass{
+	"keyword":    SyntaxKeyword,
+	"type":       SyntaxType,
+	"literal":    SyntaxLiteral,
+	"string":     SyntaxString,
+	"longstring": SyntaxString,
+	"comment":    SyntaxComment,
+}
+
+// syntaxParse reads a rule file.  Each line is a directive followed by its
+// arguments, lines starting with ! are comments.
+func syntaxParse(r io.Reader) *SyntaxRules {
+	rules := &SyntaxRules{Words: make(map[string]SyntaxClass)}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "!") {
+			continue
+		}
+		args := fields[1:]
+		switch fields[0] {
+		case "name":
+			rules.Name = strings.Join(args, " ")
+		case "files":
+			rules.Files = append(rules.Files, args...)
+		case "ignorecase":
+			rules.IgnoreCase = true
+		case "numbers":
+			rules.Numbers = true
+		case "directive":
+			if len(args) > 0 {
+				rules.Directive = []rune(args[0])
+			}
+		case "keyword", "type", "literal":
+			for _, word := range args {
+				rules.Words[word] = syntaxClassNames[fields[0]]
+			}
+		case "comment", "string", "longstring":
+			if len(args) == 0 {
+				continue
+			}
+			region := SyntaxRegion{Class: syntaxClassNames[fields[0]], Start: []rune(args[0])}
+			if len(args) > 1 {
+				region.End = []rune(args[1])
+			}
+			if len(args) > 2 {
+				region.Escape = []rune(args[2])[0]
+			}
+			// Comments with an end delimiter, and long strings, can go on
+			// over several lines
+			region.Lines = fields[0] == "longstring" || (fields[0] == "comment" && len(region.End) > 0)
+			rules.Regions = append(rules.Regions, region)
+		}
+	}
+	if rules.IgnoreCase {
+		words := make(map[string]SyntaxClass, len(rules.Words))
+		for word, class := range rules.Words {
+			words[strings.ToLower(word)] = class
+		}
+		rules.Words = words
+	}
+	// Try the longest delimiters first, so that ``` is not taken as `
+	sort.SliceStable(rules.Regions, func(i, j int) bool {
+		return len(rules.Regions[i].Start) > len(rules.Regions[j].Start)
+	})
+	return rules
+}
+
+// syntaxLoadDir reads the rule files in a directory
+func (e *Editor) syntaxLoadDir(fsys fs.FS, dir string) {
+	names, err := fs.Glob(fsys, dir+"/*.syn")
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		file, err := fsys.Open(name)
+		if err != nil {
+			continue
+		}
+		e.syntaxRules = append(e.syntaxRules, syntaxParse(file))
+		file.Close()
+	}
+}
+
+// syntaxLoad reads the rule files, those in the directory named by
+// LUD_SYNTAXDIR are tried before the built-in ones.
+func (e *Editor) syntaxLoad() {
+	e.syntaxLoaded = true
+	if dir := os.Getenv(syntaxDirEnv); dir != "" {
+		e.syntaxLoadDir(os.DirFS(dir), ".")
+	}
+	e.syntaxLoadDir(syntaxFiles, "syntax")
+}
+
+// syntaxMatch finds the rules for a file name, nil if there are none
+func (e *Editor) syntaxMatch(fileName string) *SyntaxRules {
+	if fileName == "" {
+		return nil
+	}
+	if !e.syntaxLoaded {
+		e.syntaxLoad()
+	}
+	base := filepath.Base(fileName)
+	for _, rules := range e.syntaxRules {
+		for _, pattern := range rules.Files {
+			if matched, _ := filepath.Match(pattern, base); matched {
+				return rules
+			}
+		}
+	}
+	return nil
+}
+
+// SyntaxRulesFor returns the highlighting rules for a frame, or nil if the
+// frame is not highlighted.  The rules are chosen again whenever the name
+// of the frame's file changes.
+func (e *Editor) SyntaxRulesFor(frame *FrameObject) *SyntaxRules {
+	if !frame.Options.Has(OptHighlight) {
+		return nil
+	}
+	fileName := e.FrameFileName(frame)
+	if fileName != frame.SyntaxFile {
+		frame.SyntaxFile = fileName
+		frame.Syntax = e.syntaxMatch(fileName)
+		frame.SyntaxValidNr = 0
+	}
+	return frame.Syntax
+}
+
+// SyntaxChange is called before the text of a line is changed, or lines
+// are inserted before it or removed starting at it.  The states of this
+// line and the lines after it have to be worked out again.
+func SyntaxChange(line *LineHdrObject) {
+	if line.Group == nil {
+		return
+	}
+	frame := line.Group.Frame
+	var lineNr int
+	if LineToNumber(line, &lineNr) && lineNr <= frame.SyntaxValidNr {
+		frame.SyntaxValidNr = lineNr - 1
+	}
+}
+
+// syntaxIsWord returns true for the characters that make up words
+func syntaxIsWord(ch rune) bool {
+	return ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch)
+}
+
+// syntaxHasPrefix returns true if text at pos starts with prefix
+func syntaxHasPrefix(text []rune, pos int, prefix []rune) bool {
+	return len(prefix) > 0 && len(text)-pos >= len(prefix) && slices.Equal(text[pos:pos+len(prefix)], prefix)
+}
+
+// syntaxRegionEnd finds the end of a region starting at pos.  It returns
+// the position just after the region, and true if the region ended on
+// this line.
+func syntaxRegionEnd(region *SyntaxRegion, text []rune, pos int) (int, bool) {
+	for pos < len(text) {
+		if region.Escape != 0 && text[pos] == region.Escape {
+			pos += 2
+			continue
+		}
+		if syntaxHasPrefix(text, pos, region.End) {
+			return pos + len(region.End), true
+		}
+		pos++
+	}
+	return len(text), len(region.End) == 0
+}
+
+// SyntaxTokenize breaks a line of text into runs, starting in the given
+// state, and returns the state at the end of the line.  runs may be nil
+// if only the state is wanted.
+func SyntaxTokenize(rules *SyntaxRules, state uint8, text []rune, runs *[]SyntaxRun) uint8 {
+	add := func(start int, end int, class SyntaxClass) {
+		if runs != nil && end > start {
+			*runs = append(*runs, SyntaxRun{Col: start + 1, Len: end - start, Class: class})
+		}
+	}
+
+	pos := 0
+	if state > 0 && int(state) <= len(rules.Regions) {
+		region := &rules.Regions[state-1]
+		end, closed := syntaxRegionEnd(region, text, 0)
+		add(0, end, region.Class)
+		if !closed {
+			return state
+		}
+		pos = end
+	} else if len(rules.Directive) > 0 {
+		indent := 0
+		for indent < len(text) && (text[indent] == ' ' || text[indent] == '\t') {
+			indent++
+		}
+		if syntaxHasPrefix(text, indent, rules.Directive) {
+			add(indent, len(text), SyntaxDirective)
+			return 0
+		}
+	}
+
+	for pos < len(text) {
+		regionFound := false
+		for i := range rules.Regions {
+			region := &rules.Regions[i]
+			if !syntaxHasPrefix(text, pos, region.Start) {
+				continue
+			}
+			end, closed := syntaxRegionEnd(region, text, pos+len(region.Start))
+			add(pos, end, region.Class)
+			if !closed {
+				if region.Lines {
+					return uint8(i + 1)
+				}
+				return 0
+			}
+			pos = end
+			regionFound = true
+			break
+		}
+		if regionFound {
+			continue
+		}
+
+		ch := text[pos]
+		switch {
+		case rules.Numbers && unicode.IsDigit(ch):
+			end := pos + 1
+			for end < len(text) && (syntaxIsWord(text[end]) || text[end] == '.') {
+				end++
+			}
+			add(pos, end, SyntaxLiteral)
+			pos = end
+		case syntaxIsWord(ch):
+			end := pos + 1
+			for end < len(text) && syntaxIsWord(text[end]) {
+				end++
+			}
+			word := string(text[pos:end])
+			if rules.IgnoreCase {
+				word = strings.ToLower(word)
+			}
+			if class, ok := rules.Words[word]; ok {
+				add(pos, end, class)
+			}
+			pos = end
+		default:
+			pos++
+		}
+	}
+	return 0
+}
+
+// syntaxText returns the text of a line
+func syntaxText(line *LineHdrObject) []rune {
+	if line.Used == 0 {
+		return nil
+	}
+	return line.Str.Runes()[:line.Used]
+}
+
+// syntaxStart returns the highlighting state at the start of a line.  The
+// states of any lines before it that are not known are worked out first.
+func syntaxStart(rules *SyntaxRules, line *LineHdrObject) uint8 {
+	frame := line.Group.Frame
+	var lineNr int
+	LineToNumber(line, &lineNr)
+	if lineNr-1 > frame.SyntaxValidNr {
+		var thisLine *LineHdrObject
+		LineFromNumber(frame, frame.SyntaxValidNr+1, &thisLine)
+		var state uint8
+		if thisLine.BLink != nil {
+			state = thisLine.BLink.SyntaxEnd
+		}
+		for thisLine != line {
+			state = SyntaxTokenize(rules, state, syntaxText(thisLine), nil)
+			thisLine.SyntaxEnd = state
+			thisLine = thisLine.FLink
+		}
+		frame.SyntaxValidNr = lineNr - 1
+	}
+	if line.BLink == nil {
+		return 0
+	}
+	return line.BLink.SyntaxEnd
+}
+
+// SyntaxLine returns the runs to highlight in a line, nil if the line is
+// not highlighted.  The state the line was drawn from is noted in the line.
+func (e *Editor) SyntaxLine(line *LineHdrObject) []SyntaxRun {
+	if line.FLink == nil || line.Group == nil {
+		return nil
+	}
+	rules := e.SyntaxRulesFor(line.Group.Frame)
+	if rules == nil {
+		return nil
+	}
+	var runs []SyntaxRun
+	line.SyntaxShown = syntaxStart(rules, line)
+	SyntaxTokenize(rules, line.SyntaxShown, syntaxText(line), &runs)
+	return runs
+}
+
+// SyntaxFixLines returns the lines from first to last that were drawn
+// from a highlighting state that has since changed, because lines before
+// them have been changed.
+func (e *Editor) SyntaxFixLines(first *LineHdrObject, last *LineHdrObject) []*LineHdrObject {
+	rules := e.SyntaxRulesFor(first.Group.Frame)
+	if rules == nil {
+		return nil
+	}
+	var stale []*LineHdrObject
+	for line := first; line != nil && line.FLink != nil; line = line.FLink {
+		if syntaxStart(rules, line) != line.SyntaxShown {
+			stale = append(stale, line)
+		}
+		if line == last {
+			break
+		}
+	}
+	return stale
+}
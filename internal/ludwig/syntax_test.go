@@ -0,0 +1,130 @@
+// Tests for syntax.go functions
+
+package ludwig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntaxTestRules parses rules given as the text of a rule file
+func syntaxTestRules(text string) *SyntaxRules {
+	return syntaxParse(strings.NewReader(text))
+}
+
+// syntaxRuns tokenizes a line of text, returning the runs as strings of
+// the form "class:text" and the state at the end of the line
+func syntaxRuns(rules *SyntaxRules, state uint8, line string) ([]string, uint8) {
+	var runs []SyntaxRun
+	text := []rune(line)
+	state = SyntaxTokenize(rules, state, text, &runs)
+	result := []string{}
+	for _, run := range runs {
+		result = append(result, string(rune('0'+run.Class))+":"+string(text[run.Col-1:run.Col-1+run.Len]))
+	}
+	return result, state
+}
+
+func TestSyntaxTokenize(t *testing.T) {
+	rules := syntaxTestRules(`! A test language
+keyword   func return
+type      int
+literal   nil
+numbers
+comment   //
+comment   /* */
+string    " " \
+longstring ` + "` `\n")
+
+	runs, state := syntaxRuns(rules, 0, `func f() int { return "a\"b" + 12 // done`)
+	assert.Equal(t, []string{"1:func", "2:int", "1:return", `4:"a\"b"`, "3:12", "5:// done"}, runs)
+	assert.Equal(t, uint8(0), state)
+
+	// Words only match as a whole, numbers inside words are not numbers
+	runs, _ = syntaxRuns(rules, 0, "funcs x2 nil")
+	assert.Equal(t, []string{"3:nil"}, runs)
+
+	// A block comment carries on to the next line
+	runs, state = syntaxRuns(rules, 0, "x /* one")
+	assert.Equal(t, []string{"5:/* one"}, runs)
+	require.NotEqual(t, uint8(0), state)
+	runs, state = syntaxRuns(rules, state, "two */ return")
+	assert.Equal(t, []string{"5:two */", "1:return"}, runs)
+	assert.Equal(t, uint8(0), state)
+
+	// Strings end at the end of the line, long strings do not
+	_, state = syntaxRuns(rules, 0, `"open`)
+	assert.Equal(t, uint8(0), state)
+	_, state = syntaxRuns(rules, 0, "`open")
+	assert.NotEqual(t, uint8(0), state)
+}
+
+func TestSyntaxParseOptions(t *testing.T) {
+	rules := syntaxTestRules(`name Pascal
+files *.pas *.p
+ignorecase
+keyword BEGIN end
+directive #
+longstring ` + "``` ```\nstring ` `\n")
+
+	assert.Equal(t, "Pascal", rules.Name)
+	assert.Equal(t, []string{"*.pas", "*.p"}, rules.Files)
+
+	runs, _ := syntaxRuns(rules, 0, "Begin x END")
+	assert.Equal(t, []string{"1:Begin", "1:END"}, runs)
+
+	runs, _ = syntaxRuns(rules, 0, "  # heading")
+	assert.Equal(t, []string{"6:# heading"}, runs)
+
+	// The longest delimiter is tried first
+	runs, state := syntaxRuns(rules, 0, "```go")
+	assert.Equal(t, []string{"4:```go"}, runs)
+	assert.NotEqual(t, uint8(0), state)
+}
+
+func TestSyntaxMatch(t *testing.T) {
+	e := NewEditor()
+	t.Setenv(syntaxDirEnv, "")
+	e.syntaxLoaded = false
+	e.syntaxRules = nil
+	t.Cleanup(func() {
+		e.syntaxLoaded = false
+		e.syntaxRules = nil
+	})
+
+	for _, name := range []string{"main.go", "/usr/src/x.c", "ludwig.pas", "build.sh", "README.md"} {
+		assert.NotNil(t, e.syntaxMatch(name), name)
+	}
+	assert.Equal(t, "Go", e.syntaxMatch("dir/main.go").Name)
+	assert.Nil(t, e.syntaxMatch("notes.txt"))
+	assert.Nil(t, e.syntaxMatch(""))
+}
+
+func TestSyntaxLineStates(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "a /* b", "c", "d */ e", "f")
+	frame.Options.Set(OptHighlight)
+	frame.Syntax = syntaxTestRules("comment /* */\nkeyword f\n")
+
+	var line *LineHdrObject
+	require.True(t, LineFromNumber(frame, 4, &line))
+	assert.Equal(t, []SyntaxRun{{Col: 1, Len: 1, Class: SyntaxKeyword}}, e.SyntaxLine(line))
+	assert.Equal(t, 3, frame.SyntaxValidNr)
+
+	// Closing the comment early changes how the following lines are shown
+	first := frame.FirstGroup.FirstLine
+	for l := first; l.FLink != nil; l = l.FLink {
+		e.SyntaxLine(l)
+	}
+	assert.Empty(t, e.SyntaxFixLines(first, line))
+	require.True(t, e.undoSetLine(first, []rune("a /* b */")))
+	assert.Equal(t, 0, frame.SyntaxValidNr)
+	assert.Equal(t, []*LineHdrObject{first.FLink, first.FLink.FLink}, e.SyntaxFixLines(first, line))
+
+	// Highlighting can be turned off
+	frame.Options.Clear(OptHighlight)
+	assert.Nil(t, e.SyntaxLine(line))
+}
@@ -19,20 +19,42 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"os/user"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"golang.org/x/term"
 )
 
 const (
 	nl = "\n"
+
+	// sysGrace is how long Ludwig is given to start winding up after
+	// SIGHUP or SIGTERM, before it exits anyway
+	sysGrace = 5 * time.Second
+)
+
+// Flags set by the signal handler for SysCheckSignals to act on
+var (
+	sysTerminating atomic.Bool // SIGHUP or SIGTERM has arrived
+	sysWindingUp   atomic.Bool // SysWindup has been called
+	sysStop        atomic.Bool // SIGTSTP has arrived
+	sysContinued   atomic.Bool // SIGCONT has arrived
 )
 
+// sysFileFd remembers a file and returns its number
+func (e *Editor) sysFileFd(f *os.File) int {
+	fd := int(f.Fd())
+	e.sysFiles[fd] = f
+	return fd
+}
+
 // FileStatus represents file status information
 type FileStatus struct {
 	Valid bool
@@ -41,10 +63,14 @@ type FileStatus struct {
 	IsDir bool
 }
 
-// SysSuspend sends SIGTSTP to suspend the process
-func SysSuspend() bool {
-	pid := os.Getpid()
-	return syscall.Kill(pid, syscall.SIGTSTP) == nil
+// SysSuspend suspends the process, giving the terminal back to the shell
+// until the process is continued
+func (e *Editor) SysSuspend() bool {
+	e.VduSuspend()
+	// SIGTSTP is caught, so stop with SIGSTOP instead
+	err := syscall.Kill(os.Getpid(), syscall.SIGSTOP)
+	e.VduResume()
+	return err == nil
 }
 
 // SysShell launches a shell
@@ -68,9 +94,68 @@ func SysGetEnv(environ string, result *string) bool {
 	return true
 }
 
-// SysInitSig initializes signal handlers
-func SysInitSig() {
-	// Nothing yet
+// sysSignals receives the signals Ludwig handles
+var sysSignals = make(chan os.Signal, 8)
+
+// SysInitSig initializes signal handlers.  Signals are sent to the whole
+// process, so only the editor using the terminal should ask for them.  The
+// handler runs alongside the editor, so it only sets flags.  TtControlC and
+// TtWinChanged are looked at by the editor as it goes, the rest are acted
+// on by SysCheckSignals.
+func (e *Editor) SysInitSig() {
+	signal.Notify(sysSignals, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGINT,
+		syscall.SIGWINCH, syscall.SIGTSTP, syscall.SIGCONT)
+	go e.sysHandleSignals()
+}
+
+// sysHandleSignals sets the flags for each signal as it arrives
+func (e *Editor) sysHandleSignals() {
+	for sig := range sysSignals {
+		switch sig {
+		case syscall.SIGINT:
+			e.TtControlC.Store(true)
+		case syscall.SIGWINCH:
+			e.TtWinChanged.Store(true)
+		case syscall.SIGTSTP:
+			sysStop.Store(true)
+		case syscall.SIGCONT:
+			sysContinued.Store(true)
+		case syscall.SIGHUP, syscall.SIGTERM:
+			if sysTerminating.Swap(true) {
+				continue
+			}
+			// Abandon the command being done, so that the editor gets
+			// to SysCheckSignals, which writes the journals and the files.
+			// The frames belong to the editor, so if it does not get there
+			// in time, perhaps because it is waiting for batch input, go
+			// with the journals it last wrote.
+			e.TtControlC.Store(true)
+			time.AfterFunc(sysGrace, func() {
+				if !sysWindingUp.Load() {
+					SysExitFailure()
+				}
+			})
+		}
+	}
+}
+
+// SysCheckSignals acts on signals that have arrived since it was last
+// called.  It is called where the frames are in a fit state to be written
+// out, and does not return if Ludwig has been told to stop.
+func (e *Editor) SysCheckSignals() {
+	if sysTerminating.Load() && !sysWindingUp.Swap(true) {
+		e.VduFree()
+		if e.SysWindup != nil {
+			e.SysWindup(true)
+		}
+		SysExitFailure()
+	}
+	if sysStop.Swap(false) {
+		e.SysSuspend()
+	}
+	if sysContinued.Swap(false) {
+		e.VduResume()
+	}
 }
 
 // SysExitSuccess exits with success status
@@ -142,7 +227,7 @@ func SysCopyFilename(srcPath string, dstPath *string) bool {
 }
 
 // SysOpenCommand opens a pipe to a command
-func SysOpenCommand(cmd string) int {
+func (e *Editor) SysOpenCommand(cmd string) int {
 	command := exec.Command("sh", "-c", cmd)
 
 	stdout, err := command.StdoutPipe()
@@ -158,28 +243,87 @@ func SysOpenCommand(cmd string) int {
 
 	// Convert the pipe to a file descriptor
 	if f, ok := stdout.(*os.File); ok {
-		return int(f.Fd())
+		return e.sysFileFd(f)
 	}
 
 	return -1
 }
 
+// SysFilter runs a command with input as its standard input, and returns
+// what it writes to standard output and standard error, and its exit
+// status.  If a signal killed the command its name is returned too.  It
+// fails if the command cannot be run at all, with the reason as the error
+// output and a status of -1.
+func SysFilter(cmd string, input string, output *string, errors *string, status *int, signal *string) bool {
+	command := exec.Command("sh", "-c", cmd)
+	command.Stdin = strings.NewReader(input)
+	var stdout, stderr strings.Builder
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	err := command.Run()
+	*output = stdout.String()
+	*errors = stderr.String()
+	return sysExitStatus(err, errors, status, signal)
+}
+
+// SysCommand runs a command with no input, and returns what it writes to
+// standard output and standard error interleaved as it wrote them, and its
+// exit status.  If a signal killed the command its name is returned too.
+// It fails if the command cannot be run at all, with the reason as the
+// output and a status of -1.
+func SysCommand(cmd string, output *string, status *int, signal *string) bool {
+	command := exec.Command("sh", "-c", cmd)
+	var combined strings.Builder
+	command.Stdout = &combined
+	command.Stderr = &combined
+
+	err := command.Run()
+	*output = combined.String()
+	return sysExitStatus(err, output, status, signal)
+}
+
+// sysExitStatus sets the exit status of a command that has been run, from
+// the error running it returned.  A command killed by a signal has the
+// status the shell gives it, 128 plus the signal number, and the signal's
+// name.  If the command could not be run at all, the reason goes in errors
+// and the status is -1.
+func sysExitStatus(err error, errors *string, status *int, signal *string) bool {
+	*signal = ""
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			*status = 128 + int(ws.Signal())
+			*signal = ws.Signal().String()
+			return true
+		}
+		*status = exitErr.ExitCode()
+		return true
+	}
+	if err != nil {
+		*errors = err.Error()
+		*status = -1
+		return false
+	}
+	*status = 0
+	return true
+}
+
 // SysOpenFile opens a file for reading
-func SysOpenFile(filename string) int {
+func (e *Editor) SysOpenFile(filename string) int {
 	f, err := os.Open(filename)
 	if err != nil {
 		return -1
 	}
-	return int(f.Fd())
+	return e.sysFileFd(f)
 }
 
 // SysCreateFile creates a file for reading and writing
-func SysCreateFile(filename string) int {
+func (e *Editor) SysCreateFile(filename string) int {
 	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
 		return -1
 	}
-	return int(f.Fd())
+	return e.sysFileFd(f)
 }
 
 // SysFileMask returns the current file creation mask
@@ -267,8 +411,14 @@ func SysWrite(fd int, buf []byte) int64 {
 }
 
 // SysClose closes a file descriptor
-func SysClose(fd int) int {
-	err := syscall.Close(fd)
+func (e *Editor) SysClose(fd int) int {
+	var err error
+	if f, ok := e.sysFiles[fd]; ok {
+		delete(e.sysFiles, fd)
+		err = f.Close()
+	} else {
+		err = syscall.Close(fd)
+	}
 	if err != nil {
 		return -1
 	}
@@ -58,10 +58,10 @@ func TextReturnCol(curLine *LineHdrObject, curCol int, splitting bool) int {
 }
 
 // TextRealizeNull converts a null line into a real line
-func TextRealizeNull(oldNull *LineHdrObject) bool {
+func (e *Editor) TextRealizeNull(oldNull *LineHdrObject) bool {
 	var newNull *LineHdrObject
 	if LinesCreate(1, &newNull, &newNull) {
-		if LinesInject(newNull, newNull, oldNull) {
+		if e.LinesInject(newNull, newNull, oldNull) {
 			if MarksShift(oldNull, 1, MaxStrLenP, newNull, 1) {
 				newNull.Group.Frame.TextModified = true
 				if MarkCreate(
@@ -78,7 +78,7 @@ func TextRealizeNull(oldNull *LineHdrObject) bool {
 }
 
 // TextInsert inserts text at a specified position
-func TextInsert(
+func (e *Editor) TextInsert(
 	updateScreen bool,
 	count int,
 	buf *StrObject,
@@ -87,6 +87,9 @@ func TextInsert(
 ) bool {
 	insertLen := count * bufLen
 	if insertLen > 0 {
+		rec := e.undoBegin()
+		defer e.undoEnd(rec)
+
 		dstLine := dst.Line
 		dstCol := dst.Col
 
@@ -100,8 +103,9 @@ func TextInsert(
 		if finalLen > MaxStrLen {
 			return false
 		}
+		rec.capture(dstLine, 1)
 		if dstLine.FLink == nil {
-			if !TextRealizeNull(dstLine) {
+			if !e.TextRealizeNull(dstLine) {
 				return false
 			}
 			dstLine = dstLine.BLink
@@ -135,13 +139,15 @@ func TextInsert(
 		}
 
 		// Update screen if necessary
-		if updateScreen && dstLine.ScrRowNr != 0 {
+		if updateScreen && dstLine.ScrRowNr != 0 && !screenNarrow(dstLine) {
+			e.ScreenDrawLine(dstLine)
+		} else if updateScreen && dstLine.ScrRowNr != 0 {
 			scrCol := dstCol - dstLine.Group.Frame.ScrOffset
 			if scrCol <= dstLine.Group.Frame.ScrWidth {
 				if scrCol <= 0 {
 					scrCol = 1
 				}
-				VduMoveCurs(scrCol, dstLine.ScrRowNr)
+				e.VduMoveCurs(scrCol, dstLine.ScrRowNr)
 				firstColRedraw := dstCol
 				var lastColRedraw int
 				if firstColRedraw <= dstLine.Group.Frame.ScrOffset {
@@ -149,7 +155,7 @@ func TextInsert(
 				}
 				if (scrCol+insertLen <= dstLine.Group.Frame.ScrWidth) &&
 					(scrCol+dstLine.Group.Frame.ScrOffset <= dstLine.Used) {
-					VduInsertChars(insertLen)
+					e.VduInsertChars(insertLen)
 					lastColRedraw = firstColRedraw + insertLen - 1
 				} else {
 					lastColRedraw = dstLine.Used
@@ -159,7 +165,7 @@ func TextInsert(
 				}
 				lenRedraw := lastColRedraw - firstColRedraw + 1
 				if lenRedraw > 0 {
-					VduDisplayStr(dstLine.Str.Slice(firstColRedraw, lenRedraw), 0)
+					e.VduDisplayStr(dstLine.Str.Slice(firstColRedraw, lenRedraw), 0)
 				}
 			}
 		}
@@ -168,7 +174,7 @@ func TextInsert(
 }
 
 // TextOvertype overtypes text at a specified position
-func TextOvertype(
+func (e *Editor) TextOvertype(
 	updateScreen bool,
 	count int,
 	buf *StrObject,
@@ -177,13 +183,17 @@ func TextOvertype(
 ) bool {
 	overtypeLen := count * bufLen
 	if overtypeLen > 0 {
+		rec := e.undoBegin()
+		defer e.undoEnd(rec)
+
 		dstLine := dst.Line
 		finalLen := dst.Col + overtypeLen - 1
 		if finalLen > MaxStrLen {
 			return false
 		}
+		rec.capture(dstLine, 1)
 		if dstLine.FLink == nil {
-			if !TextRealizeNull(dst.Line) {
+			if !e.TextRealizeNull(dst.Line) {
 				return false
 			}
 			dstLine = dstLine.BLink
@@ -194,6 +204,7 @@ func TextOvertype(
 				return false
 			}
 		}
+		narrow := screenNarrow(dstLine)
 		newCol := dst.Col
 		for i := 0; i < count; i++ {
 			dstLine.Str.Copy(buf, 1, bufLen, newCol)
@@ -206,7 +217,9 @@ func TextOvertype(
 		}
 
 		// Update screen if necessary
-		if updateScreen && dstLine.ScrRowNr != 0 {
+		if updateScreen && dstLine.ScrRowNr != 0 && (!narrow || !screenNarrow(dstLine)) {
+			e.ScreenDrawLine(dstLine)
+		} else if updateScreen && dstLine.ScrRowNr != 0 {
 			firstColOnScr := dst.Col
 			if firstColOnScr <= dstLine.Group.Frame.ScrOffset {
 				firstColOnScr = dstLine.Group.Frame.ScrOffset + 1
@@ -218,8 +231,8 @@ func TextOvertype(
 
 			lenOnScr := lastColOnScr - firstColOnScr
 			if lenOnScr > 0 {
-				VduMoveCurs(firstColOnScr-dstLine.Group.Frame.ScrOffset, dstLine.ScrRowNr)
-				VduDisplayStr(dstLine.Str.Slice(firstColOnScr, lenOnScr), 0)
+				e.VduMoveCurs(firstColOnScr-dstLine.Group.Frame.ScrOffset, dstLine.ScrRowNr)
+				e.VduDisplayStr(dstLine.Str.Slice(firstColOnScr, lenOnScr), 0)
 			}
 		}
 
@@ -230,7 +243,7 @@ func TextOvertype(
 }
 
 // TextInsertTpar inserts a trailing parameter at a mark position
-func TextInsertTpar(tp *TParObject, beforeMark *MarkObject, equalsMark **MarkObject) bool {
+func (e *Editor) TextInsertTpar(tp *TParObject, beforeMark *MarkObject, equalsMark **MarkObject) bool {
 	result := false
 	var firstLine *LineHdrObject
 	var lastLine *LineHdrObject
@@ -238,8 +251,8 @@ func TextInsertTpar(tp *TParObject, beforeMark *MarkObject, equalsMark **MarkObj
 
 	// Check for the simple case
 	if tp.Con == nil {
-		if !TextInsert(true, 1, tp.Str, tp.Len, beforeMark) {
-			ScreenMessage(MsgNoRoomOnLine)
+		if !e.TextInsert(true, 1, tp.Str, tp.Len, beforeMark) {
+			e.ScreenMessage(MsgNoRoomOnLine)
 			return false
 		}
 		if !MarkCreate(beforeMark.Line, beforeMark.Col-tp.Len, equalsMark) {
@@ -247,7 +260,7 @@ func TextInsertTpar(tp *TParObject, beforeMark *MarkObject, equalsMark **MarkObj
 		}
 	} else {
 		if beforeMark.Col+tp.Len > MaxStrLen {
-			ScreenMessage(MsgNoRoomOnLine)
+			e.ScreenMessage(MsgNoRoomOnLine)
 			return false
 		}
 		lineCount := 0
@@ -257,20 +270,20 @@ func TextInsertTpar(tp *TParObject, beforeMark *MarkObject, equalsMark **MarkObj
 			tmpTp = tmpTp.Con
 		}
 		if tmpTp.Len+(beforeMark.Line.Used-beforeMark.Col) > MaxStrLen {
-			ScreenMessage(MsgNoRoomOnLine)
+			e.ScreenMessage(MsgNoRoomOnLine)
 			return false
 		}
 		if !LinesCreate(lineCount, &firstLine, &lastLine) {
 			return false
 		}
 		discard = true
-		if beforeMark.Line.FLink == nil && !TextRealizeNull(beforeMark.Line) {
+		if beforeMark.Line.FLink == nil && !e.TextRealizeNull(beforeMark.Line) {
 			goto cleanup
 		}
-		if !TextSplitLine(beforeMark, 1, equalsMark) {
+		if !e.TextSplitLine(beforeMark, 1, equalsMark) {
 			goto cleanup
 		}
-		if !TextInsert(true, 1, tp.Str, tp.Len, *equalsMark) {
+		if !e.TextInsert(true, 1, tp.Str, tp.Len, *equalsMark) {
 			goto cleanup
 		}
 		(*equalsMark).Col -= tp.Len
@@ -290,12 +303,12 @@ func TextInsertTpar(tp *TParObject, beforeMark *MarkObject, equalsMark **MarkObj
 			tmpLine = tmpLine.FLink
 		}
 		if lineCount != 0 {
-			if !LinesInject(firstLine, lastLine, beforeMark.Line) {
+			if !e.LinesInject(firstLine, lastLine, beforeMark.Line) {
 				goto cleanup
 			}
 		}
 		discard = false
-		if !TextInsert(true, 1, tmpTp.Str, tmpTp.Len, beforeMark) {
+		if !e.TextInsert(true, 1, tmpTp.Str, tmpTp.Len, beforeMark) {
 			return false
 		}
 	}
@@ -308,7 +321,7 @@ cleanup:
 }
 
 // textIntraRemove removes text within a single line
-func textIntraRemove(markOne *MarkObject, size int) bool {
+func (e *Editor) textIntraRemove(markOne *MarkObject, size int) bool {
 	ln := markOne.Line
 	colOne := markOne.Col
 	colTwo := colOne + size
@@ -326,6 +339,7 @@ func textIntraRemove(markOne *MarkObject, size int) bool {
 	if colOne > oldUsed {
 		return true
 	}
+	narrow := screenNarrow(ln)
 	dstLen := oldUsed + 1 - colOne
 	if colTwo <= oldUsed {
 		// Shift data down
@@ -348,6 +362,10 @@ func textIntraRemove(markOne *MarkObject, size int) bool {
 	if ln.ScrRowNr == 0 {
 		return true
 	}
+	if !narrow {
+		e.ScreenDrawLine(ln)
+		return true
+	}
 	offsetPWidth := ln.Group.Frame.ScrOffset + ln.Group.Frame.ScrWidth
 	if colOne > offsetPWidth {
 		return true
@@ -360,8 +378,8 @@ func textIntraRemove(markOne *MarkObject, size int) bool {
 
 	// If possible, drag any characters on screen to final place
 	if (firstColOnScr+distance <= offsetPWidth) && (firstColOnScr+distance <= oldUsed) {
-		VduMoveCurs(firstColOnScr-ln.Group.Frame.ScrOffset, ln.ScrRowNr)
-		VduDeleteChars(distance)
+		e.VduMoveCurs(firstColOnScr-ln.Group.Frame.ScrOffset, ln.ScrRowNr)
+		e.VduDeleteChars(distance)
 		firstColOnScr = offsetPWidth + 1 - distance
 		if firstColOnScr > ln.Used {
 			return true
@@ -369,7 +387,7 @@ func textIntraRemove(markOne *MarkObject, size int) bool {
 	}
 
 	// Fix the remainder of the line's appearance on the screen
-	VduMoveCurs(firstColOnScr-ln.Group.Frame.ScrOffset, ln.ScrRowNr)
+	e.VduMoveCurs(firstColOnScr-ln.Group.Frame.ScrOffset, ln.ScrRowNr)
 	var bufLen int
 	if ln.Used <= ln.Group.Frame.ScrOffset+ln.Group.Frame.ScrWidth {
 		bufLen = ln.Used + 1 - firstColOnScr
@@ -377,22 +395,22 @@ func textIntraRemove(markOne *MarkObject, size int) bool {
 		bufLen = ln.Group.Frame.ScrOffset + ln.Group.Frame.ScrWidth + 1 - firstColOnScr
 	}
 	if bufLen <= 0 {
-		VduClearEOL()
+		e.VduClearEOL()
 	} else {
-		VduDisplayStr(ln.Str.Slice(firstColOnScr, bufLen), 3)
+		e.VduDisplayStr(ln.Str.Slice(firstColOnScr, bufLen), 3)
 	}
 	return true
 }
 
 // textInterRemove removes text spanning multiple lines
-func textInterRemove(markOne *MarkObject, markTwo *MarkObject) bool {
+func (e *Editor) textInterRemove(markOne *MarkObject, markTwo *MarkObject) bool {
 	result := false
 	var markStart *MarkObject
 	var extrOne *LineHdrObject
 	var extrTwo *LineHdrObject
 	var textLen int
-	strng := NewBlankStrObject(MaxStrLen)
-	strngTail := NewBlankStrObject(MaxStrLen)
+	strng := EmptyStrObject()
+	strngTail := EmptyStrObject()
 	var delta int
 	var lineOne *LineHdrObject
 	var colOne int
@@ -402,7 +420,7 @@ func textInterRemove(markOne *MarkObject, markTwo *MarkObject) bool {
 		colOne = markOne.Col
 		extrOne = lineOne.FLink
 		extrTwo = markTwo.Line
-		if !textIntraRemove(markOne, MaxStrLenP-markOne.Col) {
+		if !e.textIntraRemove(markOne, MaxStrLenP-markOne.Col) {
 			goto cleanup
 		}
 		if !MarksSqueeze(lineOne, colOne, markTwo.Line, markTwo.Col) {
@@ -432,12 +450,12 @@ func textInterRemove(markOne *MarkObject, markTwo *MarkObject) bool {
 		if !MarkCreate(markTwo.Line, markOne.Col, &markStart) {
 			goto cleanup
 		}
-		if !textIntraRemove(markStart, markTwo.Col-markStart.Col) {
+		if !e.textIntraRemove(markStart, markTwo.Col-markStart.Col) {
 			goto cleanup
 		}
 	} else if delta > 0 {
 		strngTail.Copy(strng, markTwo.Col, delta, 1)
-		if !TextInsert(true, 1, strngTail, delta, markTwo) {
+		if !e.TextInsert(true, 1, strngTail, delta, markTwo) {
 			goto cleanup
 		}
 		textLen -= delta
@@ -446,7 +464,7 @@ func textInterRemove(markOne *MarkObject, markTwo *MarkObject) bool {
 		goto cleanup
 	}
 	if textLen > 0 {
-		if !TextOvertype(true, 1, strng, textLen, markStart) {
+		if !e.TextOvertype(true, 1, strng, textLen, markStart) {
 			goto cleanup
 		}
 	}
@@ -462,7 +480,7 @@ func textInterRemove(markOne *MarkObject, markTwo *MarkObject) bool {
 		}
 	}
 extract:
-	if !LinesExtract(extrOne, extrTwo) {
+	if !e.LinesExtract(extrOne, extrTwo) {
 		goto cleanup
 	}
 	if !LinesDestroy(&extrOne, &extrTwo) {
@@ -478,15 +496,21 @@ cleanup:
 }
 
 // TextRemove removes text between two marks
-func TextRemove(markOne *MarkObject, markTwo *MarkObject) bool {
+func (e *Editor) TextRemove(markOne *MarkObject, markTwo *MarkObject) bool {
+	rec := e.undoBegin()
+	defer e.undoEnd(rec)
+	if markOne.Line != markTwo.Line || markOne.Col != markTwo.Col {
+		rec.captureBetween(markOne.Line, markTwo.Line)
+	}
+
 	if markOne.Line == markTwo.Line {
-		return textIntraRemove(markOne, markTwo.Col-markOne.Col)
+		return e.textIntraRemove(markOne, markTwo.Col-markOne.Col)
 	}
-	return textInterRemove(markOne, markTwo)
+	return e.textInterRemove(markOne, markTwo)
 }
 
 // textIntraMove moves/copies text within a single line
-func textIntraMove(
+func (e *Editor) textIntraMove(
 	copy bool,
 	count int,
 	markOne *MarkObject,
@@ -499,7 +523,7 @@ func textIntraMove(
 	colTwo := markTwo.Col
 
 	fullLen := colTwo - colOne
-	textStr := NewBlankStrObject(MaxStrLen)
+	textStr := EmptyStrObject()
 	if fullLen != 0 {
 		if fullLen*count > MaxStrLen {
 			return false
@@ -517,7 +541,7 @@ func textIntraMove(
 		for i := 1; i < count; i++ {
 			textStr.Copy(textStr, 1, textLen, 1+fullLen)
 			fullLen += textLen
-			if TtControlC {
+			if e.TtControlC.Load() {
 				return false
 			}
 		}
@@ -545,13 +569,13 @@ func textIntraMove(
 			return false
 		}
 		if fullLen != 0 {
-			if !textIntraRemove(markOne, markTwo.Col-markOne.Col) {
+			if !e.textIntraRemove(markOne, markTwo.Col-markOne.Col) {
 				return false
 			}
 		}
 	}
 	if fullLen != 0 {
-		if !TextInsert(true, 1, textStr, fullLen, dst) {
+		if !e.TextInsert(true, 1, textStr, fullLen, dst) {
 			return false
 		}
 	}
@@ -566,7 +590,7 @@ func textIntraMove(
 }
 
 // textInterMove moves/copies text spanning multiple lines
-func textInterMove(
+func (e *Editor) textInterMove(
 	copy bool,
 	count int,
 	markOne *MarkObject,
@@ -587,7 +611,7 @@ func textInterMove(
 	var lastLineLength int
 	var tempLen int
 	var textLen int
-	textStr := NewBlankStrObject(MaxStrLen)
+	textStr := EmptyStrObject()
 	var dstCol int
 	var dstUsed int
 	var dstLine *LineHdrObject
@@ -658,7 +682,7 @@ func textInterMove(
 	// Take count copies
 	nextDstLine = firstLine
 	for i = count - 1; i >= 0; i-- {
-		if TtControlC {
+		if e.TtControlC.Load() {
 			goto cleanup
 		}
 		nextSrcLine = lineOne.FLink
@@ -669,7 +693,7 @@ func textInterMove(
 				if !MarksSqueeze(firstNicked, 1, lastNicked.FLink, 1) {
 					goto cleanup
 				}
-				if !LinesExtract(firstNicked, lastNicked) {
+				if !e.LinesExtract(firstNicked, lastNicked) {
 					goto cleanup
 				}
 				lastNicked.FLink = nextDstLine
@@ -723,7 +747,7 @@ func textInterMove(
 	}
 
 	// Remove original if necessary
-	if !copy && !textInterRemove(markOne, markTwo) {
+	if !copy && !e.textInterRemove(markOne, markTwo) {
 		goto cleanup
 	}
 
@@ -747,7 +771,7 @@ func textInterMove(
 	// Special case for NULL line
 	if dstLine.FLink == nil {
 		if (dstCol != 1) || (lastLineLength != 0) {
-			if !TextRealizeNull(dstLine) {
+			if !e.TextRealizeNull(dstLine) {
 				goto cleanup
 			}
 			dstLine = dstLine.BLink
@@ -769,7 +793,7 @@ func textInterMove(
 				ChFillCopy(textStr, 1, textLen, firstLine.Str, 1, firstLine.Len(), ' ')
 				firstLine.Used = textLen
 			}
-			if !LinesInject(firstLine, lastLine, dstLine) {
+			if !e.LinesInject(firstLine, lastLine, dstLine) {
 				goto cleanup
 			}
 
@@ -780,7 +804,7 @@ func textInterMove(
 		}
 	}
 
-	if !LinesInject(firstLine, lastLine, dstLine.FLink) {
+	if !e.LinesInject(firstLine, lastLine, dstLine.FLink) {
 		goto cleanup
 	}
 	if !MarksShift(dstLine, dstCol, MaxStrLenP+1-dstCol, lastLine, colTwo) {
@@ -794,13 +818,13 @@ func textInterMove(
 		ChFillCopy(textStr, 1, textLen, dstLine.Str, dstCol, dstLine.Len()+1-dstCol, ' ')
 		dstLine.Used = dstCol + textLen - 1
 		if dstLine.ScrRowNr != 0 {
-			ScreenDrawLine(dstLine)
+			e.ScreenDrawLine(dstLine)
 		}
 	} else if dstCol <= dstLine.Used {
 		dstLine.Str.Fill(' ', dstCol, dstLine.Used)
 		dstLine.Used = dstLine.Str.Length(' ', dstCol)
 		if dstLine.ScrRowNr != 0 {
-			ScreenDrawLine(dstLine)
+			e.ScreenDrawLine(dstLine)
 		}
 	}
 
@@ -820,7 +844,7 @@ cleanup:
 }
 
 // TextMove moves or copies text between two marks to a destination
-func TextMove(
+func (e *Editor) TextMove(
 	copy bool,
 	count int,
 	markOne *MarkObject,
@@ -830,17 +854,28 @@ func TextMove(
 	newEnd **MarkObject,
 ) bool {
 	if count > 0 {
+		rec := e.undoBegin()
+		defer e.undoEnd(rec)
+		if copy {
+			rec.capture(dst.Line, 1)
+		} else if markOne.Line.Group.Frame != dst.Line.Group.Frame {
+			rec.captureBetween(markOne.Line, markTwo.Line)
+			rec.capture(dst.Line, 1)
+		} else {
+			rec.captureBetween(markOne.Line, markTwo.Line, dst.Line)
+		}
+
 		var cmdSuccess bool
 		if markOne.Line == markTwo.Line {
-			cmdSuccess = textIntraMove(copy, count, markOne, markTwo, dst, newStart, newEnd)
+			cmdSuccess = e.textIntraMove(copy, count, markOne, markTwo, dst, newStart, newEnd)
 		} else {
-			cmdSuccess = textInterMove(copy, count, markOne, markTwo, dst, newStart, newEnd)
+			cmdSuccess = e.textInterMove(copy, count, markOne, markTwo, dst, newStart, newEnd)
 		}
-		if TtControlC {
+		if e.TtControlC.Load() {
 			return false
 		}
 		if !cmdSuccess {
-			ScreenMessage(MsgNoRoomOnLine)
+			e.ScreenMessage(MsgNoRoomOnLine)
 			return false
 		}
 		if !copy {
@@ -866,7 +901,7 @@ func TextMove(
 }
 
 // TextSplitLine splits a line at a mark position
-func TextSplitLine(beforeMark *MarkObject, newCol int, equalsMark **MarkObject) bool {
+func (e *Editor) TextSplitLine(beforeMark *MarkObject, newCol int, equalsMark **MarkObject) bool {
 	var saveCol int
 	var length int
 	var shift int
@@ -875,10 +910,13 @@ func TextSplitLine(beforeMark *MarkObject, newCol int, equalsMark **MarkObject)
 	var equalsCol int
 	var equalsLine *LineHdrObject
 
+	rec := e.undoBegin()
+	defer e.undoEnd(rec)
+
 	result := false
 	discard := false
 	if beforeMark.Line.FLink == nil {
-		ScreenMessage(MsgCantSplitNullLine)
+		e.ScreenMessage(MsgCantSplitNullLine)
 		goto cleanup
 	}
 	if newCol == 0 {
@@ -889,10 +927,11 @@ func TextSplitLine(beforeMark *MarkObject, newCol int, equalsMark **MarkObject)
 		length = 0
 	} else {
 		if (newCol + length) > MaxStrLenP {
-			ScreenMessage(MsgNoRoomOnLine)
+			e.ScreenMessage(MsgNoRoomOnLine)
 			goto cleanup
 		}
 	}
+	rec.capture(beforeMark.Line, 1)
 
 	if !LinesCreate(1, &newLine, &newLine) {
 		goto cleanup
@@ -928,15 +967,17 @@ func TextSplitLine(beforeMark *MarkObject, newCol int, equalsMark **MarkObject)
 			newLine.Used = newCol + length - 1
 			if beforeMark.Line.ScrRowNr != 0 {
 				if beforeMark.Line.Used <= beforeMark.Line.Group.Frame.ScrOffset {
-					VduMoveCurs(1, beforeMark.Line.ScrRowNr)
-					VduClearEOL()
-				} else if beforeMark.Line.Used+1 <= beforeMark.Line.Group.Frame.ScrOffset+beforeMark.Line.Group.Frame.ScrWidth {
-					VduMoveCurs(beforeMark.Line.Used+1-beforeMark.Line.Group.Frame.ScrOffset, beforeMark.Line.ScrRowNr)
-					VduClearEOL()
+					e.VduMoveCurs(1, beforeMark.Line.ScrRowNr)
+					e.VduClearEOL()
+				} else if scrCol := screenColumn(
+					beforeMark.Line, beforeMark.Line.Used+1, beforeMark.Line.Group.Frame.ScrOffset,
+				); scrCol <= beforeMark.Line.Group.Frame.ScrWidth {
+					e.VduMoveCurs(scrCol, beforeMark.Line.ScrRowNr)
+					e.VduClearEOL()
 				}
 			}
 		}
-		if !LinesInject(newLine, newLine, beforeMark.Line.FLink) {
+		if !e.LinesInject(newLine, newLine, beforeMark.Line.FLink) {
 			goto cleanup
 		}
 		discard = false
@@ -959,7 +1000,7 @@ func TextSplitLine(beforeMark *MarkObject, newCol int, equalsMark **MarkObject)
 			newLine.Str.Copy(beforeMark.Line.Str, 1, shift, 1)
 			newLine.Used = newLine.Str.Length(' ', shift)
 		}
-		if !LinesInject(newLine, newLine, beforeMark.Line) {
+		if !e.LinesInject(newLine, newLine, beforeMark.Line) {
 			goto cleanup
 		}
 		discard = false
@@ -972,26 +1013,26 @@ func TextSplitLine(beforeMark *MarkObject, newCol int, equalsMark **MarkObject)
 		if shift <= 0 {
 			if shift < 0 {
 				beforeMark.Col += shift
-				if !textIntraRemove(beforeMark, -shift) {
+				if !e.textIntraRemove(beforeMark, -shift) {
 					goto cleanup
 				}
 			}
 			if newCol > 1 {
 				saveCol = beforeMark.Col
 				beforeMark.Col = 1
-				if !TextOvertype(true, 1, BlankString, newCol-1, beforeMark) {
+				if !e.TextOvertype(true, 1, NewBlankStrObject(newCol-1), newCol-1, beforeMark) {
 					goto cleanup
 				}
 				beforeMark.Col = saveCol
 			}
 		} else {
-			if !TextInsert(true, 1, BlankString, shift, beforeMark) {
+			if !e.TextInsert(true, 1, NewBlankStrObject(shift), shift, beforeMark) {
 				goto cleanup
 			}
 			if newCol > 1 {
 				saveCol = beforeMark.Col
 				beforeMark.Col = 1
-				if !TextOvertype(true, 1, BlankString, newCol-1, beforeMark) {
+				if !e.TextOvertype(true, 1, NewBlankStrObject(newCol-1), newCol-1, beforeMark) {
 					goto cleanup
 				}
 				beforeMark.Col = saveCol
@@ -3,6 +3,7 @@
 package ludwig
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,7 +35,7 @@ func setupTestLineInFrame() (*FrameObject, *LineHdrObject) {
 		OffsetNr: 0,
 		Used:     0,
 		ScrRowNr: 0,
-		Str:      NewBlankStrObject(MaxStrLen),
+		Str:      NewBlankStrObject(testStrLen),
 	}
 
 	// Add NULL line at the end
@@ -74,7 +75,7 @@ func setupTestLineWithContent(content string) (*FrameObject, *LineHdrObject) {
 	// Copy content into the line
 	for i, ch := range content {
 		if i < MaxStrLen {
-			line.Str.Set(i+1, byte(ch))
+			line.Str.Set(i+1, rune(ch))
 		}
 	}
 	line.Used = len(content)
@@ -108,7 +109,7 @@ func setupLinkedLines(count int) (*FrameObject, []*LineHdrObject) {
 			OffsetNr: i,
 			Used:     0,
 			ScrRowNr: 0,
-			Str:      NewBlankStrObject(MaxStrLen),
+			Str:      NewBlankStrObject(testStrLen),
 		}
 
 		if i > 0 {
@@ -168,7 +169,7 @@ func TestTextReturnCol(t *testing.T) {
 			Group:    line.Group,
 			OffsetNr: 1,
 			Used:     10,
-			Str:      NewBlankStrObject(MaxStrLen),
+			Str:      NewBlankStrObject(testStrLen),
 			BLink:    line,
 		}
 		line.FLink = nextLine
@@ -200,6 +201,7 @@ func TestTextReturnCol(t *testing.T) {
 
 // TestTextRealizeNull tests the TextRealizeNull function
 func TestTextRealizeNull(t *testing.T) {
+	e := NewEditor()
 	t.Run("RealizeNullLine", func(t *testing.T) {
 		frame, lines := setupLinkedLines(2)
 
@@ -220,7 +222,7 @@ func TestTextRealizeNull(t *testing.T) {
 		}
 		frame.Dot = dot
 
-		result := TextRealizeNull(nullLine)
+		result := e.TextRealizeNull(nullLine)
 		assert.True(t, result, "TextRealizeNull should succeed")
 		assert.True(t, frame.TextModified, "Frame should be marked as modified")
 	})
@@ -228,6 +230,7 @@ func TestTextRealizeNull(t *testing.T) {
 
 // TestTextInsert tests the TextInsert function
 func TestTextInsert(t *testing.T) {
+	e := NewEditor()
 	t.Run("InsertIntoEmptyLine", func(t *testing.T) {
 		_, line := setupTestLineInFrame()
 
@@ -237,14 +240,14 @@ func TestTextInsert(t *testing.T) {
 		}
 
 		// Create a simple string to insert
-		insertStr := NewBlankStrObject(MaxStrLen)
+		insertStr := NewBlankStrObject(testStrLen)
 		insertStr.Set(1, 'H')
 		insertStr.Set(2, 'i')
 
-		result := TextInsert(false, 1, insertStr, 2, mark)
+		result := e.TextInsert(false, 1, insertStr, 2, mark)
 		assert.True(t, result, "TextInsert should succeed")
-		assert.Equal(t, byte('H'), line.Str.Get(1), "First character should be 'H'")
-		assert.Equal(t, byte('i'), line.Str.Get(2), "Second character should be 'i'")
+		assert.Equal(t, rune('H'), line.Str.Get(1), "First character should be 'H'")
+		assert.Equal(t, rune('i'), line.Str.Get(2), "Second character should be 'i'")
 		assert.Equal(t, 2, line.Used, "Line.Used should be 2")
 	})
 
@@ -256,19 +259,19 @@ func TestTextInsert(t *testing.T) {
 			Col:  4, // Insert before 'l'
 		}
 
-		insertStr := NewBlankStrObject(MaxStrLen)
+		insertStr := NewBlankStrObject(testStrLen)
 		insertStr.Set(1, 'X')
 		insertStr.Set(2, 'Y')
 
-		result := TextInsert(false, 1, insertStr, 2, mark)
+		result := e.TextInsert(false, 1, insertStr, 2, mark)
 		assert.True(t, result, "TextInsert should succeed")
-		assert.Equal(t, byte('H'), line.Str.Get(1))
-		assert.Equal(t, byte('e'), line.Str.Get(2))
-		assert.Equal(t, byte('l'), line.Str.Get(3))
-		assert.Equal(t, byte('X'), line.Str.Get(4))
-		assert.Equal(t, byte('Y'), line.Str.Get(5))
-		assert.Equal(t, byte('l'), line.Str.Get(6))
-		assert.Equal(t, byte('o'), line.Str.Get(7))
+		assert.Equal(t, rune('H'), line.Str.Get(1))
+		assert.Equal(t, rune('e'), line.Str.Get(2))
+		assert.Equal(t, rune('l'), line.Str.Get(3))
+		assert.Equal(t, rune('X'), line.Str.Get(4))
+		assert.Equal(t, rune('Y'), line.Str.Get(5))
+		assert.Equal(t, rune('l'), line.Str.Get(6))
+		assert.Equal(t, rune('o'), line.Str.Get(7))
 	})
 
 	t.Run("InsertMultipleCopies", func(t *testing.T) {
@@ -279,14 +282,14 @@ func TestTextInsert(t *testing.T) {
 			Col:  1,
 		}
 
-		insertStr := NewBlankStrObject(MaxStrLen)
+		insertStr := NewBlankStrObject(testStrLen)
 		insertStr.Set(1, 'A')
 
-		result := TextInsert(false, 3, insertStr, 1, mark)
+		result := e.TextInsert(false, 3, insertStr, 1, mark)
 		assert.True(t, result, "TextInsert should succeed")
-		assert.Equal(t, byte('A'), line.Str.Get(1))
-		assert.Equal(t, byte('A'), line.Str.Get(2))
-		assert.Equal(t, byte('A'), line.Str.Get(3))
+		assert.Equal(t, rune('A'), line.Str.Get(1))
+		assert.Equal(t, rune('A'), line.Str.Get(2))
+		assert.Equal(t, rune('A'), line.Str.Get(3))
 		assert.Equal(t, 3, line.Used)
 	})
 
@@ -298,13 +301,47 @@ func TestTextInsert(t *testing.T) {
 			Col:  1,
 		}
 
-		insertStr := NewBlankStrObject(MaxStrLen)
+		insertStr := NewBlankStrObject(testStrLen)
 
 		// Try to insert too much data
-		result := TextInsert(false, 1, insertStr, MaxStrLen+10, mark)
+		result := e.TextInsert(false, 1, insertStr, MaxStrLen+10, mark)
 		assert.False(t, result, "TextInsert should fail when exceeding MaxStrLen")
 	})
 
+	t.Run("InsertCountExceedsMaxLength", func(t *testing.T) {
+		_, line := setupTestLineWithContent("Hello")
+
+		mark := &MarkObject{
+			Line: line,
+			Col:  1,
+		}
+
+		// Fails before making room for all the copies
+		result := e.TextInsert(false, MaxInt, NewStrObjectFrom("abc"), 3, mark)
+		assert.False(t, result, "TextInsert should fail when the copies exceed MaxStrLen")
+		assert.Equal(t, 5, line.Used, "Line.Used should not change")
+	})
+
+	t.Run("InsertIntoLongLine", func(t *testing.T) {
+		_, line := setupTestLineWithContent("Hello")
+
+		// Well past the old limit of 400 characters
+		insertStr := NewStrObjectFrom(strings.Repeat("x", 5000))
+		mark := &MarkObject{Line: line, Col: 3}
+		result := e.TextInsert(false, 2, insertStr, 5000, mark)
+		assert.True(t, result, "TextInsert should succeed on a long line")
+		assert.Equal(t, 10005, line.Used)
+		assert.Equal(t, "He", line.Str.Slice(1, 2))
+		assert.Equal(t, "llo", line.Str.Slice(10003, 3))
+
+		overtypeStr := NewStrObjectFrom("abc")
+		mark = &MarkObject{Line: line, Col: 10004}
+		result = e.TextOvertype(false, 1, overtypeStr, 3, mark)
+		assert.True(t, result, "TextOvertype should succeed on a long line")
+		assert.Equal(t, 10006, line.Used)
+		assert.Equal(t, "labc", line.Str.Slice(10003, 4))
+	})
+
 	t.Run("InsertAtNullLine", func(t *testing.T) {
 		frame, lines := setupLinkedLines(2)
 
@@ -322,10 +359,10 @@ func TestTextInsert(t *testing.T) {
 			Col:  1,
 		}
 
-		insertStr := NewBlankStrObject(MaxStrLen)
+		insertStr := NewBlankStrObject(testStrLen)
 		insertStr.Set(1, 'X')
 
-		result := TextInsert(false, 1, insertStr, 1, mark)
+		result := e.TextInsert(false, 1, insertStr, 1, mark)
 		// This will realize the null line first
 		assert.True(t, result, "TextInsert at null line should succeed")
 	})
@@ -333,6 +370,7 @@ func TestTextInsert(t *testing.T) {
 
 // TestTextOvertype tests the TextOvertype function
 func TestTextOvertype(t *testing.T) {
+	e := NewEditor()
 	t.Run("OvertypeEmptyLine", func(t *testing.T) {
 		_, line := setupTestLineInFrame()
 
@@ -341,14 +379,14 @@ func TestTextOvertype(t *testing.T) {
 			Col:  1,
 		}
 
-		overtypeStr := NewBlankStrObject(MaxStrLen)
+		overtypeStr := NewBlankStrObject(testStrLen)
 		overtypeStr.Set(1, 'A')
 		overtypeStr.Set(2, 'B')
 
-		result := TextOvertype(false, 1, overtypeStr, 2, mark)
+		result := e.TextOvertype(false, 1, overtypeStr, 2, mark)
 		assert.True(t, result, "TextOvertype should succeed")
-		assert.Equal(t, byte('A'), line.Str.Get(1))
-		assert.Equal(t, byte('B'), line.Str.Get(2))
+		assert.Equal(t, rune('A'), line.Str.Get(1))
+		assert.Equal(t, rune('B'), line.Str.Get(2))
 		assert.Equal(t, 2, line.Used)
 		assert.Equal(t, 3, mark.Col, "Mark should advance to column 3")
 	})
@@ -361,17 +399,17 @@ func TestTextOvertype(t *testing.T) {
 			Col:  2,
 		}
 
-		overtypeStr := NewBlankStrObject(MaxStrLen)
+		overtypeStr := NewBlankStrObject(testStrLen)
 		overtypeStr.Set(1, 'X')
 		overtypeStr.Set(2, 'Y')
 
-		result := TextOvertype(false, 1, overtypeStr, 2, mark)
+		result := e.TextOvertype(false, 1, overtypeStr, 2, mark)
 		assert.True(t, result, "TextOvertype should succeed")
-		assert.Equal(t, byte('H'), line.Str.Get(1))
-		assert.Equal(t, byte('X'), line.Str.Get(2))
-		assert.Equal(t, byte('Y'), line.Str.Get(3))
-		assert.Equal(t, byte('l'), line.Str.Get(4))
-		assert.Equal(t, byte('o'), line.Str.Get(5))
+		assert.Equal(t, rune('H'), line.Str.Get(1))
+		assert.Equal(t, rune('X'), line.Str.Get(2))
+		assert.Equal(t, rune('Y'), line.Str.Get(3))
+		assert.Equal(t, rune('l'), line.Str.Get(4))
+		assert.Equal(t, rune('o'), line.Str.Get(5))
 		assert.Equal(t, 4, mark.Col, "Mark should advance")
 	})
 
@@ -383,13 +421,13 @@ func TestTextOvertype(t *testing.T) {
 			Col:  1,
 		}
 
-		overtypeStr := NewBlankStrObject(MaxStrLen)
+		overtypeStr := NewBlankStrObject(testStrLen)
 		overtypeStr.Set(1, 'Z')
 
-		result := TextOvertype(false, 5, overtypeStr, 1, mark)
+		result := e.TextOvertype(false, 5, overtypeStr, 1, mark)
 		assert.True(t, result, "TextOvertype should succeed")
 		for i := 1; i <= 5; i++ {
-			assert.Equal(t, byte('Z'), line.Str.Get(i), "Character %d should be 'Z'", i)
+			assert.Equal(t, rune('Z'), line.Str.Get(i), "Character %d should be 'Z'", i)
 		}
 		assert.Equal(t, 6, mark.Col, "Mark should advance to column 6")
 	})
@@ -402,15 +440,16 @@ func TestTextOvertype(t *testing.T) {
 			Col:  MaxStrLen - 5,
 		}
 
-		overtypeStr := NewBlankStrObject(MaxStrLen)
+		overtypeStr := NewBlankStrObject(testStrLen)
 
-		result := TextOvertype(false, 1, overtypeStr, 20, mark)
+		result := e.TextOvertype(false, 1, overtypeStr, 20, mark)
 		assert.False(t, result, "TextOvertype should fail when exceeding MaxStrLen")
 	})
 }
 
 // TestTextRemove tests the TextRemove function
 func TestTextRemove(t *testing.T) {
+	e := NewEditor()
 	t.Run("RemoveWithinSingleLine", func(t *testing.T) {
 		_, line := setupTestLineWithContent("Hello World")
 
@@ -423,15 +462,15 @@ func TestTextRemove(t *testing.T) {
 			Col:  12, // After "Hello World"
 		}
 
-		result := TextRemove(markOne, markTwo)
+		result := e.TextRemove(markOne, markTwo)
 		assert.True(t, result, "TextRemove should succeed")
 
 		// Should have "Hello " left, but trailing spaces are trimmed
-		assert.Equal(t, byte('H'), line.Str.Get(1))
-		assert.Equal(t, byte('e'), line.Str.Get(2))
-		assert.Equal(t, byte('l'), line.Str.Get(3))
-		assert.Equal(t, byte('l'), line.Str.Get(4))
-		assert.Equal(t, byte('o'), line.Str.Get(5))
+		assert.Equal(t, rune('H'), line.Str.Get(1))
+		assert.Equal(t, rune('e'), line.Str.Get(2))
+		assert.Equal(t, rune('l'), line.Str.Get(3))
+		assert.Equal(t, rune('l'), line.Str.Get(4))
+		assert.Equal(t, rune('o'), line.Str.Get(5))
 		// The Used field gets trimmed to remove trailing spaces
 		assert.Equal(t, 5, line.Used)
 	})
@@ -448,12 +487,12 @@ func TestTextRemove(t *testing.T) {
 			Col:  3,
 		}
 
-		result := TextRemove(markOne, markTwo)
+		result := e.TextRemove(markOne, markTwo)
 		assert.True(t, result, "TextRemove should succeed")
 
 		// Should have "st" left
-		assert.Equal(t, byte('s'), line.Str.Get(1))
-		assert.Equal(t, byte('t'), line.Str.Get(2))
+		assert.Equal(t, rune('s'), line.Str.Get(1))
+		assert.Equal(t, rune('t'), line.Str.Get(2))
 		assert.Equal(t, 2, line.Used)
 	})
 
@@ -469,7 +508,7 @@ func TestTextRemove(t *testing.T) {
 			Col:  5,
 		}
 
-		result := TextRemove(markOne, markTwo)
+		result := e.TextRemove(markOne, markTwo)
 		assert.True(t, result, "TextRemove should succeed")
 		assert.Equal(t, 0, line.Used, "Line should be empty")
 	})
@@ -480,7 +519,7 @@ func TestTextRemove(t *testing.T) {
 		// Set content on each line
 		for i, content := range []string{"First line", "Second line", "Third line"} {
 			for j, ch := range content {
-				lines[i].Str.Set(j+1, byte(ch))
+				lines[i].Str.Set(j+1, rune(ch))
 			}
 			lines[i].Used = len(content)
 		}
@@ -495,23 +534,23 @@ func TestTextRemove(t *testing.T) {
 			Col:  7, // After "Third "
 		}
 
-		result := TextRemove(markOne, markTwo)
+		result := e.TextRemove(markOne, markTwo)
 		assert.True(t, result, "TextRemove across lines should succeed")
 
 		// After inter-line remove, the result is in markTwo.Line (lines[2])
 		// It should have "First " + "line" (remainder of third line)
 		// = "First line"
 		resultLine := markTwo.Line
-		assert.Equal(t, byte('F'), resultLine.Str.Get(1))
-		assert.Equal(t, byte('i'), resultLine.Str.Get(2))
-		assert.Equal(t, byte('r'), resultLine.Str.Get(3))
-		assert.Equal(t, byte('s'), resultLine.Str.Get(4))
-		assert.Equal(t, byte('t'), resultLine.Str.Get(5))
-		assert.Equal(t, byte(' '), resultLine.Str.Get(6))
-		assert.Equal(t, byte('l'), resultLine.Str.Get(7))
-		assert.Equal(t, byte('i'), resultLine.Str.Get(8))
-		assert.Equal(t, byte('n'), resultLine.Str.Get(9))
-		assert.Equal(t, byte('e'), resultLine.Str.Get(10))
+		assert.Equal(t, rune('F'), resultLine.Str.Get(1))
+		assert.Equal(t, rune('i'), resultLine.Str.Get(2))
+		assert.Equal(t, rune('r'), resultLine.Str.Get(3))
+		assert.Equal(t, rune('s'), resultLine.Str.Get(4))
+		assert.Equal(t, rune('t'), resultLine.Str.Get(5))
+		assert.Equal(t, rune(' '), resultLine.Str.Get(6))
+		assert.Equal(t, rune('l'), resultLine.Str.Get(7))
+		assert.Equal(t, rune('i'), resultLine.Str.Get(8))
+		assert.Equal(t, rune('n'), resultLine.Str.Get(9))
+		assert.Equal(t, rune('e'), resultLine.Str.Get(10))
 	})
 
 	t.Run("RemoveFromStartOfFirstLineToEndOfSecondLine", func(t *testing.T) {
@@ -521,11 +560,11 @@ func TestTextRemove(t *testing.T) {
 		line1Content := "First"
 		line2Content := "Second"
 		for i, ch := range line1Content {
-			lines[0].Str.Set(i+1, byte(ch))
+			lines[0].Str.Set(i+1, rune(ch))
 		}
 		lines[0].Used = len(line1Content)
 		for i, ch := range line2Content {
-			lines[1].Str.Set(i+1, byte(ch))
+			lines[1].Str.Set(i+1, rune(ch))
 		}
 		lines[1].Used = len(line2Content)
 
@@ -539,7 +578,7 @@ func TestTextRemove(t *testing.T) {
 			Col:  7, // After "Second"
 		}
 
-		result := TextRemove(markOne, markTwo)
+		result := e.TextRemove(markOne, markTwo)
 		assert.True(t, result, "TextRemove from start should succeed")
 
 		// Result should be in markTwo.Line (lines[1]) and should be empty
@@ -554,7 +593,7 @@ func TestTextRemove(t *testing.T) {
 		// Set content
 		for i, content := range []string{"AAA", "BBB", "CCC"} {
 			for j, ch := range content {
-				lines[i].Str.Set(j+1, byte(ch))
+				lines[i].Str.Set(j+1, rune(ch))
 			}
 			lines[i].Used = len(content)
 		}
@@ -569,23 +608,24 @@ func TestTextRemove(t *testing.T) {
 			Col:  1, // Before "CCC"
 		}
 
-		result := TextRemove(markOne, markTwo)
+		result := e.TextRemove(markOne, markTwo)
 		assert.True(t, result, "TextRemove of middle line should succeed")
 
 		// The result is in markTwo.Line (lines[2])
 		// Should have "AAA" (from before markOne.Col) + "CCC" (from markTwo.Col onwards)
 		resultLine := markTwo.Line
-		assert.Equal(t, byte('A'), resultLine.Str.Get(1))
-		assert.Equal(t, byte('A'), resultLine.Str.Get(2))
-		assert.Equal(t, byte('A'), resultLine.Str.Get(3))
-		assert.Equal(t, byte('C'), resultLine.Str.Get(4))
-		assert.Equal(t, byte('C'), resultLine.Str.Get(5))
-		assert.Equal(t, byte('C'), resultLine.Str.Get(6))
+		assert.Equal(t, rune('A'), resultLine.Str.Get(1))
+		assert.Equal(t, rune('A'), resultLine.Str.Get(2))
+		assert.Equal(t, rune('A'), resultLine.Str.Get(3))
+		assert.Equal(t, rune('C'), resultLine.Str.Get(4))
+		assert.Equal(t, rune('C'), resultLine.Str.Get(5))
+		assert.Equal(t, rune('C'), resultLine.Str.Get(6))
 	})
 }
 
 // TestTextSplitLine tests the TextSplitLine function
 func TestTextSplitLine(t *testing.T) {
+	e := NewEditor()
 	t.Run("SplitEmptyLine", func(t *testing.T) {
 		frame, lines := setupLinkedLines(2)
 		line := lines[0]
@@ -602,7 +642,7 @@ func TestTextSplitLine(t *testing.T) {
 		}
 
 		var equalsMark *MarkObject
-		result := TextSplitLine(mark, 1, &equalsMark)
+		result := e.TextSplitLine(mark, 1, &equalsMark)
 		assert.True(t, result, "TextSplitLine should succeed")
 		assert.NotNil(t, equalsMark, "Equals mark should be created")
 		assert.True(t, frame.TextModified, "Frame should be marked as modified")
@@ -615,7 +655,7 @@ func TestTextSplitLine(t *testing.T) {
 		// Set content
 		content := "Hello World"
 		for i, ch := range content {
-			line.Str.Set(i+1, byte(ch))
+			line.Str.Set(i+1, rune(ch))
 		}
 		line.Used = len(content)
 
@@ -631,7 +671,7 @@ func TestTextSplitLine(t *testing.T) {
 		}
 
 		var equalsMark *MarkObject
-		result := TextSplitLine(mark, 1, &equalsMark)
+		result := e.TextSplitLine(mark, 1, &equalsMark)
 		assert.True(t, result, "TextSplitLine should succeed")
 		assert.NotNil(t, equalsMark, "Equals mark should be created")
 	})
@@ -646,13 +686,14 @@ func TestTextSplitLine(t *testing.T) {
 		}
 
 		var equalsMark *MarkObject
-		result := TextSplitLine(mark, 1, &equalsMark)
+		result := e.TextSplitLine(mark, 1, &equalsMark)
 		assert.False(t, result, "Should not be able to split null line")
 	})
 }
 
 // TestTextMove tests the TextMove function
 func TestTextMove(t *testing.T) {
+	e := NewEditor()
 	t.Run("CopySingleLine", func(t *testing.T) {
 		frame, line := setupTestLineWithContent("Hello")
 
@@ -676,7 +717,7 @@ func TestTextMove(t *testing.T) {
 		}
 
 		var newStart, newEnd *MarkObject
-		result := TextMove(true, 1, markOne, markTwo, dst, &newStart, &newEnd)
+		result := e.TextMove(true, 1, markOne, markTwo, dst, &newStart, &newEnd)
 		assert.True(t, result, "TextMove (copy) should succeed")
 		assert.NotNil(t, newStart, "newStart should be set")
 		assert.NotNil(t, newEnd, "newEnd should be set")
@@ -705,7 +746,7 @@ func TestTextMove(t *testing.T) {
 		}
 
 		var newStart, newEnd *MarkObject
-		result := TextMove(false, 1, markOne, markTwo, dst, &newStart, &newEnd)
+		result := e.TextMove(false, 1, markOne, markTwo, dst, &newStart, &newEnd)
 		assert.True(t, result, "TextMove should succeed")
 		assert.NotNil(t, newStart, "newStart should be set")
 		assert.NotNil(t, newEnd, "newEnd should be set")
@@ -729,7 +770,7 @@ func TestTextMove(t *testing.T) {
 		}
 
 		var newStart, newEnd *MarkObject
-		result := TextMove(true, 0, markOne, markTwo, dst, &newStart, &newEnd)
+		result := e.TextMove(true, 0, markOne, markTwo, dst, &newStart, &newEnd)
 		assert.True(t, result, "TextMove with count=0 should succeed")
 	})
 
@@ -740,11 +781,11 @@ func TestTextMove(t *testing.T) {
 		line1Content := "First"
 		line2Content := "Second"
 		for i, ch := range line1Content {
-			lines[0].Str.Set(i+1, byte(ch))
+			lines[0].Str.Set(i+1, rune(ch))
 		}
 		lines[0].Used = len(line1Content)
 		for i, ch := range line2Content {
-			lines[1].Str.Set(i+1, byte(ch))
+			lines[1].Str.Set(i+1, rune(ch))
 		}
 		lines[1].Used = len(line2Content)
 
@@ -763,16 +804,16 @@ func TestTextMove(t *testing.T) {
 		}
 
 		var newStart, newEnd *MarkObject
-		result := TextMove(true, 1, markOne, markTwo, dst, &newStart, &newEnd)
+		result := e.TextMove(true, 1, markOne, markTwo, dst, &newStart, &newEnd)
 		assert.True(t, result, "TextMove (copy) across lines should succeed")
 		assert.NotNil(t, newStart, "newStart should be set")
 		assert.NotNil(t, newEnd, "newEnd should be set")
 
 		// Original lines should be unchanged (copy, not move)
-		assert.Equal(t, byte('F'), lines[0].Str.Get(1))
-		assert.Equal(t, byte('i'), lines[0].Str.Get(2))
+		assert.Equal(t, rune('F'), lines[0].Str.Get(1))
+		assert.Equal(t, rune('i'), lines[0].Str.Get(2))
 		assert.Equal(t, 5, lines[0].Used)
-		assert.Equal(t, byte('S'), lines[1].Str.Get(1))
+		assert.Equal(t, rune('S'), lines[1].Str.Get(1))
 		assert.Equal(t, 6, lines[1].Used)
 	})
 
@@ -783,11 +824,11 @@ func TestTextMove(t *testing.T) {
 		line1Content := "AAA"
 		line2Content := "BBB"
 		for i, ch := range line1Content {
-			lines[0].Str.Set(i+1, byte(ch))
+			lines[0].Str.Set(i+1, rune(ch))
 		}
 		lines[0].Used = len(line1Content)
 		for i, ch := range line2Content {
-			lines[1].Str.Set(i+1, byte(ch))
+			lines[1].Str.Set(i+1, rune(ch))
 		}
 		lines[1].Used = len(line2Content)
 
@@ -806,7 +847,7 @@ func TestTextMove(t *testing.T) {
 		}
 
 		var newStart, newEnd *MarkObject
-		result := TextMove(false, 1, markOne, markTwo, dst, &newStart, &newEnd)
+		result := e.TextMove(false, 1, markOne, markTwo, dst, &newStart, &newEnd)
 		assert.True(t, result, "TextMove across lines should succeed")
 		assert.NotNil(t, newStart, "newStart should be set")
 		assert.NotNil(t, newEnd, "newEnd should be set")
@@ -820,11 +861,11 @@ func TestTextMove(t *testing.T) {
 		line1Content := "First"
 		line2Content := "Second"
 		for i, ch := range line1Content {
-			lines[0].Str.Set(i+1, byte(ch))
+			lines[0].Str.Set(i+1, rune(ch))
 		}
 		lines[0].Used = len(line1Content)
 		for i, ch := range line2Content {
-			lines[1].Str.Set(i+1, byte(ch))
+			lines[1].Str.Set(i+1, rune(ch))
 		}
 		lines[1].Used = len(line2Content)
 
@@ -843,7 +884,7 @@ func TestTextMove(t *testing.T) {
 		}
 
 		var newStart, newEnd *MarkObject
-		result := TextMove(true, 2, markOne, markTwo, dst, &newStart, &newEnd)
+		result := e.TextMove(true, 2, markOne, markTwo, dst, &newStart, &newEnd)
 		assert.True(t, result, "TextMove with count=2 should succeed")
 		assert.NotNil(t, newStart, "newStart should be set")
 		assert.NotNil(t, newEnd, "newEnd should be set")
@@ -852,6 +893,7 @@ func TestTextMove(t *testing.T) {
 
 // TestTextInsertTpar tests the TextInsertTpar function
 func TestTextInsertTpar(t *testing.T) {
+	e := NewEditor()
 	t.Run("InsertSimpleTpar", func(t *testing.T) {
 		_, line := setupTestLineInFrame()
 
@@ -875,16 +917,16 @@ func TestTextInsertTpar(t *testing.T) {
 		tpar.Str.Set(5, 'o')
 
 		var equalsMark *MarkObject
-		result := TextInsertTpar(tpar, mark, &equalsMark)
+		result := e.TextInsertTpar(tpar, mark, &equalsMark)
 		assert.True(t, result, "TextInsertTpar should succeed")
 		assert.NotNil(t, equalsMark, "Equals mark should be created")
 
 		// Check content was inserted
-		assert.Equal(t, byte('H'), line.Str.Get(1))
-		assert.Equal(t, byte('e'), line.Str.Get(2))
-		assert.Equal(t, byte('l'), line.Str.Get(3))
-		assert.Equal(t, byte('l'), line.Str.Get(4))
-		assert.Equal(t, byte('o'), line.Str.Get(5))
+		assert.Equal(t, rune('H'), line.Str.Get(1))
+		assert.Equal(t, rune('e'), line.Str.Get(2))
+		assert.Equal(t, rune('l'), line.Str.Get(3))
+		assert.Equal(t, rune('l'), line.Str.Get(4))
+		assert.Equal(t, rune('o'), line.Str.Get(5))
 	})
 
 	t.Run("InsertEmptyTpar", func(t *testing.T) {
@@ -904,7 +946,7 @@ func TestTextInsertTpar(t *testing.T) {
 		}
 
 		var equalsMark *MarkObject
-		result := TextInsertTpar(tpar, mark, &equalsMark)
+		result := e.TextInsertTpar(tpar, mark, &equalsMark)
 		assert.True(t, result, "TextInsertTpar with empty tpar should succeed")
 		assert.NotNil(t, equalsMark, "Equals mark should be created")
 	})
@@ -958,7 +1000,7 @@ func TestTextInsertTpar(t *testing.T) {
 		tpar2.Con = tpar3
 
 		var equalsMark *MarkObject
-		result := TextInsertTpar(tpar1, mark, &equalsMark)
+		result := e.TextInsertTpar(tpar1, mark, &equalsMark)
 		assert.True(t, result, "TextInsertTpar with multi-line tpar should succeed")
 		assert.NotNil(t, equalsMark, "Equals mark should be created")
 		assert.True(t, frame.TextModified, "Frame should be marked as modified")
@@ -17,8 +17,10 @@ package ludwig
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 const (
@@ -99,24 +101,24 @@ func TparDuplicate(fromTp *TParObject, toTp **TParObject) {
 }
 
 // TparToMark converts a tpar string to a mark number
-func TparToMark(strng *TParObject, mark *int) bool {
+func (e *Editor) TparToMark(strng *TParObject, mark *int) bool {
 	if strng.Len == 0 {
-		ScreenMessage(MsgIllegalMarkNumber)
+		e.ScreenMessage(MsgIllegalMarkNumber)
 		return false
 	}
 	mch := strng.Str.Get(1)
 	if mch >= '0' && mch <= '9' {
 		i := 1
-		if !TparToInt(strng, &i, mark) {
+		if !e.TparToInt(strng, &i, mark) {
 			return false
 		}
 		if (i <= strng.Len) || ((*mark < 1) || (*mark > MaxUserMarkNumber)) {
-			ScreenMessage(MsgIllegalMarkNumber)
+			e.ScreenMessage(MsgIllegalMarkNumber)
 			return false
 		}
 	} else {
 		if (strng.Len > 1) || (mch != '=' && mch != '%') {
-			ScreenMessage(MsgIllegalMarkNumber)
+			e.ScreenMessage(MsgIllegalMarkNumber)
 			return false
 		}
 		if mch == '=' {
@@ -129,15 +131,15 @@ func TparToMark(strng *TParObject, mark *int) bool {
 }
 
 // TparToInt converts a tpar string to an integer
-func TparToInt(strng *TParObject, chpos *int, intVal *int) bool {
-	var ch byte
+func (e *Editor) TparToInt(strng *TParObject, chpos *int, intVal *int) bool {
+	var ch rune
 	if *chpos > strng.Len {
 		ch = '\x00'
 	} else {
 		ch = strng.Str.Get(*chpos)
 	}
 	if ch < '0' || ch > '9' {
-		ScreenMessage(MsgInvalidInteger)
+		e.ScreenMessage(MsgInvalidInteger)
 		return false
 	}
 	number := 0
@@ -147,7 +149,7 @@ func TparToInt(strng *TParObject, chpos *int, intVal *int) bool {
 			number *= 10
 			number += digit
 		} else {
-			ScreenMessage(MsgInvalidInteger)
+			e.ScreenMessage(MsgInvalidInteger)
 			return false
 		}
 		*chpos++
@@ -165,16 +167,16 @@ func TparToInt(strng *TParObject, chpos *int, intVal *int) bool {
 }
 
 // tparSubstitute substitutes span content into tpar
-func tparSubstitute(tpar *TParObject, cmd Commands, thisTp int) bool {
+func (e *Editor) tparSubstitute(tpar *TParObject, cmd Commands, thisTp int) bool {
 	if tpar.Con != nil {
-		ScreenMessage(MsgSpanNamesAreOneLine)
+		e.ScreenMessage(MsgSpanNamesAreOneLine)
 		return false
 	}
 	// Get the span name and uppercase it
 	name := strings.ToUpper(string(tpar.Str.Slice(1, tpar.Len)))
 	var span *SpanObject
 	var dummy *SpanObject
-	if SpanFind(name, &span, &dummy) {
+	if e.SpanFind(name, &span, &dummy) {
 		tpar.Dlm = '\x00'
 		startMark := *span.MarkOne
 		endMark := *span.MarkTwo
@@ -189,8 +191,8 @@ func tparSubstitute(tpar *TParObject, cmd Commands, thisTp int) bool {
 				srclen = tpar.Len
 			}
 			ChFillCopy(startMark.Line.Str, startMark.Col, srclen, tpar.Str, 1, tpar.Len, ' ')
-		} else if !CmdAttrib[cmd].TparInfo[thisTp].MlAllowed {
-			ScreenMessage(MsgSpanMustBeOneLine)
+		} else if !e.CmdAttrib[cmd].TparInfo[thisTp].MlAllowed {
+			e.ScreenMessage(MsgSpanMustBeOneLine)
 			return false
 		} else {
 			// Copy entire span into a tpar
@@ -205,7 +207,7 @@ func tparSubstitute(tpar *TParObject, cmd Commands, thisTp int) bool {
 			startMark.Line = startMark.Line.FLink
 			for startMark.Line != endMark.Line {
 				tmpTp2 := &TParObject{
-					Str: NewBlankStrObject(MaxStrLen),
+					Str: EmptyStrObject(),
 				}
 				if tmpTp == nil {
 					tpar.Con = tmpTp2
@@ -222,7 +224,7 @@ func tparSubstitute(tpar *TParObject, cmd Commands, thisTp int) bool {
 			}
 			// Create new tpar for last line
 			tmpTp2 := &TParObject{
-				Str: NewBlankStrObject(MaxStrLen),
+				Str: EmptyStrObject(),
 			}
 			if tmpTp == nil {
 				tpar.Con = tmpTp2
@@ -237,7 +239,7 @@ func tparSubstitute(tpar *TParObject, cmd Commands, thisTp int) bool {
 			ChFillCopy(endMark.Line.Str, 1, endMark.Line.Used, tmpTp.Str, 1, tpar.Len, ' ')
 		}
 	} else {
-		ScreenMessage(MsgNoSuchSpan)
+		e.ScreenMessage(MsgNoSuchSpan)
 		return false
 	}
 	return true
@@ -249,7 +251,7 @@ func leftPadded(width int, value int) string {
 }
 
 // findEnquiry handles environment and system enquiries
-func findEnquiry(name string, result **StrObject, reslen *int) bool {
+func (e *Editor) findEnquiry(name string, result **StrObject, reslen *int) bool {
 	enquiryResult := false
 	variableType := varTypeUnknown
 	var item strings.Builder
@@ -291,14 +293,14 @@ func findEnquiry(name string, result **StrObject, reslen *int) bool {
 			enquiryResult = true
 			switch itemStr {
 			case "NAME":
-				*reslen = len(TerminalInfo.Name)
-				*result = NewStrObjectFrom(TerminalInfo.Name)
+				*reslen = len(e.TerminalInfo.Name)
+				*result = NewStrObjectFrom(e.TerminalInfo.Name)
 			case "HEIGHT":
-				s := leftPadded(EnquiryNumLen, TerminalInfo.Height)
+				s := leftPadded(EnquiryNumLen, e.TerminalInfo.Height)
 				*reslen = len(s)
 				*result = NewStrObjectFrom(s)
 			case "WIDTH":
-				s := leftPadded(EnquiryNumLen, TerminalInfo.Width)
+				s := leftPadded(EnquiryNumLen, e.TerminalInfo.Width)
 				*reslen = len(s)
 				*result = NewStrObjectFrom(s)
 			case "SPEED":
@@ -313,25 +315,25 @@ func findEnquiry(name string, result **StrObject, reslen *int) bool {
 			enquiryResult = true
 			switch itemStr {
 			case "NAME":
-				*reslen = len(CurrentFrame.Span.Name)
-				*result = NewStrObjectFrom(CurrentFrame.Span.Name)
+				*reslen = len(e.CurrentFrame.Span.Name)
+				*result = NewStrObjectFrom(e.CurrentFrame.Span.Name)
 			case "INPUTFILE":
-				if CurrentFrame.InputFile == 0 {
+				if e.CurrentFrame.InputFile == 0 {
 					*reslen = 0
 				} else {
-					*reslen = len(Files[CurrentFrame.InputFile].Filename)
-					*result = NewStrObjectFrom(Files[CurrentFrame.InputFile].Filename)
+					*reslen = len(e.Files[e.CurrentFrame.InputFile].Filename)
+					*result = NewStrObjectFrom(e.Files[e.CurrentFrame.InputFile].Filename)
 				}
 			case "OUTPUTFILE":
-				if CurrentFrame.OutputFile == 0 {
+				if e.CurrentFrame.OutputFile == 0 {
 					*reslen = 0
 				} else {
-					*reslen = len(Files[CurrentFrame.OutputFile].Filename)
-					*result = NewStrObjectFrom(Files[CurrentFrame.OutputFile].Filename)
+					*reslen = len(e.Files[e.CurrentFrame.OutputFile].Filename)
+					*result = NewStrObjectFrom(e.Files[e.CurrentFrame.OutputFile].Filename)
 				}
 			case "MODIFIED":
 				*reslen = 1
-				if CurrentFrame.TextModified {
+				if e.CurrentFrame.TextModified {
 					*result = NewStrObjectFrom("Y")
 				} else {
 					*result = NewStrObjectFrom("N")
@@ -344,7 +346,7 @@ func findEnquiry(name string, result **StrObject, reslen *int) bool {
 			env, found := os.LookupEnv(itemStr)
 			if found {
 				enquiryResult = true
-				*reslen = min(MaxStrLen, len(env))
+				*reslen = utf8.RuneCountInString(env)
 				*result = NewStrObjectFrom(env)
 			}
 
@@ -358,24 +360,28 @@ func findEnquiry(name string, result **StrObject, reslen *int) bool {
 				*result = NewStrObjectFrom(SystemName)
 				*reslen = len(SystemName)
 			case "COMMAND_INTRODUCER":
-				if !ChIsPrintable(rune(CommandIntroducer)) {
+				if !ChIsPrintableKey(e.CommandIntroducer) {
 					*reslen = 0
-					ScreenMessage(MsgNonprintableIntroducer)
+					e.ScreenMessage(MsgNonprintableIntroducer)
 				} else {
 					*reslen = 1
-					*result = NewStrObjectFrom(string(rune(CommandIntroducer)))
+					*result = NewStrObjectFrom(string(rune(e.CommandIntroducer)))
 				}
 			case "INSERT_MODE":
 				*reslen = 1
-				if (EditMode == ModeInsert) || ((EditMode == ModeCommand) && (PreviousMode == ModeInsert)) {
+				if (e.EditMode == ModeInsert) || ((e.EditMode == ModeCommand) && (e.PreviousMode == ModeInsert)) {
 					*result = NewStrObjectFrom("Y")
 				} else {
 					*result = NewStrObjectFrom("N")
 				}
+			case "EXIT_STATUS":
+				s := strconv.Itoa(e.opsysStatus)
+				*reslen = len(s)
+				*result = NewStrObjectFrom(s)
 			case "OVERTYPE_MODE":
 				*reslen = 1
-				if (EditMode == ModeOvertype) ||
-					((EditMode == ModeCommand) && (PreviousMode == ModeOvertype)) {
+				if (e.EditMode == ModeOvertype) ||
+					((e.EditMode == ModeCommand) && (e.PreviousMode == ModeOvertype)) {
 					*result = NewStrObjectFrom("Y")
 				} else {
 					*result = NewStrObjectFrom("N")
@@ -392,39 +398,39 @@ func findEnquiry(name string, result **StrObject, reslen *int) bool {
 }
 
 // tparEnquire performs environment enquiries
-func tparEnquire(tpar *TParObject) bool {
+func (e *Editor) tparEnquire(tpar *TParObject) bool {
 	tpar.Dlm = '\x00'
 	name := tpar.Str.Slice(1, tpar.Len)
-	if findEnquiry(name, &tpar.Str, &tpar.Len) {
+	if e.findEnquiry(name, &tpar.Str, &tpar.Len) {
 		return true
 	}
-	ScreenMessage(MsgUnknownItem)
-	ExitAbort = true
+	e.ScreenMessage(MsgUnknownItem)
+	e.ExitAbort = true
 	return false
 }
 
 // TparAnalyse analyses and processes trailing parameters
-func TparAnalyse(cmd Commands, tran *TParObject, depth int, thisTp int) bool {
+func (e *Editor) TparAnalyse(cmd Commands, tran *TParObject, depth int, thisTp int) bool {
 	if depth > MaxTparRecursion {
-		ScreenMessage(MsgTparTooDeep)
+		e.ScreenMessage(MsgTparTooDeep)
 		return false
 	}
-	if tran.Dlm != TpdSmart && tran.Dlm != TpdExact && tran.Dlm != TpdLit {
+	if tran.Dlm != TpdSmart && tran.Dlm != TpdRegexp && tran.Dlm != TpdExact && tran.Dlm != TpdLit {
 		ended := false
-		for !ended && !TtControlC {
+		for !ended && !e.TtControlC.Load() {
 			delim := tran.Dlm // Save copy of delimiter
 			if tran.Con == nil {
 				if tran.Len > 1 {
 					ts1 := tran.Str.Get(1)
 					if (ts1 == tran.Str.Get(tran.Len)) &&
 						(ts1 == TpdSpan || ts1 == TpdPrompt || ts1 == TpdEnvironment ||
-							ts1 == TpdSmart || ts1 == TpdExact || ts1 == TpdLit) {
+							ts1 == TpdSmart || ts1 == TpdRegexp || ts1 == TpdExact || ts1 == TpdLit) {
 						// Nested delimiters
-						tran.Dlm = ts1
+						tran.Dlm = byte(ts1)
 						tran.Len -= 2
 						// Erase first char
 						tran.Str.Erase(1, 1)
-						if !TparAnalyse(cmd, tran, depth+1, thisTp) {
+						if !e.TparAnalyse(cmd, tran, depth+1, thisTp) {
 							return false
 						}
 					}
@@ -438,13 +444,13 @@ func TparAnalyse(cmd Commands, tran *TParObject, depth int, thisTp int) bool {
 					ts1 := tran.Str.Get(1)
 					if (ts1 == tmpTp.Str.Get(tmpTp.Len)) &&
 						(ts1 == TpdSpan || ts1 == TpdPrompt || ts1 == TpdEnvironment ||
-							ts1 == TpdSmart || ts1 == TpdExact || ts1 == TpdLit) {
+							ts1 == TpdSmart || ts1 == TpdRegexp || ts1 == TpdExact || ts1 == TpdLit) {
 						// Nested delimiters
-						tran.Dlm = ts1
+						tran.Dlm = byte(ts1)
 						tran.Len--
 						tran.Str.Erase(1, 1)
 						tmpTp.Len--
-						if !TparAnalyse(cmd, tran, depth+1, thisTp) {
+						if !e.TparAnalyse(cmd, tran, depth+1, thisTp) {
 							return false
 						}
 					}
@@ -452,28 +458,28 @@ func TparAnalyse(cmd Commands, tran *TParObject, depth int, thisTp int) bool {
 			}
 			switch delim {
 			case TpdSpan:
-				if !tparSubstitute(tran, cmd, thisTp) {
+				if !e.tparSubstitute(tran, cmd, thisTp) {
 					return false
 				}
 			case TpdEnvironment:
-				if FileData.OldCmds {
-					ScreenMessage(MsgReservedTpd)
+				if e.FileData.OldCmds {
+					e.ScreenMessage(MsgReservedTpd)
 					return false
 				} else {
-					if !tparEnquire(tran) {
+					if !e.tparEnquire(tran) {
 						return false
 					}
 				}
 			case TpdPrompt:
-				if LudwigMode != LudwigBatch {
+				if e.LudwigMode != LudwigBatch {
 					if cmd == CmdVerify {
 						var verifyReply VerifyResponse
 						if tran.Len == 0 {
-							prompt := DfltPrompts[CmdAttrib[cmd].TparInfo[thisTp].PromptName]
-							verifyReply = ScreenVerify(prompt)
+							prompt := e.DfltPrompts[e.CmdAttrib[cmd].TparInfo[thisTp].PromptName]
+							verifyReply = e.ScreenVerify(prompt)
 						} else {
 							prompt := tran.Str.Slice(1, tran.Len)
-							verifyReply = ScreenVerify(prompt)
+							verifyReply = e.ScreenVerify(prompt)
 						}
 						switch verifyReply {
 						case VerifyReplyYes:
@@ -487,20 +493,22 @@ func TparAnalyse(cmd Commands, tran *TParObject, depth int, thisTp int) bool {
 						}
 						tran.Len = 1
 					} else if tran.Len == 0 {
-						prompt := DfltPrompts[CmdAttrib[cmd].TparInfo[thisTp].PromptName]
-						ScreenGetLineP(prompt, &tran.Str, &tran.Len, CmdAttrib[cmd].TpCount, thisTp)
+						prompt := e.DfltPrompts[e.CmdAttrib[cmd].TparInfo[thisTp].PromptName]
+						e.ScreenGetLineP(prompt, &tran.Str, &tran.Len, e.CmdAttrib[cmd].TpCount, thisTp)
+						e.LearnReply(tran)
 					} else {
 						if tran.Con != nil {
-							ScreenMessage(MsgPromptsAreOneLine)
+							e.ScreenMessage(MsgPromptsAreOneLine)
 							return false
 						} else {
 							prompt := tran.Str.Slice(1, tran.Len)
-							ScreenGetLineP(prompt, &tran.Str, &tran.Len, CmdAttrib[cmd].TpCount, thisTp)
+							e.ScreenGetLineP(prompt, &tran.Str, &tran.Len, e.CmdAttrib[cmd].TpCount, thisTp)
+							e.LearnReply(tran)
 						}
 					}
 					tran.Dlm = '\x00'
 				} else {
-					ScreenMessage(MsgInteractiveModeOnly)
+					e.ScreenMessage(MsgInteractiveModeOnly)
 					return false
 				}
 			default:
@@ -508,7 +516,7 @@ func TparAnalyse(cmd Commands, tran *TParObject, depth int, thisTp int) bool {
 			}
 		}
 	}
-	return !TtControlC
+	return !e.TtControlC.Load()
 }
 
 // trim trims leading spaces and uppercases a tpar
@@ -536,14 +544,14 @@ func trim(request *TParObject) {
 }
 
 // TparGet1 gets and processes the first trailing parameter
-func TparGet1(tpar *TParObject, cmd Commands, tran *TParObject) bool {
+func (e *Editor) TparGet1(tpar *TParObject, cmd Commands, tran *TParObject) bool {
 	if tpar == nil {
 		return false
 	}
 	tparDuplicateCon(tpar, tran)
 
-	if TparAnalyse(cmd, tran, 1, 1) {
-		if CmdAttrib[cmd].TparInfo[1].TrimReply {
+	if e.TparAnalyse(cmd, tran, 1, 1) {
+		if e.CmdAttrib[cmd].TparInfo[1].TrimReply {
 			trim(tran)
 		}
 		return true
@@ -552,7 +560,7 @@ func TparGet1(tpar *TParObject, cmd Commands, tran *TParObject) bool {
 }
 
 // TparGet2 gets and processes two trailing parameters
-func TparGet2(tpar *TParObject, cmd Commands, trn1 *TParObject, trn2 *TParObject) bool {
+func (e *Editor) TparGet2(tpar *TParObject, cmd Commands, trn1 *TParObject, trn2 *TParObject) bool {
 	if tpar == nil {
 		return false
 	}
@@ -563,18 +571,18 @@ func TparGet2(tpar *TParObject, cmd Commands, trn1 *TParObject, trn2 *TParObject
 	tparDuplicateCon(tpar, trn1)
 	tparDuplicateCon(tpar.Nxt, trn2)
 
-	if !TparAnalyse(cmd, trn1, 1, 1) {
+	if !e.TparAnalyse(cmd, trn1, 1, 1) {
 		return false
 	}
 	if trn1.Len != 0 {
-		if !TparAnalyse(cmd, trn2, 1, 2) {
+		if !e.TparAnalyse(cmd, trn2, 1, 2) {
 			return false
 		}
 	}
-	if CmdAttrib[cmd].TparInfo[1].TrimReply {
+	if e.CmdAttrib[cmd].TparInfo[1].TrimReply {
 		trim(trn1)
 	}
-	if CmdAttrib[cmd].TparInfo[2].TrimReply {
+	if e.CmdAttrib[cmd].TparInfo[2].TrimReply {
 		trim(trn2)
 	}
 	return true
@@ -13,6 +13,8 @@
 package ludwig
 
 import (
+	"io"
+	"maps"
 	"math/big"
 )
 
@@ -45,6 +47,8 @@ const (
 	OptAutoIndent FrameOptionsElts = iota
 	OptAutoWrap
 	OptNewLine
+	OptHighlight
+	OptMatches
 	OptSpecialFrame // OOPS,COMMAND,HEAP
 )
 
@@ -73,6 +77,9 @@ const (
 	CmdPositionColumn
 	CmdPositionLine
 	CmdOpSysCommand
+	CmdOpSysFilter
+	CmdErrorList
+	CmdErrorNext
 
 	// Window control
 	CmdWindowForward
@@ -86,9 +93,16 @@ const (
 	CmdWindowMiddle
 	CmdWindowSetHeight
 	CmdWindowUpdate
+	CmdWindowSplit
+	CmdWindowSplitVertical
+	CmdWindowNext
+	CmdWindowKill
+	CmdWindowOnly
 
 	// Search and comparison
 	CmdGet
+	CmdGetIncremental
+	CmdOccur
 	CmdNext
 	CmdBridge
 	CmdReplace
@@ -156,6 +170,7 @@ const (
 	CmdSpanExecute
 	CmdSpanExecuteNoRecompile
 	CmdFrameParameters
+	CmdFrameList
 
 	// File commands
 	CmdFileInput
@@ -346,6 +361,7 @@ type FileObject struct {
 	Len            int
 	Buf            []byte
 	PreviousFileId int64
+	Reader         io.Reader // Read from instead of Fd, if set
 
 	// Fields for controlling version backup
 	Purge    bool
@@ -361,12 +377,75 @@ type MarkObject struct {
 // MarkArray represents an array of mark pointers
 type MarkArray [MaxMarkNumber + 1]*MarkObject
 
-// TabArray represents an array of tab stops
-type TabArray [MaxStrLenP + 1]bool
+// TabArray represents the tab stops of a frame.  There is a stop every
+// Every columns, or none at all if Every is zero, except at the columns
+// set or cleared one by one in Stops.
+type TabArray struct {
+	Stops map[int]bool
+	Every int
+}
+
+// IsStop returns true if there is a tab stop at col
+func (t *TabArray) IsStop(col int) bool {
+	if stop, ok := t.Stops[col]; ok {
+		return stop
+	}
+	return t.Every > 0 && col%t.Every == 1%t.Every
+}
+
+// Set sets or clears the tab stop at col
+func (t *TabArray) Set(col int, stop bool) {
+	if t.Stops == nil {
+		t.Stops = make(map[int]bool)
+	}
+	t.Stops[col] = stop
+}
+
+// Clone returns a copy of the tab stops that can be changed separately
+func (t TabArray) Clone() TabArray {
+	return TabArray{Stops: maps.Clone(t.Stops), Every: t.Every}
+}
+
+// Last returns the last column with a stop set one by one, when Every is
+// zero there are no stops past it
+func (t *TabArray) Last() int {
+	last := 0
+	for col, stop := range t.Stops {
+		if stop && col > last {
+			last = col
+		}
+	}
+	return last
+}
 
 // VerifyArray represents an array of verify flags
 type VerifyArray [MaxVerify + 1]bool
 
+// UndoPosition records where a mark was, by line number and column.
+// A LineNr of zero means the mark was not set.
+type UndoPosition struct {
+	LineNr int
+	Col    int
+}
+
+// UndoEntry records that the NewCount lines starting at line First
+// replaced the lines whose text is held in Old.
+type UndoEntry struct {
+	First    int
+	Old      [][]rune
+	NewCount int
+}
+
+// UndoStep holds the entries made by one command, along with the
+// frame's Dot, marks and modified flag from before the command.
+type UndoStep struct {
+	StepNr   int
+	Entries  []UndoEntry
+	Dot      UndoPosition
+	Marks    [MaxMarkNumber + 1]UndoPosition
+	Modified bool
+}
+
 // FrameObject represents a frame in the editor
 type FrameObject struct {
 	FirstGroup    *GroupObject
@@ -399,6 +478,76 @@ type FrameObject struct {
 	RepPatternPtr *DFATableObject
 	Rep2Tpar      TParObject
 	VerifyTpar    TParObject
+	UndoSteps     []*UndoStep
+	RedoSteps     []*UndoStep
+	Syntax        *SyntaxRules // Highlighting rules, nil for none
+	SyntaxFile    string       // The file name Syntax was chosen for
+	SyntaxValidNr int          // Lines up to here have SyntaxEnd worked out
+	JournalFile   string       // The autosave journal written for the frame
+	JournalDirty  bool         // Changed since the journal was written
+	MatchShown    string       // The target whose matches are on the screen
+}
+
+// WindowObject represents one of the windows the screen is split into.  A
+// window that has been split holds its two halves, any other window shows
+// a frame.  The area of a window includes its status line.
+type WindowObject struct {
+	Parent   *WindowObject
+	Halves   [2]*WindowObject
+	Vertical bool // The halves are side by side
+	Frame    *FrameObject
+	Dot      *MarkObject // Dot of the frame when the window was left
+	DotLine  int         // Screen line of Dot when the window was left
+	Offset   int         // Screen offset when the window was left
+	Row      int
+	Col      int
+	Height   int
+	Width    int
+}
+
+// SyntaxClass is the kind of text that part of a line is highlighted as
+type SyntaxClass int
+
+const (
+	SyntaxPlain SyntaxClass = iota
+	SyntaxKeyword
+	SyntaxType
+	SyntaxLiteral
+	SyntaxString
+	SyntaxComment
+	SyntaxDirective
+	SyntaxMatch   // Text found by a search
+	SyntaxClasses // The number of classes
+)
+
+// SyntaxRegion is text between delimiters, such as a comment or a string.
+// A region with no end delimiter ends at the end of the line.
+type SyntaxRegion struct {
+	Class  SyntaxClass
+	Start  []rune
+	End    []rune
+	Escape rune // Stops the next character ending the region, 0 for none
+	Lines  bool // The region can continue onto following lines
+}
+
+// SyntaxRules are the highlighting rules for one kind of file.  The
+// highlighting state at the end of a line is 0, or one more than the index
+// of a region that continues onto the next line.
+type SyntaxRules struct {
+	Name       string
+	Files      []string // Patterns matching the names of files
+	Words      map[string]SyntaxClass
+	IgnoreCase bool
+	Numbers    bool
+	Directive  []rune // Lines starting with this are directives
+	Regions    []SyntaxRegion
+}
+
+// SyntaxRun is part of a line to be highlighted
+type SyntaxRun struct {
+	Col   int
+	Len   int
+	Class SyntaxClass
 }
 
 // GroupObject represents a group of lines
@@ -422,6 +571,9 @@ type LineHdrObject struct {
 	Str      *StrObject
 	Used     int
 	ScrRowNr int
+
+	SyntaxEnd   uint8 // Highlighting state at the end of the line
+	SyntaxShown uint8 // Highlighting state the line was drawn from
 }
 
 func (l *LineHdrObject) Len() int {
@@ -440,6 +592,7 @@ type SpanObject struct {
 	MarkTwo *MarkObject
 	Name    string
 	Code    *CodeHeader
+	Block   bool // Marks are the corners of a rectangular block
 }
 
 // PromptRegionAttrib represents prompt region attributes
@@ -492,6 +645,13 @@ type DFATableObject struct {
 	DFATable      [MaxDFAStateRange + 1]DFAStateType
 	DFAStatesUsed int
 	Definition    PatternDefType
+	RuneSet       big.Int // elements of characters above MaxSetRange in the pattern
+	// The NFA the DFA was built from, its start and final states, and the
+	// number of groups in the pattern, for finding where the groups matched
+	NFA      *NFATableType
+	NFAStart int
+	NFAFinal int
+	Groups   int
 }
 
 // NFATransitionType represents NFA transition
@@ -504,6 +664,11 @@ type NFATransitionType struct {
 	SecondOut int     // for epsilon transitions
 	NextState int     // for non-epsilon transitions
 	AcceptSet big.Int // bitset for non-epsilon transitions
+	// Characters above MaxSetRange left out of a negated set, their class
+	// stays in the AcceptSet
+	ExcludeSet big.Int
+	// The tags a match records its column in when it passes the state
+	Tags []int
 }
 
 // NFATableType represents an NFA table
@@ -534,6 +699,7 @@ type CommandObject struct {
 	Command Commands
 	Code    *CodeHeader
 	Tpar    *TParObject
+	Text    string // The commands Code was compiled from
 }
 
 // TerminalInfoType represents terminal information
@@ -0,0 +1,360 @@
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         UNDO
+//
+// Description:  The undo journal. Each text primitive records the lines
+//               it is about to change, and the changes made by a single
+//               command are grouped into one step of the frame's journal.
+
+package ludwig
+
+// undoCapture holds the text of a range of lines before a change
+type undoCapture struct {
+	frame *FrameObject
+	first int
+	old   [][]rune
+	total int
+	state UndoStep
+}
+
+// undoRecord holds the captures made by one text primitive
+type undoRecord struct {
+	captures []undoCapture
+}
+
+// UndoCheckpoint starts a new undo step.  All changes made until the next
+// checkpoint are undone together.
+func (e *Editor) UndoCheckpoint() {
+	e.UndoStepNr++
+	e.undoStartFrame = e.CurrentFrame
+	if e.CurrentFrame != nil {
+		undoSaveState(e.CurrentFrame, &e.undoStartState)
+	}
+}
+
+// UndoDiscard throws away the undo and redo journals of a frame
+func UndoDiscard(frame *FrameObject) {
+	frame.UndoSteps = nil
+	frame.RedoSteps = nil
+}
+
+// undoSuspend stops the text primitives recording changes until the
+// matching undoResume
+func (e *Editor) undoSuspend() {
+	e.undoDepth++
+}
+
+// undoResume undoes the effect of undoSuspend
+func (e *Editor) undoResume() {
+	e.undoDepth--
+}
+
+// undoBegin is called on entry to a text primitive. It returns nil if the
+// primitive is nested inside another one, or recording is suspended.
+func (e *Editor) undoBegin() *undoRecord {
+	e.undoDepth++
+	if e.undoDepth > 1 {
+		return nil
+	}
+	return &undoRecord{}
+}
+
+// undoLineCount returns the number of lines in a frame, including the null line
+func undoLineCount(frame *FrameObject) int {
+	return frame.LastGroup.FirstLineNr + frame.LastGroup.NrLines - 1
+}
+
+// undoMarkPosition converts a mark into an UndoPosition
+func undoMarkPosition(mark *MarkObject) UndoPosition {
+	if mark == nil {
+		return UndoPosition{}
+	}
+	var lineNr int
+	if !LineToNumber(mark.Line, &lineNr) {
+		return UndoPosition{}
+	}
+	return UndoPosition{LineNr: lineNr, Col: mark.Col}
+}
+
+// undoSaveState records the frame's Dot, marks and modified flag in step
+func undoSaveState(frame *FrameObject, step *UndoStep) {
+	step.Dot = undoMarkPosition(frame.Dot)
+	for i := 0; i <= MaxMarkNumber; i++ {
+		step.Marks[i] = undoMarkPosition(frame.Marks[i])
+	}
+	step.Modified = frame.TextModified
+}
+
+// undoSetMark moves a mark to a recorded position, the null line is used
+// if the line no longer exists
+func undoSetMark(frame *FrameObject, pos UndoPosition, mark **MarkObject) bool {
+	if pos.LineNr == 0 {
+		if *mark != nil {
+			return MarkDestroy(mark)
+		}
+		return true
+	}
+	var line *LineHdrObject
+	if !LineFromNumber(frame, pos.LineNr, &line) {
+		return false
+	}
+	if line == nil {
+		line = frame.LastGroup.LastLine
+	}
+	return MarkCreate(line, pos.Col, mark)
+}
+
+// undoRestoreState puts back the frame's Dot, marks and modified flag
+func undoRestoreState(frame *FrameObject, step *UndoStep) bool {
+	if !undoSetMark(frame, step.Dot, &frame.Dot) {
+		return false
+	}
+	for i := 0; i <= MaxMarkNumber; i++ {
+		if !undoSetMark(frame, step.Marks[i], &frame.Marks[i]) {
+			return false
+		}
+	}
+	frame.TextModified = step.Modified
+	return true
+}
+
+// undoLineText returns the text of count lines starting at line.  The
+// characters are copied as they are, so that characters holding bytes that
+// are not UTF-8 are put back unchanged.
+func undoLineText(line *LineHdrObject, count int) [][]rune {
+	text := make([][]rune, 0, count)
+	for i := 0; i < count && line != nil; i++ {
+		text = append(text, line.Str.RuneSlice(1, line.Used))
+		line = line.FLink
+	}
+	return text
+}
+
+// undoLinesSame tells whether the lines starting at line still have the
+// text recorded in old, without copying them
+func undoLinesSame(line *LineHdrObject, old [][]rune) bool {
+	for _, text := range old {
+		if line == nil || line.Used != len(text) || !line.Str.EqualsRunes(text, 1) {
+			return false
+		}
+		line = line.FLink
+	}
+	return true
+}
+
+// undoChanged tells highlighting and the autosave journal that a line is
+// about to change
+func undoChanged(line *LineHdrObject) {
+	SyntaxChange(line)
+	JournalChange(line)
+}
+
+// capture records the text of count lines starting at first, before they
+// are changed. The null line is never recorded.  Highlighting and the
+// autosave journal are told about every change here, even when it is not
+// recorded.
+func (r *undoRecord) capture(first *LineHdrObject, count int) {
+	undoChanged(first)
+	if r == nil || first.Group == nil {
+		return
+	}
+	frame := first.Group.Frame
+	if frame.Options.Has(OptSpecialFrame) {
+		return
+	}
+	var firstNr int
+	if !LineToNumber(first, &firstNr) {
+		return
+	}
+	total := undoLineCount(frame)
+	count = max(min(count, total-firstNr), 0)
+	c := undoCapture{
+		frame: frame,
+		first: firstNr,
+		old:   undoLineText(first, count),
+		total: total,
+	}
+	undoSaveState(frame, &c.state)
+	r.captures = append(r.captures, c)
+}
+
+// captureBetween records the text of the lines from the first to the last
+// of the given lines, which must all be in the same frame.
+func (r *undoRecord) captureBetween(lines ...*LineHdrObject) {
+	for _, line := range lines {
+		undoChanged(line)
+	}
+	if r == nil || lines[0].Group == nil {
+		return
+	}
+	first := lines[0]
+	var firstNr int
+	var lastNr int
+	if !LineToNumber(first, &firstNr) {
+		return
+	}
+	lastNr = firstNr
+	for _, line := range lines[1:] {
+		var lineNr int
+		if !LineToNumber(line, &lineNr) {
+			return
+		}
+		if lineNr < firstNr {
+			first = line
+			firstNr = lineNr
+		}
+		lastNr = max(lastNr, lineNr)
+	}
+	r.capture(first, lastNr+1-firstNr)
+}
+
+// undoEnd is called on exit from a text primitive with what undoBegin
+// returned. The captured lines are compared with the lines that replaced
+// them and any changes are added to the frame's current undo step.
+func (e *Editor) undoEnd(r *undoRecord) {
+	e.undoDepth--
+	if r == nil {
+		return
+	}
+	for i := range r.captures {
+		c := &r.captures[i]
+		newCount := len(c.old) + undoLineCount(c.frame) - c.total
+		if newCount == len(c.old) {
+			var line *LineHdrObject
+			if !LineFromNumber(c.frame, c.first, &line) || line == nil {
+				continue
+			}
+			if undoLinesSame(line, c.old) {
+				continue
+			}
+		}
+		frame := c.frame
+		n := len(frame.UndoSteps)
+		if n == 0 || frame.UndoSteps[n-1].StepNr != e.UndoStepNr {
+			// Commands can move Dot and the marks before changing any text,
+			// so prefer the state from before the command started.
+			step := c.state
+			if frame == e.undoStartFrame {
+				step = e.undoStartState
+			}
+			step.StepNr = e.UndoStepNr
+			frame.UndoSteps = append(frame.UndoSteps, &step)
+			if n+1 > MaxUndoSteps {
+				frame.UndoSteps = frame.UndoSteps[1:]
+			}
+			frame.RedoSteps = nil
+			n = len(frame.UndoSteps)
+		}
+		step := frame.UndoSteps[n-1]
+		step.Entries = append(step.Entries, UndoEntry{First: c.first, Old: c.old, NewCount: newCount})
+	}
+}
+
+// undoSetLine replaces the text of a line
+func (e *Editor) undoSetLine(line *LineHdrObject, text []rune) bool {
+	undoChanged(line)
+	if len(text) > line.Len() {
+		if !LineChangeLength(line, len(text)) {
+			return false
+		}
+	}
+	if line.Len() > 0 {
+		line.Str.FillCopyRunes(text, 1, line.Len(), ' ')
+	}
+	line.Used = len(text)
+	if line.ScrRowNr != 0 {
+		e.ScreenDrawLine(line)
+	}
+	return true
+}
+
+// undoApply puts back the lines recorded in entry, and turns entry into
+// the entry that will reverse what was just done.
+func (e *Editor) undoApply(frame *FrameObject, entry *UndoEntry) bool {
+	var line *LineHdrObject
+	if !LineFromNumber(frame, entry.First, &line) || line == nil {
+		e.ScreenMessage(DbgLineFromNumberFailed)
+		return false
+	}
+	current := undoLineText(line, entry.NewCount)
+	keep := min(entry.NewCount, len(entry.Old))
+	for i := 0; i < keep; i++ {
+		if !e.undoSetLine(line, entry.Old[i]) {
+			return false
+		}
+		line = line.FLink
+	}
+	if len(entry.Old) > keep {
+		var firstLine *LineHdrObject
+		var lastLine *LineHdrObject
+		if !LinesCreate(len(entry.Old)-keep, &firstLine, &lastLine) {
+			return false
+		}
+		newLine := firstLine
+		for _, text := range entry.Old[keep:] {
+			if !e.undoSetLine(newLine, text) {
+				return false
+			}
+			newLine = newLine.FLink
+		}
+		if !e.LinesInject(firstLine, lastLine, line) {
+			return false
+		}
+	} else if entry.NewCount > keep {
+		lastLine := line
+		for i := keep + 1; i < entry.NewCount; i++ {
+			lastLine = lastLine.FLink
+		}
+		if !MarksSqueeze(line, 1, lastLine.FLink, 1) {
+			return false
+		}
+		if !e.LinesExtract(line, lastLine) {
+			return false
+		}
+		if !LinesDestroy(&line, &lastLine) {
+			return false
+		}
+	}
+	entry.NewCount = len(entry.Old)
+	entry.Old = current
+	return true
+}
+
+// UndoReplay reverses the last step of steps and pushes the step that will
+// reverse it again onto others. It is used for both undo and redo.
+func (e *Editor) UndoReplay(frame *FrameObject, steps *[]*UndoStep, others *[]*UndoStep) bool {
+	n := len(*steps)
+	if n == 0 {
+		return false
+	}
+	step := (*steps)[n-1]
+	*steps = (*steps)[:n-1]
+
+	var reverse UndoStep
+	reverse.StepNr = step.StepNr
+	undoSaveState(frame, &reverse)
+
+	e.undoDepth++
+	defer func() { e.undoDepth-- }()
+	for i := len(step.Entries) - 1; i >= 0; i-- {
+		if !e.undoApply(frame, &step.Entries[i]) {
+			return false
+		}
+	}
+	for i := len(step.Entries) - 1; i >= 0; i-- {
+		reverse.Entries = append(reverse.Entries, step.Entries[i])
+	}
+	if !undoRestoreState(frame, step) {
+		return false
+	}
+	*others = append(*others, &reverse)
+	return true
+}
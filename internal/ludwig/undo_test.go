@@ -0,0 +1,218 @@
+// Tests for undo.go functions
+
+package ludwig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupUndoFrame builds a well formed frame holding the given lines, and
+// makes it the current frame.
+func setupUndoFrame(t *testing.T, e *Editor, lines ...string) *FrameObject {
+	frame := &FrameObject{
+		SpaceLeft:   MaxSpace,
+		SpaceLimit:  MaxSpace,
+		ScrWidth:    80,
+		MarginLeft:  1,
+		MarginRight: MaxStrLen,
+	}
+	var group *GroupObject
+	require.True(t, LineEOPCreate(frame, &group))
+	frame.FirstGroup = group
+	frame.LastGroup = group
+	if len(lines) > 0 {
+		var first *LineHdrObject
+		var last *LineHdrObject
+		require.True(t, LinesCreate(len(lines), &first, &last))
+		line := first
+		for _, text := range lines {
+			require.True(t, e.undoSetLine(line, []rune(text)))
+			line = line.FLink
+		}
+		e.undoSuspend()
+		require.True(t, e.LinesInject(first, last, group.LastLine))
+		e.undoResume()
+	}
+	require.True(t, MarkCreate(frame.FirstGroup.FirstLine, 1, &frame.Dot))
+	e.CurrentFrame = frame
+	return frame
+}
+
+// frameText returns the text of all lines in a frame, except the null line
+func frameText(frame *FrameObject) []string {
+	text := []string{}
+	for line := frame.FirstGroup.FirstLine; line.FLink != nil; line = line.FLink {
+		text = append(text, line.Str.Slice(1, line.Used))
+	}
+	return text
+}
+
+func TestUserUndoInsert(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "hello", "world")
+
+	frame.Dot.Col = 6
+	e.UndoCheckpoint()
+	require.True(t, e.TextInsert(false, 1, NewStrObjectFrom(" there"), 6, frame.Dot))
+	frame.TextModified = true
+	assert.Equal(t, []string{"hello there", "world"}, frameText(frame))
+	assert.Equal(t, 12, frame.Dot.Col)
+
+	assert.True(t, e.UserUndo(LeadParamNone, 1))
+	assert.Equal(t, []string{"hello", "world"}, frameText(frame))
+	assert.Equal(t, 6, frame.Dot.Col)
+	assert.False(t, frame.TextModified)
+	assert.Empty(t, frame.UndoSteps)
+	assert.Len(t, frame.RedoSteps, 1)
+
+	assert.True(t, e.UserUndo(LeadParamMinus, -1))
+	assert.Equal(t, []string{"hello there", "world"}, frameText(frame))
+	assert.Equal(t, 12, frame.Dot.Col)
+	assert.True(t, frame.TextModified)
+	assert.Len(t, frame.UndoSteps, 1)
+	assert.Empty(t, frame.RedoSteps)
+}
+
+func TestUserUndoMultiLineRemove(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "one", "two", "three", "four")
+	lines := frame.FirstGroup.FirstLine
+
+	var markTwo *MarkObject
+	require.True(t, MarkCreate(lines.FLink.FLink, 3, &markTwo))
+	require.True(t, MarkCreate(lines, 2, &frame.Dot))
+	require.True(t, MarkCreate(lines.FLink.FLink.FLink, 2, &frame.Marks[1]))
+
+	e.UndoCheckpoint()
+	require.True(t, MarkCreate(lines.FLink, 1, &frame.Marks[2]))
+	require.True(t, e.TextRemove(frame.Dot, markTwo))
+	assert.Equal(t, []string{"oree", "four"}, frameText(frame))
+
+	assert.True(t, e.UserUndo(LeadParamNone, 1))
+	assert.Equal(t, []string{"one", "two", "three", "four"}, frameText(frame))
+	assert.Equal(t, "one", frame.Dot.Line.Str.Slice(1, frame.Dot.Line.Used))
+	assert.Equal(t, 2, frame.Dot.Col)
+	require.NotNil(t, frame.Marks[1])
+	assert.Equal(t, "four", frame.Marks[1].Line.Str.Slice(1, frame.Marks[1].Line.Used))
+	assert.Equal(t, 2, frame.Marks[1].Col)
+	assert.Nil(t, frame.Marks[2])
+
+	assert.True(t, e.UserUndo(LeadParamNInt, -1))
+	assert.Equal(t, []string{"oree", "four"}, frameText(frame))
+}
+
+func TestUserUndoSplitAndExtract(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "abcdef", "ghi")
+
+	frame.Dot.Col = 4
+	e.UndoCheckpoint()
+	require.True(t, e.TextSplitLine(frame.Dot, 1, &frame.Marks[MarkEquals]))
+	assert.Equal(t, []string{"abc", "def", "ghi"}, frameText(frame))
+
+	e.UndoCheckpoint()
+	last := frame.LastGroup.LastLine.BLink
+	require.True(t, MarksSqueeze(last, 1, last.FLink, 1))
+	require.True(t, e.LinesExtract(last, last))
+	assert.Equal(t, []string{"abc", "def"}, frameText(frame))
+
+	assert.True(t, e.UserUndo(LeadParamPInt, 2))
+	assert.Equal(t, []string{"abcdef", "ghi"}, frameText(frame))
+	assert.Len(t, frame.RedoSteps, 2)
+
+	assert.True(t, e.UserUndo(LeadParamMinus, -1))
+	assert.Equal(t, []string{"abc", "def", "ghi"}, frameText(frame))
+	assert.True(t, e.UserUndo(LeadParamNIndef, 0))
+	assert.Equal(t, []string{"abc", "def"}, frameText(frame))
+	assert.Empty(t, frame.RedoSteps)
+}
+
+func TestUserUndoGroupsCommand(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "text")
+
+	e.UndoCheckpoint()
+	require.True(t, e.TextInsert(false, 1, NewStrObjectFrom("ab"), 2, frame.Dot))
+	require.True(t, e.TextOvertype(false, 1, NewStrObjectFrom("XY"), 2, frame.Dot))
+	assert.Equal(t, []string{"abXYxt"}, frameText(frame))
+	assert.Len(t, frame.UndoSteps, 1)
+
+	assert.True(t, e.UserUndo(LeadParamNone, 1))
+	assert.Equal(t, []string{"text"}, frameText(frame))
+}
+
+func TestUserUndoNothing(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "text")
+
+	assert.False(t, e.UserUndo(LeadParamNone, 1))
+	assert.False(t, e.UserUndo(LeadParamMinus, -1))
+
+	e.UndoCheckpoint()
+	require.True(t, e.TextInsert(false, 1, NewStrObjectFrom("x"), 1, frame.Dot))
+	assert.False(t, e.UserUndo(LeadParamPInt, 2))
+	assert.Equal(t, []string{"xtext"}, frameText(frame))
+
+	// A new change throws away anything that could be redone
+	assert.True(t, e.UserUndo(LeadParamNone, 1))
+	e.UndoCheckpoint()
+	require.True(t, e.TextInsert(false, 1, NewStrObjectFrom("y"), 1, frame.Dot))
+	assert.Empty(t, frame.RedoSteps)
+	assert.False(t, e.UserUndo(LeadParamMinus, -1))
+}
+
+func TestUndoIgnoresSpecialFrames(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "text")
+	frame.Options.Set(OptSpecialFrame)
+
+	e.UndoCheckpoint()
+	require.True(t, e.TextInsert(false, 1, NewStrObjectFrom("x"), 1, frame.Dot))
+	assert.Empty(t, frame.UndoSteps)
+}
+
+func TestUndoNullLine(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e)
+
+	e.UndoCheckpoint()
+	require.True(t, e.TextInsert(false, 1, NewStrObjectFrom("new"), 3, frame.Dot))
+	assert.Equal(t, []string{"new"}, frameText(frame))
+
+	assert.True(t, e.UserUndo(LeadParamNone, 1))
+	assert.Equal(t, []string{}, frameText(frame))
+	assert.Nil(t, frame.Dot.Line.FLink)
+}
+
+func TestUndoKeepsCharacters(t *testing.T) {
+	e := NewEditor()
+	frame := setupUndoFrame(t, e, "héllo", "")
+	first := frame.FirstGroup.FirstLine
+	raw := []rune{ChFromRawByte(0xff), 'a', 'b'}
+	require.True(t, e.undoSetLine(first.FLink, raw))
+	lineRunes := func(line *LineHdrObject) []rune {
+		return line.Str.Runes()[:line.Used]
+	}
+
+	e.UndoCheckpoint()
+	require.True(t, e.TextInsert(false, 1, NewStrObjectFrom("X"), 1, frame.Dot))
+	require.True(t, MarkCreate(first.FLink, 1, &frame.Dot))
+	require.True(t, e.TextInsert(false, 1, NewStrObjectFrom("X"), 1, frame.Dot))
+	assert.Equal(t, []rune("Xhéllo"), lineRunes(first))
+	assert.Equal(t, append([]rune("X"), raw...), lineRunes(first.FLink))
+
+	// Undo and redo put back the same characters, and no more of them
+	for range 3 {
+		assert.True(t, e.UserUndo(LeadParamNone, 1))
+		assert.Equal(t, []rune("héllo"), lineRunes(first))
+		assert.Equal(t, raw, lineRunes(first.FLink))
+		assert.Equal(t, []byte("\xffab"), ChAppendUTF8(nil, first.FLink.Str, 1, first.FLink.Used))
+
+		assert.True(t, e.UserUndo(LeadParamMinus, -1))
+		assert.Equal(t, []rune("Xhéllo"), lineRunes(first))
+		assert.Equal(t, append([]rune("X"), raw...), lineRunes(first.FLink))
+	}
+}
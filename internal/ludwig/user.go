@@ -21,10 +21,10 @@ func specialCommand(cmd Commands) bool {
 }
 
 // UserKeyCodeToName converts a key code to its name
-func UserKeyCodeToName(keyCode int, keyName *string) bool {
-	for i := 1; i < len(KeyNameList); i++ {
-		if KeyNameList[i].KeyCode == keyCode {
-			*keyName = KeyNameList[i].KeyName
+func (e *Editor) UserKeyCodeToName(keyCode int, keyName *string) bool {
+	for i := 1; i < len(e.KeyNameList); i++ {
+		if e.KeyNameList[i].KeyCode == keyCode {
+			*keyName = e.KeyNameList[i].KeyName
 			return true
 		}
 	}
@@ -32,53 +32,61 @@ func UserKeyCodeToName(keyCode int, keyName *string) bool {
 }
 
 // UserKeyNameToCode converts a key name to its code
-func UserKeyNameToCode(keyName string, keyCode *int) bool {
-	for i := 1; i < len(KeyNameList); i++ {
-		if KeyNameList[i].KeyName == keyName {
-			*keyCode = KeyNameList[i].KeyCode
+func (e *Editor) UserKeyNameToCode(keyName string, keyCode *int) bool {
+	for i := 1; i < len(e.KeyNameList); i++ {
+		if e.KeyNameList[i].KeyName == keyName {
+			*keyCode = e.KeyNameList[i].KeyCode
 			return true
 		}
 	}
 	return false
 }
 
+// KeyLookup returns the entry in Lookup for a key
+func (e *Editor) KeyLookup(key int) *CommandObject {
+	if key < 0 || key >= len(e.Lookup) {
+		return &e.noKeyLookup
+	}
+	return &e.Lookup[key]
+}
+
 // UserKeyInitialize initializes terminal-defined key map table
-func UserKeyInitialize() {
+func (e *Editor) UserKeyInitialize() {
 	var keyCode int
-	VduKeyboardInit(&NrKeyNames, &KeyNameList, &KeyIntroducers, &TerminalInfo)
+	e.VduKeyboardInit(&e.NrKeyNames, &e.KeyNameList, &e.KeyIntroducers, &e.TerminalInfo)
 
-	if UserKeyNameToCode("UP-ARROW", &keyCode) {
-		Lookup[keyCode].Command = CmdUp
+	if e.UserKeyNameToCode("UP-ARROW", &keyCode) {
+		e.Lookup[keyCode].Command = CmdUp
 	}
-	if UserKeyNameToCode("DOWN-ARROW", &keyCode) {
-		Lookup[keyCode].Command = CmdDown
+	if e.UserKeyNameToCode("DOWN-ARROW", &keyCode) {
+		e.Lookup[keyCode].Command = CmdDown
 	}
-	if UserKeyNameToCode("RIGHT-ARROW", &keyCode) {
-		Lookup[keyCode].Command = CmdRight
+	if e.UserKeyNameToCode("RIGHT-ARROW", &keyCode) {
+		e.Lookup[keyCode].Command = CmdRight
 	}
-	if UserKeyNameToCode("LEFT-ARROW", &keyCode) {
-		Lookup[keyCode].Command = CmdLeft
+	if e.UserKeyNameToCode("LEFT-ARROW", &keyCode) {
+		e.Lookup[keyCode].Command = CmdLeft
 	}
-	if UserKeyNameToCode("HOME", &keyCode) {
-		Lookup[keyCode].Command = CmdHome
+	if e.UserKeyNameToCode("HOME", &keyCode) {
+		e.Lookup[keyCode].Command = CmdHome
 	}
-	if UserKeyNameToCode("BACK-TAB", &keyCode) {
-		Lookup[keyCode].Command = CmdBacktab
+	if e.UserKeyNameToCode("BACK-TAB", &keyCode) {
+		e.Lookup[keyCode].Command = CmdBacktab
 	}
-	if UserKeyNameToCode("INSERT-CHAR", &keyCode) {
-		Lookup[keyCode].Command = CmdInsertChar
+	if e.UserKeyNameToCode("INSERT-CHAR", &keyCode) {
+		e.Lookup[keyCode].Command = CmdInsertChar
 	}
-	if UserKeyNameToCode("DELETE-CHAR", &keyCode) {
-		Lookup[keyCode].Command = CmdDeleteChar
+	if e.UserKeyNameToCode("DELETE-CHAR", &keyCode) {
+		e.Lookup[keyCode].Command = CmdDeleteChar
 	}
-	if UserKeyNameToCode("INSERT-LINE", &keyCode) {
-		Lookup[keyCode].Command = CmdInsertLine
+	if e.UserKeyNameToCode("INSERT-LINE", &keyCode) {
+		e.Lookup[keyCode].Command = CmdInsertLine
 	}
-	if UserKeyNameToCode("DELETE-LINE", &keyCode) {
-		Lookup[keyCode].Command = CmdDeleteLine
+	if e.UserKeyNameToCode("DELETE-LINE", &keyCode) {
+		e.Lookup[keyCode].Command = CmdDeleteLine
 	}
-	if UserKeyNameToCode("HELP", &keyCode) {
-		Lookup[keyCode].Command = CmdHelp
+	if e.UserKeyNameToCode("HELP", &keyCode) {
+		e.Lookup[keyCode].Command = CmdHelp
 		tpar := &TParObject{
 			Str: EmptyStrObject(),
 			Dlm: TpdPrompt,
@@ -86,10 +94,10 @@ func UserKeyInitialize() {
 			Con: nil,
 			Nxt: nil,
 		}
-		Lookup[keyCode].Tpar = tpar
+		e.Lookup[keyCode].Tpar = tpar
 	}
-	if UserKeyNameToCode("FIND", &keyCode) {
-		Lookup[keyCode].Command = CmdGet
+	if e.UserKeyNameToCode("FIND", &keyCode) {
+		e.Lookup[keyCode].Command = CmdGet
 		tpar := &TParObject{
 			Str: EmptyStrObject(),
 			Dlm: TpdPrompt,
@@ -97,55 +105,55 @@ func UserKeyInitialize() {
 			Con: nil,
 			Nxt: nil,
 		}
-		Lookup[keyCode].Tpar = tpar
+		e.Lookup[keyCode].Tpar = tpar
 	}
-	if UserKeyNameToCode("PREV-SCREEN", &keyCode) {
-		Lookup[keyCode].Command = CmdWindowBackward
+	if e.UserKeyNameToCode("PREV-SCREEN", &keyCode) {
+		e.Lookup[keyCode].Command = CmdWindowBackward
 	}
-	if UserKeyNameToCode("NEXT-SCREEN", &keyCode) {
-		Lookup[keyCode].Command = CmdWindowForward
+	if e.UserKeyNameToCode("NEXT-SCREEN", &keyCode) {
+		e.Lookup[keyCode].Command = CmdWindowForward
 	}
-	if UserKeyNameToCode("PAGE-UP", &keyCode) {
-		Lookup[keyCode].Command = CmdWindowBackward
+	if e.UserKeyNameToCode("PAGE-UP", &keyCode) {
+		e.Lookup[keyCode].Command = CmdWindowBackward
 	}
-	if UserKeyNameToCode("PAGE-DOWN", &keyCode) {
-		Lookup[keyCode].Command = CmdWindowForward
+	if e.UserKeyNameToCode("PAGE-DOWN", &keyCode) {
+		e.Lookup[keyCode].Command = CmdWindowForward
 	}
-	if UserKeyNameToCode("WINDOW-RESIZE-EVENT", &keyCode) {
-		Lookup[keyCode].Command = CmdResizeWindow
+	if e.UserKeyNameToCode("WINDOW-RESIZE-EVENT", &keyCode) {
+		e.Lookup[keyCode].Command = CmdResizeWindow
 	}
 }
 
 // UserCommandIntroducer enters command introducer into text in correct keyboard mode
-func UserCommandIntroducer() bool {
-	if !ChIsPrintable(rune(CommandIntroducer)) {
-		ScreenMessage(MsgNonprintableIntroducer)
+func (e *Editor) UserCommandIntroducer() bool {
+	if !ChIsPrintableKey(e.CommandIntroducer) {
+		e.ScreenMessage(MsgNonprintableIntroducer)
 		return false
 	}
 
-	temp := NewBlankStrObject(MaxStrLen)
-	temp.Set(1, byte(CommandIntroducer))
+	temp := EmptyStrObject()
+	temp.Set(1, rune(e.CommandIntroducer))
 	cmdSuccess := true
 
-	switch EditMode {
+	switch e.EditMode {
 	case ModeInsert:
-		cmdSuccess = TextInsert(true, 1, temp, 1, CurrentFrame.Dot)
+		cmdSuccess = e.TextInsert(true, 1, temp, 1, e.CurrentFrame.Dot)
 	case ModeCommand:
-		if PreviousMode == ModeInsert {
-			cmdSuccess = TextInsert(true, 1, temp, 1, CurrentFrame.Dot)
+		if e.PreviousMode == ModeInsert {
+			cmdSuccess = e.TextInsert(true, 1, temp, 1, e.CurrentFrame.Dot)
 		} else {
-			cmdSuccess = TextOvertype(true, 1, temp, 1, CurrentFrame.Dot)
+			cmdSuccess = e.TextOvertype(true, 1, temp, 1, e.CurrentFrame.Dot)
 		}
 	case ModeOvertype:
-		cmdSuccess = TextOvertype(true, 1, temp, 1, CurrentFrame.Dot)
+		cmdSuccess = e.TextOvertype(true, 1, temp, 1, e.CurrentFrame.Dot)
 	}
 
 	if cmdSuccess {
-		CurrentFrame.TextModified = true
+		e.CurrentFrame.TextModified = true
 		if !MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkModified],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkModified],
 		) {
 			cmdSuccess = false
 		}
@@ -154,7 +162,7 @@ func UserCommandIntroducer() bool {
 }
 
 // UserKey assigns a key to a command string
-func UserKey(key *TParObject, strng *TParObject) bool {
+func (e *Editor) UserKey(key *TParObject, strng *TParObject) bool {
 	result := false
 	var keyCode int
 
@@ -162,60 +170,62 @@ func UserKey(key *TParObject, strng *TParObject) bool {
 		keyCode = int(key.Str.Get(1))
 	} else {
 		keyName := key.Str.Slice(1, key.Len)
-		if !UserKeyNameToCode(keyName, &keyCode) {
-			ScreenMessage(MsgUnrecognizedKeyName)
+		if !e.UserKeyNameToCode(keyName, &keyCode) {
+			e.ScreenMessage(MsgUnrecognizedKeyName)
 			return false
 		}
 	}
 
 	// Create a span in frame "HEAP"
-	if !MarkCreate(FrameHeap.LastGroup.LastLine, 1, &FrameHeap.Span.MarkTwo) {
+	if !MarkCreate(e.FrameHeap.LastGroup.LastLine, 1, &e.FrameHeap.Span.MarkTwo) {
 		return false
 	}
-	if !SpanCreate(BlankFrameName, FrameHeap.Span.MarkTwo, FrameHeap.Span.MarkTwo) {
+	if !e.SpanCreate(BlankFrameName, e.FrameHeap.Span.MarkTwo, e.FrameHeap.Span.MarkTwo) {
 		return false
 	}
 
 	var keySpan *SpanObject
 	var oldSpan *SpanObject
-	if SpanFind(BlankFrameName, &keySpan, &oldSpan) {
+	if e.SpanFind(BlankFrameName, &keySpan, &oldSpan) {
 		success := false
 		var keyMarkOne *MarkObject = keySpan.MarkOne
-		if TextInsertTpar(strng, keySpan.MarkTwo, &keyMarkOne) {
-			if CodeCompile(keySpan, true) {
+		if e.TextInsertTpar(strng, keySpan.MarkTwo, &keyMarkOne) {
+			if e.CodeCompile(keySpan, true) {
 				// discard code_ptr, if it exists, NOW!
-				if Lookup[keyCode].Code != nil {
-					CodeDiscard(&Lookup[keyCode].Code)
+				if e.Lookup[keyCode].Code != nil {
+					e.CodeDiscard(&e.Lookup[keyCode].Code)
 				}
-				if Lookup[keyCode].Tpar != nil {
-					TparCleanObject(Lookup[keyCode].Tpar)
-					Lookup[keyCode].Tpar = nil
+				if e.Lookup[keyCode].Tpar != nil {
+					TparCleanObject(e.Lookup[keyCode].Tpar)
+					e.Lookup[keyCode].Tpar = nil
 				}
 
 				code := keySpan.Code
-				if (code.Len == 2) && (CompilerCode[code.Code].Rep == LeadParamNone) &&
-					!specialCommand(CompilerCode[code.Code].Op) {
+				if (code.Len == 2) && (e.CompilerCode[code.Code].Rep == LeadParamNone) &&
+					!specialCommand(e.CompilerCode[code.Code].Op) {
 					// simple command, put directly into lookup table
-					Lookup[keyCode].Command = CompilerCode[code.Code].Op
-					Lookup[keyCode].Tpar = CompilerCode[code.Code].Tpar
-					CompilerCode[code.Code].Tpar = nil
+					e.Lookup[keyCode].Command = e.CompilerCode[code.Code].Op
+					e.Lookup[keyCode].Tpar = e.CompilerCode[code.Code].Tpar
+					e.Lookup[keyCode].Text = ""
+					e.CompilerCode[code.Code].Tpar = nil
 				} else {
-					Lookup[keyCode].Command = CmdExtended
-					Lookup[keyCode].Code = code
+					e.Lookup[keyCode].Command = CmdExtended
+					e.Lookup[keyCode].Code = code
+					e.Lookup[keyCode].Text = learnTparString(strng)
 					keySpan.Code = nil
 				}
 				success = true
 			}
 		}
-		SpanDestroy(&keySpan)
+		e.SpanDestroy(&keySpan)
 		result = success
 	}
 	return result
 }
 
 // UserParent suspends Ludwig and returns to parent shell
-func UserParent() bool {
-	return SysSuspend()
+func (e *Editor) UserParent() bool {
+	return e.SysSuspend()
 }
 
 // UserSubprocess spawns a subshell
@@ -223,8 +233,32 @@ func UserSubprocess() bool {
 	return SysShell()
 }
 
-// UserUndo performs undo operation (not implemented)
-func UserUndo() bool {
-	ScreenMessage(MsgNotImplemented)
-	return false
+// UserUndo undoes the last count changes made to the current frame.
+// A negative count redoes changes that were undone.
+func (e *Editor) UserUndo(rept LeadParam, count int) bool {
+	steps := &e.CurrentFrame.UndoSteps
+	others := &e.CurrentFrame.RedoSteps
+	msg := MsgNothingToUndo
+	switch rept {
+	case LeadParamMinus, LeadParamNInt:
+		count = -count
+		steps, others = others, steps
+		msg = MsgNothingToRedo
+	case LeadParamNIndef:
+		steps, others = others, steps
+		msg = MsgNothingToRedo
+		count = len(*steps)
+	case LeadParamPIndef:
+		count = len(*steps)
+	}
+	if count == 0 || count > len(*steps) {
+		e.ScreenMessage(msg)
+		return false
+	}
+	for ; count > 0; count-- {
+		if !e.UndoReplay(e.CurrentFrame, steps, others) {
+			return false
+		}
+	}
+	return true
 }
@@ -15,7 +15,7 @@
 package ludwig
 
 // ValidateCommand validates the data structure
-func ValidateCommand() bool {
+func (e *Editor) ValidateCommand() bool {
 	/*
 	  Purpose  : Validate the data structure.
 	  Inputs   : none.
@@ -31,59 +31,59 @@ func ValidateCommand() bool {
 		heap = 0x0004
 	)
 
-	if CurrentFrame == nil || FrameOops == nil || FrameCmd == nil || FrameHeap == nil {
-		ScreenMessage(DbgInvalidFramePtr)
+	if e.CurrentFrame == nil || e.FrameOops == nil || e.FrameCmd == nil || e.FrameHeap == nil {
+		e.ScreenMessage(DbgInvalidFramePtr)
 		return false
 	}
 
-	if FirstSpan == nil {
-		ScreenMessage(DbgInvalidSpanPtr)
+	if e.FirstSpan == nil {
+		e.ScreenMessage(DbgInvalidSpanPtr)
 		return false
 	}
 
 	// Validate the data structure.
 	frameList := 0 // Bit mask OOPS, CMD, HEAP
 	scrRow := 0
-	thisSpan := FirstSpan
+	thisSpan := e.FirstSpan
 	var prevSpan *SpanObject
 
 	for thisSpan != nil {
 		if thisSpan.BLink != prevSpan {
-			ScreenMessage(DbgInvalidBlink)
+			e.ScreenMessage(DbgInvalidBlink)
 			return false
 		}
 		if thisSpan.MarkOne == nil || thisSpan.MarkTwo == nil {
-			ScreenMessage(DbgMarkPtrIsNil)
+			e.ScreenMessage(DbgMarkPtrIsNil)
 			return false
 		}
 		if thisSpan.Code != nil {
 			if thisSpan.Code.Ref == 0 {
-				ScreenMessage(DbgRefCountIsZero)
+				e.ScreenMessage(DbgRefCountIsZero)
 				return false
 			}
 		}
 		if thisSpan.Frame != nil {
 			thisFrame := thisSpan.Frame
 			switch thisFrame {
-			case FrameCmd:
+			case e.FrameCmd:
 				frameList |= cmd
-			case FrameOops:
+			case e.FrameOops:
 				frameList |= oops
-			case FrameHeap:
+			case e.FrameHeap:
 				frameList |= heap
 			}
 
 			if thisFrame.FirstGroup == nil || thisFrame.LastGroup == nil {
-				ScreenMessage(DbgInvalidGroupPtr)
+				e.ScreenMessage(DbgInvalidGroupPtr)
 				return false
 			}
 			if thisFrame.FirstGroup.BLink != nil {
-				ScreenMessage(DbgFirstNotAtTop)
+				e.ScreenMessage(DbgFirstNotAtTop)
 				return false
 			}
 			endGroup := thisFrame.LastGroup.FLink
 			if endGroup != nil {
-				ScreenMessage(DbgLastNotAtEnd)
+				e.ScreenMessage(DbgLastNotAtEnd)
 				return false
 			}
 			thisGroup := thisFrame.FirstGroup
@@ -95,19 +95,19 @@ func ValidateCommand() bool {
 
 			for thisGroup != endGroup {
 				if thisGroup.BLink != prevGroup {
-					ScreenMessage(DbgInvalidBlink)
+					e.ScreenMessage(DbgInvalidBlink)
 					return false
 				}
 				if thisGroup.Frame != thisFrame {
-					ScreenMessage(DbgInvalidFramePtr)
+					e.ScreenMessage(DbgInvalidFramePtr)
 					return false
 				}
 				if thisGroup.FirstLine == nil || thisGroup.LastLine == nil {
-					ScreenMessage(DbgLinePtrIsNil)
+					e.ScreenMessage(DbgLinePtrIsNil)
 					return false
 				}
 				if thisGroup.FirstLine != thisLine {
-					ScreenMessage(DbgFirstNotAtTop)
+					e.ScreenMessage(DbgFirstNotAtTop)
 					return false
 				}
 				lineCount := 0
@@ -115,41 +115,41 @@ func ValidateCommand() bool {
 
 				for thisLine != endLine {
 					if thisLine.BLink != prevLine {
-						ScreenMessage(DbgInvalidBlink)
+						e.ScreenMessage(DbgInvalidBlink)
 						return false
 					}
 					if thisLine.Group != thisGroup {
-						ScreenMessage(DbgInvalidGroupPtr)
+						e.ScreenMessage(DbgInvalidGroupPtr)
 						return false
 					}
 					if thisLine.OffsetNr != lineCount {
-						ScreenMessage(DbgInvalidOffsetNr)
+						e.ScreenMessage(DbgInvalidOffsetNr)
 						return false
 					}
 					for _, thisMark := range thisLine.Marks {
 						if thisMark.Line != thisLine {
-							ScreenMessage(DbgInvalidLinePtr)
+							e.ScreenMessage(DbgInvalidLinePtr)
 							return false
 						}
 					}
 					if thisLine.Str == nil && thisLine.Len() != 0 {
-						ScreenMessage(DbgInvalidLineLength)
+						e.ScreenMessage(DbgInvalidLineLength)
 						return false
 					}
 					if thisLine.Used > thisLine.Len() {
-						ScreenMessage(DbgInvalidLineUsedLength)
+						e.ScreenMessage(DbgInvalidLineUsedLength)
 						return false
 					}
 					if thisLine.ScrRowNr != scrRow {
-						if thisLine == ScrTopLine {
+						if thisLine == e.ScrTopLine {
 							scrRow = thisLine.ScrRowNr
 						} else {
-							ScreenMessage(DbgInvalidScrRowNr)
+							e.ScreenMessage(DbgInvalidScrRowNr)
 							return false
 						}
 					}
 					if scrRow != 0 {
-						if thisLine != ScrBotLine {
+						if thisLine != e.ScrBotLine {
 							scrRow++
 						} else {
 							scrRow = 0
@@ -161,15 +161,15 @@ func ValidateCommand() bool {
 				}
 
 				if thisGroup.LastLine != prevLine {
-					ScreenMessage(DbgLastNotAtEnd)
+					e.ScreenMessage(DbgLastNotAtEnd)
 					return false
 				}
 				if thisGroup.FirstLineNr != lineNr {
-					ScreenMessage(DbgInvalidLineNr)
+					e.ScreenMessage(DbgInvalidLineNr)
 					return false
 				}
 				if thisGroup.NrLines != lineCount {
-					ScreenMessage(DbgInvalidNrLines)
+					e.ScreenMessage(DbgInvalidNrLines)
 					return false
 				}
 				lineNr = lineNr + thisGroup.NrLines
@@ -178,52 +178,52 @@ func ValidateCommand() bool {
 			}
 
 			if thisFrame.FirstGroup.FirstLine.BLink != nil {
-				ScreenMessage(DbgFirstNotAtTop)
+				e.ScreenMessage(DbgFirstNotAtTop)
 				return false
 			}
 			if endLine != nil {
-				ScreenMessage(DbgLastNotAtEnd)
+				e.ScreenMessage(DbgLastNotAtEnd)
 				return false
 			}
 			if thisFrame.Dot == nil {
-				ScreenMessage(DbgMarkPtrIsNil)
+				e.ScreenMessage(DbgMarkPtrIsNil)
 				return false
 			}
 			if thisFrame.Dot.Line.Group.Frame != thisFrame {
-				ScreenMessage(DbgMarkInWrongFrame)
+				e.ScreenMessage(DbgMarkInWrongFrame)
 				return false
 			}
 			for markNr := 0; markNr <= MaxMarkNumber; markNr++ {
 				if thisFrame.Marks[markNr] != nil {
 					if thisFrame.Marks[markNr].Line.Group.Frame != thisFrame {
-						ScreenMessage(DbgMarkInWrongFrame)
+						e.ScreenMessage(DbgMarkInWrongFrame)
 						return false
 					}
 				}
 			}
-			if thisFrame.ScrHeight == 0 || thisFrame.ScrHeight > TerminalInfo.Height {
-				ScreenMessage(DbgInvalidScrParam)
+			if thisFrame.ScrHeight == 0 || thisFrame.ScrHeight > e.TerminalInfo.Height {
+				e.ScreenMessage(DbgInvalidScrParam)
 				return false
 			}
-			if thisFrame.ScrWidth == 0 || thisFrame.ScrWidth > TerminalInfo.Width {
-				ScreenMessage(DbgInvalidScrParam)
+			if thisFrame.ScrWidth == 0 || thisFrame.ScrWidth > e.TerminalInfo.Width {
+				e.ScreenMessage(DbgInvalidScrParam)
 				return false
 			}
 			if thisFrame.Span != thisSpan {
-				ScreenMessage(DbgInvalidSpanPtr)
+				e.ScreenMessage(DbgInvalidSpanPtr)
 				return false
 			}
 			if thisFrame.MarginLeft >= thisFrame.MarginRight {
-				ScreenMessage(MsgLeftMarginGeRight)
+				e.ScreenMessage(MsgLeftMarginGeRight)
 				return false
 			}
 			if thisSpan.MarkOne.Line.Group.Frame != thisFrame ||
 				thisSpan.MarkTwo.Line.Group.Frame != thisFrame {
-				ScreenMessage(DbgMarkInWrongFrame)
+				e.ScreenMessage(DbgMarkInWrongFrame)
 				return false
 			}
 		} else if thisSpan.MarkOne.Line.Group.Frame != thisSpan.MarkTwo.Line.Group.Frame {
-			ScreenMessage(DbgMarksFromDiffFrames)
+			e.ScreenMessage(DbgMarksFromDiffFrames)
 			return false
 		}
 		prevSpan = thisSpan
@@ -231,7 +231,7 @@ func ValidateCommand() bool {
 	}
 
 	if frameList != (cmd | oops | heap) {
-		ScreenMessage(DbgNeededFrameNotFound)
+		e.ScreenMessage(DbgNeededFrameNotFound)
 		return false
 	}
 
@@ -11,16 +11,20 @@
 // Name:         VDU
 //
 // Description:  This module does all the complex control of the VDU type
-//               screens that Ludwig demands. This is the ncurses version.
+//               screens that Ludwig demands.  The drawing is done by a
+//               terminal.Screen, which may use ncurses, drive the terminal
+//               itself, or be a virtual screen in memory.
 
 package ludwig
 
 import (
 	"os"
 	"strconv"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
-	nc "ludwig-go/internal/ncurses"
+	"ludwig-go/internal/terminal"
 )
 
 // Constants
@@ -28,6 +32,7 @@ const (
 	BS  = 8
 	NL  = 10
 	CR  = 13
+	ESC = 27
 	SPC = 32
 	DEL = 127
 
@@ -41,27 +46,37 @@ const (
 	MaxNormalCode = OrdMaxChar
 )
 
-// Ncurses key range constants
-var (
-	MinCursesKey    int
-	MaxCursesKey    int
-	NumNcursesKeys  int
-	NcursesSubtract int
-	MassagedMax     int
-)
-
-// Global variables
+// Set once when the package is initialized, and shared by all editors
 var (
 	controlChars map[int]bool
-	terminators  map[int]bool
-	vduSetup     bool
-	inInsertMode bool
-	gCtrlC       *bool
-	gWinChange   *bool
-	stdscr       *nc.Window
+
 	refreshDelay int
 )
 
+// vduTerminalEnv names the variable that chooses the kind of screen used
+// on a terminal, one of the names in vduScreens
+const vduTerminalEnv = "LUD_TERMINAL"
+
+// vduScreens make the kinds of screen that can be used on a terminal
+var vduScreens = map[string]func() terminal.Screen{
+	"ansi": func() terminal.Screen { return terminal.NewANSI(os.Stdin, os.Stdout) },
+}
+
+// vduDefaultScreen is the kind of screen used unless LUD_TERMINAL says
+// otherwise
+var vduDefaultScreen = "ansi"
+
+// vduSyntaxColours are the colours used for each class of highlighted text
+var vduSyntaxColours = [SyntaxClasses]terminal.Colour{
+	SyntaxPlain:     terminal.ColourDefault,
+	SyntaxKeyword:   terminal.ColourYellow,
+	SyntaxType:      terminal.ColourGreen,
+	SyntaxLiteral:   terminal.ColourMagenta,
+	SyntaxString:    terminal.ColourRed,
+	SyntaxComment:   terminal.ColourCyan,
+	SyntaxDirective: terminal.ColourBlue,
+}
+
 func init() {
 	// Initialize control chars set
 	controlChars = map[int]bool{
@@ -75,7 +90,6 @@ func init() {
 		0x1C: true, 0x1D: true, 0x1E: true, 0x1F: true,
 		0x7F: true,
 	}
-	terminators = make(map[int]bool)
 	value := os.Getenv("LUD_REFRESH_DELAY")
 	if value == "" {
 		refreshDelay = 0
@@ -89,12 +103,12 @@ func init() {
 	}
 }
 
-// massageKey converts ncurses key codes to Ludwig key codes
-func massageKey(keyCode int) int {
+// massageKey converts the key codes of the screen to Ludwig key codes
+func (e *Editor) massageKey(keyCode int) int {
 	if keyCode >= MinNormalCode && keyCode <= MaxNormalCode {
 		return keyCode
-	} else if keyCode >= MinCursesKey && keyCode <= MaxCursesKey {
-		if keyCode == nc.KEY_BACKSPACE {
+	} else if keyCode >= e.MinCursesKey && keyCode <= e.MaxCursesKey {
+		if keyCode == terminal.KeyBackspace {
 			return DEL
 		}
 		return keyCode
@@ -102,229 +116,320 @@ func massageKey(keyCode int) int {
 	return 0
 }
 
-// unmassageKey converts Ludwig key codes back to ncurses key codes
-func unmassageKey(key int) int {
-	if key >= MinNormalCode && key <= MaxNormalCode {
-		return key
-	} else if key > MaxNormalCode && key <= MassagedMax {
-		return key
-	}
-	return 0
-}
-
 // VduMoveCurs moves the cursor to the specified position (1-based)
-func VduMoveCurs(x, y int) {
-	stdscr.Move(y-1, x-1)
+func (e *Editor) VduMoveCurs(x, y int) {
+	e.vduScreen.Move(y-1, x-1)
 }
 
 // VduFlush refreshes the screen
-func VduFlush() {
-	stdscr.Refresh()
+func (e *Editor) VduFlush() {
+	e.vduScreen.Refresh()
 	if refreshDelay > 0 {
 		time.Sleep(time.Duration(refreshDelay) * time.Millisecond)
 	}
 }
 
 // VduBeep produces a beep or flash
-func VduBeep() {
-	nc.Flash()
+func (e *Editor) VduBeep() {
+	e.vduScreen.Beep()
 }
 
 // VduClearEOL clears from cursor to end of line
-func VduClearEOL() {
-	stdscr.ClearToEOL()
+func (e *Editor) VduClearEOL() {
+	e.vduScreen.ClearToEOL()
 }
 
 // VduDisplayStr displays a string with optional clear to end of line
-func VduDisplayStr(str string, opts int) {
-	_, maxX := stdscr.MaxYX()
-	_, curX := stdscr.CursorYX()
+func (e *Editor) VduDisplayStr(str string, opts int) {
+	_, maxX := e.vduScreen.AreaSize()
+	_, curX := e.vduScreen.Cursor()
 	maxlen := maxX - curX
 
+	// Characters are counted by the number of columns they occupy
 	slen := len(str)
 	hitMargin := false
-	if slen >= maxlen {
-		slen = maxlen
-		hitMargin = true
+	width := 0
+	for i, ch := range str {
+		width += ChWidth(ch)
+		if width >= maxlen {
+			if width == maxlen {
+				slen = i + utf8.RuneLen(ch)
+			} else {
+				slen = i
+			}
+			hitMargin = true
+			break
+		}
 	}
 
-	stdscr.Print(str[:slen])
+	e.vduScreen.Print(str[:slen])
 
 	if !hitMargin && (opts&OutMClearEOL) != 0 {
-		VduClearEOL()
+		e.VduClearEOL()
 	}
 }
 
 // VduDisplayCh displays a single character
-func VduDisplayCh(ch byte) {
-	stdscr.AddChar(nc.Char(ch))
+func (e *Editor) VduDisplayCh(ch rune) {
+	e.vduScreen.AddChar(vduDisplayable(ch))
+}
+
+// vduDisplayable returns the character to show for a character of text,
+// bytes that were not UTF-8 are shown as the replacement character.
+func vduDisplayable(ch rune) rune {
+	if !utf8.ValidRune(ch) {
+		return utf8.RuneError
+	}
+	return ch
 }
 
 // VduClearScr clears the entire screen
-func VduClearScr() {
-	stdscr.Clear()
+func (e *Editor) VduClearScr() {
+	e.vduScreen.Clear()
 }
 
 // VduClearEOS clears from cursor to end of screen
-func VduClearEOS() {
-	stdscr.ClearToBottom()
+func (e *Editor) VduClearEOS() {
+	e.vduScreen.ClearToBottom()
 }
 
 // VduScrollUp scrolls the screen up by n lines
-func VduScrollUp(n int) {
-	stdscr.ScrollOk(true)
-	stdscr.Scroll(n)
-	stdscr.ScrollOk(false)
+func (e *Editor) VduScrollUp(n int) {
+	e.vduScreen.Scroll(n)
 }
 
 // VduDeleteLines deletes n lines at current position
-func VduDeleteLines(n int) {
-	stdscr.InsDelLines(-n)
+func (e *Editor) VduDeleteLines(n int) {
+	e.vduScreen.InsDelLines(-n)
 }
 
 // VduInsertLines inserts n lines at current position
-func VduInsertLines(n int) {
-	stdscr.InsDelLines(n)
+func (e *Editor) VduInsertLines(n int) {
+	e.vduScreen.InsDelLines(n)
 }
 
 // VduInsertChars inserts n characters at current position
-func VduInsertChars(n int) {
+func (e *Editor) VduInsertChars(n int) {
 	for range n {
-		stdscr.InsChar(nc.Char(' '))
+		e.vduScreen.InsChar(' ')
 	}
 }
 
 // VduDeleteChars deletes n characters at current position
-func VduDeleteChars(n int) {
+func (e *Editor) VduDeleteChars(n int) {
 	for range n {
-		stdscr.DelChar()
+		e.vduScreen.DelChar()
 	}
 }
 
 // VduDisplayCrLf displays a carriage return / line feed
-func VduDisplayCrLf() {
-	y, _ := stdscr.CursorYX()
-	maxY, _ := stdscr.MaxYX()
+func (e *Editor) VduDisplayCrLf() {
+	y, _ := e.vduScreen.Cursor()
+	maxY, _ := e.vduScreen.AreaSize()
 
 	if y == maxY-1 {
-		VduScrollUp(1)
+		e.VduScrollUp(1)
 	} else {
 		y++
 	}
 
-	stdscr.Move(y, 0)
-	stdscr.Refresh()
+	e.vduScreen.Move(y, 0)
+	e.vduScreen.Refresh()
 }
 
 // VduTakeBackKey pushes a key back to the input queue
-func VduTakeBackKey(key int) {
-	nc.UnGetChar(nc.Char(unmassageKey(key)))
+func (e *Editor) VduTakeBackKey(key int) {
+	e.takenBack = append(e.takenBack, key)
 }
 
 // VduNewIntroducer sets up terminators for input
-func VduNewIntroducer(key int) {
-	terminators = make(map[int]bool)
+func (e *Editor) VduNewIntroducer(key int) {
+	e.terminators = make(map[int]bool)
 	for k := range controlChars {
-		terminators[k] = true
+		e.terminators[k] = true
 	}
 	if key > 0 {
-		terminators[key] = true
+		e.terminators[key] = true
 	}
 }
 
+// vduPoll is how often signals are looked for while waiting for a key
+const vduPoll = 250 * time.Millisecond
+
 // VduGetKey gets a single key from the user
-func VduGetKey() int {
-	nc.CursSet(1)
-	VduFlush()
-	var rawKey nc.Key
+func (e *Editor) VduGetKey() int {
+	key, _ := e.vduGetKey(-1)
+	return key
+}
+
+// vduGetKey gets a single key from the user, giving up and returning false
+// if none has arrived after delay.  A negative delay waits for ever.  While
+// there are changes that are not in the autosave journal, they are written
+// out whenever the user stops typing for a while.  Waiting is also given up
+// now and then to act on signals, an interrupt gives a zero key and a
+// change of the terminal's size gives the resize key.  Files that are being
+// paged in lazily are read between keys.
+func (e *Editor) vduGetKey(delay time.Duration) (int, bool) {
+	if len(e.takenBack) > 0 {
+		key := e.takenBack[len(e.takenBack)-1]
+		e.takenBack = e.takenBack[:len(e.takenBack)-1]
+		return key, true
+	}
+	e.vduScreen.ShowCursor(true)
+	e.VduFlush()
+	var rawKey int
+	var isKey bool
+	var err error
+	start := time.Now()
+	ctrlC := e.TtControlC.Load()
+	winChanged := e.TtWinChanged.Load()
 	for {
-		rawKey = stdscr.GetChar()
+		wait := vduPoll
+		// Large files are read a piece at a time until a key comes
+		if e.FileIdle() {
+			wait = 0
+		}
+		pending := e.JournalPending()
+		if pending {
+			wait = min(wait, max(journalIdle-time.Since(e.vduLastKey), 0))
+		}
+		if delay >= 0 {
+			wait = min(wait, max(delay-time.Since(start), 0))
+		}
+		rawKey, isKey, err = e.vduScreen.GetKey(wait)
+		if err == terminal.ErrTimeout {
+			if pending && time.Since(e.vduLastKey) >= journalIdle {
+				e.JournalWrite()
+			}
+			if delay >= 0 && time.Since(start) >= delay {
+				e.vduScreen.ShowCursor(false)
+				return 0, false
+			}
+			e.SysCheckSignals()
+			if e.TtControlC.Load() && !ctrlC {
+				e.vduScreen.ShowCursor(false)
+				return 0, true
+			}
+			if e.TtWinChanged.Load() && !winChanged {
+				rawKey, isKey = terminal.KeyResize, true
+				break
+			}
+			continue
+		}
+		// A key that can't be read is passed on as no key at all
+		if err != nil {
+			rawKey, isKey = 0, false
+			break
+		}
 		if rawKey != 0 {
 			break
 		}
 	}
 
-	if rawKey == nc.KEY_RESIZE && gWinChange != nil {
-		*gWinChange = true
+	e.vduLastKey = time.Now()
+	if isKey && rawKey == terminal.KeyResize && e.gWinChange != nil {
+		e.gWinChange.Store(true)
 	}
-	nc.CursSet(0)
-	return massageKey(int(rawKey))
+	e.vduScreen.ShowCursor(false)
+	if !isKey && rawKey > OrdMaxChar {
+		return ChToKey(rune(rawKey)), true
+	}
+	return e.massageKey(rawKey), true
 }
 
 // VduGetInput gets a line of input from the user with a prompt
-func VduGetInput(prompt string, get **StrObject, getLen int, outlen *int) {
-	VduBold()
-	VduDisplayStr(prompt, OutMClearEOL)
-	VduNormal()
-
-	// Fill get with spaces
-	*get = NewBlankStrObject(MaxStrLen)
+func (e *Editor) VduGetInput(prompt string, get **StrObject, getLen int, outlen *int) {
+	e.VduBold()
+	e.VduDisplayStr(prompt, OutMClearEOL)
+	e.VduNormal()
 
-	_, curX := stdscr.CursorYX()
-	maxY, maxX := stdscr.MaxYX()
-	maxlen := min(MaxStrLen, maxX-curX)
+	_, curX := e.vduScreen.Cursor()
+	maxY, maxX := e.vduScreen.AreaSize()
+	maxlen := maxX - curX
 
 	if getLen > maxlen {
 		getLen = maxlen
 	}
 
+	// Fill get with spaces
+	*get = NewBlankStrObject(max(getLen, 0))
+
 	*outlen = 0
-	key := VduGetKey()
+	key := e.VduGetKey()
 
 	for getLen > 0 && key != CR && key != NL {
 		if *outlen > 0 && (key == BS || key == DEL) {
-			getLen++
+			width := ChWidth((*get).Get(*outlen))
+			getLen += width
 			*outlen--
-			stdscr.AddChar(nc.Char(BS))
-			stdscr.AddChar(nc.Char(SPC))
-			stdscr.AddChar(nc.Char(BS))
+			for range width {
+				e.vduScreen.AddChar(BS)
+				e.vduScreen.AddChar(SPC)
+				e.vduScreen.AddChar(BS)
+			}
 		} else {
-			if key < 0 || key > OrdMaxChar || controlChars[key] {
-				VduBeep()
+			ch, ok := KeyToCh(key)
+			if !ok || controlChars[key] || ChWidth(ch) > getLen {
+				e.VduBeep()
 			} else {
-				getLen--
+				getLen -= ChWidth(ch)
 				*outlen++
-				(*get).Set(*outlen, byte(key))
-				stdscr.AddChar(nc.Char(key))
+				(*get).Set(*outlen, ch)
+				e.VduDisplayCh(ch)
 			}
 		}
-		key = VduGetKey()
+		key = e.VduGetKey()
 	}
 	_ = maxY // Avoid unused variable warning
 }
 
 // VduInsertMode sets insert mode on or off
-func VduInsertMode(turnOn bool) {
-	inInsertMode = turnOn
+func (e *Editor) VduInsertMode(turnOn bool) {
+	e.inInsertMode = turnOn
 }
 
 // VduGetText gets text input from the user
-func VduGetText(strLen int, str *StrObject, outlen *int) {
+func (e *Editor) VduGetText(strLen int, str *StrObject, outlen *int) {
 	// Fill str with spaces
-	str.Fill(' ', 1, MaxStrLen)
+	str.Fill(' ', 1, str.Len())
 
 	*outlen = 0
-	_, curX := stdscr.CursorYX()
-	maxY, maxX := stdscr.MaxYX()
+	_, curX := e.vduScreen.Cursor()
+	maxY, maxX := e.vduScreen.AreaSize()
 	maxlen := maxX - curX
 
 	if strLen > maxlen {
 		strLen = maxlen
 	}
 
+	// The echo of a character that does not fit on the screen is left for
+	// the caller to redraw
 	for strLen > 0 {
-		key := VduGetKey()
-		if key < 0 || key > OrdMaxChar || terminators[key] {
-			VduTakeBackKey(key)
+		// Once some text has been typed, hand it back if the user stops
+		// typing for a while, so that it can go into the autosave journal
+		delay := time.Duration(-1)
+		if *outlen > 0 && journalDir() != "" {
+			delay = journalIdle
+		}
+		key, ok := e.vduGetKey(delay)
+		if !ok {
+			break
+		}
+		ch, ok := KeyToCh(key)
+		if !ok || e.terminators[key] || (*outlen > 0 && ChWidth(ch) > maxlen) {
+			e.VduTakeBackKey(key)
 			strLen = 0
 		} else {
-			if inInsertMode {
-				VduInsertChars(1)
+			if ChWidth(ch) <= maxlen {
+				if e.inInsertMode {
+					e.VduInsertChars(ChWidth(ch))
+				}
+				e.VduDisplayCh(ch)
+				e.vduScreen.Refresh()
+				maxlen -= ChWidth(ch)
 			}
-			stdscr.AddChar(nc.Char(key))
-			stdscr.Refresh()
 			*outlen++
-			str.Set(*outlen, byte(key))
+			str.Set(*outlen, ch)
 			strLen--
 		}
 	}
@@ -332,13 +437,13 @@ func VduGetText(strLen int, str *StrObject, outlen *int) {
 }
 
 // VduKeyboardInit initializes keyboard mappings
-func VduKeyboardInit(
+func (e *Editor) VduKeyboardInit(
 	nrKeyNames *int,
 	keyNameList *[]KeyNameRecord,
 	keyIntroducers *[MaxSetRange + 1]bool,
 	terminalInfo *TerminalInfoType,
 ) {
-	*nrKeyNames = NumControlChars + NumNcursesKeys
+	*nrKeyNames = NumControlChars + e.NumNcursesKeys
 	kl := make([]KeyNameRecord, *nrKeyNames+1)
 
 	// Initialize control character names
@@ -381,8 +486,8 @@ func VduKeyboardInit(
 	kl[33].KeyName = "DELETE"
 
 	// Initialize ncurses key names
-	for i := MinCursesKey; i <= MaxCursesKey; i++ {
-		kl[NumControlChars+1+i-MinCursesKey].KeyCode = massageKey(i)
+	for i := e.MinCursesKey; i <= e.MaxCursesKey; i++ {
+		kl[NumControlChars+1+i-e.MinCursesKey].KeyCode = e.massageKey(i)
 	}
 
 	kl[NumControlChars+1].KeyName = "BREAK"
@@ -549,83 +654,146 @@ func VduKeyboardInit(
 	copy(*keyNameList, kl)
 }
 
+// VduUseScreen makes Ludwig draw on a screen of the caller's choosing, such
+// as a virtual screen, rather than on the terminal.  It is called before
+// VduInit.
+func (e *Editor) VduUseScreen(screen terminal.Screen) {
+	e.vduScreen = screen
+}
+
+// vduNewScreen makes a screen on the terminal, of the kind named by
+// LUD_TERMINAL if there is one of that name
+func vduNewScreen() terminal.Screen {
+	newScreen, ok := vduScreens[os.Getenv(vduTerminalEnv)]
+	if !ok {
+		newScreen = vduScreens[vduDefaultScreen]
+	}
+	return newScreen()
+}
+
 // VduInit initializes the VDU system
-func VduInit(terminalInfo *TerminalInfoType, ctrlCFlag *bool, winchangeFlag *bool) bool {
-	gCtrlC = ctrlCFlag
-	gWinChange = winchangeFlag
+func (e *Editor) VduInit(terminalInfo *TerminalInfoType, ctrlCFlag *atomic.Bool, winchangeFlag *atomic.Bool) bool {
+	e.gCtrlC = ctrlCFlag
+	e.gWinChange = winchangeFlag
 	terminalInfo.Name = ""
 	terminalInfo.Width = 80
 	terminalInfo.Height = 4
 
-	if SysIsTTY() {
-		var err error
-		stdscr, err = nc.Init()
-		if err == nil {
-			vduSetup = true
-			nc.Raw(true)
-			nc.Echo(false)
-			nc.NewLines(false)
-			nc.CursSet(0)
-			stdscr.IntrFlush(false)
-			stdscr.Keypad(true)
-			stdscr.Idlok(true)
-			stdscr.Idcok(true)
-			stdscr.ScrollOk(false)
-
-			// Initialize ncurses key range constants after Init
-			MinCursesKey = 257
-			MaxCursesKey = nc.KEY_MAX
-			NumNcursesKeys = (MaxCursesKey - MinCursesKey) + 1
-			NcursesSubtract = MinCursesKey - 1
-			MassagedMax = MaxCursesKey
-
-			maxY, maxX := stdscr.MaxYX()
-			terminalInfo.Width = maxX
-			terminalInfo.Height = maxY
-			terminalInfo.Name = os.Getenv("TERM")
-
-			VduClearScr()
+	if e.vduScreen == nil {
+		if !SysIsTTY() {
+			return false
 		}
-		return true
+		e.vduScreen = vduNewScreen()
+	}
+	if e.vduScreen.Init() == nil {
+		e.vduSetup = true
+
+		// Initialize key range constants
+		e.MinCursesKey = terminal.KeyMin
+		e.MaxCursesKey = terminal.KeyMax
+		e.NumNcursesKeys = (e.MaxCursesKey - e.MinCursesKey) + 1
+		e.NcursesSubtract = e.MinCursesKey - 1
+		e.MassagedMax = e.MaxCursesKey
+
+		maxY, maxX := e.vduScreen.Size()
+		terminalInfo.Width = maxX
+		terminalInfo.Height = maxY
+		terminalInfo.Name = os.Getenv("TERM")
+
+		e.vduScreen.ShowCursor(false)
+		e.vduAttr = terminal.AttrNormal
+		e.vduColour = terminal.ColourDefault
+		e.vduScreen.SetStyle(e.vduAttr, e.vduColour)
+		e.VduClearScr()
 	}
-	return false
+	return true
 }
 
 // VduFree cleans up the VDU system
-func VduFree() {
-	if vduSetup {
-		vduSetup = false
-		VduScrollUp(1)
-		maxY, _ := stdscr.MaxYX()
-		VduMoveCurs(1, maxY)
-		VduFlush()
-		nc.End()
+func (e *Editor) VduFree() {
+	if e.vduSetup {
+		e.VduSetArea(1, 1, 0, 0)
+		e.vduSetup = false
+		e.VduScrollUp(1)
+		maxY, _ := e.vduScreen.Size()
+		e.VduMoveCurs(1, maxY)
+		e.VduFlush()
+		e.vduScreen.End()
+	}
+}
+
+// VduSuspend gives the terminal back to the shell for a while
+func (e *Editor) VduSuspend() {
+	if e.vduSetup {
+		e.vduScreen.Suspend()
+	}
+}
+
+// VduResume takes the terminal back from the shell and redraws the screen
+func (e *Editor) VduResume() {
+	if e.vduSetup {
+		e.vduScreen.Resume()
 	}
 }
 
 // VduGetNewDimensions gets the new screen dimensions after resize
-func VduGetNewDimensions(newX *int, newY *int) {
-	nc.End()
-	stdscr.Refresh()
-	maxY, maxX := stdscr.MaxYX()
+func (e *Editor) VduGetNewDimensions(newX *int, newY *int) {
+	e.VduSetArea(1, 1, 0, 0)
+	e.vduScreen.UpdateSize()
+	maxY, maxX := e.vduScreen.Size()
 	*newX = maxX
 	*newY = maxY
 }
 
+// VduSetArea confines the display to an area of the screen, given by the
+// position of its top left corner and its size.  Cursor positions and
+// scrolling are then relative to the area.  An area of zero size is the
+// whole screen.
+func (e *Editor) VduSetArea(x, y, width, height int) {
+	if !e.vduSetup {
+		return
+	}
+	e.vduScreen.SetArea(y-1, x-1, height, width)
+}
+
+// vduSetStyle draws text from now on as vduAttr and vduColour say
+func (e *Editor) vduSetStyle(attr terminal.Attr, colour terminal.Colour) {
+	e.vduAttr = attr
+	e.vduColour = colour
+	e.vduScreen.SetStyle(attr, colour)
+}
+
 // VduBold turns on bold attribute
-func VduBold() {
-	stdscr.AttrOn(nc.A_BOLD)
-	stdscr.AttrOff(nc.A_DIM)
+func (e *Editor) VduBold() {
+	e.vduSetStyle(e.vduAttr&^terminal.AttrDim|terminal.AttrBold, e.vduColour)
 }
 
 // VduDim turns on dim attribute
-func VduDim() {
-	stdscr.AttrOff(nc.A_BOLD)
-	stdscr.AttrOn(nc.A_DIM)
+func (e *Editor) VduDim() {
+	e.vduSetStyle(e.vduAttr&^terminal.AttrBold|terminal.AttrDim, e.vduColour)
 }
 
 // VduNormal turns off all attributes
-func VduNormal() {
-	stdscr.AttrOff(nc.A_BOLD)
-	stdscr.AttrOff(nc.A_DIM)
+func (e *Editor) VduNormal() {
+	e.vduSetStyle(terminal.AttrNormal, terminal.ColourDefault)
+}
+
+// VduSyntax draws text in the colour for a class of highlighted text.  On
+// terminals without colours, keywords and the like are bold and comments
+// are dim.  Text found by a search is always in reverse video.
+func (e *Editor) VduSyntax(class SyntaxClass) {
+	switch {
+	case class == SyntaxPlain:
+		e.VduNormal()
+	case class == SyntaxMatch:
+		e.vduSetStyle(terminal.AttrReverse, terminal.ColourDefault)
+	case e.vduScreen.HasColours():
+		e.vduSetStyle(terminal.AttrNormal, vduSyntaxColours[class])
+	case class == SyntaxComment:
+		e.vduSetStyle(terminal.AttrDim, terminal.ColourDefault)
+	case class != SyntaxString && class != SyntaxLiteral:
+		e.vduSetStyle(terminal.AttrBold, terminal.ColourDefault)
+	default:
+		e.VduNormal()
+	}
 }
@@ -0,0 +1,30 @@
+//go:build cgo && !nocurses
+
+/**********************************************************************}
+{                                                                      }
+{            L      U   U   DDDD   W      W  IIIII   GGGG              }
+{            L      U   U   D   D   W    W     I    G                  }
+{            L      U   U   D   D   W ww W     I    G   GG             }
+{            L      U   U   D   D    W  W      I    G    G             }
+{            LLLLL   UUU    DDDD     W  W    IIIII   GGGG              }
+{                                                                      }
+{**********************************************************************/
+
+// Name:         VDU_NCURSES
+//
+// Description:  The ncurses screen, which is used on terminals unless
+//               LUD_TERMINAL says otherwise.  Building with the nocurses
+//               tag, or without cgo, leaves it out so that Ludwig does not
+//               need the ncurses library.
+
+package ludwig
+
+import (
+	nc "ludwig-go/internal/ncurses"
+	"ludwig-go/internal/terminal"
+)
+
+func init() {
+	vduScreens["ncurses"] = func() terminal.Screen { return nc.NewScreen() }
+	vduDefaultScreen = "ncurses"
+}
@@ -0,0 +1,118 @@
+// Tests for vdu.go functions
+
+package ludwig
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"ludwig-go/internal/terminal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupVdu starts the VDU on a virtual screen of the given size
+func setupVdu(t *testing.T, e *Editor, width, height int) (*terminal.Virtual, *atomic.Bool) {
+	t.Cleanup(func() {
+		if e.vduSetup {
+			e.VduFree()
+		}
+	})
+	screen := terminal.NewVirtual(height, width)
+	e.VduUseScreen(screen)
+	var info TerminalInfoType
+	var ctrlC, winChanged atomic.Bool
+	require.True(t, e.VduInit(&info, &ctrlC, &winChanged))
+	assert.Equal(t, width, info.Width)
+	assert.Equal(t, height, info.Height)
+	return screen, &winChanged
+}
+
+func TestVduDisplay(t *testing.T) {
+	e := NewEditor()
+	screen, _ := setupVdu(t, e, 12, 3)
+
+	e.VduMoveCurs(3, 2)
+	e.VduDisplayStr("hello", OutMClearEOL)
+	e.VduBold()
+	e.VduDisplayCh('!')
+	e.VduNormal()
+	e.VduMoveCurs(1, 3)
+	e.VduDisplayStr("a long line of text", 0)
+	e.VduFlush()
+	assert.Equal(t, []string{"", "  hello!", "a long line"}, screen.Lines())
+	attr, _ := screen.Style(1, 7)
+	assert.Equal(t, terminal.AttrBold, attr)
+
+	// Lines and characters can be inserted and deleted
+	e.VduMoveCurs(1, 2)
+	e.VduInsertLines(1)
+	e.VduMoveCurs(1, 3)
+	e.VduInsertChars(2)
+	e.VduMoveCurs(1, 1)
+	e.VduDeleteLines(1)
+	e.VduFlush()
+	assert.Equal(t, []string{"", "    hello!", ""}, screen.Lines())
+}
+
+func TestVduSetArea(t *testing.T) {
+	e := NewEditor()
+	screen, _ := setupVdu(t, e, 10, 4)
+
+	e.VduMoveCurs(1, 4)
+	e.VduDisplayStr("bottom", 0)
+	e.VduSetArea(6, 1, 5, 3)
+	e.VduMoveCurs(1, 3)
+	e.VduDisplayStr("abc", 0)
+	e.VduScrollUp(1)
+	e.VduSetArea(1, 1, 0, 0)
+	e.VduFlush()
+	assert.Equal(t, []string{"", "     abc", "", "bottom"}, screen.Lines())
+}
+
+func TestVduGetKey(t *testing.T) {
+	e := NewEditor()
+	screen, winChanged := setupVdu(t, e, 20, 5)
+
+	screen.Type("a日")
+	screen.Press(terminal.KeyBackspace, terminal.KeyUp)
+	assert.Equal(t, int('a'), e.VduGetKey())
+	assert.Equal(t, ChToKey('日'), e.VduGetKey())
+	assert.Equal(t, DEL, e.VduGetKey())
+	assert.Equal(t, terminal.KeyUp, e.VduGetKey())
+
+	e.VduTakeBackKey('x')
+	assert.Equal(t, int('x'), e.VduGetKey())
+
+	// A change of size comes as a key, and the screen then has the new size
+	screen.Resize(8, 30)
+	assert.Equal(t, terminal.KeyResize, e.VduGetKey())
+	assert.True(t, winChanged.Load())
+	var width, height int
+	e.VduGetNewDimensions(&width, &height)
+	assert.Equal(t, []int{30, 8}, []int{width, height})
+}
+
+func TestVduSyntax(t *testing.T) {
+	e := NewEditor()
+	screen, _ := setupVdu(t, e, 20, 2)
+
+	e.VduSyntax(SyntaxKeyword)
+	e.VduDisplayStr("func", 0)
+	screen.SetColours(false)
+	e.VduSyntax(SyntaxComment)
+	e.VduDisplayStr("//", 0)
+	e.VduSyntax(SyntaxPlain)
+	e.VduDisplayStr(" x", 0)
+	e.VduFlush()
+
+	attr, colour := screen.Style(0, 0)
+	assert.Equal(t, terminal.AttrNormal, attr)
+	assert.Equal(t, vduSyntaxColours[SyntaxKeyword], colour)
+	attr, colour = screen.Style(0, 4)
+	assert.Equal(t, terminal.AttrDim, attr)
+	assert.Equal(t, terminal.ColourDefault, colour)
+	attr, _ = screen.Style(0, 7)
+	assert.Equal(t, terminal.AttrNormal, attr)
+}
@@ -10,12 +10,369 @@
 
 // Name:         WINDOW
 //
-// Description:  Implement the window commands.
+// Description:  Implement the window commands.  The screen can also be
+//               split into several windows, each showing a frame.  Only
+//               the current window has the screen mapped onto it, the
+//               others are drawn from where their frame's Dot was when
+//               they were left.
 
 package ludwig
 
+import "strings"
+
+// windowLeaves returns the windows below a window that show frames, in
+// order from the top left of the screen
+func windowLeaves(window *WindowObject, leaves []*WindowObject) []*WindowObject {
+	if window.Halves[0] == nil {
+		return append(leaves, window)
+	}
+	leaves = windowLeaves(window.Halves[0], leaves)
+	return windowLeaves(window.Halves[1], leaves)
+}
+
+// windowLayout gives an area of the screen to a window, and divides it
+// between the window's halves
+func windowLayout(window *WindowObject, row int, col int, height int, width int) {
+	window.Row = row
+	window.Col = col
+	window.Height = height
+	window.Width = width
+	if window.Halves[0] == nil {
+		return
+	}
+	if window.Vertical {
+		// One column is left between the halves for a separator
+		left := (width - 1) / 2
+		windowLayout(window.Halves[0], row, col, height, left)
+		windowLayout(window.Halves[1], row, col+left+1, height, width-left-1)
+	} else {
+		top := height / 2
+		windowLayout(window.Halves[0], row, col, top, width)
+		windowLayout(window.Halves[1], row+top, col, height-top, width)
+	}
+}
+
+// windowFits returns true if none of the windows is too small
+func (e *Editor) windowFits() bool {
+	for _, window := range windowLeaves(e.RootWindow, nil) {
+		if window.Height < MinWindowHeight || window.Width < MinWindowWidth {
+			return false
+		}
+	}
+	return true
+}
+
+// windowTextHeight returns the number of rows of text in a window, all but
+// the last row when there is a status line
+func (e *Editor) windowTextHeight(window *WindowObject) int {
+	if window == e.RootWindow {
+		return window.Height
+	}
+	return window.Height - 1
+}
+
+// windowArea maps the screen onto the text area of the current window
+func (e *Editor) windowArea() {
+	window := e.CurrentWindow
+	height := e.windowTextHeight(window)
+	e.VduSetArea(window.Col, window.Row, window.Width, height)
+	e.screenSetSize(window.Width, height)
+	e.ScrMsgRow = e.TerminalInfo.Height + 1
+}
+
+// windowLeave remembers where the current frame is in the current window
+// and unmaps the screen
+func (e *Editor) windowLeave() {
+	window := e.CurrentWindow
+	if e.ScrFrame != nil {
+		e.ScreenUnload()
+	}
+	window.Frame = e.CurrentFrame
+	MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &window.Dot)
+	window.DotLine = e.CurrentFrame.ScrDotLine
+	window.Offset = e.CurrentFrame.ScrOffset
+}
+
+// windowEnter makes a window the current window, its frame becomes the
+// current frame with Dot where it was when the window was left
+func (e *Editor) windowEnter(window *WindowObject) {
+	e.CurrentWindow = window
+	e.CurrentFrame = window.Frame
+	if window.Dot != nil {
+		MarkCreate(window.Dot.Line, window.Dot.Col, &e.CurrentFrame.Dot)
+		MarkDestroy(&window.Dot)
+	}
+	e.CurrentFrame.ScrDotLine = max(window.DotLine, 1)
+	e.CurrentFrame.ScrOffset = window.Offset
+	e.windowArea()
+}
+
+// windowJoin goes back to a screen that is not split, once the root window
+// shows a frame
+func (e *Editor) windowJoin() {
+	e.windowEnter(e.RootWindow)
+	e.RootWindow = nil
+	e.CurrentWindow = nil
+}
+
+// windowSplit splits the current window into two halves, one above the
+// other or side by side.  Both halves show the current frame, the first
+// half becomes the current window.
+func (e *Editor) windowSplit(vertical bool) bool {
+	if e.LudwigMode != LudwigScreen {
+		e.ScreenMessage(MsgScreenModeOnly)
+		return false
+	}
+	if e.RootWindow == nil {
+		e.RootWindow = &WindowObject{
+			Frame:  e.CurrentFrame,
+			Row:    1,
+			Col:    1,
+			Height: e.TerminalInfo.Height,
+			Width:  e.TerminalInfo.Width,
+		}
+		e.CurrentWindow = e.RootWindow
+	}
+	window := e.CurrentWindow
+	if (vertical && (window.Width-1)/2 < MinWindowWidth) ||
+		(!vertical && window.Height/2 < MinWindowHeight) {
+		if window == e.RootWindow {
+			e.RootWindow = nil
+			e.CurrentWindow = nil
+		}
+		e.ScreenMessage(MsgWindowTooSmall)
+		return false
+	}
+
+	e.windowLeave()
+	for i := range window.Halves {
+		half := &WindowObject{
+			Parent:  window,
+			Frame:   window.Frame,
+			DotLine: window.DotLine,
+			Offset:  window.Offset,
+		}
+		MarkCreate(window.Dot.Line, window.Dot.Col, &half.Dot)
+		window.Halves[i] = half
+	}
+	MarkDestroy(&window.Dot)
+	window.Frame = nil
+	window.Vertical = vertical
+	windowLayout(window, window.Row, window.Col, window.Height, window.Width)
+	e.windowEnter(window.Halves[0])
+	return true
+}
+
+// windowNext makes the count'th window after the current one the current
+// window, counting back through the windows if count is negative
+func (e *Editor) windowNext(count int) bool {
+	if e.RootWindow == nil {
+		return true
+	}
+	leaves := windowLeaves(e.RootWindow, nil)
+	index := 0
+	for i, window := range leaves {
+		if window == e.CurrentWindow {
+			index = i
+		}
+	}
+	index = ((index+count)%len(leaves) + len(leaves)) % len(leaves)
+	if leaves[index] != e.CurrentWindow {
+		e.windowLeave()
+		e.windowEnter(leaves[index])
+	}
+	return true
+}
+
+// windowKill removes the current window, the other half of the window it
+// was split from takes its place
+func (e *Editor) windowKill() bool {
+	if e.RootWindow == nil {
+		e.ScreenMessage(MsgOnlyOneWindow)
+		return false
+	}
+	e.windowLeave()
+	window := e.CurrentWindow
+	MarkDestroy(&window.Dot)
+	parent := window.Parent
+	other := parent.Halves[0]
+	if other == window {
+		other = parent.Halves[1]
+	}
+	parent.Halves = other.Halves
+	parent.Vertical = other.Vertical
+	parent.Frame = other.Frame
+	parent.Dot = other.Dot
+	parent.DotLine = other.DotLine
+	parent.Offset = other.Offset
+	for _, half := range parent.Halves {
+		if half != nil {
+			half.Parent = parent
+		}
+	}
+	windowLayout(parent, parent.Row, parent.Col, parent.Height, parent.Width)
+	if parent == e.RootWindow && parent.Halves[0] == nil {
+		e.windowJoin()
+	} else {
+		e.windowEnter(windowLeaves(parent, nil)[0])
+	}
+	return true
+}
+
+// windowOnly removes all the windows except the current one, which takes
+// up the whole screen
+func (e *Editor) windowOnly() bool {
+	if e.RootWindow == nil {
+		return true
+	}
+	e.windowLeave()
+	window := e.CurrentWindow
+	for _, other := range windowLeaves(e.RootWindow, nil) {
+		if other != window {
+			MarkDestroy(&other.Dot)
+		}
+	}
+	e.RootWindow.Halves = [2]*WindowObject{}
+	e.RootWindow.Frame = window.Frame
+	e.RootWindow.Dot = window.Dot
+	e.RootWindow.DotLine = window.DotLine
+	e.RootWindow.Offset = window.Offset
+	e.windowJoin()
+	return true
+}
+
+// WindowResize lays the windows out on a screen of a new size, going back
+// to one window if they no longer fit
+func (e *Editor) WindowResize(width int, height int) {
+	if e.RootWindow == nil {
+		e.screenSetSize(width, height)
+		return
+	}
+	windowLayout(e.RootWindow, 1, 1, height, width)
+	if e.windowFits() {
+		e.windowArea()
+	} else {
+		e.RootWindow.Height = height
+		e.RootWindow.Width = width
+		e.windowOnly()
+	}
+}
+
+// WindowShows returns true if a frame is shown in one of the windows
+// other than the current window
+func (e *Editor) WindowShows(frame *FrameObject) bool {
+	if e.RootWindow == nil {
+		return false
+	}
+	for _, window := range windowLeaves(e.RootWindow, nil) {
+		if window != e.CurrentWindow && window.Frame == frame {
+			return true
+		}
+	}
+	return false
+}
+
+// windowDraw draws a window that is not the current window, placing its
+// frame's Dot as the screen would be loaded if the window were current
+func (e *Editor) windowDraw(window *WindowObject) {
+	height := e.windowTextHeight(window)
+	e.VduSetArea(window.Col, window.Row, window.Width, height)
+	frame := window.Frame
+
+	var lineNr int
+	LineToNumber(window.Dot.Line, &lineNr)
+	eopLineNr := frame.LastGroup.FirstLineNr + frame.LastGroup.NrLines - 1
+	row := min(max(window.DotLine, 1), height)
+	if eopLineNr-lineNr < height-row {
+		row = height - (eopLineNr - lineNr)
+	}
+	if lineNr < row {
+		row = lineNr
+	}
+	line := window.Dot.Line
+	for ; row > 1; row-- {
+		line = line.BLink
+	}
+	for row = 1; row <= height; row++ {
+		e.VduMoveCurs(1, row)
+		if line == nil {
+			e.VduClearEOL()
+		} else {
+			e.screenDrawText(line, window.Offset, window.Width)
+			line = line.FLink
+		}
+	}
+}
+
+// windowDrawStatus draws the status line of a window, naming its frame
+// and the file that the frame is written to or read from
+func (e *Editor) windowDrawStatus(window *WindowObject) {
+	e.VduSetArea(window.Col, window.Row+window.Height-1, window.Width, 1)
+	frame := window.Frame
+	var status strings.Builder
+	status.WriteString("-- ")
+	status.WriteString(frame.Span.Name)
+	status.WriteString(" ")
+	if fileName := e.FrameFileName(frame); fileName != "" {
+		status.WriteString(fileName)
+		status.WriteString(" ")
+	}
+	if frame.TextModified {
+		status.WriteString("* ")
+	}
+	text := []rune(status.String())
+	if len(text) < window.Width {
+		text = append(text, []rune(strings.Repeat("-", window.Width-len(text)))...)
+	}
+	if window == e.CurrentWindow {
+		e.VduBold()
+	} else {
+		e.VduDim()
+	}
+	e.VduMoveCurs(1, 1)
+	e.VduDisplayStr(string(text), 0)
+	e.VduNormal()
+}
+
+// windowDrawSeparators draws the columns between windows side by side
+func (e *Editor) windowDrawSeparators(window *WindowObject) {
+	if window.Halves[0] == nil {
+		return
+	}
+	if window.Vertical {
+		e.VduSetArea(window.Col+window.Halves[0].Width, window.Row, 1, window.Height)
+		for row := 1; row <= window.Height; row++ {
+			e.VduMoveCurs(1, row)
+			e.VduDisplayCh('|')
+		}
+	}
+	e.windowDrawSeparators(window.Halves[0])
+	e.windowDrawSeparators(window.Halves[1])
+}
+
+// WindowUpdate brings the windows other than the current window up to
+// date, along with all the status lines, and puts the cursor back at Dot.
+// The current window is kept up to date by SCREEN.
+func (e *Editor) WindowUpdate() {
+	if e.RootWindow == nil {
+		return
+	}
+	e.CurrentWindow.Frame = e.CurrentFrame
+	e.windowDrawSeparators(e.RootWindow)
+	for _, window := range windowLeaves(e.RootWindow, nil) {
+		if window != e.CurrentWindow {
+			e.windowDraw(window)
+		}
+		e.windowDrawStatus(window)
+	}
+	e.VduSetArea(e.CurrentWindow.Col, e.CurrentWindow.Row, e.CurrentWindow.Width, e.windowTextHeight(e.CurrentWindow))
+	if e.ScrFrame == e.CurrentFrame && e.CurrentFrame.Dot.Line.ScrRowNr != 0 {
+		e.VduMoveCurs(e.screenDotCol(), e.CurrentFrame.Dot.Line.ScrRowNr)
+	}
+}
+
 // WindowCommand implements all window-related commands
-func WindowCommand(command Commands, rept LeadParam, count int, fromSpan bool) bool {
+func (e *Editor) WindowCommand(command Commands, rept LeadParam, count int, fromSpan bool) bool {
 	cmdSuccess := false
 
 	var lineNr int
@@ -24,158 +381,158 @@ func WindowCommand(command Commands, rept LeadParam, count int, fromSpan bool) b
 
 	switch command {
 	case CmdWindowBackward:
-		if !LineToNumber(CurrentFrame.Dot.Line, &lineNr) {
+		if !LineToNumber(e.CurrentFrame.Dot.Line, &lineNr) {
 			goto cleanup
 		}
-		if lineNr <= CurrentFrame.ScrHeight*count {
+		if lineNr <= e.CurrentFrame.ScrHeight*count {
 			MarkCreate(
-				CurrentFrame.FirstGroup.FirstLine, CurrentFrame.Dot.Col, &CurrentFrame.Dot,
+				e.CurrentFrame.FirstGroup.FirstLine, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Dot,
 			)
 		} else {
-			newLine := CurrentFrame.Dot.Line
-			for i := 1; i <= CurrentFrame.ScrHeight*count; i++ {
+			newLine := e.CurrentFrame.Dot.Line
+			for i := 1; i <= e.CurrentFrame.ScrHeight*count; i++ {
 				newLine = newLine.BLink
 			}
 			if count == 1 {
-				line := CurrentFrame.Dot.Line
+				line := e.CurrentFrame.Dot.Line
 				if line.ScrRowNr != 0 {
-					if line.ScrRowNr > CurrentFrame.ScrHeight-CurrentFrame.MarginBottom {
-						ScreenScroll(
-							-2*CurrentFrame.ScrHeight+line.ScrRowNr+CurrentFrame.MarginBottom,
+					if line.ScrRowNr > e.CurrentFrame.ScrHeight-e.CurrentFrame.MarginBottom {
+						e.ScreenScroll(
+							-2*e.CurrentFrame.ScrHeight+line.ScrRowNr+e.CurrentFrame.MarginBottom,
 							true,
 						)
 					} else {
-						ScreenScroll(-CurrentFrame.ScrHeight, true)
+						e.ScreenScroll(-e.CurrentFrame.ScrHeight, true)
 					}
 				}
 			} else {
-				ScreenUnload()
+				e.ScreenUnload()
 			}
-			MarkCreate(newLine, CurrentFrame.Dot.Col, &CurrentFrame.Dot)
+			MarkCreate(newLine, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Dot)
 		}
 		cmdSuccess = true
 
 	case CmdWindowEnd:
 		cmdSuccess = MarkCreate(
-			CurrentFrame.LastGroup.LastLine, CurrentFrame.Dot.Col, &CurrentFrame.Dot,
+			e.CurrentFrame.LastGroup.LastLine, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Dot,
 		)
 
 	case CmdWindowForward:
-		if !LineToNumber(CurrentFrame.Dot.Line, &lineNr) {
+		if !LineToNumber(e.CurrentFrame.Dot.Line, &lineNr) {
 			goto cleanup
 		}
-		lastGroup := CurrentFrame.LastGroup
-		dot := CurrentFrame.Dot
-		if lineNr+CurrentFrame.ScrHeight*count >
+		lastGroup := e.CurrentFrame.LastGroup
+		dot := e.CurrentFrame.Dot
+		if lineNr+e.CurrentFrame.ScrHeight*count >
 			lastGroup.FirstLineNr+lastGroup.LastLine.OffsetNr {
 			MarkCreate(lastGroup.LastLine, dot.Col, &dot)
 		} else {
 			newLine := dot.Line
-			for i := 1; i <= CurrentFrame.ScrHeight*count; i++ {
+			for i := 1; i <= e.CurrentFrame.ScrHeight*count; i++ {
 				newLine = newLine.FLink
 			}
 			if count == 1 {
 				line := dot.Line
 				if line.ScrRowNr != 0 {
-					if line.ScrRowNr <= CurrentFrame.MarginTop {
-						ScreenScroll(
-							CurrentFrame.ScrHeight+line.ScrRowNr-CurrentFrame.MarginTop-1,
+					if line.ScrRowNr <= e.CurrentFrame.MarginTop {
+						e.ScreenScroll(
+							e.CurrentFrame.ScrHeight+line.ScrRowNr-e.CurrentFrame.MarginTop-1,
 							true,
 						)
 					} else {
-						ScreenScroll(CurrentFrame.ScrHeight, true)
+						e.ScreenScroll(e.CurrentFrame.ScrHeight, true)
 					}
 				}
 			} else {
-				ScreenUnload()
+				e.ScreenUnload()
 			}
-			MarkCreate(newLine, dot.Col, &CurrentFrame.Dot)
+			MarkCreate(newLine, dot.Col, &e.CurrentFrame.Dot)
 		}
 		cmdSuccess = true
 
 	case CmdWindowLeft:
 		cmdSuccess = true
-		if ScrFrame == CurrentFrame {
+		if e.ScrFrame == e.CurrentFrame {
 			if rept == LeadParamNone {
-				count = CurrentFrame.ScrWidth / 2
+				count = e.CurrentFrame.ScrWidth / 2
 			}
-			if CurrentFrame.ScrOffset < count {
-				count = CurrentFrame.ScrOffset
+			if e.CurrentFrame.ScrOffset < count {
+				count = e.CurrentFrame.ScrOffset
 			}
-			ScreenSlide(-count)
-			if CurrentFrame.ScrOffset+CurrentFrame.ScrWidth < CurrentFrame.Dot.Col {
-				CurrentFrame.Dot.Col = CurrentFrame.ScrOffset + CurrentFrame.ScrWidth
+			e.ScreenSlide(-count)
+			if e.CurrentFrame.ScrOffset+e.CurrentFrame.ScrWidth < e.CurrentFrame.Dot.Col {
+				e.CurrentFrame.Dot.Col = e.CurrentFrame.ScrOffset + e.CurrentFrame.ScrWidth
 			}
 		}
 
 	case CmdWindowMiddle:
 		cmdSuccess = true
-		if ScrFrame == CurrentFrame {
-			if LineToNumber(CurrentFrame.Dot.Line, &lineNr) &&
-				LineToNumber(ScrTopLine, &line2Nr) && LineToNumber(ScrBotLine, &line3Nr) {
-				ScreenScroll(lineNr-((line2Nr+line3Nr)/2), true)
+		if e.ScrFrame == e.CurrentFrame {
+			if LineToNumber(e.CurrentFrame.Dot.Line, &lineNr) &&
+				LineToNumber(e.ScrTopLine, &line2Nr) && LineToNumber(e.ScrBotLine, &line3Nr) {
+				e.ScreenScroll(lineNr-((line2Nr+line3Nr)/2), true)
 			}
 		}
 
 	case CmdWindowNew:
 		cmdSuccess = true
-		ScreenRedraw()
+		e.ScreenRedraw()
 
 	case CmdWindowRight:
 		cmdSuccess = true
-		if ScrFrame == CurrentFrame {
+		if e.ScrFrame == e.CurrentFrame {
 			if rept == LeadParamNone {
-				count = CurrentFrame.ScrWidth / 2
+				count = e.CurrentFrame.ScrWidth / 2
 			}
-			if MaxStrLenP < (CurrentFrame.ScrOffset+CurrentFrame.ScrWidth)+count {
-				count = MaxStrLenP - (CurrentFrame.ScrOffset + CurrentFrame.ScrWidth)
+			if MaxStrLenP < (e.CurrentFrame.ScrOffset+e.CurrentFrame.ScrWidth)+count {
+				count = MaxStrLenP - (e.CurrentFrame.ScrOffset + e.CurrentFrame.ScrWidth)
 			}
-			ScreenSlide(count)
-			if CurrentFrame.Dot.Col <= CurrentFrame.ScrOffset {
-				CurrentFrame.Dot.Col = CurrentFrame.ScrOffset + 1
+			e.ScreenSlide(count)
+			if e.CurrentFrame.Dot.Col <= e.CurrentFrame.ScrOffset {
+				e.CurrentFrame.Dot.Col = e.CurrentFrame.ScrOffset + 1
 			}
 		}
 
 	case CmdWindowScroll:
 		cmdSuccess = true
-		if CurrentFrame == ScrFrame {
+		if e.CurrentFrame == e.ScrFrame {
 			var key int
 			for {
 				switch rept {
 				case LeadParamPIndef:
-					count = max(CurrentFrame.Dot.Line.ScrRowNr-1, 0)
+					count = max(e.CurrentFrame.Dot.Line.ScrRowNr-1, 0)
 				case LeadParamNIndef:
-					count = CurrentFrame.Dot.Line.ScrRowNr - CurrentFrame.ScrHeight
+					count = e.CurrentFrame.Dot.Line.ScrRowNr - e.CurrentFrame.ScrHeight
 				}
 				if rept != LeadParamNone {
-					ScreenScroll(count, true)
+					e.ScreenScroll(count, true)
 				}
 				key = 0
 
 				// If the dot is still visible and the command is interactive
 				// then support stay-behind mode
-				if !fromSpan && (CurrentFrame.Dot.Line.ScrRowNr != 0) &&
-					(CurrentFrame.ScrOffset < CurrentFrame.Dot.Col) &&
-					(CurrentFrame.Dot.Col <= CurrentFrame.ScrOffset+CurrentFrame.ScrWidth) {
+				if !fromSpan && (e.CurrentFrame.Dot.Line.ScrRowNr != 0) &&
+					(e.CurrentFrame.ScrOffset < e.CurrentFrame.Dot.Col) &&
+					(e.screenDotCol() <= e.CurrentFrame.ScrWidth) {
 					if !cmdSuccess {
-						VduBeep()
+						e.VduBeep()
 						cmdSuccess = true
 					}
-					VduMoveCurs(
-						CurrentFrame.Dot.Col-CurrentFrame.ScrOffset,
-						CurrentFrame.Dot.Line.ScrRowNr,
+					e.VduMoveCurs(
+						e.screenDotCol(),
+						e.CurrentFrame.Dot.Line.ScrRowNr,
 					)
-					key = VduGetKey()
-					if TtControlC {
+					key = e.VduGetKey()
+					if e.TtControlC.Load() {
 						key = 0
-					} else if Lookup[key].Command == CmdUp {
+					} else if e.KeyLookup(key).Command == CmdUp {
 						rept = LeadParamPInt
 						count = 1
-					} else if Lookup[key].Command == CmdDown {
+					} else if e.KeyLookup(key).Command == CmdDown {
 						rept = LeadParamNInt
 						count = -1
 					} else {
-						VduTakeBackKey(key)
+						e.VduTakeBackKey(key)
 						key = 0
 					}
 				}
@@ -187,21 +544,42 @@ func WindowCommand(command Commands, rept LeadParam, count int, fromSpan bool) b
 
 	case CmdWindowSetHeight:
 		if rept == LeadParamNone {
-			count = TerminalInfo.Height
+			count = e.TerminalInfo.Height
 		}
-		cmdSuccess = FrameSetHeight(count, false)
+		cmdSuccess = e.FrameSetHeight(count, false)
 
 	case CmdWindowTop:
 		cmdSuccess = MarkCreate(
-			CurrentFrame.FirstGroup.FirstLine, CurrentFrame.Dot.Col, &CurrentFrame.Dot,
+			e.CurrentFrame.FirstGroup.FirstLine, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Dot,
 		)
 
 	case CmdWindowUpdate:
 		cmdSuccess = true
-		if LudwigMode == LudwigScreen {
-			ScreenFixup()
+		if e.LudwigMode == LudwigScreen {
+			e.ScreenFixup()
 		}
 
+	case CmdWindowSplit:
+		cmdSuccess = e.windowSplit(false)
+
+	case CmdWindowSplitVertical:
+		cmdSuccess = e.windowSplit(true)
+
+	case CmdWindowNext:
+		switch rept {
+		case LeadParamNone, LeadParamPlus:
+			count = 1
+		case LeadParamMinus:
+			count = -1
+		}
+		cmdSuccess = e.windowNext(count)
+
+	case CmdWindowKill:
+		cmdSuccess = e.windowKill()
+
+	case CmdWindowOnly:
+		cmdSuccess = e.windowOnly()
+
 	default:
 		// All other commands ignored
 	}
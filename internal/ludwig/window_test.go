@@ -0,0 +1,122 @@
+// Tests for window.go functions
+
+package ludwig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupWindows sets up a screen of the given size with no windows
+func setupWindows(e *Editor, width, height int) {
+	e.LudwigMode = LudwigScreen
+	e.screenSetSize(width, height)
+}
+
+func TestWindowLayout(t *testing.T) {
+	left := &WindowObject{}
+	top := &WindowObject{}
+	bottom := &WindowObject{}
+	right := &WindowObject{Halves: [2]*WindowObject{top, bottom}}
+	root := &WindowObject{Halves: [2]*WindowObject{left, right}, Vertical: true}
+
+	windowLayout(root, 1, 1, 25, 80)
+	assert.Equal(t, []*WindowObject{left, top, bottom}, windowLeaves(root, nil))
+
+	// The separator takes the column between the halves
+	assert.Equal(t, [4]int{1, 1, 25, 39}, [4]int{left.Row, left.Col, left.Height, left.Width})
+	assert.Equal(t, [4]int{1, 41, 12, 40}, [4]int{top.Row, top.Col, top.Height, top.Width})
+	assert.Equal(t, [4]int{13, 41, 13, 40}, [4]int{bottom.Row, bottom.Col, bottom.Height, bottom.Width})
+}
+
+func TestWindowSplitNextKill(t *testing.T) {
+	e := NewEditor()
+	setupWindows(e, 80, 24)
+	frame := setupUndoFrame(t, e, "one", "two", "three")
+
+	setDot(t, e, 2, 1)
+	require.True(t, e.windowSplit(false))
+	require.NotNil(t, e.RootWindow)
+	first := e.CurrentWindow
+	assert.Equal(t, e.RootWindow.Halves[0], first)
+	assert.Equal(t, 11, e.TerminalInfo.Height)
+	assert.Equal(t, 80, e.TerminalInfo.Width)
+	assert.False(t, e.WindowShows(nil))
+
+	// Each window keeps its own Dot
+	setDot(t, e, 3, 2)
+	require.True(t, e.windowNext(1))
+	second := e.CurrentWindow
+	assert.Equal(t, e.RootWindow.Halves[1], second)
+	assert.Equal(t, "two", frame.Dot.Line.Str.Slice(1, frame.Dot.Line.Used))
+	assert.Equal(t, 1, frame.Dot.Col)
+	assert.NotNil(t, first.Dot)
+	assert.True(t, e.WindowShows(frame))
+
+	require.True(t, e.windowSplit(true))
+	assert.Len(t, windowLeaves(e.RootWindow, nil), 3)
+	assert.Equal(t, 39, e.TerminalInfo.Width)
+
+	// Counting wraps around at either end
+	require.True(t, e.windowNext(-2))
+	assert.Equal(t, e.RootWindow.Halves[1].Halves[1], e.CurrentWindow)
+	require.True(t, e.windowNext(1))
+	assert.Equal(t, first, e.CurrentWindow)
+	assert.Equal(t, "three", frame.Dot.Line.Str.Slice(1, frame.Dot.Line.Used))
+	assert.Equal(t, 2, frame.Dot.Col)
+
+	require.True(t, e.windowKill())
+	assert.Len(t, windowLeaves(e.RootWindow, nil), 2)
+	assert.True(t, e.RootWindow.Vertical)
+	assert.Equal(t, 24, e.RootWindow.Halves[0].Height)
+
+	require.True(t, e.windowKill())
+	assert.Nil(t, e.RootWindow)
+	assert.Nil(t, e.CurrentWindow)
+	assert.Equal(t, 24, e.TerminalInfo.Height)
+	assert.Equal(t, 80, e.TerminalInfo.Width)
+	assert.Equal(t, frame, e.CurrentFrame)
+
+	e.LudwigMode = LudwigBatch
+	assert.False(t, e.windowKill())
+}
+
+func TestWindowOnly(t *testing.T) {
+	e := NewEditor()
+	setupWindows(e, 80, 24)
+	frame := setupUndoFrame(t, e, "one", "two")
+
+	require.True(t, e.windowSplit(true))
+	require.True(t, e.windowSplit(false))
+	setDot(t, e, 2, 3)
+	require.True(t, e.windowOnly())
+	assert.Nil(t, e.RootWindow)
+	assert.Equal(t, 24, e.TerminalInfo.Height)
+	assert.Equal(t, "two", frame.Dot.Line.Str.Slice(1, frame.Dot.Line.Used))
+	assert.Equal(t, 3, frame.Dot.Col)
+
+	// Only the Dot marks of the frame are left
+	for line := frame.FirstGroup.FirstLine; line != nil; line = line.FLink {
+		for _, mark := range line.Marks {
+			assert.Equal(t, frame.Dot, mark)
+		}
+	}
+}
+
+func TestWindowSplitTooSmall(t *testing.T) {
+	e := NewEditor()
+	setupWindows(e, 80, 5)
+	setupUndoFrame(t, e, "one")
+
+	// There is no terminal to show the message on
+	e.Hangup = true
+	assert.False(t, e.windowSplit(false))
+	assert.Nil(t, e.RootWindow)
+	assert.Equal(t, 5, e.TerminalInfo.Height)
+
+	e.Hangup = false
+	e.LudwigMode = LudwigBatch
+	assert.False(t, e.windowSplit(true))
+}
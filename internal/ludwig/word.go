@@ -15,7 +15,7 @@
 package ludwig
 
 // WordFill fills/wraps text within margins
-func WordFill(rept LeadParam, count int) bool {
+func (e *Editor) WordFill(rept LeadParam, count int) bool {
 	var startChar int
 	var endChar int
 	var oldEnd int
@@ -41,7 +41,7 @@ func WordFill(rept LeadParam, count int) bool {
 	}
 	if rept == LeadParamPInt {
 		lineCount = count
-		thisLine = CurrentFrame.Dot.Line
+		thisLine = e.CurrentFrame.Dot.Line
 		for (lineCount > 0) && (thisLine.Used > 0) {
 			thisLine = thisLine.FLink
 			lineCount--
@@ -53,47 +53,47 @@ func WordFill(rept LeadParam, count int) bool {
 			goto cleanup
 		}
 	}
-	for (count > 0) && (CurrentFrame.Dot.Line.Used > 0) {
-		if CurrentFrame.Dot.Line.FLink == nil {
+	for (count > 0) && (e.CurrentFrame.Dot.Line.Used > 0) {
+		if e.CurrentFrame.Dot.Line.FLink == nil {
 			goto cleanup
 		}
 		// Adjust the current line to the margins
-		if CurrentFrame.Dot.Line.BLink != nil {
-			if CurrentFrame.Dot.Line.BLink.Used != 0 {
+		if e.CurrentFrame.Dot.Line.BLink != nil {
+			if e.CurrentFrame.Dot.Line.BLink.Used != 0 {
 				// Not the first line of a paragraph, adjust to left margin
 				startChar = 1
-				for (CurrentFrame.Dot.Line.Str.Get(startChar) == ' ') &&
-					(startChar < CurrentFrame.Dot.Line.Used) {
+				for (e.CurrentFrame.Dot.Line.Str.Get(startChar) == ' ') &&
+					(startChar < e.CurrentFrame.Dot.Line.Used) {
 					startChar++
 				}
-				if (startChar < CurrentFrame.MarginLeft) &&
-					(startChar < CurrentFrame.Dot.Line.Used) {
-					if !MarkCreate(CurrentFrame.Dot.Line, startChar, &here) {
+				if (startChar < e.CurrentFrame.MarginLeft) &&
+					(startChar < e.CurrentFrame.Dot.Line.Used) {
+					if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &here) {
 						goto cleanup
 					}
-					if !TextInsert(
-						true, 1, BlankString, CurrentFrame.MarginLeft-startChar, here,
+					if !e.TextInsert(
+						true, 1, NewBlankStrObject(e.CurrentFrame.MarginLeft-startChar), e.CurrentFrame.MarginLeft-startChar, here,
 					) {
 						goto cleanup
 					}
 					MarkDestroy(&here)
 				} else {
 					// Might have to remove some spaces
-					startChar = CurrentFrame.MarginLeft
-					endChar = CurrentFrame.MarginLeft
-					if endChar < CurrentFrame.Dot.Line.Used {
-						for (CurrentFrame.Dot.Line.Str.Get(endChar) == ' ') &&
-							(endChar < CurrentFrame.Dot.Line.Used) {
+					startChar = e.CurrentFrame.MarginLeft
+					endChar = e.CurrentFrame.MarginLeft
+					if endChar < e.CurrentFrame.Dot.Line.Used {
+						for (e.CurrentFrame.Dot.Line.Str.Get(endChar) == ' ') &&
+							(endChar < e.CurrentFrame.Dot.Line.Used) {
 							endChar++
 						}
 						if endChar > 1 {
-							if !MarkCreate(CurrentFrame.Dot.Line, startChar, &here) {
+							if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &here) {
 								goto cleanup
 							}
-							if !MarkCreate(CurrentFrame.Dot.Line, endChar, &there) {
+							if !MarkCreate(e.CurrentFrame.Dot.Line, endChar, &there) {
 								goto cleanup
 							}
-							if !TextRemove(here, there) {
+							if !e.TextRemove(here, there) {
 								goto cleanup
 							}
 							MarkDestroy(&here)
@@ -103,41 +103,41 @@ func WordFill(rept LeadParam, count int) bool {
 				}
 			}
 		}
-		if CurrentFrame.Dot.Line.Used > CurrentFrame.MarginRight {
+		if e.CurrentFrame.Dot.Line.Used > e.CurrentFrame.MarginRight {
 			// Must split this line if possible
 			// 1. Scan back for first non-blank
-			endChar = CurrentFrame.MarginRight + 1
-			if CurrentFrame.Dot.Line.Str.Get(endChar) != ' ' {
-				for (CurrentFrame.Dot.Line.Str.Get(endChar) != ' ') &&
-					(endChar > CurrentFrame.MarginLeft) {
+			endChar = e.CurrentFrame.MarginRight + 1
+			if e.CurrentFrame.Dot.Line.Str.Get(endChar) != ' ' {
+				for (e.CurrentFrame.Dot.Line.Str.Get(endChar) != ' ') &&
+					(endChar > e.CurrentFrame.MarginLeft) {
 					endChar--
 				}
-				if endChar == CurrentFrame.MarginLeft {
+				if endChar == e.CurrentFrame.MarginLeft {
 					goto cleanup
 				}
 			}
 			startChar = endChar
-			for (CurrentFrame.Dot.Line.Str.Get(endChar) == ' ') &&
-				(endChar > CurrentFrame.MarginLeft) {
+			for (e.CurrentFrame.Dot.Line.Str.Get(endChar) == ' ') &&
+				(endChar > e.CurrentFrame.MarginLeft) {
 				endChar--
 			}
-			if endChar == CurrentFrame.MarginLeft {
+			if endChar == e.CurrentFrame.MarginLeft {
 				goto cleanup
 			}
 			// 2. Scan forward for first non-blank
-			for CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
+			for e.CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
 				startChar++
 			}
 			// 3. Split the line and ensure new line starts at margin_left
-			if !MarkCreate(CurrentFrame.Dot.Line, startChar, &here) {
+			if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &here) {
 				goto cleanup
 			}
-			if CurrentFrame.Dot.Col > endChar {
-				if !MarkCreate(CurrentFrame.Dot.Line, endChar, &CurrentFrame.Dot) {
+			if e.CurrentFrame.Dot.Col > endChar {
+				if !MarkCreate(e.CurrentFrame.Dot.Line, endChar, &e.CurrentFrame.Dot) {
 					goto cleanup
 				}
 			}
-			if !TextSplitLine(here, CurrentFrame.MarginLeft, &there) {
+			if !e.TextSplitLine(here, e.CurrentFrame.MarginLeft, &there) {
 				goto cleanup
 			}
 			if !MarkDestroy(&here) {
@@ -153,28 +153,28 @@ func WordFill(rept LeadParam, count int) bool {
 			// Need to get stuff from the next line
 		getMore:
 			// 1. Figure out how many chars we can fit in
-			spaceToAdd = CurrentFrame.MarginRight - CurrentFrame.Dot.Line.Used - 1
+			spaceToAdd = e.CurrentFrame.MarginRight - e.CurrentFrame.Dot.Line.Used - 1
 			// 2. See if we can find a word to fit
 			startChar = 1
-			if (spaceToAdd > 0) && (CurrentFrame.Dot.Line.FLink.Used != 0) {
-				for CurrentFrame.Dot.Line.FLink.Str.Get(startChar) == ' ' {
+			if (spaceToAdd > 0) && (e.CurrentFrame.Dot.Line.FLink.Used != 0) {
+				for e.CurrentFrame.Dot.Line.FLink.Str.Get(startChar) == ' ' {
 					startChar++
 				}
 				endChar = startChar
 				oldEnd = endChar
-				for endChar <= CurrentFrame.Dot.Line.FLink.Used {
-					for CurrentFrame.Dot.Line.FLink.Str.Get(endChar) == ' ' {
+				for endChar <= e.CurrentFrame.Dot.Line.FLink.Used {
+					for e.CurrentFrame.Dot.Line.FLink.Str.Get(endChar) == ' ' {
 						endChar++
 					}
-					for (CurrentFrame.Dot.Line.FLink.Str.Get(endChar) != ' ') &&
-						(endChar < CurrentFrame.Dot.Line.FLink.Used) {
+					for (e.CurrentFrame.Dot.Line.FLink.Str.Get(endChar) != ' ') &&
+						(endChar < e.CurrentFrame.Dot.Line.FLink.Used) {
 						endChar++
 					}
-					if endChar == CurrentFrame.Dot.Line.FLink.Used {
+					if endChar == e.CurrentFrame.Dot.Line.FLink.Used {
 						endChar++
 					}
 					if spaceToAdd < (endChar - startChar) {
-						endChar = CurrentFrame.Dot.Line.FLink.Used + 1
+						endChar = e.CurrentFrame.Dot.Line.FLink.Used + 1
 					} else {
 						oldEnd = endChar
 					}
@@ -183,26 +183,26 @@ func WordFill(rept LeadParam, count int) bool {
 					// It will fit
 					oldHere = nil
 					oldThere = nil
-					if !MarkCreate(CurrentFrame.Dot.Line.FLink, startChar, &here) {
+					if !MarkCreate(e.CurrentFrame.Dot.Line.FLink, startChar, &here) {
 						goto cleanup
 					}
-					if !MarkCreate(CurrentFrame.Dot.Line.FLink, startChar, &oldHere) {
+					if !MarkCreate(e.CurrentFrame.Dot.Line.FLink, startChar, &oldHere) {
 						goto cleanup
 					}
-					if !MarkCreate(CurrentFrame.Dot.Line.FLink, oldEnd, &there) {
+					if !MarkCreate(e.CurrentFrame.Dot.Line.FLink, oldEnd, &there) {
 						goto cleanup
 					}
-					if !MarkCreate(CurrentFrame.Dot.Line.FLink, oldEnd, &oldThere) {
+					if !MarkCreate(e.CurrentFrame.Dot.Line.FLink, oldEnd, &oldThere) {
 						goto cleanup
 					}
-					CurrentFrame.Dot.Col = CurrentFrame.Dot.Line.Used + 2
+					e.CurrentFrame.Dot.Col = e.CurrentFrame.Dot.Line.Used + 2
 					// Copy the text
-					if !TextMove(true, 1, here, there, CurrentFrame.Dot, &here, &there) {
+					if !e.TextMove(true, 1, here, there, e.CurrentFrame.Dot, &here, &there) {
 						goto cleanup
 					}
 					// Copy the marks
 					if !MarksShift(
-						CurrentFrame.Dot.Line.FLink,
+						e.CurrentFrame.Dot.Line.FLink,
 						oldHere.Col,
 						oldThere.Col-oldHere.Col,
 						here.Line,
@@ -211,29 +211,29 @@ func WordFill(rept LeadParam, count int) bool {
 						goto cleanup
 					}
 					// Wipe out the old text
-					if !MarkCreate(CurrentFrame.Dot.Line.FLink, 1, &oldHere) {
+					if !MarkCreate(e.CurrentFrame.Dot.Line.FLink, 1, &oldHere) {
 						goto cleanup
 					}
-					if !MarkCreate(CurrentFrame.Dot.Line.FLink, oldEnd, &oldThere) {
+					if !MarkCreate(e.CurrentFrame.Dot.Line.FLink, oldEnd, &oldThere) {
 						goto cleanup
 					}
-					if !TextRemove(oldHere, oldThere) {
+					if !e.TextRemove(oldHere, oldThere) {
 						goto cleanup
 					}
 					MarkDestroy(&oldHere)
 					MarkDestroy(&oldThere)
 					// If next line is now empty, delete it
-					if CurrentFrame.Dot.Line.FLink.Used == 0 {
-						thisLine = CurrentFrame.Dot.Line.FLink
+					if e.CurrentFrame.Dot.Line.FLink.Used == 0 {
+						thisLine = e.CurrentFrame.Dot.Line.FLink
 						if !MarksSqueeze(
-							CurrentFrame.Dot.Line.FLink,
+							e.CurrentFrame.Dot.Line.FLink,
 							1,
-							CurrentFrame.Dot.Line.FLink.FLink,
+							e.CurrentFrame.Dot.Line.FLink.FLink,
 							1,
 						) {
 							goto cleanup
 						}
-						if !LinesExtract(thisLine, thisLine) {
+						if !e.LinesExtract(thisLine, thisLine) {
 							goto cleanup
 						}
 						if !LinesDestroy(&thisLine, &thisLine) {
@@ -247,32 +247,32 @@ func WordFill(rept LeadParam, count int) bool {
 					}
 				}
 				// Make sure first char in next line is at left margin
-				if (count > 0) && (CurrentFrame.Dot.Line.FLink.Used != 0) {
+				if (count > 0) && (e.CurrentFrame.Dot.Line.FLink.Used != 0) {
 					startChar = 1
-					for CurrentFrame.Dot.Line.FLink.Str.Get(startChar) == ' ' {
+					for e.CurrentFrame.Dot.Line.FLink.Str.Get(startChar) == ' ' {
 						startChar++
 					}
-					if !MarkCreate(CurrentFrame.Dot.Line.FLink, startChar, &there) {
+					if !MarkCreate(e.CurrentFrame.Dot.Line.FLink, startChar, &there) {
 						goto cleanup
 					}
-					if startChar < CurrentFrame.MarginLeft {
+					if startChar < e.CurrentFrame.MarginLeft {
 						// Must insert some chars
-						if !TextInsert(
+						if !e.TextInsert(
 							true,
 							1,
-							BlankString,
-							CurrentFrame.MarginLeft-startChar,
+							NewBlankStrObject(e.CurrentFrame.MarginLeft-startChar),
+							e.CurrentFrame.MarginLeft-startChar,
 							there,
 						) {
 							goto cleanup
 						}
 					} else {
 						if !MarkCreate(
-							CurrentFrame.Dot.Line.FLink, CurrentFrame.MarginLeft, &here,
+							e.CurrentFrame.Dot.Line.FLink, e.CurrentFrame.MarginLeft, &here,
 						) {
 							goto cleanup
 						}
-						if !TextRemove(here, there) {
+						if !e.TextRemove(here, there) {
 							goto cleanup
 						}
 					}
@@ -292,16 +292,16 @@ func WordFill(rept LeadParam, count int) bool {
 		count--
 		if !leaveDotAlone {
 			if !MarkCreate(
-				CurrentFrame.Dot.Line.FLink, CurrentFrame.MarginLeft, &CurrentFrame.Dot,
+				e.CurrentFrame.Dot.Line.FLink, e.CurrentFrame.MarginLeft, &e.CurrentFrame.Dot,
 			) {
 				goto cleanup
 			}
 		}
-		CurrentFrame.TextModified = true
+		e.CurrentFrame.TextModified = true
 		if !MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkModified],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkModified],
 		) {
 			goto cleanup
 		}
@@ -318,7 +318,7 @@ cleanup:
 }
 
 // WordCentre centers text between margins
-func WordCentre(rept LeadParam, count int) bool {
+func (e *Editor) WordCentre(rept LeadParam, count int) bool {
 	var startChar int
 	var lineCount int
 	var spaceToAdd int
@@ -338,7 +338,7 @@ func WordCentre(rept LeadParam, count int) bool {
 	}
 	if rept == LeadParamPInt {
 		lineCount = count
-		thisLine = CurrentFrame.Dot.Line
+		thisLine = e.CurrentFrame.Dot.Line
 		for (lineCount > 0) && (thisLine.Used > 0) {
 			thisLine = thisLine.FLink
 			lineCount--
@@ -350,42 +350,42 @@ func WordCentre(rept LeadParam, count int) bool {
 			goto cleanup
 		}
 	}
-	for (count > 0) && (CurrentFrame.Dot.Line.Used > 0) {
-		if CurrentFrame.Dot.Line.FLink == nil {
+	for (count > 0) && (e.CurrentFrame.Dot.Line.Used > 0) {
+		if e.CurrentFrame.Dot.Line.FLink == nil {
 			goto cleanup
 		}
-		if (CurrentFrame.Dot.Line.Used < CurrentFrame.MarginLeft) ||
-			(CurrentFrame.Dot.Line.Used > CurrentFrame.MarginRight) {
+		if (e.CurrentFrame.Dot.Line.Used < e.CurrentFrame.MarginLeft) ||
+			(e.CurrentFrame.Dot.Line.Used > e.CurrentFrame.MarginRight) {
 			goto cleanup
 		}
 		startChar = 1
-		for CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
+		for e.CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
 			startChar++
 		}
-		if startChar < CurrentFrame.MarginLeft {
+		if startChar < e.CurrentFrame.MarginLeft {
 			goto cleanup
 		}
-		spaceToAdd = (CurrentFrame.MarginRight-CurrentFrame.MarginLeft-
-			(CurrentFrame.Dot.Line.Used-startChar))/2 -
-			(startChar - CurrentFrame.MarginLeft)
+		spaceToAdd = (e.CurrentFrame.MarginRight-e.CurrentFrame.MarginLeft-
+			(e.CurrentFrame.Dot.Line.Used-startChar))/2 -
+			(startChar - e.CurrentFrame.MarginLeft)
 		if spaceToAdd > 0 {
-			if !MarkCreate(CurrentFrame.Dot.Line, startChar, &here) {
+			if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &here) {
 				goto cleanup
 			}
-			if !TextInsert(true, 1, BlankString, spaceToAdd, here) {
+			if !e.TextInsert(true, 1, NewBlankStrObject(spaceToAdd), spaceToAdd, here) {
 				goto cleanup
 			}
 			if !MarkDestroy(&here) {
 				goto cleanup
 			}
 		} else if spaceToAdd < 0 {
-			if !MarkCreate(CurrentFrame.Dot.Line, startChar, &there) {
+			if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &there) {
 				goto cleanup
 			}
-			if !MarkCreate(CurrentFrame.Dot.Line, startChar-spaceToAdd, &here) {
+			if !MarkCreate(e.CurrentFrame.Dot.Line, startChar-spaceToAdd, &here) {
 				goto cleanup
 			}
-			if !TextRemove(there, here) {
+			if !e.TextRemove(there, here) {
 				goto cleanup
 			}
 			if !MarkDestroy(&here) {
@@ -397,15 +397,15 @@ func WordCentre(rept LeadParam, count int) bool {
 		}
 		count--
 		if !MarkCreate(
-			CurrentFrame.Dot.Line.FLink, CurrentFrame.MarginLeft, &CurrentFrame.Dot,
+			e.CurrentFrame.Dot.Line.FLink, e.CurrentFrame.MarginLeft, &e.CurrentFrame.Dot,
 		) {
 			goto cleanup
 		}
-		CurrentFrame.TextModified = true
+		e.CurrentFrame.TextModified = true
 		if !MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkModified],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkModified],
 		) {
 			goto cleanup
 		}
@@ -422,7 +422,7 @@ cleanup:
 }
 
 // WordJustify space-justifies text between margins
-func WordJustify(rept LeadParam, count int) bool {
+func (e *Editor) WordJustify(rept LeadParam, count int) bool {
 	var startChar int
 	var endChar int
 	var holes int
@@ -445,7 +445,7 @@ func WordJustify(rept LeadParam, count int) bool {
 	}
 	if rept == LeadParamPInt {
 		lineCount = count
-		thisLine = CurrentFrame.Dot.Line
+		thisLine = e.CurrentFrame.Dot.Line
 		for (lineCount > 0) && (thisLine.Used > 0) {
 			thisLine = thisLine.FLink
 			lineCount--
@@ -457,38 +457,38 @@ func WordJustify(rept LeadParam, count int) bool {
 			goto cleanup
 		}
 	}
-	for (count > 0) && (CurrentFrame.Dot.Line.Used > 0) {
-		if CurrentFrame.Dot.Line.FLink == nil {
+	for (count > 0) && (e.CurrentFrame.Dot.Line.Used > 0) {
+		if e.CurrentFrame.Dot.Line.FLink == nil {
 			goto cleanup
 		}
-		if CurrentFrame.Dot.Line.FLink.Used == 0 {
+		if e.CurrentFrame.Dot.Line.FLink.Used == 0 {
 			goto nextLine
 		}
-		if CurrentFrame.Dot.Line.Used > CurrentFrame.MarginRight {
+		if e.CurrentFrame.Dot.Line.Used > e.CurrentFrame.MarginRight {
 			goto cleanup
 		}
 
 		// Figure out how many spaces to add
-		spaceToAdd = CurrentFrame.MarginRight - CurrentFrame.Dot.Line.Used
+		spaceToAdd = e.CurrentFrame.MarginRight - e.CurrentFrame.Dot.Line.Used
 		// Find number of holes for space distribution
-		startChar = CurrentFrame.MarginLeft
-		for (CurrentFrame.Dot.Line.Str.Get(startChar) == ' ') &&
-			(startChar < CurrentFrame.Dot.Line.Used) {
+		startChar = e.CurrentFrame.MarginLeft
+		for (e.CurrentFrame.Dot.Line.Str.Get(startChar) == ' ') &&
+			(startChar < e.CurrentFrame.Dot.Line.Used) {
 			startChar++
 		}
 		endChar = startChar
 		holes = 0
 		for {
-			for (CurrentFrame.Dot.Line.Str.Get(startChar) != ' ') &&
-				(startChar < CurrentFrame.Dot.Line.Used) {
+			for (e.CurrentFrame.Dot.Line.Str.Get(startChar) != ' ') &&
+				(startChar < e.CurrentFrame.Dot.Line.Used) {
 				startChar++
 			}
-			for (CurrentFrame.Dot.Line.Str.Get(startChar) == ' ') &&
-				(startChar < CurrentFrame.Dot.Line.Used) {
+			for (e.CurrentFrame.Dot.Line.Str.Get(startChar) == ' ') &&
+				(startChar < e.CurrentFrame.Dot.Line.Used) {
 				startChar++
 			}
 			holes++
-			if !(startChar < CurrentFrame.Dot.Line.Used) {
+			if !(startChar < e.CurrentFrame.Dot.Line.Used) {
 				break
 			}
 		}
@@ -500,17 +500,17 @@ func WordJustify(rept LeadParam, count int) bool {
 		startChar = endChar
 		for i = 1; i <= holes; i++ {
 			// Find a hole
-			for CurrentFrame.Dot.Line.Str.Get(startChar) != ' ' {
+			for e.CurrentFrame.Dot.Line.Str.Get(startChar) != ' ' {
 				startChar++
 			}
 			debit += fillRatio
 			spaceToAdd = int(debit + 0.5)
 			if spaceToAdd > 0 {
 				here = nil
-				if !MarkCreate(CurrentFrame.Dot.Line, startChar, &here) {
+				if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &here) {
 					goto cleanup
 				}
-				if !TextInsert(true, 1, BlankString, spaceToAdd, here) {
+				if !e.TextInsert(true, 1, NewBlankStrObject(spaceToAdd), spaceToAdd, here) {
 					goto cleanup
 				}
 				if !MarkDestroy(&here) {
@@ -518,22 +518,22 @@ func WordJustify(rept LeadParam, count int) bool {
 				}
 				debit -= float64(spaceToAdd)
 			}
-			for CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
+			for e.CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
 				startChar++
 			}
 		}
 	nextLine:
 		count--
 		if !MarkCreate(
-			CurrentFrame.Dot.Line.FLink, CurrentFrame.MarginLeft, &CurrentFrame.Dot,
+			e.CurrentFrame.Dot.Line.FLink, e.CurrentFrame.MarginLeft, &e.CurrentFrame.Dot,
 		) {
 			goto cleanup
 		}
-		CurrentFrame.TextModified = true
+		e.CurrentFrame.TextModified = true
 		if !MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkModified],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkModified],
 		) {
 			goto cleanup
 		}
@@ -547,7 +547,7 @@ cleanup:
 }
 
 // WordSqueeze removes multiple spaces from lines
-func WordSqueeze(rept LeadParam, count int) bool {
+func (e *Editor) WordSqueeze(rept LeadParam, count int) bool {
 	var startChar int
 	var endChar int
 	var lineCount int
@@ -567,7 +567,7 @@ func WordSqueeze(rept LeadParam, count int) bool {
 	}
 	if rept == LeadParamPInt {
 		lineCount = count
-		thisLine = CurrentFrame.Dot.Line
+		thisLine = e.CurrentFrame.Dot.Line
 		for (lineCount > 0) && (thisLine.Used > 0) {
 			thisLine = thisLine.FLink
 			lineCount--
@@ -579,38 +579,38 @@ func WordSqueeze(rept LeadParam, count int) bool {
 			goto cleanup
 		}
 	}
-	for (count > 0) && (CurrentFrame.Dot.Line.Used > 0) {
-		if CurrentFrame.Dot.Line.FLink == nil {
+	for (count > 0) && (e.CurrentFrame.Dot.Line.Used > 0) {
+		if e.CurrentFrame.Dot.Line.FLink == nil {
 			// on EOP line so abort
 			goto cleanup
 		}
 		startChar = 1
-		for CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
+		for e.CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
 			startChar += 1
 		}
 		// with line^ do
 		for {
-			for CurrentFrame.Dot.Line.Str.Get(startChar) != ' ' &&
-				startChar < CurrentFrame.Dot.Line.Used {
+			for e.CurrentFrame.Dot.Line.Str.Get(startChar) != ' ' &&
+				startChar < e.CurrentFrame.Dot.Line.Used {
 				startChar++
 			}
-			if CurrentFrame.Dot.Line.Str.Get(startChar) != ' ' {
+			if e.CurrentFrame.Dot.Line.Str.Get(startChar) != ' ' {
 				break // Nothing more to do
 			}
 			endChar = startChar
-			for CurrentFrame.Dot.Line.Str.Get(endChar) == ' ' {
+			for e.CurrentFrame.Dot.Line.Str.Get(endChar) == ' ' {
 				endChar++
 			}
 			if (endChar - startChar) > 1 {
 				here = nil
-				if !MarkCreate(CurrentFrame.Dot.Line, startChar, &here) {
+				if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &here) {
 					goto cleanup
 				}
 				there = nil
-				if !MarkCreate(CurrentFrame.Dot.Line, endChar-1, &there) {
+				if !MarkCreate(e.CurrentFrame.Dot.Line, endChar-1, &there) {
 					goto cleanup
 				}
-				if !TextRemove(here, there) {
+				if !e.TextRemove(here, there) {
 					goto cleanup
 				}
 				startChar = here.Col
@@ -620,11 +620,11 @@ func WordSqueeze(rept LeadParam, count int) bool {
 		}
 
 		count -= 1
-		if !MarkCreate(CurrentFrame.Dot.Line.FLink, CurrentFrame.MarginLeft, &CurrentFrame.Dot) {
+		if !MarkCreate(e.CurrentFrame.Dot.Line.FLink, e.CurrentFrame.MarginLeft, &e.CurrentFrame.Dot) {
 			goto cleanup
 		}
-		CurrentFrame.TextModified = true
-		if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &CurrentFrame.Marks[MarkModified]) {
+		e.CurrentFrame.TextModified = true
+		if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &e.CurrentFrame.Marks[MarkModified]) {
 			goto cleanup
 		}
 	}
@@ -640,7 +640,7 @@ cleanup:
 }
 
 // WordRight right-aligns text
-func WordRight(rept LeadParam, count int) bool {
+func (e *Editor) WordRight(rept LeadParam, count int) bool {
 	var startChar int
 	var lineCount int
 	var spaceToAdd int
@@ -660,7 +660,7 @@ func WordRight(rept LeadParam, count int) bool {
 	}
 	if rept == LeadParamPInt {
 		lineCount = count
-		thisLine = CurrentFrame.Dot.Line
+		thisLine = e.CurrentFrame.Dot.Line
 		for (lineCount > 0) && (thisLine.Used > 0) {
 			thisLine = thisLine.FLink
 			lineCount--
@@ -672,40 +672,40 @@ func WordRight(rept LeadParam, count int) bool {
 			goto cleanup
 		}
 	}
-	for (count > 0) && (CurrentFrame.Dot.Line.Used > 0) {
-		if CurrentFrame.Dot.Line.FLink == nil {
+	for (count > 0) && (e.CurrentFrame.Dot.Line.Used > 0) {
+		if e.CurrentFrame.Dot.Line.FLink == nil {
 			goto cleanup
 		}
-		if (CurrentFrame.Dot.Line.Used < CurrentFrame.MarginLeft) ||
-			(CurrentFrame.Dot.Line.Used > CurrentFrame.MarginRight) {
+		if (e.CurrentFrame.Dot.Line.Used < e.CurrentFrame.MarginLeft) ||
+			(e.CurrentFrame.Dot.Line.Used > e.CurrentFrame.MarginRight) {
 			goto cleanup
 		}
 		startChar = 1
-		for CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
+		for e.CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
 			startChar++
 		}
-		if startChar < CurrentFrame.MarginLeft {
+		if startChar < e.CurrentFrame.MarginLeft {
 			goto cleanup
 		}
-		spaceToAdd = CurrentFrame.MarginRight - CurrentFrame.Dot.Line.Used
+		spaceToAdd = e.CurrentFrame.MarginRight - e.CurrentFrame.Dot.Line.Used
 		if spaceToAdd > 0 {
-			if !MarkCreate(CurrentFrame.Dot.Line, startChar, &here) {
+			if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &here) {
 				goto cleanup
 			}
-			if !TextInsert(true, 1, BlankString, spaceToAdd, here) {
+			if !e.TextInsert(true, 1, NewBlankStrObject(spaceToAdd), spaceToAdd, here) {
 				goto cleanup
 			}
 			if !MarkDestroy(&here) {
 				goto cleanup
 			}
 		} else if spaceToAdd < 0 {
-			if !MarkCreate(CurrentFrame.Dot.Line, startChar, &there) {
+			if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &there) {
 				goto cleanup
 			}
-			if !MarkCreate(CurrentFrame.Dot.Line, startChar-spaceToAdd, &here) {
+			if !MarkCreate(e.CurrentFrame.Dot.Line, startChar-spaceToAdd, &here) {
 				goto cleanup
 			}
-			if !TextRemove(there, here) {
+			if !e.TextRemove(there, here) {
 				goto cleanup
 			}
 			if !MarkDestroy(&here) {
@@ -717,15 +717,15 @@ func WordRight(rept LeadParam, count int) bool {
 		}
 		count--
 		if !MarkCreate(
-			CurrentFrame.Dot.Line.FLink, CurrentFrame.MarginLeft, &CurrentFrame.Dot,
+			e.CurrentFrame.Dot.Line.FLink, e.CurrentFrame.MarginLeft, &e.CurrentFrame.Dot,
 		) {
 			goto cleanup
 		}
-		CurrentFrame.TextModified = true
+		e.CurrentFrame.TextModified = true
 		if !MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkModified],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkModified],
 		) {
 			goto cleanup
 		}
@@ -742,7 +742,7 @@ cleanup:
 }
 
 // WordLeft left-aligns text
-func WordLeft(rept LeadParam, count int) bool {
+func (e *Editor) WordLeft(rept LeadParam, count int) bool {
 	var startChar int
 	var lineCount int
 	var thisLine *LineHdrObject
@@ -761,7 +761,7 @@ func WordLeft(rept LeadParam, count int) bool {
 	}
 	if rept == LeadParamPInt {
 		lineCount = count
-		thisLine = CurrentFrame.Dot.Line
+		thisLine = e.CurrentFrame.Dot.Line
 		for (lineCount > 0) && (thisLine.Used > 0) {
 			thisLine = thisLine.FLink
 			lineCount--
@@ -773,25 +773,25 @@ func WordLeft(rept LeadParam, count int) bool {
 			goto cleanup
 		}
 	}
-	for (count > 0) && (CurrentFrame.Dot.Line.Used > 0) {
-		if CurrentFrame.Dot.Line.FLink == nil {
+	for (count > 0) && (e.CurrentFrame.Dot.Line.Used > 0) {
+		if e.CurrentFrame.Dot.Line.FLink == nil {
 			goto cleanup
 		}
-		if (CurrentFrame.Dot.Line.Used < CurrentFrame.MarginLeft) ||
-			(CurrentFrame.Dot.Line.Used > CurrentFrame.MarginRight) {
+		if (e.CurrentFrame.Dot.Line.Used < e.CurrentFrame.MarginLeft) ||
+			(e.CurrentFrame.Dot.Line.Used > e.CurrentFrame.MarginRight) {
 			goto cleanup
 		}
 		startChar = 1
-		for CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
+		for e.CurrentFrame.Dot.Line.Str.Get(startChar) == ' ' {
 			startChar++
 		}
-		if startChar != CurrentFrame.MarginLeft {
-			if startChar < CurrentFrame.MarginLeft {
-				if !MarkCreate(CurrentFrame.Dot.Line, startChar, &here) {
+		if startChar != e.CurrentFrame.MarginLeft {
+			if startChar < e.CurrentFrame.MarginLeft {
+				if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &here) {
 					goto cleanup
 				}
-				if !TextInsert(
-					true, 1, BlankString, CurrentFrame.MarginLeft-startChar, here,
+				if !e.TextInsert(
+					true, 1, NewBlankStrObject(e.CurrentFrame.MarginLeft-startChar), e.CurrentFrame.MarginLeft-startChar, here,
 				) {
 					goto cleanup
 				}
@@ -799,13 +799,13 @@ func WordLeft(rept LeadParam, count int) bool {
 					goto cleanup
 				}
 			} else {
-				if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.MarginLeft, &here) {
+				if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.MarginLeft, &here) {
 					goto cleanup
 				}
-				if !MarkCreate(CurrentFrame.Dot.Line, startChar, &there) {
+				if !MarkCreate(e.CurrentFrame.Dot.Line, startChar, &there) {
 					goto cleanup
 				}
-				if !TextRemove(here, there) {
+				if !e.TextRemove(here, there) {
 					goto cleanup
 				}
 				if !MarkDestroy(&here) {
@@ -818,15 +818,15 @@ func WordLeft(rept LeadParam, count int) bool {
 		}
 		count--
 		if !MarkCreate(
-			CurrentFrame.Dot.Line.FLink, CurrentFrame.MarginLeft, &CurrentFrame.Dot,
+			e.CurrentFrame.Dot.Line.FLink, e.CurrentFrame.MarginLeft, &e.CurrentFrame.Dot,
 		) {
 			goto cleanup
 		}
-		CurrentFrame.TextModified = true
+		e.CurrentFrame.TextModified = true
 		if !MarkCreate(
-			CurrentFrame.Dot.Line,
-			CurrentFrame.Dot.Col,
-			&CurrentFrame.Marks[MarkModified],
+			e.CurrentFrame.Dot.Line,
+			e.CurrentFrame.Dot.Col,
+			&e.CurrentFrame.Marks[MarkModified],
 		) {
 			goto cleanup
 		}
@@ -843,24 +843,24 @@ cleanup:
 }
 
 // WordAdvanceWord advances cursor to start of a word
-func WordAdvanceWord(rept LeadParam, count int) bool {
+func (e *Editor) WordAdvanceWord(rept LeadParam, count int) bool {
 	var thisLine *LineHdrObject
 	var pos int
 
 	result := false
 	if rept == LeadParamMarker {
-		ScreenMessage(MsgSyntaxError)
+		e.ScreenMessage(MsgSyntaxError)
 		goto cleanup
 	}
-	pos = CurrentFrame.Dot.Col
-	thisLine = CurrentFrame.Dot.Line
+	pos = e.CurrentFrame.Dot.Col
+	thisLine = e.CurrentFrame.Dot.Line
 	if rept == LeadParamNone || rept == LeadParamPlus || rept == LeadParamPIndef ||
 		((rept == LeadParamPInt) && (count != 0)) {
 		// Handle PINDEF case
 		if rept == LeadParamPIndef {
 			// Get to blank line between paragraphs
 			for (thisLine.Used != 0) && (thisLine.FLink != nil) {
-				thisLine = thisLine.FLink
+				thisLine = e.FileNextLine(thisLine)
 			}
 			pos = 1
 			count = 1
@@ -890,7 +890,7 @@ func WordAdvanceWord(rept LeadParam, count int) bool {
 						}
 						goto cleanup
 					}
-					thisLine = thisLine.FLink
+					thisLine = e.FileNextLine(thisLine)
 					if !(thisLine.Used <= 0) {
 						break
 					}
@@ -902,7 +902,7 @@ func WordAdvanceWord(rept LeadParam, count int) bool {
 			count--
 		}
 	success:
-		if !MarkCreate(thisLine, pos, &CurrentFrame.Dot) {
+		if !MarkCreate(thisLine, pos, &e.CurrentFrame.Dot) {
 			goto cleanup
 		}
 		result = true
@@ -926,7 +926,7 @@ func WordAdvanceWord(rept LeadParam, count int) bool {
 		for thisLine.Str.Get(pos) == ' ' {
 			pos++
 		}
-		if !MarkCreate(thisLine, pos, &CurrentFrame.Dot) {
+		if !MarkCreate(thisLine, pos, &e.CurrentFrame.Dot) {
 			goto cleanup
 		}
 		result = true
@@ -982,7 +982,7 @@ func WordAdvanceWord(rept LeadParam, count int) bool {
 				break
 			}
 		}
-		if !MarkCreate(thisLine, pos, &CurrentFrame.Dot) {
+		if !MarkCreate(thisLine, pos, &e.CurrentFrame.Dot) {
 			goto cleanup
 		}
 		result = true
@@ -992,7 +992,7 @@ cleanup:
 }
 
 // WordDeleteWord deletes words at cursor
-func WordDeleteWord(rept LeadParam, count int) bool {
+func (e *Editor) WordDeleteWord(rept LeadParam, count int) bool {
 	var oldPos *MarkObject
 	var here *MarkObject
 	var anotherMark *MarkObject
@@ -1006,63 +1006,63 @@ func WordDeleteWord(rept LeadParam, count int) bool {
 	here = nil
 	theOtherMark = nil
 	if rept == LeadParamMarker {
-		ScreenMessage(MsgSyntaxError)
+		e.ScreenMessage(MsgSyntaxError)
 		goto cleanup
 	}
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &oldPos) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &oldPos) {
 		goto cleanup
 	}
 	// Get to beginning of word if in middle
-	if !WordAdvanceWord(LeadParamPInt, 0) {
+	if !e.WordAdvanceWord(LeadParamPInt, 0) {
 		goto cleanup
 	}
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &here) {
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &here) {
 		goto cleanup
 	}
-	if !WordAdvanceWord(rept, count) {
+	if !e.WordAdvanceWord(rept, count) {
 		// Put dot back and bail out
-		if !MarkCreate(oldPos.Line, oldPos.Col, &CurrentFrame.Dot) {
+		if !MarkCreate(oldPos.Line, oldPos.Col, &e.CurrentFrame.Dot) {
 			goto cleanup
 		}
 		goto cleanup
 	}
 	// Wipe out everything from dot to here
-	oldDotCol = CurrentFrame.Dot.Col
-	if !MarkCreate(CurrentFrame.Dot.Line, CurrentFrame.Dot.Col, &theOtherMark) {
+	oldDotCol = e.CurrentFrame.Dot.Col
+	if !MarkCreate(e.CurrentFrame.Dot.Line, e.CurrentFrame.Dot.Col, &theOtherMark) {
 		goto cleanup
 	}
-	if !LineToNumber(CurrentFrame.Dot.Line, &lineNr) {
+	if !LineToNumber(e.CurrentFrame.Dot.Line, &lineNr) {
 		goto cleanup
 	}
 	if !LineToNumber(here.Line, &newLineNr) {
 		goto cleanup
 	}
 	if (lineNr > newLineNr) ||
-		((lineNr == newLineNr) && (CurrentFrame.Dot.Col > here.Col)) {
+		((lineNr == newLineNr) && (e.CurrentFrame.Dot.Col > here.Col)) {
 		// Reverse mark pointers
 		anotherMark = here
 		here = theOtherMark
 		theOtherMark = anotherMark
 	}
-	if CurrentFrame != FrameOops {
+	if e.CurrentFrame != e.FrameOops {
 		// Make sure oops_span is okay
-		if !MarkCreate(FrameOops.LastGroup.LastLine, 1, &FrameOops.Span.MarkTwo) {
+		if !MarkCreate(e.FrameOops.LastGroup.LastLine, 1, &e.FrameOops.Span.MarkTwo) {
 			goto cleanup
 		}
-		result = TextMove(
+		result = e.TextMove(
 			false,
 			1,
 			theOtherMark,
 			here,
-			FrameOops.Span.MarkTwo,
-			&FrameOops.Marks[MarkEquals],
-			&FrameOops.Dot,
+			e.FrameOops.Span.MarkTwo,
+			&e.FrameOops.Marks[MarkEquals],
+			&e.FrameOops.Dot,
 		)
 	} else {
-		result = TextRemove(theOtherMark, here)
+		result = e.TextRemove(theOtherMark, here)
 	}
 	if lineNr != newLineNr {
-		result = TextSplitLine(CurrentFrame.Dot, oldDotCol, &here)
+		result = e.TextSplitLine(e.CurrentFrame.Dot, oldDotCol, &here)
 	}
 cleanup:
 	if oldPos != nil {
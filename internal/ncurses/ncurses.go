@@ -2,9 +2,15 @@
 package ncurses
 
 /*
-#cgo LDFLAGS: -lncurses
+#cgo CFLAGS: -D_XOPEN_SOURCE_EXTENDED -DNCURSES_WIDECHAR=1
+#cgo LDFLAGS: -lncursesw
+#include <locale.h>
 #include <ncurses.h>
 #include <stdlib.h>
+#include <sys/ioctl.h>
+#include <termios.h>
+#include <unistd.h>
+#include <wchar.h>
 
 // Helper function to get KEY_MAX
 static int get_key_max() {
@@ -19,6 +25,63 @@ static void get_yx(WINDOW *win, int *y, int *x) {
 static void get_maxyx(WINDOW *win, int *y, int *x) {
 	getmaxyx(win, *y, *x);
 }
+
+// Helper functions for wide characters
+static int add_rune(WINDOW *win, int ch) {
+	wchar_t wstr[2] = { (wchar_t)ch, 0 };
+	return waddnwstr(win, wstr, 1);
+}
+
+static int ins_rune(WINDOW *win, int ch) {
+	wchar_t wstr[2] = { (wchar_t)ch, 0 };
+	return wins_nwstr(win, wstr, 1);
+}
+
+// Let the interrupt character raise SIGINT in raw mode, the quit and
+// suspend characters are still read as keys
+static void raw_interrupt(void) {
+	struct termios t;
+	if (tcgetattr(STDIN_FILENO, &t) != 0) {
+		return;
+	}
+	t.c_lflag |= ISIG;
+	t.c_cc[VQUIT] = _POSIX_VDISABLE;
+	t.c_cc[VSUSP] = _POSIX_VDISABLE;
+#ifdef VDSUSP
+	t.c_cc[VDSUSP] = _POSIX_VDISABLE;
+#endif
+	tcsetattr(STDIN_FILENO, TCSADRAIN, &t);
+	def_prog_mode();
+}
+
+// Fit the screen to the size the terminal is now
+static void update_size(void) {
+	struct winsize ws;
+	if (ioctl(STDOUT_FILENO, TIOCGWINSZ, &ws) == 0 && ws.ws_row > 0 && ws.ws_col > 0) {
+		// resizeterm would queue KEY_RESIZE, the caller already knows
+		resize_term(ws.ws_row, ws.ws_col);
+		clearok(curscr, TRUE);
+	}
+}
+
+static void set_attrs(WINDOW *win, int attrs) {
+	wattrset(win, attrs);
+}
+
+static int colour_pair(int pair) {
+	return COLOR_PAIR(pair);
+}
+
+static int get_rune(WINDOW *win, int *is_key) {
+	wint_t ch;
+	int rc = wget_wch(win, &ch);
+	if (rc == ERR) {
+		*is_key = 1;
+		return ERR;
+	}
+	*is_key = rc == KEY_CODE_YES;
+	return (int)ch;
+}
 */
 import "C"
 import (
@@ -43,8 +106,22 @@ const (
 	A_REVERSE int = C.A_REVERSE
 )
 
+// Colour constants
+const (
+	COLOR_DEFAULT int = -1
+	COLOR_BLACK   int = C.COLOR_BLACK
+	COLOR_RED     int = C.COLOR_RED
+	COLOR_GREEN   int = C.COLOR_GREEN
+	COLOR_YELLOW  int = C.COLOR_YELLOW
+	COLOR_BLUE    int = C.COLOR_BLUE
+	COLOR_MAGENTA int = C.COLOR_MAGENTA
+	COLOR_CYAN    int = C.COLOR_CYAN
+	COLOR_WHITE   int = C.COLOR_WHITE
+)
+
 // Key constants
 const (
+	ERR           = C.ERR
 	KEY_BACKSPACE = C.KEY_BACKSPACE
 	KEY_RESIZE    = C.KEY_RESIZE
 	KEY_DOWN      = C.KEY_DOWN
@@ -71,6 +148,9 @@ var (
 
 // Init initializes ncurses and returns the standard screen window
 func Init() (*Window, error) {
+	empty := C.CString("")
+	C.setlocale(C.LC_ALL, empty)
+	C.free(unsafe.Pointer(empty))
 	cwin := C.initscr()
 	if cwin == nil {
 		return nil, ErrInitFailed
@@ -94,6 +174,25 @@ func Raw(enable bool) {
 	}
 }
 
+// RawInterrupt lets the interrupt character raise SIGINT in raw mode, the
+// other characters that raise signals are still read as keys
+func RawInterrupt() {
+	C.raw_interrupt()
+}
+
+// UpdateSize fits the screen to the size the terminal is now
+func UpdateSize() {
+	C.update_size()
+}
+
+// Resume puts the terminal back in the state it was in before End, and
+// redraws the whole screen
+func Resume() {
+	C.reset_prog_mode()
+	C.clearok(C.curscr, C.bool(true))
+	C.wrefresh(C.curscr)
+}
+
 // Echo sets echo mode
 func Echo(enable bool) {
 	if enable {
@@ -127,6 +226,28 @@ func UnGetChar(ch Char) {
 	C.ungetch(C.int(ch))
 }
 
+// HasColors returns true if the terminal can show colours
+func HasColors() bool {
+	return bool(C.has_colors())
+}
+
+// StartColor sets up colour support, the terminal's own colours can then
+// be used as COLOR_DEFAULT
+func StartColor() {
+	C.start_color()
+	C.use_default_colors()
+}
+
+// InitPair defines a colour pair
+func InitPair(pair, fg, bg int) {
+	C.init_pair(C.short(pair), C.short(fg), C.short(bg))
+}
+
+// ColorPair returns the attribute that draws in a colour pair
+func ColorPair(pair int) int {
+	return int(C.colour_pair(C.int(pair)))
+}
+
 // ErrInitFailed is returned when ncurses initialization fails
 type InitError struct{}
 
@@ -148,6 +269,27 @@ func (w *Window) Refresh() {
 	C.wrefresh(w.win)
 }
 
+// NoutRefresh copies the window to the virtual screen without updating
+// the terminal
+func (w *Window) NoutRefresh() {
+	C.wnoutrefresh(w.win)
+}
+
+// DerWin creates a window for an area of this window, the two windows
+// share the text in the area.  Returns nil if the area does not fit.
+func (w *Window) DerWin(lines, cols, y, x int) *Window {
+	cwin := C.derwin(w.win, C.int(lines), C.int(cols), C.int(y), C.int(x))
+	if cwin == nil {
+		return nil
+	}
+	return &Window{win: cwin}
+}
+
+// Delete deletes a window created by DerWin
+func (w *Window) Delete() {
+	C.delwin(w.win)
+}
+
 // ClearToEOL clears from cursor to end of line
 func (w *Window) ClearToEOL() {
 	C.wclrtoeol(w.win)
@@ -162,7 +304,11 @@ func (w *Window) Print(str string) {
 
 // AddChar adds a character at the cursor position
 func (w *Window) AddChar(ch Char) {
-	C.waddch(w.win, C.chtype(ch))
+	if ch < 0x80 {
+		C.waddch(w.win, C.chtype(ch))
+	} else {
+		C.add_rune(w.win, C.int(ch))
+	}
 }
 
 // Clear clears the entire window
@@ -196,7 +342,11 @@ func (w *Window) InsDelLines(n int) {
 
 // InsChar inserts a character at cursor position
 func (w *Window) InsChar(ch Char) {
-	C.winsch(w.win, C.chtype(ch))
+	if ch < 0x80 {
+		C.winsch(w.win, C.chtype(ch))
+	} else {
+		C.ins_rune(w.win, C.int(ch))
+	}
 }
 
 // DelChar deletes character at cursor position
@@ -224,6 +374,20 @@ func (w *Window) GetChar() Key {
 	return Key(ch)
 }
 
+// GetWideChar gets a character from the window, the result is true if it
+// is a function key code rather than a character
+func (w *Window) GetWideChar() (Key, bool) {
+	var isKey C.int
+	ch := C.get_rune(w.win, &isKey)
+	return Key(ch), isKey != 0
+}
+
+// Timeout sets how long reading a character waits, in milliseconds.  A
+// negative delay waits for ever.  ERR is read if no character arrives.
+func (w *Window) Timeout(delay int) {
+	C.wtimeout(w.win, C.int(delay))
+}
+
 // Keypad enables or disables keypad mode
 func (w *Window) Keypad(enable bool) {
 	if enable {
@@ -238,6 +402,11 @@ func (w *Window) AttrOn(attr int) {
 	C.wattron(w.win, C.int(attr))
 }
 
+// AttrSet sets the attributes, turning off any others
+func (w *Window) AttrSet(attr int) {
+	C.set_attrs(w.win, C.int(attr))
+}
+
 // AttrOff turns off the specified attributes
 func (w *Window) AttrOff(attr int) {
 	C.wattroff(w.win, C.int(attr))
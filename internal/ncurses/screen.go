@@ -0,0 +1,237 @@
+//go:build cgo
+
+package ncurses
+
+import (
+	"errors"
+	"time"
+
+	"ludwig-go/internal/terminal"
+)
+
+// errGetKey is returned by GetKey if reading a key fails
+var errGetKey = errors.New("can't read a key")
+
+// Screen is a terminal.Screen drawn with ncurses
+type Screen struct {
+	stdscr  *Window
+	win     *Window // The area of the screen being drawn on
+	colours bool
+	attr    int // The attributes of the characters being drawn
+}
+
+// NewScreen makes a screen on the terminal, it is set up by Init
+func NewScreen() *Screen {
+	return &Screen{}
+}
+
+// setup sets the modes that every window of the screen needs
+func setup(win *Window) {
+	win.IntrFlush(false)
+	win.Keypad(true)
+	win.Idlok(true)
+	win.Idcok(true)
+	win.ScrollOk(false)
+}
+
+// Init takes over the terminal
+func (s *Screen) Init() error {
+	stdscr, err := Init()
+	if err != nil {
+		return err
+	}
+	Raw(true)
+	RawInterrupt()
+	Echo(false)
+	NewLines(false)
+	CursSet(0)
+	setup(stdscr)
+	s.stdscr = stdscr
+	s.win = stdscr
+
+	// Each colour has a colour pair of its own, numbered one more than it
+	if HasColors() {
+		StartColor()
+		for colour := terminal.ColourBlack; colour <= terminal.ColourWhite; colour++ {
+			InitPair(int(colour)+1, int(colour), COLOR_DEFAULT)
+		}
+		s.colours = true
+	}
+	return nil
+}
+
+// End gives the terminal back
+func (s *Screen) End() {
+	End()
+}
+
+// Suspend gives the terminal back to the shell for a while
+func (s *Screen) Suspend() {
+	maxY, _ := s.stdscr.MaxYX()
+	s.stdscr.Move(maxY-1, 0)
+	s.stdscr.Refresh()
+	End()
+}
+
+// Resume takes the terminal back after Suspend and redraws it
+func (s *Screen) Resume() {
+	Resume()
+	s.win.Refresh()
+}
+
+// UpdateSize fits the screen to the size the terminal is now
+func (s *Screen) UpdateSize() {
+	s.SetArea(0, 0, 0, 0)
+	UpdateSize()
+}
+
+// Size returns the size of the whole screen
+func (s *Screen) Size() (int, int) {
+	return s.stdscr.MaxYX()
+}
+
+// SetArea confines drawing to an area of the screen, with a window that
+// shares the text of the area
+func (s *Screen) SetArea(y, x, rows, cols int) {
+	if s.win != s.stdscr {
+		// Keep what was drawn in the old area
+		s.win.NoutRefresh()
+		s.win.Delete()
+		s.win = s.stdscr
+	}
+	maxY, maxX := s.stdscr.MaxYX()
+	if rows <= 0 || cols <= 0 || (rows == maxY && cols == maxX) {
+		return
+	}
+	area := s.stdscr.DerWin(rows, cols, y, x)
+	if area == nil {
+		return
+	}
+	setup(area)
+	area.AttrSet(s.attr)
+	s.win = area
+}
+
+// AreaSize returns the size of the area
+func (s *Screen) AreaSize() (int, int) {
+	return s.win.MaxYX()
+}
+
+// Move moves the cursor
+func (s *Screen) Move(y, x int) {
+	s.win.Move(y, x)
+}
+
+// Cursor returns the position of the cursor
+func (s *Screen) Cursor() (int, int) {
+	return s.win.CursorYX()
+}
+
+// Print draws a string at the cursor
+func (s *Screen) Print(str string) {
+	s.win.Print(str)
+}
+
+// AddChar draws a character at the cursor
+func (s *Screen) AddChar(ch rune) {
+	s.win.AddChar(Char(ch))
+}
+
+// InsChar inserts a character at the cursor
+func (s *Screen) InsChar(ch rune) {
+	s.win.InsChar(Char(ch))
+}
+
+// DelChar deletes the character at the cursor
+func (s *Screen) DelChar() {
+	s.win.DelChar()
+}
+
+// ClearToEOL clears from the cursor to the end of the line
+func (s *Screen) ClearToEOL() {
+	s.win.ClearToEOL()
+}
+
+// ClearToBottom clears from the cursor to the bottom of the area
+func (s *Screen) ClearToBottom() {
+	s.win.ClearToBottom()
+}
+
+// Clear clears the area
+func (s *Screen) Clear() {
+	s.win.Clear()
+}
+
+// Scroll scrolls the area up n lines, or down if n is negative
+func (s *Screen) Scroll(n int) {
+	s.win.ScrollOk(true)
+	s.win.Scroll(n)
+	s.win.ScrollOk(false)
+}
+
+// InsDelLines inserts or deletes lines at the cursor
+func (s *Screen) InsDelLines(n int) {
+	s.win.InsDelLines(n)
+}
+
+// SetStyle sets how the characters drawn from now on look
+func (s *Screen) SetStyle(attr terminal.Attr, colour terminal.Colour) {
+	s.attr = 0
+	if attr&terminal.AttrBold != 0 {
+		s.attr |= A_BOLD
+	}
+	if attr&terminal.AttrDim != 0 {
+		s.attr |= A_DIM
+	}
+	if attr&terminal.AttrReverse != 0 {
+		s.attr |= A_REVERSE
+	}
+	if colour != terminal.ColourDefault && s.colours {
+		s.attr |= ColorPair(int(colour) + 1)
+	}
+	s.win.AttrSet(s.attr)
+}
+
+// HasColours returns true if the terminal can show colours
+func (s *Screen) HasColours() bool {
+	return s.colours
+}
+
+// ShowCursor shows or hides the cursor
+func (s *Screen) ShowCursor(visible bool) {
+	if visible {
+		CursSet(1)
+	} else {
+		CursSet(0)
+	}
+}
+
+// Refresh makes the terminal show what has been drawn
+func (s *Screen) Refresh() {
+	s.win.Refresh()
+}
+
+// Beep flashes the screen
+func (s *Screen) Beep() {
+	Flash()
+}
+
+// GetKey reads a key from the terminal
+func (s *Screen) GetKey(timeout time.Duration) (int, bool, error) {
+	delay := -1
+	if timeout >= 0 {
+		delay = int((timeout + time.Millisecond - 1) / time.Millisecond)
+	}
+	s.win.Timeout(delay)
+	start := time.Now()
+	key, isKey := s.win.GetWideChar()
+	if isKey && key == ERR {
+		// Curses gives an error for a timeout as well, an error straight
+		// away is a real one
+		if timeout >= 0 && time.Since(start) >= timeout/2 {
+			return 0, false, terminal.ErrTimeout
+		}
+		return 0, false, errGetKey
+	}
+	return int(key), isKey, nil
+}
@@ -0,0 +1,354 @@
+package terminal
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// escDelayEnv names the variable giving how long to wait, in milliseconds,
+// for the rest of a key sequence after an escape.  It is the one curses
+// uses.
+const escDelayEnv = "ESCDELAY"
+
+// defaultEscDelay is how long to wait for the rest of a key sequence
+const defaultEscDelay = time.Second
+
+// ansiStrings are used for a terminal without a terminfo entry
+var ansiStrings = map[int]string{
+	tiBell:         "\a",
+	tiCursorHidden: "\x1b[?25l",
+	tiCursorNormal: "\x1b[?25h",
+	tiEnterCA:      "\x1b[?1049h",
+	tiExitCA:       "\x1b[?1049l",
+}
+
+// ANSI is a screen on a terminal that understands the ANSI escape
+// sequences, as nearly all terminals do.  The terminal's terminfo entry is
+// used for the sequences its keys send, and for a few other things, if it
+// can be found.
+type ANSI struct {
+	*cells
+	in       *os.File
+	outFile  *os.File
+	out      *bufio.Writer
+	info     *terminfo
+	keys     *keyMap
+	escDelay time.Duration
+	saved    unix.Termios // The terminal's modes before Init
+	raw      unix.Termios // The terminal's modes while in use
+	active   bool
+
+	shown      [][]cell // What the terminal shows
+	curY       int      // Where the terminal's cursor is
+	curX       int
+	curKnown   bool
+	curAttr    Attr // How the terminal draws characters
+	curColour  Colour
+	styleKnown bool
+	cursorOn   bool // Whether the cursor should be seen
+	cursorSet  bool // Whether the terminal knows whether to show it
+	pending    []byte
+}
+
+// NewANSI makes a screen on the terminal that in and out are connected to
+func NewANSI(in, out *os.File) *ANSI {
+	return &ANSI{
+		cells:    newCells(24, 80),
+		in:       in,
+		outFile:  out,
+		out:      bufio.NewWriterSize(out, 8192),
+		escDelay: defaultEscDelay,
+	}
+}
+
+// outFd returns the file descriptor the terminal is written to
+func (a *ANSI) outFd() int {
+	return int(a.outFile.Fd())
+}
+
+// Init takes over the terminal
+func (a *ANSI) Init() error {
+	fd := int(a.in.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(a.outFd()) {
+		return ErrNoTerminal
+	}
+	modes, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return err
+	}
+	a.saved = *modes
+	a.raw = *modes
+	a.raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR |
+		unix.IGNCR | unix.ICRNL | unix.IXON
+	a.raw.Oflag &^= unix.OPOST
+	a.raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.IEXTEN
+	a.raw.Cflag &^= unix.CSIZE | unix.PARENB
+	a.raw.Cflag |= unix.CS8
+	a.raw.Cc[unix.VMIN] = 1
+	a.raw.Cc[unix.VTIME] = 0
+	// The interrupt character still raises SIGINT, the others are keys
+	a.raw.Lflag |= unix.ISIG
+	disableSignalChars(&a.raw)
+
+	a.info, err = loadTerminfo(os.Getenv("TERM"))
+	if err != nil {
+		a.info = &terminfo{colours: 8, strings: ansiStrings}
+	}
+	a.keys = newKeyMap(a.info)
+	if ms, err := strconv.Atoi(os.Getenv(escDelayEnv)); err == nil && ms >= 0 {
+		a.escDelay = time.Duration(ms) * time.Millisecond
+	}
+	a.UpdateSize()
+	return a.enter()
+}
+
+// enter puts the terminal into the modes the screen needs
+func (a *ANSI) enter() error {
+	if err := unix.IoctlSetTermios(int(a.in.Fd()), ioctlSetTermios, &a.raw); err != nil {
+		return err
+	}
+	a.active = true
+	a.put(a.info.strings[tiEnterCA])
+	a.put(a.info.strings[tiKeypadXmit])
+	a.cleared = true
+	a.curKnown = false
+	a.styleKnown = false
+	a.cursorSet = false
+	return nil
+}
+
+// leave puts the terminal back the way it was, with the cursor at the
+// bottom of the screen
+func (a *ANSI) leave() {
+	if !a.active {
+		return
+	}
+	a.moveTo(a.rows-1, 0)
+	a.out.WriteString("\x1b[0m")
+	a.put(a.info.strings[tiCursorNormal])
+	a.put(a.info.strings[tiKeypadLocal])
+	a.put(a.info.strings[tiExitCA])
+	a.out.Flush()
+	unix.IoctlSetTermios(int(a.in.Fd()), ioctlSetTermios, &a.saved)
+	a.active = false
+}
+
+// End gives the terminal back
+func (a *ANSI) End() {
+	a.leave()
+}
+
+// Suspend gives the terminal back to the shell for a while
+func (a *ANSI) Suspend() {
+	a.leave()
+}
+
+// Resume takes the terminal back after Suspend and redraws it
+func (a *ANSI) Resume() {
+	if a.enter() == nil {
+		a.Refresh()
+	}
+}
+
+// UpdateSize fits the screen to the size the terminal is now
+func (a *ANSI) UpdateSize() {
+	cols, rows, err := term.GetSize(a.outFd())
+	if err != nil || rows <= 0 || cols <= 0 {
+		return
+	}
+	a.resize(rows, cols)
+}
+
+// HasColours returns true if the terminal can show colours
+func (a *ANSI) HasColours() bool {
+	return a.info != nil && a.info.colours >= 8
+}
+
+// ShowCursor shows or hides the cursor
+func (a *ANSI) ShowCursor(visible bool) {
+	if visible != a.cursorOn {
+		a.cursorOn = visible
+		a.cursorSet = false
+	}
+}
+
+// put writes a terminfo string to the terminal, any delays in it are
+// waited for
+func (a *ANSI) put(str string) {
+	for {
+		start := strings.Index(str, "$<")
+		end := strings.IndexByte(str[max(start, 0):], '>')
+		if start < 0 || end < 0 {
+			break
+		}
+		a.out.WriteString(str[:start])
+		delay := strings.TrimRight(str[start+2:start+end], "*/")
+		if ms, err := strconv.ParseFloat(delay, 64); err == nil {
+			a.out.Flush()
+			time.Sleep(time.Duration(ms * float64(time.Millisecond)))
+		}
+		str = str[start+end+1:]
+	}
+	a.out.WriteString(str)
+}
+
+// moveTo moves the terminal's cursor
+func (a *ANSI) moveTo(y, x int) {
+	if a.curKnown && y == a.curY && x == a.curX {
+		return
+	}
+	a.out.WriteString("\x1b[" + strconv.Itoa(y+1) + ";" + strconv.Itoa(x+1) + "H")
+	a.curY, a.curX, a.curKnown = y, x, true
+}
+
+// setStyle sets how the terminal draws characters
+func (a *ANSI) setStyle(attr Attr, colour Colour) {
+	if a.styleKnown && attr == a.curAttr && colour == a.curColour {
+		return
+	}
+	a.out.WriteString("\x1b[0")
+	if attr&AttrBold != 0 {
+		a.out.WriteString(";1")
+	}
+	if attr&AttrDim != 0 {
+		a.out.WriteString(";2")
+	}
+	if attr&AttrReverse != 0 {
+		a.out.WriteString(";7")
+	}
+	if colour != ColourDefault && a.HasColours() {
+		a.out.WriteString(";3" + strconv.Itoa(int(colour)))
+	}
+	a.out.WriteByte('m')
+	a.curAttr, a.curColour, a.styleKnown = attr, colour, true
+}
+
+// Refresh makes the terminal show what has been drawn, by drawing the
+// cells that differ from what it shows
+func (a *ANSI) Refresh() {
+	if !a.active {
+		return
+	}
+	if a.cleared || len(a.shown) != a.rows || len(a.shown[0]) != a.cols {
+		a.setStyle(AttrNormal, ColourDefault)
+		a.out.WriteString("\x1b[H\x1b[2J")
+		a.curY, a.curX, a.curKnown = 0, 0, true
+		a.shown = make([][]cell, a.rows)
+		for y := range a.shown {
+			a.shown[y] = make([]cell, a.cols)
+			for x := range a.shown[y] {
+				a.shown[y][x] = blank
+			}
+		}
+		a.cleared = false
+	}
+	for y, line := range a.lines {
+		shown := a.shown[y]
+		for x := 0; x < a.cols; {
+			c := line[x]
+			width := 1
+			if x+1 < a.cols && line[x+1].text == "" {
+				width = 2
+			}
+			if c.text == "" || (c == shown[x] && (width == 1 || line[x+1] == shown[x+1])) {
+				x += width
+				continue
+			}
+			a.moveTo(y, x)
+			a.setStyle(c.attr, c.colour)
+			a.out.WriteString(c.text)
+			copy(shown[x:x+width], line[x:x+width])
+			a.curX += width
+			if a.curX >= a.cols {
+				// Terminals differ about where the cursor goes now
+				a.curKnown = false
+			}
+			x += width
+		}
+	}
+	if !a.cursorSet {
+		if a.cursorOn {
+			a.put(a.info.strings[tiCursorNormal])
+		} else {
+			a.put(a.info.strings[tiCursorHidden])
+		}
+		a.cursorSet = true
+	}
+	a.moveTo(a.areaY+a.y, a.areaX+a.x)
+	a.out.Flush()
+}
+
+// Beep flashes the screen, or rings the bell if it cannot be flashed
+func (a *ANSI) Beep() {
+	if flash := a.info.strings[tiFlash]; flash != "" {
+		a.put(flash)
+	} else {
+		a.put(a.info.strings[tiBell])
+	}
+	a.out.Flush()
+}
+
+// GetKey reads a key from the terminal
+func (a *ANSI) GetKey(timeout time.Duration) (int, bool, error) {
+	start := time.Now()
+	for {
+		if key, isKey, n := a.keys.decode(a.pending, false); n > 0 {
+			a.pending = a.pending[n:]
+			return key, isKey, nil
+		}
+		wait := time.Duration(-1)
+		if len(a.pending) > 0 {
+			wait = a.escDelay
+		} else if timeout >= 0 {
+			wait = max(timeout-time.Since(start), 0)
+		}
+		err := a.read(wait)
+		if err == ErrTimeout && len(a.pending) > 0 {
+			// The rest of the key sequence is not coming
+			key, isKey, n := a.keys.decode(a.pending, true)
+			a.pending = a.pending[n:]
+			return key, isKey, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+	}
+}
+
+// read waits for bytes from the terminal and adds them to those pending
+func (a *ANSI) read(wait time.Duration) error {
+	fd := int(a.in.Fd())
+	ms := -1
+	if wait >= 0 {
+		ms = int((wait + time.Millisecond - 1) / time.Millisecond)
+	}
+	// A signal arriving cuts the wait short, as if it had timed out
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, ms)
+	if err == unix.EINTR || (err == nil && n == 0) {
+		return ErrTimeout
+	}
+	if err != nil {
+		return err
+	}
+	var buf [256]byte
+	n, err = unix.Read(fd, buf[:])
+	if err == unix.EINTR || err == unix.EAGAIN {
+		return ErrTimeout
+	}
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return io.EOF
+	}
+	a.pending = append(a.pending, buf[:n]...)
+	return nil
+}
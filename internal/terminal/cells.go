@@ -0,0 +1,325 @@
+package terminal
+
+import "unicode/utf8"
+
+// cell is one character cell of a screen.  A wide character takes two
+// cells, the second of which has no text.
+type cell struct {
+	text   string
+	attr   Attr
+	colour Colour
+}
+
+// blank is an empty cell
+var blank = cell{text: " ", colour: ColourDefault}
+
+// tabSize is the distance between tab stops
+const tabSize = 8
+
+// cells is a screen held in memory, it does the drawing for the screens
+// in this package
+type cells struct {
+	rows, cols int
+	lines      [][]cell
+
+	areaY, areaX       int // Top left corner of the area
+	areaRows, areaCols int // Size of the area
+	y, x               int // The cursor, in the area
+	savedY, savedX     int // The cursor of the whole screen, while in an area
+
+	attr    Attr
+	colour  Colour
+	cleared bool // The whole screen has been cleared since it was last shown
+}
+
+// newCells makes an empty screen
+func newCells(rows, cols int) *cells {
+	c := &cells{colour: ColourDefault}
+	c.resize(rows, cols)
+	c.cleared = false
+	return c
+}
+
+// resize changes the size of the screen, keeping what fits of the text.
+// Drawing goes back to the whole screen.
+func (c *cells) resize(rows, cols int) {
+	rows = max(rows, 1)
+	cols = max(cols, 1)
+	lines := make([][]cell, rows)
+	for y := range lines {
+		lines[y] = make([]cell, cols)
+		for x := range lines[y] {
+			lines[y][x] = blank
+			if y < c.rows && x < c.cols {
+				lines[y][x] = c.lines[y][x]
+			}
+		}
+		if lines[y][cols-1].text != "" && cols < c.cols && y < c.rows && c.lines[y][cols].text == "" {
+			lines[y][cols-1] = blank
+		}
+	}
+	if c.areaRows != c.rows || c.areaCols != c.cols {
+		c.y, c.x = c.savedY, c.savedX
+	}
+	c.rows, c.cols, c.lines = rows, cols, lines
+	c.areaY, c.areaX, c.areaRows, c.areaCols = 0, 0, rows, cols
+	c.y = min(c.y, rows-1)
+	c.x = min(c.x, cols-1)
+	c.cleared = true
+}
+
+// Size returns the size of the whole screen
+func (c *cells) Size() (int, int) {
+	return c.rows, c.cols
+}
+
+// SetArea confines drawing to an area of the screen
+func (c *cells) SetArea(y, x, rows, cols int) {
+	whole := c.areaRows == c.rows && c.areaCols == c.cols
+	if whole {
+		c.savedY, c.savedX = c.y, c.x
+	}
+	y = min(max(y, 0), c.rows-1)
+	x = min(max(x, 0), c.cols-1)
+	if rows <= 0 || cols <= 0 {
+		y, x, rows, cols = 0, 0, c.rows, c.cols
+	}
+	c.areaY, c.areaX = y, x
+	c.areaRows = min(rows, c.rows-y)
+	c.areaCols = min(cols, c.cols-x)
+	if c.areaRows == c.rows && c.areaCols == c.cols {
+		c.y, c.x = c.savedY, c.savedX
+	} else {
+		c.y, c.x = 0, 0
+	}
+}
+
+// AreaSize returns the size of the area
+func (c *cells) AreaSize() (int, int) {
+	return c.areaRows, c.areaCols
+}
+
+// Move moves the cursor
+func (c *cells) Move(y, x int) {
+	if y >= 0 && y < c.areaRows && x >= 0 && x < c.areaCols {
+		c.y, c.x = y, x
+	}
+}
+
+// Cursor returns the position of the cursor
+func (c *cells) Cursor() (int, int) {
+	return c.y, c.x
+}
+
+// SetStyle sets how the characters drawn from now on look
+func (c *cells) SetStyle(attr Attr, colour Colour) {
+	c.attr, c.colour = attr, colour
+}
+
+// line returns a line of the area
+func (c *cells) line(y int) []cell {
+	return c.lines[c.areaY+y][c.areaX : c.areaX+c.areaCols]
+}
+
+// unsplit blanks the rest of a wide character that a cell is part of,
+// before the cell is overwritten
+func unsplit(line []cell, x int) {
+	if line[x].text == "" {
+		if x > 0 {
+			line[x-1] = blank
+		}
+	} else if x+1 < len(line) && line[x+1].text == "" {
+		line[x+1] = blank
+	}
+}
+
+// newLine moves the cursor to the start of the next line, or leaves it
+// where it is at the bottom of the area
+func (c *cells) newLine() {
+	if c.y < c.areaRows-1 {
+		c.y++
+		c.x = 0
+	}
+}
+
+// Print draws a string at the cursor
+func (c *cells) Print(str string) {
+	for _, ch := range str {
+		c.AddChar(ch)
+	}
+}
+
+// AddChar draws a character at the cursor and moves past it
+func (c *cells) AddChar(ch rune) {
+	switch {
+	case ch == '\b':
+		if c.x > 0 {
+			c.x--
+		}
+		return
+	case ch == '\r':
+		c.x = 0
+		return
+	case ch == '\n':
+		c.ClearToEOL()
+		c.newLine()
+		return
+	case ch == '\t':
+		for stop := (c.x/tabSize + 1) * tabSize; c.x < stop && c.x < c.areaCols-1; {
+			c.AddChar(' ')
+		}
+		return
+	case ch < ' ' || ch == 0x7f:
+		c.AddChar('^')
+		c.AddChar(ch ^ 0x40)
+		return
+	case !utf8.ValidRune(ch):
+		ch = utf8.RuneError
+	}
+	line := c.line(c.y)
+	width := Width(ch)
+	if width == 0 {
+		// A combining mark goes on the character before the cursor
+		x := c.x - 1
+		if x > 0 && line[x].text == "" {
+			x--
+		}
+		if x >= 0 {
+			line[x].text += string(ch)
+		}
+		return
+	}
+	if width == 2 && c.x == c.areaCols-1 {
+		// Not enough room, it goes on the next line
+		unsplit(line, c.x)
+		line[c.x] = cell{text: " ", attr: c.attr, colour: c.colour}
+		if c.y == c.areaRows-1 {
+			return
+		}
+		c.newLine()
+		line = c.line(c.y)
+	}
+	unsplit(line, c.x)
+	if width == 2 {
+		unsplit(line, c.x+1)
+	}
+	line[c.x] = cell{text: string(ch), attr: c.attr, colour: c.colour}
+	if width == 2 {
+		line[c.x+1] = cell{attr: c.attr, colour: c.colour}
+	}
+	if c.x+width < c.areaCols {
+		c.x += width
+	} else if c.y < c.areaRows-1 {
+		c.newLine()
+	}
+}
+
+// InsChar inserts a character at the cursor
+func (c *cells) InsChar(ch rune) {
+	line := c.line(c.y)
+	width := max(Width(ch), 1)
+	if c.x+width > c.areaCols {
+		return
+	}
+	if line[c.x].text == "" {
+		// Inserting in the middle of a wide character loses it
+		if c.x > 0 {
+			line[c.x-1] = blank
+		}
+		line[c.x] = blank
+	}
+	copy(line[c.x+width:], line[c.x:])
+	// A wide character pushed half off the end of the line is lost
+	last := len(line) - 1
+	if ch, _ := utf8.DecodeRuneInString(line[last].text); Width(ch) == 2 {
+		line[last] = blank
+	}
+	x := c.x
+	c.AddChar(ch)
+	c.Move(c.y, x)
+}
+
+// DelChar deletes the character at the cursor
+func (c *cells) DelChar() {
+	line := c.line(c.y)
+	start, n := c.x, 1
+	if line[start].text == "" && start > 0 {
+		start--
+		n++
+	} else if start+1 < len(line) && line[start+1].text == "" {
+		n++
+	}
+	copy(line[start:], line[start+n:])
+	for x := len(line) - n; x < len(line); x++ {
+		line[x] = blank
+	}
+}
+
+// ClearToEOL clears from the cursor to the end of the line
+func (c *cells) ClearToEOL() {
+	line := c.line(c.y)
+	unsplit(line, c.x)
+	for x := c.x; x < len(line); x++ {
+		line[x] = blank
+	}
+}
+
+// ClearToBottom clears from the cursor to the bottom of the area
+func (c *cells) ClearToBottom() {
+	c.ClearToEOL()
+	for y := c.y + 1; y < c.areaRows; y++ {
+		c.clearLine(y)
+	}
+}
+
+// Clear clears the area
+func (c *cells) Clear() {
+	for y := range c.areaRows {
+		c.clearLine(y)
+	}
+	c.y, c.x = 0, 0
+	if c.areaRows == c.rows && c.areaCols == c.cols {
+		c.cleared = true
+	}
+}
+
+// clearLine clears a line of the area
+func (c *cells) clearLine(y int) {
+	line := c.line(y)
+	for x := range line {
+		line[x] = blank
+	}
+}
+
+// Scroll scrolls the area up n lines, or down if n is negative
+func (c *cells) Scroll(n int) {
+	c.shift(0, n)
+}
+
+// InsDelLines inserts n blank lines at the cursor, or deletes lines if n
+// is negative
+func (c *cells) InsDelLines(n int) {
+	c.shift(c.y, -n)
+}
+
+// shift moves the lines of the area from top down up n lines, or down if
+// n is negative, blanking the lines left behind
+func (c *cells) shift(top, n int) {
+	if n > 0 {
+		for y := top; y < c.areaRows; y++ {
+			if y+n < c.areaRows {
+				copy(c.line(y), c.line(y+n))
+			} else {
+				c.clearLine(y)
+			}
+		}
+	} else if n < 0 {
+		for y := c.areaRows - 1; y >= top; y-- {
+			if y+n >= top {
+				copy(c.line(y), c.line(y+n))
+			} else {
+				c.clearLine(y)
+			}
+		}
+	}
+}
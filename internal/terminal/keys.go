@@ -0,0 +1,93 @@
+package terminal
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ansiKeys are the sequences sent by the keys of ANSI terminals.  They are
+// understood as well as the ones in the terminal's terminfo entry, as the
+// cursor keys send different sequences depending on the keypad mode.
+var ansiKeys = map[string]int{
+	"\x1b[A": KeyUp, "\x1b[B": KeyDown, "\x1b[C": KeyRight, "\x1b[D": KeyLeft,
+	"\x1bOA": KeyUp, "\x1bOB": KeyDown, "\x1bOC": KeyRight, "\x1bOD": KeyLeft,
+	"\x1b[H": KeyHome, "\x1bOH": KeyHome, "\x1b[1~": KeyHome, "\x1b[7~": KeyHome,
+	"\x1b[F": KeyEnd, "\x1bOF": KeyEnd, "\x1b[4~": KeyEnd, "\x1b[8~": KeyEnd,
+	"\x1b[2~": KeyIC, "\x1b[3~": KeyDC, "\x1b[5~": KeyPPage, "\x1b[6~": KeyNPage,
+	"\x1b[Z": KeyBTab, "\x1b[E": KeyB2, "\x1bOE": KeyB2, "\x1bOM": KeyEnter,
+	"\x1bOP": KeyF0 + 1, "\x1bOQ": KeyF0 + 2, "\x1bOR": KeyF0 + 3, "\x1bOS": KeyF0 + 4,
+	"\x1b[11~": KeyF0 + 1, "\x1b[12~": KeyF0 + 2, "\x1b[13~": KeyF0 + 3,
+	"\x1b[14~": KeyF0 + 4, "\x1b[15~": KeyF0 + 5, "\x1b[17~": KeyF0 + 6,
+	"\x1b[18~": KeyF0 + 7, "\x1b[19~": KeyF0 + 8, "\x1b[20~": KeyF0 + 9,
+	"\x1b[21~": KeyF0 + 10, "\x1b[23~": KeyF0 + 11, "\x1b[24~": KeyF0 + 12,
+	"\x1b[1;2P": KeyF0 + 13, "\x1b[1;2Q": KeyF0 + 14, "\x1b[1;2R": KeyF0 + 15,
+	"\x1b[1;2S": KeyF0 + 16, "\x1b[15;2~": KeyF0 + 17, "\x1b[17;2~": KeyF0 + 18,
+	"\x1b[18;2~": KeyF0 + 19, "\x1b[19;2~": KeyF0 + 20, "\x1b[20;2~": KeyF0 + 21,
+	"\x1b[21;2~": KeyF0 + 22, "\x1b[23;2~": KeyF0 + 23, "\x1b[24;2~": KeyF0 + 24,
+	"\x1b[1;2D": KeySLeft, "\x1b[1;2C": KeySRight, "\x1b[1;2H": KeySHome,
+	"\x1b[1;2F": KeySEnd, "\x1b[3;2~": KeySDC, "\x1b[1;2A": KeySR, "\x1b[1;2B": KeySF,
+}
+
+// keyMap decodes the bytes sent by a terminal's keys
+type keyMap struct {
+	keys map[string]int
+}
+
+// newKeyMap makes a key map for a terminal, info may be nil if the
+// terminal has no terminfo entry
+func newKeyMap(info *terminfo) *keyMap {
+	m := &keyMap{keys: make(map[string]int)}
+	for seq, key := range ansiKeys {
+		m.keys[seq] = key
+	}
+	if info == nil {
+		return m
+	}
+	add := func(index, key int) {
+		if seq := info.strings[index]; seq != "" {
+			m.keys[seq] = key
+		}
+	}
+	for index, key := range terminfoKeys {
+		add(index, key)
+	}
+	for n := 11; n <= 63; n++ {
+		add(tiKeyF11+n-11, KeyF0+n)
+	}
+	return m
+}
+
+// decode decodes the key at the start of buf, returning the number of
+// bytes it took.  No bytes are taken if more are needed to tell which key
+// it is, unless final is set because no more are coming.  Bytes that are
+// not a key sequence or UTF-8 are taken a byte at a time.
+func (m *keyMap) decode(buf []byte, final bool) (key int, isKey bool, n int) {
+	if len(buf) == 0 {
+		return 0, false, 0
+	}
+	str := string(buf)
+	partial := false
+	for seq, code := range m.keys {
+		if strings.HasPrefix(str, seq) {
+			if len(seq) > n {
+				key, n = code, len(seq)
+			}
+		} else if strings.HasPrefix(seq, str) {
+			partial = true
+		}
+	}
+	if partial && !final {
+		return 0, false, 0
+	}
+	if n > 0 {
+		return key, true, n
+	}
+	if !utf8.FullRune(buf) && !final {
+		return 0, false, 0
+	}
+	ch, size := utf8.DecodeRune(buf)
+	if ch == utf8.RuneError && size <= 1 {
+		return int(buf[0]), false, 1
+	}
+	return int(ch), false, size
+}
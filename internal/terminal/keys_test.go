@@ -0,0 +1,117 @@
+// Tests for decoding keys and reading terminfo entries
+
+package terminal
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyDecode(t *testing.T) {
+	m := newKeyMap(&terminfo{strings: map[int]string{87: "\x1b[99~", tiKeyF11 + 2: "\x1bX"}})
+
+	tests := []struct {
+		input string
+		final bool
+		key   int
+		isKey bool
+		n     int
+	}{
+		{"a", false, 'a', false, 1},
+		{"\x1b[A", false, KeyUp, true, 3},
+		{"\x1bOAx", false, KeyUp, true, 3},
+		{"\x1b[99~", false, KeyUp, true, 5},
+		{"\x1bX", false, KeyF0 + 13, true, 2},
+		{"\x1b[1;2D", false, KeySLeft, true, 6},
+		// Part of a sequence waits for the rest, unless it is not coming
+		{"\x1b", false, 0, false, 0},
+		{"\x1b[", false, 0, false, 0},
+		{"\x1b", true, 0x1b, false, 1},
+		{"\x1b[", true, 0x1b, false, 1},
+		{"\x1bz", false, 0x1b, false, 1},
+		// UTF-8 is decoded, bytes that are not UTF-8 are taken one by one
+		{"é", false, 'é', false, 2},
+		{"\xc3", false, 0, false, 0},
+		{"\xc3", true, 0xc3, false, 1},
+		{"\xffa", false, 0xff, false, 1},
+	}
+	for _, test := range tests {
+		key, isKey, n := m.decode([]byte(test.input), test.final)
+		assert.Equal(t, test.n, n, "%q", test.input)
+		if n > 0 {
+			assert.Equal(t, test.key, key, "%q", test.input)
+			assert.Equal(t, test.isKey, isKey, "%q", test.input)
+		}
+	}
+}
+
+// compileTerminfo makes a compiled terminfo entry with some numbers and
+// strings
+func compileTerminfo(magic int, numbers map[int]int, strs map[int]string) []byte {
+	numSize := 2
+	if magic == terminfoMagic32 {
+		numSize = 4
+	}
+	numCount, strCount := 0, 0
+	for i := range numbers {
+		numCount = max(numCount, i+1)
+	}
+	for i := range strs {
+		strCount = max(strCount, i+1)
+	}
+	names := "test|a test terminal\x00"
+	var table []byte
+	offsets := make([]int, strCount)
+	for i := range offsets {
+		offsets[i] = -1
+		if str, ok := strs[i]; ok {
+			offsets[i] = len(table)
+			table = append(table, str+"\x00"...)
+		}
+	}
+
+	data := []byte{}
+	for _, v := range []int{magic, len(names), 1, numCount, strCount, len(table)} {
+		data = binary.LittleEndian.AppendUint16(data, uint16(v))
+	}
+	data = append(data, names...)
+	data = append(data, 1)
+	if len(data)%2 != 0 {
+		data = append(data, 0)
+	}
+	for i := range numCount {
+		v, ok := numbers[i]
+		if !ok {
+			v = -1
+		}
+		if numSize == 2 {
+			data = binary.LittleEndian.AppendUint16(data, uint16(v))
+		} else {
+			data = binary.LittleEndian.AppendUint32(data, uint32(v))
+		}
+	}
+	for _, offset := range offsets {
+		data = binary.LittleEndian.AppendUint16(data, uint16(offset))
+	}
+	return append(data, table...)
+}
+
+func TestParseTerminfo(t *testing.T) {
+	for _, magic := range []int{terminfoMagic, terminfoMagic32} {
+		data := compileTerminfo(magic, map[int]int{tiColours: 256},
+			map[int]string{tiBell: "\a", tiFlash: "\x1b[?5h$<100/>\x1b[?5l", 87: "\x1bOA"})
+		info, err := parseTerminfo(data)
+		require.NoError(t, err)
+		assert.Equal(t, 256, info.colours)
+		assert.Equal(t, map[int]string{tiBell: "\a", tiFlash: "\x1b[?5h$<100/>\x1b[?5l", 87: "\x1bOA"}, info.strings)
+	}
+
+	_, err := parseTerminfo([]byte("not terminfo"))
+	assert.Error(t, err)
+	data := compileTerminfo(terminfoMagic, nil, map[int]string{1: "\a"})
+	_, err = parseTerminfo(data[:len(data)-1])
+	assert.Error(t, err)
+}
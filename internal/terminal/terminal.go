@@ -0,0 +1,156 @@
+// Package terminal defines the screen that Ludwig draws on and reads keys
+// from, together with the screens that do not need a C library: a pure Go
+// screen driving an ANSI terminal, and a virtual screen held in memory for
+// tests.
+package terminal
+
+import (
+	"errors"
+	"time"
+)
+
+// Screen is a character cell terminal.  Drawing happens in an area of the
+// screen, all positions are relative to the top left corner of the area
+// and counted from zero.  What is drawn is not shown until Refresh.
+type Screen interface {
+	// Init takes over the terminal
+	Init() error
+	// End gives the terminal back, leaving the cursor at the bottom
+	End()
+	// Suspend gives the terminal back to the shell for a while
+	Suspend()
+	// Resume takes the terminal back after Suspend and redraws it
+	Resume()
+	// UpdateSize fits the screen to the size the terminal is now
+	UpdateSize()
+	// Size returns the size of the whole screen
+	Size() (rows, cols int)
+
+	// SetArea confines drawing to an area of the screen.  An area of no
+	// rows is the whole screen.  The cursor of a new area is at its top
+	// left corner.
+	SetArea(y, x, rows, cols int)
+	// AreaSize returns the size of the area
+	AreaSize() (rows, cols int)
+	// Move moves the cursor, a position outside the area is ignored
+	Move(y, x int)
+	// Cursor returns the position of the cursor
+	Cursor() (y, x int)
+
+	// Print draws a string at the cursor
+	Print(str string)
+	// AddChar draws a character at the cursor and moves past it.  A
+	// backspace moves the cursor left, other control characters are drawn
+	// as ^X.
+	AddChar(ch rune)
+	// InsChar inserts a character at the cursor, moving the rest of the
+	// line right.  The cursor does not move.
+	InsChar(ch rune)
+	// DelChar deletes the character at the cursor
+	DelChar()
+	// ClearToEOL clears from the cursor to the end of the line
+	ClearToEOL()
+	// ClearToBottom clears from the cursor to the bottom of the area
+	ClearToBottom()
+	// Clear clears the area and moves the cursor to its top left corner
+	Clear()
+	// Scroll scrolls the area up n lines, or down if n is negative
+	Scroll(n int)
+	// InsDelLines inserts n blank lines at the cursor, or deletes lines
+	// if n is negative
+	InsDelLines(n int)
+	// SetStyle sets how the characters drawn from now on look
+	SetStyle(attr Attr, colour Colour)
+	// HasColours returns true if the terminal can show colours
+	HasColours() bool
+
+	// ShowCursor shows or hides the cursor
+	ShowCursor(visible bool)
+	// Refresh makes the terminal show what has been drawn
+	Refresh()
+	// Beep gets the user's attention
+	Beep()
+
+	// GetKey reads a key, waiting up to timeout for one to arrive.  A
+	// negative timeout waits for ever.  The key is a character, or one of
+	// the key codes if isKey is set.  ErrTimeout is returned if no key
+	// arrived in time.
+	GetKey(timeout time.Duration) (key int, isKey bool, err error)
+}
+
+// Attr is a set of attributes of drawn characters
+type Attr int
+
+// Attributes
+const (
+	AttrBold Attr = 1 << iota
+	AttrDim
+	AttrReverse
+
+	AttrNormal Attr = 0
+)
+
+// Colour is the colour of drawn characters
+type Colour int
+
+// Colours, in the order terminals number them
+const (
+	ColourDefault Colour = iota - 1
+	ColourBlack
+	ColourRed
+	ColourGreen
+	ColourYellow
+	ColourBlue
+	ColourMagenta
+	ColourCyan
+	ColourWhite
+)
+
+// Key codes, numbered as curses numbers them
+const (
+	KeyMin       = 0401
+	KeyBreak     = 0401
+	KeyDown      = 0402
+	KeyUp        = 0403
+	KeyLeft      = 0404
+	KeyRight     = 0405
+	KeyHome      = 0406
+	KeyBackspace = 0407
+	KeyF0        = 0410 // Function key n is KeyF0 + n
+	KeyDL        = 0510
+	KeyIL        = 0511
+	KeyDC        = 0512
+	KeyIC        = 0513
+	KeyEIC       = 0514
+	KeyClear     = 0515
+	KeyEOS       = 0516
+	KeyEOL       = 0517
+	KeySF        = 0520
+	KeySR        = 0521
+	KeyNPage     = 0522
+	KeyPPage     = 0523
+	KeyEnter     = 0527
+	KeyA1        = 0534
+	KeyA3        = 0535
+	KeyB2        = 0536
+	KeyC1        = 0537
+	KeyC3        = 0540
+	KeyBTab      = 0541
+	KeyBeg       = 0542
+	KeyEnd       = 0550
+	KeySDC       = 0577
+	KeySEnd      = 0602
+	KeySHome     = 0607
+	KeySLeft     = 0611
+	KeySRight    = 0622
+	KeyMouse     = 0631
+	KeyResize    = 0632
+	KeyEvent     = 0633
+	KeyMax       = 0777
+)
+
+// ErrTimeout is returned by GetKey if no key arrives in time
+var ErrTimeout = errors.New("timed out waiting for a key")
+
+// ErrNoTerminal is returned by Init if there is no terminal to use
+var ErrNoTerminal = errors.New("not a terminal")
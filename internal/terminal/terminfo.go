@@ -0,0 +1,137 @@
+package terminal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Magic numbers of compiled terminfo entries, with 16 and 32 bit numbers
+const (
+	terminfoMagic   = 0432
+	terminfoMagic32 = 01036
+)
+
+// Indexes of the capabilities in a compiled terminfo entry
+const (
+	tiColours = 13 // max_colors
+
+	tiBell         = 1   // bell
+	tiCursorHidden = 13  // cursor_invisible
+	tiCursorNormal = 16  // cursor_normal
+	tiEnterCA      = 28  // enter_ca_mode
+	tiExitCA       = 40  // exit_ca_mode
+	tiFlash        = 45  // flash_screen
+	tiKeypadLocal  = 88  // keypad_local
+	tiKeypadXmit   = 89  // keypad_xmit
+	tiKeyF11       = 216 // key_f11, the keys up to key_f63 follow
+)
+
+// terminfoKeys are the indexes of the key capabilities with the key codes
+// they give
+var terminfoKeys = map[int]int{
+	55: KeyBackspace, 57: KeyClear, 59: KeyDC, 60: KeyDL, 61: KeyDown,
+	62: KeyEIC, 63: KeyEOL, 64: KeyEOS, 65: KeyF0, 66: KeyF0 + 1,
+	67: KeyF0 + 10, 68: KeyF0 + 2, 69: KeyF0 + 3, 70: KeyF0 + 4,
+	71: KeyF0 + 5, 72: KeyF0 + 6, 73: KeyF0 + 7, 74: KeyF0 + 8,
+	75: KeyF0 + 9, 76: KeyHome, 77: KeyIC, 78: KeyIL, 79: KeyLeft,
+	81: KeyNPage, 82: KeyPPage, 83: KeyRight, 84: KeySF, 85: KeySR,
+	87: KeyUp, 139: KeyA1, 140: KeyA3, 141: KeyB2, 142: KeyC1, 143: KeyC3,
+	148: KeyBTab, 158: KeyBeg, 164: KeyEnd, 165: KeyEnter,
+	191: KeySDC, 194: KeySEnd, 199: KeySHome, 201: KeySLeft, 210: KeySRight,
+}
+
+// terminfo is what is needed of a terminal's terminfo entry
+type terminfo struct {
+	colours int
+	strings map[int]string
+}
+
+// terminfoDirs returns the directories terminfo entries are looked for in
+func terminfoDirs() []string {
+	var dirs []string
+	if dir := os.Getenv("TERMINFO"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	for _, dir := range strings.Split(os.Getenv("TERMINFO_DIRS"), ":") {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return append(dirs, "/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo",
+		"/usr/lib/terminfo", "/usr/share/lib/terminfo")
+}
+
+// loadTerminfo reads the terminfo entry for a terminal
+func loadTerminfo(name string) (*terminfo, error) {
+	if name == "" || strings.ContainsAny(name, "/.") {
+		return nil, errors.New("bad terminal name")
+	}
+	for _, dir := range terminfoDirs() {
+		// Entries are filed under their first letter, or its hex code
+		for _, sub := range []string{name[:1], fmt.Sprintf("%02x", name[0])} {
+			data, err := os.ReadFile(filepath.Join(dir, sub, name))
+			if err == nil {
+				return parseTerminfo(data)
+			}
+		}
+	}
+	return nil, fmt.Errorf("no terminfo entry for %s", name)
+}
+
+// parseTerminfo decodes a compiled terminfo entry
+func parseTerminfo(data []byte) (*terminfo, error) {
+	bad := errors.New("bad terminfo entry")
+	short := func(i int) int {
+		return int(int16(binary.LittleEndian.Uint16(data[i:])))
+	}
+	if len(data) < 12 {
+		return nil, bad
+	}
+	numSize := 2
+	switch short(0) {
+	case terminfoMagic:
+	case terminfoMagic32:
+		numSize = 4
+	default:
+		return nil, bad
+	}
+	namesSize, boolCount, numCount, strCount, tableSize := short(2), short(4), short(6), short(8), short(10)
+	if namesSize < 0 || boolCount < 0 || numCount < 0 || strCount < 0 || tableSize < 0 {
+		return nil, bad
+	}
+	numbers := 12 + namesSize + boolCount
+	numbers += numbers % 2
+	offsets := numbers + numCount*numSize
+	table := offsets + strCount*2
+	if len(data) < table+tableSize {
+		return nil, bad
+	}
+
+	info := &terminfo{colours: -1, strings: make(map[int]string)}
+	if tiColours < numCount {
+		if numSize == 2 {
+			info.colours = short(numbers + tiColours*2)
+		} else {
+			info.colours = int(int32(binary.LittleEndian.Uint32(data[numbers+tiColours*4:])))
+		}
+	}
+	for i := range strCount {
+		offset := short(offsets + i*2)
+		if offset < 0 || offset >= tableSize {
+			continue
+		}
+		str := data[table+offset : table+tableSize]
+		if end := strings.IndexByte(string(str), 0); end >= 0 {
+			str = str[:end]
+		}
+		info.strings[i] = string(str)
+	}
+	return info, nil
+}
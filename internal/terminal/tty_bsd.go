@@ -0,0 +1,18 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package terminal
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETAW
+)
+
+// disableSignalChars stops the quit and suspend characters raising
+// signals, so that they are read as keys
+func disableSignalChars(t *unix.Termios) {
+	t.Cc[unix.VQUIT] = 0xff
+	t.Cc[unix.VSUSP] = 0xff
+	t.Cc[unix.VDSUSP] = 0xff
+}
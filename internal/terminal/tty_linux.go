@@ -0,0 +1,15 @@
+package terminal
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETSW
+)
+
+// disableSignalChars stops the quit and suspend characters raising
+// signals, so that they are read as keys
+func disableSignalChars(t *unix.Termios) {
+	t.Cc[unix.VQUIT] = 0
+	t.Cc[unix.VSUSP] = 0
+}
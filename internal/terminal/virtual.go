@@ -0,0 +1,181 @@
+package terminal
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// virtualKey is a key waiting to be read from a virtual screen
+type virtualKey struct {
+	key   int
+	isKey bool
+}
+
+// Virtual is a screen held in memory.  Keys are typed at it by the program
+// using it, and what it shows can be looked at after each Refresh, so
+// tests can drive an editor and check what the user would see.
+type Virtual struct {
+	*cells
+	shown      [][]cell // What was drawn, as of the last Refresh
+	shownY     int
+	shownX     int
+	cursorOn   bool
+	colours    bool
+	keys       []virtualKey
+	newRows    int
+	newCols    int
+	beeps      int
+	refreshes  int
+	terminated bool
+}
+
+// NewVirtual makes a virtual screen of the given size
+func NewVirtual(rows, cols int) *Virtual {
+	v := &Virtual{cells: newCells(rows, cols), colours: true, cursorOn: true}
+	v.newRows, v.newCols = v.rows, v.cols
+	v.Refresh()
+	v.refreshes = 0
+	return v
+}
+
+// SetColours sets whether the virtual screen can show colours
+func (v *Virtual) SetColours(colours bool) {
+	v.colours = colours
+}
+
+// Type queues the characters of a string to be read as keys
+func (v *Virtual) Type(str string) {
+	for _, ch := range str {
+		v.keys = append(v.keys, virtualKey{key: int(ch)})
+	}
+}
+
+// Press queues key codes to be read as keys
+func (v *Virtual) Press(keys ...int) {
+	for _, key := range keys {
+		v.keys = append(v.keys, virtualKey{key: key, isKey: true})
+	}
+}
+
+// Pending returns the number of keys waiting to be read
+func (v *Virtual) Pending() int {
+	return len(v.keys)
+}
+
+// Resize changes the size of the terminal, as if the user had resized its
+// window.  The resize key is queued to tell the program.
+func (v *Virtual) Resize(rows, cols int) {
+	v.newRows, v.newCols = rows, cols
+	v.Press(KeyResize)
+}
+
+// Lines returns what the screen showed when it was last refreshed, one
+// string for each line with the trailing spaces removed
+func (v *Virtual) Lines() []string {
+	lines := make([]string, len(v.shown))
+	for y, line := range v.shown {
+		var b strings.Builder
+		for _, c := range line {
+			b.WriteString(c.text)
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return lines
+}
+
+// String returns what the screen showed when it was last refreshed
+func (v *Virtual) String() string {
+	return strings.Join(v.Lines(), "\n")
+}
+
+// Style returns how the character shown at a position looks
+func (v *Virtual) Style(y, x int) (Attr, Colour) {
+	c := v.shown[y][x]
+	return c.attr, c.colour
+}
+
+// ShownCursor returns where the cursor was shown on the screen, and
+// whether it could be seen
+func (v *Virtual) ShownCursor() (y, x int, visible bool) {
+	return v.shownY, v.shownX, v.cursorOn
+}
+
+// Beeps returns the number of times the screen has beeped
+func (v *Virtual) Beeps() int {
+	return v.beeps
+}
+
+// Refreshes returns the number of times the screen has been refreshed
+func (v *Virtual) Refreshes() int {
+	return v.refreshes
+}
+
+// Ended returns true if the program has given the screen back
+func (v *Virtual) Ended() bool {
+	return v.terminated
+}
+
+// Init takes over the screen
+func (v *Virtual) Init() error {
+	v.terminated = false
+	return nil
+}
+
+// End gives the screen back
+func (v *Virtual) End() {
+	v.Refresh()
+	v.terminated = true
+}
+
+// Suspend gives the screen back for a while
+func (v *Virtual) Suspend() {
+	v.Refresh()
+}
+
+// Resume takes the screen back after Suspend
+func (v *Virtual) Resume() {
+	v.Refresh()
+}
+
+// UpdateSize fits the screen to the size set by Resize
+func (v *Virtual) UpdateSize() {
+	v.resize(v.newRows, v.newCols)
+}
+
+// HasColours returns true if the screen can show colours
+func (v *Virtual) HasColours() bool {
+	return v.colours
+}
+
+// ShowCursor shows or hides the cursor
+func (v *Virtual) ShowCursor(visible bool) {
+	v.cursorOn = visible
+}
+
+// Refresh makes what has been drawn visible
+func (v *Virtual) Refresh() {
+	v.shown = make([][]cell, v.rows)
+	for y, line := range v.lines {
+		v.shown[y] = append([]cell(nil), line...)
+	}
+	v.shownY, v.shownX = v.areaY+v.y, v.areaX+v.x
+	v.cleared = false
+	v.refreshes++
+}
+
+// Beep counts a beep
+func (v *Virtual) Beep() {
+	v.beeps++
+}
+
+// GetKey reads the next queued key.  There is nobody to type more keys,
+// so io.EOF is returned once the queue is empty.
+func (v *Virtual) GetKey(timeout time.Duration) (int, bool, error) {
+	if len(v.keys) == 0 {
+		return 0, false, io.EOF
+	}
+	key := v.keys[0]
+	v.keys = v.keys[1:]
+	return key.key, key.isKey, nil
+}
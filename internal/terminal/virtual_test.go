@@ -0,0 +1,174 @@
+// Tests for the virtual screen, and the drawing it shares with the other
+// screens
+
+package terminal
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestScreen makes a virtual screen with some lines drawn on it
+func newTestScreen(rows, cols int, lines ...string) *Virtual {
+	v := NewVirtual(rows, cols)
+	for y, line := range lines {
+		v.Move(y, 0)
+		v.Print(line)
+	}
+	v.Refresh()
+	return v
+}
+
+func TestVirtualDraw(t *testing.T) {
+	v := NewVirtual(3, 10)
+	v.Move(1, 2)
+	v.Print("abc")
+	assert.Equal(t, []string{"", "", ""}, v.Lines(), "nothing shows until refreshed")
+	v.Refresh()
+	assert.Equal(t, []string{"", "  abc", ""}, v.Lines())
+	y, x, _ := v.ShownCursor()
+	assert.Equal(t, []int{1, 5}, []int{y, x})
+
+	// Text wraps at the end of a line and stops at the bottom corner
+	v.Move(1, 8)
+	v.Print("wxyz")
+	v.Move(2, 8)
+	v.Print("123")
+	v.Refresh()
+	assert.Equal(t, []string{"", "  abc   wx", "yz      13"}, v.Lines())
+
+	// Control characters are shown as ^X, backspace moves back
+	v.Move(0, 0)
+	v.Print("a\x01b\bc")
+	v.Refresh()
+	assert.Equal(t, "a^Ac", v.Lines()[0])
+
+	v.Clear()
+	v.Refresh()
+	assert.Equal(t, []string{"", "", ""}, v.Lines())
+}
+
+func TestVirtualWide(t *testing.T) {
+	v := newTestScreen(2, 6, "日本語")
+	assert.Equal(t, "日本語", v.Lines()[0])
+
+	// Overwriting half of a wide character blanks the other half
+	v.Move(0, 1)
+	v.AddChar('x')
+	v.Refresh()
+	assert.Equal(t, " x本語", v.Lines()[0])
+
+	// A wide character that does not fit goes on the next line
+	v.Move(0, 5)
+	v.AddChar('字')
+	v.Refresh()
+	assert.Equal(t, []string{" x本", "字"}, v.Lines())
+
+	// Combining marks go on the character before
+	v.Move(1, 2)
+	v.Print("é!")
+	v.Refresh()
+	assert.Equal(t, "字é!", v.Lines()[1])
+}
+
+func TestVirtualEdit(t *testing.T) {
+	v := newTestScreen(4, 6, "abcdef", "ghijkl", "mnopqr", "stuvwx")
+
+	v.Move(0, 1)
+	v.InsChar('X')
+	v.Move(1, 1)
+	v.DelChar()
+	v.Move(2, 3)
+	v.ClearToEOL()
+	v.Refresh()
+	assert.Equal(t, []string{"aXbcde", "gijkl", "mno", "stuvwx"}, v.Lines())
+	y, x := v.Cursor()
+	assert.Equal(t, []int{2, 3}, []int{y, x})
+
+	v.Move(1, 0)
+	v.InsDelLines(1)
+	v.Refresh()
+	assert.Equal(t, []string{"aXbcde", "", "gijkl", "mno"}, v.Lines())
+	v.InsDelLines(-2)
+	v.Refresh()
+	assert.Equal(t, []string{"aXbcde", "mno", "", ""}, v.Lines())
+
+	v.Scroll(1)
+	v.Refresh()
+	assert.Equal(t, []string{"mno", "", "", ""}, v.Lines())
+	v.Scroll(-1)
+	v.Refresh()
+	assert.Equal(t, []string{"", "mno", "", ""}, v.Lines())
+
+	v.Move(1, 1)
+	v.ClearToBottom()
+	v.Refresh()
+	assert.Equal(t, []string{"", "m", "", ""}, v.Lines())
+}
+
+func TestVirtualArea(t *testing.T) {
+	v := newTestScreen(4, 8, "11111111", "22222222", "33333333", "44444444")
+	v.Move(3, 7)
+
+	// Drawing in an area leaves the rest of the screen alone, and stops at
+	// the area's bottom corner
+	v.SetArea(1, 2, 2, 4)
+	rows, cols := v.AreaSize()
+	assert.Equal(t, []int{2, 4}, []int{rows, cols})
+	y, x := v.Cursor()
+	assert.Equal(t, []int{0, 0}, []int{y, x})
+	v.Scroll(1)
+	v.Move(1, 0)
+	v.Print("abcdef")
+	v.Refresh()
+	assert.Equal(t, []string{"11111111", "22333322", "33abcf33", "44444444"}, v.Lines())
+
+	// Going back to the whole screen puts its cursor back
+	v.SetArea(0, 0, 0, 0)
+	y, x = v.Cursor()
+	assert.Equal(t, []int{3, 7}, []int{y, x})
+}
+
+func TestVirtualStyle(t *testing.T) {
+	v := NewVirtual(1, 10)
+	v.SetStyle(AttrBold, ColourRed)
+	v.Print("ab")
+	v.SetStyle(AttrNormal, ColourDefault)
+	v.Print("c")
+	v.Refresh()
+	attr, colour := v.Style(0, 1)
+	assert.Equal(t, AttrBold, attr)
+	assert.Equal(t, ColourRed, colour)
+	attr, colour = v.Style(0, 2)
+	assert.Equal(t, AttrNormal, attr)
+	assert.Equal(t, ColourDefault, colour)
+}
+
+func TestVirtualKeys(t *testing.T) {
+	v := NewVirtual(5, 20)
+	v.Type("aé")
+	v.Press(KeyUp)
+	v.Resize(3, 10)
+	assert.Equal(t, 4, v.Pending())
+
+	for _, want := range []struct {
+		key   int
+		isKey bool
+	}{{'a', false}, {'é', false}, {KeyUp, true}, {KeyResize, true}} {
+		key, isKey, err := v.GetKey(-1)
+		require.NoError(t, err)
+		assert.Equal(t, want.key, key)
+		assert.Equal(t, want.isKey, isKey)
+	}
+	_, _, err := v.GetKey(0)
+	assert.Equal(t, io.EOF, err)
+
+	rows, cols := v.Size()
+	assert.Equal(t, []int{5, 20}, []int{rows, cols})
+	v.UpdateSize()
+	rows, cols = v.Size()
+	assert.Equal(t, []int{3, 10}, []int{rows, cols})
+}
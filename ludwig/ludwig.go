@@ -0,0 +1,251 @@
+// Package ludwig runs Ludwig's command language from Go programs, without a
+// terminal.  An Editor holds frames of text, which are edited by running
+// command strings in them just as Ludwig's batch mode runs the commands it
+// reads from standard input.  The text of the frames, their marks and the
+// contents of spans can then be read back.
+//
+//	ed, err := ludwig.New(ludwig.OldCommands)
+//	...
+//	err = ed.NewFrame("LUDWIG", "hello world\n")
+//	err = ed.Run(`G/world/ -5D I/there/`)
+//	text, err := ed.Text("LUDWIG") // "hello there\n"
+//
+// An Editor is not safe for use by more than one goroutine at once, but any
+// number of Editors can be used at the same time.
+package ludwig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	lw "ludwig-go/internal/ludwig"
+)
+
+// CommandSet chooses which of Ludwig's two command sets commands are
+// written in
+type CommandSet int
+
+const (
+	OldCommands CommandSet = iota // The command set Ludwig uses by default
+	NewCommands                   // The command set chosen by ludwig -O
+)
+
+// The marks that are not numbered
+const (
+	MarkEquals   = lw.MarkEquals   // The = mark, set at the last change
+	MarkModified = lw.MarkModified // The % mark, set at the last modification
+)
+
+// DefaultFrame is the name of the frame an Editor starts in
+const DefaultFrame = lw.DefaultFrameName
+
+var (
+	// ErrFailed is returned when commands fail, wrapped with what Ludwig
+	// had to say about it
+	ErrFailed = errors.New("command failed")
+	// ErrNoFrame is returned when there is no frame of the name asked for
+	ErrNoFrame = errors.New("no such frame")
+	// ErrNoSpan is returned when there is no span of the name asked for
+	ErrNoSpan = errors.New("no such span")
+	// ErrExists is returned when a frame or span of the name already exists
+	ErrExists = errors.New("frame or span exists")
+	// ErrNoMark is returned when a mark asked for is not set
+	ErrNoMark = errors.New("mark not set")
+)
+
+// Position is a place in a frame, by line and column numbered from 1
+type Position struct {
+	Line int
+	Col  int
+}
+
+// Editor is an editing session with no terminal
+type Editor struct {
+	e        *lw.Editor
+	messages bytes.Buffer
+}
+
+// New makes an Editor with the special frames Ludwig always has, and an
+// empty frame named DefaultFrame as the current frame.
+func New(commands CommandSet) (*Editor, error) {
+	ed := &Editor{e: lw.NewEditor()}
+	e := ed.e
+	e.Output = &ed.messages
+	e.ValueInitializations()
+	e.FileData.OldCmds = commands == OldCommands
+	if !e.BatchStart() {
+		return nil, ed.failed()
+	}
+	e.LudwigAborted = true
+	return ed, nil
+}
+
+// NewFrame creates a frame holding some text, and makes it the current
+// frame.  Tabs in the text are expanded and trailing spaces are removed, as
+// when Ludwig reads a file.
+func (ed *Editor) NewFrame(name, text string) error {
+	return ed.newFrame(name, strings.NewReader(text))
+}
+
+// NewFrameFromFile creates a frame holding the text of a file, and makes it
+// the current frame.  The frame is not attached to the file, so writing it
+// back is up to the caller.
+func (ed *Editor) NewFrameFromFile(name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return ed.newFrame(name, f)
+}
+
+func (ed *Editor) newFrame(name string, r io.Reader) error {
+	ed.messages.Reset()
+	var span, oldp *lw.SpanObject
+	if ed.e.SpanFind(name, &span, &oldp) {
+		return fmt.Errorf("%w: %s", ErrExists, name)
+	}
+	if !ed.e.FrameEdit(name) || !ed.e.FileLoad(ed.e.CurrentFrame, r) {
+		return ed.failed()
+	}
+	return nil
+}
+
+// SetFrame makes a frame the current frame
+func (ed *Editor) SetFrame(name string) error {
+	ed.messages.Reset()
+	if _, err := ed.frame(name); err != nil {
+		return err
+	}
+	if !ed.e.FrameEdit(name) {
+		return ed.failed()
+	}
+	return nil
+}
+
+// CurrentFrame returns the name of the current frame
+func (ed *Editor) CurrentFrame() string {
+	return ed.e.CurrentFrame.Span.Name
+}
+
+// Run compiles and runs a string of commands in the current frame, which
+// may be several lines long.  If the commands fail, the error returned
+// wraps ErrFailed.
+func (ed *Editor) Run(commands string) error {
+	ed.messages.Reset()
+	if !ed.e.BatchExecute(commands) {
+		return ed.failed()
+	}
+	return nil
+}
+
+// Messages returns what Ludwig had to say during the last call of one of
+// the Editor's methods, a line at a time
+func (ed *Editor) Messages() []string {
+	text := strings.TrimRight(ed.messages.String(), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// failed makes the error for something that has failed, from the messages
+// Ludwig gave
+func (ed *Editor) failed() error {
+	if messages := ed.Messages(); len(messages) > 0 {
+		return fmt.Errorf("%w: %s", ErrFailed, strings.Join(messages, "; "))
+	}
+	return ErrFailed
+}
+
+// Frames returns the names of the frames, other than the special frames
+// OOPS, COMMAND and HEAP
+func (ed *Editor) Frames() []string {
+	var names []string
+	for span := ed.e.FirstSpan; span != nil; span = span.FLink {
+		if span.Frame != nil && !span.Frame.Options.Has(lw.OptSpecialFrame) {
+			names = append(names, span.Name)
+		}
+	}
+	return names
+}
+
+// Text returns the text of a frame, with each line ending in a newline
+func (ed *Editor) Text(frame string) (string, error) {
+	f, err := ed.frame(frame)
+	if err != nil {
+		return "", err
+	}
+	return markedText(f.Span.MarkOne, f.Span.MarkTwo), nil
+}
+
+// Span returns the text of a span, with a newline wherever the span goes
+// on to the next line.  Frames are spans too.
+func (ed *Editor) Span(name string) (string, error) {
+	var span, oldp *lw.SpanObject
+	if !ed.e.SpanFind(name, &span, &oldp) {
+		return "", fmt.Errorf("%w: %s", ErrNoSpan, name)
+	}
+	return markedText(span.MarkOne, span.MarkTwo), nil
+}
+
+// Dot returns where the dot is in a frame
+func (ed *Editor) Dot(frame string) (Position, error) {
+	f, err := ed.frame(frame)
+	if err != nil {
+		return Position{}, err
+	}
+	return markPosition(f.Dot), nil
+}
+
+// Mark returns where a mark is in a frame.  The marks are numbered from 1
+// to 9, and there are also MarkEquals and MarkModified.
+func (ed *Editor) Mark(frame string, mark int) (Position, error) {
+	f, err := ed.frame(frame)
+	if err != nil {
+		return Position{}, err
+	}
+	if mark < 0 || mark > lw.MaxMarkNumber || f.Marks[mark] == nil {
+		return Position{}, fmt.Errorf("%w: %d", ErrNoMark, mark)
+	}
+	return markPosition(f.Marks[mark]), nil
+}
+
+// frame finds a frame by name
+func (ed *Editor) frame(name string) (*lw.FrameObject, error) {
+	var span, oldp *lw.SpanObject
+	if !ed.e.SpanFind(name, &span, &oldp) || span.Frame == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoFrame, name)
+	}
+	return span.Frame, nil
+}
+
+// markPosition returns the position of a mark
+func markPosition(mark *lw.MarkObject) Position {
+	var lineNr int
+	lw.LineToNumber(mark.Line, &lineNr)
+	return Position{Line: lineNr, Col: mark.Col}
+}
+
+// markedText returns the text from one mark up to another, with any bytes
+// that were not UTF-8 in the file given back as they were
+func markedText(from, to *lw.MarkObject) string {
+	var b []byte
+	line, col := from.Line, from.Col
+	for ; line != to.Line && line != nil; line, col = line.FLink, 1 {
+		if col <= line.Used {
+			b = lw.ChAppendUTF8(b, line.Str, col, line.Used-col+1)
+		}
+		b = append(b, '\n')
+	}
+	if line != nil {
+		if end := min(to.Col-1, line.Used); col <= end {
+			b = lw.ChAppendUTF8(b, line.Str, col, end-col+1)
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,132 @@
+// Tests for editing with the public API, without a terminal
+
+package ludwig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEditor(t *testing.T, text string) *Editor {
+	ed, err := New(OldCommands)
+	require.NoError(t, err)
+	require.NoError(t, ed.NewFrame("TEXT", text))
+	return ed
+}
+
+func TestRun(t *testing.T) {
+	ed := newEditor(t, "hello world\nsecond\tline\n")
+	assert.Equal(t, "TEXT", ed.CurrentFrame())
+	assert.Equal(t, []string{DefaultFrame, "TEXT"}, ed.Frames())
+
+	text, err := ed.Text("TEXT")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\nsecond  line\n", text)
+
+	require.NoError(t, ed.Run("g/world/ -5d i/there/"))
+	text, err = ed.Text("TEXT")
+	require.NoError(t, err)
+	assert.Equal(t, "hello there\nsecond  line\n", text)
+
+	dot, err := ed.Dot("TEXT")
+	require.NoError(t, err)
+	assert.Equal(t, Position{Line: 1, Col: 12}, dot)
+	mark, err := ed.Mark("TEXT", MarkEquals)
+	require.NoError(t, err)
+	assert.Equal(t, Position{Line: 1, Col: 7}, mark)
+}
+
+func TestRunLines(t *testing.T) {
+	ed := newEditor(t, "one\ntwo\nthree\n")
+	require.NoError(t, ed.Run("a\nk\n"))
+	text, err := ed.Text("TEXT")
+	require.NoError(t, err)
+	assert.Equal(t, "one\nthree\n", text)
+}
+
+func TestRunFails(t *testing.T) {
+	ed := newEditor(t, "text\n")
+	err := ed.Run("g/missing/")
+	assert.ErrorIs(t, err, ErrFailed)
+
+	err = ed.Run("this is not a command")
+	assert.ErrorIs(t, err, ErrFailed)
+	assert.NotEmpty(t, ed.Messages())
+
+	assert.ErrorIs(t, ed.NewFrame("TEXT", ""), ErrExists)
+	_, err = ed.Text("NONE")
+	assert.ErrorIs(t, err, ErrNoFrame)
+	_, err = ed.Span("NONE")
+	assert.ErrorIs(t, err, ErrNoSpan)
+	_, err = ed.Mark("TEXT", 1)
+	assert.ErrorIs(t, err, ErrNoMark)
+}
+
+func TestMarksAndSpans(t *testing.T) {
+	ed := newEditor(t, "first line\nsecond line\n")
+	require.NoError(t, ed.Run("6j 1m a 7j 2m sd/SPAN/"))
+
+	mark, err := ed.Mark("TEXT", 1)
+	require.NoError(t, err)
+	assert.Equal(t, Position{Line: 1, Col: 7}, mark)
+	mark, err = ed.Mark("TEXT", 2)
+	require.NoError(t, err)
+	assert.Equal(t, Position{Line: 2, Col: 8}, mark)
+
+	span, err := ed.Span("SPAN")
+	require.NoError(t, err)
+	assert.Equal(t, "line\nsecond ", span)
+}
+
+func TestTextKeepsBytes(t *testing.T) {
+	// A byte that is not UTF-8 comes back as it was read
+	ed := newEditor(t, "caf\xe9 olé\nnext\n")
+	text, err := ed.Text("TEXT")
+	require.NoError(t, err)
+	assert.Equal(t, "caf\xe9 olé\nnext\n", text)
+
+	require.NoError(t, ed.Run("3j 1m 4j 2m sd/SPAN/"))
+	span, err := ed.Span("SPAN")
+	require.NoError(t, err)
+	assert.Equal(t, "\xe9 ol", span)
+}
+
+func TestNewFrameFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("from a file\n"), 0644))
+
+	ed, err := New(NewCommands)
+	require.NoError(t, err)
+	require.NoError(t, ed.NewFrameFromFile("FILE", path))
+	require.NoError(t, ed.Run("5ac 2dc"))
+	text, err := ed.Text("FILE")
+	require.NoError(t, err)
+	assert.Equal(t, "from file\n", text)
+
+	require.NoError(t, ed.SetFrame(DefaultFrame))
+	assert.Equal(t, DefaultFrame, ed.CurrentFrame())
+	assert.Error(t, ed.NewFrameFromFile("MISSING", filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestEditorsInParallel(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			ed := newEditor(t, "line\n")
+			word := fmt.Sprint(i)
+			for range 20 {
+				assert.NoError(t, ed.Run("i/"+word+"/"))
+			}
+			text, err := ed.Text("TEXT")
+			assert.NoError(t, err)
+			assert.Len(t, text, 20*len(word)+len("line\n"))
+		})
+	}
+	wg.Wait()
+}